@@ -12,13 +12,18 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package backend implements Zero Knowledge Proof systems: it consumes circuit compiled with gnark/frontend.
+// Package backend defines the identifiers and the options of the proof systems
+// consuming the circuits compiled with gnark/frontend. The zero-knowledge proof
+// systems are listed by [Implemented].
 package backend
 
 import (
+	"context"
 	"crypto/sha256"
+	"fmt"
 	"hash"
 
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark/constraint/solver"
 )
 
@@ -29,11 +34,13 @@ const (
 	UNKNOWN ID = iota
 	GROTH16
 	PLONK
+	PLONK_FRI
 )
 
-// Implemented return the list of proof systems implemented in gnark
+// Implemented return the list of zero-knowledge proof systems implemented in
+// gnark.
 func Implemented() []ID {
-	return []ID{GROTH16, PLONK}
+	return []ID{GROTH16, PLONK, PLONK_FRI}
 }
 
 // String returns the string representation of a proof system
@@ -43,6 +50,8 @@ func (id ID) String() string {
 		return "groth16"
 	case PLONK:
 		return "plonk"
+	case PLONK_FRI:
+		return "plonk_fri"
 	default:
 		return "unknown"
 	}
@@ -55,11 +64,14 @@ type ProverOption func(*ProverConfig) error
 
 // ProverConfig is the configuration for the prover with the options applied.
 type ProverConfig struct {
-	SolverOpts     []solver.Option
-	HashToFieldFn  hash.Hash
-	ChallengeHash  hash.Hash
-	KZGFoldingHash hash.Hash
-	Accelerator    string
+	SolverOpts          []solver.Option
+	HashToFieldFn       hash.Hash
+	ChallengeHash       hash.Hash
+	ChallengeTranscript NewTranscriptFunc
+	KZGFoldingHash      hash.Hash
+	Accelerator         string
+	Ctx                 context.Context
+	ProgressHandler     ProgressHandler
 }
 
 // NewProverConfig returns a default ProverConfig with given prover options opts
@@ -70,6 +82,7 @@ func NewProverConfig(opts ...ProverOption) (ProverConfig, error) {
 		// separation tags for PLONK and Groth16
 		ChallengeHash:  sha256.New(),
 		KZGFoldingHash: sha256.New(),
+		Ctx:            context.Background(),
 	}
 	for _, option := range opts {
 		if err := option(&opt); err != nil {
@@ -109,6 +122,24 @@ func WithProverChallengeHashFunction(hFunc hash.Hash) ProverOption {
 	}
 }
 
+// WithProverChallengeTranscript sets the transcript used for computing
+// non-interactive challenges in Fiat-Shamir heuristic instead of the transcript
+// of gnark-crypto hashing the challenges with the function set by
+// [WithProverChallengeHashFunction]. Used mainly for efficient recursion with a
+// duplex-sponge transcript. Only the PLONK prover supports this option.
+func WithProverChallengeTranscript(newTranscript NewTranscriptFunc) ProverOption {
+	return func(pc *ProverConfig) error {
+		pc.ChallengeTranscript = newTranscript
+		return nil
+	}
+}
+
+// NewTranscript returns the Fiat-Shamir transcript of the prover for the given
+// challenges.
+func (pc *ProverConfig) NewTranscript(challengesID ...string) (Transcript, error) {
+	return newTranscript(pc.ChallengeTranscript, pc.ChallengeHash, challengesID)
+}
+
 // WithProverKZGFoldingHashFunction sets the hash function used for computing
 // the challenge when folding the KZG opening proofs. If not set then by default
 // SHA2-256 is used. Used mainly for compatibility between different systems and
@@ -133,6 +164,55 @@ func WithIcicleAcceleration() ProverOption {
 	}
 }
 
+// WithContext sets the context of the prover. When the context is done (it is
+// canceled or its deadline is exceeded), the prover stops at the next safe
+// point of the solver, FFT and MSM phases, drops its intermediate values and
+// returns a [*ProverCanceledError]. A multi-scalar multiplication or FFT which
+// is already running is not interrupted. If not set then
+// [context.Background] is used and the prover cannot be canceled.
+func WithContext(ctx context.Context) ProverOption {
+	return func(pc *ProverConfig) error {
+		if ctx == nil {
+			return fmt.Errorf("nil context")
+		}
+		pc.Ctx = ctx
+		return nil
+	}
+}
+
+// ProverCanceledError is returned by the provers when the context set with
+// [WithContext] is done before the proof is computed. It wraps the error of the
+// context, so that errors.Is(err, context.Canceled) and errors.Is(err,
+// context.DeadlineExceeded) can be used to distinguish the causes.
+type ProverCanceledError struct {
+	// Phase is the phase of the prover during which the cancellation was
+	// noticed.
+	Phase ProgressPhase
+	// Err is the error of the context.
+	Err error
+}
+
+func (e *ProverCanceledError) Error() string {
+	return fmt.Sprintf("prover canceled during %s: %v", e.Phase, e.Err)
+}
+
+func (e *ProverCanceledError) Unwrap() error {
+	return e.Err
+}
+
+// CheckContext returns a [*ProverCanceledError] for the given phase if the
+// context of the prover is done, and nil otherwise. It is called by the provers
+// at the safe points where the proof computation can be stopped.
+func (pc *ProverConfig) CheckContext(phase ProgressPhase) error {
+	if pc.Ctx == nil {
+		return nil
+	}
+	if err := pc.Ctx.Err(); err != nil {
+		return &ProverCanceledError{Phase: phase, Err: err}
+	}
+	return nil
+}
+
 // VerifierOption defines option for altering the behavior of the verifier. See
 // the descriptions of functions returning instances of this type for
 // implemented options.
@@ -140,9 +220,10 @@ type VerifierOption func(*VerifierConfig) error
 
 // VerifierConfig is the configuration for the verifier with the options applied.
 type VerifierConfig struct {
-	HashToFieldFn  hash.Hash
-	ChallengeHash  hash.Hash
-	KZGFoldingHash hash.Hash
+	HashToFieldFn       hash.Hash
+	ChallengeHash       hash.Hash
+	ChallengeTranscript NewTranscriptFunc
+	KZGFoldingHash      hash.Hash
 }
 
 // NewVerifierConfig returns a default [VerifierConfig] with given verifier
@@ -184,6 +265,23 @@ func WithVerifierChallengeHashFunction(hFunc hash.Hash) VerifierOption {
 	}
 }
 
+// WithVerifierChallengeTranscript sets the transcript used for computing
+// non-interactive challenges in Fiat-Shamir heuristic. It must be the transcript
+// set with [WithProverChallengeTranscript]. Only the PLONK verifier supports
+// this option.
+func WithVerifierChallengeTranscript(newTranscript NewTranscriptFunc) VerifierOption {
+	return func(pc *VerifierConfig) error {
+		pc.ChallengeTranscript = newTranscript
+		return nil
+	}
+}
+
+// NewTranscript returns the Fiat-Shamir transcript of the verifier for the
+// given challenges.
+func (vc *VerifierConfig) NewTranscript(challengesID ...string) (Transcript, error) {
+	return newTranscript(vc.ChallengeTranscript, vc.ChallengeHash, challengesID)
+}
+
 // WithVerifierKZGFoldingHashFunction sets the hash function used for computing
 // the challenge when folding the KZG opening proofs. If not set then by default
 // SHA2-256 is used. Used mainly for compatibility between different systems and
@@ -194,3 +292,42 @@ func WithVerifierKZGFoldingHashFunction(hFunc hash.Hash) VerifierOption {
 		return nil
 	}
 }
+
+// Transcript is a Fiat-Shamir transcript deriving the challenges of an
+// interactive protocol from the values they are bound to. It is implemented by
+// the transcript of gnark-crypto.
+type Transcript interface {
+	// Bind binds the challenge to the value. The challenge can be bound to
+	// several values, in order, until it is computed.
+	Bind(challengeID string, value []byte) error
+	// ComputeChallenge returns the challenge. The challenges must be computed
+	// in the order they were declared.
+	ComputeChallenge(challengeID string) ([]byte, error)
+}
+
+// NewTranscriptFunc returns a new [Transcript] for the given challenges.
+type NewTranscriptFunc func(challengesID ...string) (Transcript, error)
+
+func newTranscript(newTranscript NewTranscriptFunc, h hash.Hash, challengesID []string) (Transcript, error) {
+	if newTranscript != nil {
+		return newTranscript(challengesID...)
+	}
+	return fiatshamir.NewTranscript(h, challengesID...), nil
+}
+
+// BatchVerifyError is returned by the batch verifiers when a proof of the batch
+// is invalid.
+type BatchVerifyError struct {
+	// Index is the index of the first invalid proof in the batch.
+	Index int
+	// Err is the error returned when verifying the proof alone.
+	Err error
+}
+
+func (e *BatchVerifyError) Error() string {
+	return fmt.Sprintf("proof %d of the batch: %v", e.Index, e.Err)
+}
+
+func (e *BatchVerifyError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,296 @@
+// Package ceremony provides a coordinator for the MPC ceremonies of the
+// mpcsetup packages, such as the phases of the Groth16 setup.
+//
+// The coordinator stores the contributions on disk and streams them through
+// the verification function, so that ceremonies larger than the memory of the
+// coordinator can be run (see for example the ContributePhase1Stream and
+// VerifyPhase1Stream functions of the Groth16 mpcsetup packages). Each accepted
+// contribution is recorded in a hash-chained transcript, optionally with an
+// attestation signed by the contributor. The state of the coordinator only
+// lives on disk, so that it can resume after a crash by calling Open again.
+//
+// The directory of a ceremony holds:
+//
+//	transcript.jsonl          the entries of the transcript, one JSON object per line
+//	contribution-000000.bin   the initial state
+//	contribution-000001.bin   the first contribution, and so on
+package ceremony
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// VerifyFunc checks that the contribution read from next is based on the one
+// read from prev.
+type VerifyFunc func(prev, next io.Reader) error
+
+var (
+	ErrNotInitialized     = errors.New("the ceremony is not initialized")
+	ErrAlreadyInitialized = errors.New("the ceremony is already initialized")
+)
+
+const transcriptFile = "transcript.jsonl"
+
+// Coordinator accepts the contributions of a ceremony stored in a directory.
+// It is safe for concurrent use, but a directory must not be opened by two
+// coordinators at once.
+type Coordinator struct {
+	dir     string
+	verify  VerifyFunc
+	entries []Entry
+	mu      sync.Mutex
+}
+
+// Open opens the ceremony stored in dir, creating the directory if needed. If
+// the coordinator crashed while a contribution was submitted, the partial
+// contribution is discarded, and the ceremony resumes from the last one
+// recorded in the transcript.
+func Open(dir string, verify VerifyFunc) (*Coordinator, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	c := &Coordinator{dir: dir, verify: verify}
+	if err := c.readTranscript(); err != nil {
+		return nil, err
+	}
+	if err := VerifyTranscript(c.entries); err != nil {
+		return nil, err
+	}
+
+	// remove the leftovers of an interrupted submission
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		var i int
+		if strings.HasSuffix(f.Name(), ".tmp") || (isContributionFile(f.Name(), &i) && i >= len(c.entries)) {
+			if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// the last contribution must match the transcript
+	if len(c.entries) != 0 {
+		last := c.entries[len(c.entries)-1]
+		f, err := c.Contribution(last.Index)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		digest, err := Digest(f)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(digest, last.Digest) {
+			return nil, fmt.Errorf("contribution %d doesn't match the transcript", last.Index)
+		}
+	}
+
+	return c, nil
+}
+
+// Initialize records the initial state of the ceremony, for example a Phase1
+// returned by InitPhase1 and encoded with WriteTo. It is not verified.
+func (c *Coordinator) Initialize(r io.Reader) (Entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) != 0 {
+		return Entry{}, ErrAlreadyInitialized
+	}
+	return c.add(r, nil)
+}
+
+// Submit verifies a contribution against the last one and records it. The
+// attestation is optional, if not nil it must be the signature of the digest
+// of the contribution.
+func (c *Coordinator) Submit(r io.Reader, attestation *Attestation) (Entry, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) == 0 {
+		return Entry{}, ErrNotInitialized
+	}
+	return c.add(r, attestation)
+}
+
+// Latest opens the last accepted contribution, on which the next one must be
+// based.
+func (c *Coordinator) Latest() (*os.File, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) == 0 {
+		return nil, ErrNotInitialized
+	}
+	return c.Contribution(len(c.entries) - 1)
+}
+
+// Contribution opens the i-th contribution, 0 being the initial state.
+func (c *Coordinator) Contribution(i int) (*os.File, error) {
+	return os.Open(filepath.Join(c.dir, contributionFile(i)))
+}
+
+// Transcript returns the entries of the transcript.
+func (c *Coordinator) Transcript() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := append([]Entry(nil), c.entries...)
+	for i := range entries {
+		if entries[i].Attestation != nil {
+			a := *entries[i].Attestation
+			entries[i].Attestation = &a
+		}
+	}
+	return entries
+}
+
+func (c *Coordinator) add(r io.Reader, attestation *Attestation) (entry Entry, err error) {
+	index := len(c.entries)
+
+	// write the contribution to a temporary file, renamed once accepted
+	tmp, err := os.CreateTemp(c.dir, "contribution-*.tmp")
+	if err != nil {
+		return Entry{}, err
+	}
+	defer func() {
+		tmp.Close()
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	h := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(tmp, h))
+	if _, err = io.Copy(w, r); err != nil {
+		return Entry{}, err
+	}
+	if err = w.Flush(); err != nil {
+		return Entry{}, err
+	}
+	if err = tmp.Sync(); err != nil {
+		return Entry{}, err
+	}
+	digest := h.Sum(nil)
+
+	if attestation != nil {
+		if err = attestation.Verify(digest); err != nil {
+			return Entry{}, err
+		}
+	}
+
+	if index != 0 {
+		if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+			return Entry{}, err
+		}
+		var prev *os.File
+		if prev, err = c.Contribution(index - 1); err != nil {
+			return Entry{}, err
+		}
+		err = c.verify(bufio.NewReader(prev), bufio.NewReader(tmp))
+		prev.Close()
+		if err != nil {
+			return Entry{}, err
+		}
+	}
+
+	if err = tmp.Close(); err != nil {
+		return Entry{}, err
+	}
+	if err = os.Rename(tmp.Name(), filepath.Join(c.dir, contributionFile(index))); err != nil {
+		return Entry{}, err
+	}
+	syncDir(c.dir)
+
+	entry = Entry{
+		Index:       index,
+		Digest:      digest,
+		Attestation: attestation,
+	}
+	if index != 0 {
+		entry.Previous = c.entries[index-1].Hash()
+	}
+	if err = c.appendTranscript(&entry); err != nil {
+		return Entry{}, err
+	}
+	c.entries = append(c.entries, entry)
+
+	return entry, nil
+}
+
+// readTranscript reads the entries of the transcript. A last line without a
+// line feed comes from an interrupted write, and is removed.
+func (c *Coordinator) readTranscript() error {
+	f, err := os.OpenFile(filepath.Join(c.dir, transcriptFile), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) != 0 {
+				return f.Truncate(offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("transcript entry %d: %w", len(c.entries), err)
+		}
+		c.entries = append(c.entries, entry)
+		offset += int64(len(line))
+	}
+}
+
+func (c *Coordinator) appendTranscript(entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(c.dir, transcriptFile), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func contributionFile(i int) string {
+	return fmt.Sprintf("contribution-%06d.bin", i)
+}
+
+func isContributionFile(name string, i *int) bool {
+	n, err := fmt.Sscanf(name, "contribution-%06d.bin", i)
+	return err == nil && n == 1 && name == contributionFile(*i)
+}
+
+// syncDir makes the renaming of a file durable. Not all platforms support it,
+// so errors are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
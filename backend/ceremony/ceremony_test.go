@@ -0,0 +1,90 @@
+package ceremony_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark/backend/ceremony"
+	"github.com/consensys/gnark/backend/groth16/bn254/mpcsetup"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinator(t *testing.T) {
+	assert := require.New(t)
+	dir := t.TempDir()
+
+	c, err := ceremony.Open(dir, mpcsetup.VerifyPhase1Stream)
+	assert.NoError(err)
+	_, err = c.Latest()
+	assert.ErrorIs(err, ceremony.ErrNotInitialized)
+
+	srs := mpcsetup.InitPhase1(6)
+	var buf bytes.Buffer
+	_, err = srs.WriteTo(&buf)
+	assert.NoError(err)
+	_, err = c.Initialize(&buf)
+	assert.NoError(err)
+
+	_, key, err := ed25519.GenerateKey(nil)
+	assert.NoError(err)
+	contribute := func(c *ceremony.Coordinator) []byte {
+		latest, err := c.Latest()
+		assert.NoError(err)
+		defer latest.Close()
+		var buf bytes.Buffer
+		assert.NoError(mpcsetup.ContributePhase1Stream(&buf, latest))
+		return buf.Bytes()
+	}
+
+	// contributions with and without attestations
+	contribution := contribute(c)
+	digest, err := ceremony.Digest(bytes.NewReader(contribution))
+	assert.NoError(err)
+	attestation := ceremony.Attest("alice", key, digest)
+	_, err = c.Submit(bytes.NewReader(contribution), &attestation)
+	assert.NoError(err)
+	_, err = c.Submit(bytes.NewReader(contribute(c)), nil)
+	assert.NoError(err)
+
+	// invalid contributions are rejected
+	_, err = c.Submit(bytes.NewReader(contribution), nil)
+	assert.Error(err)
+	contribution = contribute(c)
+	_, err = c.Submit(bytes.NewReader(contribution), &attestation)
+	assert.Error(err)
+	assert.Len(c.Transcript(), 3)
+
+	// the ceremony resumes after a crash, dropping partial writes
+	assert.NoError(os.WriteFile(filepath.Join(dir, "contribution-123.tmp"), []byte{1}, 0600))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "contribution-000003.bin"), contribution, 0600))
+	f, err := os.OpenFile(filepath.Join(dir, "transcript.jsonl"), os.O_WRONLY|os.O_APPEND, 0600)
+	assert.NoError(err)
+	_, err = f.Write([]byte(`{"index":3,"dig`))
+	assert.NoError(err)
+	assert.NoError(f.Close())
+
+	c, err = ceremony.Open(dir, mpcsetup.VerifyPhase1Stream)
+	assert.NoError(err)
+	transcript := c.Transcript()
+	assert.Len(transcript, 3)
+	assert.NoError(ceremony.VerifyTranscript(transcript))
+	assert.NotNil(transcript[1].Attestation)
+	assert.Nil(transcript[2].Attestation)
+	files, err := os.ReadDir(dir)
+	assert.NoError(err)
+	assert.Len(files, 4)
+
+	_, err = c.Submit(bytes.NewReader(contribution), nil)
+	assert.NoError(err)
+
+	// the transcript can't be rewritten
+	transcript = c.Transcript()
+	transcript[1].Attestation = nil
+	assert.Error(ceremony.VerifyTranscript(transcript))
+	transcript = c.Transcript()
+	transcript[1].Attestation.Contributor = "mallory"
+	assert.Error(ceremony.VerifyTranscript(transcript))
+}
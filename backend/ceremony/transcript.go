@@ -0,0 +1,115 @@
+package ceremony
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Entry is a record of the transcript of a ceremony. Each entry holds the hash
+// of the previous one, so that the transcript can't be rewritten without
+// changing the hash of its last entry.
+type Entry struct {
+	Index       int          `json:"index"`
+	Digest      []byte       `json:"digest"`             // sha256 of the contribution
+	Previous    []byte       `json:"previous,omitempty"` // hash of the previous entry, empty for the initial state
+	Attestation *Attestation `json:"attestation,omitempty"`
+}
+
+// Hash returns the hash of the entry, recorded in the next one.
+func (e *Entry) Hash() []byte {
+	h := sha256.New()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(e.Index))
+	h.Write(buf[:])
+	h.Write(e.Digest)
+	h.Write(e.Previous)
+	if e.Attestation != nil {
+		h.Write(e.Attestation.message(e.Digest))
+		h.Write(e.Attestation.PublicKey)
+		h.Write(e.Attestation.Signature)
+	}
+	return h.Sum(nil)
+}
+
+// Attestation is the signature by a contributor of the digest of their
+// contribution.
+type Attestation struct {
+	Contributor string            `json:"contributor"`
+	PublicKey   ed25519.PublicKey `json:"publicKey"`
+	Signature   []byte            `json:"signature"`
+}
+
+// Attest signs the digest of a contribution (see Digest) on behalf of
+// contributor.
+func Attest(contributor string, key ed25519.PrivateKey, digest []byte) Attestation {
+	a := Attestation{
+		Contributor: contributor,
+		PublicKey:   key.Public().(ed25519.PublicKey),
+	}
+	a.Signature = ed25519.Sign(key, a.message(digest))
+	return a
+}
+
+// Verify checks the signature of the digest of a contribution.
+func (a *Attestation) Verify(digest []byte) error {
+	if len(a.PublicKey) != ed25519.PublicKeySize {
+		return errors.New("invalid attestation public key")
+	}
+	if !ed25519.Verify(a.PublicKey, a.message(digest), a.Signature) {
+		return fmt.Errorf("invalid attestation of %q", a.Contributor)
+	}
+	return nil
+}
+
+// message returns the signed message, binding the contributor name to the
+// digest.
+func (a *Attestation) message(digest []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("gnark ceremony contribution")
+	binary.Write(&buf, binary.BigEndian, uint32(len(a.Contributor)))
+	buf.WriteString(a.Contributor)
+	buf.Write(digest)
+	return buf.Bytes()
+}
+
+// Digest returns the digest of a contribution, as recorded in the transcript
+// and signed in attestations.
+func Digest(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// VerifyTranscript checks that the entries are chained and that their
+// attestations are valid. It doesn't check the contributions themselves, which
+// the coordinator does as they are submitted.
+func VerifyTranscript(entries []Entry) error {
+	for i := range entries {
+		if entries[i].Index != i {
+			return fmt.Errorf("entry %d: unexpected index %d", i, entries[i].Index)
+		}
+		if len(entries[i].Digest) != sha256.Size {
+			return fmt.Errorf("entry %d: invalid digest", i)
+		}
+		var previous []byte
+		if i > 0 {
+			previous = entries[i-1].Hash()
+		}
+		if !bytes.Equal(entries[i].Previous, previous) {
+			return fmt.Errorf("entry %d: doesn't follow the previous entry", i)
+		}
+		if entries[i].Attestation != nil {
+			if err := entries[i].Attestation.Verify(entries[i].Digest); err != nil {
+				return fmt.Errorf("entry %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
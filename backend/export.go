@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+)
+
+// Target is a language in which the verifier of a proof system can be
+// exported, see the Export methods of the verifying keys.
+//
+// The Solidity verifiers are checked on an EVM by the test suite (see
+// test.WithSolidity). The Vyper, Move and Cairo verifiers of Groth16 and PlonK
+// are compiled and executed by the tests with the vypercheck, movecheck and
+// cairocheck build tags, which need the vyper, aptos and scarb toolchains.
+type Target string
+
+const (
+	Solidity Target = "solidity"
+	Vyper    Target = "vyper"
+	Move     Target = "move"
+	Cairo    Target = "cairo"
+)
+
+// ErrUnsupportedTarget is returned when no exporter is registered for a
+// verifying key and a target.
+var ErrUnsupportedTarget = errors.New("unsupported export target")
+
+// ExportFunc writes a verifier contract for the verifying key vk, whose
+// concrete type is the one of the proof system and curve it was registered
+// for (for instance *groth16_bn254.VerifyingKey).
+type ExportFunc func(w io.Writer, vk any) error
+
+type exporterKey struct {
+	id     ID
+	curve  ecc.ID
+	target Target
+}
+
+var (
+	exportersLock sync.RWMutex
+	exporters     = make(map[exporterKey]ExportFunc)
+)
+
+// RegisterExporter registers the exporter of the verifying keys of the proof
+// system id on the given curve to target, replacing the previous one if any.
+//
+// The exporters shipped with gnark are registered by the backend packages, it
+// can be used to plug a new target or to customize an existing one.
+func RegisterExporter(id ID, curve ecc.ID, target Target, fn ExportFunc) {
+	exportersLock.Lock()
+	defer exportersLock.Unlock()
+	exporters[exporterKey{id, curve, target}] = fn
+}
+
+// Export writes a verifier contract for vk using the exporter registered for
+// the proof system id, the curve and the target. It returns an error wrapping
+// ErrUnsupportedTarget if there is none.
+func Export(w io.Writer, vk any, id ID, curve ecc.ID, target Target) error {
+	exportersLock.RLock()
+	fn, ok := exporters[exporterKey{id, curve, target}]
+	exportersLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s for %s on %s", ErrUnsupportedTarget, target, id, curve)
+	}
+	return fn(w, vk)
+}
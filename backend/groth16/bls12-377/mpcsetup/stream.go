@@ -0,0 +1,869 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// streamChunkSize is the maximum number of points held in memory at once when
+// streaming a contribution.
+var streamChunkSize = 1 << 16
+
+// publicKeySize is the size of an encoded PublicKey.
+const publicKeySize = 2*curve.SizeOfG1AffineCompressed + curve.SizeOfG2AffineCompressed
+
+// ContributePhase1Stream reads a Phase1 encoded by WriteTo from r, contributes
+// randomness to it and writes the result to w, encoded as by WriteTo. Unlike
+// Contribute, it only holds a chunk of the parameters in memory at once, so
+// that it can be used on ceremonies that don't fit in memory.
+//
+// r must be seekable as the hash of the previous contribution, which is
+// encoded last, is needed first.
+func ContributePhase1Stream(w io.Writer, r io.ReadSeeker) error {
+	challenge, err := readTrailingHash(r)
+	if err != nil {
+		return err
+	}
+
+	// Generate key pairs
+	var tau, alpha, beta, one fr.Element
+	tau.SetRandom()
+	alpha.SetRandom()
+	beta.SetRandom()
+	one.SetOne()
+	publicKeys := []PublicKey{
+		newPublicKey(tau, challenge, 1),
+		newPublicKey(alpha, challenge, 2),
+		newPublicKey(beta, challenge, 3),
+	}
+
+	sha := sha256.New()
+	hw := io.MultiWriter(w, sha)
+	if err := writePublicKeys(hw, publicKeys...); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, r, 3*publicKeySize); err != nil {
+		return err
+	}
+
+	// Update the powers of τ, ατ and βτ chunk by chunk
+	if err := scaleG1Stream(hw, r, one, tau); err != nil {
+		return err
+	}
+	if err := scaleG1Stream(hw, r, alpha, tau); err != nil {
+		return err
+	}
+	if err := scaleG1Stream(hw, r, beta, tau); err != nil {
+		return err
+	}
+	if err := scaleG2Stream(hw, r, one, tau); err != nil {
+		return err
+	}
+	var betaG2 curve.G2Affine
+	if err := readPoint(r, &betaG2); err != nil {
+		return err
+	}
+	var betaBI big.Int
+	beta.BigInt(&betaBI)
+	betaG2.ScalarMultiplication(&betaG2, &betaBI)
+	if err := curve.NewEncoder(hw).Encode(&betaG2); err != nil {
+		return err
+	}
+
+	_, err = w.Write(sha.Sum(nil))
+	return err
+}
+
+// VerifyPhase1Stream checks that the Phase1 encoded in next is based on the one
+// encoded in prev, as VerifyPhase1 does, without holding them in memory.
+func VerifyPhase1Stream(prev, next io.Reader) error {
+	// Read the parameters of the previous contribution needed for the checks
+	var current struct {
+		tau1, alphaTau0, betaTau0 curve.G1Affine
+		tau2, beta2               curve.G2Affine
+		nbG1, nbG2                int
+		hash                      []byte
+	}
+	if _, err := io.CopyN(io.Discard, prev, 3*publicKeySize); err != nil {
+		return err
+	}
+	var err error
+	if current.nbG1, err = readG1At(prev, 1, &current.tau1); err != nil {
+		return err
+	}
+	if _, err = readG1At(prev, 0, &current.alphaTau0); err != nil {
+		return err
+	}
+	if _, err = readG1At(prev, 0, &current.betaTau0); err != nil {
+		return err
+	}
+	if current.nbG2, err = readG2At(prev, 1, &current.tau2); err != nil {
+		return err
+	}
+	if err = readPoint(prev, &current.beta2); err != nil {
+		return err
+	}
+	current.hash = make([]byte, sha256.Size)
+	if _, err = io.ReadFull(prev, current.hash); err != nil {
+		return err
+	}
+
+	// Read the contribution chunk by chunk, accumulating random linear
+	// combinations of the powers
+	sha := sha256.New()
+	tr := io.TeeReader(next, sha)
+	publicKeys, err := readPublicKeys(tr, 3)
+	if err != nil {
+		return err
+	}
+	var (
+		tau1, alphaTau0, betaTau0 curve.G1Affine
+		tau2, beta2               curve.G2Affine
+		tauLC, alphaLC, betaLC    linearCombinationG1Stream
+		tau2LC                    linearCombinationG2Stream
+	)
+	nbG1, err := readG1Stream(tr, func(start, n int, points []curve.G1Affine) {
+		if start <= 1 && 1 < start+len(points) {
+			tau1 = points[1-start]
+		}
+		tauLC.add(start, n, points)
+	})
+	if err != nil {
+		return err
+	}
+	nbAlpha, err := readG1Stream(tr, func(start, n int, points []curve.G1Affine) {
+		if start == 0 {
+			alphaTau0 = points[0]
+		}
+		alphaLC.add(start, n, points)
+	})
+	if err != nil {
+		return err
+	}
+	nbBeta, err := readG1Stream(tr, func(start, n int, points []curve.G1Affine) {
+		if start == 0 {
+			betaTau0 = points[0]
+		}
+		betaLC.add(start, n, points)
+	})
+	if err != nil {
+		return err
+	}
+	nbG2, err := readG2Stream(tr, func(start, n int, points []curve.G2Affine) {
+		if start <= 1 && 1 < start+len(points) {
+			tau2 = points[1-start]
+		}
+		tau2LC.add(start, n, points)
+	})
+	if err != nil {
+		return err
+	}
+	if err = readPoint(tr, &beta2); err != nil {
+		return err
+	}
+	hash := make([]byte, sha256.Size)
+	if _, err = io.ReadFull(next, hash); err != nil {
+		return err
+	}
+
+	if nbG1 != current.nbG1 || nbG2 != current.nbG2 || nbAlpha != nbG2 || nbBeta != nbG2 || nbG1 != 2*nbG2-1 || nbG2 < 2 {
+		return errors.New("the contribution doesn't have the same size as the previous one")
+	}
+
+	// Compute R for τ, α, β
+	tauR := genR(publicKeys[0].SG, publicKeys[0].SXG, current.hash, 1)
+	alphaR := genR(publicKeys[1].SG, publicKeys[1].SXG, current.hash, 2)
+	betaR := genR(publicKeys[2].SG, publicKeys[2].SXG, current.hash, 3)
+
+	// Check for knowledge of toxic parameters
+	if !sameRatio(publicKeys[0].SG, publicKeys[0].SXG, publicKeys[0].XR, tauR) {
+		return errors.New("couldn't verify public key of τ")
+	}
+	if !sameRatio(publicKeys[1].SG, publicKeys[1].SXG, publicKeys[1].XR, alphaR) {
+		return errors.New("couldn't verify public key of α")
+	}
+	if !sameRatio(publicKeys[2].SG, publicKeys[2].SXG, publicKeys[2].XR, betaR) {
+		return errors.New("couldn't verify public key of β")
+	}
+
+	// Check for valid updates using previous parameters
+	if !sameRatio(tau1, current.tau1, tauR, publicKeys[0].XR) {
+		return errors.New("couldn't verify that [τ]₁ is based on previous contribution")
+	}
+	if !sameRatio(alphaTau0, current.alphaTau0, alphaR, publicKeys[1].XR) {
+		return errors.New("couldn't verify that [α]₁ is based on previous contribution")
+	}
+	if !sameRatio(betaTau0, current.betaTau0, betaR, publicKeys[2].XR) {
+		return errors.New("couldn't verify that [β]₁ is based on previous contribution")
+	}
+	if !sameRatio(publicKeys[0].SG, publicKeys[0].SXG, tau2, current.tau2) {
+		return errors.New("couldn't verify that [τ]₂ is based on previous contribution")
+	}
+	if !sameRatio(publicKeys[2].SG, publicKeys[2].SXG, beta2, current.beta2) {
+		return errors.New("couldn't verify that [β]₂ is based on previous contribution")
+	}
+
+	// Check for valid updates using powers of τ
+	_, _, g1, g2 := curve.Generators()
+	tauL1, tauL2 := tauLC.result()
+	if !sameRatio(tauL1, tauL2, tau2, g2) {
+		return errors.New("couldn't verify valid powers of τ in G₁")
+	}
+	alphaL1, alphaL2 := alphaLC.result()
+	if !sameRatio(alphaL1, alphaL2, tau2, g2) {
+		return errors.New("couldn't verify valid powers of α(τ) in G₁")
+	}
+	betaL1, betaL2 := betaLC.result()
+	if !sameRatio(betaL1, betaL2, tau2, g2) {
+		return errors.New("couldn't verify valid powers of β(τ) in G₁")
+	}
+	tau2L1, tau2L2 := tau2LC.result()
+	if !sameRatio(tau1, g1, tau2L1, tau2L2) {
+		return errors.New("couldn't verify valid powers of τ in G₂")
+	}
+
+	// Check hash of the contribution
+	if !bytes.Equal(sha.Sum(nil), hash) {
+		return errors.New("couldn't verify hash of contribution")
+	}
+
+	return nil
+}
+
+// ContributePhase2Stream reads a Phase2 encoded by WriteTo from r, contributes
+// randomness to it and writes the result to w, encoded as by WriteTo. Unlike
+// Contribute, it only holds a chunk of the parameters in memory at once.
+//
+// r must be seekable as the hash of the previous contribution, which is
+// encoded last, is needed first.
+func ContributePhase2Stream(w io.Writer, r io.ReadSeeker) error {
+	challenge, err := readTrailingHash(r)
+	if err != nil {
+		return err
+	}
+
+	// Sample toxic δ and σ
+	var delta, deltaInv, sigma, one fr.Element
+	delta.SetRandom()
+	deltaInv.Inverse(&delta)
+	sigma.SetRandom()
+	one.SetOne()
+
+	sha := sha256.New()
+	hw := io.MultiWriter(w, sha)
+	if err := writePublicKeys(hw, newPublicKey(delta, challenge, 1), newPublicKey(sigma, challenge, 2)); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(io.Discard, r, 2*publicKeySize); err != nil {
+		return err
+	}
+
+	// Update δ, and L and Z using δ⁻¹
+	var deltaBI, sigmaBI big.Int
+	delta.BigInt(&deltaBI)
+	sigma.BigInt(&sigmaBI)
+	var deltaG1 curve.G1Affine
+	if err := readPoint(r, &deltaG1); err != nil {
+		return err
+	}
+	deltaG1.ScalarMultiplication(&deltaG1, &deltaBI)
+	if err := curve.NewEncoder(hw).Encode(&deltaG1); err != nil {
+		return err
+	}
+	if err := scaleG1Stream(hw, r, deltaInv, one); err != nil {
+		return err
+	}
+	if err := scaleG1Stream(hw, r, deltaInv, one); err != nil {
+		return err
+	}
+
+	// Update the commitment keys using σ
+	var nbCommitments uint32
+	if err := binary.Read(r, binary.BigEndian, &nbCommitments); err != nil {
+		return err
+	}
+	if err := binary.Write(hw, binary.BigEndian, nbCommitments); err != nil {
+		return err
+	}
+	for i := uint32(0); i < nbCommitments; i++ {
+		if err := scaleG1Stream(hw, r, sigma, one); err != nil {
+			return err
+		}
+	}
+
+	var deltaG2, sigmaG2 curve.G2Affine
+	if err := readPoint(r, &deltaG2); err != nil {
+		return err
+	}
+	if err := readPoint(r, &sigmaG2); err != nil {
+		return err
+	}
+	deltaG2.ScalarMultiplication(&deltaG2, &deltaBI)
+	sigmaG2.ScalarMultiplication(&sigmaG2, &sigmaBI)
+	enc := curve.NewEncoder(hw)
+	if err := enc.Encode(&deltaG2); err != nil {
+		return err
+	}
+	if err := enc.Encode(&sigmaG2); err != nil {
+		return err
+	}
+
+	_, err = w.Write(sha.Sum(nil))
+	return err
+}
+
+// VerifyPhase2Stream checks that the Phase2 encoded in next is based on the one
+// encoded in prev, as VerifyPhase2 does, without holding them in memory.
+func VerifyPhase2Stream(prev, next io.Reader) error {
+	sha := sha256.New()
+	tr := io.TeeReader(next, sha)
+
+	if _, err := io.CopyN(io.Discard, prev, 2*publicKeySize); err != nil {
+		return err
+	}
+	publicKeys, err := readPublicKeys(tr, 2)
+	if err != nil {
+		return err
+	}
+	var prevDeltaG1, deltaG1 curve.G1Affine
+	if err := readPoint(prev, &prevDeltaG1); err != nil {
+		return err
+	}
+	if err := readPoint(tr, &deltaG1); err != nil {
+		return err
+	}
+
+	// Read L, Z and the commitment keys of both contributions in lockstep,
+	// accumulating the same random linear combinations of their points
+	var L, Z mergeStream
+	if err := L.read(prev, tr); err != nil {
+		return err
+	}
+	if err := Z.read(prev, tr); err != nil {
+		return err
+	}
+	var prevNbCommitments, nbCommitments uint32
+	if err := binary.Read(prev, binary.BigEndian, &prevNbCommitments); err != nil {
+		return err
+	}
+	if err := binary.Read(tr, binary.BigEndian, &nbCommitments); err != nil {
+		return err
+	}
+	if nbCommitments != prevNbCommitments {
+		return errors.New("couldn't verify that the commitment keys are based on previous contribution")
+	}
+	sigmaCKK := make([]mergeStream, nbCommitments)
+	for i := range sigmaCKK {
+		if err := sigmaCKK[i].read(prev, tr); err != nil {
+			return err
+		}
+	}
+
+	var prevDeltaG2, prevSigmaG2, deltaG2, sigmaG2 curve.G2Affine
+	if err := readPoint(prev, &prevDeltaG2); err != nil {
+		return err
+	}
+	if err := readPoint(prev, &prevSigmaG2); err != nil {
+		return err
+	}
+	if err := readPoint(tr, &deltaG2); err != nil {
+		return err
+	}
+	if err := readPoint(tr, &sigmaG2); err != nil {
+		return err
+	}
+	challenge := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(prev, challenge); err != nil {
+		return err
+	}
+	hash := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(next, hash); err != nil {
+		return err
+	}
+
+	// Compute R for δ and σ
+	deltaR := genR(publicKeys[0].SG, publicKeys[0].SXG, challenge, 1)
+	sigmaR := genR(publicKeys[1].SG, publicKeys[1].SXG, challenge, 2)
+
+	// Check for knowledge of δ and σ
+	if !sameRatio(publicKeys[0].SG, publicKeys[0].SXG, publicKeys[0].XR, deltaR) {
+		return errors.New("couldn't verify knowledge of δ")
+	}
+	if !sameRatio(publicKeys[1].SG, publicKeys[1].SXG, publicKeys[1].XR, sigmaR) {
+		return errors.New("couldn't verify knowledge of σ")
+	}
+
+	// Check for valid updates using previous parameters
+	if !sameRatio(deltaG1, prevDeltaG1, deltaR, publicKeys[0].XR) {
+		return errors.New("couldn't verify that [δ]₁ is based on previous contribution")
+	}
+	if !sameRatio(publicKeys[0].SG, publicKeys[0].SXG, deltaG2, prevDeltaG2) {
+		return errors.New("couldn't verify that [δ]₂ is based on previous contribution")
+	}
+	if !sameRatio(publicKeys[1].SG, publicKeys[1].SXG, sigmaG2, prevSigmaG2) {
+		return errors.New("couldn't verify that [σ]₂ is based on previous contribution")
+	}
+	for i := range sigmaCKK {
+		if !sigmaCKK[i].sameRatio(prevSigmaG2, sigmaG2) {
+			return errors.New("couldn't verify valid updates of the commitment keys using σ")
+		}
+	}
+
+	// Check for valid updates of L and Z using δ⁻¹
+	if !L.sameRatio(deltaG2, prevDeltaG2) {
+		return errors.New("couldn't verify valid updates of L using δ⁻¹")
+	}
+	if !Z.sameRatio(deltaG2, prevDeltaG2) {
+		return errors.New("couldn't verify valid updates of Z using δ⁻¹")
+	}
+
+	// Check hash of the contribution
+	if !bytes.Equal(sha.Sum(nil), hash) {
+		return errors.New("couldn't verify hash of contribution")
+	}
+
+	return nil
+}
+
+// mergeStream accumulates a = ∑ rᵢAᵢ, b = ∑ rᵢBᵢ (see merge) over two slices of
+// points read in lockstep, A from the contribution and B from the previous one.
+type mergeStream struct {
+	a, b curve.G1Jac
+}
+
+func (m *mergeStream) read(prev, next io.Reader) error {
+	nbPrev, err := readSliceLen(prev)
+	if err != nil {
+		return err
+	}
+	n, err := readSliceLen(next)
+	if err != nil {
+		return err
+	}
+	if n != nbPrev {
+		return errors.New("the contribution doesn't have the same size as the previous one")
+	}
+	prevPoints := newG1Chunk(n)
+	points := newG1Chunk(n)
+	for start := 0; start < n; start += streamChunkSize {
+		k := n - start
+		if k > streamChunkSize {
+			k = streamChunkSize
+		}
+		if err := prevPoints.read(prev, k); err != nil {
+			return err
+		}
+		if err := points.read(next, k); err != nil {
+			return err
+		}
+		r := make([]fr.Element, k)
+		for i := range r {
+			r[i].SetRandom()
+		}
+		var a, b curve.G1Jac
+		a.MultiExp(points.points[:k], r, ecc.MultiExpConfig{})
+		b.MultiExp(prevPoints.points[:k], r, ecc.MultiExpConfig{})
+		m.a.AddAssign(&a)
+		m.b.AddAssign(&b)
+	}
+	return nil
+}
+
+// sameRatio checks e(a, x₂) = e(b, y₂).
+func (m *mergeStream) sameRatio(x2, y2 curve.G2Affine) bool {
+	var a, b curve.G1Affine
+	a.FromJacobian(&m.a)
+	b.FromJacobian(&m.b)
+	return sameRatio(a, b, x2, y2)
+}
+
+// linearCombinationG1Stream accumulates L1 = ∑ rᵢAᵢ, L2 = ∑ rᵢAᵢ₊₁ (see
+// linearCombinationG1) over a slice of points read chunk by chunk.
+type linearCombinationG1Stream struct {
+	L1, L2 curve.G1Jac
+	last   fr.Element // r of the last point of the previous chunk
+}
+
+// add accumulates the chunk A of a slice of n points, A[0] being at index start.
+func (lc *linearCombinationG1Stream) add(start, n int, A []curve.G1Affine) {
+	// r[j] is the coefficient of A[j] in L1 and of A[j+1] in L2
+	k := len(A)
+	r := make([]fr.Element, k+1)
+	r[0] = lc.last
+	for j := 1; j <= k; j++ {
+		r[j].SetRandom()
+	}
+	lc.last = r[k]
+
+	var tmp curve.G1Jac
+	l1, l2 := A, A
+	r1, r2 := r[1:], r[:k]
+	if start+k == n { // the last point is not in L1
+		l1, r1 = l1[:k-1], r1[:k-1]
+	}
+	if start == 0 { // the first point is not in L2
+		l2, r2 = l2[1:], r2[1:]
+	}
+	if len(l1) != 0 {
+		tmp.MultiExp(l1, r1, ecc.MultiExpConfig{})
+		lc.L1.AddAssign(&tmp)
+	}
+	if len(l2) != 0 {
+		tmp.MultiExp(l2, r2, ecc.MultiExpConfig{})
+		lc.L2.AddAssign(&tmp)
+	}
+}
+
+func (lc *linearCombinationG1Stream) result() (L1, L2 curve.G1Affine) {
+	L1.FromJacobian(&lc.L1)
+	L2.FromJacobian(&lc.L2)
+	return
+}
+
+// linearCombinationG2Stream accumulates L1 = ∑ rᵢAᵢ, L2 = ∑ rᵢAᵢ₊₁ (see
+// linearCombinationG2) over a slice of points read chunk by chunk.
+type linearCombinationG2Stream struct {
+	L1, L2 curve.G2Jac
+	last   fr.Element // r of the last point of the previous chunk
+}
+
+// add accumulates the chunk A of a slice of n points, A[0] being at index start.
+func (lc *linearCombinationG2Stream) add(start, n int, A []curve.G2Affine) {
+	// r[j] is the coefficient of A[j] in L1 and of A[j+1] in L2
+	k := len(A)
+	r := make([]fr.Element, k+1)
+	r[0] = lc.last
+	for j := 1; j <= k; j++ {
+		r[j].SetRandom()
+	}
+	lc.last = r[k]
+
+	var tmp curve.G2Jac
+	l1, l2 := A, A
+	r1, r2 := r[1:], r[:k]
+	if start+k == n { // the last point is not in L1
+		l1, r1 = l1[:k-1], r1[:k-1]
+	}
+	if start == 0 { // the first point is not in L2
+		l2, r2 = l2[1:], r2[1:]
+	}
+	if len(l1) != 0 {
+		tmp.MultiExp(l1, r1, ecc.MultiExpConfig{})
+		lc.L1.AddAssign(&tmp)
+	}
+	if len(l2) != 0 {
+		tmp.MultiExp(l2, r2, ecc.MultiExpConfig{})
+		lc.L2.AddAssign(&tmp)
+	}
+}
+
+func (lc *linearCombinationG2Stream) result() (L1, L2 curve.G2Affine) {
+	L1.FromJacobian(&lc.L1)
+	L2.FromJacobian(&lc.L2)
+	return
+}
+
+// scaleG1Stream reads a slice of points {A₀, A₁, …} encoded by a curve.Encoder
+// from r, and writes {aA₀, axA₁, ax²A₂, …} to w with the same encoding.
+func scaleG1Stream(w io.Writer, r io.Reader, a, x fr.Element) error {
+	n, err := readSliceLen(r)
+	if err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(n)); err != nil {
+		return err
+	}
+	chunk := newG1Chunk(n)
+	scale := a // axˢᵗᵃʳᵗ
+	for start := 0; start < n; start += streamChunkSize {
+		k := n - start
+		if k > streamChunkSize {
+			k = streamChunkSize
+		}
+		if err := chunk.read(r, k); err != nil {
+			return err
+		}
+		scalars := powers(x, k)
+		utils.Parallelize(k, func(start, end int) {
+			var tmp big.Int
+			for i := start; i < end; i++ {
+				scalars[i].Mul(&scalars[i], &scale)
+				scalars[i].BigInt(&tmp)
+				chunk.points[i].ScalarMultiplication(&chunk.points[i], &tmp)
+				b := chunk.points[i].Bytes()
+				copy(chunk.buf[i*curve.SizeOfG1AffineCompressed:], b[:])
+			}
+		})
+		scale.Mul(&scalars[k-1], &x)
+		if _, err := w.Write(chunk.buf[:k*curve.SizeOfG1AffineCompressed]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scaleG2Stream reads a slice of points {A₀, A₁, …} encoded by a curve.Encoder
+// from r, and writes {aA₀, axA₁, ax²A₂, …} to w with the same encoding.
+func scaleG2Stream(w io.Writer, r io.Reader, a, x fr.Element) error {
+	n, err := readSliceLen(r)
+	if err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint32(n)); err != nil {
+		return err
+	}
+	chunk := newG2Chunk(n)
+	scale := a // axˢᵗᵃʳᵗ
+	for start := 0; start < n; start += streamChunkSize {
+		k := n - start
+		if k > streamChunkSize {
+			k = streamChunkSize
+		}
+		if err := chunk.read(r, k); err != nil {
+			return err
+		}
+		scalars := powers(x, k)
+		utils.Parallelize(k, func(start, end int) {
+			var tmp big.Int
+			for i := start; i < end; i++ {
+				scalars[i].Mul(&scalars[i], &scale)
+				scalars[i].BigInt(&tmp)
+				chunk.points[i].ScalarMultiplication(&chunk.points[i], &tmp)
+				b := chunk.points[i].Bytes()
+				copy(chunk.buf[i*curve.SizeOfG2AffineCompressed:], b[:])
+			}
+		})
+		scale.Mul(&scalars[k-1], &x)
+		if _, err := w.Write(chunk.buf[:k*curve.SizeOfG2AffineCompressed]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readG1Stream reads a slice of points encoded by a curve.Encoder from r, and
+// calls f on each chunk, with the index of its first point and the length of
+// the slice. It returns the length of the slice.
+func readG1Stream(r io.Reader, f func(start, n int, points []curve.G1Affine)) (int, error) {
+	n, err := readSliceLen(r)
+	if err != nil {
+		return 0, err
+	}
+	chunk := newG1Chunk(n)
+	for start := 0; start < n; start += streamChunkSize {
+		k := n - start
+		if k > streamChunkSize {
+			k = streamChunkSize
+		}
+		if err := chunk.read(r, k); err != nil {
+			return 0, err
+		}
+		f(start, n, chunk.points[:k])
+	}
+	return n, nil
+}
+
+// readG2Stream reads a slice of points encoded by a curve.Encoder from r, and
+// calls f on each chunk, with the index of its first point and the length of
+// the slice. It returns the length of the slice.
+func readG2Stream(r io.Reader, f func(start, n int, points []curve.G2Affine)) (int, error) {
+	n, err := readSliceLen(r)
+	if err != nil {
+		return 0, err
+	}
+	chunk := newG2Chunk(n)
+	for start := 0; start < n; start += streamChunkSize {
+		k := n - start
+		if k > streamChunkSize {
+			k = streamChunkSize
+		}
+		if err := chunk.read(r, k); err != nil {
+			return 0, err
+		}
+		f(start, n, chunk.points[:k])
+	}
+	return n, nil
+}
+
+// readG1At reads a slice of points encoded by a curve.Encoder from r, only
+// decoding the point at index i. It returns the length of the slice.
+func readG1At(r io.Reader, i int, p *curve.G1Affine) (int, error) {
+	n, err := readSliceLen(r)
+	if err != nil {
+		return 0, err
+	}
+	if i >= n {
+		return 0, fmt.Errorf("expected at least %d points, got %d", i+1, n)
+	}
+	if _, err := io.CopyN(io.Discard, r, int64(i*curve.SizeOfG1AffineCompressed)); err != nil {
+		return 0, err
+	}
+	if err := readPoint(r, p); err != nil {
+		return 0, err
+	}
+	_, err = io.CopyN(io.Discard, r, int64((n-i-1)*curve.SizeOfG1AffineCompressed))
+	return n, err
+}
+
+// readG2At reads a slice of points encoded by a curve.Encoder from r, only
+// decoding the point at index i. It returns the length of the slice.
+func readG2At(r io.Reader, i int, p *curve.G2Affine) (int, error) {
+	n, err := readSliceLen(r)
+	if err != nil {
+		return 0, err
+	}
+	if i >= n {
+		return 0, fmt.Errorf("expected at least %d points, got %d", i+1, n)
+	}
+	if _, err := io.CopyN(io.Discard, r, int64(i*curve.SizeOfG2AffineCompressed)); err != nil {
+		return 0, err
+	}
+	if err := readPoint(r, p); err != nil {
+		return 0, err
+	}
+	_, err = io.CopyN(io.Discard, r, int64((n-i-1)*curve.SizeOfG2AffineCompressed))
+	return n, err
+}
+
+// g1Chunk holds a chunk of compressed points and their decoding.
+type g1Chunk struct {
+	buf    []byte
+	points []curve.G1Affine
+}
+
+func newG1Chunk(n int) g1Chunk {
+	if n > streamChunkSize {
+		n = streamChunkSize
+	}
+	return g1Chunk{
+		buf:    make([]byte, n*curve.SizeOfG1AffineCompressed),
+		points: make([]curve.G1Affine, n),
+	}
+}
+
+// read reads and decodes k compressed points.
+func (c *g1Chunk) read(r io.Reader, k int) error {
+	if _, err := io.ReadFull(r, c.buf[:k*curve.SizeOfG1AffineCompressed]); err != nil {
+		return err
+	}
+	errs := make([]error, k)
+	utils.Parallelize(k, func(start, end int) {
+		for i := start; i < end; i++ {
+			nbBytes, err := c.points[i].SetBytes(c.buf[i*curve.SizeOfG1AffineCompressed : (i+1)*curve.SizeOfG1AffineCompressed])
+			if err == nil && nbBytes != curve.SizeOfG1AffineCompressed {
+				err = errors.New("invalid compressed point")
+			}
+			errs[i] = err
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// g2Chunk holds a chunk of compressed points and their decoding.
+type g2Chunk struct {
+	buf    []byte
+	points []curve.G2Affine
+}
+
+func newG2Chunk(n int) g2Chunk {
+	if n > streamChunkSize {
+		n = streamChunkSize
+	}
+	return g2Chunk{
+		buf:    make([]byte, n*curve.SizeOfG2AffineCompressed),
+		points: make([]curve.G2Affine, n),
+	}
+}
+
+// read reads and decodes k compressed points.
+func (c *g2Chunk) read(r io.Reader, k int) error {
+	if _, err := io.ReadFull(r, c.buf[:k*curve.SizeOfG2AffineCompressed]); err != nil {
+		return err
+	}
+	errs := make([]error, k)
+	utils.Parallelize(k, func(start, end int) {
+		for i := start; i < end; i++ {
+			nbBytes, err := c.points[i].SetBytes(c.buf[i*curve.SizeOfG2AffineCompressed : (i+1)*curve.SizeOfG2AffineCompressed])
+			if err == nil && nbBytes != curve.SizeOfG2AffineCompressed {
+				err = errors.New("invalid compressed point")
+			}
+			errs[i] = err
+		}
+	})
+	return errors.Join(errs...)
+}
+
+func readSliceLen(r io.Reader) (int, error) {
+	var n uint32
+	err := binary.Read(r, binary.BigEndian, &n)
+	return int(n), err
+}
+
+// readPoint decodes a single point encoded by a curve.Encoder.
+func readPoint(r io.Reader, p interface{}) error {
+	return curve.NewDecoder(r).Decode(p)
+}
+
+func readPublicKeys(r io.Reader, n int) ([]PublicKey, error) {
+	res := make([]PublicKey, n)
+	dec := curve.NewDecoder(r)
+	for i := range res {
+		for _, v := range []interface{}{&res[i].SG, &res[i].SXG, &res[i].XR} {
+			if err := dec.Decode(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+func writePublicKeys(w io.Writer, publicKeys ...PublicKey) error {
+	enc := curve.NewEncoder(w)
+	for i := range publicKeys {
+		for _, v := range []interface{}{&publicKeys[i].SG, &publicKeys[i].SXG, &publicKeys[i].XR} {
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readTrailingHash returns the hash encoded at the end of r, and rewinds r.
+func readTrailingHash(r io.ReadSeeker) ([]byte, error) {
+	if _, err := r.Seek(-sha256.Size, io.SeekEnd); err != nil {
+		return nil, err
+	}
+	hash := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(r, hash); err != nil {
+		return nil, err
+	}
+	_, err := r.Seek(0, io.SeekStart)
+	return hash, err
+}
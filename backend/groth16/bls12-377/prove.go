@@ -69,6 +69,14 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		opt.HashToFieldFn = hash_to_field.New([]byte(constraint.CommitmentDst))
 	}
 
+	// the weights are rough estimates of the relative costs of the phases
+	progress := opt.NewProgressReporter(
+		backend.PhaseWeight{Phase: backend.PhaseSolve, Weight: 2},
+		backend.PhaseWeight{Phase: backend.PhaseCommitments, Weight: 0.5},
+		backend.PhaseWeight{Phase: backend.PhaseFFT, Weight: 2},
+		backend.PhaseWeight{Phase: backend.PhaseMSM, Weight: 5},
+	)
+
 	log := logger.Logger().With().Str("curve", r1cs.CurveID().String()).Str("acceleration", "none").Int("nbConstraints", r1cs.GetNbConstraints()).Str("backend", "groth16").Logger()
 
 	commitmentInfo := r1cs.CommitmentInfo.(constraint.Groth16Commitments)
@@ -76,6 +84,7 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 	proof := &Proof{Commitments: make([]curve.G1Affine, len(commitmentInfo))}
 
 	solverOpts := opt.SolverOpts[:len(opt.SolverOpts):len(opt.SolverOpts)]
+	solverOpts = append(solverOpts, solver.WithContext(opt.Ctx))
 
 	privateCommittedValues := make([][]fr.Element, len(commitmentInfo))
 
@@ -109,11 +118,17 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		return nil
 	}))
 
+	progress.Start(backend.PhaseSolve, 1)
 	_solution, err := r1cs.Solve(fullWitness, solverOpts...)
 	if err != nil {
+		if errCtx := opt.CheckContext(backend.PhaseSolve); errCtx != nil {
+			return nil, errCtx
+		}
 		return nil, err
 	}
 
+	progress.Step(backend.PhaseSolve)
+
 	solution := _solution.(*cs.R1CSSolution)
 	wireValues := []fr.Element(solution.W)
 
@@ -124,19 +139,25 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		copy(commitmentsSerialized[fr.Bytes*i:], wireValues[commitmentInfo[i].CommitmentIndex].Marshal())
 	}
 
+	progress.Start(backend.PhaseCommitments, 1)
 	if proof.CommitmentPok, err = pedersen.BatchProve(pk.CommitmentKeys, privateCommittedValues, commitmentsSerialized); err != nil {
 		return nil, err
 	}
+	if err := opt.CheckContext(backend.PhaseCommitments); err != nil {
+		return nil, err
+	}
+	progress.Step(backend.PhaseCommitments)
 
 	// H (witness reduction / FFT part)
 	var h []fr.Element
-	chHDone := make(chan struct{}, 1)
+	chHDone := make(chan error, 1)
 	go func() {
-		h = computeH(solution.A, solution.B, solution.C, &pk.Domain)
+		var err error
+		h, err = computeH(solution.A, solution.B, solution.C, &pk.Domain, &opt, progress)
 		solution.A = nil
 		solution.B = nil
 		solution.C = nil
-		chHDone <- struct{}{}
+		chHDone <- err
 	}()
 
 	// we need to copy and filter the wireValues for each multi exp
@@ -191,6 +212,11 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 	chBs1Done := make(chan error, 1)
 	computeBS1 := func() {
 		<-chWireValuesB
+		if err := opt.CheckContext(backend.PhaseMSM); err != nil {
+			chBs1Done <- err
+			close(chBs1Done)
+			return
+		}
 		if _, err := bs1.MultiExp(pk.G1.B, wireValuesB, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
 			chBs1Done <- err
 			close(chBs1Done)
@@ -198,12 +224,18 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		}
 		bs1.AddMixed(&pk.G1.Beta)
 		bs1.AddMixed(&deltas[1])
+		progress.Step(backend.PhaseMSM)
 		chBs1Done <- nil
 	}
 
 	chArDone := make(chan error, 1)
 	computeAR1 := func() {
 		<-chWireValuesA
+		if err := opt.CheckContext(backend.PhaseMSM); err != nil {
+			chArDone <- err
+			close(chArDone)
+			return
+		}
 		if _, err := ar.MultiExp(pk.G1.A, wireValuesA, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
 			chArDone <- err
 			close(chArDone)
@@ -212,6 +244,7 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		ar.AddMixed(&pk.G1.Alpha)
 		ar.AddMixed(&deltas[0])
 		proof.Ar.FromJacobian(&ar)
+		progress.Step(backend.PhaseMSM)
 		chArDone <- nil
 	}
 
@@ -234,6 +267,10 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		toRemove = append(toRemove, commitmentInfo.CommitmentIndexes())
 		_wireValues := filterHeap(wireValues[r1cs.GetNbPublicVariables():], r1cs.GetNbPublicVariables(), internal.ConcatAll(toRemove...))
 
+		if err := opt.CheckContext(backend.PhaseMSM); err != nil {
+			chKrsDone <- err
+			return
+		}
 		if _, err := krs.MultiExp(pk.G1.K, _wireValues, ecc.MultiExpConfig{NbTasks: n / 2}); err != nil {
 			chKrsDone <- err
 			return
@@ -267,6 +304,7 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		}
 
 		proof.Krs.FromJacobian(&krs)
+		progress.Step(backend.PhaseMSM)
 		chKrsDone <- nil
 	}
 
@@ -280,6 +318,9 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 			nbTasks *= 2
 		}
 		<-chWireValuesB
+		if err := opt.CheckContext(backend.PhaseMSM); err != nil {
+			return err
+		}
 		if _, err := Bs.MultiExp(pk.G2.B, wireValuesB, ecc.MultiExpConfig{NbTasks: nbTasks}); err != nil {
 			return err
 		}
@@ -290,13 +331,17 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		Bs.AddMixed(&pk.G2.Beta)
 
 		proof.Bs.FromJacobian(&Bs)
+		progress.Step(backend.PhaseMSM)
 		return nil
 	}
 
 	// wait for FFT to end, as it uses all our CPUs
-	<-chHDone
+	if err := <-chHDone; err != nil {
+		return nil, err
+	}
 
 	// schedule our proof part computations
+	progress.Start(backend.PhaseMSM, 4)
 	go computeKRS()
 	go computeAR1()
 	go computeBS1()
@@ -343,7 +388,9 @@ func filterHeap(slice []fr.Element, sliceFirstIndex int, toRemove []int) (r []fr
 	return
 }
 
-func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
+// computeH returns the coefficients of the quotient polynomial H. It checks the
+// context of the prover and reports the progress between the FFTs.
+func computeH(a, b, c []fr.Element, domain *fft.Domain, opt *backend.ProverConfig, progress *backend.ProgressReporter) ([]fr.Element, error) {
 	// H part of Krs
 	// Compute H (hz=ab-c, where z=-2 on ker X^n+1 (z(x)=x^n-1))
 	// 	1 - _a = ifft(a), _b = ifft(b), _c = ifft(c)
@@ -359,13 +406,21 @@ func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
 	c = append(c, padding...)
 	n = len(a)
 
-	domain.FFTInverse(a, fft.DIF)
-	domain.FFTInverse(b, fft.DIF)
-	domain.FFTInverse(c, fft.DIF)
-
-	domain.FFT(a, fft.DIT, fft.OnCoset())
-	domain.FFT(b, fft.DIT, fft.OnCoset())
-	domain.FFT(c, fft.DIT, fft.OnCoset())
+	progress.Start(backend.PhaseFFT, 7)
+	for _, p := range [][]fr.Element{a, b, c} {
+		if err := opt.CheckContext(backend.PhaseFFT); err != nil {
+			return nil, err
+		}
+		domain.FFTInverse(p, fft.DIF)
+		progress.Step(backend.PhaseFFT)
+	}
+	for _, p := range [][]fr.Element{a, b, c} {
+		if err := opt.CheckContext(backend.PhaseFFT); err != nil {
+			return nil, err
+		}
+		domain.FFT(p, fft.DIT, fft.OnCoset())
+		progress.Step(backend.PhaseFFT)
+	}
 
 	var den, one fr.Element
 	one.SetOne()
@@ -383,7 +438,11 @@ func computeH(a, b, c []fr.Element, domain *fft.Domain) []fr.Element {
 	})
 
 	// ifft_coset
+	if err := opt.CheckContext(backend.PhaseFFT); err != nil {
+		return nil, err
+	}
 	domain.FFTInverse(a, fft.DIF, fft.OnCoset())
+	progress.Step(backend.PhaseFFT)
 
-	return a
+	return a, nil
 }
@@ -0,0 +1,159 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package snarkpack
+
+import (
+	"bytes"
+	"fmt"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bls12_377 "github.com/consensys/gnark/backend/groth16/bls12-377"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type aggregationCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *aggregationCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	cmt, err := api.(frontend.Committer).Commit(c.X, c.Y)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	api.AssertIsDifferent(cmt, 0)
+	return nil
+}
+
+func TestAggregate(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	const nbProofs = 4
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(curve.ID.ScalarField(), r1cs.NewBuilder, &aggregationCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+
+	proofs := make([]*groth16_bls12_377.Proof, nbProofs)
+	publicWitnesses := make([]fr.Vector, nbProofs)
+	for i := range proofs {
+		w, err := frontend.NewWitness(&aggregationCircuit{X: i + 2, Y: (i + 2) * (i + 2)}, curve.ID.ScalarField())
+		assert.NoError(err)
+		proof, err := groth16.Prove(ccs, pk, w)
+		assert.NoError(err)
+		proofs[i] = proof.(*groth16_bls12_377.Proof)
+		pw, err := w.Public()
+		assert.NoError(err)
+		publicWitnesses[i] = pw.Vector().(fr.Vector)
+	}
+	groth16Vk := vk.(*groth16_bls12_377.VerifyingKey)
+
+	aggPk, aggVk, err := UnsafeSetup(2 * nbProofs)
+	assert.NoError(err)
+
+	aggregated, err := Aggregate(aggPk, proofs, publicWitnesses)
+	assert.NoError(err)
+	assert.Len(aggregated.Rounds, 2)
+	assert.NoError(Verify(aggregated, aggVk, groth16Vk, publicWitnesses))
+
+	// fewer proofs than the SRS allows
+	aggregated2, err := Aggregate(aggPk, proofs[:2], publicWitnesses[:2])
+	assert.NoError(err)
+	assert.NoError(Verify(aggregated2, aggVk, groth16Vk, publicWitnesses[:2]))
+
+	// the public witnesses are bound to the aggregated proof
+	swapped := append([]fr.Vector{}, publicWitnesses...)
+	swapped[1], swapped[2] = swapped[2], swapped[1]
+	assert.Error(Verify(aggregated, aggVk, groth16Vk, swapped))
+
+	// a proof of the aggregation is changed
+	tampered := *aggregated
+	tampered.FinalC.Neg(&tampered.FinalC)
+	assert.Error(Verify(&tampered, aggVk, groth16Vk, publicWitnesses))
+
+	_, err = Aggregate(aggPk, proofs[:3], publicWitnesses[:3])
+	assert.ErrorIs(err, ErrNbProofs)
+}
+
+func TestSerialization(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	assert := require.New(t)
+
+	aggPk, aggVk, err := UnsafeSetup(4)
+	assert.NoError(err)
+
+	// the aggregated proof doesn't need to be valid for the serialization
+	_, _, g1, g2 := curve.Generators()
+	proofs := make([]*groth16_bls12_377.Proof, 4)
+	publicWitnesses := make([]fr.Vector, 4)
+	for i := range proofs {
+		proofs[i] = &groth16_bls12_377.Proof{Ar: g1, Bs: g2, Krs: g1, Commitments: []curve.G1Affine{g1}, CommitmentPok: g1}
+		publicWitnesses[i] = fr.Vector{fr.NewElement(uint64(i))}
+	}
+	aggregated, err := Aggregate(aggPk, proofs, publicWitnesses)
+	assert.NoError(err)
+
+	for _, raw := range []bool{false, true} {
+		var buf bytes.Buffer
+		var written int64
+		if raw {
+			written, err = aggregated.WriteRawTo(&buf)
+		} else {
+			written, err = aggregated.WriteTo(&buf)
+		}
+		assert.NoError(err)
+		var decoded Proof
+		read, err := decoded.ReadFrom(&buf)
+		assert.NoError(err)
+		assert.Equal(written, read)
+		assert.Equal(aggregated, &decoded)
+
+		buf.Reset()
+		if raw {
+			_, err = aggPk.WriteRawTo(&buf)
+		} else {
+			_, err = aggPk.WriteTo(&buf)
+		}
+		assert.NoError(err)
+		var decodedPk ProvingKey
+		_, err = decodedPk.ReadFrom(&buf)
+		assert.NoError(err)
+		assert.Equal(aggPk, &decodedPk)
+
+		buf.Reset()
+		if raw {
+			_, err = aggVk.WriteRawTo(&buf)
+		} else {
+			_, err = aggVk.WriteTo(&buf)
+		}
+		assert.NoError(err)
+		var decodedVk VerifyingKey
+		_, err = decodedVk.ReadFrom(&buf)
+		assert.NoError(err)
+		assert.Equal(aggVk, &decodedVk)
+	}
+}
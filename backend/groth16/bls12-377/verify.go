@@ -19,7 +19,9 @@ package groth16
 import (
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"math/big"
 	"time"
 
 	"github.com/consensys/gnark-crypto/ecc"
@@ -29,6 +31,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/pedersen"
 	"github.com/consensys/gnark-crypto/utils"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/logger"
 )
@@ -72,12 +75,158 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		close(chDone)
 	}()
 
+	kSum, foldedCommitment, err := vk.publicInputsSum(proof, publicWitness, opt.HashToFieldFn)
+	if err != nil {
+		return err
+	}
+	if err = vk.CommitmentKey.Verify(foldedCommitment, proof.CommitmentPok); err != nil {
+		return err
+	}
+
+	// compute e(Σx.[Kvk(t)]1, -[γ]2)
+	right, err := curve.MillerLoop([]curve.G1Affine{kSum}, []curve.G2Affine{vk.G2.gammaNeg})
+	if err != nil {
+		return err
+	}
+
+	// wait for (eKrsδ, eArBs)
+	if err := <-chDone; err != nil {
+		return err
+	}
+
+	right = curve.FinalExponentiation(&right, &doubleML)
+	if !vk.e.Equal(&right) {
+		return errPairingCheckFailed
+	}
+
+	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
+	return nil
+}
+
+// BatchVerify verifies the proofs with the same VerifyingKey against the
+// matching public witnesses.
+//
+// The pairing equations of the proofs, and of their proofs of knowledge of the
+// commitments, are combined with random coefficients so that the batch costs a
+// single multi-pairing. If the combined check fails, the proofs are verified
+// one by one and a [*backend.BatchVerifyError] is returned for the first
+// invalid proof.
+func BatchVerify(proofs []*Proof, vk *VerifyingKey, publicWitnesses []fr.Vector, opts ...backend.VerifierOption) error {
+	opt, err := backend.NewVerifierConfig(opts...)
+	if err != nil {
+		return fmt.Errorf("new verifier config: %w", err)
+	}
+	if opt.HashToFieldFn == nil {
+		opt.HashToFieldFn = hash_to_field.New([]byte(constraint.CommitmentDst))
+	}
+	if len(proofs) != len(publicWitnesses) {
+		return fmt.Errorf("invalid number of public witnesses, got %d, expected %d", len(publicWitnesses), len(proofs))
+	}
+	if len(proofs) == 0 {
+		return nil
+	}
+
+	log := logger.Logger().With().Str("curve", vk.CurveID().String()).Str("backend", "groth16").Int("nbProofs", len(proofs)).Logger()
+	start := time.Now()
+
+	nbPublicVars := len(vk.G1.K) - len(vk.PublicAndCommitmentCommitted)
+	n := len(proofs)
+
+	// for the proof i, with the random coefficients rᵢ and sᵢ, we check
+	// 	Π e(rᵢ.Arᵢ, Bsᵢ) . e(Σrᵢ.Krsᵢ, -δ) . e(Σrᵢ.kSumᵢ, -γ) . e(-Σrᵢ.α, β) = 1
+	// and for the proofs of knowledge of the commitments
+	// 	e(Σsᵢ.Cᵢ, G) . e(Σsᵢ.PoKᵢ, -G/√σ) = 1
+	r := make([]fr.Element, n)
+	s := make([]fr.Element, n)
+	krs := make([]curve.G1Affine, n)
+	kSums := make([]curve.G1Affine, n)
+	commitments := make([]curve.G1Affine, n)
+	poks := make([]curve.G1Affine, n)
+	P := make([]curve.G1Affine, 0, n+5)
+	Q := make([]curve.G2Affine, 0, n+5)
+	var rSum fr.Element
+	for i, proof := range proofs {
+		if len(publicWitnesses[i]) != nbPublicVars-1 {
+			return &backend.BatchVerifyError{Index: i, Err: fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitnesses[i]), nbPublicVars-1)}
+		}
+		if !proof.isValid() {
+			return &backend.BatchVerifyError{Index: i, Err: errCorrectSubgroupCheckFailed}
+		}
+		if !proof.CommitmentPok.IsInSubGroup() {
+			return &backend.BatchVerifyError{Index: i, Err: errCorrectSubgroupCheckFailed}
+		}
+		if kSums[i], commitments[i], err = vk.publicInputsSum(proof, publicWitnesses[i], opt.HashToFieldFn); err != nil {
+			return &backend.BatchVerifyError{Index: i, Err: err}
+		}
+		krs[i] = proof.Krs
+		poks[i] = proof.CommitmentPok
+
+		if _, err = r[i].SetRandom(); err != nil {
+			return err
+		}
+		if _, err = s[i].SetRandom(); err != nil {
+			return err
+		}
+		rSum.Add(&rSum, &r[i])
+
+		var ri big.Int
+		r[i].BigInt(&ri)
+		var ar curve.G1Affine
+		ar.ScalarMultiplication(&proof.Ar, &ri)
+		P = append(P, ar)
+		Q = append(Q, proof.Bs)
+	}
+
+	var sums [5]curve.G1Affine
+	if _, err = sums[0].MultiExp(krs, r, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if _, err = sums[1].MultiExp(kSums, r, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if _, err = sums[2].MultiExp(commitments, s, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	if _, err = sums[3].MultiExp(poks, s, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	var rSumBig big.Int
+	rSum.Neg(&rSum).BigInt(&rSumBig)
+	sums[4].ScalarMultiplication(&vk.G1.Alpha, &rSumBig)
+
+	P = append(P, sums[:]...)
+	Q = append(Q, vk.G2.deltaNeg, vk.G2.gammaNeg, vk.CommitmentKey.G, vk.CommitmentKey.GRootSigmaNeg, vk.G2.Beta)
+
+	ok, err := curve.PairingCheck(P, Q)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// find the invalid proof
+		for i := range proofs {
+			if err := Verify(proofs[i], vk, publicWitnesses[i], opts...); err != nil {
+				return &backend.BatchVerifyError{Index: i, Err: err}
+			}
+		}
+		return errPairingCheckFailed
+	}
+
+	log.Debug().Dur("took", time.Since(start)).Msg("batch verifier done")
+	return nil
+}
+
+// publicInputsSum returns Σx.[Kvk(t)]1 + Σ[Commitments]1 for the public
+// witness completed with the commitment wires, and the folded commitment to be
+// checked against the proof of knowledge of the proof.
+func (vk *VerifyingKey) publicInputsSum(proof *Proof, publicWitness fr.Vector, hashToField hash.Hash) (kSumAff, foldedCommitment curve.G1Affine, err error) {
 	maxNbPublicCommitted := 0
 	for _, s := range vk.PublicAndCommitmentCommitted { // iterate over commitments
 		maxNbPublicCommitted = utils.Max(maxNbPublicCommitted, len(s))
 	}
 	commitmentsSerialized := make([]byte, len(vk.PublicAndCommitmentCommitted)*fr.Bytes)
 	commitmentPrehashSerialized := make([]byte, curve.SizeOfG1AffineUncompressed+maxNbPublicCommitted*fr.Bytes)
+	// do not modify the caller's witness when appending the commitment wires
+	publicWitness = publicWitness[:len(publicWitness):len(publicWitness)]
 	for i := range vk.PublicAndCommitmentCommitted { // solveCommitmentWire
 		copy(commitmentPrehashSerialized, proof.Commitments[i].Marshal())
 		offset := curve.SizeOfG1AffineUncompressed
@@ -85,12 +234,12 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 			copy(commitmentPrehashSerialized[offset:], publicWitness[vk.PublicAndCommitmentCommitted[i][j]-1].Marshal())
 			offset += fr.Bytes
 		}
-		opt.HashToFieldFn.Write(commitmentPrehashSerialized[:offset])
-		hashBts := opt.HashToFieldFn.Sum(nil)
-		opt.HashToFieldFn.Reset()
+		hashToField.Write(commitmentPrehashSerialized[:offset])
+		hashBts := hashToField.Sum(nil)
+		hashToField.Reset()
 		nbBuf := fr.Bytes
-		if opt.HashToFieldFn.Size() < fr.Bytes {
-			nbBuf = opt.HashToFieldFn.Size()
+		if hashToField.Size() < fr.Bytes {
+			nbBuf = hashToField.Size()
 		}
 		var res fr.Element
 		res.SetBytes(hashBts[:nbBuf])
@@ -98,18 +247,13 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		copy(commitmentsSerialized[i*fr.Bytes:], res.Marshal())
 	}
 
-	if folded, err := pedersen.FoldCommitments(proof.Commitments, commitmentsSerialized); err != nil {
-		return err
-	} else {
-		if err = vk.CommitmentKey.Verify(folded, proof.CommitmentPok); err != nil {
-			return err
-		}
+	if foldedCommitment, err = pedersen.FoldCommitments(proof.Commitments, commitmentsSerialized); err != nil {
+		return
 	}
 
-	// compute e(Σx.[Kvk(t)]1, -[γ]2)
 	var kSum curve.G1Jac
-	if _, err := kSum.MultiExp(vk.G1.K[1:], publicWitness, ecc.MultiExpConfig{}); err != nil {
-		return err
+	if _, err = kSum.MultiExp(vk.G1.K[1:], publicWitness, ecc.MultiExpConfig{}); err != nil {
+		return
 	}
 	kSum.AddMixed(&vk.G1.K[0])
 
@@ -117,29 +261,21 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		kSum.AddMixed(&proof.Commitments[i])
 	}
 
-	var kSumAff curve.G1Affine
 	kSumAff.FromJacobian(&kSum)
+	return
+}
 
-	right, err := curve.MillerLoop([]curve.G1Affine{kSumAff}, []curve.G2Affine{vk.G2.gammaNeg})
-	if err != nil {
-		return err
-	}
-
-	// wait for (eKrsδ, eArBs)
-	if err := <-chDone; err != nil {
-		return err
-	}
-
-	right = curve.FinalExponentiation(&right, &doubleML)
-	if !vk.e.Equal(&right) {
-		return errPairingCheckFailed
+// Export writes a verifier contract for vk in the target language. Solidity is
+// handled by ExportSolidity, the other targets by the exporter registered with
+// backend.RegisterExporter.
+func (vk *VerifyingKey) Export(w io.Writer, target backend.Target) error {
+	if target == backend.Solidity {
+		return vk.ExportSolidity(w)
 	}
-
-	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
-	return nil
+	return backend.Export(w, vk, backend.GROTH16, ecc.BLS12_377, target)
 }
 
 // ExportSolidity not implemented for BLS12-377
-func (vk *VerifyingKey) ExportSolidity(w io.Writer) error {
+func (vk *VerifyingKey) ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error {
 	return errors.New("not implemented")
 }
@@ -17,8 +17,10 @@
 package mpcsetup
 
 import (
-	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
 	"io"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/internal/utils"
 )
 
 // WriteTo implements io.WriterTo
@@ -104,11 +106,21 @@ func (c *Phase2) writeTo(writer io.Writer) (int64, error) {
 		&c.PublicKey.SG,
 		&c.PublicKey.SXG,
 		&c.PublicKey.XR,
+		&c.SigmaPublicKey.SG,
+		&c.SigmaPublicKey.SXG,
+		&c.SigmaPublicKey.XR,
 		&c.Parameters.G1.Delta,
 		c.Parameters.G1.L,
 		c.Parameters.G1.Z,
-		&c.Parameters.G2.Delta,
+		uint32(len(c.Parameters.G1.SigmaCKK)),
 	}
+	for i := range c.Parameters.G1.SigmaCKK {
+		toEncode = append(toEncode, c.Parameters.G1.SigmaCKK[i])
+	}
+	toEncode = append(toEncode,
+		&c.Parameters.G2.Delta,
+		&c.Parameters.G2.Sigma,
+	)
 
 	for _, v := range toEncode {
 		if err := enc.Encode(v); err != nil {
@@ -122,14 +134,18 @@ func (c *Phase2) writeTo(writer io.Writer) (int64, error) {
 // ReadFrom implements io.ReaderFrom
 func (c *Phase2) ReadFrom(reader io.Reader) (int64, error) {
 	dec := curve.NewDecoder(reader)
+	var nbCommitments uint32
 	toEncode := []interface{}{
 		&c.PublicKey.SG,
 		&c.PublicKey.SXG,
 		&c.PublicKey.XR,
+		&c.SigmaPublicKey.SG,
+		&c.SigmaPublicKey.SXG,
+		&c.SigmaPublicKey.XR,
 		&c.Parameters.G1.Delta,
 		&c.Parameters.G1.L,
 		&c.Parameters.G1.Z,
-		&c.Parameters.G2.Delta,
+		&nbCommitments,
 	}
 
 	for _, v := range toEncode {
@@ -138,6 +154,22 @@ func (c *Phase2) ReadFrom(reader io.Reader) (int64, error) {
 		}
 	}
 
+	c.Parameters.G1.SigmaCKK = make([][]curve.G1Affine, nbCommitments)
+	toEncode = toEncode[:0]
+	for i := range c.Parameters.G1.SigmaCKK {
+		toEncode = append(toEncode, &c.Parameters.G1.SigmaCKK[i])
+	}
+	toEncode = append(toEncode,
+		&c.Parameters.G2.Delta,
+		&c.Parameters.G2.Sigma,
+	)
+
+	for _, v := range toEncode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
 	c.Hash = make([]byte, 32)
 	n, err := reader.Read(c.Hash)
 	return int64(n) + dec.BytesRead(), err
@@ -150,8 +182,16 @@ func (c *Phase2Evaluations) WriteTo(writer io.Writer) (int64, error) {
 	toEncode := []interface{}{
 		c.G1.A,
 		c.G1.B,
-		c.G2.B,
+		c.G1.VKK,
+		uint32(len(c.G1.CKK)),
 	}
+	for i := range c.G1.CKK {
+		toEncode = append(toEncode, c.G1.CKK[i])
+	}
+	toEncode = append(toEncode,
+		c.G2.B,
+		utils.IntSliceSliceToUint64SliceSlice(c.PublicAndCommitmentCommitted),
+	)
 
 	for _, v := range toEncode {
 		if err := enc.Encode(v); err != nil {
@@ -165,17 +205,37 @@ func (c *Phase2Evaluations) WriteTo(writer io.Writer) (int64, error) {
 // ReadFrom implements io.ReaderFrom
 func (c *Phase2Evaluations) ReadFrom(reader io.Reader) (int64, error) {
 	dec := curve.NewDecoder(reader)
+	var nbCommitments uint32
 	toEncode := []interface{}{
 		&c.G1.A,
 		&c.G1.B,
-		&c.G2.B,
+		&c.G1.VKK,
+		&nbCommitments,
+	}
+
+	for _, v := range toEncode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
 	}
 
+	c.G1.CKK = make([][]curve.G1Affine, nbCommitments)
+	var publicAndCommitmentCommitted [][]uint64
+	toEncode = toEncode[:0]
+	for i := range c.G1.CKK {
+		toEncode = append(toEncode, &c.G1.CKK[i])
+	}
+	toEncode = append(toEncode,
+		&c.G2.B,
+		&publicAndCommitmentCommitted,
+	)
+
 	for _, v := range toEncode {
 		if err := dec.Decode(v); err != nil {
 			return dec.BytesRead(), err
 		}
 	}
+	c.PublicAndCommitmentCommitted = utils.Uint64SliceSliceToIntSliceSlice(publicAndCommitmentCommitted)
 
 	return dec.BytesRead(), nil
 }
@@ -0,0 +1,141 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package snarkpack
+
+import (
+	"errors"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"math/big"
+)
+
+var (
+	ErrNbProofs    = errors.New("the number of proofs must be a power of two")
+	ErrSRSTooSmall = errors.New("the SRS is too small for the number of proofs")
+)
+
+// ProvingKey is the structured reference string of the aggregation prover.
+//
+// It holds the powers of two secrets a and b, which must come from two
+// independent ceremonies, so that the commitment keys for the A and C
+// elements of the proofs ({[aⁱ]₂}, {[bⁱ]₂}) and for the B elements
+// ({[aⁿ⁺ⁱ]₁}, {[bⁿ⁺ⁱ]₁}) are unrelated.
+type ProvingKey struct {
+	G1 struct {
+		A, B []curve.G1Affine // {[a⁰]₁, [a¹]₁, …, [a²ⁿ⁻¹]₁}, {[b⁰]₁, [b¹]₁, …, [b²ⁿ⁻¹]₁}
+	}
+	G2 struct {
+		A, B []curve.G2Affine // {[a⁰]₂, [a¹]₂, …, [aⁿ⁻¹]₂}, {[b⁰]₂, [b¹]₂, …, [bⁿ⁻¹]₂}
+	}
+}
+
+// VerifyingKey is the structured reference string of the aggregation verifier.
+type VerifyingKey struct {
+	G1 struct {
+		Gen, A, B curve.G1Affine // [1]₁, [a]₁, [b]₁
+	}
+	G2 struct {
+		Gen, A, B curve.G2Affine // [1]₂, [a]₂, [b]₂
+	}
+}
+
+// NbProofs returns the maximum number of proofs the key can aggregate.
+func (pk *ProvingKey) NbProofs() int {
+	return len(pk.G2.A)
+}
+
+// NewSetup returns the keys for aggregating proofs from the powers of the two
+// secrets a and b, for example the τ powers of two independent
+// powers-of-tau ceremonies. The powers must start at the generators. The keys
+// can aggregate up to n proofs, for the largest power of two n such that there
+// are 2n powers in G1 and n powers in G2, and n must be at least 2.
+func NewSetup(g1A, g1B []curve.G1Affine, g2A, g2B []curve.G2Affine) (*ProvingKey, *VerifyingKey, error) {
+	nbG1 := len(g1A)
+	if len(g1B) < nbG1 {
+		nbG1 = len(g1B)
+	}
+	nbG2 := len(g2A)
+	if len(g2B) < nbG2 {
+		nbG2 = len(g2B)
+	}
+	n := 1
+	for 4*n <= nbG1 && 2*n <= nbG2 {
+		n *= 2
+	}
+	if n < 2 || !g1A[0].Equal(&g1B[0]) || !g2A[0].Equal(&g2B[0]) {
+		return nil, nil, ErrSRSTooSmall
+	}
+
+	var pk ProvingKey
+	pk.G1.A = g1A[:2*n]
+	pk.G1.B = g1B[:2*n]
+	pk.G2.A = g2A[:n]
+	pk.G2.B = g2B[:n]
+
+	var vk VerifyingKey
+	vk.G1.Gen, vk.G1.A, vk.G1.B = g1A[0], g1A[1], g1B[1]
+	vk.G2.Gen, vk.G2.A, vk.G2.B = g2A[0], g2A[1], g2B[1]
+
+	return &pk, &vk, nil
+}
+
+// UnsafeSetup returns the keys for aggregating up to n proofs, n being a power
+// of two, from secrets sampled locally. As whoever knows the secrets can forge
+// aggregated proofs, it must only be used for tests. See [NewSetup] for
+// building the keys from the outputs of ceremonies.
+func UnsafeSetup(n int) (*ProvingKey, *VerifyingKey, error) {
+	if n < 2 || n&(n-1) != 0 {
+		return nil, nil, ErrNbProofs
+	}
+
+	var a, b fr.Element
+	if _, err := a.SetRandom(); err != nil {
+		return nil, nil, err
+	}
+	if _, err := b.SetRandom(); err != nil {
+		return nil, nil, err
+	}
+
+	_, _, g1, g2 := curve.Generators()
+
+	g1A := curve.BatchScalarMultiplicationG1(&g1, powers(a, 2*n))
+	g1B := curve.BatchScalarMultiplicationG1(&g1, powers(b, 2*n))
+	g2A := curve.BatchScalarMultiplicationG2(&g2, powers(a, n))
+	g2B := curve.BatchScalarMultiplicationG2(&g2, powers(b, n))
+
+	return NewSetup(g1A, g1B, g2A, g2B)
+}
+
+// powers returns [x⁰, x¹, …, xⁿ⁻¹].
+func powers(x fr.Element, n int) []fr.Element {
+	res := make([]fr.Element, n)
+	if n == 0 {
+		return res
+	}
+	res[0].SetOne()
+	for i := 1; i < n; i++ {
+		res[i].Mul(&res[i-1], &x)
+	}
+	return res
+}
+
+// bigInt returns the integer representation of x.
+func bigInt(x *fr.Element) *big.Int {
+	var res big.Int
+	x.BigInt(&res)
+	return &res
+}
@@ -0,0 +1,473 @@
+package groth16
+
+import (
+	"encoding/hex"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+)
+
+// solidityTemplate
+// this is an experimental feature and gnark solidity generator as not been thoroughly tested
+const solidityTemplate = `
+{{- $numCommitments := len .PublicAndCommitmentCommitted }}
+{{- $numPublic := sub (sub (len .G1.K) $numCommitments) 1 }}
+// SPDX-License-Identifier: MIT
+
+pragma solidity ^0.8.0;
+
+/// @title Groth16 verifier template for BLS12-381.
+/// @notice Supports verifying Groth16 proofs
+{{- if gt $numCommitments 0 }} with {{ $numCommitments }} BSB22 commitment(s){{ end }} with the
+/// BLS12-381 precompiles of EIP-2537. Proofs are {{ add 512 (mul $numCommitments 128) }} bytes: the points (A, B, C)
+{{- if gt $numCommitments 0 }},
+/// the commitments and their proof of knowledge{{ end }} in the EIP-2537 encoding, as
+/// returned by gnark's MarshalSolidity.
+contract Verifier {
+
+    /// Some of the provided public input values are larger than the field modulus.
+    /// @dev Public input elements are not automatically reduced, as this is can be
+    /// a dangerous source of bugs.
+    error PublicInputNotInField();
+
+    /// The proof is invalid.
+    /// @dev This can mean that provided Groth16 proof points are not on their
+    /// curves or subgroups, that pairing equation fails, or that the proof is
+    /// not for the provided public input.
+    error ProofInvalid();
+    {{- if gt $numCommitments 0 }}
+
+    /// The proof of knowledge of the commitments is invalid.
+    /// @dev This can mean that the commitments or their proof of knowledge are
+    /// not on the curve or in the subgroup, or that the pairing equation fails.
+    error CommitmentInvalid();
+    {{- end }}
+
+    // Addresses of precompiles
+    uint256 constant PRECOMPILE_G1MSM = 0x0c;
+    uint256 constant PRECOMPILE_PAIRING = 0x0f;
+
+    // Scalar field Fr order R.
+    uint256 constant R = 0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001;
+
+    // Elements of the base field Fp have 381 bits and don't fit in a word.
+    // As in EIP-2537, they are encoded in two words: the 128 high bits (_HI)
+    // followed by the 256 low bits (_LO).
+    // Elements of the extension field Fp2 = Fp[u] / (u² + 1) are written
+    // a₀ + a₁⋅u, and encoded as a₀ followed by a₁, as in EIP-2537.
+
+    // Groth16 alpha point in G1
+    uint256 constant ALPHA_X_HI = {{fpHi .G1.Alpha.X}};
+    uint256 constant ALPHA_X_LO = {{fpLo .G1.Alpha.X}};
+    uint256 constant ALPHA_Y_HI = {{fpHi .G1.Alpha.Y}};
+    uint256 constant ALPHA_Y_LO = {{fpLo .G1.Alpha.Y}};
+
+    // Groth16 beta point in G2 in powers of u
+    uint256 constant BETA_NEG_X_0_HI = {{fpHi .G2.Beta.X.A0}};
+    uint256 constant BETA_NEG_X_0_LO = {{fpLo .G2.Beta.X.A0}};
+    uint256 constant BETA_NEG_X_1_HI = {{fpHi .G2.Beta.X.A1}};
+    uint256 constant BETA_NEG_X_1_LO = {{fpLo .G2.Beta.X.A1}};
+    uint256 constant BETA_NEG_Y_0_HI = {{fpHi .G2.Beta.Y.A0}};
+    uint256 constant BETA_NEG_Y_0_LO = {{fpLo .G2.Beta.Y.A0}};
+    uint256 constant BETA_NEG_Y_1_HI = {{fpHi .G2.Beta.Y.A1}};
+    uint256 constant BETA_NEG_Y_1_LO = {{fpLo .G2.Beta.Y.A1}};
+
+    // Groth16 gamma point in G2 in powers of u
+    uint256 constant GAMMA_NEG_X_0_HI = {{fpHi .G2.Gamma.X.A0}};
+    uint256 constant GAMMA_NEG_X_0_LO = {{fpLo .G2.Gamma.X.A0}};
+    uint256 constant GAMMA_NEG_X_1_HI = {{fpHi .G2.Gamma.X.A1}};
+    uint256 constant GAMMA_NEG_X_1_LO = {{fpLo .G2.Gamma.X.A1}};
+    uint256 constant GAMMA_NEG_Y_0_HI = {{fpHi .G2.Gamma.Y.A0}};
+    uint256 constant GAMMA_NEG_Y_0_LO = {{fpLo .G2.Gamma.Y.A0}};
+    uint256 constant GAMMA_NEG_Y_1_HI = {{fpHi .G2.Gamma.Y.A1}};
+    uint256 constant GAMMA_NEG_Y_1_LO = {{fpLo .G2.Gamma.Y.A1}};
+
+    // Groth16 delta point in G2 in powers of u
+    uint256 constant DELTA_NEG_X_0_HI = {{fpHi .G2.Delta.X.A0}};
+    uint256 constant DELTA_NEG_X_0_LO = {{fpLo .G2.Delta.X.A0}};
+    uint256 constant DELTA_NEG_X_1_HI = {{fpHi .G2.Delta.X.A1}};
+    uint256 constant DELTA_NEG_X_1_LO = {{fpLo .G2.Delta.X.A1}};
+    uint256 constant DELTA_NEG_Y_0_HI = {{fpHi .G2.Delta.Y.A0}};
+    uint256 constant DELTA_NEG_Y_0_LO = {{fpLo .G2.Delta.Y.A0}};
+    uint256 constant DELTA_NEG_Y_1_HI = {{fpHi .G2.Delta.Y.A1}};
+    uint256 constant DELTA_NEG_Y_1_LO = {{fpLo .G2.Delta.Y.A1}};
+
+    // Constant and public input points
+    {{- $k0 := index .G1.K 0}}
+    uint256 constant CONSTANT_X_HI = {{fpHi $k0.X}};
+    uint256 constant CONSTANT_X_LO = {{fpLo $k0.X}};
+    uint256 constant CONSTANT_Y_HI = {{fpHi $k0.Y}};
+    uint256 constant CONSTANT_Y_LO = {{fpLo $k0.Y}};
+    {{- range $i, $ki := .G1.K }}
+        {{- if gt $i 0 }}
+    uint256 constant PUB_{{sub $i 1}}_X_HI = {{fpHi $ki.X}};
+    uint256 constant PUB_{{sub $i 1}}_X_LO = {{fpLo $ki.X}};
+    uint256 constant PUB_{{sub $i 1}}_Y_HI = {{fpHi $ki.Y}};
+    uint256 constant PUB_{{sub $i 1}}_Y_LO = {{fpLo $ki.Y}};
+        {{- end }}
+    {{- end }}
+    {{- if gt $numCommitments 0 }}
+
+    // Pedersen G point in G2 in powers of u
+    uint256 constant PEDERSEN_G_X_0_HI = {{fpHi .CommitmentKey.G.X.A0}};
+    uint256 constant PEDERSEN_G_X_0_LO = {{fpLo .CommitmentKey.G.X.A0}};
+    uint256 constant PEDERSEN_G_X_1_HI = {{fpHi .CommitmentKey.G.X.A1}};
+    uint256 constant PEDERSEN_G_X_1_LO = {{fpLo .CommitmentKey.G.X.A1}};
+    uint256 constant PEDERSEN_G_Y_0_HI = {{fpHi .CommitmentKey.G.Y.A0}};
+    uint256 constant PEDERSEN_G_Y_0_LO = {{fpLo .CommitmentKey.G.Y.A0}};
+    uint256 constant PEDERSEN_G_Y_1_HI = {{fpHi .CommitmentKey.G.Y.A1}};
+    uint256 constant PEDERSEN_G_Y_1_LO = {{fpLo .CommitmentKey.G.Y.A1}};
+
+    // Pedersen GRootSigmaNeg point in G2 in powers of u
+    uint256 constant PEDERSEN_GROOTSIGMANEG_X_0_HI = {{fpHi .CommitmentKey.GRootSigmaNeg.X.A0}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_X_0_LO = {{fpLo .CommitmentKey.GRootSigmaNeg.X.A0}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_X_1_HI = {{fpHi .CommitmentKey.GRootSigmaNeg.X.A1}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_X_1_LO = {{fpLo .CommitmentKey.GRootSigmaNeg.X.A1}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_Y_0_HI = {{fpHi .CommitmentKey.GRootSigmaNeg.Y.A0}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_Y_0_LO = {{fpLo .CommitmentKey.GRootSigmaNeg.Y.A0}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_Y_1_HI = {{fpHi .CommitmentKey.GRootSigmaNeg.Y.A1}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_Y_1_LO = {{fpLo .CommitmentKey.GRootSigmaNeg.Y.A1}};
+
+    /// Serialize a point of G1 as gnark-crypto's G1Affine.Marshal.
+    /// @notice The coordinates are written on 48 bytes, without the padding of
+    /// EIP-2537, and the point at infinity is flagged with 0x40 in the first byte.
+    /// @param x_hi The high word of the X coordinate.
+    /// @param x_lo The low word of the X coordinate.
+    /// @param y_hi The high word of the Y coordinate.
+    /// @param y_lo The low word of the Y coordinate.
+    /// @return The 96 bytes of the point.
+    function marshalG1(uint256 x_hi, uint256 x_lo, uint256 y_hi, uint256 y_lo)
+    internal pure returns (bytes memory) {
+        if ((x_hi | x_lo | y_hi | y_lo) == 0) {
+            x_hi = 0x40 << 120;
+        }
+        return abi.encodePacked(uint128(x_hi), x_lo, uint128(y_hi), y_lo);
+    }
+
+    /// Compute the public inputs of the commitments.
+    /// @notice Each commitment is hashed with the public inputs it commits to, as
+    /// keccak256(commitment || committed inputs) mod R. This matches the proofs
+    /// generated with solidity.WithProverTargetSolidityVerifier.
+    /// @param commitments The commitments, points of G1 encoded as in EIP-2537.
+    /// @param input The public inputs. These are elements of the scalar field Fr.
+    /// @return publicCommitments The public inputs of the commitments.
+    function hashCommitments(
+        uint256[{{mul $numCommitments 4}}] calldata commitments,
+        uint256[{{$numPublic}}] calldata input
+    ) internal pure returns (uint256[{{$numCommitments}}] memory publicCommitments) {
+        {{- range $i, $committed := .PublicAndCommitmentCommitted }}
+        publicCommitments[{{$i}}] = uint256(keccak256(abi.encodePacked(
+            marshalG1(
+                commitments[{{mul $i 4}}],
+                commitments[{{add (mul $i 4) 1}}],
+                commitments[{{add (mul $i 4) 2}}],
+                commitments[{{add (mul $i 4) 3}}]
+            )
+            {{- range $j := $committed }},
+            {{ if lt (sub $j 1) $numPublic }}input[{{sub $j 1}}]{{ else }}publicCommitments[{{sub (sub $j 1) $numPublic}}]{{ end }}
+            {{- end }}
+        ))) % R;
+        {{- end }}
+    }
+
+    /// Verify the proof of knowledge of the commitments.
+    /// @notice Reverts with CommitmentInvalid if the proof of knowledge is invalid
+    /// or if the points are not in G1.
+    {{- if gt $numCommitments 1 }}
+    /// @notice The commitments are folded with the powers of a challenge derived
+    /// from their public inputs, as in gnark-crypto's pedersen.FoldCommitments, so
+    /// that a single pairing check covers all of them.
+    {{- end }}
+    /// @param commitments The commitments, points of G1 encoded as in EIP-2537.
+    {{- if gt $numCommitments 1 }}
+    /// @param publicCommitments The public inputs of the commitments.
+    {{- end }}
+    /// @param commitmentPok The proof of knowledge, a point of G1 encoded as in EIP-2537.
+    function verifyCommitmentPok(
+        uint256[{{mul $numCommitments 4}}] calldata commitments,
+        uint256[{{$numCommitments}}] memory {{- if gt $numCommitments 1 }} publicCommitments{{ else }} /* publicCommitments */{{ end }},
+        uint256[4] calldata commitmentPok
+    ) internal view {
+        bool success = true;
+        {{- if gt $numCommitments 1 }}
+        uint256 r = uint256(sha256(abi.encodePacked("r", publicCommitments))) % R;
+        {{- end }}
+        assembly ("memory-safe") {
+            let f := mload(0x40)
+            {{- if eq $numCommitments 1 }}
+
+            // e(C, G)
+            calldatacopy(f, commitments, 0x80)
+            {{- else }}
+
+            // e(∑ᵢ rⁱ⋅Cᵢ, G) where r = sha256("r" || publicCommitments) mod R
+            let g := f
+            let ri := 1
+            for { let i := 0 } lt(i, {{mul $numCommitments 0x80}}) { i := add(i, 0x80) } {
+                calldatacopy(g, add(commitments, i), 0x80)
+                mstore(add(g, 0x80), ri)
+                g := add(g, 0xa0)
+                ri := mulmod(ri, r, R)
+            }
+            success := staticcall(gas(), PRECOMPILE_G1MSM, f, {{mul $numCommitments 0xa0}}, f, 0x80)
+            {{- end }}
+            mstore(add(f, 0x80), PEDERSEN_G_X_0_HI)
+            mstore(add(f, 0xa0), PEDERSEN_G_X_0_LO)
+            mstore(add(f, 0xc0), PEDERSEN_G_X_1_HI)
+            mstore(add(f, 0xe0), PEDERSEN_G_X_1_LO)
+            mstore(add(f, 0x100), PEDERSEN_G_Y_0_HI)
+            mstore(add(f, 0x120), PEDERSEN_G_Y_0_LO)
+            mstore(add(f, 0x140), PEDERSEN_G_Y_1_HI)
+            mstore(add(f, 0x160), PEDERSEN_G_Y_1_LO)
+
+            // e(PoK, G^{-1/σ})
+            calldatacopy(add(f, 0x180), commitmentPok, 0x80)
+            mstore(add(f, 0x200), PEDERSEN_GROOTSIGMANEG_X_0_HI)
+            mstore(add(f, 0x220), PEDERSEN_GROOTSIGMANEG_X_0_LO)
+            mstore(add(f, 0x240), PEDERSEN_GROOTSIGMANEG_X_1_HI)
+            mstore(add(f, 0x260), PEDERSEN_GROOTSIGMANEG_X_1_LO)
+            mstore(add(f, 0x280), PEDERSEN_GROOTSIGMANEG_Y_0_HI)
+            mstore(add(f, 0x2a0), PEDERSEN_GROOTSIGMANEG_Y_0_LO)
+            mstore(add(f, 0x2c0), PEDERSEN_GROOTSIGMANEG_Y_1_HI)
+            mstore(add(f, 0x2e0), PEDERSEN_GROOTSIGMANEG_Y_1_LO)
+
+            // Check pairing equation.
+            success := and(success, staticcall(gas(), PRECOMPILE_PAIRING, f, 0x300, f, 0x20))
+            // Also check returned value (both are either 1 or 0).
+            success := and(success, mload(f))
+        }
+        if (!success) {
+            revert CommitmentInvalid();
+        }
+    }
+    {{- end }}
+
+    /// Compute the public input linear combination.
+    /// @notice Reverts with PublicInputNotInField if the input is not in the field.
+    /// @notice Computes the multi-scalar-multiplication of the public input
+    /// elements and the verification key including the constant term.
+    {{- if gt $numCommitments 0 }}
+    /// The public inputs of the commitments and the commitments are added.
+    {{- end }}
+    /// @param input The public inputs. These are elements of the scalar field Fr.
+    {{- if gt $numCommitments 0 }}
+    /// @param publicCommitments The public inputs of the commitments.
+    /// @param commitments The commitments, points of G1 encoded as in EIP-2537.
+    {{- end }}
+    /// @return x_hi The high word of the X coordinate of the resulting G1 point.
+    /// @return x_lo The low word of the X coordinate of the resulting G1 point.
+    /// @return y_hi The high word of the Y coordinate of the resulting G1 point.
+    /// @return y_lo The low word of the Y coordinate of the resulting G1 point.
+    {{- if gt $numCommitments 0 }}
+    function publicInputMSM(
+        uint256[{{$numPublic}}] calldata input,
+        uint256[{{$numCommitments}}] memory publicCommitments,
+        uint256[{{mul $numCommitments 4}}] calldata commitments
+    )
+    {{- else }}
+    function publicInputMSM(uint256[{{$numPublic}}] calldata input)
+    {{- end }}
+    internal view returns (uint256 x_hi, uint256 x_lo, uint256 y_hi, uint256 y_lo) {
+        // Note: The G1MSM precompile does not reject unreduced scalars, so we check this.
+        // G1MSM has input (point, scalar)* with 160 bytes per pair and output a point.
+        // The constant term is the first pair, with the scalar 1.
+        bool success = true;
+        assembly ("memory-safe") {
+            let f := mload(0x40)
+            let s
+            mstore(f, CONSTANT_X_HI)
+            mstore(add(f, 0x20), CONSTANT_X_LO)
+            mstore(add(f, 0x40), CONSTANT_Y_HI)
+            mstore(add(f, 0x60), CONSTANT_Y_LO)
+            mstore(add(f, 0x80), 1)
+            {{- range $i := intRange $numPublic }}
+            {{- $g := mul (add $i 1) 0xa0 }}
+            mstore(add(f, {{$g}}), PUB_{{$i}}_X_HI)
+            mstore(add(f, {{add $g 0x20}}), PUB_{{$i}}_X_LO)
+            mstore(add(f, {{add $g 0x40}}), PUB_{{$i}}_Y_HI)
+            mstore(add(f, {{add $g 0x60}}), PUB_{{$i}}_Y_LO)
+            {{- if eq $i 0 }}
+            s := calldataload(input)
+            {{- else }}
+            s := calldataload(add(input, {{mul $i 0x20}}))
+            {{- end }}
+            mstore(add(f, {{add $g 0x80}}), s)
+            success := and(success, lt(s, R))
+            {{- end }}
+            {{- range $i := intRange $numCommitments }}
+            {{- $g := mul (add (add $numPublic $i) 1) 0xa0 }}
+            mstore(add(f, {{$g}}), PUB_{{add $numPublic $i}}_X_HI)
+            mstore(add(f, {{add $g 0x20}}), PUB_{{add $numPublic $i}}_X_LO)
+            mstore(add(f, {{add $g 0x40}}), PUB_{{add $numPublic $i}}_Y_HI)
+            mstore(add(f, {{add $g 0x60}}), PUB_{{add $numPublic $i}}_Y_LO)
+            mstore(add(f, {{add $g 0x80}}), mload(add(publicCommitments, {{mul $i 0x20}})))
+            {{- end }}
+            {{- range $i := intRange $numCommitments }}
+            {{- $g := mul (add (add (add $numPublic $numCommitments) $i) 1) 0xa0 }}
+            calldatacopy(add(f, {{$g}}), add(commitments, {{mul $i 0x80}}), 0x80)
+            mstore(add(f, {{add $g 0x80}}), 1)
+            {{- end }}
+            success := and(success, staticcall(gas(), PRECOMPILE_G1MSM, f, {{mul (add (add $numPublic (mul $numCommitments 2)) 1) 0xa0}}, f, 0x80))
+            x_hi := mload(f)
+            x_lo := mload(add(f, 0x20))
+            y_hi := mload(add(f, 0x40))
+            y_lo := mload(add(f, 0x60))
+        }
+        if (!success) {
+            // Either Public input not in field, or verification key invalid.
+            // We assume the contract is correctly generated, so the verification key is valid.
+            revert PublicInputNotInField();
+        }
+    }
+
+    /// Verify a Groth16 proof.
+    /// @notice Reverts with InvalidProof if the proof is invalid or
+    /// with PublicInputNotInField the public input is not reduced.
+    {{- if gt $numCommitments 0 }}
+    /// @notice Reverts with CommitmentInvalid if the proof of knowledge of the
+    /// commitments is invalid.
+    {{- end }}
+    /// @notice There is no return value. If the function does not revert, the
+    /// proof was successfully verified.
+    /// @notice The arguments are the words of Proof.MarshalSolidity, in order.
+    /// @param proof the points (A, B, C) in EIP-2537 format.
+    {{- if gt $numCommitments 0 }}
+    /// @param commitments the commitments, points of G1 encoded as in EIP-2537.
+    /// @param commitmentPok the proof of knowledge of the commitments, encoded as in EIP-2537.
+    {{- end }}
+    /// @param input the public input field elements in the scalar field Fr.
+    /// Elements must be reduced.
+    function verifyProof(
+        uint256[16] calldata proof,
+        {{- if gt $numCommitments 0 }}
+        uint256[{{mul $numCommitments 4}}] calldata commitments,
+        uint256[4] calldata commitmentPok,
+        {{- end }}
+        uint256[{{$numPublic}}] calldata input
+    ) public view {
+        {{- if gt $numCommitments 0 }}
+        uint256[{{$numCommitments}}] memory publicCommitments = hashCommitments(commitments, input);
+        verifyCommitmentPok(commitments, publicCommitments, commitmentPok);
+        (uint256 x_hi, uint256 x_lo, uint256 y_hi, uint256 y_lo) = publicInputMSM(input, publicCommitments, commitments);
+        {{- else }}
+        (uint256 x_hi, uint256 x_lo, uint256 y_hi, uint256 y_lo) = publicInputMSM(input);
+        {{- end }}
+
+        // Note: The pairing precompile rejects unreduced values and points
+        // which are not in the correct subgroup, so we won't check that here.
+
+        bool success;
+        assembly ("memory-safe") {
+            let f := mload(0x40) // Free memory pointer.
+
+            // Copy points (A, B, C) to memory. They are already in correct encoding.
+            // This is pairing e(A, B) and G1 of e(C, -δ).
+            calldatacopy(f, proof, 0x180)
+            calldatacopy(add(f, 0x180), add(proof, 0x180), 0x80)
+
+            // Complete e(C, -δ) and write e(α, -β), e(L_pub, -γ) to memory.
+            mstore(add(f, 0x200), DELTA_NEG_X_0_HI)
+            mstore(add(f, 0x220), DELTA_NEG_X_0_LO)
+            mstore(add(f, 0x240), DELTA_NEG_X_1_HI)
+            mstore(add(f, 0x260), DELTA_NEG_X_1_LO)
+            mstore(add(f, 0x280), DELTA_NEG_Y_0_HI)
+            mstore(add(f, 0x2a0), DELTA_NEG_Y_0_LO)
+            mstore(add(f, 0x2c0), DELTA_NEG_Y_1_HI)
+            mstore(add(f, 0x2e0), DELTA_NEG_Y_1_LO)
+            mstore(add(f, 0x300), ALPHA_X_HI)
+            mstore(add(f, 0x320), ALPHA_X_LO)
+            mstore(add(f, 0x340), ALPHA_Y_HI)
+            mstore(add(f, 0x360), ALPHA_Y_LO)
+            mstore(add(f, 0x380), BETA_NEG_X_0_HI)
+            mstore(add(f, 0x3a0), BETA_NEG_X_0_LO)
+            mstore(add(f, 0x3c0), BETA_NEG_X_1_HI)
+            mstore(add(f, 0x3e0), BETA_NEG_X_1_LO)
+            mstore(add(f, 0x400), BETA_NEG_Y_0_HI)
+            mstore(add(f, 0x420), BETA_NEG_Y_0_LO)
+            mstore(add(f, 0x440), BETA_NEG_Y_1_HI)
+            mstore(add(f, 0x460), BETA_NEG_Y_1_LO)
+            mstore(add(f, 0x480), x_hi)
+            mstore(add(f, 0x4a0), x_lo)
+            mstore(add(f, 0x4c0), y_hi)
+            mstore(add(f, 0x4e0), y_lo)
+            mstore(add(f, 0x500), GAMMA_NEG_X_0_HI)
+            mstore(add(f, 0x520), GAMMA_NEG_X_0_LO)
+            mstore(add(f, 0x540), GAMMA_NEG_X_1_HI)
+            mstore(add(f, 0x560), GAMMA_NEG_X_1_LO)
+            mstore(add(f, 0x580), GAMMA_NEG_Y_0_HI)
+            mstore(add(f, 0x5a0), GAMMA_NEG_Y_0_LO)
+            mstore(add(f, 0x5c0), GAMMA_NEG_Y_1_HI)
+            mstore(add(f, 0x5e0), GAMMA_NEG_Y_1_LO)
+
+            // Check pairing equation.
+            success := staticcall(gas(), PRECOMPILE_PAIRING, f, 0x600, f, 0x20)
+            // Also check returned value (both are either 1 or 0).
+            success := and(success, mload(f))
+        }
+        if (!success) {
+            // Either proof or verification key invalid.
+            // We assume the contract is correctly generated, so the verification key is valid.
+            revert ProofInvalid();
+        }
+    }
+}
+`
+
+// MarshalSolidity converts a proof to a byte array that can be used in a
+// Solidity contract: the words of the arguments of verifyProof, in order. That
+// is the points A, B and C in the encoding of EIP-2537, followed if the circuit
+// has commitments by the commitments and their proof of knowledge.
+func (proof *Proof) MarshalSolidity() []byte {
+	res := make([]byte, 0, 512+128*(len(proof.Commitments)+1))
+
+	// uint256[4] a;
+	res = appendG1Solidity(res, &proof.Ar)
+
+	// uint256[8] b;
+	res = appendFpSolidity(res, &proof.Bs.X.A0)
+	res = appendFpSolidity(res, &proof.Bs.X.A1)
+	res = appendFpSolidity(res, &proof.Bs.Y.A0)
+	res = appendFpSolidity(res, &proof.Bs.Y.A1)
+
+	// uint256[4] c;
+	res = appendG1Solidity(res, &proof.Krs)
+
+	if len(proof.Commitments) > 0 {
+		// uint256[4*nbCommitments] commitments
+		for i := range proof.Commitments {
+			res = appendG1Solidity(res, &proof.Commitments[i])
+		}
+		// uint256[4] commitmentPok
+		res = appendG1Solidity(res, &proof.CommitmentPok)
+	}
+
+	return res
+}
+
+// appendG1Solidity appends p encoded as in EIP-2537. The point at infinity is
+// encoded with zeroes.
+func appendG1Solidity(res []byte, p *curve.G1Affine) []byte {
+	res = appendFpSolidity(res, &p.X)
+	return appendFpSolidity(res, &p.Y)
+}
+
+// appendFpSolidity appends x encoded as in EIP-2537: big endian, left padded
+// with zeroes to 64 bytes.
+func appendFpSolidity(res []byte, x *fp.Element) []byte {
+	var padding [64 - fp.Bytes]byte
+	b := x.Bytes()
+	res = append(res, padding[:]...)
+	return append(res, b[:]...)
+}
+
+// fpHi returns the first word of the EIP-2537 encoding of x, i.e. its 128 high bits.
+func fpHi(x fp.Element) string {
+	b := x.Bytes()
+	return "0x" + hex.EncodeToString(b[:fp.Bytes-32])
+}
+
+// fpLo returns the second word of the EIP-2537 encoding of x, i.e. its 256 low bits.
+func fpLo(x fp.Element) string {
+	b := x.Bytes()
+	return "0x" + hex.EncodeToString(b[fp.Bytes-32:])
+}
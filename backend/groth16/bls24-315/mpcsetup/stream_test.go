@@ -0,0 +1,134 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	cs "github.com/consensys/gnark/constraint/bls24-315"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+	"math/bits"
+	"testing"
+)
+
+func TestPhase1Stream(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	assert := require.New(t)
+
+	// use several chunks per slice
+	defer func(chunkSize int) { streamChunkSize = chunkSize }(streamChunkSize)
+	streamChunkSize = 100
+
+	srs1 := InitPhase1(8)
+	srs1.Contribute()
+	var prev bytes.Buffer
+	_, err := srs1.WriteTo(&prev)
+	assert.NoError(err)
+
+	// a streamed contribution is a valid Phase1
+	var next bytes.Buffer
+	assert.NoError(ContributePhase1Stream(&next, bytes.NewReader(prev.Bytes())))
+	var srs2 Phase1
+	_, err = srs2.ReadFrom(bytes.NewReader(next.Bytes()))
+	assert.NoError(err)
+	assert.NoError(VerifyPhase1(&srs1, &srs2))
+	assert.NoError(VerifyPhase1Stream(bytes.NewReader(prev.Bytes()), bytes.NewReader(next.Bytes())))
+
+	// an in memory contribution can be verified as a stream
+	srs3 := srs2.clone()
+	srs3.Contribute()
+	var next2 bytes.Buffer
+	_, err = srs3.WriteTo(&next2)
+	assert.NoError(err)
+	assert.NoError(VerifyPhase1Stream(bytes.NewReader(next.Bytes()), bytes.NewReader(next2.Bytes())))
+
+	// a contribution must be based on the previous one
+	assert.Error(VerifyPhase1Stream(bytes.NewReader(prev.Bytes()), bytes.NewReader(next2.Bytes())))
+
+	// a contribution must be made of powers of the same τ
+	tampered := append([]byte{}, next.Bytes()...)
+	offset := 3*publicKeySize + 4 + 150*curve.SizeOfG1AffineCompressed
+	a := append([]byte{}, tampered[offset:offset+curve.SizeOfG1AffineCompressed]...)
+	copy(tampered[offset:], tampered[offset+curve.SizeOfG1AffineCompressed:offset+2*curve.SizeOfG1AffineCompressed])
+	copy(tampered[offset+curve.SizeOfG1AffineCompressed:], a)
+	rehash(tampered)
+	assert.Error(VerifyPhase1Stream(bytes.NewReader(prev.Bytes()), bytes.NewReader(tampered)))
+}
+
+func TestPhase2Stream(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	assert := require.New(t)
+
+	// use several chunks per slice
+	defer func(chunkSize int) { streamChunkSize = chunkSize }(streamChunkSize)
+	streamChunkSize = 100
+
+	ccs, err := frontend.Compile(curve.ID.ScalarField(), r1cs.NewBuilder, &rangeCheckCircuit{})
+	assert.NoError(err)
+	srs1 := InitPhase1(bits.Len(uint(ccs.GetNbConstraints() - 1)))
+	srs1.Contribute()
+	srs2, evals := InitPhase2(ccs.(*cs.R1CS), &srs1)
+	var prev bytes.Buffer
+	_, err = srs2.WriteTo(&prev)
+	assert.NoError(err)
+
+	// a streamed contribution is a valid Phase2
+	var next bytes.Buffer
+	assert.NoError(ContributePhase2Stream(&next, bytes.NewReader(prev.Bytes())))
+	var srs3 Phase2
+	_, err = srs3.ReadFrom(bytes.NewReader(next.Bytes()))
+	assert.NoError(err)
+	assert.NoError(VerifyPhase2(&srs2, &srs3))
+	assert.NoError(VerifyPhase2Stream(bytes.NewReader(prev.Bytes()), bytes.NewReader(next.Bytes())))
+
+	// an in memory contribution can be verified as a stream
+	srs4 := srs3.clone()
+	srs4.Contribute()
+	var next2 bytes.Buffer
+	_, err = srs4.WriteTo(&next2)
+	assert.NoError(err)
+	assert.NoError(VerifyPhase2Stream(bytes.NewReader(next.Bytes()), bytes.NewReader(next2.Bytes())))
+
+	// a contribution must be based on the previous one
+	assert.Error(VerifyPhase2Stream(bytes.NewReader(prev.Bytes()), bytes.NewReader(next2.Bytes())))
+
+	// the points of L must be updated with δ⁻¹
+	tampered := append([]byte{}, next.Bytes()...)
+	offset := 2*publicKeySize + curve.SizeOfG1AffineCompressed + 4
+	copy(tampered[offset:], tampered[2*publicKeySize:2*publicKeySize+curve.SizeOfG1AffineCompressed])
+	rehash(tampered)
+	assert.Error(VerifyPhase2Stream(bytes.NewReader(prev.Bytes()), bytes.NewReader(tampered)))
+
+	// the keys extracted from a streamed ceremony are valid
+	pk, vk := ExtractKeys(&srs1, &srs4, &evals, ccs.GetNbConstraints())
+	assert.Len(pk.CommitmentKeys, 1)
+	assert.Len(vk.PublicAndCommitmentCommitted, 1)
+}
+
+// rehash replaces the hash at the end of an encoded contribution by the hash
+// of its content.
+func rehash(b []byte) {
+	h := sha256.Sum256(b[:len(b)-sha256.Size])
+	copy(b[len(b)-sha256.Size:], h[:])
+}
@@ -0,0 +1,388 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package snarkpack
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/hash_to_field"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/pedersen"
+	"github.com/consensys/gnark-crypto/utils"
+	"github.com/consensys/gnark/backend"
+	groth16 "github.com/consensys/gnark/backend/groth16/bls24-315"
+	"github.com/consensys/gnark/constraint"
+)
+
+var (
+	errInvalidProof          = errors.New("invalid aggregated proof")
+	errSubgroupCheckFailed   = errors.New("points in the aggregated proof are not in the correct subgroup")
+	errInnerProductsMismatch = errors.New("inner product arguments don't match")
+	errOpeningsMismatch      = errors.New("openings of the commitment keys don't match")
+	errPairingCheckFailed    = errors.New("aggregated pairing doesn't match")
+)
+
+// Verify verifies the aggregation of Groth16 proofs for the verifying key
+// groth16Vk with the given public witnesses, in the order of the aggregated
+// proofs. The number of pairings is constant and the number of operations in
+// the target group is logarithmic in the number of proofs, but deriving the
+// public inputs and verifying the commitments of the Groth16 commitment
+// extension is linear in the number of proofs.
+func Verify(proof *Proof, vk *VerifyingKey, groth16Vk *groth16.VerifyingKey, publicWitnesses []fr.Vector, opts ...backend.VerifierOption) error {
+	opt, err := backend.NewVerifierConfig(opts...)
+	if err != nil {
+		return fmt.Errorf("new verifier config: %w", err)
+	}
+	if opt.HashToFieldFn == nil {
+		opt.HashToFieldFn = hash_to_field.New([]byte(constraint.CommitmentDst))
+	}
+
+	n := len(publicWitnesses)
+	if n == 0 || n&(n-1) != 0 {
+		return ErrNbProofs
+	}
+	if 1<<len(proof.Rounds) != n || len(proof.Commitments) != n || len(proof.CommitmentPoks) != n {
+		return errInvalidProof
+	}
+	nbPublicVars := len(groth16Vk.G1.K) - len(groth16Vk.PublicAndCommitmentCommitted)
+	for i := range publicWitnesses {
+		if len(publicWitnesses[i]) != nbPublicVars-1 {
+			return fmt.Errorf("invalid witness size, got %d, expected %d (public - ONE_WIRE)", len(publicWitnesses[i]), nbPublicVars-1)
+		}
+		if len(proof.Commitments[i]) != len(groth16Vk.PublicAndCommitmentCommitted) {
+			return errInvalidProof
+		}
+	}
+	if !proof.isValid() {
+		return errSubgroupCheckFailed
+	}
+
+	// derive the challenges
+	fs := newTranscript()
+	fs.bind(gtBytes(&proof.ComAB.T), gtBytes(&proof.ComAB.U), gtBytes(&proof.ComC.T), gtBytes(&proof.ComC.U))
+	bindInputs(fs, publicWitnesses, proof.Commitments)
+	r := fs.challenge()
+	fs.bind(gtBytes(&proof.ZAB), proof.ZC.Marshal())
+	challenges := make([]fr.Element, len(proof.Rounds))
+	for i := range proof.Rounds {
+		fs.bind(proof.Rounds[i].bytes()...)
+		challenges[i] = fs.challenge()
+	}
+	fs.bind(proof.FinalV[0].Marshal(), proof.FinalV[1].Marshal(), proof.FinalW[0].Marshal(), proof.FinalW[1].Marshal())
+	z := fs.challenge()
+
+	if err := proof.verifyInnerProducts(challenges); err != nil {
+		return err
+	}
+	if err := proof.verifyOpenings(vk, n, r, z, challenges); err != nil {
+		return err
+	}
+	return proof.verifyGroth16(groth16Vk, publicWitnesses, r, opt)
+}
+
+// verifyInnerProducts folds the commitments and the inner products with the
+// cross terms of the rounds and checks them against the final elements.
+func (proof *Proof) verifyInnerProducts(challenges []fr.Element) error {
+	comAB, zAB, comC, zC := proof.ComAB, proof.ZAB, proof.ComC, proof.ZC
+	var s, one fr.Element
+	s.SetOne()
+	one.SetOne()
+	for i := range proof.Rounds {
+		round := &proof.Rounds[i]
+		var xInv fr.Element
+		xInv.Inverse(&challenges[i])
+		x, xInvBig := bigInt(&challenges[i]), bigInt(&xInv)
+
+		// v ← vₗ^x . v . vᵣ^x⁻¹
+		foldGT(&comAB.T, &round.ComABL.T, &round.ComABR.T, x, xInvBig)
+		foldGT(&comAB.U, &round.ComABL.U, &round.ComABR.U, x, xInvBig)
+		foldGT(&zAB, &round.ZABL, &round.ZABR, x, xInvBig)
+		foldGT(&comC.T, &round.ComCL.T, &round.ComCR.T, x, xInvBig)
+		foldGT(&comC.U, &round.ComCL.U, &round.ComCR.U, x, xInvBig)
+
+		var l, r curve.G1Affine
+		l.ScalarMultiplication(&round.ZCL, x)
+		r.ScalarMultiplication(&round.ZCR, xInvBig)
+		zC.Add(&zC, &l).Add(&zC, &r)
+
+		var t fr.Element
+		t.Add(&one, &xInv)
+		s.Mul(&s, &t)
+	}
+
+	// ZAB = e(A, B)
+	res, err := curve.Pair([]curve.G1Affine{proof.FinalA}, []curve.G2Affine{proof.FinalB})
+	if err != nil {
+		return err
+	}
+	if !res.Equal(&zAB) {
+		return errInnerProductsMismatch
+	}
+
+	// ZC = s.C
+	var sC curve.G1Affine
+	sC.ScalarMultiplication(&proof.FinalC, bigInt(&s))
+	if !sC.Equal(&zC) {
+		return errInnerProductsMismatch
+	}
+
+	// commitments to (A, B) and C under the final keys
+	v := g2Key{a: proof.FinalV[:1], b: proof.FinalV[1:]}
+	w := g1Key{a: proof.FinalW[:1], b: proof.FinalW[1:]}
+	finalAB, err := commitPair(v, w, []curve.G1Affine{proof.FinalA}, []curve.G2Affine{proof.FinalB})
+	if err != nil {
+		return err
+	}
+	if !finalAB.T.Equal(&comAB.T) || !finalAB.U.Equal(&comAB.U) {
+		return errInnerProductsMismatch
+	}
+	finalC, err := commitG1(v, []curve.G1Affine{proof.FinalC})
+	if err != nil {
+		return err
+	}
+	if !finalC.T.Equal(&comC.T) || !finalC.U.Equal(&comC.U) {
+		return errInnerProductsMismatch
+	}
+
+	return nil
+}
+
+// verifyOpenings checks that the final keys are the evaluations at the secrets
+// of the SRS of the polynomials given by the challenges, with the KZG opening
+// proofs at z. For a secret τ and a polynomial f, the opening π of [f(τ)] is
+// checked by e([1]₁, [f(τ)]₂ - f(z)[1]₂) = e([τ]₁ - z[1]₁, π) in G2 and
+// e([f(τ)]₁ - f(z)[1]₁, [1]₂) = e(π, [τ]₂ - z[1]₂) in G1. The four checks are
+// batched with random coefficients.
+func (proof *Proof) verifyOpenings(vk *VerifyingKey, n int, r, z fr.Element, challenges []fr.Element) error {
+	var rInv fr.Element
+	rInv.Inverse(&r)
+	fv, fw := keyPolynomials(n, rInv, challenges)
+	fvz, fwz := evaluate(fv, z), evaluate(fw, z)
+	zBig := bigInt(&z)
+
+	var zG1 curve.G1Affine
+	zG1.ScalarMultiplication(&vk.G1.Gen, zBig)
+	var zG2 curve.G2Affine
+	zG2.ScalarMultiplication(&vk.G2.Gen, zBig)
+	var fvzG2 curve.G2Affine
+	fvzG2.ScalarMultiplication(&vk.G2.Gen, bigInt(&fvz))
+	var fwzG1 curve.G1Affine
+	fwzG1.ScalarMultiplication(&vk.G1.Gen, bigInt(&fwz))
+
+	var u fr.Element
+	if _, err := u.SetRandom(); err != nil {
+		return err
+	}
+	coeffs := powers(u, 4)
+
+	P := make([]curve.G1Affine, 0, 8)
+	Q := make([]curve.G2Affine, 0, 8)
+	for i, tau := range [2]curve.G1Affine{vk.G1.A, vk.G1.B} {
+		c := bigInt(&coeffs[i])
+		// e(c[1]₁, [f(τ)]₂ - f(z)[1]₂) . e(c(z[1]₁ - [τ]₁), π) = 1
+		var g, zMinusTau curve.G1Affine
+		g.ScalarMultiplication(&vk.G1.Gen, c)
+		zMinusTau.Sub(&zG1, &tau)
+		zMinusTau.ScalarMultiplication(&zMinusTau, c)
+		var fDiff curve.G2Affine
+		fDiff.Sub(&proof.FinalV[i], &fvzG2)
+		P = append(P, g, zMinusTau)
+		Q = append(Q, fDiff, proof.OpeningV[i])
+	}
+	for i, tau := range [2]curve.G2Affine{vk.G2.A, vk.G2.B} {
+		c := bigInt(&coeffs[2+i])
+		// e(c([f(τ)]₁ - f(z)[1]₁), [1]₂) . e(c.π, z[1]₂ - [τ]₂) = 1
+		var fDiff, pi curve.G1Affine
+		fDiff.Sub(&proof.FinalW[i], &fwzG1)
+		fDiff.ScalarMultiplication(&fDiff, c)
+		pi.ScalarMultiplication(&proof.OpeningW[i], c)
+		var zMinusTau curve.G2Affine
+		zMinusTau.Sub(&zG2, &tau)
+		P = append(P, fDiff, pi)
+		Q = append(Q, vk.G2.Gen, zMinusTau)
+	}
+
+	ok, err := curve.PairingCheck(P, Q)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errOpeningsMismatch
+	}
+	return nil
+}
+
+// verifyGroth16 checks the random linear combination of the Groth16 equations
+//
+//	ZAB = e(Σrⁱ.[α]₁, [β]₂) . e(Σrⁱ.[Kvkᵢ]₁, [γ]₂) . e(ZC, [δ]₂)
+//
+// where [Kvkᵢ]₁ is the sum of the public inputs of the proof i and of its
+// commitments, and the proofs of knowledge of the commitments.
+func (proof *Proof) verifyGroth16(vk *groth16.VerifyingKey, publicWitnesses []fr.Vector, r fr.Element, opt backend.VerifierConfig) error {
+	n := len(publicWitnesses)
+	rPowers := powers(r, n)
+	var rSum fr.Element
+	for i := range rPowers {
+		rSum.Add(&rSum, &rPowers[i])
+	}
+
+	// Σrⁱ.[Kvkᵢ]₁ = Σrⁱ.[K₀]₁ + Σⱼ (Σᵢ rⁱ.xᵢⱼ).[Kⱼ]₁ + Σᵢ rⁱ.Σ[Commitmentsᵢ]₁
+	scalars := make([]fr.Element, len(vk.G1.K)-1)
+	var t fr.Element
+	folded := make([]curve.G1Affine, n)
+	committed := make([]curve.G1Affine, 0, n*len(vk.PublicAndCommitmentCommitted))
+	committedScalars := make([]fr.Element, 0, cap(committed))
+	for i := range publicWitnesses {
+		var err error
+		publicWitness, commitmentsSerialized := completeWitness(vk, proof.Commitments[i], publicWitnesses[i], opt)
+		for j := range publicWitness {
+			t.Mul(&publicWitness[j], &rPowers[i])
+			scalars[j].Add(&scalars[j], &t)
+		}
+		if folded[i], err = pedersen.FoldCommitments(proof.Commitments[i], commitmentsSerialized); err != nil {
+			return err
+		}
+		for j := range proof.Commitments[i] {
+			committed = append(committed, proof.Commitments[i][j])
+			committedScalars = append(committedScalars, rPowers[i])
+		}
+	}
+	var kSum curve.G1Jac
+	if _, err := kSum.MultiExp(vk.G1.K[1:], scalars, ecc.MultiExpConfig{}); err != nil {
+		return err
+	}
+	var k0 curve.G1Affine
+	k0.ScalarMultiplication(&vk.G1.K[0], bigInt(&rSum))
+	kSum.AddMixed(&k0)
+	if len(committed) > 0 {
+		var commitmentsSum curve.G1Affine
+		if _, err := commitmentsSum.MultiExp(committed, committedScalars, ecc.MultiExpConfig{}); err != nil {
+			return err
+		}
+		kSum.AddMixed(&commitmentsSum)
+	}
+	var kSumAff curve.G1Affine
+	kSumAff.FromJacobian(&kSum)
+
+	if len(vk.PublicAndCommitmentCommitted) > 0 {
+		// e(Σsᵢ.Cᵢ, G) . e(Σsᵢ.PoKᵢ, -G/√σ) = 1 for random sᵢ
+		s := make([]fr.Element, n)
+		for i := range s {
+			if _, err := s[i].SetRandom(); err != nil {
+				return err
+			}
+		}
+		var c, pok curve.G1Affine
+		if _, err := c.MultiExp(folded, s, ecc.MultiExpConfig{}); err != nil {
+			return err
+		}
+		if _, err := pok.MultiExp(proof.CommitmentPoks, s, ecc.MultiExpConfig{}); err != nil {
+			return err
+		}
+		if err := vk.CommitmentKey.Verify(c, pok); err != nil {
+			return err
+		}
+	}
+
+	var alpha curve.G1Affine
+	alpha.ScalarMultiplication(&vk.G1.Alpha, bigInt(&rSum))
+	right, err := curve.Pair([]curve.G1Affine{alpha, kSumAff, proof.ZC}, []curve.G2Affine{vk.G2.Beta, vk.G2.Gamma, vk.G2.Delta})
+	if err != nil {
+		return err
+	}
+	if !right.Equal(&proof.ZAB) {
+		return errPairingCheckFailed
+	}
+	return nil
+}
+
+// completeWitness returns the public witness completed with the commitment
+// wires, as in the Groth16 verifier, and the serialized commitment wires.
+func completeWitness(vk *groth16.VerifyingKey, commitments []curve.G1Affine, publicWitness fr.Vector, opt backend.VerifierConfig) (fr.Vector, []byte) {
+	maxNbPublicCommitted := 0
+	for _, s := range vk.PublicAndCommitmentCommitted { // iterate over commitments
+		maxNbPublicCommitted = utils.Max(maxNbPublicCommitted, len(s))
+	}
+	commitmentsSerialized := make([]byte, len(vk.PublicAndCommitmentCommitted)*fr.Bytes)
+	commitmentPrehashSerialized := make([]byte, curve.SizeOfG1AffineUncompressed+maxNbPublicCommitted*fr.Bytes)
+	publicWitness = publicWitness[:len(publicWitness):len(publicWitness)]
+	for i := range vk.PublicAndCommitmentCommitted { // solveCommitmentWire
+		copy(commitmentPrehashSerialized, commitments[i].Marshal())
+		offset := curve.SizeOfG1AffineUncompressed
+		for j := range vk.PublicAndCommitmentCommitted[i] {
+			copy(commitmentPrehashSerialized[offset:], publicWitness[vk.PublicAndCommitmentCommitted[i][j]-1].Marshal())
+			offset += fr.Bytes
+		}
+		opt.HashToFieldFn.Write(commitmentPrehashSerialized[:offset])
+		hashBts := opt.HashToFieldFn.Sum(nil)
+		opt.HashToFieldFn.Reset()
+		nbBuf := fr.Bytes
+		if opt.HashToFieldFn.Size() < fr.Bytes {
+			nbBuf = opt.HashToFieldFn.Size()
+		}
+		var res fr.Element
+		res.SetBytes(hashBts[:nbBuf])
+		publicWitness = append(publicWitness, res)
+		copy(commitmentsSerialized[i*fr.Bytes:], res.Marshal())
+	}
+	return publicWitness, commitmentsSerialized
+}
+
+// foldGT sets v ← l^x . v . r^xInv.
+func foldGT(v, l, r *curve.GT, x, xInv *big.Int) {
+	var t curve.GT
+	t.Exp(*l, x)
+	v.Mul(v, &t)
+	t.Exp(*r, xInv)
+	v.Mul(v, &t)
+}
+
+// evaluate returns f(z).
+func evaluate(f []fr.Element, z fr.Element) fr.Element {
+	var res fr.Element
+	for i := len(f) - 1; i >= 0; i-- {
+		res.Mul(&res, &z).Add(&res, &f[i])
+	}
+	return res
+}
+
+// isValid checks that the points of the proof are in the correct subgroups.
+func (proof *Proof) isValid() bool {
+	g1 := []curve.G1Affine{proof.ZC, proof.FinalA, proof.FinalC, proof.FinalW[0], proof.FinalW[1], proof.OpeningW[0], proof.OpeningW[1]}
+	g1 = append(g1, proof.CommitmentPoks...)
+	for i := range proof.Commitments {
+		g1 = append(g1, proof.Commitments[i]...)
+	}
+	for i := range proof.Rounds {
+		g1 = append(g1, proof.Rounds[i].ZCL, proof.Rounds[i].ZCR)
+	}
+	for i := range g1 {
+		if !g1[i].IsInSubGroup() {
+			return false
+		}
+	}
+	for _, p := range []curve.G2Affine{proof.FinalB, proof.FinalV[0], proof.FinalV[1], proof.OpeningV[0], proof.OpeningV[1]} {
+		if !p.IsInSubGroup() {
+			return false
+		}
+	}
+	return true
+}
@@ -43,11 +43,20 @@ func TestContributionSerialization(t *testing.T) {
 	ccs, err := frontend.Compile(curve.ID.ScalarField(), r1cs.NewBuilder, &myCircuit)
 	assert.NoError(err)
 
-	r1cs := ccs.(*cs.R1CS)
-
 	// Phase 2
-	srs2, _ := InitPhase2(r1cs, &srs1)
+	srs2, evals := InitPhase2(ccs.(*cs.R1CS), &srs1)
+	srs2.Contribute()
+
+	assert.NoError(gnarkio.RoundTripCheck(&srs2, func() interface{} { return new(Phase2) }))
+	assert.NoError(gnarkio.RoundTripCheck(&evals, func() interface{} { return new(Phase2Evaluations) }))
+
+	// with a commitment
+	ccs, err = frontend.Compile(curve.ID.ScalarField(), r1cs.NewBuilder, &rangeCheckCircuit{})
+	assert.NoError(err)
+
+	srs2, evals = InitPhase2(ccs.(*cs.R1CS), &srs1)
 	srs2.Contribute()
 
 	assert.NoError(gnarkio.RoundTripCheck(&srs2, func() interface{} { return new(Phase2) }))
+	assert.NoError(gnarkio.RoundTripCheck(&evals, func() interface{} { return new(Phase2Evaluations) }))
 }
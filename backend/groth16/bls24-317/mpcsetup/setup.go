@@ -17,8 +17,11 @@
 package mpcsetup
 
 import (
+	"bytes"
+
 	curve "github.com/consensys/gnark-crypto/ecc/bls24-317"
 	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/fft"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/pedersen"
 	groth16 "github.com/consensys/gnark/backend/groth16/bls24-317"
 )
 
@@ -88,6 +91,15 @@ func ExtractKeys(srs1 *Phase1, srs2 *Phase2, evals *Phase2Evaluations, nConstrai
 	vk.G2.Gamma.Set(&g2)
 	vk.G1.K = evals.G1.VKK
 
+	// Commitment keys, the proofs of knowledge are checked against [σ]₂
+	pk.CommitmentKeys = make([]pedersen.ProvingKey, len(evals.G1.CKK))
+	for i := range pk.CommitmentKeys {
+		pk.CommitmentKeys[i] = newPedersenProvingKey(evals.G1.CKK[i], srs2.Parameters.G1.SigmaCKK[i])
+	}
+	vk.CommitmentKey.G.Set(&srs2.Parameters.G2.Sigma)
+	vk.CommitmentKey.GRootSigmaNeg.Neg(&g2)
+	vk.PublicAndCommitmentCommitted = evals.PublicAndCommitmentCommitted
+
 	// sets e, -[δ]2, -[γ]2
 	if err := vk.Precompute(); err != nil {
 		panic(err)
@@ -95,3 +107,21 @@ func ExtractKeys(srs1 *Phase1, srs2 *Phase2, evals *Phase2Evaluations, nConstrai
 
 	return pk, vk
 }
+
+// newPedersenProvingKey returns the key committing to the basis, with proofs of
+// knowledge on the basis multiplied by σ. As the fields of the key are not
+// exported, it is built from its serialized form.
+func newPedersenProvingKey(basis, basisExpSigma []curve.G1Affine) (pk pedersen.ProvingKey) {
+	var buf bytes.Buffer
+	enc := curve.NewEncoder(&buf, curve.RawEncoding())
+	if err := enc.Encode(basis); err != nil {
+		panic(err)
+	}
+	if err := enc.Encode(basisExpSigma); err != nil {
+		panic(err)
+	}
+	if _, err := pk.ReadFrom(&buf); err != nil {
+		panic(err)
+	}
+	return
+}
@@ -0,0 +1,322 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package snarkpack
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-317"
+	"io"
+)
+
+// WriteTo writes binary encoding of the Proof to writer.
+// Points are compressed, use WriteRawTo(...) to encode the proof without point
+// compression.
+func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w, false)
+}
+
+// WriteRawTo writes binary encoding of the Proof to writer.
+// Points are not compressed, use WriteTo(...) to encode the proof with point
+// compression.
+func (proof *Proof) WriteRawTo(w io.Writer) (int64, error) {
+	return proof.writeTo(w, true)
+}
+
+func (proof *Proof) writeTo(w io.Writer, raw bool) (int64, error) {
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
+
+	toEncode := []interface{}{
+		&gtElement{&proof.ComAB.T},
+		&gtElement{&proof.ComAB.U},
+		&gtElement{&proof.ComC.T},
+		&gtElement{&proof.ComC.U},
+		&gtElement{&proof.ZAB},
+		&proof.ZC,
+		uint32(len(proof.Commitments)),
+	}
+	for i := range proof.Commitments {
+		toEncode = append(toEncode, proof.Commitments[i])
+	}
+	toEncode = append(toEncode, proof.CommitmentPoks, uint32(len(proof.Rounds)))
+	for i := range proof.Rounds {
+		toEncode = append(toEncode, proof.Rounds[i].toEncode()...)
+	}
+	toEncode = append(toEncode,
+		&proof.FinalA,
+		&proof.FinalB,
+		&proof.FinalC,
+		&proof.FinalV[0],
+		&proof.FinalV[1],
+		&proof.OpeningV[0],
+		&proof.OpeningV[1],
+		&proof.FinalW[0],
+		&proof.FinalW[1],
+		&proof.OpeningW[0],
+		&proof.OpeningW[1],
+	)
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom attempts to decode a Proof from reader.
+// Proof must be encoded through WriteTo (compressed) or WriteRawTo (uncompressed).
+func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+
+	var nbProofs, nbRounds uint32
+	toDecode := []interface{}{
+		&gtElement{&proof.ComAB.T},
+		&gtElement{&proof.ComAB.U},
+		&gtElement{&proof.ComC.T},
+		&gtElement{&proof.ComC.U},
+		&gtElement{&proof.ZAB},
+		&proof.ZC,
+		&nbProofs,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	proof.Commitments = make([][]curve.G1Affine, nbProofs)
+	for i := range proof.Commitments {
+		if err := dec.Decode(&proof.Commitments[i]); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+	if err := dec.Decode(&proof.CommitmentPoks); err != nil {
+		return dec.BytesRead(), err
+	}
+	if err := dec.Decode(&nbRounds); err != nil {
+		return dec.BytesRead(), err
+	}
+
+	// the number of rounds is the logarithm of the number of proofs
+	if nbRounds > 32 {
+		return dec.BytesRead(), errInvalidProof
+	}
+	proof.Rounds = make([]Round, nbRounds)
+	toDecode = toDecode[:0]
+	for i := range proof.Rounds {
+		toDecode = append(toDecode, proof.Rounds[i].toEncode()...)
+	}
+	toDecode = append(toDecode,
+		&proof.FinalA,
+		&proof.FinalB,
+		&proof.FinalC,
+		&proof.FinalV[0],
+		&proof.FinalV[1],
+		&proof.OpeningV[0],
+		&proof.OpeningV[1],
+		&proof.FinalW[0],
+		&proof.FinalW[1],
+		&proof.OpeningW[0],
+		&proof.OpeningW[1],
+	)
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// toEncode returns the elements of the round to encode or decode.
+func (round *Round) toEncode() []interface{} {
+	return []interface{}{
+		&gtElement{&round.ComABL.T},
+		&gtElement{&round.ComABL.U},
+		&gtElement{&round.ComABR.T},
+		&gtElement{&round.ComABR.U},
+		&gtElement{&round.ZABL},
+		&gtElement{&round.ZABR},
+		&gtElement{&round.ComCL.T},
+		&gtElement{&round.ComCL.U},
+		&gtElement{&round.ComCR.T},
+		&gtElement{&round.ComCR.U},
+		&round.ZCL,
+		&round.ZCR,
+	}
+}
+
+// WriteTo writes binary encoding of the key to writer.
+// Points are compressed, use WriteRawTo(...) to encode the key without point
+// compression.
+func (pk *ProvingKey) WriteTo(w io.Writer) (int64, error) {
+	return pk.writeTo(w, false)
+}
+
+// WriteRawTo writes binary encoding of the key to writer.
+// Points are not compressed, use WriteTo(...) to encode the key with point
+// compression.
+func (pk *ProvingKey) WriteRawTo(w io.Writer) (int64, error) {
+	return pk.writeTo(w, true)
+}
+
+func (pk *ProvingKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
+
+	toEncode := []interface{}{
+		pk.G1.A,
+		pk.G1.B,
+		pk.G2.A,
+		pk.G2.B,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom attempts to decode a ProvingKey from reader.
+// ProvingKey must be encoded through WriteTo (compressed) or WriteRawTo
+// (uncompressed).
+func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	return pk.readFrom(r)
+}
+
+// UnsafeReadFrom behaves like ReadFrom excepts it doesn't check if the decoded
+// points are on the curve or in the correct subgroup.
+func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return pk.readFrom(r, curve.NoSubgroupChecks())
+}
+
+func (pk *ProvingKey) readFrom(r io.Reader, decOptions ...func(*curve.Decoder)) (int64, error) {
+	dec := curve.NewDecoder(r, decOptions...)
+
+	toDecode := []interface{}{
+		&pk.G1.A,
+		&pk.G1.B,
+		&pk.G2.A,
+		&pk.G2.B,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// WriteTo writes binary encoding of the key to writer.
+// Points are compressed, use WriteRawTo(...) to encode the key without point
+// compression.
+func (vk *VerifyingKey) WriteTo(w io.Writer) (int64, error) {
+	return vk.writeTo(w, false)
+}
+
+// WriteRawTo writes binary encoding of the key to writer.
+// Points are not compressed, use WriteTo(...) to encode the key with point
+// compression.
+func (vk *VerifyingKey) WriteRawTo(w io.Writer) (int64, error) {
+	return vk.writeTo(w, true)
+}
+
+func (vk *VerifyingKey) writeTo(w io.Writer, raw bool) (int64, error) {
+	var enc *curve.Encoder
+	if raw {
+		enc = curve.NewEncoder(w, curve.RawEncoding())
+	} else {
+		enc = curve.NewEncoder(w)
+	}
+
+	toEncode := []interface{}{
+		&vk.G1.Gen,
+		&vk.G1.A,
+		&vk.G1.B,
+		&vk.G2.Gen,
+		&vk.G2.A,
+		&vk.G2.B,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom attempts to decode a VerifyingKey from reader.
+// VerifyingKey must be encoded through WriteTo (compressed) or WriteRawTo
+// (uncompressed).
+func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+
+	toDecode := []interface{}{
+		&vk.G1.Gen,
+		&vk.G1.A,
+		&vk.G1.B,
+		&vk.G2.Gen,
+		&vk.G2.A,
+		&vk.G2.B,
+	}
+
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// gtElement wraps an element of the target group for the encoder and decoder
+// of the curve, which don't support them.
+type gtElement struct {
+	*curve.GT
+}
+
+func (e *gtElement) WriteTo(w io.Writer) (int64, error) {
+	b := e.GT.Bytes()
+	n, err := w.Write(b[:])
+	return int64(n), err
+}
+
+func (e *gtElement) ReadFrom(r io.Reader) (int64, error) {
+	var b [curve.SizeOfGT]byte
+	n, err := io.ReadFull(r, b[:])
+	if err != nil {
+		return int64(n), err
+	}
+	return int64(n), e.GT.SetBytes(b[:])
+}
@@ -0,0 +1,495 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package snarkpack
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-317"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls24-317/fr/hash_to_field"
+	groth16 "github.com/consensys/gnark/backend/groth16/bls24-317"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// transcriptDST is the domain separation tag of the challenges.
+const transcriptDST = "SnarkPack"
+
+// Commitment is the commitment to a vector of points under the keys of the two
+// secrets of the SRS.
+type Commitment struct {
+	T, U curve.GT
+}
+
+// Round holds the cross terms sent by the prover at a round of the inner
+// product arguments, which halves the size of the vectors. The left terms
+// pair the right half of the vectors of G1 elements with the left half of the
+// vectors of G2 elements and the right terms the other way around.
+type Round struct {
+	ComABL, ComABR Commitment
+	ZABL, ZABR     curve.GT
+	ComCL, ComCR   Commitment
+	ZCL, ZCR       curve.G1Affine
+}
+
+// Proof is an aggregation of Groth16 proofs for the same verifying key.
+//
+// It holds the commitments to the A, B and C elements of the proofs, their
+// random linear combinations ZAB = Π e(rⁱ.Aᵢ, Bᵢ) and ZC = Σ rⁱ.Cᵢ, and the
+// arguments (TIPP and MIPP in SnarkPack) that they are consistent, of size
+// logarithmic in the number of proofs. The commitments of the Groth16
+// commitment extension can't be aggregated as the verifier needs them to
+// derive the public inputs, so they are kept with their proofs of knowledge.
+type Proof struct {
+	ComAB, ComC Commitment
+	ZAB         curve.GT
+	ZC          curve.G1Affine
+
+	Commitments    [][]curve.G1Affine // Commitments of the proofs
+	CommitmentPoks []curve.G1Affine   // CommitmentPok of the proofs
+
+	Rounds []Round
+
+	FinalA, FinalC curve.G1Affine
+	FinalB         curve.G2Affine
+
+	// FinalV and FinalW are the commitment keys at the end of the rounds, at the
+	// secrets a and b, with their KZG opening proofs.
+	FinalV, OpeningV [2]curve.G2Affine
+	FinalW, OpeningW [2]curve.G1Affine
+}
+
+// Aggregate returns the aggregation of the proofs, which must be for the same
+// Groth16 verifying key. The number of proofs must be a power of two, at most
+// [ProvingKey.NbProofs]. The public witnesses of the proofs are bound to the
+// aggregated proof and must be given again to [Verify].
+func Aggregate(pk *ProvingKey, proofs []*groth16.Proof, publicWitnesses []fr.Vector) (*Proof, error) {
+	n := len(proofs)
+	if n == 0 || n&(n-1) != 0 {
+		return nil, ErrNbProofs
+	}
+	if n > pk.NbProofs() {
+		return nil, ErrSRSTooSmall
+	}
+	if len(publicWitnesses) != n {
+		return nil, errors.New("invalid number of public witnesses")
+	}
+
+	a := make([]curve.G1Affine, n)
+	b := make([]curve.G2Affine, n)
+	c := make([]curve.G1Affine, n)
+	res := &Proof{
+		Commitments:    make([][]curve.G1Affine, n),
+		CommitmentPoks: make([]curve.G1Affine, n),
+	}
+	for i, p := range proofs {
+		a[i], b[i], c[i] = p.Ar, p.Bs, p.Krs
+		res.Commitments[i] = p.Commitments
+		res.CommitmentPoks[i] = p.CommitmentPok
+	}
+
+	// the keys are modified by the rounds
+	v := newG2Key(pk.G2.A[:n], pk.G2.B[:n])
+	w := newG1Key(pk.G1.A[n:2*n], pk.G1.B[n:2*n])
+
+	var err error
+	if res.ComAB, err = commitPair(v, w, a, b); err != nil {
+		return nil, err
+	}
+	if res.ComC, err = commitG1(v, c); err != nil {
+		return nil, err
+	}
+
+	fs := newTranscript()
+	fs.bind(gtBytes(&res.ComAB.T), gtBytes(&res.ComAB.U), gtBytes(&res.ComC.T), gtBytes(&res.ComC.U))
+	bindInputs(fs, publicWitnesses, res.Commitments)
+	r := fs.challenge()
+
+	// A ← (rⁱ.Aᵢ), C ← (rⁱ.Cᵢ) and the keys of A and C are rescaled by r⁻ⁱ so
+	// that the commitments are unchanged
+	rPowers := powers(r, n)
+	scaleG1(a, rPowers)
+	scaleG1(c, rPowers)
+	var rInv fr.Element
+	rInv.Inverse(&r)
+	rInvPowers := powers(rInv, n)
+	scaleG2(v.a, rInvPowers)
+	scaleG2(v.b, rInvPowers)
+
+	if res.ZAB, err = curve.Pair(a, b); err != nil {
+		return nil, err
+	}
+	res.ZC = sumG1(c)
+	fs.bind(gtBytes(&res.ZAB), res.ZC.Marshal())
+
+	challenges, err := res.prove(fs, v, w, a, b, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// open the final keys at a random point
+	fs.bind(res.FinalV[0].Marshal(), res.FinalV[1].Marshal(), res.FinalW[0].Marshal(), res.FinalW[1].Marshal())
+	z := fs.challenge()
+	fv, fw := keyPolynomials(n, rInv, challenges)
+	if res.OpeningV[0], err = openG2(fv, z, pk.G2.A); err != nil {
+		return nil, err
+	}
+	if res.OpeningV[1], err = openG2(fv, z, pk.G2.B); err != nil {
+		return nil, err
+	}
+	if res.OpeningW[0], err = openG1(fw, z, pk.G1.A); err != nil {
+		return nil, err
+	}
+	if res.OpeningW[1], err = openG1(fw, z, pk.G1.B); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// prove runs the rounds of the inner product arguments (TIPP for ZAB and MIPP
+// for ZC), each halving the size of the vectors and of the keys, and returns
+// the challenges of the rounds.
+//
+// ZC is the inner product of C with the vector of ones, whose entries stay
+// equal to each other over the rounds, so that it is tracked as a single
+// scalar s.
+func (proof *Proof) prove(fs *transcript, v g2Key, w g1Key, a []curve.G1Affine, b []curve.G2Affine, c []curve.G1Affine) ([]fr.Element, error) {
+	var challenges []fr.Element
+	var s, one fr.Element
+	s.SetOne()
+	one.SetOne()
+
+	for m := len(a); m > 1; m /= 2 {
+		h := m / 2
+		vL, vR := v.slice(0, h), v.slice(h, m)
+		wL, wR := w.slice(0, h), w.slice(h, m)
+
+		var round Round
+		var errs [6]error
+		var wg sync.WaitGroup
+		wg.Add(6)
+		go func() {
+			round.ComABL, errs[0] = commitPair(vL, wR, a[h:m], b[:h])
+			wg.Done()
+		}()
+		go func() {
+			round.ComABR, errs[1] = commitPair(vR, wL, a[:h], b[h:m])
+			wg.Done()
+		}()
+		go func() {
+			round.ZABL, errs[2] = curve.Pair(a[h:m], b[:h])
+			wg.Done()
+		}()
+		go func() {
+			round.ZABR, errs[3] = curve.Pair(a[:h], b[h:m])
+			wg.Done()
+		}()
+		go func() {
+			round.ComCL, errs[4] = commitG1(vL, c[h:m])
+			wg.Done()
+		}()
+		go func() {
+			round.ComCR, errs[5] = commitG1(vR, c[:h])
+			wg.Done()
+		}()
+		sBig := bigInt(&s)
+		round.ZCL = sumG1(c[h:m])
+		round.ZCL.ScalarMultiplication(&round.ZCL, sBig)
+		round.ZCR = sumG1(c[:h])
+		round.ZCR.ScalarMultiplication(&round.ZCR, sBig)
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		fs.bind(round.bytes()...)
+		x := fs.challenge()
+		var xInv fr.Element
+		xInv.Inverse(&x)
+
+		// A ← A_L + x.A_R, B ← B_L + x⁻¹.B_R, C ← C_L + x.C_R
+		// v ← v_L + x⁻¹.v_R, w ← w_L + x.w_R
+		compressG1(a[:m], x)
+		compressG2(b[:m], xInv)
+		compressG1(c[:m], x)
+		compressG2(v.a[:m], xInv)
+		compressG2(v.b[:m], xInv)
+		compressG1(w.a[:m], x)
+		compressG1(w.b[:m], x)
+		var t fr.Element
+		t.Add(&one, &xInv)
+		s.Mul(&s, &t)
+
+		proof.Rounds = append(proof.Rounds, round)
+		challenges = append(challenges, x)
+	}
+
+	proof.FinalA, proof.FinalB, proof.FinalC = a[0], b[0], c[0]
+	proof.FinalV = [2]curve.G2Affine{v.a[0], v.b[0]}
+	proof.FinalW = [2]curve.G1Affine{w.a[0], w.b[0]}
+
+	return challenges, nil
+}
+
+// keyPolynomials returns the coefficients of the polynomials fv and fw such
+// that the final keys are [fv(a)]₂, [fv(b)]₂, [fw(a)]₁ and [fw(b)]₁. For the
+// challenges xⱼ and nⱼ = n/2ʲ⁺¹
+//
+//	fv(X) = Π (1 + xⱼ⁻¹.r⁻ⁿʲ.Xⁿʲ)
+//	fw(X) = Xⁿ.Π (1 + xⱼ.Xⁿʲ)
+func keyPolynomials(n int, rInv fr.Element, challenges []fr.Element) (fv, fw []fr.Element) {
+	kv := make([]fr.Element, len(challenges))
+	kw := make([]fr.Element, len(challenges))
+	for j := range challenges {
+		nj := uint64(n >> (j + 1))
+		var rInvNj fr.Element
+		rInvNj.Exp(rInv, new(big.Int).SetUint64(nj))
+		kv[j].Inverse(&challenges[j]).Mul(&kv[j], &rInvNj)
+		kw[j] = challenges[j]
+	}
+	fv = productPolynomial(n, kv)
+	fw = make([]fr.Element, 2*n)
+	copy(fw[n:], productPolynomial(n, kw))
+	return
+}
+
+// productPolynomial returns the coefficients of Π (1 + kⱼ.Xⁿʲ), nⱼ = n/2ʲ⁺¹.
+// The coefficient of Xⁱ is the product of the kⱼ for which the bit of nⱼ is
+// set in i.
+func productPolynomial(n int, k []fr.Element) []fr.Element {
+	res := make([]fr.Element, n)
+	res[0].SetOne()
+	size := 1
+	for j := len(k) - 1; j >= 0; j-- {
+		for i := 0; i < size; i++ {
+			res[size+i].Mul(&res[i], &k[j])
+		}
+		size *= 2
+	}
+	return res
+}
+
+// quotient returns the coefficients of (f(X) - f(z))/(X - z).
+func quotient(f []fr.Element, z fr.Element) []fr.Element {
+	if len(f) < 2 {
+		return nil
+	}
+	q := make([]fr.Element, len(f)-1)
+	q[len(q)-1] = f[len(f)-1]
+	for i := len(q) - 1; i > 0; i-- {
+		q[i-1].Mul(&q[i], &z).Add(&q[i-1], &f[i])
+	}
+	return q
+}
+
+// openG1 returns the KZG opening proof of f at z for the powers in G1.
+func openG1(f []fr.Element, z fr.Element, powers []curve.G1Affine) (curve.G1Affine, error) {
+	var res curve.G1Affine
+	q := quotient(f, z)
+	if len(q) == 0 {
+		return res, nil
+	}
+	_, err := res.MultiExp(powers[:len(q)], q, ecc.MultiExpConfig{})
+	return res, err
+}
+
+// openG2 returns the KZG opening proof of f at z for the powers in G2.
+func openG2(f []fr.Element, z fr.Element, powers []curve.G2Affine) (curve.G2Affine, error) {
+	var res curve.G2Affine
+	q := quotient(f, z)
+	if len(q) == 0 {
+		return res, nil
+	}
+	_, err := res.MultiExp(powers[:len(q)], q, ecc.MultiExpConfig{})
+	return res, err
+}
+
+// g1Key is a commitment key in G1, for the B elements of the proofs.
+type g1Key struct {
+	a, b []curve.G1Affine
+}
+
+func newG1Key(a, b []curve.G1Affine) g1Key {
+	return g1Key{a: append([]curve.G1Affine{}, a...), b: append([]curve.G1Affine{}, b...)}
+}
+
+func (k g1Key) slice(from, to int) g1Key {
+	return g1Key{a: k.a[from:to], b: k.b[from:to]}
+}
+
+// g2Key is a commitment key in G2, for the A and C elements of the proofs.
+type g2Key struct {
+	a, b []curve.G2Affine
+}
+
+func newG2Key(a, b []curve.G2Affine) g2Key {
+	return g2Key{a: append([]curve.G2Affine{}, a...), b: append([]curve.G2Affine{}, b...)}
+}
+
+func (k g2Key) slice(from, to int) g2Key {
+	return g2Key{a: k.a[from:to], b: k.b[from:to]}
+}
+
+// commitPair returns the commitment to the vectors x and y:
+// T = Π e(xᵢ, vₐᵢ).e(wₐᵢ, yᵢ) and U = Π e(xᵢ, v_bᵢ).e(w_bᵢ, yᵢ).
+func commitPair(v g2Key, w g1Key, x []curve.G1Affine, y []curve.G2Affine) (Commitment, error) {
+	var res Commitment
+	var err error
+	P := append(append(make([]curve.G1Affine, 0, 2*len(x)), x...), w.a...)
+	Q := append(append(make([]curve.G2Affine, 0, 2*len(x)), v.a...), y...)
+	if res.T, err = curve.Pair(P, Q); err != nil {
+		return res, err
+	}
+	copy(P[len(x):], w.b)
+	copy(Q, v.b)
+	res.U, err = curve.Pair(P, Q)
+	return res, err
+}
+
+// commitG1 returns the commitment to the vector x: T = Π e(xᵢ, vₐᵢ) and
+// U = Π e(xᵢ, v_bᵢ).
+func commitG1(v g2Key, x []curve.G1Affine) (Commitment, error) {
+	var res Commitment
+	var err error
+	if res.T, err = curve.Pair(x, v.a); err != nil {
+		return res, err
+	}
+	res.U, err = curve.Pair(x, v.b)
+	return res, err
+}
+
+// sumG1 returns Σ xᵢ.
+func sumG1(x []curve.G1Affine) curve.G1Affine {
+	var acc curve.G1Jac
+	for i := range x {
+		acc.AddMixed(&x[i])
+	}
+	var res curve.G1Affine
+	res.FromJacobian(&acc)
+	return res
+}
+
+// scaleG1 sets xᵢ ← sᵢ.xᵢ.
+func scaleG1(x []curve.G1Affine, s []fr.Element) {
+	utils.Parallelize(len(x), func(start, end int) {
+		for i := start; i < end; i++ {
+			x[i].ScalarMultiplication(&x[i], bigInt(&s[i]))
+		}
+	})
+}
+
+// scaleG2 sets xᵢ ← sᵢ.xᵢ.
+func scaleG2(x []curve.G2Affine, s []fr.Element) {
+	utils.Parallelize(len(x), func(start, end int) {
+		for i := start; i < end; i++ {
+			x[i].ScalarMultiplication(&x[i], bigInt(&s[i]))
+		}
+	})
+}
+
+// compressG1 sets the left half of x to x_L + s.x_R.
+func compressG1(x []curve.G1Affine, s fr.Element) {
+	h := len(x) / 2
+	sBig := bigInt(&s)
+	utils.Parallelize(h, func(start, end int) {
+		var t curve.G1Affine
+		for i := start; i < end; i++ {
+			t.ScalarMultiplication(&x[h+i], sBig)
+			x[i].Add(&x[i], &t)
+		}
+	})
+}
+
+// compressG2 sets the left half of x to x_L + s.x_R.
+func compressG2(x []curve.G2Affine, s fr.Element) {
+	h := len(x) / 2
+	sBig := bigInt(&s)
+	utils.Parallelize(h, func(start, end int) {
+		var t curve.G2Affine
+		for i := start; i < end; i++ {
+			t.ScalarMultiplication(&x[h+i], sBig)
+			x[i].Add(&x[i], &t)
+		}
+	})
+}
+
+// bytes returns the serialization of the round for the transcript.
+func (round *Round) bytes() [][]byte {
+	return [][]byte{
+		gtBytes(&round.ComABL.T), gtBytes(&round.ComABL.U),
+		gtBytes(&round.ComABR.T), gtBytes(&round.ComABR.U),
+		gtBytes(&round.ZABL), gtBytes(&round.ZABR),
+		gtBytes(&round.ComCL.T), gtBytes(&round.ComCL.U),
+		gtBytes(&round.ComCR.T), gtBytes(&round.ComCR.U),
+		round.ZCL.Marshal(), round.ZCR.Marshal(),
+	}
+}
+
+// transcript derives the challenges of the aggregation. Every challenge is the
+// hash to the field of the previous challenge and of the elements bound since.
+type transcript struct {
+	h        hash.Hash
+	previous fr.Element
+}
+
+func newTranscript() *transcript {
+	return &transcript{h: hash_to_field.New([]byte(transcriptDST))}
+}
+
+// bind adds the elements to the next challenge.
+func (t *transcript) bind(data ...[]byte) {
+	for _, d := range data {
+		t.h.Write(d)
+	}
+}
+
+// challenge returns a new non-zero challenge.
+func (t *transcript) challenge() fr.Element {
+	for {
+		t.h.Write(t.previous.Marshal())
+		t.previous.SetBytes(t.h.Sum(nil))
+		t.h.Reset()
+		if !t.previous.IsZero() {
+			return t.previous
+		}
+	}
+}
+
+// gtBytes returns the serialization of x.
+func gtBytes(x *curve.GT) []byte {
+	b := x.Bytes()
+	return b[:]
+}
+
+// bindInputs binds the public witnesses and the commitments of the proofs.
+func bindInputs(fs *transcript, publicWitnesses []fr.Vector, commitments [][]curve.G1Affine) {
+	for i := range publicWitnesses {
+		for j := range publicWitnesses[i] {
+			fs.bind(publicWitnesses[i][j].Marshal())
+		}
+		for j := range commitments[i] {
+			fs.bind(commitments[i][j].Marshal())
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package groth16
+
+// cairoTemplate is the Cairo port of solidityTemplate, for Starknet, without
+// the compressed proofs. The proof holds the words of MarshalSolidity and the
+// public inputs are encoded as for verifyProof in the Solidity contract, so
+// that the proofs with commitments must be generated with
+// solidity.WithProverTargetSolidityVerifier.
+//
+// Starknet has no precompiles for BN254, so the operations on the curve are
+// delegated to a contract implementing IBn254Curve.
+//
+// The generated contract is compiled and executed with Scarb in the tests with
+// the cairocheck build tag.
+const cairoTemplate = `
+{{- $numCommitments := len .PublicAndCommitmentCommitted -}}
+{{- $numPublic := sub (sub (len .G1.K) $numCommitments) 1 -}}
+{{- $proofSize := 8 -}}
+{{- if gt $numCommitments 0 }}{{ $proofSize = add 10 (mul $numCommitments 2) }}{{ end -}}
+// SPDX-License-Identifier: MIT
+// Code generated by gnark DO NOT EDIT
+
+//! Groth16 verifier on BN254{{ if gt $numCommitments 0 }} with {{ $numCommitments }} BSB22 commitment(s){{ end }} for Starknet.
+//!
+//! Starknet doesn't provide the BN254 precompiles of the EVM: the multi scalar
+//! multiplications and the pairing check are delegated to a contract implementing
+//! IBn254Curve (for instance built with Garaga), whose address is given to the
+//! constructor.
+
+/// A point of G1, in affine coordinates.
+#[derive(Copy, Drop, Serde)]
+pub struct G1Point {
+    pub x: u256,
+    pub y: u256,
+}
+
+/// A point of G2, in affine coordinates x = x0 + x1⋅i, y = y0 + y1⋅i.
+#[derive(Copy, Drop, Serde)]
+pub struct G2Point {
+    pub x0: u256,
+    pub x1: u256,
+    pub y0: u256,
+    pub y1: u256,
+}
+
+#[starknet::interface]
+pub trait IBn254Curve<TContractState> {
+    /// Returns ∑ᵢ scalarsᵢ⋅pointsᵢ. Reverts if a point is not on the curve.
+    fn msm(self: @TContractState, points: Span<G1Point>, scalars: Span<u256>) -> G1Point;
+    /// Returns true if ∏ᵢ e(aᵢ, bᵢ) = 1. Reverts if a point is not in its group.
+    fn pairing_check(self: @TContractState, a: Span<G1Point>, b: Span<G2Point>) -> bool;
+}
+
+#[starknet::interface]
+pub trait IGroth16Verifier<TContractState> {
+    /// Verify an uncompressed Groth16 proof.
+    /// Reverts if the proof or the public inputs are malformed.
+    {{- if gt $numCommitments 0 }}
+    /// proof: the points (A, B, C) in EIP-197 format, followed by the commitments
+    /// and their proof of knowledge in EIP-196 format, as encoded by MarshalSolidity.
+    {{- else }}
+    /// proof: the points (A, B, C) in EIP-197 format, as for verifyProof in the
+    /// Solidity contract.
+    {{- end }}
+    /// public_inputs: the public input field elements in the scalar field Fr.
+    /// Elements must be reduced.
+    fn verify_proof(self: @TContractState, proof: Span<u256>, public_inputs: Span<u256>) -> bool;
+}
+
+#[starknet::contract]
+pub mod Groth16Verifier {
+    {{- if gt $numCommitments 0 }}
+    use core::integer::u128_byte_reverse;
+    use core::keccak::keccak_u256s_be_inputs;
+    {{- if gt $numCommitments 2 }}
+    use core::math::u256_mul_mod_n;
+    {{- end }}
+    {{- if gt $numCommitments 1 }}
+    use core::sha256::compute_sha256_byte_array;
+    {{- end }}
+    {{- end }}
+    use starknet::ContractAddress;
+    use starknet::storage::{StoragePointerReadAccess, StoragePointerWriteAccess};
+    use super::{G1Point, G2Point, IBn254CurveDispatcher, IBn254CurveDispatcherTrait};
+
+    // Scalar field Fr order R
+    const R: u256 = 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001;
+
+    const NB_PUBLIC_INPUTS: u32 = {{$numPublic}};
+
+    // Groth16 alpha point in G1
+    const ALPHA_X: u256 = {{.G1.Alpha.X.String}};
+    const ALPHA_Y: u256 = {{.G1.Alpha.Y.String}};
+
+    // Groth16 beta point in G2 in powers of i
+    const BETA_NEG_X_0: u256 = {{.G2.Beta.X.A0.String}};
+    const BETA_NEG_X_1: u256 = {{.G2.Beta.X.A1.String}};
+    const BETA_NEG_Y_0: u256 = {{.G2.Beta.Y.A0.String}};
+    const BETA_NEG_Y_1: u256 = {{.G2.Beta.Y.A1.String}};
+
+    // Groth16 gamma point in G2 in powers of i
+    const GAMMA_NEG_X_0: u256 = {{.G2.Gamma.X.A0.String}};
+    const GAMMA_NEG_X_1: u256 = {{.G2.Gamma.X.A1.String}};
+    const GAMMA_NEG_Y_0: u256 = {{.G2.Gamma.Y.A0.String}};
+    const GAMMA_NEG_Y_1: u256 = {{.G2.Gamma.Y.A1.String}};
+
+    // Groth16 delta point in G2 in powers of i
+    const DELTA_NEG_X_0: u256 = {{.G2.Delta.X.A0.String}};
+    const DELTA_NEG_X_1: u256 = {{.G2.Delta.X.A1.String}};
+    const DELTA_NEG_Y_0: u256 = {{.G2.Delta.Y.A0.String}};
+    const DELTA_NEG_Y_1: u256 = {{.G2.Delta.Y.A1.String}};
+
+    // Constant and public input points
+    {{- $k0 := index .G1.K 0}}
+    const CONSTANT_X: u256 = {{$k0.X.String}};
+    const CONSTANT_Y: u256 = {{$k0.Y.String}};
+    {{- range $i, $ki := .G1.K }}
+    {{- if gt $i 0 }}
+    const PUB_{{sub $i 1}}_X: u256 = {{$ki.X.String}};
+    const PUB_{{sub $i 1}}_Y: u256 = {{$ki.Y.String}};
+    {{- end }}
+    {{- end }}
+    {{- if gt $numCommitments 0 }}
+
+    // Pedersen G point in G2 in powers of i
+    const PEDERSEN_G_X_0: u256 = {{.CommitmentKey.G.X.A0.String}};
+    const PEDERSEN_G_X_1: u256 = {{.CommitmentKey.G.X.A1.String}};
+    const PEDERSEN_G_Y_0: u256 = {{.CommitmentKey.G.Y.A0.String}};
+    const PEDERSEN_G_Y_1: u256 = {{.CommitmentKey.G.Y.A1.String}};
+
+    // Pedersen GRootSigmaNeg point in G2 in powers of i
+    const PEDERSEN_GROOTSIGMANEG_X_0: u256 = {{.CommitmentKey.GRootSigmaNeg.X.A0.String}};
+    const PEDERSEN_GROOTSIGMANEG_X_1: u256 = {{.CommitmentKey.GRootSigmaNeg.X.A1.String}};
+    const PEDERSEN_GROOTSIGMANEG_Y_0: u256 = {{.CommitmentKey.GRootSigmaNeg.Y.A0.String}};
+    const PEDERSEN_GROOTSIGMANEG_Y_1: u256 = {{.CommitmentKey.GRootSigmaNeg.Y.A1.String}};
+    {{- end }}
+
+    #[storage]
+    struct Storage {
+        curve: ContractAddress,
+    }
+
+    #[constructor]
+    fn constructor(ref self: ContractState, curve: ContractAddress) {
+        self.curve.write(curve);
+    }
+
+    #[abi(embed_v0)]
+    impl Groth16VerifierImpl of super::IGroth16Verifier<ContractState> {
+        fn verify_proof(self: @ContractState, proof: Span<u256>, public_inputs: Span<u256>) -> bool {
+            assert(proof.len() == {{$proofSize}}, 'wrong proof size');
+            assert(public_inputs.len() == NB_PUBLIC_INPUTS, 'wrong number of public inputs');
+            let curve = IBn254CurveDispatcher { contract_address: self.curve.read() };
+            {{- if gt $numCommitments 0 }}
+
+            let commitments = array![
+                {{- range $i := intRange $numCommitments }}
+                G1Point { x: *proof.at({{add 8 (mul $i 2)}}), y: *proof.at({{add 9 (mul $i 2)}}) },
+                {{- end }}
+            ];
+            let commitment_pok = G1Point { x: *proof.at({{sub $proofSize 2}}), y: *proof.at({{sub $proofSize 1}}) };
+            let public_commitments = hash_commitments(proof, public_inputs);
+            if !verify_commitment_pok(curve, commitments.span(), public_commitments.span(), commitment_pok) {
+                return false;
+            }
+            {{- end }}
+
+            // L_pub = CONSTANT + ∑ᵢ inputᵢ⋅PUBᵢ
+            {{- if gt $numCommitments 0 }}
+            // where the public inputs of the commitments are appended to the inputs,
+            // and the commitments are added.
+            {{- end }}
+            let {{ if gt $numCommitments 0 }}mut {{ end }}points = array![
+                G1Point { x: CONSTANT_X, y: CONSTANT_Y },
+                {{- range $i := intRange (sub (len .G1.K) 1) }}
+                G1Point { x: PUB_{{$i}}_X, y: PUB_{{$i}}_Y },
+                {{- end }}
+            ];
+            let mut scalars = array![1_u256];
+            for input in public_inputs {
+                assert(*input < R, 'public input not in field');
+                scalars.append(*input);
+            };
+            {{- if gt $numCommitments 0 }}
+            scalars.append_span(public_commitments.span());
+            for commitment in commitments {
+                points.append(commitment);
+                scalars.append(1);
+            };
+            {{- end }}
+            let l = curve.msm(points.span(), scalars.span());
+
+            // The proof encodes the F2 coefficients in big-endian order.
+            let a = G1Point { x: *proof.at(0), y: *proof.at(1) };
+            let b = G2Point { x0: *proof.at(3), x1: *proof.at(2), y0: *proof.at(5), y1: *proof.at(4) };
+            let c = G1Point { x: *proof.at(6), y: *proof.at(7) };
+
+            // e(A, B)⋅e(C, -δ)⋅e(α, -β)⋅e(L_pub, -γ) = 1
+            curve
+                .pairing_check(
+                    array![a, c, G1Point { x: ALPHA_X, y: ALPHA_Y }, l].span(),
+                    array![
+                        b,
+                        G2Point { x0: DELTA_NEG_X_0, x1: DELTA_NEG_X_1, y0: DELTA_NEG_Y_0, y1: DELTA_NEG_Y_1 },
+                        G2Point { x0: BETA_NEG_X_0, x1: BETA_NEG_X_1, y0: BETA_NEG_Y_0, y1: BETA_NEG_Y_1 },
+                        G2Point { x0: GAMMA_NEG_X_0, x1: GAMMA_NEG_X_1, y0: GAMMA_NEG_Y_0, y1: GAMMA_NEG_Y_1 },
+                    ]
+                        .span(),
+                )
+        }
+    }
+    {{- if gt $numCommitments 0 }}
+
+    /// Returns the public inputs of the commitments encoded in the proof after
+    /// (A, B, C). Each commitment is hashed with the public inputs it commits to,
+    /// as keccak256(commitment || committed inputs) mod R.
+    fn hash_commitments(proof: Span<u256>, public_inputs: Span<u256>) -> Array<u256> {
+        let mut res = array![];
+        {{- range $i, $committed := .PublicAndCommitmentCommitted }}
+        let h = keccak_be(
+            array![
+                *proof.at({{add 8 (mul $i 2)}}),
+                *proof.at({{add 9 (mul $i 2)}}),
+                {{- range $j := $committed }}
+                {{- if lt (sub $j 1) $numPublic }}
+                *public_inputs.at({{sub $j 1}}),
+                {{- else }}
+                *res.at({{sub (sub $j 1) $numPublic}}),
+                {{- end }}
+                {{- end }}
+            ]
+                .span(),
+        );
+        res.append(h % R);
+        {{- end }}
+        res
+    }
+
+    /// Verifies the proof of knowledge of the commitments.
+    {{- if gt $numCommitments 1 }}
+    /// The commitments are folded with the powers of a challenge derived from their
+    /// public inputs, as in gnark-crypto's pedersen.FoldCommitments.
+    {{- end }}
+    fn verify_commitment_pok(
+        curve: IBn254CurveDispatcher,
+        commitments: Span<G1Point>,
+        {{- if gt $numCommitments 1 }}
+        public_commitments: Span<u256>,
+        {{- else }}
+        _public_commitments: Span<u256>,
+        {{- end }}
+        commitment_pok: G1Point,
+    ) -> bool {
+        {{- if eq $numCommitments 1 }}
+        let folded = *commitments.at(0);
+        {{- else }}
+        // ∑ᵢ rⁱ⋅Cᵢ where r = sha256("r" || public_commitments) mod R
+        let mut data: ByteArray = "r";
+        for x in public_commitments {
+            data.append_word((*x.high).into(), 16);
+            data.append_word((*x.low).into(), 16);
+        };
+        let [h0, h1, h2, h3, h4, h5, h6, h7] = compute_sha256_byte_array(@data);
+        let mut r: u256 = 0;
+        for h in array![h0, h1, h2, h3, h4, h5, h6, h7] {
+            r = r * 0x100000000 + h.into();
+        };
+        let r = r % R;
+        {{- range $i := intRange $numCommitments }}
+        {{- if gt $i 1 }}
+        let r_{{$i}} = u256_mul_mod_n({{ if eq $i 2 }}r{{ else }}r_{{sub $i 1}}{{ end }}, r, R.try_into().unwrap());
+        {{- end }}
+        {{- end }}
+        let scalars = array![
+            1,
+            {{- range $i := intRange $numCommitments }}
+            {{- if eq $i 1 }}
+            r,
+            {{- else if gt $i 1 }}
+            r_{{$i}},
+            {{- end }}
+            {{- end }}
+        ];
+        let folded = curve.msm(commitments, scalars.span());
+        {{- end }}
+
+        // e(C, G)⋅e(PoK, G^{-1/σ}) = 1
+        curve
+            .pairing_check(
+                array![folded, commitment_pok].span(),
+                array![
+                    G2Point { x0: PEDERSEN_G_X_0, x1: PEDERSEN_G_X_1, y0: PEDERSEN_G_Y_0, y1: PEDERSEN_G_Y_1 },
+                    G2Point {
+                        x0: PEDERSEN_GROOTSIGMANEG_X_0,
+                        x1: PEDERSEN_GROOTSIGMANEG_X_1,
+                        y0: PEDERSEN_GROOTSIGMANEG_Y_0,
+                        y1: PEDERSEN_GROOTSIGMANEG_Y_1,
+                    },
+                ]
+                    .span(),
+            )
+    }
+
+    /// Returns keccak256 of the words in big endian, read as a big endian integer
+    /// as in the EVM.
+    fn keccak_be(words: Span<u256>) -> u256 {
+        let h = keccak_u256s_be_inputs(words);
+        u256 { low: u128_byte_reverse(h.high), high: u128_byte_reverse(h.low) }
+    }
+    {{- end }}
+}
+`
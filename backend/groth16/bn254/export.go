@@ -0,0 +1,101 @@
+package groth16
+
+import (
+	"encoding/hex"
+	"io"
+	"text/template"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark/backend"
+)
+
+func init() {
+	for target, src := range map[backend.Target]string{
+		backend.Vyper: vyperTemplate,
+		backend.Move:  moveTemplate,
+		backend.Cairo: cairoTemplate,
+	} {
+		src := src
+		backend.RegisterExporter(backend.GROTH16, ecc.BN254, target, func(w io.Writer, vk any) error {
+			return vk.(*VerifyingKey).exportTemplate(w, src)
+		})
+	}
+}
+
+// exportTemplate executes the template of a verifier contract with vk, whose
+// G2 points Beta, Gamma and Delta are negated, as in ExportSolidity.
+func (vk *VerifyingKey) exportTemplate(w io.Writer, src string) error {
+	helpers := template.FuncMap{
+		"add": func(a, b int) int {
+			return a + b
+		},
+		"sub": func(a, b int) int {
+			return a - b
+		},
+		"mul": func(a, b int) int {
+			return a * b
+		},
+		"intRange": func(max int) []int {
+			out := make([]int, max)
+			for i := 0; i < max; i++ {
+				out[i] = i
+			}
+			return out
+		},
+		"moveG1": moveG1,
+		"moveG2": moveG2,
+	}
+
+	tmpl, err := template.New("").Funcs(helpers).Parse(src)
+	if err != nil {
+		return err
+	}
+
+	// negate Beta, Gamma and Delta, to avoid negating proof elements in the verifier
+	var betaNeg curve.G2Affine
+	betaNeg.Neg(&vk.G2.Beta)
+	beta := vk.G2.Beta
+	vk.G2.Beta = betaNeg
+	vk.G2.Gamma, vk.G2.gammaNeg = vk.G2.gammaNeg, vk.G2.Gamma
+	vk.G2.Delta, vk.G2.deltaNeg = vk.G2.deltaNeg, vk.G2.Delta
+
+	// execute template
+	err = tmpl.Execute(w, vk)
+
+	// restore Beta, Gamma and Delta
+	vk.G2.Beta = beta
+	vk.G2.Gamma, vk.G2.gammaNeg = vk.G2.gammaNeg, vk.G2.Gamma
+	vk.G2.Delta, vk.G2.deltaNeg = vk.G2.deltaNeg, vk.G2.Delta
+
+	return err
+}
+
+// moveG1 returns the byte string literal of p in the FormatG1Uncompr format of
+// the bn254_algebra module of Aptos: x then y, in little endian.
+func moveG1(p curve.G1Affine) string {
+	b := make([]byte, 0, 2*fp.Bytes)
+	b = appendLittleEndian(b, &p.X)
+	b = appendLittleEndian(b, &p.Y)
+	return `x"` + hex.EncodeToString(b) + `"`
+}
+
+// moveG2 returns the byte string literal of p in the FormatG2Uncompr format of
+// the bn254_algebra module of Aptos: x.A0, x.A1, y.A0 then y.A1, in little endian.
+func moveG2(p curve.G2Affine) string {
+	b := make([]byte, 0, 4*fp.Bytes)
+	b = appendLittleEndian(b, &p.X.A0)
+	b = appendLittleEndian(b, &p.X.A1)
+	b = appendLittleEndian(b, &p.Y.A0)
+	b = appendLittleEndian(b, &p.Y.A1)
+	return `x"` + hex.EncodeToString(b) + `"`
+}
+
+func appendLittleEndian(res []byte, x *fp.Element) []byte {
+	b := x.Bytes()
+	for i := len(b) - 1; i >= 0; i-- {
+		res = append(res, b[i])
+	}
+	return res
+}
@@ -0,0 +1,287 @@
+//go:build cairocheck
+
+package groth16_test
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+const cairoManifest = `[package]
+name = "groth16_verifier"
+version = "0.1.0"
+edition = "2024_07"
+
+[dependencies]
+starknet = "2.8.4"
+
+[dev-dependencies]
+cairo_test = "2.8.4"
+
+[[target.starknet-contract]]
+`
+
+const cairoLib = `pub mod verifier;
+#[cfg(test)]
+mod test_curve;
+#[cfg(test)]
+mod tests;
+`
+
+// cairoTestCurve implements IBn254Curve with the answers to the calls expected
+// from the verifier, computed with gnark-crypto: Starknet has no BN254
+// precompiles. It panics on any other call.
+var cairoTestCurve = template.Must(template.New("").Parse(`use crate::verifier::{G1Point, G2Point, IBn254Curve};
+
+#[starknet::contract]
+pub mod TestCurve {
+    use super::{G1Point, G2Point, IBn254Curve};
+
+    #[storage]
+    struct Storage {}
+
+    #[abi(embed_v0)]
+    impl TestCurveImpl of IBn254Curve<ContractState> {
+        fn msm(self: @ContractState, points: Span<G1Point>, scalars: Span<u256>) -> G1Point {
+            let mut input: Array<felt252> = array![];
+            points.serialize(ref input);
+            scalars.serialize(ref input);
+            {{- range .MSMs }}
+            if input == array![{{ .Input }}] {
+                return G1Point { x: {{ .Output.X.String }}, y: {{ .Output.Y.String }} };
+            }
+            {{- end }}
+            panic!("unexpected msm")
+        }
+
+        fn pairing_check(self: @ContractState, a: Span<G1Point>, b: Span<G2Point>) -> bool {
+            let mut input: Array<felt252> = array![];
+            a.serialize(ref input);
+            b.serialize(ref input);
+            {{- range .Pairings }}
+            if input == array![{{ .Input }}] {
+                return {{ .Output }};
+            }
+            {{- end }}
+            panic!("unexpected pairing check")
+        }
+    }
+}
+`))
+
+var cairoTests = template.Must(template.New("").Parse(`use starknet::SyscallResultTrait;
+use starknet::syscalls::deploy_syscall;
+use crate::test_curve::TestCurve;
+use crate::verifier::{Groth16Verifier, IGroth16VerifierDispatcher, IGroth16VerifierDispatcherTrait};
+
+fn deploy() -> IGroth16VerifierDispatcher {
+    let (curve, _) = deploy_syscall(TestCurve::TEST_CLASS_HASH.try_into().unwrap(), 0, array![].span(), false)
+        .unwrap_syscall();
+    let (verifier, _) = deploy_syscall(
+        Groth16Verifier::TEST_CLASS_HASH.try_into().unwrap(), 0, array![curve.into()].span(), false,
+    )
+        .unwrap_syscall();
+    IGroth16VerifierDispatcher { contract_address: verifier }
+}
+
+#[test]
+fn test_verify_proof() {
+    assert(deploy().verify_proof(array![{{.Proof}}].span(), array![{{.PublicInputs}}].span()), 'proof rejected');
+}
+
+#[test]
+fn test_wrong_public_inputs() {
+    assert(!deploy().verify_proof(array![{{.Proof}}].span(), array![{{.WrongPublicInputs}}].span()), 'proof accepted');
+}
+`))
+
+// TestExportCairo runs Cairo tests verifying a proof with the Cairo verifiers,
+// with Scarb, which must be in the PATH.
+func TestExportCairo(t *testing.T) {
+	for name, p := range exportCheckProofs(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := require.New(t)
+			dir := t.TempDir()
+			src := filepath.Join(dir, "src")
+			assert.NoError(os.Mkdir(src, 0700))
+			assert.NoError(os.WriteFile(filepath.Join(dir, "Scarb.toml"), []byte(cairoManifest), 0600))
+			assert.NoError(os.WriteFile(filepath.Join(src, "lib.cairo"), []byte(cairoLib), 0600))
+
+			f, err := os.Create(filepath.Join(src, "verifier.cairo"))
+			assert.NoError(err)
+			assert.NoError(p.vk.Export(f, backend.Cairo))
+			assert.NoError(f.Close())
+
+			vk := p.vk.(*groth16_bn254.VerifyingKey)
+			proof := p.proof.(*groth16_bn254.Proof)
+			var calls cairoCurveCalls
+			assert.True(calls.verify(vk, proof, p.publicInputs), "the reference verifier rejects the proof")
+			assert.False(calls.verify(vk, proof, p.wrongPublicInputs), "the reference verifier accepts wrong public inputs")
+			f, err = os.Create(filepath.Join(src, "test_curve.cairo"))
+			assert.NoError(err)
+			assert.NoError(cairoTestCurve.Execute(f, calls))
+			assert.NoError(f.Close())
+
+			words := proof.MarshalSolidity()
+			proofWords := make([]string, len(words)/32)
+			for i := range proofWords {
+				proofWords[i] = new(big.Int).SetBytes(words[32*i : 32*(i+1)]).String()
+			}
+			f, err = os.Create(filepath.Join(src, "tests.cairo"))
+			assert.NoError(err)
+			assert.NoError(cairoTests.Execute(f, map[string]string{
+				"Proof":             strings.Join(proofWords, ", "),
+				"PublicInputs":      cairoU256s(p.publicInputs),
+				"WrongPublicInputs": cairoU256s(p.wrongPublicInputs),
+			}))
+			assert.NoError(f.Close())
+
+			cmd := exec.Command("scarb", "test")
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			assert.NoError(err, string(out))
+		})
+	}
+}
+
+// cairoCall is a call to IBn254Curve, its input being serialized as the
+// felt252 literals of the Serde encoding of its arguments.
+type cairoCall[T any] struct {
+	Input  string
+	Output T
+}
+
+type cairoCurveCalls struct {
+	MSMs     []cairoCall[curve.G1Affine]
+	Pairings []cairoCall[bool]
+}
+
+// verify verifies the proof as the Cairo verifier does, recording the calls to
+// IBn254Curve and their answers.
+func (calls *cairoCurveCalls) verify(vk *groth16_bn254.VerifyingKey, proof *groth16_bn254.Proof, publicInputs fr.Vector) bool {
+	// public inputs of the commitments
+	witness := append(fr.Vector{}, publicInputs...)
+	for i, committed := range vk.PublicAndCommitmentCommitted {
+		h := sha3.NewLegacyKeccak256()
+		h.Write(proof.Commitments[i].Marshal())
+		for _, j := range committed {
+			h.Write(witness[j-1].Marshal())
+		}
+		var c fr.Element
+		c.SetBytes(h.Sum(nil))
+		witness = append(witness, c)
+	}
+	publicCommitments := witness[len(publicInputs):]
+
+	if len(proof.Commitments) > 0 {
+		folded := proof.Commitments[0]
+		if len(proof.Commitments) > 1 {
+			h := sha256.New()
+			h.Write([]byte("r"))
+			for i := range publicCommitments {
+				h.Write(publicCommitments[i].Marshal())
+			}
+			scalars := make(fr.Vector, len(proof.Commitments))
+			scalars[0].SetOne()
+			scalars[1].SetBytes(h.Sum(nil))
+			for i := 2; i < len(scalars); i++ {
+				scalars[i].Mul(&scalars[i-1], &scalars[1])
+			}
+			folded = calls.msm(proof.Commitments, scalars)
+		}
+		if !calls.pairingCheck(
+			[]curve.G1Affine{folded, proof.CommitmentPok},
+			[]curve.G2Affine{vk.CommitmentKey.G, vk.CommitmentKey.GRootSigmaNeg},
+		) {
+			return false
+		}
+	}
+
+	points := append([]curve.G1Affine{}, vk.G1.K...)
+	scalars := append(fr.Vector{fr.One()}, witness...)
+	for i := range proof.Commitments {
+		points = append(points, proof.Commitments[i])
+		scalars = append(scalars, fr.One())
+	}
+	l := calls.msm(points, scalars)
+
+	var betaNeg, gammaNeg, deltaNeg curve.G2Affine
+	betaNeg.Neg(&vk.G2.Beta)
+	gammaNeg.Neg(&vk.G2.Gamma)
+	deltaNeg.Neg(&vk.G2.Delta)
+	return calls.pairingCheck(
+		[]curve.G1Affine{proof.Ar, proof.Krs, vk.G1.Alpha, l},
+		[]curve.G2Affine{proof.Bs, deltaNeg, betaNeg, gammaNeg},
+	)
+}
+
+func (calls *cairoCurveCalls) msm(points []curve.G1Affine, scalars fr.Vector) curve.G1Affine {
+	var res curve.G1Affine
+	if _, err := res.MultiExp(points, scalars, ecc.MultiExpConfig{}); err != nil {
+		panic(err)
+	}
+	input := cairoSpan(len(points))
+	for i := range points {
+		input = append(input, cairoU256(points[i].X.BigInt(new(big.Int))), cairoU256(points[i].Y.BigInt(new(big.Int))))
+	}
+	input = append(input, cairoSpan(len(scalars))...)
+	for i := range scalars {
+		input = append(input, cairoU256(scalars[i].BigInt(new(big.Int))))
+	}
+	calls.MSMs = append(calls.MSMs, cairoCall[curve.G1Affine]{strings.Join(input, ", "), res})
+	return res
+}
+
+func (calls *cairoCurveCalls) pairingCheck(a []curve.G1Affine, b []curve.G2Affine) bool {
+	res, err := curve.PairingCheck(a, b)
+	if err != nil {
+		panic(err)
+	}
+	input := cairoSpan(len(a))
+	for i := range a {
+		input = append(input, cairoU256(a[i].X.BigInt(new(big.Int))), cairoU256(a[i].Y.BigInt(new(big.Int))))
+	}
+	input = append(input, cairoSpan(len(b))...)
+	for i := range b {
+		input = append(input,
+			cairoU256(b[i].X.A0.BigInt(new(big.Int))), cairoU256(b[i].X.A1.BigInt(new(big.Int))),
+			cairoU256(b[i].Y.A0.BigInt(new(big.Int))), cairoU256(b[i].Y.A1.BigInt(new(big.Int))))
+	}
+	calls.Pairings = append(calls.Pairings, cairoCall[bool]{strings.Join(input, ", "), res})
+	return res
+}
+
+// cairoSpan returns the Serde encoding of the length of a span.
+func cairoSpan(n int) []string {
+	return []string{big.NewInt(int64(n)).String()}
+}
+
+// cairoU256 returns the Serde encoding of x as a u256: its low then its high
+// 128 bits.
+func cairoU256(x *big.Int) string {
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	return new(big.Int).And(x, mask).String() + ", " + new(big.Int).Rsh(x, 128).String()
+}
+
+// cairoU256s returns the elements of v as u256 literals.
+func cairoU256s(v fr.Vector) string {
+	elems := make([]string, len(v))
+	for i := range v {
+		elems[i] = v[i].String()
+	}
+	return strings.Join(elems, ", ")
+}
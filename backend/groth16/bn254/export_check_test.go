@@ -0,0 +1,66 @@
+//go:build vypercheck || movecheck || cairocheck
+
+package groth16_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+// exportCheckProof is a proof to be checked by an exported verifier, along
+// with public inputs it must accept and public inputs it must reject.
+type exportCheckProof struct {
+	vk                groth16.VerifyingKey
+	proof             groth16.Proof
+	publicInputs      fr.Vector
+	wrongPublicInputs fr.Vector
+}
+
+// exportCheckProofs returns proofs of circuits without and with commitments,
+// generated for the Solidity verifier whose encoding the exports share.
+func exportCheckProofs(t *testing.T) map[string]exportCheckProof {
+	res := make(map[string]exportCheckProof)
+	for name, c := range map[string]struct {
+		circuit, assignment frontend.Circuit
+	}{
+		"noCommitment": {&exportCircuit{}, &exportCircuit{X: 3, Y: 9, Z: 12}},
+		"commitments":  {&multiCommitmentCircuit{}, &multiCommitmentCircuit{X: 3, Y: 4, Z: 7}},
+	} {
+		assert := require.New(t)
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, c.circuit)
+		assert.NoError(err)
+		pk, vk, err := groth16.Setup(ccs)
+		assert.NoError(err)
+		w, err := frontend.NewWitness(c.assignment, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		proof, err := groth16.Prove(ccs, pk, w, solidity.WithProverTargetSolidityVerifier(backend.GROTH16))
+		assert.NoError(err)
+		public, err := w.Public()
+		assert.NoError(err)
+		assert.NoError(groth16.Verify(proof, vk, public, solidity.WithVerifierTargetSolidityVerifier(backend.GROTH16)))
+
+		publicInputs := public.Vector().(fr.Vector)
+		wrongPublicInputs := make(fr.Vector, len(publicInputs))
+		copy(wrongPublicInputs, publicInputs)
+		wrongPublicInputs[0].SetOne().Add(&wrongPublicInputs[0], &publicInputs[0])
+		res[name] = exportCheckProof{vk, proof, publicInputs, wrongPublicInputs}
+	}
+	return res
+}
+
+// marshalPublicInputs returns the public inputs in big endian, 32 bytes each.
+func marshalPublicInputs(v fr.Vector) []byte {
+	res := make([]byte, 0, len(v)*fr.Bytes)
+	for i := range v {
+		res = append(res, v[i].Marshal()...)
+	}
+	return res
+}
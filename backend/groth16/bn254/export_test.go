@@ -0,0 +1,116 @@
+package groth16_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "regenerate the proof, the verifying key and the golden verifiers in testdata")
+
+type exportCircuit struct {
+	X    frontend.Variable
+	Y, Z frontend.Variable `gnark:",public"`
+}
+
+func (c *exportCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	api.AssertIsEqual(api.Add(c.X, c.Y), c.Z)
+	return nil
+}
+
+// TestExportGolden checks the verifiers exported for a known proof/vk pair
+// against the golden files in testdata. The verifiers are compiled and executed
+// by the tests with the vypercheck, movecheck and cairocheck build tags.
+func TestExportGolden(t *testing.T) {
+	assert := require.New(t)
+
+	if *update {
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &exportCircuit{})
+		assert.NoError(err)
+		pk, vk, err := groth16.Setup(ccs)
+		assert.NoError(err)
+		w, err := frontend.NewWitness(&exportCircuit{X: 3, Y: 9, Z: 12}, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		proof, err := groth16.Prove(ccs, pk, w)
+		assert.NoError(err)
+		public, err := w.Public()
+		assert.NoError(err)
+
+		writeTestdata(t, "vk.bin", vk)
+		writeTestdata(t, "proof.bin", proof)
+		writeTestdata(t, "public.wtns", public)
+	}
+
+	vk := groth16.NewVerifyingKey(ecc.BN254)
+	readTestdata(t, "vk.bin", vk)
+	proof := groth16.NewProof(ecc.BN254)
+	readTestdata(t, "proof.bin", proof)
+	public, err := witness.New(ecc.BN254.ScalarField())
+	assert.NoError(err)
+	readTestdata(t, "public.wtns", public)
+	assert.NoError(groth16.Verify(proof, vk, public))
+
+	for target, name := range map[backend.Target]string{
+		backend.Vyper: "verifier.vy",
+		backend.Move:  "verifier.move",
+		backend.Cairo: "verifier.cairo",
+	} {
+		var buf bytes.Buffer
+		assert.NoError(vk.Export(&buf, target), target)
+		path := filepath.Join("testdata", name)
+		if *update {
+			assert.NoError(os.WriteFile(path, buf.Bytes(), 0600))
+			continue
+		}
+		golden, err := os.ReadFile(path)
+		assert.NoError(err)
+		assert.Equal(string(golden), buf.String(), "%s verifier differs from %s, run the tests with -update", target, path)
+	}
+
+	assert.ErrorIs(vk.Export(io.Discard, backend.Target("noir")), backend.ErrUnsupportedTarget)
+}
+
+func TestExportCommitments(t *testing.T) {
+	assert := require.New(t)
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &multiCommitmentCircuit{})
+	assert.NoError(err)
+	_, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	for target, check := range map[backend.Target]string{
+		backend.Vyper: "self._verify_commitment_pok(commitments, public_commitments, commitment_pok)",
+		backend.Move:  "if (!verify_commitment_pok(&commitments, &public_commitments, commitment_pok)) {",
+		backend.Cairo: "if !verify_commitment_pok(curve, commitments.span(), public_commitments.span(), commitment_pok) {",
+	} {
+		var buf bytes.Buffer
+		assert.NoError(vk.Export(&buf, target), target)
+		assert.Contains(buf.String(), check, target)
+	}
+}
+
+func writeTestdata(t *testing.T, name string, v io.WriterTo) {
+	f, err := os.Create(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = v.WriteTo(f)
+	require.NoError(t, err)
+}
+
+func readTestdata(t *testing.T, name string, v io.ReaderFrom) {
+	f, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = v.ReadFrom(f)
+	require.NoError(t, err)
+}
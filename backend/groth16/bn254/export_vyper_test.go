@@ -0,0 +1,43 @@
+//go:build vypercheck
+
+package groth16_test
+
+import (
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportVyper compiles the Vyper verifiers with vyper and checks them on an
+// EVM with evmchecker, see test/evmchecker. Both must be in the PATH.
+func TestExportVyper(t *testing.T) {
+	for name, p := range exportCheckProofs(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := require.New(t)
+			dir := t.TempDir()
+			path := filepath.Join(dir, "verifier.vy")
+			f, err := os.Create(path)
+			assert.NoError(err)
+			assert.NoError(p.vk.Export(f, backend.Vyper))
+			assert.NoError(f.Close())
+
+			proof := hex.EncodeToString(p.proof.(*groth16_bn254.Proof).MarshalSolidity())
+			evmchecker := func(publicInputs fr.Vector) ([]byte, error) {
+				return exec.Command("evmchecker", "--vyper", path,
+					"--proof", proof,
+					"--public-inputs", hex.EncodeToString(marshalPublicInputs(publicInputs))).CombinedOutput()
+			}
+			out, err := evmchecker(p.publicInputs)
+			assert.NoError(err, string(out))
+			out, err = evmchecker(p.wrongPublicInputs)
+			assert.Error(err, string(out))
+		})
+	}
+}
@@ -141,6 +141,14 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 	if opt.Accelerator != "icicle" {
 		return groth16_bn254.Prove(r1cs, &pk.ProvingKey, fullWitness, opts...)
 	}
+	// the weights are rough estimates of the relative costs of the phases
+	progress := opt.NewProgressReporter(
+		backend.PhaseWeight{Phase: backend.PhaseSolve, Weight: 2},
+		backend.PhaseWeight{Phase: backend.PhaseCommitments, Weight: 0.5},
+		backend.PhaseWeight{Phase: backend.PhaseFFT, Weight: 1},
+		backend.PhaseWeight{Phase: backend.PhaseMSM, Weight: 2},
+	)
+
 	log := logger.Logger().With().Str("curve", r1cs.CurveID().String()).Str("acceleration", "icicle").Int("nbConstraints", r1cs.GetNbConstraints()).Str("backend", "groth16").Logger()
 	if pk.deviceInfo == nil {
 		log.Debug().Msg("precomputing proving key in GPU")
@@ -154,6 +162,7 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 	proof := &groth16_bn254.Proof{Commitments: make([]curve.G1Affine, len(commitmentInfo))}
 
 	solverOpts := opt.SolverOpts[:len(opt.SolverOpts):len(opt.SolverOpts)]
+	solverOpts = append(solverOpts, solver.WithContext(opt.Ctx))
 
 	privateCommittedValues := make([][]fr.Element, len(commitmentInfo))
 	for i := range commitmentInfo {
@@ -193,10 +202,15 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 			solver.OverrideHint(r1cs.GkrInfo.ProveHintID, cs.GkrProveHint(r1cs.GkrInfo.HashName, &gkrData)))
 	}
 
+	progress.Start(backend.PhaseSolve, 1)
 	_solution, err := r1cs.Solve(fullWitness, solverOpts...)
 	if err != nil {
+		if errCtx := opt.CheckContext(backend.PhaseSolve); errCtx != nil {
+			return nil, errCtx
+		}
 		return nil, err
 	}
+	progress.Step(backend.PhaseSolve)
 
 	solution := _solution.(*cs.R1CSSolution)
 	wireValues := []fr.Element(solution.W)
@@ -208,15 +222,23 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 		copy(commitmentsSerialized[fr.Bytes*i:], wireValues[commitmentInfo[i].CommitmentIndex].Marshal())
 	}
 
+	progress.Start(backend.PhaseCommitments, 1)
 	if proof.CommitmentPok, err = pedersen.BatchProve(pk.CommitmentKeys, privateCommittedValues, commitmentsSerialized); err != nil {
 		return nil, err
 	}
+	if err := opt.CheckContext(backend.PhaseCommitments); err != nil {
+		return nil, err
+	}
+	progress.Step(backend.PhaseCommitments)
 
 	// H (witness reduction / FFT part)
 	var h unsafe.Pointer
 	chHDone := make(chan struct{}, 1)
 	go func() {
+		// the FFTs run on the device in a single batch
+		progress.Start(backend.PhaseFFT, 1)
 		h = computeH(solution.A, solution.B, solution.C, pk)
+		progress.Step(backend.PhaseFFT)
 		solution.A = nil
 		solution.B = nil
 		solution.C = nil
@@ -395,18 +417,18 @@ func Prove(r1cs *cs.R1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...b
 	// wait for FFT to end
 	<-chHDone
 
-	// schedule our proof part computations
-	if err := computeAR1(); err != nil {
-		return nil, err
-	}
-	if err := computeBS1(); err != nil {
-		return nil, err
-	}
-	if err := computeKRS(); err != nil {
-		return nil, err
-	}
-	if err := computeBS2(); err != nil {
-		return nil, err
+	// schedule our proof part computations, checking the context between
+	// the MSMs
+	computes := []func() error{computeAR1, computeBS1, computeKRS, computeBS2}
+	progress.Start(backend.PhaseMSM, len(computes))
+	for _, compute := range computes {
+		if err := opt.CheckContext(backend.PhaseMSM); err != nil {
+			return nil, err
+		}
+		if err := compute(); err != nil {
+			return nil, err
+		}
+		progress.Step(backend.PhaseMSM)
 	}
 
 	log.Debug().Dur("took", time.Since(start)).Msg("prover done")
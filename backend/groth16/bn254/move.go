@@ -0,0 +1,273 @@
+package groth16
+
+// moveTemplate is the Move port of solidityTemplate, for the Aptos framework,
+// without the compressed proofs. The proof is encoded with MarshalSolidity and
+// the public inputs as for verifyProof in the Solidity contract, so that the
+// proofs with commitments must be generated with
+// solidity.WithProverTargetSolidityVerifier.
+//
+// The generated module is compiled and executed by the Move unit tests of the
+// Aptos CLI in the tests with the movecheck build tag.
+const moveTemplate = `
+{{- $numCommitments := len .PublicAndCommitmentCommitted -}}
+{{- $numPublic := sub (sub (len .G1.K) $numCommitments) 1 -}}
+{{- $proofSize := 256 -}}
+{{- if gt $numCommitments 0 }}{{ $proofSize = add 256 (mul (add $numCommitments 1) 64) }}{{ end -}}
+// SPDX-License-Identifier: MIT
+// Code generated by gnark DO NOT EDIT
+
+/// Groth16 verifier on BN254{{ if gt $numCommitments 0 }} with {{ $numCommitments }} BSB22 commitment(s){{ end }}, using the crypto_algebra module of Aptos.
+/// The module is published at the named address verifier.
+module verifier::groth16_verifier {
+    use std::bcs;
+    {{- if gt $numCommitments 1 }}
+    use std::hash;
+    {{- end }}
+    use std::option;
+    use std::vector;
+    {{- if gt $numCommitments 0 }}
+    use aptos_std::aptos_hash;
+    use aptos_std::from_bcs;
+    {{- end }}
+    use aptos_std::bn254_algebra::{Fr, FormatFrLsb, G1, FormatG1Uncompr, G2, FormatG2Uncompr, Gt};
+    use aptos_std::crypto_algebra::{Self, Element};
+
+    /// Some of the provided public input values are larger than the field modulus.
+    const EPUBLIC_INPUT_NOT_IN_FIELD: u64 = 1;
+    /// The number of public inputs is not the one of the verifying key.
+    const EWRONG_NUMBER_OF_PUBLIC_INPUTS: u64 = 2;
+    /// The proof is not on {{$proofSize}} bytes, or its points are not in their groups.
+    const EINVALID_PROOF_ENCODING: u64 = 3;
+
+    const NB_PUBLIC_INPUTS: u64 = {{$numPublic}};
+    {{- if gt $numCommitments 0 }}
+    const NB_COMMITMENTS: u64 = {{$numCommitments}};
+
+    // Scalar field Fr order R
+    const R: u256 = 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001;
+    {{- end }}
+
+    // The points are serialized in the formats FormatG1Uncompr and FormatG2Uncompr.
+
+    // Groth16 alpha point in G1
+    const ALPHA: vector<u8> = {{moveG1 .G1.Alpha}};
+
+    // Groth16 beta, gamma and delta points in G2, negated
+    const BETA_NEG: vector<u8> = {{moveG2 .G2.Beta}};
+    const GAMMA_NEG: vector<u8> = {{moveG2 .G2.Gamma}};
+    const DELTA_NEG: vector<u8> = {{moveG2 .G2.Delta}};
+
+    // Constant and public input points
+    const CONSTANT: vector<u8> = {{moveG1 (index .G1.K 0)}};
+    {{- range $i, $ki := .G1.K }}
+    {{- if gt $i 0 }}
+    const PUB_{{sub $i 1}}: vector<u8> = {{moveG1 $ki}};
+    {{- end }}
+    {{- end }}
+    {{- if gt $numCommitments 0 }}
+
+    // Pedersen G and GRootSigmaNeg points in G2
+    const PEDERSEN_G: vector<u8> = {{moveG2 .CommitmentKey.G}};
+    const PEDERSEN_GROOTSIGMANEG: vector<u8> = {{moveG2 .CommitmentKey.GRootSigmaNeg}};
+    {{- end }}
+
+    #[view]
+    /// Verify an uncompressed Groth16 proof.
+    /// Aborts if the proof or the public inputs are malformed.
+    {{- if gt $numCommitments 0 }}
+    /// @param proof the points (A, B, C) in EIP-197 format, followed by the commitments
+    /// and their proof of knowledge in EIP-196 format, as encoded by MarshalSolidity.
+    {{- else }}
+    /// @param proof the points (A, B, C) in EIP-197 format, as for verifyProof in the
+    /// Solidity contract.
+    {{- end }}
+    /// @param public_inputs the public input field elements in the scalar field Fr.
+    /// Elements must be reduced.
+    /// @return true if the proof is valid, false otherwise.
+    public fun verify_proof(proof: vector<u8>, public_inputs: vector<u256>): bool {
+        assert!(vector::length(&proof) == {{$proofSize}}, EINVALID_PROOF_ENCODING);
+        assert!(vector::length(&public_inputs) == NB_PUBLIC_INPUTS, EWRONG_NUMBER_OF_PUBLIC_INPUTS);
+
+        let a = g1_from_evm(&proof, 0);
+        let b = g2_from_evm(&proof, 64);
+        let c = g1_from_evm(&proof, 192);
+        {{- if gt $numCommitments 0 }}
+
+        let commitments = vector[
+            {{- range $i := intRange $numCommitments }}
+            g1_from_evm(&proof, {{add 256 (mul $i 64)}}),
+            {{- end }}
+        ];
+        let commitment_pok = g1_from_evm(&proof, {{sub $proofSize 64}});
+        let public_commitments = hash_commitments(&proof, &public_inputs);
+        if (!verify_commitment_pok(&commitments, &public_commitments, commitment_pok)) {
+            return false
+        };
+        {{- end }}
+
+        // L_pub = CONSTANT + ∑ᵢ inputᵢ⋅PUBᵢ
+        {{- if gt $numCommitments 0 }}
+        // where the public inputs of the commitments are appended to the inputs,
+        // and the commitments are added.
+        {{- end }}
+        let points = vector[
+            g1(CONSTANT),
+            {{- range $i := intRange (sub (len .G1.K) 1) }}
+            g1(PUB_{{$i}}),
+            {{- end }}
+        ];
+        let scalars = vector[crypto_algebra::one<Fr>()];
+        let i = 0;
+        while (i < NB_PUBLIC_INPUTS) {
+            vector::push_back(&mut scalars, fr_from_u256(*vector::borrow(&public_inputs, i)));
+            i = i + 1;
+        };
+        {{- if gt $numCommitments 0 }}
+        let i = 0;
+        while (i < NB_COMMITMENTS) {
+            vector::push_back(&mut scalars, fr_from_u256(*vector::borrow(&public_commitments, i)));
+            i = i + 1;
+        };
+        vector::append(&mut points, commitments);
+        let i = 0;
+        while (i < NB_COMMITMENTS) {
+            vector::push_back(&mut scalars, crypto_algebra::one<Fr>());
+            i = i + 1;
+        };
+        {{- end }}
+        let l = crypto_algebra::multi_scalar_mul(&points, &scalars);
+
+        // e(A, B)⋅e(C, -δ)⋅e(α, -β)⋅e(L_pub, -γ) = 1
+        let res = crypto_algebra::multi_pairing<G1, G2, Gt>(
+            &vector[a, c, g1(ALPHA), l],
+            &vector[b, g2(DELTA_NEG), g2(BETA_NEG), g2(GAMMA_NEG)],
+        );
+        crypto_algebra::eq(&res, &crypto_algebra::zero<Gt>())
+    }
+
+    {{- if gt $numCommitments 0 }}
+
+    /// Returns the public inputs of the commitments encoded in the proof after (A, B, C).
+    /// Each commitment is hashed with the public inputs it commits to, as
+    /// keccak256(commitment || committed inputs) mod R.
+    fun hash_commitments(proof: &vector<u8>, public_inputs: &vector<u256>): vector<u256> {
+        let res = vector::empty<u256>();
+        {{- range $i, $committed := .PublicAndCommitmentCommitted }}
+        let data = vector::slice(proof, {{add 256 (mul $i 64)}}, {{add 320 (mul $i 64)}});
+        {{- range $j := $committed }}
+        {{- if lt (sub $j 1) $numPublic }}
+        append_u256(&mut data, *vector::borrow(public_inputs, {{sub $j 1}}));
+        {{- else }}
+        append_u256(&mut data, *vector::borrow(&res, {{sub (sub $j 1) $numPublic}}));
+        {{- end }}
+        {{- end }}
+        vector::push_back(&mut res, u256_from_bytes(aptos_hash::keccak256(data)) % R);
+        {{- end }}
+        res
+    }
+
+    /// Verifies the proof of knowledge of the commitments.
+    {{- if gt $numCommitments 1 }}
+    /// The commitments are folded with the powers of a challenge derived from their
+    /// public inputs, as in gnark-crypto's pedersen.FoldCommitments.
+    {{- end }}
+    /// @return true if the proof of knowledge is valid, false otherwise.
+    fun verify_commitment_pok(
+        commitments: &vector<Element<G1>>,
+        {{- if gt $numCommitments 1 }}
+        public_commitments: &vector<u256>,
+        {{- else }}
+        _public_commitments: &vector<u256>,
+        {{- end }}
+        commitment_pok: Element<G1>,
+    ): bool {
+        {{- if eq $numCommitments 1 }}
+        let folded = *vector::borrow(commitments, 0);
+        {{- else }}
+        // ∑ᵢ rⁱ⋅Cᵢ where r = sha256("r" || public_commitments) mod R
+        let data = b"r";
+        let i = 0;
+        while (i < NB_COMMITMENTS) {
+            append_u256(&mut data, *vector::borrow(public_commitments, i));
+            i = i + 1;
+        };
+        let r = fr_from_u256(u256_from_bytes(hash::sha2_256(data)) % R);
+        let scalars = vector[crypto_algebra::one<Fr>()];
+        let i = 1;
+        while (i < NB_COMMITMENTS) {
+            let previous = *vector::borrow(&scalars, i - 1);
+            vector::push_back(&mut scalars, crypto_algebra::mul(&previous, &r));
+            i = i + 1;
+        };
+        let folded = crypto_algebra::multi_scalar_mul(commitments, &scalars);
+        {{- end }}
+
+        // e(C, G)⋅e(PoK, G^{-1/σ}) = 1
+        let res = crypto_algebra::multi_pairing<G1, G2, Gt>(
+            &vector[folded, commitment_pok],
+            &vector[g2(PEDERSEN_G), g2(PEDERSEN_GROOTSIGMANEG)],
+        );
+        crypto_algebra::eq(&res, &crypto_algebra::zero<Gt>())
+    }
+
+    /// Appends x to dst in big endian.
+    fun append_u256(dst: &mut vector<u8>, x: u256) {
+        let b = bcs::to_bytes(&x);
+        vector::reverse(&mut b);
+        vector::append(dst, b);
+    }
+
+    /// Reads the 32 bytes as a big endian integer.
+    fun u256_from_bytes(bytes: vector<u8>): u256 {
+        vector::reverse(&mut bytes);
+        from_bcs::to_u256(bytes)
+    }
+    {{- end }}
+
+    fun g1(bytes: vector<u8>): Element<G1> {
+        option::destroy_some(crypto_algebra::deserialize<G1, FormatG1Uncompr>(&bytes))
+    }
+
+    fun g2(bytes: vector<u8>): Element<G2> {
+        option::destroy_some(crypto_algebra::deserialize<G2, FormatG2Uncompr>(&bytes))
+    }
+
+    /// Reads the point of G1 encoded at offset as in EIP-196, i.e. x then y in big endian.
+    fun g1_from_evm(bytes: &vector<u8>, offset: u64): Element<G1> {
+        let b = vector::empty<u8>();
+        append_reversed(&mut b, bytes, offset);
+        append_reversed(&mut b, bytes, offset + 32);
+        let p = crypto_algebra::deserialize<G1, FormatG1Uncompr>(&b);
+        assert!(option::is_some(&p), EINVALID_PROOF_ENCODING);
+        option::destroy_some(p)
+    }
+
+    /// Reads the point of G2 encoded at offset as in EIP-197, i.e. x₁, x₀, y₁ then y₀
+    /// in big endian.
+    fun g2_from_evm(bytes: &vector<u8>, offset: u64): Element<G2> {
+        let b = vector::empty<u8>();
+        append_reversed(&mut b, bytes, offset + 32);
+        append_reversed(&mut b, bytes, offset);
+        append_reversed(&mut b, bytes, offset + 96);
+        append_reversed(&mut b, bytes, offset + 64);
+        let p = crypto_algebra::deserialize<G2, FormatG2Uncompr>(&b);
+        assert!(option::is_some(&p), EINVALID_PROOF_ENCODING);
+        option::destroy_some(p)
+    }
+
+    /// Appends the 32 bytes of src at offset to dst, in reverse order.
+    fun append_reversed(dst: &mut vector<u8>, src: &vector<u8>, offset: u64) {
+        let i = 32;
+        while (i > 0) {
+            i = i - 1;
+            vector::push_back(dst, *vector::borrow(src, offset + i));
+        };
+    }
+
+    fun fr_from_u256(x: u256): Element<Fr> {
+        let e = crypto_algebra::deserialize<Fr, FormatFrLsb>(&bcs::to_bytes(&x));
+        assert!(option::is_some(&e), EPUBLIC_INPUT_NOT_IN_FIELD);
+        option::destroy_some(e)
+    }
+}
+`
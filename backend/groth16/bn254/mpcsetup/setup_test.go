@@ -20,12 +20,14 @@ import (
 	curve "github.com/consensys/gnark-crypto/ecc/bn254"
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	cs "github.com/consensys/gnark/constraint/bn254"
+	"math/bits"
 	"testing"
 
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/std/rangecheck"
 	"github.com/stretchr/testify/require"
 
 	native_mimc "github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
@@ -98,6 +100,62 @@ func TestSetupCircuit(t *testing.T) {
 	assert.NoError(err)
 }
 
+func TestSetupCircuitWithCommitment(t *testing.T) {
+	const nContributionsPhase2 = 3
+
+	assert := require.New(t)
+
+	// Compile the circuit, the range checks use a commitment
+	var myCircuit rangeCheckCircuit
+	ccs, err := frontend.Compile(curve.ID.ScalarField(), r1cs.NewBuilder, &myCircuit)
+	assert.NoError(err)
+
+	// the size of phase 1 must match the domain of the circuit
+	power := bits.Len(uint(ccs.GetNbConstraints() - 1))
+	srs1 := InitPhase1(power)
+	srs1.Contribute()
+
+	var evals Phase2Evaluations
+	r1cs := ccs.(*cs.R1CS)
+
+	// Prepare for phase-2
+	srs2, evals := InitPhase2(r1cs, &srs1)
+	assert.Len(evals.G1.CKK, 1)
+	assert.NotEmpty(evals.G1.CKK[0])
+
+	// Make and verify contributions for phase2
+	contributions := []*Phase2{}
+	for i := 1; i < nContributionsPhase2; i++ {
+		prev := srs2.clone()
+		contributions = append(contributions, &prev)
+
+		srs2.Contribute()
+		assert.NoError(VerifyPhase2(&prev, &srs2))
+	}
+
+	// the commitment keys must be updated with the same σ
+	tampered := srs2.clone()
+	tampered.Parameters.G1.SigmaCKK[0][0].Add(&tampered.Parameters.G1.SigmaCKK[0][0], &tampered.Parameters.G1.Delta)
+	tampered.Hash = tampered.hash()
+	assert.Error(VerifyPhase2(contributions[len(contributions)-1], &tampered))
+
+	// Extract the proving and verifying keys
+	pk, vk := ExtractKeys(&srs1, &srs2, &evals, ccs.GetNbConstraints())
+
+	witness, err := frontend.NewWitness(&rangeCheckCircuit{X: 42, Y: 42 * 42}, curve.ID.ScalarField())
+	assert.NoError(err)
+
+	pubWitness, err := witness.Public()
+	assert.NoError(err)
+
+	// groth16: ensure proof is verified
+	proof, err := groth16.Prove(ccs, &pk, witness)
+	assert.NoError(err)
+
+	err = groth16.Verify(proof, &vk, pubWitness)
+	assert.NoError(err)
+}
+
 func BenchmarkPhase1(b *testing.B) {
 	const power = 14
 
@@ -168,6 +226,21 @@ func (circuit *Circuit) Define(api frontend.API) error {
 	return nil
 }
 
+// rangeCheckCircuit defines a square root knowledge proof, with a range check
+// on the root
+type rangeCheckCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+// Y = X², X < 2¹⁶
+func (circuit *rangeCheckCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	rangecheck.New(api).Check(circuit.X, 16)
+	return nil
+}
+
 func (phase1 *Phase1) clone() Phase1 {
 	r := Phase1{}
 	r.Parameters.G1.Tau = append(r.Parameters.G1.Tau, phase1.Parameters.G1.Tau...)
@@ -188,8 +261,13 @@ func (phase2 *Phase2) clone() Phase2 {
 	r.Parameters.G1.Delta = phase2.Parameters.G1.Delta
 	r.Parameters.G1.L = append(r.Parameters.G1.L, phase2.Parameters.G1.L...)
 	r.Parameters.G1.Z = append(r.Parameters.G1.Z, phase2.Parameters.G1.Z...)
+	for i := range phase2.Parameters.G1.SigmaCKK {
+		r.Parameters.G1.SigmaCKK = append(r.Parameters.G1.SigmaCKK, append([]curve.G1Affine{}, phase2.Parameters.G1.SigmaCKK[i]...))
+	}
 	r.Parameters.G2.Delta = phase2.Parameters.G2.Delta
+	r.Parameters.G2.Sigma = phase2.Parameters.G2.Sigma
 	r.PublicKey = phase2.PublicKey
+	r.SigmaPublicKey = phase2.SigmaPublicKey
 	r.Hash = append(r.Hash, phase2.Hash...)
 
 	return r
@@ -1,19 +1,28 @@
 package groth16
 
+import (
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark/backend/solidity"
+)
+
 // solidityTemplate
 // this is an experimental feature and gnark solidity generator as not been thoroughly tested
 const solidityTemplate = `
-{{- $numPublic := sub (len .G1.K) 1 }}
+{{- $numCommitments := len .PublicAndCommitmentCommitted }}
+{{- $numPublic := sub (sub (len .G1.K) $numCommitments) 1 }}
 // SPDX-License-Identifier: MIT
 
 pragma solidity ^0.8.0;
 
 /// @title Groth16 verifier template.
 /// @author Remco Bloemen
-/// @notice Supports verifying Groth16 proofs. Proofs can be in uncompressed
-/// (256 bytes) and compressed (128 bytes) format. A view function is provided
+/// @notice Supports verifying Groth16 proofs
+{{- if gt $numCommitments 0 }} with {{ $numCommitments }} BSB22 commitment(s){{ end }}.
+{{- if .CompressedProofs }} Proofs can be in uncompressed
+/// ({{ add 256 (mul $numCommitments 64) }} bytes) and compressed ({{ add 128 (mul $numCommitments 32) }} bytes) format. A view function is provided
 /// to compress proofs.
 /// @notice See <https://2π.com/23/bn254-compression> for further explanation.
+{{- end }}
 contract Verifier {
     
     /// Some of the provided public input values are larger than the field modulus.
@@ -26,6 +35,13 @@ contract Verifier {
     /// curves, that pairing equation fails, or that the proof is not for the
     /// provided public input.
     error ProofInvalid();
+    {{- if gt $numCommitments 0 }}
+
+    /// The proof of knowledge of the commitments is invalid.
+    /// @dev This can mean that the commitments or their proof of knowledge are
+    /// not on the curve, or that the pairing equation fails.
+    error CommitmentInvalid();
+    {{- end }}
 
     // Addresses of precompiles
     uint256 constant PRECOMPILE_MODEXP = 0x05;
@@ -40,6 +56,7 @@ contract Verifier {
     //     R = 36⋅t⁴ + 36⋅t³ + 18⋅t² + 6⋅t + 1
     uint256 constant P = 0x30644e72e131a029b85045b68181585d97816a916871ca8d3c208c16d87cfd47;
     uint256 constant R = 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001;
+    {{- if .CompressedProofs }}
 
     // Extension field Fp2 = Fp[i] / (i² + 1)
     // Note: This is the complex extension field of Fp with i² = -1.
@@ -56,6 +73,7 @@ contract Verifier {
     // Exponents for inversions and square roots mod P
     uint256 constant EXP_INVERSE_FP = 0x30644E72E131A029B85045B68181585D97816A916871CA8D3C208C16D87CFD45; // P - 2
     uint256 constant EXP_SQRT_FP = 0xC19139CB84C680A6E14116DA060561765E05AA45A1C72A34F082305B61F3F52; // (P + 1) / 4;
+    {{- end }}
 
     // Groth16 alpha point in G1
     uint256 constant ALPHA_X = {{.G1.Alpha.X.String}};
@@ -89,6 +107,21 @@ contract Verifier {
     uint256 constant PUB_{{sub $i 1}}_Y = {{$ki.Y.String}};
         {{- end }}
     {{- end }}
+    {{- if gt $numCommitments 0 }}
+
+    // Pedersen G point in G2 in powers of i
+    uint256 constant PEDERSEN_G_X_0 = {{.CommitmentKey.G.X.A0.String}};
+    uint256 constant PEDERSEN_G_X_1 = {{.CommitmentKey.G.X.A1.String}};
+    uint256 constant PEDERSEN_G_Y_0 = {{.CommitmentKey.G.Y.A0.String}};
+    uint256 constant PEDERSEN_G_Y_1 = {{.CommitmentKey.G.Y.A1.String}};
+
+    // Pedersen GRootSigmaNeg point in G2 in powers of i
+    uint256 constant PEDERSEN_GROOTSIGMANEG_X_0 = {{.CommitmentKey.GRootSigmaNeg.X.A0.String}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_X_1 = {{.CommitmentKey.GRootSigmaNeg.X.A1.String}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_Y_0 = {{.CommitmentKey.GRootSigmaNeg.Y.A0.String}};
+    uint256 constant PEDERSEN_GROOTSIGMANEG_Y_1 = {{.CommitmentKey.GRootSigmaNeg.Y.A1.String}};
+    {{- end }}
+    {{- if .CompressedProofs }}
 
     /// Negation in Fp.
     /// @notice Returns a number x such that a + x = 0 in Fp.
@@ -357,15 +390,123 @@ contract Verifier {
             y1 = negate(y1);
         }
     }
+    {{- end }}
+    {{- if gt $numCommitments 0 }}
+
+    /// Compute the public inputs of the commitments.
+    /// @notice Each commitment is hashed with the public inputs it commits to, as
+    /// keccak256(commitment || committed inputs) mod R. This matches the proofs
+    /// generated with solidity.WithProverTargetSolidityVerifier.
+    /// @param commitments The commitments, points of G1 encoded as in EIP-196.
+    /// @param input The public inputs. These are elements of the scalar field Fr.
+    /// @return publicCommitments The public inputs of the commitments.
+    function hashCommitments(
+        uint256[{{mul $numCommitments 2}}] memory commitments,
+        uint256[{{$numPublic}}] calldata input
+    ) internal pure returns (uint256[{{$numCommitments}}] memory publicCommitments) {
+        {{- range $i, $committed := .PublicAndCommitmentCommitted }}
+        publicCommitments[{{$i}}] = uint256(keccak256(abi.encodePacked(
+            commitments[{{mul $i 2}}],
+            commitments[{{add (mul $i 2) 1}}]
+            {{- range $j := $committed }},
+            {{ if lt (sub $j 1) $numPublic }}input[{{sub $j 1}}]{{ else }}publicCommitments[{{sub (sub $j 1) $numPublic}}]{{ end }}
+            {{- end }}
+        ))) % R;
+        {{- end }}
+    }
+
+    /// Verify the proof of knowledge of the commitments.
+    /// @notice Reverts with CommitmentInvalid if the proof of knowledge is invalid
+    /// or if the points are not on the curve.
+    {{- if gt $numCommitments 1 }}
+    /// @notice The commitments are folded with the powers of a challenge derived
+    /// from their public inputs, as in gnark-crypto's pedersen.FoldCommitments, so
+    /// that a single pairing check covers all of them.
+    {{- end }}
+    /// @param commitments The commitments, points of G1 encoded as in EIP-196.
+    {{- if gt $numCommitments 1 }}
+    /// @param publicCommitments The public inputs of the commitments.
+    {{- end }}
+    /// @param commitmentPok The proof of knowledge, a point of G1 encoded as in EIP-196.
+    function verifyCommitmentPok(
+        uint256[{{mul $numCommitments 2}}] memory commitments,
+        uint256[{{$numCommitments}}] memory {{- if gt $numCommitments 1 }} publicCommitments{{ else }} /* publicCommitments */{{ end }},
+        uint256[2] memory commitmentPok
+    ) internal view {
+        bool success = true;
+        uint256[12] memory pairings;
+        {{- if eq $numCommitments 1 }}
+        // e(C, G)
+        pairings[ 0] = commitments[0];
+        pairings[ 1] = commitments[1];
+        {{- else }}
+        // e(∑ᵢ rⁱ⋅Cᵢ, G) where r = sha256("r" || publicCommitments) mod R
+        uint256 r = uint256(sha256(abi.encodePacked("r", publicCommitments))) % R;
+        assembly ("memory-safe") {
+            let f := mload(0x40)
+            let g := add(f, 0x40)
+            mstore(f, mload(commitments))
+            mstore(add(f, 0x20), mload(add(commitments, 0x20)))
+            let ri := r
+            for { let i := 0x40 } lt(i, {{mul $numCommitments 0x40}}) { i := add(i, 0x40) } {
+                mstore(g, mload(add(commitments, i)))
+                mstore(add(g, 0x20), mload(add(commitments, add(i, 0x20))))
+                mstore(add(g, 0x40), ri)
+                success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+                success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+                ri := mulmod(ri, r, R)
+            }
+            mstore(pairings, mload(f))
+            mstore(add(pairings, 0x20), mload(add(f, 0x20)))
+        }
+        {{- end }}
+        pairings[ 2] = PEDERSEN_G_X_1;
+        pairings[ 3] = PEDERSEN_G_X_0;
+        pairings[ 4] = PEDERSEN_G_Y_1;
+        pairings[ 5] = PEDERSEN_G_Y_0;
+        // e(PoK, G^{-1/σ})
+        pairings[ 6] = commitmentPok[0];
+        pairings[ 7] = commitmentPok[1];
+        pairings[ 8] = PEDERSEN_GROOTSIGMANEG_X_1;
+        pairings[ 9] = PEDERSEN_GROOTSIGMANEG_X_0;
+        pairings[10] = PEDERSEN_GROOTSIGMANEG_Y_1;
+        pairings[11] = PEDERSEN_GROOTSIGMANEG_Y_0;
+
+        // Check pairing equation.
+        assembly ("memory-safe") {
+            success := and(success, staticcall(gas(), PRECOMPILE_VERIFY, pairings, 0x180, pairings, 0x20))
+            // Also check returned value (both are either 1 or 0).
+            success := and(success, mload(pairings))
+        }
+        if (!success) {
+            revert CommitmentInvalid();
+        }
+    }
+    {{- end }}
 
     /// Compute the public input linear combination.
     /// @notice Reverts with PublicInputNotInField if the input is not in the field.
     /// @notice Computes the multi-scalar-multiplication of the public input
     /// elements and the verification key including the constant term.
+    {{- if gt $numCommitments 0 }}
+    /// The public inputs of the commitments and the commitments are added.
+    {{- end }}
     /// @param input The public inputs. These are elements of the scalar field Fr.
+    {{- if gt $numCommitments 0 }}
+    /// @param publicCommitments The public inputs of the commitments.
+    /// @param commitments The commitments, points of G1 encoded as in EIP-196.
+    {{- end }}
     /// @return x The X coordinate of the resulting G1 point.
     /// @return y The Y coordinate of the resulting G1 point.
+    {{- if gt $numCommitments 0 }}
+    function publicInputMSM(
+        uint256[{{$numPublic}}] calldata input,
+        uint256[{{$numCommitments}}] memory publicCommitments,
+        uint256[{{mul $numCommitments 2}}] memory commitments
+    )
+    {{- else }}
     function publicInputMSM(uint256[{{$numPublic}}] calldata input)
+    {{- end }}
     internal view returns (uint256 x, uint256 y) {
         // Note: The ECMUL precompile does not reject unreduced values, so we check this.
         // Note: Unrolling this loop does not cost much extra in code-size, the bulk of the
@@ -394,6 +535,19 @@ contract Verifier {
             success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
             success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
             {{- end }}
+            {{- range $i := intRange $numCommitments }}
+            mstore(g, PUB_{{add $numPublic $i}}_X)
+            mstore(add(g, 0x20), PUB_{{add $numPublic $i}}_Y)
+            s := mload(add(publicCommitments, {{mul $i 0x20}}))
+            mstore(add(g, 0x40), s)
+            success := and(success, staticcall(gas(), PRECOMPILE_MUL, g, 0x60, g, 0x40))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            {{- end }}
+            {{- range $i := intRange $numCommitments }}
+            mstore(g, mload(add(commitments, {{mul $i 0x40}})))
+            mstore(add(g, 0x20), mload(add(commitments, {{add (mul $i 0x40) 0x20}})))
+            success := and(success, staticcall(gas(), PRECOMPILE_ADD, f, 0x80, f, 0x40))
+            {{- end }}
             x := mload(f)
             y := mload(add(f, 0x20))
         }
@@ -404,53 +558,82 @@ contract Verifier {
         }
     }
 
+    {{- if .CompressedProofs }}
     /// Compress a proof.
     /// @notice Will revert with InvalidProof if the curve points are invalid,
     /// but does not verify the proof itself.
     /// @param proof The uncompressed Groth16 proof. Elements are in the same order as for
     /// verifyProof. I.e. Groth16 points (A, B, C) encoded as in EIP-197.
+    {{- if gt $numCommitments 0 }}
+    /// @param commitments The commitments, points of G1 encoded as in EIP-196.
+    /// @param commitmentPok The proof of knowledge of the commitments, encoded as in EIP-196.
+    {{- end }}
     /// @return compressed The compressed proof. Elements are in the same order as for
     /// verifyCompressedProof. I.e. points (A, B, C) in compressed format.
+    {{- if gt $numCommitments 0 }}
+    /// @return compressedCommitments The compressed commitments.
+    /// @return compressedCommitmentPok The compressed proof of knowledge of the commitments.
+    function compressProof(
+        uint256[8] calldata proof,
+        uint256[{{mul $numCommitments 2}}] calldata commitments,
+        uint256[2] calldata commitmentPok
+    ) public view returns (
+        uint256[4] memory compressed,
+        uint256[{{$numCommitments}}] memory compressedCommitments,
+        uint256 compressedCommitmentPok
+    ) {
+    {{- else }}
     function compressProof(uint256[8] calldata proof)
     public view returns (uint256[4] memory compressed) {
+    {{- end }}
         compressed[0] = compress_g1(proof[0], proof[1]);
         (compressed[2], compressed[1]) = compress_g2(proof[3], proof[2], proof[5], proof[4]);
         compressed[3] = compress_g1(proof[6], proof[7]);
+        {{- range $i := intRange $numCommitments }}
+        compressedCommitments[{{$i}}] = compress_g1(commitments[{{mul $i 2}}], commitments[{{add (mul $i 2) 1}}]);
+        {{- end }}
+        {{- if gt $numCommitments 0 }}
+        compressedCommitmentPok = compress_g1(commitmentPok[0], commitmentPok[1]);
+        {{- end }}
     }
 
     /// Verify a Groth16 proof with compressed points.
     /// @notice Reverts with InvalidProof if the proof is invalid or
     /// with PublicInputNotInField the public input is not reduced.
+    {{- if gt $numCommitments 0 }}
+    /// @notice Reverts with CommitmentInvalid if the proof of knowledge of the
+    /// commitments is invalid.
+    {{- end }}
     /// @notice There is no return value. If the function does not revert, the
     /// proof was successfully verified.
     /// @param compressedProof the points (A, B, C) in compressed format
     /// matching the output of compressProof.
+    {{- if gt $numCommitments 0 }}
+    /// @param compressedCommitments the compressed commitments matching the
+    /// output of compressProof.
+    /// @param compressedCommitmentPok the compressed proof of knowledge of the
+    /// commitments matching the output of compressProof.
+    {{- end }}
     /// @param input the public input field elements in the scalar field Fr.
     /// Elements must be reduced.
     function verifyCompressedProof(
         uint256[4] calldata compressedProof,
+        {{- if gt $numCommitments 0 }}
+        uint256[{{$numCommitments}}] calldata compressedCommitments,
+        uint256 compressedCommitmentPok,
+        {{- end }}
         uint256[{{$numPublic}}] calldata input
     ) public view {
-        (uint256 Ax, uint256 Ay) = decompress_g1(compressedProof[0]);
-        (uint256 Bx0, uint256 Bx1, uint256 By0, uint256 By1) = decompress_g2(
-                compressedProof[2], compressedProof[1]);
-        (uint256 Cx, uint256 Cy) = decompress_g1(compressedProof[3]);
-        (uint256 Lx, uint256 Ly) = publicInputMSM(input);
-
-        // Verify the pairing
         // Note: The precompile expects the F2 coefficients in big-endian order.
         // Note: The pairing precompile rejects unreduced values, so we won't check that here.
+        // Note: The points are decompressed in place to avoid stack-too-deep.
         uint256[24] memory pairings;
         // e(A, B)
-        pairings[ 0] = Ax;
-        pairings[ 1] = Ay;
-        pairings[ 2] = Bx1;
-        pairings[ 3] = Bx0;
-        pairings[ 4] = By1;
-        pairings[ 5] = By0;
+        (pairings[ 0], pairings[ 1]) = decompress_g1(compressedProof[0]);
+        (pairings[ 3], pairings[ 2], pairings[ 5], pairings[ 4]) = decompress_g2(
+                compressedProof[2], compressedProof[1]);
         // e(C, -δ)
-        pairings[ 6] = Cx;
-        pairings[ 7] = Cy;
+        (pairings[ 6], pairings[ 7]) = decompress_g1(compressedProof[3]);
         pairings[ 8] = DELTA_NEG_X_1;
         pairings[ 9] = DELTA_NEG_X_0;
         pairings[10] = DELTA_NEG_Y_1;
@@ -463,8 +646,21 @@ contract Verifier {
         pairings[16] = BETA_NEG_Y_1;
         pairings[17] = BETA_NEG_Y_0;
         // e(L_pub, -γ)
-        pairings[18] = Lx;
-        pairings[19] = Ly;
+        {{- if gt $numCommitments 0 }}
+        {
+            uint256[{{mul $numCommitments 2}}] memory commitments;
+            uint256[2] memory commitmentPok;
+            {{- range $i := intRange $numCommitments }}
+            (commitments[{{mul $i 2}}], commitments[{{add (mul $i 2) 1}}]) = decompress_g1(compressedCommitments[{{$i}}]);
+            {{- end }}
+            (commitmentPok[0], commitmentPok[1]) = decompress_g1(compressedCommitmentPok);
+            uint256[{{$numCommitments}}] memory publicCommitments = hashCommitments(commitments, input);
+            verifyCommitmentPok(commitments, publicCommitments, commitmentPok);
+            (pairings[18], pairings[19]) = publicInputMSM(input, publicCommitments, commitments);
+        }
+        {{- else }}
+        (pairings[18], pairings[19]) = publicInputMSM(input);
+        {{- end }}
         pairings[20] = GAMMA_NEG_X_1;
         pairings[21] = GAMMA_NEG_X_0;
         pairings[22] = GAMMA_NEG_Y_1;
@@ -482,21 +678,40 @@ contract Verifier {
             revert ProofInvalid();
         }
     }
+    {{- end }}
 
     /// Verify an uncompressed Groth16 proof.
     /// @notice Reverts with InvalidProof if the proof is invalid or
     /// with PublicInputNotInField the public input is not reduced.
+    {{- if gt $numCommitments 0 }}
+    /// @notice Reverts with CommitmentInvalid if the proof of knowledge of the
+    /// commitments is invalid.
+    {{- end }}
     /// @notice There is no return value. If the function does not revert, the
     /// proof was successfully verified.
-    /// @param proof the points (A, B, C) in EIP-197 format matching the output
-    /// of compressProof.
+    /// @notice The arguments are the words of Proof.MarshalSolidity, in order.
+    /// @param proof the points (A, B, C) in EIP-197 format.
+    {{- if gt $numCommitments 0 }}
+    /// @param commitments the commitments, points of G1 encoded as in EIP-196.
+    /// @param commitmentPok the proof of knowledge of the commitments, encoded as in EIP-196.
+    {{- end }}
     /// @param input the public input field elements in the scalar field Fr.
     /// Elements must be reduced.
     function verifyProof(
         uint256[8] calldata proof,
+        {{- if gt $numCommitments 0 }}
+        uint256[{{mul $numCommitments 2}}] calldata commitments,
+        uint256[2] calldata commitmentPok,
+        {{- end }}
         uint256[{{$numPublic}}] calldata input
     ) public view {
+        {{- if gt $numCommitments 0 }}
+        uint256[{{$numCommitments}}] memory publicCommitments = hashCommitments(commitments, input);
+        verifyCommitmentPok(commitments, publicCommitments, commitmentPok);
+        (uint256 x, uint256 y) = publicInputMSM(input, publicCommitments, commitments);
+        {{- else }}
         (uint256 x, uint256 y) = publicInputMSM(input);
+        {{- end }}
 
         // Note: The precompile expects the F2 coefficients in big-endian order.
         // Note: The pairing precompile rejects unreduced values, so we won't check that here.
@@ -543,3 +758,35 @@ contract Verifier {
     }
 }
 `
+
+// solidityTemplateData is the data of solidityTemplate: the verifying key, with
+// Beta, Gamma and Delta negated, and the export options.
+type solidityTemplateData struct {
+	*VerifyingKey
+	solidity.ExportConfig
+}
+
+// MarshalSolidity converts a proof to a byte array that can be used in a
+// Solidity contract: the words of the arguments of verifyProof, in order. That
+// is the points A, B and C in the encoding of EIP-197, followed if the circuit
+// has commitments by the commitments and their proof of knowledge, encoded as
+// in EIP-196.
+func (proof *Proof) MarshalSolidity() []byte {
+	res := make([]byte, 0, 8*fp.Bytes+2*fp.Bytes*(len(proof.Commitments)+1))
+
+	// uint256[8] proof
+	res = append(res, proof.Ar.Marshal()...)
+	res = append(res, proof.Bs.Marshal()...)
+	res = append(res, proof.Krs.Marshal()...)
+
+	if len(proof.Commitments) > 0 {
+		// uint256[2*nbCommitments] commitments
+		for i := range proof.Commitments {
+			res = append(res, proof.Commitments[i].Marshal()...)
+		}
+		// uint256[2] commitmentPok
+		res = append(res, proof.CommitmentPok.Marshal()...)
+	}
+
+	return res
+}
@@ -0,0 +1,77 @@
+package groth16_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	gnarktest "github.com/consensys/gnark/test"
+	"github.com/stretchr/testify/require"
+)
+
+type multiCommitmentCircuit struct {
+	X, Y frontend.Variable
+	Z    frontend.Variable `gnark:",public"`
+}
+
+func (c *multiCommitmentCircuit) Define(api frontend.API) error {
+	committer := api.(frontend.Committer)
+	c0, err := committer.Commit(c.X, c.Z)
+	if err != nil {
+		return err
+	}
+	c1, err := committer.Commit(c.Y, c0)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(c0, c1)
+	api.AssertIsEqual(api.Add(c.X, c.Y), c.Z)
+	return nil
+}
+
+func TestSolidityMultiCommitment(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &multiCommitmentCircuit{})
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&multiCommitmentCircuit{X: 3, Y: 4, Z: 7}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	public, err := w.Public()
+	assert.NoError(err)
+
+	proof, err := groth16.Prove(ccs, pk, w, solidity.WithProverTargetSolidityVerifier(backend.GROTH16))
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, public, solidity.WithVerifierTargetSolidityVerifier(backend.GROTH16)))
+	assert.Error(groth16.Verify(proof, vk, public), "the commitments are not hashed with the default hash to field")
+
+	// proof || commitments || commitmentPok
+	_proof := proof.(*groth16_bn254.Proof)
+	assert.Len(_proof.Commitments, 2)
+	assert.Len(_proof.MarshalSolidity(), 32*(8+2*2+2))
+
+	var buf bytes.Buffer
+	assert.NoError(vk.ExportSolidity(&buf))
+	assert.Contains(buf.String(), "uint256[4] calldata commitments,")
+	assert.Contains(buf.String(), "function verifyCompressedProof(")
+	assert.Contains(buf.String(), "uint256[2] calldata compressedCommitments,")
+
+	buf.Reset()
+	assert.NoError(vk.ExportSolidity(&buf, solidity.WithoutCompressedProofs()))
+	assert.Contains(buf.String(), "uint256[4] calldata commitments,")
+	assert.NotContains(buf.String(), "function verifyCompressedProof(")
+
+	// with the solccheck build tag, the proof is verified by the exported
+	// contract, also through compressProof and verifyCompressedProof.
+	gnarktest.NewAssert(t).CheckCircuit(&multiCommitmentCircuit{},
+		gnarktest.WithValidAssignment(&multiCommitmentCircuit{X: 3, Y: 4, Z: 7}),
+		gnarktest.WithCurves(ecc.BN254),
+		gnarktest.WithBackends(backend.GROTH16))
+}
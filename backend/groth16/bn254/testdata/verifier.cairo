@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: MIT
+// Code generated by gnark DO NOT EDIT
+
+//! Groth16 verifier on BN254 for Starknet.
+//!
+//! Starknet doesn't provide the BN254 precompiles of the EVM: the multi scalar
+//! multiplications and the pairing check are delegated to a contract implementing
+//! IBn254Curve (for instance built with Garaga), whose address is given to the
+//! constructor.
+
+/// A point of G1, in affine coordinates.
+#[derive(Copy, Drop, Serde)]
+pub struct G1Point {
+    pub x: u256,
+    pub y: u256,
+}
+
+/// A point of G2, in affine coordinates x = x0 + x1⋅i, y = y0 + y1⋅i.
+#[derive(Copy, Drop, Serde)]
+pub struct G2Point {
+    pub x0: u256,
+    pub x1: u256,
+    pub y0: u256,
+    pub y1: u256,
+}
+
+#[starknet::interface]
+pub trait IBn254Curve<TContractState> {
+    /// Returns ∑ᵢ scalarsᵢ⋅pointsᵢ. Reverts if a point is not on the curve.
+    fn msm(self: @TContractState, points: Span<G1Point>, scalars: Span<u256>) -> G1Point;
+    /// Returns true if ∏ᵢ e(aᵢ, bᵢ) = 1. Reverts if a point is not in its group.
+    fn pairing_check(self: @TContractState, a: Span<G1Point>, b: Span<G2Point>) -> bool;
+}
+
+#[starknet::interface]
+pub trait IGroth16Verifier<TContractState> {
+    /// Verify an uncompressed Groth16 proof.
+    /// Reverts if the proof or the public inputs are malformed.
+    /// proof: the points (A, B, C) in EIP-197 format, as for verifyProof in the
+    /// Solidity contract.
+    /// public_inputs: the public input field elements in the scalar field Fr.
+    /// Elements must be reduced.
+    fn verify_proof(self: @TContractState, proof: Span<u256>, public_inputs: Span<u256>) -> bool;
+}
+
+#[starknet::contract]
+pub mod Groth16Verifier {
+    use starknet::ContractAddress;
+    use starknet::storage::{StoragePointerReadAccess, StoragePointerWriteAccess};
+    use super::{G1Point, G2Point, IBn254CurveDispatcher, IBn254CurveDispatcherTrait};
+
+    // Scalar field Fr order R
+    const R: u256 = 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001;
+
+    const NB_PUBLIC_INPUTS: u32 = 2;
+
+    // Groth16 alpha point in G1
+    const ALPHA_X: u256 = 17097527669141425851469939365584278672533224966909250894186724731392136694321;
+    const ALPHA_Y: u256 = 1633206803106994917390142861394462597513811199592086689213778285316767918865;
+
+    // Groth16 beta point in G2 in powers of i
+    const BETA_NEG_X_0: u256 = 18284646505875132932078981185059839150823653335271246100877941456540006538359;
+    const BETA_NEG_X_1: u256 = 16675415260916740441074015936575270041312594870164560244531229284997128686606;
+    const BETA_NEG_Y_0: u256 = 15968130090416649269375875990372632076715274804604950831125918776110412880786;
+    const BETA_NEG_Y_1: u256 = 20094217760411817755201841299908070712130673582368065010019644222761258001969;
+
+    // Groth16 gamma point in G2 in powers of i
+    const GAMMA_NEG_X_0: u256 = 9248769316750099984700426442923093903282925605722459880276881929007551549957;
+    const GAMMA_NEG_X_1: u256 = 11527010945554146642744348071960103841799760004293664949364289668637212872094;
+    const GAMMA_NEG_Y_0: u256 = 1589869855348385093196126927299086684377019272338654082560699768787819357007;
+    const GAMMA_NEG_Y_1: u256 = 4993886879304140417163454598090671591612400778951745102581410453011616292615;
+
+    // Groth16 delta point in G2 in powers of i
+    const DELTA_NEG_X_0: u256 = 20334225949325585381168882659216839398418116072682501351085615993049787819732;
+    const DELTA_NEG_X_1: u256 = 5398210565837960148385933025168459419718947958996217480138156658345388903528;
+    const DELTA_NEG_Y_0: u256 = 14636754270160393658715614120395159676947414196626930360046198962010160809342;
+    const DELTA_NEG_Y_1: u256 = 16297693666565747368449524984878176899932271172208040803410434299861270479775;
+
+    // Constant and public input points
+    const CONSTANT_X: u256 = 19840531420580618256738272558670961003165372200765659372075843173796920595481;
+    const CONSTANT_Y: u256 = 964729948753766014820977600945135077764340089867817949282488852943573276066;
+    const PUB_0_X: u256 = 11321435649066676026935172031513701652750090323988487723655646379462382001907;
+    const PUB_0_Y: u256 = 19011568533736226645714967127834653112587298417873749489759464418242136091468;
+    const PUB_1_X: u256 = 6154098237865012940708265151827792020543189803471017296612174360454143009606;
+    const PUB_1_Y: u256 = 13616199616158862978508372516089506818553968790805627403281137401489696246496;
+
+    #[storage]
+    struct Storage {
+        curve: ContractAddress,
+    }
+
+    #[constructor]
+    fn constructor(ref self: ContractState, curve: ContractAddress) {
+        self.curve.write(curve);
+    }
+
+    #[abi(embed_v0)]
+    impl Groth16VerifierImpl of super::IGroth16Verifier<ContractState> {
+        fn verify_proof(self: @ContractState, proof: Span<u256>, public_inputs: Span<u256>) -> bool {
+            assert(proof.len() == 8, 'wrong proof size');
+            assert(public_inputs.len() == NB_PUBLIC_INPUTS, 'wrong number of public inputs');
+            let curve = IBn254CurveDispatcher { contract_address: self.curve.read() };
+
+            // L_pub = CONSTANT + ∑ᵢ inputᵢ⋅PUBᵢ
+            let points = array![
+                G1Point { x: CONSTANT_X, y: CONSTANT_Y },
+                G1Point { x: PUB_0_X, y: PUB_0_Y },
+                G1Point { x: PUB_1_X, y: PUB_1_Y },
+            ];
+            let mut scalars = array![1_u256];
+            for input in public_inputs {
+                assert(*input < R, 'public input not in field');
+                scalars.append(*input);
+            };
+            let l = curve.msm(points.span(), scalars.span());
+
+            // The proof encodes the F2 coefficients in big-endian order.
+            let a = G1Point { x: *proof.at(0), y: *proof.at(1) };
+            let b = G2Point { x0: *proof.at(3), x1: *proof.at(2), y0: *proof.at(5), y1: *proof.at(4) };
+            let c = G1Point { x: *proof.at(6), y: *proof.at(7) };
+
+            // e(A, B)⋅e(C, -δ)⋅e(α, -β)⋅e(L_pub, -γ) = 1
+            curve
+                .pairing_check(
+                    array![a, c, G1Point { x: ALPHA_X, y: ALPHA_Y }, l].span(),
+                    array![
+                        b,
+                        G2Point { x0: DELTA_NEG_X_0, x1: DELTA_NEG_X_1, y0: DELTA_NEG_Y_0, y1: DELTA_NEG_Y_1 },
+                        G2Point { x0: BETA_NEG_X_0, x1: BETA_NEG_X_1, y0: BETA_NEG_Y_0, y1: BETA_NEG_Y_1 },
+                        G2Point { x0: GAMMA_NEG_X_0, x1: GAMMA_NEG_X_1, y0: GAMMA_NEG_Y_0, y1: GAMMA_NEG_Y_1 },
+                    ]
+                        .span(),
+                )
+        }
+    }
+}
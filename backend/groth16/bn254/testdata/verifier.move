@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: MIT
+// Code generated by gnark DO NOT EDIT
+
+/// Groth16 verifier on BN254, using the crypto_algebra module of Aptos.
+/// The module is published at the named address verifier.
+module verifier::groth16_verifier {
+    use std::bcs;
+    use std::option;
+    use std::vector;
+    use aptos_std::bn254_algebra::{Fr, FormatFrLsb, G1, FormatG1Uncompr, G2, FormatG2Uncompr, Gt};
+    use aptos_std::crypto_algebra::{Self, Element};
+
+    /// Some of the provided public input values are larger than the field modulus.
+    const EPUBLIC_INPUT_NOT_IN_FIELD: u64 = 1;
+    /// The number of public inputs is not the one of the verifying key.
+    const EWRONG_NUMBER_OF_PUBLIC_INPUTS: u64 = 2;
+    /// The proof is not on 256 bytes, or its points are not in their groups.
+    const EINVALID_PROOF_ENCODING: u64 = 3;
+
+    const NB_PUBLIC_INPUTS: u64 = 2;
+
+    // The points are serialized in the formats FormatG1Uncompr and FormatG2Uncompr.
+
+    // Groth16 alpha point in G1
+    const ALPHA: vector<u8> = x"31ca491534124aee8b5d22c9a25bc301f69cdd34b8ad5208dfc455eb93dbcc2511abae0868a9d0917b2c3ebfbf73bda4f1d98ea5756cc7084bfebc31bf5c9c03";
+
+    // Groth16 beta, gamma and delta points in G2, negated
+    const BETA_NEG: vector<u8> = x"77dca2a4c9e266f6228d80f99538b22f97a263ab24749c736bb9ced340be6c280e8c1a2bd8f03395149afca35c8b0e174375f1f2aded86493c33b68258f3dd24925b7a64a36e4a06840706c59eee42aee07ff6050dcf131d4026a8762fa44d233126ad281be0d43c2e70640ed30e34ac83ea33da7da7bb35ab470a1a99ec6c2c";
+    const GAMMA_NEG: vector<u8> = x"0512097551e8a06ff513aa7f946cc1f805e9952a2ca784fa84ea1a5c309e72149e0df5a8e3b6e241a0cc27563e871e74e2a214fcd9872a9df7c59ffb960e7c194fe3e05e0e9d61371595ea7e60b4074d7bc8e179c592f22362e466769ed5830307df145d7af6ed1eb7cbe64a9fa0745b13cb4c213a28f69385ec174997700a0b";
+    const DELTA_NEG: vector<u8> = x"d4664a3b35a755c83a01f77f8793b37e6b948de501c00278926f689b97c3f42c688ce9a4261944e3704eb7d3300a73ce9281748bf582ca0b756aca556647ef0b7ec5663a55c9d43862553f87a97093ed9a3d2682199bc32a74d758a6fd1b5c209f430f4b266a3236d68cf0d82f0aeb23af2fdc3dc48f56e15ff583e6ef2a0824";
+
+    // Constant and public input points
+    const CONSTANT: vector<u8> = x"19942a1f54ac7fbbd31b7244421bf41e64f193b4962c133bdfc8ffc7c357dd2ba2d55ef52eb6269891d9e95639745f679b5d85adfdbd2abe3838c1988e042202";
+    const PUB_0: vector<u8> = x"f31e1fb33ed84542cac9aea7db12515cfe3e068153978be99c01f9709bb407194cabd73b635cf5864471b26fe3048af5083dc378be23dce949643f659d2a082a";
+    const PUB_1: vector<u8> = x"46e321a11d9373fb9b2a3ee02bb61d7c19b6c6c041d3ae0a9b4eb55b9e189b0de04a98d6651e2a93e92459ad9a1f6c9c0f14ab65803ddf90c66906a7f17e1a1e";
+
+    #[view]
+    /// Verify an uncompressed Groth16 proof.
+    /// Aborts if the proof or the public inputs are malformed.
+    /// @param proof the points (A, B, C) in EIP-197 format, as for verifyProof in the
+    /// Solidity contract.
+    /// @param public_inputs the public input field elements in the scalar field Fr.
+    /// Elements must be reduced.
+    /// @return true if the proof is valid, false otherwise.
+    public fun verify_proof(proof: vector<u8>, public_inputs: vector<u256>): bool {
+        assert!(vector::length(&proof) == 256, EINVALID_PROOF_ENCODING);
+        assert!(vector::length(&public_inputs) == NB_PUBLIC_INPUTS, EWRONG_NUMBER_OF_PUBLIC_INPUTS);
+
+        let a = g1_from_evm(&proof, 0);
+        let b = g2_from_evm(&proof, 64);
+        let c = g1_from_evm(&proof, 192);
+
+        // L_pub = CONSTANT + ∑ᵢ inputᵢ⋅PUBᵢ
+        let points = vector[
+            g1(CONSTANT),
+            g1(PUB_0),
+            g1(PUB_1),
+        ];
+        let scalars = vector[crypto_algebra::one<Fr>()];
+        let i = 0;
+        while (i < NB_PUBLIC_INPUTS) {
+            vector::push_back(&mut scalars, fr_from_u256(*vector::borrow(&public_inputs, i)));
+            i = i + 1;
+        };
+        let l = crypto_algebra::multi_scalar_mul(&points, &scalars);
+
+        // e(A, B)⋅e(C, -δ)⋅e(α, -β)⋅e(L_pub, -γ) = 1
+        let res = crypto_algebra::multi_pairing<G1, G2, Gt>(
+            &vector[a, c, g1(ALPHA), l],
+            &vector[b, g2(DELTA_NEG), g2(BETA_NEG), g2(GAMMA_NEG)],
+        );
+        crypto_algebra::eq(&res, &crypto_algebra::zero<Gt>())
+    }
+
+    fun g1(bytes: vector<u8>): Element<G1> {
+        option::destroy_some(crypto_algebra::deserialize<G1, FormatG1Uncompr>(&bytes))
+    }
+
+    fun g2(bytes: vector<u8>): Element<G2> {
+        option::destroy_some(crypto_algebra::deserialize<G2, FormatG2Uncompr>(&bytes))
+    }
+
+    /// Reads the point of G1 encoded at offset as in EIP-196, i.e. x then y in big endian.
+    fun g1_from_evm(bytes: &vector<u8>, offset: u64): Element<G1> {
+        let b = vector::empty<u8>();
+        append_reversed(&mut b, bytes, offset);
+        append_reversed(&mut b, bytes, offset + 32);
+        let p = crypto_algebra::deserialize<G1, FormatG1Uncompr>(&b);
+        assert!(option::is_some(&p), EINVALID_PROOF_ENCODING);
+        option::destroy_some(p)
+    }
+
+    /// Reads the point of G2 encoded at offset as in EIP-197, i.e. x₁, x₀, y₁ then y₀
+    /// in big endian.
+    fun g2_from_evm(bytes: &vector<u8>, offset: u64): Element<G2> {
+        let b = vector::empty<u8>();
+        append_reversed(&mut b, bytes, offset + 32);
+        append_reversed(&mut b, bytes, offset);
+        append_reversed(&mut b, bytes, offset + 96);
+        append_reversed(&mut b, bytes, offset + 64);
+        let p = crypto_algebra::deserialize<G2, FormatG2Uncompr>(&b);
+        assert!(option::is_some(&p), EINVALID_PROOF_ENCODING);
+        option::destroy_some(p)
+    }
+
+    /// Appends the 32 bytes of src at offset to dst, in reverse order.
+    fun append_reversed(dst: &mut vector<u8>, src: &vector<u8>, offset: u64) {
+        let i = 32;
+        while (i > 0) {
+            i = i - 1;
+            vector::push_back(dst, *vector::borrow(src, offset + i));
+        };
+    }
+
+    fun fr_from_u256(x: u256): Element<Fr> {
+        let e = crypto_algebra::deserialize<Fr, FormatFrLsb>(&bcs::to_bytes(&x));
+        assert!(option::is_some(&e), EPUBLIC_INPUT_NOT_IN_FIELD);
+        option::destroy_some(e)
+    }
+}
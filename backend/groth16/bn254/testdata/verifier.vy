@@ -0,0 +1,125 @@
+# @version ^0.3.10
+# SPDX-License-Identifier: MIT
+# Code generated by gnark DO NOT EDIT
+
+"""
+@title Groth16 verifier
+@notice Verifies Groth16 proofs on BN254, using the precompiles of EIP-196 and
+        EIP-197.
+"""
+
+# Address of the pairing precompile
+PRECOMPILE_VERIFY: constant(address) = 0x0000000000000000000000000000000000000008
+
+# Scalar field Fr order R
+R: constant(uint256) = 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+
+# Groth16 alpha point in G1
+ALPHA_X: constant(uint256) = 17097527669141425851469939365584278672533224966909250894186724731392136694321
+ALPHA_Y: constant(uint256) = 1633206803106994917390142861394462597513811199592086689213778285316767918865
+
+# Groth16 beta point in G2 in powers of i
+BETA_NEG_X_0: constant(uint256) = 18284646505875132932078981185059839150823653335271246100877941456540006538359
+BETA_NEG_X_1: constant(uint256) = 16675415260916740441074015936575270041312594870164560244531229284997128686606
+BETA_NEG_Y_0: constant(uint256) = 15968130090416649269375875990372632076715274804604950831125918776110412880786
+BETA_NEG_Y_1: constant(uint256) = 20094217760411817755201841299908070712130673582368065010019644222761258001969
+
+# Groth16 gamma point in G2 in powers of i
+GAMMA_NEG_X_0: constant(uint256) = 9248769316750099984700426442923093903282925605722459880276881929007551549957
+GAMMA_NEG_X_1: constant(uint256) = 11527010945554146642744348071960103841799760004293664949364289668637212872094
+GAMMA_NEG_Y_0: constant(uint256) = 1589869855348385093196126927299086684377019272338654082560699768787819357007
+GAMMA_NEG_Y_1: constant(uint256) = 4993886879304140417163454598090671591612400778951745102581410453011616292615
+
+# Groth16 delta point in G2 in powers of i
+DELTA_NEG_X_0: constant(uint256) = 20334225949325585381168882659216839398418116072682501351085615993049787819732
+DELTA_NEG_X_1: constant(uint256) = 5398210565837960148385933025168459419718947958996217480138156658345388903528
+DELTA_NEG_Y_0: constant(uint256) = 14636754270160393658715614120395159676947414196626930360046198962010160809342
+DELTA_NEG_Y_1: constant(uint256) = 16297693666565747368449524984878176899932271172208040803410434299861270479775
+
+# Constant and public input points
+CONSTANT_X: constant(uint256) = 19840531420580618256738272558670961003165372200765659372075843173796920595481
+CONSTANT_Y: constant(uint256) = 964729948753766014820977600945135077764340089867817949282488852943573276066
+PUB_0_X: constant(uint256) = 11321435649066676026935172031513701652750090323988487723655646379462382001907
+PUB_0_Y: constant(uint256) = 19011568533736226645714967127834653112587298417873749489759464418242136091468
+PUB_1_X: constant(uint256) = 6154098237865012940708265151827792020543189803471017296612174360454143009606
+PUB_1_Y: constant(uint256) = 13616199616158862978508372516089506818553968790805627403281137401489696246496
+
+
+@internal
+@view
+def _public_input_msm(input: uint256[2]) -> uint256[2]:
+    """
+    @notice Compute the public input linear combination.
+    @dev Reverts with "public input not in field" if the input is not in the
+         field.
+    @param input The public inputs. These are elements of the scalar field Fr.
+    @return The linear combination CONSTANT + ∑ᵢ inputᵢ⋅PUBᵢ.
+    """
+    acc: uint256[2] = [CONSTANT_X, CONSTANT_Y]
+    assert input[0] < R, "public input not in field"
+    acc = ecadd(acc, ecmul([PUB_0_X, PUB_0_Y], input[0]))
+    assert input[1] < R, "public input not in field"
+    acc = ecadd(acc, ecmul([PUB_1_X, PUB_1_Y], input[1]))
+    return acc
+
+
+@external
+@view
+def verifyProof(proof: uint256[8], input: uint256[2]):
+    """
+    @notice Verify an uncompressed Groth16 proof.
+    @dev Reverts with "proof invalid" if the proof is invalid, and with "public
+         input not in field" if a public input is not reduced.
+    @param proof the points (A, B, C) in EIP-197 format, as for verifyProof
+           in the Solidity contract.
+    @param input the public input field elements in the scalar field Fr.
+           Elements must be reduced.
+    """
+    l: uint256[2] = self._public_input_msm(input)
+
+    # Note: The precompile expects the F2 coefficients in big-endian order.
+    # Note: The pairing precompile rejects unreduced values, so we won't check that here.
+    data: Bytes[768] = concat(
+        # e(A, B)
+        convert(proof[0], bytes32),
+        convert(proof[1], bytes32),
+        convert(proof[2], bytes32),
+        convert(proof[3], bytes32),
+        convert(proof[4], bytes32),
+        convert(proof[5], bytes32),
+        # e(C, -δ)
+        convert(proof[6], bytes32),
+        convert(proof[7], bytes32),
+        convert(DELTA_NEG_X_1, bytes32),
+        convert(DELTA_NEG_X_0, bytes32),
+        convert(DELTA_NEG_Y_1, bytes32),
+        convert(DELTA_NEG_Y_0, bytes32),
+        # e(α, -β)
+        convert(ALPHA_X, bytes32),
+        convert(ALPHA_Y, bytes32),
+        convert(BETA_NEG_X_1, bytes32),
+        convert(BETA_NEG_X_0, bytes32),
+        convert(BETA_NEG_Y_1, bytes32),
+        convert(BETA_NEG_Y_0, bytes32),
+        # e(L_pub, -γ)
+        convert(l[0], bytes32),
+        convert(l[1], bytes32),
+        convert(GAMMA_NEG_X_1, bytes32),
+        convert(GAMMA_NEG_X_0, bytes32),
+        convert(GAMMA_NEG_Y_1, bytes32),
+        convert(GAMMA_NEG_Y_0, bytes32),
+    )
+
+    # Check pairing equation.
+    success: bool = False
+    response: Bytes[32] = b""
+    success, response = raw_call(
+        PRECOMPILE_VERIFY,
+        data,
+        max_outsize=32,
+        is_static_call=True,
+        revert_on_failure=False,
+    )
+    # Either proof or verification key invalid.
+    # We assume the contract is correctly generated, so the verification key is valid.
+    assert success and len(response) == 32 and convert(response, uint256) == 1, "proof invalid"
@@ -0,0 +1,294 @@
+package groth16
+
+// vyperTemplate is the Vyper port of solidityTemplate, without the compressed
+// proofs. The proof, the commitments and the public inputs are encoded as for
+// verifyProof in the Solidity contract, so that the proofs with commitments
+// must be generated with solidity.WithProverTargetSolidityVerifier.
+//
+// The generated contract is compiled with vyper and executed on an EVM by the
+// tests with the vypercheck build tag.
+const vyperTemplate = `
+{{- $numCommitments := len .PublicAndCommitmentCommitted -}}
+{{- $numPublic := sub (sub (len .G1.K) $numCommitments) 1 -}}
+# @version ^0.3.10
+# SPDX-License-Identifier: MIT
+# Code generated by gnark DO NOT EDIT
+
+"""
+@title Groth16 verifier
+@notice Verifies Groth16 proofs on BN254
+{{- if gt $numCommitments 0 }} with {{ $numCommitments }} BSB22 commitment(s){{ end }}, using the precompiles of EIP-196 and
+        EIP-197.
+"""
+
+# Address of the pairing precompile
+PRECOMPILE_VERIFY: constant(address) = 0x0000000000000000000000000000000000000008
+
+# Scalar field Fr order R
+R: constant(uint256) = 0x30644e72e131a029b85045b68181585d2833e84879b9709143e1f593f0000001
+
+# Groth16 alpha point in G1
+ALPHA_X: constant(uint256) = {{.G1.Alpha.X.String}}
+ALPHA_Y: constant(uint256) = {{.G1.Alpha.Y.String}}
+
+# Groth16 beta point in G2 in powers of i
+BETA_NEG_X_0: constant(uint256) = {{.G2.Beta.X.A0.String}}
+BETA_NEG_X_1: constant(uint256) = {{.G2.Beta.X.A1.String}}
+BETA_NEG_Y_0: constant(uint256) = {{.G2.Beta.Y.A0.String}}
+BETA_NEG_Y_1: constant(uint256) = {{.G2.Beta.Y.A1.String}}
+
+# Groth16 gamma point in G2 in powers of i
+GAMMA_NEG_X_0: constant(uint256) = {{.G2.Gamma.X.A0.String}}
+GAMMA_NEG_X_1: constant(uint256) = {{.G2.Gamma.X.A1.String}}
+GAMMA_NEG_Y_0: constant(uint256) = {{.G2.Gamma.Y.A0.String}}
+GAMMA_NEG_Y_1: constant(uint256) = {{.G2.Gamma.Y.A1.String}}
+
+# Groth16 delta point in G2 in powers of i
+DELTA_NEG_X_0: constant(uint256) = {{.G2.Delta.X.A0.String}}
+DELTA_NEG_X_1: constant(uint256) = {{.G2.Delta.X.A1.String}}
+DELTA_NEG_Y_0: constant(uint256) = {{.G2.Delta.Y.A0.String}}
+DELTA_NEG_Y_1: constant(uint256) = {{.G2.Delta.Y.A1.String}}
+
+# Constant and public input points
+{{- $k0 := index .G1.K 0}}
+CONSTANT_X: constant(uint256) = {{$k0.X.String}}
+CONSTANT_Y: constant(uint256) = {{$k0.Y.String}}
+{{- range $i, $ki := .G1.K }}
+{{- if gt $i 0 }}
+PUB_{{sub $i 1}}_X: constant(uint256) = {{$ki.X.String}}
+PUB_{{sub $i 1}}_Y: constant(uint256) = {{$ki.Y.String}}
+{{- end }}
+{{- end }}
+{{- if gt $numCommitments 0 }}
+
+# Pedersen G point in G2 in powers of i
+PEDERSEN_G_X_0: constant(uint256) = {{.CommitmentKey.G.X.A0.String}}
+PEDERSEN_G_X_1: constant(uint256) = {{.CommitmentKey.G.X.A1.String}}
+PEDERSEN_G_Y_0: constant(uint256) = {{.CommitmentKey.G.Y.A0.String}}
+PEDERSEN_G_Y_1: constant(uint256) = {{.CommitmentKey.G.Y.A1.String}}
+
+# Pedersen GRootSigmaNeg point in G2 in powers of i
+PEDERSEN_GROOTSIGMANEG_X_0: constant(uint256) = {{.CommitmentKey.GRootSigmaNeg.X.A0.String}}
+PEDERSEN_GROOTSIGMANEG_X_1: constant(uint256) = {{.CommitmentKey.GRootSigmaNeg.X.A1.String}}
+PEDERSEN_GROOTSIGMANEG_Y_0: constant(uint256) = {{.CommitmentKey.GRootSigmaNeg.Y.A0.String}}
+PEDERSEN_GROOTSIGMANEG_Y_1: constant(uint256) = {{.CommitmentKey.GRootSigmaNeg.Y.A1.String}}
+
+
+@internal
+@pure
+def _hash_commitments(
+    commitments: uint256[{{mul $numCommitments 2}}],
+    input: uint256[{{$numPublic}}],
+) -> uint256[{{$numCommitments}}]:
+    """
+    @notice Compute the public inputs of the commitments.
+    @dev Each commitment is hashed with the public inputs it commits to, as
+         keccak256(commitment || committed inputs) mod R.
+    @param commitments The commitments, points of G1 encoded as in EIP-196.
+    @param input The public inputs. These are elements of the scalar field Fr.
+    @return The public inputs of the commitments.
+    """
+    public_commitments: uint256[{{$numCommitments}}] = empty(uint256[{{$numCommitments}}])
+    {{- range $i, $committed := .PublicAndCommitmentCommitted }}
+    public_commitments[{{$i}}] = convert(keccak256(concat(
+        convert(commitments[{{mul $i 2}}], bytes32),
+        convert(commitments[{{add (mul $i 2) 1}}], bytes32),
+        {{- range $j := $committed }}
+        convert({{ if lt (sub $j 1) $numPublic }}input[{{sub $j 1}}]{{ else }}public_commitments[{{sub (sub $j 1) $numPublic}}]{{ end }}, bytes32),
+        {{- end }}
+    )), uint256) % R
+    {{- end }}
+    return public_commitments
+
+
+@internal
+@view
+def _verify_commitment_pok(
+    commitments: uint256[{{mul $numCommitments 2}}],
+    public_commitments: uint256[{{$numCommitments}}],
+    commitment_pok: uint256[2],
+):
+    """
+    @notice Verify the proof of knowledge of the commitments.
+    @dev Reverts with "commitment invalid" if the proof of knowledge is invalid
+         or if the points are not on the curve.
+    {{- if gt $numCommitments 1 }}
+         The commitments are folded with the powers of a challenge derived from
+         their public inputs, as in gnark-crypto's pedersen.FoldCommitments.
+    {{- end }}
+    @param commitments The commitments, points of G1 encoded as in EIP-196.
+    @param public_commitments The public inputs of the commitments.
+    @param commitment_pok The proof of knowledge, a point of G1 encoded as in EIP-196.
+    """
+    c: uint256[2] = [commitments[0], commitments[1]]
+    {{- if gt $numCommitments 1 }}
+    # ∑ᵢ rⁱ⋅Cᵢ where r = sha256("r" || public_commitments) mod R
+    r: uint256 = convert(sha256(concat(
+        b"r",
+        {{- range $i := intRange $numCommitments }}
+        convert(public_commitments[{{$i}}], bytes32),
+        {{- end }}
+    )), uint256) % R
+    ri: uint256 = 1
+    {{- range $i := intRange $numCommitments }}
+    {{- if gt $i 0 }}
+    ri = uint256_mulmod(ri, r, R)
+    c = ecadd(c, ecmul([commitments[{{mul $i 2}}], commitments[{{add (mul $i 2) 1}}]], ri))
+    {{- end }}
+    {{- end }}
+    {{- end }}
+
+    data: Bytes[384] = concat(
+        # e(C, G)
+        convert(c[0], bytes32),
+        convert(c[1], bytes32),
+        convert(PEDERSEN_G_X_1, bytes32),
+        convert(PEDERSEN_G_X_0, bytes32),
+        convert(PEDERSEN_G_Y_1, bytes32),
+        convert(PEDERSEN_G_Y_0, bytes32),
+        # e(PoK, G^{-1/σ})
+        convert(commitment_pok[0], bytes32),
+        convert(commitment_pok[1], bytes32),
+        convert(PEDERSEN_GROOTSIGMANEG_X_1, bytes32),
+        convert(PEDERSEN_GROOTSIGMANEG_X_0, bytes32),
+        convert(PEDERSEN_GROOTSIGMANEG_Y_1, bytes32),
+        convert(PEDERSEN_GROOTSIGMANEG_Y_0, bytes32),
+    )
+    success: bool = False
+    response: Bytes[32] = b""
+    success, response = raw_call(
+        PRECOMPILE_VERIFY,
+        data,
+        max_outsize=32,
+        is_static_call=True,
+        revert_on_failure=False,
+    )
+    assert success and len(response) == 32 and convert(response, uint256) == 1, "commitment invalid"
+{{- end }}
+
+
+@internal
+@view
+{{- if gt $numCommitments 0 }}
+def _public_input_msm(
+    input: uint256[{{$numPublic}}],
+    public_commitments: uint256[{{$numCommitments}}],
+    commitments: uint256[{{mul $numCommitments 2}}],
+) -> uint256[2]:
+{{- else }}
+def _public_input_msm(input: uint256[{{$numPublic}}]) -> uint256[2]:
+{{- end }}
+    """
+    @notice Compute the public input linear combination.
+    @dev Reverts with "public input not in field" if the input is not in the
+         field.
+    @param input The public inputs. These are elements of the scalar field Fr.
+    {{- if gt $numCommitments 0 }}
+    @param public_commitments The public inputs of the commitments.
+    @param commitments The commitments, points of G1 encoded as in EIP-196.
+    @return The linear combination CONSTANT + ∑ᵢ inputᵢ⋅PUBᵢ, the public inputs
+            of the commitments being appended to the inputs, plus the
+            commitments.
+    {{- else }}
+    @return The linear combination CONSTANT + ∑ᵢ inputᵢ⋅PUBᵢ.
+    {{- end }}
+    """
+    acc: uint256[2] = [CONSTANT_X, CONSTANT_Y]
+    {{- range $i := intRange $numPublic }}
+    assert input[{{$i}}] < R, "public input not in field"
+    acc = ecadd(acc, ecmul([PUB_{{$i}}_X, PUB_{{$i}}_Y], input[{{$i}}]))
+    {{- end }}
+    {{- range $i := intRange $numCommitments }}
+    acc = ecadd(acc, ecmul([PUB_{{add $numPublic $i}}_X, PUB_{{add $numPublic $i}}_Y], public_commitments[{{$i}}]))
+    {{- end }}
+    {{- range $i := intRange $numCommitments }}
+    acc = ecadd(acc, [commitments[{{mul $i 2}}], commitments[{{add (mul $i 2) 1}}]])
+    {{- end }}
+    return acc
+
+
+@external
+@view
+{{- if gt $numCommitments 0 }}
+def verifyProof(
+    proof: uint256[8],
+    commitments: uint256[{{mul $numCommitments 2}}],
+    commitment_pok: uint256[2],
+    input: uint256[{{$numPublic}}],
+):
+{{- else }}
+def verifyProof(proof: uint256[8], input: uint256[{{$numPublic}}]):
+{{- end }}
+    """
+    @notice Verify an uncompressed Groth16 proof.
+    @dev Reverts with "proof invalid" if the proof is invalid, and with "public
+         input not in field" if a public input is not reduced.
+    {{- if gt $numCommitments 0 }}
+         Reverts with "commitment invalid" if the proof of knowledge of the
+         commitments is invalid.
+    {{- end }}
+    @param proof the points (A, B, C) in EIP-197 format, as for verifyProof
+           in the Solidity contract.
+    {{- if gt $numCommitments 0 }}
+    @param commitments the commitments, points of G1 encoded as in EIP-196.
+    @param commitment_pok the proof of knowledge of the commitments, encoded
+           as in EIP-196.
+    {{- end }}
+    @param input the public input field elements in the scalar field Fr.
+           Elements must be reduced.
+    """
+    {{- if gt $numCommitments 0 }}
+    public_commitments: uint256[{{$numCommitments}}] = self._hash_commitments(commitments, input)
+    self._verify_commitment_pok(commitments, public_commitments, commitment_pok)
+    l: uint256[2] = self._public_input_msm(input, public_commitments, commitments)
+    {{- else }}
+    l: uint256[2] = self._public_input_msm(input)
+    {{- end }}
+
+    # Note: The precompile expects the F2 coefficients in big-endian order.
+    # Note: The pairing precompile rejects unreduced values, so we won't check that here.
+    data: Bytes[768] = concat(
+        # e(A, B)
+        convert(proof[0], bytes32),
+        convert(proof[1], bytes32),
+        convert(proof[2], bytes32),
+        convert(proof[3], bytes32),
+        convert(proof[4], bytes32),
+        convert(proof[5], bytes32),
+        # e(C, -δ)
+        convert(proof[6], bytes32),
+        convert(proof[7], bytes32),
+        convert(DELTA_NEG_X_1, bytes32),
+        convert(DELTA_NEG_X_0, bytes32),
+        convert(DELTA_NEG_Y_1, bytes32),
+        convert(DELTA_NEG_Y_0, bytes32),
+        # e(α, -β)
+        convert(ALPHA_X, bytes32),
+        convert(ALPHA_Y, bytes32),
+        convert(BETA_NEG_X_1, bytes32),
+        convert(BETA_NEG_X_0, bytes32),
+        convert(BETA_NEG_Y_1, bytes32),
+        convert(BETA_NEG_Y_0, bytes32),
+        # e(L_pub, -γ)
+        convert(l[0], bytes32),
+        convert(l[1], bytes32),
+        convert(GAMMA_NEG_X_1, bytes32),
+        convert(GAMMA_NEG_X_0, bytes32),
+        convert(GAMMA_NEG_Y_1, bytes32),
+        convert(GAMMA_NEG_Y_0, bytes32),
+    )
+
+    # Check pairing equation.
+    success: bool = False
+    response: Bytes[32] = b""
+    success, response = raw_call(
+        PRECOMPILE_VERIFY,
+        data,
+        max_outsize=32,
+        is_static_call=True,
+        revert_on_failure=False,
+    )
+    # Either proof or verification key invalid.
+    # We assume the contract is correctly generated, so the verification key is valid.
+    assert success and len(response) == 32 and convert(response, uint256) == 1, "proof invalid"
+`
@@ -20,10 +20,12 @@
 package groth16
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	cs_bls12377 "github.com/consensys/gnark/constraint/bls12-377"
@@ -88,7 +90,7 @@ type ProvingKey interface {
 //
 // it's underlying implementation is strongly typed with the curve (see gnark/internal/backend)
 //
-// ExportSolidity is implemented for BN254 and will return an error with other curves
+// ExportSolidity is implemented for BN254 and BLS12-381 and will return an error with other curves
 type VerifyingKey interface {
 	groth16Object
 	gnarkio.UnsafeReaderFrom
@@ -104,7 +106,11 @@ type VerifyingKey interface {
 
 	// ExportSolidity writes a solidity Verifier contract from the VerifyingKey
 	// this will return an error if not supported on the CurveID()
-	ExportSolidity(w io.Writer) error
+	ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error
+
+	// Export writes a verifier contract from the VerifyingKey in the target
+	// language, see backend.RegisterExporter.
+	Export(w io.Writer, target backend.Target) error
 
 	IsDifferent(interface{}) bool
 }
@@ -160,6 +166,80 @@ func Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness, opts ..
 	}
 }
 
+// BatchVerify verifies many proofs with the same VerifyingKey against the
+// matching public witnesses at the cost of a single multi-pairing. If a proof is
+// invalid then a [*backend.BatchVerifyError] with the index of the first invalid
+// proof is returned.
+func BatchVerify(proofs []Proof, vk VerifyingKey, publicWitnesses []witness.Witness, opts ...backend.VerifierOption) error {
+
+	switch _vk := vk.(type) {
+	case *groth16_bls12377.VerifyingKey:
+		p, w, err := batchVerifyInputs[*groth16_bls12377.Proof, fr_bls12377.Vector](proofs, publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bls12377.BatchVerify(p, _vk, w, opts...)
+	case *groth16_bls12381.VerifyingKey:
+		p, w, err := batchVerifyInputs[*groth16_bls12381.Proof, fr_bls12381.Vector](proofs, publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bls12381.BatchVerify(p, _vk, w, opts...)
+	case *groth16_bn254.VerifyingKey:
+		p, w, err := batchVerifyInputs[*groth16_bn254.Proof, fr_bn254.Vector](proofs, publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bn254.BatchVerify(p, _vk, w, opts...)
+	case *groth16_bw6761.VerifyingKey:
+		p, w, err := batchVerifyInputs[*groth16_bw6761.Proof, fr_bw6761.Vector](proofs, publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bw6761.BatchVerify(p, _vk, w, opts...)
+	case *groth16_bls24317.VerifyingKey:
+		p, w, err := batchVerifyInputs[*groth16_bls24317.Proof, fr_bls24317.Vector](proofs, publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bls24317.BatchVerify(p, _vk, w, opts...)
+	case *groth16_bls24315.VerifyingKey:
+		p, w, err := batchVerifyInputs[*groth16_bls24315.Proof, fr_bls24315.Vector](proofs, publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bls24315.BatchVerify(p, _vk, w, opts...)
+	case *groth16_bw6633.VerifyingKey:
+		p, w, err := batchVerifyInputs[*groth16_bw6633.Proof, fr_bw6633.Vector](proofs, publicWitnesses)
+		if err != nil {
+			return err
+		}
+		return groth16_bw6633.BatchVerify(p, _vk, w, opts...)
+	default:
+		panic("unrecognized R1CS curve type")
+	}
+}
+
+// batchVerifyInputs converts the proofs and public witnesses to the types of the
+// curve package.
+func batchVerifyInputs[P Proof, V any](proofs []Proof, publicWitnesses []witness.Witness) ([]P, []V, error) {
+	if len(proofs) != len(publicWitnesses) {
+		return nil, nil, fmt.Errorf("invalid number of public witnesses, got %d, expected %d", len(publicWitnesses), len(proofs))
+	}
+	p := make([]P, len(proofs))
+	w := make([]V, len(publicWitnesses))
+	for i := range proofs {
+		var ok bool
+		if p[i], ok = proofs[i].(P); !ok {
+			return nil, nil, &backend.BatchVerifyError{Index: i, Err: fmt.Errorf("unexpected proof type %T", proofs[i])}
+		}
+		if w[i], ok = publicWitnesses[i].Vector().(V); !ok {
+			return nil, nil, &backend.BatchVerifyError{Index: i, Err: witness.ErrInvalidWitness}
+		}
+	}
+	return p, w, nil
+}
+
 // Prove runs the groth16.Prove algorithm.
 //
 // if the force flag is set:
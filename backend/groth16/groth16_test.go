@@ -1,7 +1,10 @@
 package groth16_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"testing"
 
@@ -9,6 +12,7 @@ import (
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
@@ -51,6 +55,104 @@ func TestCustomHashToField(t *testing.T) {
 	}
 }
 
+func TestProveCanceled(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range getCurves() {
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &commitmentCircuit{})
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&commitmentCircuit{X: 1}, curve.ScalarField())
+			assert.NoError(err)
+			pk, _, err := groth16.Setup(ccs)
+			assert.NoError(err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = groth16.Prove(ccs, pk, fullWitness, backend.WithContext(ctx), backend.WithProverHashToFieldFunction(constantHash{}))
+			assert.Error(err)
+			assert.True(errors.Is(err, context.Canceled))
+			var errCanceled *backend.ProverCanceledError
+			assert.True(errors.As(err, &errCanceled))
+			assert.Equal(backend.PhaseSolve, errCanceled.Phase)
+
+			// the same inputs are still valid with a live context
+			_, err = groth16.Prove(ccs, pk, fullWitness, backend.WithContext(context.Background()), backend.WithProverHashToFieldFunction(constantHash{}))
+			assert.NoError(err)
+		}, curve.String())
+	}
+}
+
+func TestProveProgress(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range getCurves() {
+		curve := curve
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &commitmentCircuit{})
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&commitmentCircuit{X: 1}, curve.ScalarField())
+			assert.NoError(err)
+			pk, _, err := groth16.Setup(ccs)
+			assert.NoError(err)
+
+			var events []backend.ProgressEvent
+			handler := func(ev backend.ProgressEvent) {
+				events = append(events, ev)
+			}
+			_, err = groth16.Prove(ccs, pk, fullWitness, backend.WithProgressHandler(handler), backend.WithProverHashToFieldFunction(constantHash{}))
+			assert.NoError(err)
+
+			phases := make(map[backend.ProgressPhase]bool)
+			lastPercent := 0.0
+			for _, ev := range events {
+				phases[ev.Phase] = true
+				assert.True(ev.Step <= ev.NbSteps)
+				assert.True(ev.Percent >= lastPercent)
+				lastPercent = ev.Percent
+			}
+			for _, phase := range []backend.ProgressPhase{backend.PhaseSolve, backend.PhaseCommitments, backend.PhaseFFT, backend.PhaseMSM} {
+				assert.True(phases[phase], "missing phase %s", phase)
+			}
+			assert.InDelta(100, lastPercent, 1e-9)
+		}, curve.String())
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range getCurves() {
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), r1cs.NewBuilder, &batchCircuit{})
+			assert.NoError(err)
+			pk, vk, err := groth16.Setup(ccs)
+			assert.NoError(err)
+
+			const nbProofs = 4
+			proofs := make([]groth16.Proof, nbProofs)
+			publicWitnesses := make([]witness.Witness, nbProofs)
+			for i := range proofs {
+				fullWitness, err := frontend.NewWitness(&batchCircuit{X: i + 2, Y: (i + 2) * (i + 2)}, curve.ScalarField())
+				assert.NoError(err)
+				proofs[i], err = groth16.Prove(ccs, pk, fullWitness)
+				assert.NoError(err)
+				publicWitnesses[i], err = fullWitness.Public()
+				assert.NoError(err)
+			}
+			assert.NoError(groth16.BatchVerify(proofs, vk, publicWitnesses))
+
+			// swap the public witnesses of two proofs
+			publicWitnesses[1], publicWitnesses[2] = publicWitnesses[2], publicWitnesses[1]
+			err = groth16.BatchVerify(proofs, vk, publicWitnesses)
+			assert.Error(err)
+			var errBatch *backend.BatchVerifyError
+			assert.True(errors.As(err, &errBatch))
+			assert.Equal(1, errBatch.Index)
+
+			err = groth16.BatchVerify(proofs[:3], vk, publicWitnesses)
+			assert.Error(err)
+		}, curve.String())
+	}
+}
+
 //--------------------//
 //     benches		  //
 //--------------------//
@@ -155,6 +257,22 @@ func referenceCircuit(curve ecc.ID) (constraint.ConstraintSystem, frontend.Circu
 	return r1cs, &good
 }
 
+func TestSolidityBLS12381Commitments(t *testing.T) {
+	assert := test.NewAssert(t)
+	ccs, err := frontend.Compile(ecc.BLS12_381.ScalarField(), r1cs.NewBuilder, &batchCircuit{})
+	assert.NoError(err)
+	_, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	assert.NoError(vk.ExportSolidity(io.Discard))
+
+	// with the solccheck build tag, the proof is verified by the exported
+	// contract in an EVM implementing EIP-2537.
+	assert.CheckCircuit(&batchCircuit{},
+		test.WithValidAssignment(&batchCircuit{X: 3, Y: 9}),
+		test.WithCurves(ecc.BLS12_381),
+		test.WithBackends(backend.GROTH16))
+}
+
 type commitmentCircuit struct {
 	X frontend.Variable
 }
@@ -168,6 +286,21 @@ func (c *commitmentCircuit) Define(api frontend.API) error {
 	return nil
 }
 
+type batchCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *batchCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	cmt, err := api.(frontend.Committer).Commit(c.X, c.Y)
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	api.AssertIsDifferent(cmt, 0)
+	return nil
+}
+
 type constantHash struct{}
 
 func (h constantHash) Write(p []byte) (n int, err error) { return len(p), nil }
@@ -0,0 +1,65 @@
+// Copyright 2020 ConsenSys AG
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nova implements the folding scheme of Nova for incremental
+// computations over R1CS.
+//
+// A step circuit is compiled with r1cs.NewBuilder and its instances are
+// committed with Pedersen vector commitments. The folding prover folds a
+// committed instance into a committed relaxed R1CS instance
+//
+//	Az∘Bz = u·Cz + E
+//
+// with a single commitment to a cross term, and the folding verifier only
+// performs a constant number of group operations. The folded instance is
+// satisfiable only if all the folded instances are, which is checked once at
+// the end by the decider (IsSatisfied). The commitment key is transparent and
+// the challenges are derived with Fiat-Shamir.
+//
+// The folding is not zero-knowledge on its own: the relaxed witness reveals the
+// folded solutions. To compress and hide the computation, the in-circuit folding
+// verifier and decider of [github.com/consensys/gnark/std/recursion/nova] can be
+// proven with Groth16.
+//
+// The implementations are curve specific, in the sub-packages of this package.
+// A step circuit may use a single commitment (see [frontend.Committer]) which
+// doesn't commit to public inputs, as the emulated arithmetic does: the
+// committed wires are committed separately and the commitment wire is derived
+// from their commitment, as in Groth16, before folding.
+//
+// # Incrementally verifiable computation
+//
+// The sub-packages also implement the native prover and verifier of an
+// incrementally verifiable computation (IVC) zᵢ₊₁ = F(zᵢ). The step circuit is
+// the augmented circuit of [github.com/consensys/gnark/std/recursion/nova],
+// which applies F and folds the instance of the previous step into a running
+// instance in emulated arithmetic. FoldIVC and ProveIVC prove one more step,
+// and VerifyIVC checks a proof whose size and verification cost don't depend on
+// the number of steps. The proof holds the folded witnesses, so it is neither
+// succinct nor zero-knowledge.
+//
+// To compress it, the commitment key is derived from a KZG SRS with SetupKZG.
+// FoldDecider folds the last instance into the running instance and opens the
+// commitments of the folded instance, and the decider circuit of
+// [github.com/consensys/gnark/std/recursion/nova] checks the folding and the
+// satisfiability of the folded instance natively, as well as the state. The
+// decider circuit is proven with Groth16, and VerifyDecider checks the folding
+// of the commitments and their opening.
+//
+// # See also
+//
+// https://eprint.iacr.org/2021/370
+//
+// [frontend.Committer]: https://pkg.go.dev/github.com/consensys/gnark/frontend#Committer
+package nova
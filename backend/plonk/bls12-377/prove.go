@@ -40,7 +40,6 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/iop"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-377/kzg"
-	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/witness"
 
@@ -74,6 +73,21 @@ const (
 	id_ID
 	id_LOne
 	id_Qci // [ .. , Qc_i, Pi_i, ...]
+	// followed by the selectors of the custom gates, the lookup polynomials
+	// (Qlk, Qlkid, Tval, Tid, M, S) if the circuit has fixed tables, L, R, O
+	// shifted by one row if a custom gate spans two rows, and S shifted by one
+	// row. See instance.idQg.
+)
+
+// offsets of the lookup polynomials from instance.idLk
+const (
+	id_Qlk int = iota
+	id_Qlkid
+	id_Tval
+	id_Tid
+	id_M
+	id_S
+	nb_lookup_polynomials
 )
 
 // blinding factors
@@ -82,6 +96,8 @@ const (
 	id_Br
 	id_Bo
 	id_Bz
+	id_Bm
+	id_Bs
 	nb_blinding_polynomials
 )
 
@@ -91,6 +107,8 @@ const (
 	order_blinding_R = 1
 	order_blinding_O = 1
 	order_blinding_Z = 2
+	order_blinding_M = 1
+	order_blinding_S = 2
 )
 
 type Proof struct {
@@ -111,6 +129,16 @@ type Proof struct {
 
 	// Opening proof of Z at zeta*mu
 	ZShiftedOpening kzg.OpeningProof
+
+	// Batch opening proof of l, r, o at zeta*mu, only set if a custom gate
+	// spans two rows
+	LROShiftedOpening kzg.BatchOpeningProof
+
+	// Commitments to the multiplicities of the entries of the fixed tables and
+	// to S, the running sum of the lookup argument, and opening proof of S at
+	// zeta*mu; only set if the circuit has fixed tables
+	Multiplicities, LookupSum kzg.Digest
+	LookupSumShiftedOpening   kzg.OpeningProof
 }
 
 func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (*Proof, error) {
@@ -129,7 +157,7 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts
 	start := time.Now()
 
 	// init instance
-	g, ctx := errgroup.WithContext(context.Background())
+	g, ctx := errgroup.WithContext(opt.Ctx)
 	instance, err := newInstance(ctx, spr, pk, fullWitness, &opt)
 	if err != nil {
 		return nil, fmt.Errorf("new instance: %w", err)
@@ -150,10 +178,13 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts
 	// compute accumulating ratio for the copy constraint
 	g.Go(instance.buildRatioCopyConstraint)
 
+	// compute the running sum of the lookup argument
+	g.Go(instance.buildLookupSum)
+
 	// compute h
 	g.Go(instance.evaluateConstraints)
 
-	// open Z (blinded) at ωζ (proof.ZShiftedOpening)
+	// open Z and S (blinded) at ωζ (proof.ZShiftedOpening, proof.LookupSumShiftedOpening)
 	g.Go(instance.openZ)
 
 	// fold the commitment to H ([H₀] + ζᵐ⁺²*[H₁] + ζ²⁽ᵐ⁺²⁾[H₂])
@@ -166,6 +197,14 @@ func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts
 	g.Go(instance.batchOpening)
 
 	if err := g.Wait(); err != nil {
+		var errCanceled *backend.ProverCanceledError
+		if errors.As(err, &errCanceled) {
+			return nil, err
+		}
+		// the error may come from a step waiting on a canceled context
+		if errCtx := opt.CheckContext(backend.PhaseProve); errCtx != nil {
+			return nil, errCtx
+		}
 		return nil, err
 	}
 
@@ -182,7 +221,9 @@ type instance struct {
 	spr   *cs.SparseR1CS
 	opt   *backend.ProverConfig
 
-	fs             *fiatshamir.Transcript
+	progress *backend.ProgressReporter
+
+	fs             backend.Transcript
 	kzgFoldingHash hash.Hash // for KZG folding
 	htfFunc        hash.Hash // hash to field function
 
@@ -191,6 +232,7 @@ type instance struct {
 	bp       []*iop.Polynomial // blinding polynomials
 	h        *iop.Polynomial   // h is the quotient polynomial
 	blindedZ []fr.Element      // blindedZ is the blinded version of Z
+	blindedS []fr.Element      // blindedS is the blinded version of S
 
 	foldedH       []fr.Element // foldedH is the folded version of H
 	foldedHDigest kzg.Digest   // foldedHDigest is the kzg commitment of foldedH
@@ -208,6 +250,20 @@ type instance struct {
 	// challenges
 	gamma, beta, alpha, zeta fr.Element
 
+	// custom gates: x[idQg+i] is the selector of the i-th gate, and if a gate
+	// spans two rows, x[idLROShifted:idLROShifted+3] are L, R, O shifted by one row
+	idQg, idLROShifted int
+	twoRows            bool
+	customGates        [][]customGateTerm
+	lroShiftedZeta     []fr.Element // l(ωζ), r(ωζ), o(ωζ) if a gate spans two rows
+
+	// lookups: x[idLk+id_Qlk:idLk+nb_lookup_polynomials] are the lookup
+	// polynomials and x[idSS] is S shifted by one row, if hasLookups.
+	// The identity of the lookup argument is scaled by alphaLookup.
+	idLk, idSS  int
+	hasLookups  bool
+	alphaLookup fr.Element
+
 	// channel to wait for the steps
 	chLRO,
 	chQk,
@@ -218,6 +274,7 @@ type instance struct {
 	chZOpening,
 	chLinearizedPolynomial,
 	chFoldedH,
+	chLookupSum,
 	chGammaBeta chan struct{}
 
 	domain0, domain1 *fft.Domain
@@ -230,14 +287,20 @@ func newInstance(ctx context.Context, spr *cs.SparseR1CS, pk *ProvingKey, fullWi
 		opts.HashToFieldFn = hash_to_field.New([]byte("BSB22-Plonk"))
 	}
 	s := instance{
-		ctx:                    ctx,
-		pk:                     pk,
-		proof:                  &Proof{},
-		spr:                    spr,
-		opt:                    opts,
+		ctx:   ctx,
+		pk:    pk,
+		proof: &Proof{},
+		spr:   spr,
+		opt:   opts,
+		// the weights are rough estimates of the relative costs of the phases
+		progress: opts.NewProgressReporter(
+			backend.PhaseWeight{Phase: backend.PhaseSolve, Weight: 2},
+			backend.PhaseWeight{Phase: backend.PhaseCommitments, Weight: 2},
+			backend.PhaseWeight{Phase: backend.PhaseQuotient, Weight: 4},
+			backend.PhaseWeight{Phase: backend.PhaseOpening, Weight: 2},
+		),
 		fullWitness:            fullWitness,
 		bp:                     make([]*iop.Polynomial, nb_blinding_polynomials),
-		fs:                     fiatshamir.NewTranscript(opts.ChallengeHash, "gamma", "beta", "alpha", "zeta"),
 		kzgFoldingHash:         opts.KZGFoldingHash,
 		htfFunc:                opts.HashToFieldFn,
 		chLRO:                  make(chan struct{}, 1),
@@ -249,10 +312,32 @@ func newInstance(ctx context.Context, spr *cs.SparseR1CS, pk *ProvingKey, fullWi
 		chZOpening:             make(chan struct{}, 1),
 		chLinearizedPolynomial: make(chan struct{}, 1),
 		chFoldedH:              make(chan struct{}, 1),
+		chLookupSum:            make(chan struct{}, 1),
 		chRestoreLRO:           make(chan struct{}, 1),
 	}
+	fs, err := opts.NewTranscript("gamma", "beta", "alpha", "zeta")
+	if err != nil {
+		return nil, fmt.Errorf("new transcript: %w", err)
+	}
+	s.fs = fs
 	s.initBSB22Commitments()
-	s.x = make([]*iop.Polynomial, id_Qci+2*len(s.commitmentInfo))
+	s.twoRows = pk.Vk.customGatesSpanTwoRows()
+	s.hasLookups = pk.Vk.hasLookups()
+	s.idQg = id_Qci + 2*len(s.commitmentInfo)
+	s.idLk = s.idQg + len(pk.Vk.CustomGates)
+	s.idLROShifted = s.idLk
+	if s.hasLookups {
+		s.idLROShifted += nb_lookup_polynomials
+	}
+	s.idSS = s.idLROShifted
+	if s.twoRows {
+		s.idSS += 3
+	}
+	if s.hasLookups {
+		s.x = make([]*iop.Polynomial, s.idSS+1)
+	} else {
+		s.x = make([]*iop.Polynomial, s.idSS)
+	}
 
 	// init fft domains
 	nbConstraints := spr.GetNbConstraints()
@@ -281,6 +366,8 @@ func (s *instance) initBlindingPolynomials() error {
 	s.bp[id_Br] = getRandomPolynomial(order_blinding_R)
 	s.bp[id_Bo] = getRandomPolynomial(order_blinding_O)
 	s.bp[id_Bz] = getRandomPolynomial(order_blinding_Z)
+	s.bp[id_Bm] = getRandomPolynomial(order_blinding_M)
+	s.bp[id_Bs] = getRandomPolynomial(order_blinding_S)
 	close(s.chbp)
 	return nil
 }
@@ -293,7 +380,7 @@ func (s *instance) initBSB22Commitments() {
 
 	// override the hint for the commitment constraints
 	bsb22ID := solver.GetHintID(fcs.Bsb22CommitmentComputePlaceholder)
-	s.opt.SolverOpts = append(s.opt.SolverOpts, solver.OverrideHint(bsb22ID, s.bsb22Hint))
+	s.opt.SolverOpts = append(s.opt.SolverOpts, solver.OverrideHint(bsb22ID, s.bsb22Hint), solver.WithContext(s.ctx))
 }
 
 // Computing and verifying Bsb22 multi-commits explained in https://hackmd.io/x8KsadW3RRyX7YTCFJIkHg
@@ -337,10 +424,15 @@ func (s *instance) bsb22Hint(_ *big.Int, ins, outs []*big.Int) error {
 // solveConstraints computes the evaluation of the polynomials L, R, O
 // and sets x[id_L], x[id_R], x[id_O] in canonical form
 func (s *instance) solveConstraints() error {
+	s.progress.Start(backend.PhaseSolve, 1)
 	_solution, err := s.spr.Solve(s.fullWitness, s.opt.SolverOpts...)
 	if err != nil {
+		if errCtx := s.opt.CheckContext(backend.PhaseSolve); errCtx != nil {
+			return errCtx
+		}
 		return err
 	}
+	s.progress.Step(backend.PhaseSolve)
 	solution := _solution.(*cs.SparseR1CSSolution)
 	evaluationLDomainSmall := []fr.Element(solution.L)
 	evaluationRDomainSmall := []fr.Element(solution.R)
@@ -360,6 +452,14 @@ func (s *instance) solveConstraints() error {
 
 	wg.Wait()
 
+	if s.hasLookups {
+		m, err := s.computeMultiplicities(evaluationLDomainSmall)
+		if err != nil {
+			return err
+		}
+		s.x[s.idLk+id_M] = iop.NewPolynomial(&m, iop.Form{Basis: iop.Lagrange, Layout: iop.Regular})
+	}
+
 	// commit to l, r, o and add blinding factors
 	if err := s.commitToLRO(); err != nil {
 		return err
@@ -403,27 +503,76 @@ func (s *instance) commitToLRO() error {
 		return errContextDone
 	case <-s.chbp:
 	}
+	if err := s.opt.CheckContext(backend.PhaseCommitments); err != nil {
+		return err
+	}
+
+	// the commitments to L, R, O and Z
+	s.progress.Start(backend.PhaseCommitments, 4)
 
 	g := new(errgroup.Group)
 
 	g.Go(func() (err error) {
 		s.proof.LRO[0], err = s.commitToPolyAndBlinding(s.x[id_L], s.bp[id_Bl])
+		s.progress.Step(backend.PhaseCommitments)
 		return
 	})
 
 	g.Go(func() (err error) {
 		s.proof.LRO[1], err = s.commitToPolyAndBlinding(s.x[id_R], s.bp[id_Br])
+		s.progress.Step(backend.PhaseCommitments)
 		return
 	})
 
 	g.Go(func() (err error) {
 		s.proof.LRO[2], err = s.commitToPolyAndBlinding(s.x[id_O], s.bp[id_Bo])
+		s.progress.Step(backend.PhaseCommitments)
 		return
 	})
 
+	if s.hasLookups {
+		g.Go(func() (err error) {
+			s.proof.Multiplicities, err = s.commitToPolyAndBlinding(s.x[s.idLk+id_M], s.bp[id_Bm])
+			return
+		})
+	}
+
 	return g.Wait()
 }
 
+// computeMultiplicities returns the Lagrange form of M: M[k] is the number of
+// lookups of the k-th entry of the concatenated fixed tables. If an entry
+// appears several times in a table, only its first occurrence is counted.
+func (s *instance) computeMultiplicities(l []fr.Element) ([]fr.Element, error) {
+	type entry struct {
+		table uint64
+		value fr.Element
+	}
+	tval := s.trace.Tval.Coefficients()
+	tid := s.trace.Tid.Coefficients()
+	rows := make(map[entry]int, fixedTablesSize(s.spr))
+	for k := fixedTablesSize(s.spr) - 1; k >= 0; k-- {
+		rows[entry{tid[k].Uint64(), tval[k]}] = k
+	}
+
+	qlk := s.trace.Qlk.Coefficients()
+	qlkid := s.trace.Qlkid.Coefficients()
+	m := make([]fr.Element, len(l))
+	var one fr.Element
+	one.SetOne()
+	for i := range qlk {
+		if qlk[i].IsZero() {
+			continue
+		}
+		k, ok := rows[entry{qlkid[i].Uint64(), l[i]}]
+		if !ok {
+			return nil, fmt.Errorf("lookup: %s is not an entry of table %d", l[i].String(), qlkid[i].Uint64())
+		}
+		m[k].Add(&m[k], &one)
+	}
+	return m, nil
+}
+
 // deriveGammaAndBeta (copy constraint)
 func (s *instance) deriveGammaAndBeta() error {
 	wWitness, ok := s.fullWitness.Vector().(fr.Vector)
@@ -442,7 +591,11 @@ func (s *instance) deriveGammaAndBeta() error {
 	case <-s.chLRO:
 	}
 
-	gamma, err := deriveRandomness(s.fs, "gamma", &s.proof.LRO[0], &s.proof.LRO[1], &s.proof.LRO[2])
+	gammaDeps := []*curve.G1Affine{&s.proof.LRO[0], &s.proof.LRO[1], &s.proof.LRO[2]}
+	if s.hasLookups {
+		gammaDeps = append(gammaDeps, &s.proof.Multiplicities)
+	}
+	gamma, err := deriveRandomness(s.fs, "gamma", gammaDeps...)
 	if err != nil {
 		return err
 	}
@@ -481,6 +634,9 @@ func (s *instance) deriveAlpha() (err error) {
 		alphaDeps[i] = &s.proof.Bsb22Commitments[i]
 	}
 	alphaDeps[len(alphaDeps)-1] = &s.proof.Z
+	if s.hasLookups {
+		alphaDeps = append(alphaDeps, &s.proof.LookupSum)
+	}
 	s.alpha, err = deriveRandomness(s.fs, "alpha", alphaDeps...)
 	return err
 }
@@ -503,6 +659,15 @@ func (s *instance) evaluateConstraints() (err error) {
 	for i := 0; i < len(s.commitmentInfo); i++ {
 		s.x[id_Qci+2*i] = s.trace.Qcp[i]
 	}
+	for i := range s.trace.Qg {
+		s.x[s.idQg+i] = s.trace.Qg[i]
+	}
+	if s.hasLookups {
+		s.x[s.idLk+id_Qlk] = s.trace.Qlk
+		s.x[s.idLk+id_Qlkid] = s.trace.Qlkid
+		s.x[s.idLk+id_Tval] = s.trace.Tval
+		s.x[s.idLk+id_Tid] = s.trace.Tid
+	}
 
 	n := s.domain0.Cardinality
 	lone := make([]fr.Element, n)
@@ -526,10 +691,19 @@ func (s *instance) evaluateConstraints() (err error) {
 	case <-s.chZ:
 	}
 
+	// wait for S to be committed or context done
+	select {
+	case <-s.ctx.Done():
+		return errContextDone
+	case <-s.chLookupSum:
+	}
+
 	// derive alpha
 	if err = s.deriveAlpha(); err != nil {
 		return err
 	}
+	s.customGates = compileCustomGates(s.pk.Vk.CustomGates, s.alpha)
+	s.alphaLookup = lookupAlpha(s.pk.Vk.CustomGates, s.alpha)
 
 	// TODO complete waste of memory find another way to do that
 	identity := make([]fr.Element, n)
@@ -538,6 +712,14 @@ func (s *instance) evaluateConstraints() (err error) {
 	s.x[id_ID] = iop.NewPolynomial(&identity, iop.Form{Basis: iop.Canonical, Layout: iop.Regular})
 	s.x[id_LOne] = iop.NewPolynomial(&lone, iop.Form{Basis: iop.Lagrange, Layout: iop.Regular})
 	s.x[id_ZS] = s.x[id_Z].ShallowClone().Shift(1)
+	if s.twoRows {
+		for i := 0; i < 3; i++ {
+			s.x[s.idLROShifted+i] = s.x[id_L+i].ShallowClone().Shift(1)
+		}
+	}
+	if s.hasLookups {
+		s.x[s.idSS] = s.x[s.idLk+id_S].ShallowClone().Shift(1)
+	}
 
 	numerator, err := s.computeNumerator()
 	if err != nil {
@@ -553,6 +735,7 @@ func (s *instance) evaluateConstraints() (err error) {
 	if err := commitToQuotient(s.h1(), s.h2(), s.h3(), s.proof, s.pk.Kzg); err != nil {
 		return err
 	}
+	s.progress.Step(backend.PhaseQuotient)
 
 	if err := s.deriveZeta(); err != nil {
 		return err
@@ -565,6 +748,9 @@ func (s *instance) evaluateConstraints() (err error) {
 	case <-s.chRestoreLRO:
 	}
 
+	// the opening of Z, the commitment to the linearized polynomial and the
+	// batch opening
+	s.progress.Start(backend.PhaseOpening, 3)
 	close(s.chH)
 
 	return nil
@@ -598,13 +784,77 @@ func (s *instance) buildRatioCopyConstraint() (err error) {
 
 	// commit to the blinded version of z
 	s.proof.Z, err = s.commitToPolyAndBlinding(s.x[id_Z], s.bp[id_Bz])
+	s.progress.Step(backend.PhaseCommitments)
 
 	close(s.chZ)
 
 	return
 }
 
-// open Z (blinded) at ωζ
+// buildLookupSum computes S, the running sum of the lookup argument:
+// S(1) = 0 and S(ωⁱ⁺¹) = S(ωⁱ) + Qlk(ωⁱ)/(β-f(ωⁱ)) - M(ωⁱ)/(β-t(ωⁱ)), where
+// f = L + γ*Qlkid and t = Tval + γ*Tid. S is cyclic if and only if every
+// lookup is an entry of its table.
+func (s *instance) buildLookupSum() (err error) {
+	if !s.hasLookups {
+		close(s.chLookupSum)
+		return nil
+	}
+
+	// wait for gamma and beta to be derived (or ctx.Done())
+	select {
+	case <-s.ctx.Done():
+		return errContextDone
+	case <-s.chGammaBeta:
+	}
+
+	n := int(s.domain0.Cardinality)
+	l := s.x[id_L].Coefficients()
+	m := s.x[s.idLk+id_M].Coefficients()
+	qlk := s.trace.Qlk.Coefficients()
+	qlkid := s.trace.Qlkid.Coefficients()
+	tval := s.trace.Tval.Coefficients()
+	tid := s.trace.Tid.Coefficients()
+
+	// den[i] = β-f(ωⁱ), den[n+i] = β-t(ωⁱ)
+	den := make([]fr.Element, 2*n)
+	utils.Parallelize(n, func(start, end int) {
+		var tmp fr.Element
+		for i := start; i < end; i++ {
+			tmp.Mul(&qlkid[i], &s.gamma).Add(&tmp, &l[i])
+			den[i].Sub(&s.beta, &tmp)
+			tmp.Mul(&tid[i], &s.gamma).Add(&tmp, &tval[i])
+			den[n+i].Sub(&s.beta, &tmp)
+		}
+	})
+	den = fr.BatchInvert(den)
+
+	sum := make([]fr.Element, n)
+	var acc, tmp fr.Element
+	for i := 0; i < n; i++ {
+		sum[i] = acc
+		tmp.Mul(&qlk[i], &den[i])
+		acc.Add(&acc, &tmp)
+		tmp.Mul(&m[i], &den[n+i])
+		acc.Sub(&acc, &tmp)
+	}
+	if !acc.IsZero() {
+		return errors.New("lookup: the multiplicities don't match the lookups")
+	}
+	s.x[s.idLk+id_S] = iop.NewPolynomial(&sum, iop.Form{Basis: iop.Lagrange, Layout: iop.Regular})
+
+	// commit to the blinded version of S
+	s.proof.LookupSum, err = s.commitToPolyAndBlinding(s.x[s.idLk+id_S], s.bp[id_Bs])
+	if err != nil {
+		return err
+	}
+
+	close(s.chLookupSum)
+
+	return nil
+}
+
+// open Z and S (blinded) at ωζ
 func (s *instance) openZ() (err error) {
 	// wait for H to be committed and zeta to be derived (or ctx.Done())
 	select {
@@ -620,6 +870,14 @@ func (s *instance) openZ() (err error) {
 	if err != nil {
 		return err
 	}
+	if s.hasLookups {
+		s.blindedS = getBlindedCoefficients(s.x[s.idLk+id_S], s.bp[id_Bs])
+		s.proof.LookupSumShiftedOpening, err = kzg.Open(s.blindedS, zetaShifted, s.pk.Kzg)
+		if err != nil {
+			return err
+		}
+	}
+	s.progress.Step(backend.PhaseOpening)
 	close(s.chZOpening)
 	return nil
 }
@@ -723,6 +981,39 @@ func (s *instance) computeLinearizedPolynomial() error {
 
 	wg.Wait()
 
+	// evaluate the custom gates at ζ, reading the next row at ωζ
+	wires := [6]fr.Element{blzeta, brzeta, bozeta}
+	if s.twoRows {
+		var zetaShifted fr.Element
+		zetaShifted.Mul(&s.zeta, &s.pk.Vk.Generator)
+		s.lroShiftedZeta = []fr.Element{
+			evaluateBlinded(s.x[id_L], s.bp[id_Bl], zetaShifted),
+			evaluateBlinded(s.x[id_R], s.bp[id_Br], zetaShifted),
+			evaluateBlinded(s.x[id_O], s.bp[id_Bo], zetaShifted),
+		}
+		copy(wires[3:], s.lroShiftedZeta)
+	}
+	customGatesZeta := make([]fr.Element, len(s.customGates))
+	for i := range s.customGates {
+		customGatesZeta[i] = evaluateCustomGate(s.customGates[i], &wires)
+	}
+
+	// the lookup argument contributes α_lk*(β-f(ζ))*((β-t(ζ))*S(X) - M(X))
+	var lookupS, lookupM fr.Element
+	var blindedM []fr.Element
+	if s.hasLookups {
+		var ft, tt fr.Element
+		ft = s.trace.Qlkid.Evaluate(s.zeta)
+		ft.Mul(&ft, &s.gamma).Add(&ft, &blzeta).Sub(&s.beta, &ft) // β-f(ζ)
+		tt = s.trace.Tid.Evaluate(s.zeta)
+		tmp := s.trace.Tval.Evaluate(s.zeta)
+		tt.Mul(&tt, &s.gamma).Add(&tt, &tmp).Sub(&s.beta, &tt) // β-t(ζ)
+		lookupM.Mul(&ft, &s.alphaLookup)
+		lookupS.Mul(&lookupM, &tt)
+		lookupM.Neg(&lookupM)
+		blindedM = getBlindedCoefficients(s.x[s.idLk+id_M], s.bp[id_Bm])
+	}
+
 	s.linearizedPolynomial = s.innerComputeLinearizedPoly(
 		blzeta,
 		brzeta,
@@ -733,8 +1024,13 @@ func (s *instance) computeLinearizedPolynomial() error {
 		s.zeta,
 		bzuzeta,
 		qcpzeta,
+		customGatesZeta,
 		s.blindedZ,
 		coefficients(s.cCommitments),
+		lookupS,
+		lookupM,
+		s.blindedS,
+		blindedM,
 		s.pk,
 	)
 
@@ -743,6 +1039,7 @@ func (s *instance) computeLinearizedPolynomial() error {
 	if err != nil {
 		return err
 	}
+	s.progress.Step(backend.PhaseOpening)
 	close(s.chLinearizedPolynomial)
 	return nil
 }
@@ -773,6 +1070,14 @@ func (s *instance) batchOpening() error {
 	polysQcp := coefficients(s.trace.Qcp)
 	polysToOpen := make([][]fr.Element, 7+len(polysQcp))
 	copy(polysToOpen[7:], polysQcp)
+	if s.hasLookups {
+		polysToOpen = append(polysToOpen,
+			s.trace.Qlk.Coefficients(),
+			s.trace.Qlkid.Coefficients(),
+			s.trace.Tval.Coefficients(),
+			s.trace.Tid.Coefficients(),
+		)
+	}
 
 	polysToOpen[0] = s.foldedH
 	polysToOpen[1] = s.linearizedPolynomial
@@ -792,18 +1097,45 @@ func (s *instance) batchOpening() error {
 	digestsToOpen[4] = s.proof.LRO[2]
 	digestsToOpen[5] = s.pk.Vk.S[0]
 	digestsToOpen[6] = s.pk.Vk.S[1]
+	if s.hasLookups {
+		digestsToOpen = append(digestsToOpen, s.pk.Vk.Qlk, s.pk.Vk.Qlkid, s.pk.Vk.Tval, s.pk.Vk.Tid)
+	}
+
+	if err := s.opt.CheckContext(backend.PhaseOpening); err != nil {
+		return err
+	}
 
 	var err error
+	if s.twoRows {
+		// open l, r, o at ωζ for the custom gates spanning two rows
+		var zetaShifted fr.Element
+		zetaShifted.Mul(&s.zeta, &s.pk.Vk.Generator)
+		s.proof.LROShiftedOpening, err = kzg.BatchOpenSinglePoint(
+			polysToOpen[2:5],
+			digestsToOpen[2:5],
+			zetaShifted,
+			s.kzgFoldingHash,
+			s.pk.Kzg,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	s.proof.BatchedProof, err = kzg.BatchOpenSinglePoint(
 		polysToOpen,
 		digestsToOpen,
 		s.zeta,
 		s.kzgFoldingHash,
 		s.pk.Kzg,
-		s.proof.ZShiftedOpening.ClaimedValue.Marshal(),
+		shiftedClaimedValues(s.proof, s.pk.Vk)...,
 	)
+	if err != nil {
+		return err
+	}
+	s.progress.Step(backend.PhaseOpening)
 
-	return err
+	return nil
 }
 
 // evaluate the full set of constraints, all polynomials in x are back in
@@ -834,7 +1166,8 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 	case <-s.chQk:
 	}
 
-	nbBsbGates := (len(s.x) - id_Qci + 1) >> 1
+	nbBsbGates := len(s.commitmentInfo)
+	twoRows := s.twoRows
 
 	gateConstraint := func(u ...fr.Element) fr.Element {
 
@@ -852,6 +1185,19 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 			ic.Add(&ic, &tmp)
 		}
 
+		// custom gates, the identities are already scaled by powers of α
+		if len(s.customGates) != 0 {
+			wires := [6]fr.Element{u[id_L], u[id_R], u[id_O]}
+			if twoRows {
+				copy(wires[3:], u[s.idLROShifted:s.idLROShifted+3])
+			}
+			for i := range s.customGates {
+				tmp = evaluateCustomGate(s.customGates[i], &wires)
+				tmp.Mul(&tmp, &u[s.idQg+i])
+				ic.Add(&ic, &tmp)
+			}
+		}
+
 		return ic
 	}
 
@@ -879,6 +1225,22 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 		return l
 	}
 
+	// Qlk*(β-t) - M*(β-f) - (S(ωX)-S(X))*(β-f)*(β-t)
+	lookupConstraint := func(u ...fr.Element) fr.Element {
+		var f, t, res, tmp fr.Element
+
+		f.Mul(&u[s.idLk+id_Qlkid], &s.gamma).Add(&f, &u[id_L]).Sub(&s.beta, &f)
+		t.Mul(&u[s.idLk+id_Tid], &s.gamma).Add(&t, &u[s.idLk+id_Tval]).Sub(&s.beta, &t)
+
+		res.Sub(&u[s.idSS], &u[s.idLk+id_S]).Mul(&res, &f).Mul(&res, &t)
+		tmp.Mul(&u[s.idLk+id_M], &f)
+		res.Add(&res, &tmp)
+		tmp.Mul(&u[s.idLk+id_Qlk], &t)
+		res.Sub(&tmp, &res)
+
+		return res
+	}
+
 	ratioLocalConstraint := func(u ...fr.Element) fr.Element {
 
 		var res fr.Element
@@ -934,10 +1296,35 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 		y = s.bp[id_Bz].Evaluate(twiddles0[(i+1)%int(n)])
 		u[id_ZS].Add(&u[id_ZS], &y)
 
+		// same for the shifted L, R, O
+		if twoRows {
+			y = s.bp[id_Bl].Evaluate(twiddles0[(i+1)%int(n)])
+			u[s.idLROShifted].Add(&u[s.idLROShifted], &y)
+			y = s.bp[id_Br].Evaluate(twiddles0[(i+1)%int(n)])
+			u[s.idLROShifted+1].Add(&u[s.idLROShifted+1], &y)
+			y = s.bp[id_Bo].Evaluate(twiddles0[(i+1)%int(n)])
+			u[s.idLROShifted+2].Add(&u[s.idLROShifted+2], &y)
+		}
+
+		// same for M, S and the shifted S
+		if s.hasLookups {
+			y = s.bp[id_Bm].Evaluate(twiddles0[i])
+			u[s.idLk+id_M].Add(&u[s.idLk+id_M], &y)
+			y = s.bp[id_Bs].Evaluate(twiddles0[i])
+			u[s.idLk+id_S].Add(&u[s.idLk+id_S], &y)
+			y = s.bp[id_Bs].Evaluate(twiddles0[(i+1)%int(n)])
+			u[s.idSS].Add(&u[s.idSS], &y)
+		}
+
 		a := gateConstraint(u...)
 		b := orderingConstraint(u...)
 		c := ratioLocalConstraint(u...)
 		c.Mul(&c, &s.alpha).Add(&c, &b).Mul(&c, &s.alpha).Add(&c, &a)
+		if s.hasLookups {
+			d := lookupConstraint(u...)
+			d.Mul(&d, &s.alphaLookup)
+			c.Add(&c, &d)
+		}
 		return c
 	}
 
@@ -952,7 +1339,13 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 	m := uint64(s.domain1.Cardinality)
 	mm := uint64(64 - bits.TrailingZeros64(m))
 
+	// the cosets and the commitment to the quotient
+	s.progress.Start(backend.PhaseQuotient, rho+1)
 	for i := 0; i < rho; i++ {
+		// the cosets are the safe points of the quotient computation
+		if err := s.opt.CheckContext(backend.PhaseQuotient); err != nil {
+			return nil, err
+		}
 
 		coset.Mul(&coset, &shifters[i])
 		tmp.Exp(coset, bn).Sub(&tmp, &one)
@@ -983,7 +1376,7 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 		// (Ql, Qr, Qm, Qo, S1, S2, S3, Qcp, Qc) and ID, LOne
 		// we could pre-compute theses rho*2 FFTs and store them
 		// at the cost of a huge memory footprint.
-		batchApply(s.x, func(p *iop.Polynomial) {
+		batchApply(s.x[:s.idLROShifted], func(p *iop.Polynomial) {
 			nbTasks := calculateNbTasks(len(s.x)-1) * 2
 			// shift polynomials to be in the correct coset
 			p.ToCanonical(s.domain0, nbTasks)
@@ -1033,6 +1426,7 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 				cq[j].Mul(&cq[j], &tmp)
 			}
 		}
+		s.progress.Step(backend.PhaseQuotient)
 	}
 
 	// scale everything back
@@ -1049,7 +1443,7 @@ func (s *instance) computeNumerator() (*iop.Polynomial, error) {
 		}
 		cs.Inverse(&cs)
 
-		batchApply(s.x, func(p *iop.Polynomial) {
+		batchApply(s.x[:s.idLROShifted], func(p *iop.Polynomial) {
 			if p == nil {
 				return
 			}
@@ -1083,6 +1477,9 @@ func calculateNbTasks(n int) int {
 }
 
 // batchApply executes fn on all polynomials in x except x[id_ZS] in parallel.
+// The shifted L, R, O of the custom gates and the shifted S of the lookups
+// must not be passed in x either, as they share their coefficients with L, R,
+// O and S.
 func batchApply(x []*iop.Polynomial, fn func(*iop.Polynomial)) {
 	var wg sync.WaitGroup
 	for i := 0; i < len(x); i++ {
@@ -1280,8 +1677,13 @@ func evaluateXnMinusOneDomainBigCoset(domains [2]*fft.Domain) []fr.Element {
 //
 // α²*L₁(ζ)*Z(X)
 // + α*( (l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*Z(μζ)*s3(X) - Z(X)*(l(ζ)+β*id1(ζ)+γ)*(r(ζ)+β*id2(ζ)+γ)*(o(ζ)+β*id3(ζ)+γ))
-// + l(ζ)*Ql(X) + l(ζ)r(ζ)*Qm(X) + r(ζ)*Qr(X) + o(ζ)*Qo(X) + Qk(X)
-func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta, zu fr.Element, qcpZeta, blindedZCanonical []fr.Element, pi2Canonical [][]fr.Element, pk *ProvingKey) []fr.Element {
+// + l(ζ)*Ql(X) + l(ζ)r(ζ)*Qm(X) + r(ζ)*Qr(X) + o(ζ)*Qo(X) + Qk(X) + ∑ᵢgᵢ(ζ)*Qgᵢ(X)
+//
+// + lookupS*S(X) + lookupM*M(X)
+//
+// where gᵢ(ζ) is the evaluation of the i-th custom gate (customGatesZeta), and
+// the last line is the contribution of the lookup argument, if any.
+func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta, gamma, zeta, zu fr.Element, qcpZeta, customGatesZeta, blindedZCanonical []fr.Element, pi2Canonical [][]fr.Element, lookupS, lookupM fr.Element, blindedSCanonical, blindedMCanonical []fr.Element, pk *ProvingKey) []fr.Element {
 	// TODO @gbotrel rename
 	// first part: individual constraints
 	var rl fr.Element
@@ -1339,6 +1741,7 @@ func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta,
 		cqm := s.trace.Qm.Coefficients()
 		cqo := s.trace.Qo.Coefficients()
 		cqk := s.trace.Qk.Coefficients()
+		cqg := coefficients(s.trace.Qg)
 
 		var t, t0, t1 fr.Element
 
@@ -1376,6 +1779,20 @@ func (s *instance) innerComputeLinearizedPoly(lZeta, rZeta, oZeta, alpha, beta,
 					t0.Mul(&pi2Canonical[j][i], &qcpZeta[j])
 					t.Add(&t, &t0)
 				}
+
+				for j := range customGatesZeta {
+					t0.Mul(&cqg[j][i], &customGatesZeta[j])
+					t.Add(&t, &t0) // linPol = linPol + gⱼ(ζ)*Qgⱼ(X)
+				}
+			}
+
+			if i < len(blindedSCanonical) {
+				t0.Mul(&blindedSCanonical[i], &lookupS)
+				t.Add(&t, &t0) // linPol = linPol + lookupS*S(X)
+			}
+			if i < len(blindedMCanonical) {
+				t0.Mul(&blindedMCanonical[i], &lookupM)
+				t.Add(&t, &t0) // linPol = linPol + lookupM*M(X)
 			}
 
 			t0.Mul(&blindedZCanonical[i], &lagrangeZeta)
@@ -21,6 +21,8 @@ import (
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/kzg"
 	"io"
+
+	"github.com/consensys/gnark/constraint"
 )
 
 // WriteRawTo writes binary encoding of Proof to w without point compression
@@ -49,6 +51,12 @@ func (proof *Proof) writeTo(w io.Writer, options ...func(*curve.Encoder)) (int64
 		&proof.ZShiftedOpening.H,
 		&proof.ZShiftedOpening.ClaimedValue,
 		proof.Bsb22Commitments,
+		&proof.LROShiftedOpening.H,
+		proof.LROShiftedOpening.ClaimedValues,
+		&proof.Multiplicities,
+		&proof.LookupSum,
+		&proof.LookupSumShiftedOpening.H,
+		&proof.LookupSumShiftedOpening.ClaimedValue,
 	}
 
 	for _, v := range toEncode {
@@ -84,9 +92,28 @@ func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
 		}
 	}
 
+	// proofs serialized before the custom gates were introduced end here
+	if err := decodeOptional(dec, &proof.LROShiftedOpening.H, &proof.LROShiftedOpening.ClaimedValues); err != nil {
+		return dec.BytesRead(), err
+	}
+
+	// proofs serialized before the lookups were introduced end here
+	if err := decodeOptional(dec,
+		&proof.Multiplicities,
+		&proof.LookupSum,
+		&proof.LookupSumShiftedOpening.H,
+		&proof.LookupSumShiftedOpening.ClaimedValue,
+	); err != nil {
+		return dec.BytesRead(), err
+	}
+
 	if proof.Bsb22Commitments == nil {
 		proof.Bsb22Commitments = []kzg.Digest{}
 	}
+	if len(proof.LROShiftedOpening.ClaimedValues) == 0 {
+		// the prover leaves it unset if no custom gate spans two rows
+		proof.LROShiftedOpening.ClaimedValues = nil
+	}
 
 	return dec.BytesRead(), nil
 }
@@ -205,6 +232,13 @@ func (vk *VerifyingKey) writeTo(w io.Writer, options ...func(*curve.Encoder)) (n
 		&vk.Kzg.G2[1],
 		&vk.Kzg.Lines,
 		vk.CommitmentConstraintIndexes,
+		vk.Qg,
+		constraint.EncodeCustomGates(vk.CustomGates),
+		vk.NbFixedTables,
+		&vk.Qlk,
+		&vk.Qlkid,
+		&vk.Tval,
+		&vk.Tid,
 	}
 
 	for _, v := range toEncode {
@@ -225,6 +259,7 @@ func (vk *VerifyingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
 // ReadFrom reads from binary representation in r into VerifyingKey
 func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 	dec := curve.NewDecoder(r)
+	var customGates [][]uint64
 	toDecode := []interface{}{
 		&vk.Size,
 		&vk.SizeInv,
@@ -253,9 +288,41 @@ func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
 		}
 	}
 
+	// verifying keys serialized before the custom gates were introduced end
+	// here; this doesn't apply to a verifying key embedded in a proving key.
+	if err := decodeOptional(dec, &vk.Qg, &customGates); err != nil {
+		return dec.BytesRead(), err
+	}
+
+	// same for the fixed tables
+	if err := decodeOptional(dec, &vk.NbFixedTables, &vk.Qlk, &vk.Qlkid, &vk.Tval, &vk.Tid); err != nil {
+		return dec.BytesRead(), err
+	}
+
 	if vk.Qcp == nil {
 		vk.Qcp = []kzg.Digest{}
 	}
+	if vk.Qg == nil {
+		vk.Qg = []kzg.Digest{}
+	}
+	var err error
+	if vk.CustomGates, err = constraint.DecodeCustomGates(customGates); err != nil {
+		return dec.BytesRead(), err
+	}
 
 	return dec.BytesRead(), nil
 }
+
+// decodeOptional decodes the trailing values of an object. The values are
+// left unset if the stream ends before the first one.
+func decodeOptional(dec *curve.Decoder, values ...interface{}) error {
+	for i, v := range values {
+		if err := dec.Decode(v); err != nil {
+			if i == 0 && err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
 
 	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/io"
 	"math/big"
 	"math/rand"
@@ -89,6 +90,22 @@ func (vk *VerifyingKey) randomize() {
 	vk.Qo = randomG1Point()
 	vk.Qk = randomG1Point()
 	vk.Qcp = randomG1Points(rand.Intn(4)) //#nosec G404 weak rng is fine here
+	vk.Qg = randomG1Points(1)
+	sbox := constraint.CustomGate{Name: "sbox"}
+	sbox.Identities = append(sbox.Identities, []constraint.GateTerm{
+		{Coeff: 1, Wires: []constraint.GateWire{constraint.GateR}},
+		{Coeff: -1, Wires: []constraint.GateWire{constraint.GateL, constraint.GateL}},
+	})
+	sbox.Identities = append(sbox.Identities, []constraint.GateTerm{
+		{Coeff: 1, Wires: []constraint.GateWire{constraint.GateNextL}},
+		{Coeff: -1, Wires: []constraint.GateWire{constraint.GateR, constraint.GateR, constraint.GateL}},
+	})
+	vk.CustomGates = []constraint.CustomGate{sbox}
+	vk.NbFixedTables = 2
+	vk.Qlk = randomG1Point()
+	vk.Qlkid = randomG1Point()
+	vk.Tval = randomG1Point()
+	vk.Tid = randomG1Point()
 }
 
 func (proof *Proof) randomize() {
@@ -104,6 +121,12 @@ func (proof *Proof) randomize() {
 	proof.ZShiftedOpening.H = randomG1Point()
 	proof.ZShiftedOpening.ClaimedValue.SetRandom()
 	proof.Bsb22Commitments = randomG1Points(rand.Intn(4)) //#nosec G404 weak rng is fine here
+	proof.LROShiftedOpening.H = randomG1Point()
+	proof.LROShiftedOpening.ClaimedValues = randomScalars(3)
+	proof.Multiplicities = randomG1Point()
+	proof.LookupSum = randomG1Point()
+	proof.LookupSumShiftedOpening.H = randomG1Point()
+	proof.LookupSumShiftedOpening.ClaimedValue.SetRandom()
 }
 
 func randomG2Point() curve.G2Affine {
@@ -0,0 +1,116 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/internal/utils"
+	"io"
+	"strings"
+)
+
+// ethereumTranscript is the part of the transcript of the Ethereum KZG ceremony
+// needed to import the powers of τ.
+type ethereumTranscript struct {
+	Transcripts []struct {
+		NumG1Powers int `json:"numG1Powers"`
+		NumG2Powers int `json:"numG2Powers"`
+		PowersOfTau struct {
+			G1Powers []string `json:"G1Powers"`
+			G2Powers []string `json:"G2Powers"`
+		} `json:"powersOfTau"`
+	} `json:"transcripts"`
+}
+
+// ImportEthereumKZGCeremony imports the first size powers of τ from the
+// transcript of the Ethereum KZG ceremony (https://ceremony.ethereum.org), in
+// its JSON format. The transcript holds several independent ceremonies of
+// different sizes, the smallest one having at least size powers of τ in G₁ is
+// used.
+//
+// The returned ceremony can receive further contributions, or directly be
+// turned into a KZG SRS (see SRS()).
+func ImportEthereumKZGCeremony(r io.Reader, size int) (PowersOfTau, error) {
+	var p PowersOfTau
+	if size < 2 {
+		return p, errors.New("the ceremony must have at least two powers of τ")
+	}
+
+	var transcript ethereumTranscript
+	if err := json.NewDecoder(r).Decode(&transcript); err != nil {
+		return p, err
+	}
+	found := -1
+	for i, t := range transcript.Transcripts {
+		if t.NumG1Powers >= size && len(t.PowersOfTau.G1Powers) >= size && len(t.PowersOfTau.G2Powers) >= 2 &&
+			(found == -1 || t.NumG1Powers < transcript.Transcripts[found].NumG1Powers) {
+			found = i
+		}
+	}
+	if found == -1 {
+		return p, fmt.Errorf("no ceremony in the transcript has %d powers of τ", size)
+	}
+	powersOfTau := transcript.Transcripts[found].PowersOfTau
+
+	p.Parameters.G1.Tau = make([]curve.G1Affine, size)
+	errs := make([]error, size)
+	utils.Parallelize(size, func(start, end int) {
+		for i := start; i < end; i++ {
+			errs[i] = setEthereumPoint(&p.Parameters.G1.Tau[i], powersOfTau.G1Powers[i])
+		}
+	})
+	for i := range errs {
+		if errs[i] != nil {
+			return p, fmt.Errorf("power %d of τ in G₁: %w", i, errs[i])
+		}
+	}
+	for i := range p.Parameters.G2.Tau {
+		if err := setEthereumPoint(&p.Parameters.G2.Tau[i], powersOfTau.G2Powers[i]); err != nil {
+			return p, fmt.Errorf("power %d of τ in G₂: %w", i, err)
+		}
+	}
+
+	if err := p.checkPowers(); err != nil {
+		return p, err
+	}
+	p.Hash = p.hash()
+
+	return p, nil
+}
+
+// setEthereumPoint decodes a point of the Ethereum KZG ceremony, hex encoded in
+// the compressed format of ZCash, which is also the one of gnark-crypto.
+func setEthereumPoint[P interface {
+	SetBytes([]byte) (int, error)
+}](point P, s string) error {
+	buf, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return err
+	}
+	n, err := point.SetBytes(buf)
+	if err != nil {
+		return err
+	}
+	if n != len(buf) {
+		return errors.New("invalid point encoding")
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestImportEthereumKZGCeremony(t *testing.T) {
+	assert := require.New(t)
+
+	// write a transcript with two ceremonies
+	var transcript ethereumTranscript
+	_, _, g1, g2 := curve.Generators()
+	var taus [2]fr.Element
+	for i, size := range []int{32, 16} {
+		taus[i].SetRandom()
+		g1Tau := curve.BatchScalarMultiplicationG1(&g1, powers(taus[i], size))
+		g2Tau := curve.BatchScalarMultiplicationG2(&g2, powers(taus[i], 4))
+		var c struct {
+			NumG1Powers int `json:"numG1Powers"`
+			NumG2Powers int `json:"numG2Powers"`
+			PowersOfTau struct {
+				G1Powers []string `json:"G1Powers"`
+				G2Powers []string `json:"G2Powers"`
+			} `json:"powersOfTau"`
+		}
+		c.NumG1Powers, c.NumG2Powers = size, len(g2Tau)
+		for j := range g1Tau {
+			b := g1Tau[j].Bytes()
+			c.PowersOfTau.G1Powers = append(c.PowersOfTau.G1Powers, "0x"+hex.EncodeToString(b[:]))
+		}
+		for j := range g2Tau {
+			b := g2Tau[j].Bytes()
+			c.PowersOfTau.G2Powers = append(c.PowersOfTau.G2Powers, "0x"+hex.EncodeToString(b[:]))
+		}
+		transcript.Transcripts = append(transcript.Transcripts, c)
+	}
+	raw, err := json.Marshal(&transcript)
+	assert.NoError(err)
+
+	// the smallest ceremony large enough is used
+	srs, err := ImportEthereumKZGCeremony(bytes.NewReader(raw), 8+3)
+	assert.NoError(err)
+	assert.Equal(curve.BatchScalarMultiplicationG1(&g1, powers(taus[1], 8+3)), srs.Parameters.G1.Tau)
+
+	srs, err = ImportEthereumKZGCeremony(bytes.NewReader(raw), 16+3)
+	assert.NoError(err)
+	assert.Equal(curve.BatchScalarMultiplicationG1(&g1, powers(taus[0], 16+3)), srs.Parameters.G1.Tau)
+
+	// the imported ceremony can be extended
+	prev := srs.clone()
+	srs.Contribute()
+	assert.NoError(VerifyPowersOfTau(&prev, &srs))
+	_, _, err = srs.SRS(16)
+	assert.NoError(err)
+
+	_, err = ImportEthereumKZGCeremony(bytes.NewReader(raw), 33)
+	assert.Error(err)
+}
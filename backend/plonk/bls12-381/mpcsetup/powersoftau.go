@@ -0,0 +1,171 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"crypto/sha256"
+	"errors"
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"math/big"
+)
+
+// PowersOfTau represents a universal powers of tau ceremony, from which the
+// KZG SRS of PLONK circuits of any size up to the size of the ceremony can be
+// extracted (see SRS()).
+//
+// Unlike the phase 1 of the Groth16 MPC, no α or β powers are needed: the
+// output only depends on τ and doesn't need a circuit specific phase 2.
+type PowersOfTau struct {
+	Parameters struct {
+		G1 struct {
+			Tau []curve.G1Affine // {[τ⁰]₁, [τ¹]₁, [τ²]₁, …, [τⁿ⁻¹]₁}
+		}
+		G2 struct {
+			Tau [2]curve.G2Affine // {[τ⁰]₂, [τ¹]₂}
+		}
+	}
+	PublicKey PublicKey
+	Hash      []byte // sha256 hash
+}
+
+// InitPowersOfTau initializes a ceremony of size powers of τ in G₁. This is
+// called once by the coordinator before any randomness contribution is made
+// (see Contribute()). To setup PLONK circuits whose domain has n elements, size
+// must be at least n+3.
+func InitPowersOfTau(size int) (p PowersOfTau) {
+	if size < 2 {
+		panic("the ceremony must have at least two powers of τ")
+	}
+
+	// Generate key pair
+	var tau fr.Element
+	tau.SetOne()
+	p.PublicKey = newPublicKey(tau, nil, 1)
+
+	// First contribution use generators
+	_, _, g1, g2 := curve.Generators()
+	p.Parameters.G1.Tau = make([]curve.G1Affine, size)
+	for i := 0; i < len(p.Parameters.G1.Tau); i++ {
+		p.Parameters.G1.Tau[i].Set(&g1)
+	}
+	p.Parameters.G2.Tau[0].Set(&g2)
+	p.Parameters.G2.Tau[1].Set(&g2)
+
+	// Compute hash of Contribution
+	p.Hash = p.hash()
+
+	return
+}
+
+// Contribute contributes randomness to the ceremony. This mutates p.
+func (p *PowersOfTau) Contribute() {
+	// Generate key pair
+	var tau fr.Element
+	tau.SetRandom()
+	p.PublicKey = newPublicKey(tau, p.Hash[:], 1)
+
+	// Update using previous parameters
+	taus := powers(tau, len(p.Parameters.G1.Tau))
+	scaleG1InPlace(p.Parameters.G1.Tau, taus)
+	var tauBI big.Int
+	tau.BigInt(&tauBI)
+	p.Parameters.G2.Tau[1].ScalarMultiplication(&p.Parameters.G2.Tau[1], &tauBI)
+
+	// Compute hash of Contribution
+	p.Hash = p.hash()
+}
+
+// VerifyPowersOfTau checks that each contribution is based on the previous
+// one, c0 being the initial state or an imported ceremony.
+func VerifyPowersOfTau(c0, c1 *PowersOfTau, c ...*PowersOfTau) error {
+	contribs := append([]*PowersOfTau{c0, c1}, c...)
+	for i := 0; i < len(contribs)-1; i++ {
+		if err := verifyPowersOfTau(contribs[i], contribs[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyPowersOfTau checks that a contribution is based on a known previous
+// state.
+func verifyPowersOfTau(current, contribution *PowersOfTau) error {
+	if len(contribution.Parameters.G1.Tau) != len(current.Parameters.G1.Tau) {
+		return errors.New("the contribution doesn't have the same size as the previous one")
+	}
+
+	// Compute R for τ
+	tauR := genR(contribution.PublicKey.SG, contribution.PublicKey.SXG, current.Hash[:], 1)
+
+	// Check for knowledge of toxic parameters
+	if !sameRatio(contribution.PublicKey.SG, contribution.PublicKey.SXG, contribution.PublicKey.XR, tauR) {
+		return errors.New("couldn't verify public key of τ")
+	}
+
+	// Check for valid updates using previous parameters
+	if !sameRatio(contribution.Parameters.G1.Tau[1], current.Parameters.G1.Tau[1], tauR, contribution.PublicKey.XR) {
+		return errors.New("couldn't verify that [τ]₁ is based on previous contribution")
+	}
+	if !sameRatio(contribution.PublicKey.SG, contribution.PublicKey.SXG, contribution.Parameters.G2.Tau[1], current.Parameters.G2.Tau[1]) {
+		return errors.New("couldn't verify that [τ]₂ is based on previous contribution")
+	}
+
+	// Check for valid updates using powers of τ
+	if err := contribution.checkPowers(); err != nil {
+		return err
+	}
+
+	// Check hash of the contribution
+	h := contribution.hash()
+	for i := 0; i < len(h); i++ {
+		if h[i] != contribution.Hash[i] {
+			return errors.New("couldn't verify hash of contribution")
+		}
+	}
+
+	return nil
+}
+
+// checkPowers checks that the parameters are the successive powers of the same
+// non-zero τ, starting at the generators.
+func (p *PowersOfTau) checkPowers() error {
+	_, _, g1, g2 := curve.Generators()
+	if len(p.Parameters.G1.Tau) < 2 {
+		return errors.New("the ceremony must have at least two powers of τ")
+	}
+	if !p.Parameters.G1.Tau[0].Equal(&g1) || !p.Parameters.G2.Tau[0].Equal(&g2) {
+		return errors.New("couldn't verify that the powers of τ start at the generators")
+	}
+	if p.Parameters.G1.Tau[1].IsInfinity() || p.Parameters.G2.Tau[1].IsInfinity() {
+		return errors.New("couldn't verify that τ is not zero")
+	}
+	tauL1, tauL2 := linearCombinationG1(p.Parameters.G1.Tau)
+	if !sameRatio(tauL1, tauL2, p.Parameters.G2.Tau[1], g2) {
+		return errors.New("couldn't verify valid powers of τ in G₁")
+	}
+	if !sameRatio(p.Parameters.G1.Tau[1], g1, g2, p.Parameters.G2.Tau[1]) {
+		return errors.New("couldn't verify valid powers of τ in G₂")
+	}
+	return nil
+}
+
+func (p *PowersOfTau) hash() []byte {
+	sha := sha256.New()
+	p.writeTo(sha)
+	return sha.Sum(nil)
+}
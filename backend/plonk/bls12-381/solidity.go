@@ -0,0 +1,1517 @@
+package plonk
+
+import (
+	"encoding/hex"
+
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+const tmplSolidityVerifier = `// SPDX-License-Identifier: Apache-2.0
+
+// Copyright 2023 Consensys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+pragma solidity ^0.8.19;
+
+/// PlonK verifier on BLS12-381, using the precompiles of EIP-2537.
+/// Elements of the base field have 381 bits and don't fit in a word: as in
+/// EIP-2537, they are encoded in two words, the 128 high bits (_HI) followed by
+/// the 256 low bits (_LO). A point of G1 is thus encoded in 4 words, and a point
+/// of G2 in 8 words (the coordinates are a₀ + a₁⋅u, encoded as a₀ followed by a₁).
+contract PlonkVerifier {
+
+  uint256 private constant R_MOD = 52435875175126190479447740508185965837690552500527637822603658699938581184513;
+  uint256 private constant R_MOD_MINUS_ONE = 52435875175126190479447740508185965837690552500527637822603658699938581184512;
+
+  // EIP-2537 precompiles
+  uint256 private constant PRECOMPILE_G1_MSM = 0x0c;
+  uint256 private constant PRECOMPILE_PAIRING = 0x0f;
+  {{ $g2 := index .Kzg.G2 0 }}
+  uint256 private constant G2_SRS_0_X_0_HI = {{ fpHi $g2.X.A0 }};
+  uint256 private constant G2_SRS_0_X_0_LO = {{ fpLo $g2.X.A0 }};
+  uint256 private constant G2_SRS_0_X_1_HI = {{ fpHi $g2.X.A1 }};
+  uint256 private constant G2_SRS_0_X_1_LO = {{ fpLo $g2.X.A1 }};
+  uint256 private constant G2_SRS_0_Y_0_HI = {{ fpHi $g2.Y.A0 }};
+  uint256 private constant G2_SRS_0_Y_0_LO = {{ fpLo $g2.Y.A0 }};
+  uint256 private constant G2_SRS_0_Y_1_HI = {{ fpHi $g2.Y.A1 }};
+  uint256 private constant G2_SRS_0_Y_1_LO = {{ fpLo $g2.Y.A1 }};
+  {{ $g2neg := negG2 (index .Kzg.G2 1) }}
+  // -[τ]₂, so that no point needs to be negated in the pairing check
+  uint256 private constant G2_SRS_1_NEG_X_0_HI = {{ fpHi $g2neg.X.A0 }};
+  uint256 private constant G2_SRS_1_NEG_X_0_LO = {{ fpLo $g2neg.X.A0 }};
+  uint256 private constant G2_SRS_1_NEG_X_1_HI = {{ fpHi $g2neg.X.A1 }};
+  uint256 private constant G2_SRS_1_NEG_X_1_LO = {{ fpLo $g2neg.X.A1 }};
+  uint256 private constant G2_SRS_1_NEG_Y_0_HI = {{ fpHi $g2neg.Y.A0 }};
+  uint256 private constant G2_SRS_1_NEG_Y_0_LO = {{ fpLo $g2neg.Y.A0 }};
+  uint256 private constant G2_SRS_1_NEG_Y_1_HI = {{ fpHi $g2neg.Y.A1 }};
+  uint256 private constant G2_SRS_1_NEG_Y_1_LO = {{ fpLo $g2neg.Y.A1 }};
+
+  uint256 private constant G1_SRS_X_HI = {{ fpHi .Kzg.G1.X }};
+  uint256 private constant G1_SRS_X_LO = {{ fpLo .Kzg.G1.X }};
+  uint256 private constant G1_SRS_Y_HI = {{ fpHi .Kzg.G1.Y }};
+  uint256 private constant G1_SRS_Y_LO = {{ fpLo .Kzg.G1.Y }};
+
+  // ----------------------- vk ---------------------
+  uint256 private constant VK_NB_PUBLIC_INPUTS = {{ .NbPublicVariables }};
+  uint256 private constant VK_DOMAIN_SIZE = {{ .Size }};
+  uint256 private constant VK_INV_DOMAIN_SIZE = {{ (frstr .SizeInv) }};
+  uint256 private constant VK_OMEGA = {{ (frstr .Generator) }};
+  uint256 private constant VK_QL_COM_X_HI = {{ fpHi .Ql.X }};
+  uint256 private constant VK_QL_COM_X_LO = {{ fpLo .Ql.X }};
+  uint256 private constant VK_QL_COM_Y_HI = {{ fpHi .Ql.Y }};
+  uint256 private constant VK_QL_COM_Y_LO = {{ fpLo .Ql.Y }};
+  uint256 private constant VK_QR_COM_X_HI = {{ fpHi .Qr.X }};
+  uint256 private constant VK_QR_COM_X_LO = {{ fpLo .Qr.X }};
+  uint256 private constant VK_QR_COM_Y_HI = {{ fpHi .Qr.Y }};
+  uint256 private constant VK_QR_COM_Y_LO = {{ fpLo .Qr.Y }};
+  uint256 private constant VK_QM_COM_X_HI = {{ fpHi .Qm.X }};
+  uint256 private constant VK_QM_COM_X_LO = {{ fpLo .Qm.X }};
+  uint256 private constant VK_QM_COM_Y_HI = {{ fpHi .Qm.Y }};
+  uint256 private constant VK_QM_COM_Y_LO = {{ fpLo .Qm.Y }};
+  uint256 private constant VK_QO_COM_X_HI = {{ fpHi .Qo.X }};
+  uint256 private constant VK_QO_COM_X_LO = {{ fpLo .Qo.X }};
+  uint256 private constant VK_QO_COM_Y_HI = {{ fpHi .Qo.Y }};
+  uint256 private constant VK_QO_COM_Y_LO = {{ fpLo .Qo.Y }};
+  uint256 private constant VK_QK_COM_X_HI = {{ fpHi .Qk.X }};
+  uint256 private constant VK_QK_COM_X_LO = {{ fpLo .Qk.X }};
+  uint256 private constant VK_QK_COM_Y_HI = {{ fpHi .Qk.Y }};
+  uint256 private constant VK_QK_COM_Y_LO = {{ fpLo .Qk.Y }};
+  {{ range $index, $element := .S }}
+  uint256 private constant VK_S{{ inc $index }}_COM_X_HI = {{ fpHi $element.X }};
+  uint256 private constant VK_S{{ inc $index }}_COM_X_LO = {{ fpLo $element.X }};
+  uint256 private constant VK_S{{ inc $index }}_COM_Y_HI = {{ fpHi $element.Y }};
+  uint256 private constant VK_S{{ inc $index }}_COM_Y_LO = {{ fpLo $element.Y }};
+  {{ end }}
+  uint256 private constant VK_COSET_SHIFT = {{ (frstr .CosetShift) }};
+
+  {{ range $index, $element := .Qcp}}
+  uint256 private constant VK_QCP_{{ $index }}_X_HI = {{ fpHi $element.X }};
+  uint256 private constant VK_QCP_{{ $index }}_X_LO = {{ fpLo $element.X }};
+  uint256 private constant VK_QCP_{{ $index }}_Y_HI = {{ fpHi $element.Y }};
+  uint256 private constant VK_QCP_{{ $index }}_Y_LO = {{ fpLo $element.Y }};
+  {{ end }}
+
+  {{ range $index, $element := .CommitmentConstraintIndexes -}}
+  uint256 private constant VK_INDEX_COMMIT_API{{ $index }} = {{ $element }};
+  {{ end -}}
+  uint256 private constant VK_NB_CUSTOM_GATES = {{ len .CommitmentConstraintIndexes }};
+  {{ range $index, $element := .Qg }}
+  uint256 private constant VK_QG_{{ $index }}_X_HI = {{ fpHi $element.X }};
+  uint256 private constant VK_QG_{{ $index }}_X_LO = {{ fpLo $element.X }};
+  uint256 private constant VK_QG_{{ $index }}_Y_HI = {{ fpHi $element.Y }};
+  uint256 private constant VK_QG_{{ $index }}_Y_LO = {{ fpLo $element.Y }};
+  {{ end }}
+
+  // ------------------------------------------------
+
+  // offset proof, the points are on 0x80 bytes
+  uint256 private constant PROOF_L_COM = 0x00;
+  uint256 private constant PROOF_R_COM = 0x80;
+  uint256 private constant PROOF_O_COM = 0x100;
+
+  // h = h_0 + x^{n+2}h_1 + x^{2(n+2)}h_2
+  uint256 private constant PROOF_H_0 = 0x180;
+  uint256 private constant PROOF_H_1 = 0x200;
+  uint256 private constant PROOF_H_2 = 0x280;
+
+  // wire values at zeta
+  uint256 private constant PROOF_L_AT_ZETA = 0x300;
+  uint256 private constant PROOF_R_AT_ZETA = 0x320;
+  uint256 private constant PROOF_O_AT_ZETA = 0x340;
+
+  //uint256[STATE_WIDTH-1] permutation_polynomials_at_zeta; // Sσ1(zeta),Sσ2(zeta)
+  uint256 private constant PROOF_S1_AT_ZETA = 0x360; // Sσ1(zeta)
+  uint256 private constant PROOF_S2_AT_ZETA = 0x380; // Sσ2(zeta)
+
+  //Bls12381.G1Point grand_product_commitment;                 // [z(x)]
+  uint256 private constant PROOF_GRAND_PRODUCT_COMMITMENT = 0x3a0;
+
+  uint256 private constant PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA = 0x420; // z(w*zeta)
+  uint256 private constant PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA = 0x440; // t(zeta)
+  uint256 private constant PROOF_LINEARISED_POLYNOMIAL_AT_ZETA = 0x460; // r(zeta)
+
+  // Folded proof for the opening of H, linearised poly, l, r, o, s_1, s_2, qcp
+  uint256 private constant PROOF_BATCH_OPENING_AT_ZETA = 0x480; // [Wzeta]
+
+  uint256 private constant PROOF_OPENING_AT_ZETA_OMEGA = 0x500;
+
+  uint256 private constant PROOF_OPENING_QCP_AT_ZETA = 0x580;
+  uint256 private constant PROOF_COMMITMENTS_WIRES_CUSTOM_GATES = {{ hex (add 1408 (mul (len .CommitmentConstraintIndexes) 32 ) )}};
+
+  // -> next part of proof is
+  // [ openings_selector_commits || commitments_wires_commit_api]
+  {{ if spansTwoRows }}
+  // openings of l, r, o at zeta*omega, read by the custom gates spanning two rows
+  uint256 private constant PROOF_L_AT_ZETA_OMEGA = {{ hex (add 1408 (mul (len .CommitmentConstraintIndexes) 160 ) )}};
+  uint256 private constant PROOF_R_AT_ZETA_OMEGA = {{ hex (add 1440 (mul (len .CommitmentConstraintIndexes) 160 ) )}};
+  uint256 private constant PROOF_O_AT_ZETA_OMEGA = {{ hex (add 1472 (mul (len .CommitmentConstraintIndexes) 160 ) )}};
+  uint256 private constant PROOF_OPENING_LRO_AT_ZETA_OMEGA = {{ hex (add 1504 (mul (len .CommitmentConstraintIndexes) 160 ) )}};
+  {{ end }}
+
+  // -------- offset state
+
+  // challenges to check the claimed quotient
+  uint256 private constant STATE_ALPHA = 0x00;
+  uint256 private constant STATE_BETA = 0x20;
+  uint256 private constant STATE_GAMMA = 0x40;
+  uint256 private constant STATE_ZETA = 0x60;
+
+  // reusable value
+  uint256 private constant STATE_ALPHA_SQUARE_LAGRANGE_0 = 0x80;
+
+  // commitment to H
+  uint256 private constant STATE_FOLDED_H = 0xa0;
+
+  // commitment to the linearised polynomial
+  uint256 private constant STATE_LINEARISED_POLYNOMIAL = 0x120;
+
+  // Folded proof for the opening of H, linearised poly, l, r, o, s_1, s_2, qcp
+  uint256 private constant STATE_FOLDED_CLAIMED_VALUES = 0x1a0;
+
+  // folded digests of H, linearised poly, l, r, o, s_1, s_2, qcp
+  uint256 private constant STATE_FOLDED_DIGESTS = 0x1c0;
+
+  uint256 private constant STATE_PI = 0x240;
+
+  uint256 private constant STATE_ZETA_POWER_N_MINUS_ONE = 0x260;
+
+  uint256 private constant STATE_GAMMA_KZG = 0x280;
+
+  uint256 private constant STATE_SUCCESS = 0x2a0;
+  uint256 private constant STATE_CHECK_VAR = 0x2c0; // /!\ this slot is used for debugging only
+  {{ if spansTwoRows }}
+  // folded digests and claimed values of l, r, o at zeta*omega
+  uint256 private constant STATE_FOLDED_LRO = 0x2e0;
+  uint256 private constant STATE_FOLDED_LRO_CLAIMED_VALUE = 0x360;
+
+  uint256 private constant STATE_LAST_MEM = 0x380;
+  {{ else }}
+  uint256 private constant STATE_LAST_MEM = 0x2e0;
+  {{ end }}
+
+  // -------- errors
+  uint256 private constant ERROR_STRING_ID = 0x08c379a000000000000000000000000000000000000000000000000000000000; // selector for function Error(string)
+
+  {{ if (gt (len .CommitmentConstraintIndexes) 0 )}}
+  // -------- utils (for hash_fr)
+	uint256 private constant HASH_FR_BB = 340282366920938463463374607431768211456; // 2**128
+	uint256 private constant HASH_FR_ZERO_UINT256 = 0;
+
+	uint8 private constant HASH_FR_LEN_IN_BYTES = 48;
+	uint8 private constant HASH_FR_SIZE_DOMAIN = 11;
+	uint8 private constant HASH_FR_ONE = 1;
+	uint8 private constant HASH_FR_TWO = 2;
+  {{ end }}
+
+  /// Verify a Plonk proof.
+  /// Reverts if the proof or the public inputs are malformed.
+  /// @param proof serialised plonk proof (using gnark's MarshalSolidity)
+  /// @param public_inputs (must be reduced)
+  /// @return success true if the proof passes false otherwise
+  function Verify(bytes calldata proof, uint256[] calldata public_inputs)
+  public view returns(bool success) {
+
+    assembly {
+
+      let mem := mload(0x40)
+      let freeMem := add(mem, STATE_LAST_MEM)
+
+      // sanity checks
+      check_number_of_public_inputs(public_inputs.length)
+      check_inputs_size(public_inputs.length, public_inputs.offset)
+      check_proof_size(proof.length)
+      check_proof_openings_size(proof.offset)
+
+      // compute the challenges
+      let prev_challenge_non_reduced
+      prev_challenge_non_reduced := derive_gamma(proof.offset, public_inputs.length, public_inputs.offset)
+      prev_challenge_non_reduced := derive_beta(prev_challenge_non_reduced)
+      prev_challenge_non_reduced := derive_alpha(proof.offset, prev_challenge_non_reduced)
+      derive_zeta(proof.offset, prev_challenge_non_reduced)
+
+      // evaluation of Z=Xⁿ-1 at ζ, we save this value
+      let zeta := mload(add(mem, STATE_ZETA))
+      let zeta_power_n_minus_one := addmod(pow(zeta, VK_DOMAIN_SIZE, freeMem), sub(R_MOD, 1), R_MOD)
+      mstore(add(mem, STATE_ZETA_POWER_N_MINUS_ONE), zeta_power_n_minus_one)
+
+      // public inputs contribution
+      let l_pi := sum_pi_wo_api_commit(public_inputs.offset, public_inputs.length, freeMem)
+      {{ if (gt (len .CommitmentConstraintIndexes) 0 ) -}}
+      let l_wocommit := sum_pi_commit(proof.offset, public_inputs.length, freeMem)
+      l_pi := addmod(l_wocommit, l_pi, R_MOD)
+      {{ end -}}
+      mstore(add(mem, STATE_PI), l_pi)
+
+      compute_alpha_square_lagrange_0()
+      verify_quotient_poly_eval_at_zeta(proof.offset)
+      fold_h(proof.offset)
+      compute_commitment_linearised_polynomial(proof.offset)
+      compute_gamma_kzg(proof.offset)
+      fold_state(proof.offset)
+      {{ if spansTwoRows -}}
+      fold_lro_at_zeta_omega(proof.offset)
+      {{ end -}}
+      batch_verify_multi_points(proof.offset)
+
+      success := mload(add(mem, STATE_SUCCESS))
+
+      // Beginning errors -------------------------------------------------
+
+      function error_nb_public_inputs() {
+        let ptError := mload(0x40)
+        mstore(ptError, ERROR_STRING_ID) // selector for function Error(string)
+        mstore(add(ptError, 0x4), 0x20)
+        mstore(add(ptError, 0x24), 0x1d)
+        mstore(add(ptError, 0x44), "wrong number of public inputs")
+        revert(ptError, 0x64)
+      }
+
+      /// Called when an operation on Bls12381 fails
+      /// @dev for instance when calling the MSM precompile on a point not in G1.
+      function error_ec_op() {
+        let ptError := mload(0x40)
+        mstore(ptError, ERROR_STRING_ID) // selector for function Error(string)
+        mstore(add(ptError, 0x4), 0x20)
+        mstore(add(ptError, 0x24), 0x12)
+        mstore(add(ptError, 0x44), "error ec operation")
+        revert(ptError, 0x64)
+      }
+
+      /// Called when one of the public inputs is not reduced.
+      function error_inputs_size() {
+        let ptError := mload(0x40)
+        mstore(ptError, ERROR_STRING_ID) // selector for function Error(string)
+        mstore(add(ptError, 0x4), 0x20)
+        mstore(add(ptError, 0x24), 0x18)
+        mstore(add(ptError, 0x44), "inputs are bigger than r")
+        revert(ptError, 0x64)
+      }
+
+      /// Called when the size proof is not as expected
+      /// @dev to avoid overflow attack for instance
+      function error_proof_size() {
+        let ptError := mload(0x40)
+        mstore(ptError, ERROR_STRING_ID) // selector for function Error(string)
+        mstore(add(ptError, 0x4), 0x20)
+        mstore(add(ptError, 0x24), 0x10)
+        mstore(add(ptError, 0x44), "wrong proof size")
+        revert(ptError, 0x64)
+      }
+
+      /// Called when one the openings is bigger than r
+      /// The openings are the claimed evalutions of a polynomial
+      /// in a Kzg proof.
+      function error_proof_openings_size() {
+        let ptError := mload(0x40)
+        mstore(ptError, ERROR_STRING_ID) // selector for function Error(string)
+        mstore(add(ptError, 0x4), 0x20)
+        mstore(add(ptError, 0x24), 0x16)
+        mstore(add(ptError, 0x44), "openings bigger than r")
+        revert(ptError, 0x64)
+      }
+
+      function error_verify() {
+        let ptError := mload(0x40)
+        mstore(ptError, ERROR_STRING_ID) // selector for function Error(string)
+        mstore(add(ptError, 0x4), 0x20)
+        mstore(add(ptError, 0x24), 0xc)
+        mstore(add(ptError, 0x44), "error verify")
+        revert(ptError, 0x64)
+      }
+
+      function error_random_generation() {
+        let ptError := mload(0x40)
+        mstore(ptError, ERROR_STRING_ID) // selector for function Error(string)
+        mstore(add(ptError, 0x4), 0x20)
+        mstore(add(ptError, 0x24), 0x14)
+        mstore(add(ptError, 0x44), "error random gen kzg")
+        revert(ptError, 0x64)
+      }
+      // end errors -------------------------------------------------
+
+      // Beginning checks -------------------------------------------------
+
+      /// @param s actual number of public inputs
+      function check_number_of_public_inputs(s) {
+        if iszero(eq(s, VK_NB_PUBLIC_INPUTS)) {
+          error_nb_public_inputs()
+        }
+      }
+
+      /// Checks that the public inputs are < R_MOD.
+      /// @param s number of public inputs
+      /// @param p pointer to the public inputs array
+      function check_inputs_size(s, p) {
+        for {let i} lt(i, s) {i:=add(i,1)}
+        {
+          if gt(calldataload(p), R_MOD_MINUS_ONE) {
+            error_inputs_size()
+          }
+          p := add(p, 0x20)
+        }
+      }
+
+      /// Checks if the proof is of the correct size
+      /// @param actual_proof_size size of the proof (not the expected size)
+      function check_proof_size(actual_proof_size) {
+        let expected_proof_size := add(0x580, mul(VK_NB_CUSTOM_GATES,0xa0))
+        {{ if spansTwoRows -}}
+        expected_proof_size := add(expected_proof_size, 0xe0)
+        {{ end -}}
+        if iszero(eq(actual_proof_size, expected_proof_size)) {
+         error_proof_size()
+        }
+      }
+
+      /// Checks if the multiple openings of the polynomials are < R_MOD.
+      /// @param aproof pointer to the beginning of the proof
+      /// @dev the 'a' prepending proof is to have a local name
+      function check_proof_openings_size(aproof) {
+
+
+        // linearised polynomial at zeta
+        let p := add(aproof, PROOF_LINEARISED_POLYNOMIAL_AT_ZETA)
+        if gt(calldataload(p), R_MOD_MINUS_ONE) {
+          error_proof_openings_size()
+        }
+
+        // quotient polynomial at zeta
+        p := add(aproof, PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA)
+        if gt(calldataload(p), R_MOD_MINUS_ONE) {
+          error_proof_openings_size()
+        }
+
+        // PROOF_L_AT_ZETA
+        p := add(aproof, PROOF_L_AT_ZETA)
+        if gt(calldataload(p), R_MOD_MINUS_ONE) {
+          error_proof_openings_size()
+        }
+
+        // PROOF_R_AT_ZETA
+        p := add(aproof, PROOF_R_AT_ZETA)
+        if gt(calldataload(p), R_MOD_MINUS_ONE) {
+          error_proof_openings_size()
+        }
+
+        // PROOF_O_AT_ZETA
+        p := add(aproof, PROOF_O_AT_ZETA)
+        if gt(calldataload(p), R_MOD_MINUS_ONE) {
+          error_proof_openings_size()
+        }
+
+        // PROOF_S1_AT_ZETA
+        p := add(aproof, PROOF_S1_AT_ZETA)
+        if gt(calldataload(p), R_MOD_MINUS_ONE) {
+          error_proof_openings_size()
+        }
+
+        // PROOF_S2_AT_ZETA
+        p := add(aproof, PROOF_S2_AT_ZETA)
+        if gt(calldataload(p), R_MOD_MINUS_ONE) {
+          error_proof_openings_size()
+        }
+
+        // PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA
+        p := add(aproof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA)
+        if gt(calldataload(p), R_MOD_MINUS_ONE) {
+          error_proof_openings_size()
+        }
+
+        // PROOF_OPENING_QCP_AT_ZETA
+
+        p := add(aproof, PROOF_OPENING_QCP_AT_ZETA)
+        for {let i:=0} lt(i, VK_NB_CUSTOM_GATES) {i:=add(i,1)}
+        {
+          if gt(calldataload(p), R_MOD_MINUS_ONE) {
+            error_proof_openings_size()
+          }
+          p := add(p, 0x20)
+        }
+        {{ if spansTwoRows }}
+        // PROOF_L_AT_ZETA_OMEGA, PROOF_R_AT_ZETA_OMEGA, PROOF_O_AT_ZETA_OMEGA
+        p := add(aproof, PROOF_L_AT_ZETA_OMEGA)
+        for {let i:=0} lt(i, 3) {i:=add(i,1)}
+        {
+          if gt(calldataload(p), R_MOD_MINUS_ONE) {
+            error_proof_openings_size()
+          }
+          p := add(p, 0x20)
+        }
+        {{ end }}
+      }
+      // end checks -------------------------------------------------
+
+      // Beginning challenges -------------------------------------------------
+
+      /// Derive gamma as Sha256(<transcript>)
+      /// @param aproof pointer to the proof
+      /// @param nb_pi number of public inputs
+      /// @param pi pointer to the array of public inputs
+      /// @return the challenge gamma, not reduced
+      /// @notice The transcript is the concatenation (in this order) of:
+      /// * the word "gamma" in ascii, equal to [0x67,0x61,0x6d, 0x6d, 0x61] and encoded as a uint256.
+      /// * the commitments to the permutation polynomials S1, S2, S3, where we concatenate the coordinates of those points
+      /// * the commitments of Ql, Qr, Qm, Qo, Qk
+      /// * the commitments of the selectors Qcp_i, then of the selectors of the custom gates Qg_i
+      /// * the public inputs
+      /// * the commitments of the wires related to the custom gates (commitments_wires_commit_api)
+      /// * commitments to L, R, O (proof_<l,r,o>_com)
+      /// The points are written as in gnark's transcript, i.e. the 48 bytes of
+      /// each coordinate (see store_point_raw).
+      /// The data described above is written starting at mPtr. "gamma" lies on 5 bytes,
+      /// and is encoded as a uint256 number n. In basis b = 256, the number looks like this
+      /// [0 0 0 .. 0x67 0x61 0x6d, 0x6d, 0x61]. The first non zero entry is at position 27=0x1b
+      /// Gamma reduced (the actual challenge) is stored at add(state, state_gamma)
+      function derive_gamma(aproof, nb_pi, pi)->gamma_not_reduced {
+
+        let state := mload(0x40)
+        let mPtr := add(state, STATE_LAST_MEM)
+
+        // gamma
+        // gamma in ascii is [0x67,0x61,0x6d, 0x6d, 0x61]
+        // (same for alpha, beta, zeta)
+        mstore(mPtr, 0x67616d6d61) // "gamma"
+
+        let _mPtr := add(mPtr, 0x20)
+        _mPtr := store_point_raw(_mPtr, VK_S1_COM_X_HI, VK_S1_COM_X_LO, VK_S1_COM_Y_HI, VK_S1_COM_Y_LO)
+        _mPtr := store_point_raw(_mPtr, VK_S2_COM_X_HI, VK_S2_COM_X_LO, VK_S2_COM_Y_HI, VK_S2_COM_Y_LO)
+        _mPtr := store_point_raw(_mPtr, VK_S3_COM_X_HI, VK_S3_COM_X_LO, VK_S3_COM_Y_HI, VK_S3_COM_Y_LO)
+        _mPtr := store_point_raw(_mPtr, VK_QL_COM_X_HI, VK_QL_COM_X_LO, VK_QL_COM_Y_HI, VK_QL_COM_Y_LO)
+        _mPtr := store_point_raw(_mPtr, VK_QR_COM_X_HI, VK_QR_COM_X_LO, VK_QR_COM_Y_HI, VK_QR_COM_Y_LO)
+        _mPtr := store_point_raw(_mPtr, VK_QM_COM_X_HI, VK_QM_COM_X_LO, VK_QM_COM_Y_HI, VK_QM_COM_Y_LO)
+        _mPtr := store_point_raw(_mPtr, VK_QO_COM_X_HI, VK_QO_COM_X_LO, VK_QO_COM_Y_HI, VK_QO_COM_Y_LO)
+        _mPtr := store_point_raw(_mPtr, VK_QK_COM_X_HI, VK_QK_COM_X_LO, VK_QK_COM_Y_HI, VK_QK_COM_Y_LO)
+        {{ range $index, $element := .CommitmentConstraintIndexes}}
+        _mPtr := store_point_raw(_mPtr, VK_QCP_{{ $index }}_X_HI, VK_QCP_{{ $index }}_X_LO, VK_QCP_{{ $index }}_Y_HI, VK_QCP_{{ $index }}_Y_LO)
+        {{ end }}
+        {{- range $index, $element := .Qg }}
+        _mPtr := store_point_raw(_mPtr, VK_QG_{{ $index }}_X_HI, VK_QG_{{ $index }}_X_LO, VK_QG_{{ $index }}_Y_HI, VK_QG_{{ $index }}_Y_LO)
+        {{ end }}
+        // public inputs
+        let size_pi_in_bytes := mul(nb_pi, 0x20)
+        calldatacopy(_mPtr, pi, size_pi_in_bytes)
+        _mPtr := add(_mPtr, size_pi_in_bytes)
+
+        // commitments to l, r, o
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_L_COM))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_R_COM))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_O_COM))
+
+        let start := add(mPtr, 0x1b) // 0x1b -> 000.."gamma"
+        let l_success := staticcall(gas(), 0x2, start, sub(_mPtr, start), mPtr, 0x20)
+        if iszero(l_success) {
+          error_verify()
+        }
+        gamma_not_reduced := mload(mPtr)
+        mstore(add(state, STATE_GAMMA), mod(gamma_not_reduced, R_MOD))
+      }
+
+      /// derive beta as Sha256<transcript>
+      /// @param gamma_not_reduced the previous challenge (gamma) not reduced
+      /// @return beta_not_reduced the next challenge, beta, not reduced
+      /// @notice the transcript consists of the previous challenge only.
+      /// The reduced version of beta is stored at add(state, state_beta)
+      function derive_beta(gamma_not_reduced)->beta_not_reduced{
+
+        let state := mload(0x40)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+
+        // beta
+        mstore(mPtr, 0x62657461) // "beta"
+        mstore(add(mPtr, 0x20), gamma_not_reduced)
+        let l_success := staticcall(gas(), 0x2, add(mPtr, 0x1c), 0x24, mPtr, 0x20) //0x1b -> 000.."gamma"
+        if iszero(l_success) {
+          error_verify()
+        }
+        beta_not_reduced := mload(mPtr)
+        mstore(add(state, STATE_BETA), mod(beta_not_reduced, R_MOD))
+      }
+
+      /// derive alpha as sha256<transcript>
+      /// @param aproof pointer to the proof object
+      /// @param beta_not_reduced the previous challenge (beta) not reduced
+      /// @return alpha_not_reduced the next challenge, alpha, not reduced
+      /// @notice the transcript consists of the previous challenge (beta)
+      /// not reduced, the commitments to the wires associated to the QCP_i,
+      /// and the commitment to the grand product polynomial
+      function derive_alpha(aproof, beta_not_reduced)->alpha_not_reduced {
+
+        let state := mload(0x40)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+
+        // alpha
+        mstore(mPtr, 0x616C706861) // "alpha"
+        mstore(add(mPtr, 0x20), beta_not_reduced)
+        let _mPtr := add(mPtr, 0x40)
+
+        // Bsb22Commitments
+        let proof_bsb_commitments := add(aproof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES)
+        for {let i:=0} lt(i, VK_NB_CUSTOM_GATES) {i:=add(i,1)}
+        {
+          _mPtr := store_point_raw_calldata(_mPtr, proof_bsb_commitments)
+          proof_bsb_commitments := add(proof_bsb_commitments, 0x80)
+        }
+
+        // [Z], the commitment to the grand product polynomial
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_GRAND_PRODUCT_COMMITMENT))
+
+        let start := add(mPtr, 0x1b) // 0x1b -> 000.."alpha"
+        let l_success := staticcall(gas(), 0x2, start, sub(_mPtr, start), mPtr, 0x20)
+        if iszero(l_success) {
+          error_verify()
+        }
+
+        alpha_not_reduced := mload(mPtr)
+        mstore(add(state, STATE_ALPHA), mod(alpha_not_reduced, R_MOD))
+      }
+
+      /// derive zeta as sha256<transcript>
+      /// @param aproof pointer to the proof object
+      /// @param alpha_not_reduced the previous challenge (alpha) not reduced
+      /// The transcript consists of the previous challenge and the commitment to
+      /// the quotient polynomial h.
+      function derive_zeta(aproof, alpha_not_reduced) {
+
+        let state := mload(0x40)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+
+        // zeta
+        mstore(mPtr, 0x7a657461) // "zeta"
+        mstore(add(mPtr, 0x20), alpha_not_reduced)
+        let _mPtr := store_point_raw_calldata(add(mPtr, 0x40), add(aproof, PROOF_H_0))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_H_1))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_H_2))
+        let l_success := staticcall(gas(), 0x2, add(mPtr, 0x1c), 0x144, mPtr, 0x20)
+        if iszero(l_success) {
+          error_verify()
+        }
+        let zeta_not_reduced := mload(mPtr)
+        mstore(add(state, STATE_ZETA), mod(zeta_not_reduced, R_MOD))
+      }
+      // END challenges -------------------------------------------------
+
+      // BEGINNING compute_pi -------------------------------------------------
+
+      /// sum_pi_wo_api_commit computes the public inputs contributions,
+      /// except for the public inputs coming from the custom gate
+      /// @param ins pointer to the public inputs
+      /// @param n number of public inputs
+      /// @param mPtr free memory
+      /// @return pi_wo_commit public inputs contribution (except the public inputs coming from the custom gate)
+      function sum_pi_wo_api_commit(ins, n, mPtr)->pi_wo_commit {
+
+        let state := mload(0x40)
+        let z := mload(add(state, STATE_ZETA))
+        let zpnmo := mload(add(state, STATE_ZETA_POWER_N_MINUS_ONE))
+
+        let li := mPtr
+        batch_compute_lagranges_at_z(z, zpnmo, n, li)
+
+        let tmp := 0
+        for {let i:=0} lt(i,n) {i:=add(i,1)}
+        {
+          tmp := mulmod(mload(li), calldataload(ins), R_MOD)
+          pi_wo_commit := addmod(pi_wo_commit, tmp, R_MOD)
+          li := add(li, 0x20)
+          ins := add(ins, 0x20)
+        }
+
+      }
+
+      /// batch_compute_lagranges_at_z computes [L_0(z), .., L_{n-1}(z)]
+      /// @param z point at which the Lagranges are evaluated
+      /// @param zpnmo ζⁿ-1
+      /// @param n number of public inputs (number of Lagranges to compute)
+      /// @param mPtr pointer to which the results are stored
+      function batch_compute_lagranges_at_z(z, zpnmo, n, mPtr) {
+
+        let zn := mulmod(zpnmo, VK_INV_DOMAIN_SIZE, R_MOD) // 1/n * (ζⁿ - 1)
+
+        let _w := 1
+        let _mPtr := mPtr
+        for {let i:=0} lt(i,n) {i:=add(i,1)}
+        {
+          mstore(_mPtr, addmod(z,sub(R_MOD, _w), R_MOD))
+          _w := mulmod(_w, VK_OMEGA, R_MOD)
+          _mPtr := add(_mPtr, 0x20)
+        }
+        batch_invert(mPtr, n, _mPtr)
+        _mPtr := mPtr
+        _w := 1
+        for {let i:=0} lt(i,n) {i:=add(i,1)}
+        {
+          mstore(_mPtr, mulmod(mulmod(mload(_mPtr), zn , R_MOD), _w, R_MOD))
+          _mPtr := add(_mPtr, 0x20)
+          _w := mulmod(_w, VK_OMEGA, R_MOD)
+        }
+      }
+
+      /// @notice Montgomery trick for batch inversion mod R_MOD
+      /// @param ins pointer to the data to batch invert
+      /// @param number of elements to batch invert
+      /// @param mPtr free memory
+      function batch_invert(ins, nb_ins, mPtr) {
+        mstore(mPtr, 1)
+        let offset := 0
+        for {let i:=0} lt(i, nb_ins) {i:=add(i,1)}
+        {
+          let prev := mload(add(mPtr, offset))
+          let cur := mload(add(ins, offset))
+          cur := mulmod(prev, cur, R_MOD)
+          offset := add(offset, 0x20)
+          mstore(add(mPtr, offset), cur)
+        }
+        ins := add(ins, sub(offset, 0x20))
+        mPtr := add(mPtr, offset)
+        let inv := pow(mload(mPtr), sub(R_MOD,2), add(mPtr, 0x20))
+        for {let i:=0} lt(i, nb_ins) {i:=add(i,1)}
+        {
+          mPtr := sub(mPtr, 0x20)
+          let tmp := mload(ins)
+          let cur := mulmod(inv, mload(mPtr), R_MOD)
+          mstore(ins, cur)
+          inv := mulmod(inv, tmp, R_MOD)
+          ins := sub(ins, 0x20)
+        }
+      }
+
+      {{ if (gt (len .CommitmentConstraintIndexes) 0 )}}
+      /// Public inputs (the ones coming from the custom gate) contribution
+      /// @param aproof pointer to the proof
+      /// @param nb_public_inputs number of public inputs
+      /// @param mPtr pointer to free memory
+      /// @return pi_commit custom gate public inputs contribution
+      function sum_pi_commit(aproof, nb_public_inputs, mPtr)->pi_commit {
+
+        let state := mload(0x40)
+        let z := mload(add(state, STATE_ZETA))
+        let zpnmo := mload(add(state, STATE_ZETA_POWER_N_MINUS_ONE))
+
+        let p := add(aproof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES)
+
+        let h_fr, ith_lagrange
+
+        {{ range $index, $element := .CommitmentConstraintIndexes}}
+        h_fr := hash_fr(p, mPtr)
+        ith_lagrange := compute_ith_lagrange_at_z(z, zpnmo, add(nb_public_inputs, VK_INDEX_COMMIT_API{{ $index }}), mPtr)
+        pi_commit := addmod(pi_commit, mulmod(h_fr, ith_lagrange, R_MOD), R_MOD)
+        p := add(p, 0x80)
+        {{ end }}
+
+      }
+
+      /// Computes L_i(zeta) =  ωⁱ/n * (ζⁿ-1)/(ζ-ωⁱ) where:
+      /// @param z zeta
+      /// @param zpmno ζⁿ-1
+      /// @param i i-th lagrange
+      /// @param mPtr free memory
+      /// @return res = ωⁱ/n * (ζⁿ-1)/(ζ-ωⁱ)
+      function compute_ith_lagrange_at_z(z, zpnmo, i, mPtr)->res {
+
+        let w := pow(VK_OMEGA, i, mPtr) // w**i
+        i := addmod(z, sub(R_MOD, w), R_MOD) // z-w**i
+        w := mulmod(w, VK_INV_DOMAIN_SIZE, R_MOD) // w**i/n
+        i := pow(i, sub(R_MOD,2), mPtr) // (z-w**i)**-1
+        w := mulmod(w, i, R_MOD) // w**i/n*(z-w)**-1
+        res := mulmod(w, zpnmo, R_MOD)
+
+      }
+
+      /// @dev https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-06#section-5.2
+      /// @param p pointer to a point on Bls12381(𝔽_p) on calldata
+      /// @param mPtr free memory
+      /// @return res an element mod R_MOD
+      function hash_fr(p, mPtr)->res {
+
+        // [0x00, .. , 0x00 || x, y, || 0, 48, 0, dst, HASH_FR_SIZE_DOMAIN]
+        // <-  64 bytes  ->  <-96b -> <-       1 bytes each     ->
+
+        // [0x00, .., 0x00] 64 bytes of zero
+        mstore(mPtr, HASH_FR_ZERO_UINT256)
+        mstore(add(mPtr, 0x20), HASH_FR_ZERO_UINT256)
+
+        // msg =  x || y , both on 48 bytes
+        pop(store_point_raw_calldata(add(mPtr, 0x40), p))
+
+        // 0 || 48 || 0 all on 1 byte
+        mstore8(add(mPtr, 0xa0), 0)
+        mstore8(add(mPtr, 0xa1), HASH_FR_LEN_IN_BYTES)
+        mstore8(add(mPtr, 0xa2), 0)
+
+        // "BSB22-Plonk" = [42, 53, 42, 32, 32, 2d, 50, 6c, 6f, 6e, 6b,]
+        mstore8(add(mPtr, 0xa3), 0x42)
+        mstore8(add(mPtr, 0xa4), 0x53)
+        mstore8(add(mPtr, 0xa5), 0x42)
+        mstore8(add(mPtr, 0xa6), 0x32)
+        mstore8(add(mPtr, 0xa7), 0x32)
+        mstore8(add(mPtr, 0xa8), 0x2d)
+        mstore8(add(mPtr, 0xa9), 0x50)
+        mstore8(add(mPtr, 0xaa), 0x6c)
+        mstore8(add(mPtr, 0xab), 0x6f)
+        mstore8(add(mPtr, 0xac), 0x6e)
+        mstore8(add(mPtr, 0xad), 0x6b)
+
+        // size domain
+        mstore8(add(mPtr, 0xae), HASH_FR_SIZE_DOMAIN)
+
+        let l_success := staticcall(gas(), 0x2, mPtr, 0xaf, mPtr, 0x20)
+        if iszero(l_success) {
+          error_verify()
+        }
+
+        let b0 := mload(mPtr)
+
+        // [b0         || one || dst || HASH_FR_SIZE_DOMAIN]
+        // <-64bytes ->  <-    1 byte each      ->
+        mstore8(add(mPtr, 0x20), HASH_FR_ONE) // 1
+
+        mstore8(add(mPtr, 0x21), 0x42) // dst
+        mstore8(add(mPtr, 0x22), 0x53)
+        mstore8(add(mPtr, 0x23), 0x42)
+        mstore8(add(mPtr, 0x24), 0x32)
+        mstore8(add(mPtr, 0x25), 0x32)
+        mstore8(add(mPtr, 0x26), 0x2d)
+        mstore8(add(mPtr, 0x27), 0x50)
+        mstore8(add(mPtr, 0x28), 0x6c)
+        mstore8(add(mPtr, 0x29), 0x6f)
+        mstore8(add(mPtr, 0x2a), 0x6e)
+        mstore8(add(mPtr, 0x2b), 0x6b)
+
+        mstore8(add(mPtr, 0x2c), HASH_FR_SIZE_DOMAIN) // size domain
+        l_success := staticcall(gas(), 0x2, mPtr, 0x2d, mPtr, 0x20)
+        if iszero(l_success) {
+          error_verify()
+        }
+
+        // b1 is located at mPtr. We store b2 at add(mPtr, 0x20)
+
+        // [b0^b1      || two || dst || HASH_FR_SIZE_DOMAIN]
+        // <-64bytes ->  <-    1 byte each      ->
+        mstore(add(mPtr, 0x20), xor(mload(mPtr), b0))
+        mstore8(add(mPtr, 0x40), HASH_FR_TWO)
+
+        mstore8(add(mPtr, 0x41), 0x42) // dst
+        mstore8(add(mPtr, 0x42), 0x53)
+        mstore8(add(mPtr, 0x43), 0x42)
+        mstore8(add(mPtr, 0x44), 0x32)
+        mstore8(add(mPtr, 0x45), 0x32)
+        mstore8(add(mPtr, 0x46), 0x2d)
+        mstore8(add(mPtr, 0x47), 0x50)
+        mstore8(add(mPtr, 0x48), 0x6c)
+        mstore8(add(mPtr, 0x49), 0x6f)
+        mstore8(add(mPtr, 0x4a), 0x6e)
+        mstore8(add(mPtr, 0x4b), 0x6b)
+
+        mstore8(add(mPtr, 0x4c), HASH_FR_SIZE_DOMAIN) // size domain
+
+        let offset := add(mPtr, 0x20)
+        l_success := staticcall(gas(), 0x2, offset, 0x2d, offset, 0x20)
+        if iszero(l_success) {
+          error_verify()
+        }
+
+        // at this point we have mPtr = [ b1 || b2] where b1 is on 32byes and b2 in 16bytes.
+        // we interpret it as a big integer mod r in big endian (similar to regular decimal notation)
+        // the result is then 2**(8*16)*mPtr[32:] + mPtr[32:48]
+        res := mulmod(mload(mPtr), HASH_FR_BB, R_MOD) // <- res = 2**128 * mPtr[:32]
+        let b1 := shr(128, mload(add(mPtr, 0x20))) // b1 <- [0, 0, .., 0 ||  b2[:16] ]
+        res := addmod(res, b1, R_MOD)
+
+      }
+      {{ end }}
+      // END compute_pi -------------------------------------------------
+
+      /// @notice compute α² * 1/n * (ζ{n}-1)/(ζ - 1) where
+      /// *  α = challenge derived in derive_gamma_beta_alpha_zeta
+      /// * n = vk_domain_size
+      /// * ω = vk_omega (generator of the multiplicative cyclic group of order n in (ℤ/rℤ)*)
+      /// * ζ = zeta (challenge derived with Fiat Shamir)
+      function compute_alpha_square_lagrange_0() {
+        let state := mload(0x40)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+
+        let res := mload(add(state, STATE_ZETA_POWER_N_MINUS_ONE))
+        let den := addmod(mload(add(state, STATE_ZETA)), sub(R_MOD, 1), R_MOD)
+        den := pow(den, sub(R_MOD, 2), mPtr)
+        den := mulmod(den, VK_INV_DOMAIN_SIZE, R_MOD)
+        res := mulmod(den, res, R_MOD)
+
+        let l_alpha := mload(add(state, STATE_ALPHA))
+        res := mulmod(res, l_alpha, R_MOD)
+        res := mulmod(res, l_alpha, R_MOD)
+        mstore(add(state, STATE_ALPHA_SQUARE_LAGRANGE_0), res)
+      }
+
+      /// @notice follows alg. p.13 of https://eprint.iacr.org/2019/953.pdf
+      /// with t₁ = t₂ = 1, and the proofs are ([digest] + [quotient] +purported evaluation):
+      /// * [state_folded_state_digests], [proof_batch_opening_at_zeta], state_folded_evals
+      /// * [proof_grand_product_commitment], [proof_opening_at_zeta_omega], [proof_grand_product_at_zeta_omega]
+      {{- if spansTwoRows }}
+      /// * [state_folded_lro], [proof_opening_lro_at_zeta_omega], state_folded_lro_claimed_value
+      {{- end }}
+      /// @param aproof pointer to the proof
+      function batch_verify_multi_points(aproof) {
+        let state := mload(0x40)
+        let mPtr := add(state, STATE_LAST_MEM)
+
+        // derive a random number. As there is no random generator, we
+        // do an FS like challenge derivation, depending on both digests and
+        // ζ to ensure that the prover cannot control the random numger.
+        // Note: adding the other point ζω is not needed, as ω is known beforehand.
+        copy_point(mPtr, add(state, STATE_FOLDED_DIGESTS))
+        calldatacopy(add(mPtr, 0x80), add(aproof, PROOF_BATCH_OPENING_AT_ZETA), 0x80)
+        calldatacopy(add(mPtr, 0x100), add(aproof, PROOF_GRAND_PRODUCT_COMMITMENT), 0x80)
+        calldatacopy(add(mPtr, 0x180), add(aproof, PROOF_OPENING_AT_ZETA_OMEGA), 0x80)
+        mstore(add(mPtr, 0x200), mload(add(state, STATE_ZETA)))
+        mstore(add(mPtr, 0x220), mload(add(state, STATE_GAMMA_KZG)))
+        {{ if spansTwoRows -}}
+        calldatacopy(add(mPtr, 0x240), add(aproof, PROOF_OPENING_LRO_AT_ZETA_OMEGA), 0x80)
+        let random := staticcall(gas(), 0x2, mPtr, 0x2c0, mPtr, 0x20)
+        {{ else -}}
+        let random := staticcall(gas(), 0x2, mPtr, 0x240, mPtr, 0x20)
+        {{ end -}}
+        if iszero(random){
+          error_random_generation()
+        }
+        random := mod(mload(mPtr), R_MOD) // use the same variable as we are one variable away from getting stack-too-deep error...
+
+        // [Wζ] + random*[Wζω]{{ if spansTwoRows }} + random²*[Wlro]{{ end }}
+        let folded_quotients := mPtr
+        mPtr := add(folded_quotients, 0x80)
+        let _mPtr := calldata_msm_pair(mPtr, add(aproof, PROOF_BATCH_OPENING_AT_ZETA), 1)
+        {{ if spansTwoRows -}}
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_OPENING_AT_ZETA_OMEGA), random)
+        pop(calldata_msm_pair(_mPtr, add(aproof, PROOF_OPENING_LRO_AT_ZETA_OMEGA), mulmod(random, random, R_MOD)))
+        msm(folded_quotients, mPtr, 3)
+        {{ else -}}
+        pop(calldata_msm_pair(_mPtr, add(aproof, PROOF_OPENING_AT_ZETA_OMEGA), random))
+        msm(folded_quotients, mPtr, 2)
+        {{ end }}
+        let folded_evals := add(state, STATE_FOLDED_CLAIMED_VALUES)
+        fr_acc_mul_calldata(folded_evals, add(aproof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA), random)
+        {{ if spansTwoRows -}}
+        mstore(folded_evals, addmod(mload(folded_evals), mulmod(mload(add(state, STATE_FOLDED_LRO_CLAIMED_VALUE)), mulmod(random, random, R_MOD), R_MOD), R_MOD))
+        {{ end -}}
+
+        // [folded_digests] + random*[Z] - folded_evals*[1] + ζ*[Wζ] + random*ζω*[Wζω]
+        {{- if spansTwoRows }}
+        // + random²*[folded_lro] + random²*ζω*[Wlro]
+        {{- end }}
+        // the subtraction is done with the scalar r - folded_evals, which the
+        // MSM precompile accepts even when folded_evals is 0.
+        _mPtr := memory_msm_pair(mPtr, add(state, STATE_FOLDED_DIGESTS), 1)
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_GRAND_PRODUCT_COMMITMENT), random)
+        _mPtr := store_msm_pair(_mPtr, G1_SRS_X_HI, G1_SRS_X_LO, G1_SRS_Y_HI, G1_SRS_Y_LO, sub(R_MOD, mload(folded_evals)))
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_BATCH_OPENING_AT_ZETA), mload(add(state, STATE_ZETA)))
+        {{ if spansTwoRows -}}
+        _mPtr := memory_msm_pair(_mPtr, add(state, STATE_FOLDED_LRO), mulmod(random, random, R_MOD))
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_OPENING_LRO_AT_ZETA_OMEGA), mulmod(mulmod(random, random, R_MOD), mulmod(mload(add(state, STATE_ZETA)), VK_OMEGA, R_MOD), R_MOD))
+        {{ end -}}
+        random := mulmod(random, mulmod(mload(add(state, STATE_ZETA)), VK_OMEGA, R_MOD), R_MOD)
+        pop(calldata_msm_pair(_mPtr, add(aproof, PROOF_OPENING_AT_ZETA_OMEGA), random))
+        msm(add(state, STATE_FOLDED_DIGESTS), mPtr, {{ if spansTwoRows }}7{{ else }}5{{ end }})
+
+        // e([folded_digests], [1]₂)⋅e([folded_quotients], -[τ]₂) = 1
+        copy_point(mPtr, add(state, STATE_FOLDED_DIGESTS))
+        mstore(add(mPtr, 0x80), G2_SRS_0_X_0_HI) // the 8 words are the canonical G2 point on BLS12-381
+        mstore(add(mPtr, 0xa0), G2_SRS_0_X_0_LO)
+        mstore(add(mPtr, 0xc0), G2_SRS_0_X_1_HI)
+        mstore(add(mPtr, 0xe0), G2_SRS_0_X_1_LO)
+        mstore(add(mPtr, 0x100), G2_SRS_0_Y_0_HI)
+        mstore(add(mPtr, 0x120), G2_SRS_0_Y_0_LO)
+        mstore(add(mPtr, 0x140), G2_SRS_0_Y_1_HI)
+        mstore(add(mPtr, 0x160), G2_SRS_0_Y_1_LO)
+        copy_point(add(mPtr, 0x180), folded_quotients)
+        mstore(add(mPtr, 0x200), G2_SRS_1_NEG_X_0_HI)
+        mstore(add(mPtr, 0x220), G2_SRS_1_NEG_X_0_LO)
+        mstore(add(mPtr, 0x240), G2_SRS_1_NEG_X_1_HI)
+        mstore(add(mPtr, 0x260), G2_SRS_1_NEG_X_1_LO)
+        mstore(add(mPtr, 0x280), G2_SRS_1_NEG_Y_0_HI)
+        mstore(add(mPtr, 0x2a0), G2_SRS_1_NEG_Y_0_LO)
+        mstore(add(mPtr, 0x2c0), G2_SRS_1_NEG_Y_1_HI)
+        mstore(add(mPtr, 0x2e0), G2_SRS_1_NEG_Y_1_LO)
+        check_pairing_kzg(mPtr)
+      }
+
+      /// @notice check_pairing_kzg checks the result of the final pairing product of the batched
+      /// kzg verification. The purpose of this function is to avoid exhausting the stack
+      /// in the function batch_verify_multi_points.
+      /// @param mPtr pointer storing the tuple of pairs
+      function check_pairing_kzg(mPtr) {
+        let state := mload(0x40)
+
+        let l_success := staticcall(gas(), PRECOMPILE_PAIRING, mPtr, 0x300, 0x00, 0x20)
+        let res_pairing := mload(0x00)
+        let s_success := mload(add(state, STATE_SUCCESS))
+        res_pairing := and(and(res_pairing, l_success), s_success)
+        mstore(add(state, STATE_SUCCESS), res_pairing)
+      }
+
+      /// @notice Fold the opening proofs at ζ:
+      /// * at state+state_folded_digest we store: [H] + γ[Linearised_polynomial]+γ²[L] + γ³[R] + γ⁴[O] + γ⁵[S₁] +γ⁶[S₂] + ∑ᵢγ⁶⁺ⁱ[Pi_{i}]
+      /// * at state+state_folded_claimed_values we store: H(ζ) + γLinearised_polynomial(ζ)+γ²L(ζ) + γ³R(ζ)+ γ⁴O(ζ) + γ⁵S₁(ζ) +γ⁶S₂(ζ) + ∑ᵢγ⁶⁺ⁱPi_{i}(ζ)
+      /// @param aproof pointer to the proof
+      /// acc_gamma stores the γⁱ
+      function fold_state(aproof) {
+
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+        let folded_claimed_values := add(mload(0x40), STATE_FOLDED_CLAIMED_VALUES)
+
+        let l_gamma_kzg := mload(add(mload(0x40), STATE_GAMMA_KZG))
+        let acc_gamma := l_gamma_kzg
+
+        let _mPtr := memory_msm_pair(mPtr, add(mload(0x40), STATE_FOLDED_H), 1)
+        mstore(folded_claimed_values, calldataload(add(aproof, PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA)))
+
+        _mPtr := memory_msm_pair(_mPtr, add(mload(0x40), STATE_LINEARISED_POLYNOMIAL), acc_gamma)
+        fr_acc_mul_calldata(folded_claimed_values, add(aproof, PROOF_LINEARISED_POLYNOMIAL_AT_ZETA), acc_gamma)
+
+        acc_gamma := mulmod(acc_gamma, l_gamma_kzg, R_MOD)
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_L_COM), acc_gamma)
+        fr_acc_mul_calldata(folded_claimed_values, add(aproof, PROOF_L_AT_ZETA), acc_gamma)
+
+        acc_gamma := mulmod(acc_gamma, l_gamma_kzg, R_MOD)
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_R_COM), acc_gamma)
+        fr_acc_mul_calldata(folded_claimed_values, add(aproof, PROOF_R_AT_ZETA), acc_gamma)
+
+        acc_gamma := mulmod(acc_gamma, l_gamma_kzg, R_MOD)
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_O_COM), acc_gamma)
+        fr_acc_mul_calldata(folded_claimed_values, add(aproof, PROOF_O_AT_ZETA), acc_gamma)
+
+        acc_gamma := mulmod(acc_gamma, l_gamma_kzg, R_MOD)
+        _mPtr := store_msm_pair(_mPtr, VK_S1_COM_X_HI, VK_S1_COM_X_LO, VK_S1_COM_Y_HI, VK_S1_COM_Y_LO, acc_gamma)
+        fr_acc_mul_calldata(folded_claimed_values, add(aproof, PROOF_S1_AT_ZETA), acc_gamma)
+
+        acc_gamma := mulmod(acc_gamma, l_gamma_kzg, R_MOD)
+        _mPtr := store_msm_pair(_mPtr, VK_S2_COM_X_HI, VK_S2_COM_X_LO, VK_S2_COM_Y_HI, VK_S2_COM_Y_LO, acc_gamma)
+        fr_acc_mul_calldata(folded_claimed_values, add(aproof, PROOF_S2_AT_ZETA), acc_gamma)
+
+        {{- if (gt (len .CommitmentConstraintIndexes) 0 ) }}
+        let poscaz := add(aproof, PROOF_OPENING_QCP_AT_ZETA)
+        {{ end -}}
+
+        {{ range $index, $element := .CommitmentConstraintIndexes }}
+        acc_gamma := mulmod(acc_gamma, l_gamma_kzg, R_MOD)
+        _mPtr := store_msm_pair(_mPtr, VK_QCP_{{ $index }}_X_HI, VK_QCP_{{ $index }}_X_LO, VK_QCP_{{ $index }}_Y_HI, VK_QCP_{{ $index }}_Y_LO, acc_gamma)
+        fr_acc_mul_calldata(folded_claimed_values, poscaz, acc_gamma)
+        poscaz := add(poscaz, 0x20)
+        {{ end }}
+
+        msm(add(mload(0x40), STATE_FOLDED_DIGESTS), mPtr, add(7, VK_NB_CUSTOM_GATES))
+      }
+
+      {{ if spansTwoRows -}}
+      /// @notice Fold the openings of l, r, o at ζω:
+      /// * at state+state_folded_lro we store: [L] + γ[R] + γ²[O]
+      /// * at state+state_folded_lro_claimed_value we store: l(ζω) + γr(ζω) + γ²o(ζω)
+      /// where γ is derived as in compute_gamma_kzg, from ζω, [L], [R], [O], l(ζω), r(ζω), o(ζω).
+      /// @param aproof pointer to the proof
+      function fold_lro_at_zeta_omega(aproof) {
+
+        let state := mload(0x40)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+        mstore(mPtr, 0x67616d6d61) // "gamma"
+        mstore(add(mPtr, 0x20), mulmod(mload(add(state, STATE_ZETA)), VK_OMEGA, R_MOD))
+        let _mPtr := store_point_raw_calldata(add(mPtr, 0x40), add(aproof, PROOF_L_COM))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_R_COM))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_O_COM))
+        calldatacopy(_mPtr, add(aproof, PROOF_L_AT_ZETA_OMEGA), 0x60)
+        _mPtr := add(_mPtr, 0x60)
+
+        let start_input := add(mPtr, 0x1b) // 00.."gamma"
+        let check_staticcall := staticcall(gas(), 0x2, start_input, sub(_mPtr, start_input), mPtr, 0x20)
+        if iszero(check_staticcall) {
+          error_verify()
+        }
+        let l_gamma := mod(mload(mPtr), R_MOD)
+        let gamma_square := mulmod(l_gamma, l_gamma, R_MOD)
+
+        _mPtr := calldata_msm_pair(mPtr, add(aproof, PROOF_L_COM), 1)
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_R_COM), l_gamma)
+        pop(calldata_msm_pair(_mPtr, add(aproof, PROOF_O_COM), gamma_square))
+        msm(add(state, STATE_FOLDED_LRO), mPtr, 3)
+
+        let folded_claimed_value := add(state, STATE_FOLDED_LRO_CLAIMED_VALUE)
+        mstore(folded_claimed_value, calldataload(add(aproof, PROOF_L_AT_ZETA_OMEGA)))
+        fr_acc_mul_calldata(folded_claimed_value, add(aproof, PROOF_R_AT_ZETA_OMEGA), l_gamma)
+        fr_acc_mul_calldata(folded_claimed_value, add(aproof, PROOF_O_AT_ZETA_OMEGA), gamma_square)
+      }
+
+      {{ end -}}
+      /// @notice generate the challenge (using Fiat Shamir) to fold the opening proofs
+      /// at ζ.
+      /// The process for deriving γ is the same as in derive_gamma but this time the inputs are
+      /// in this order (the [] means it's a commitment):
+      /// * ζ
+      /// * [H] ( = H₁ + ζᵐ⁺²*H₂ + ζ²⁽ᵐ⁺²⁾*H₃ )
+      /// * [Linearised polynomial]
+      /// * [L], [R], [O]
+      /// * [S₁] [S₂]
+      /// * [Pi_{i}] (wires associated to custom gates)
+      /// Then there are the purported evaluations of the previous committed polynomials:
+      /// * H(ζ)
+      /// * Linearised_polynomial(ζ)
+      /// * L(ζ), R(ζ), O(ζ), S₁(ζ), S₂(ζ)
+      /// * Pi_{i}(ζ)
+      /// * Z(ζω)
+      {{- if spansTwoRows }}
+      /// * l(ζω), r(ζω), o(ζω)
+      {{- end }}
+      /// @param aproof pointer to the proof
+      function compute_gamma_kzg(aproof) {
+
+        let state := mload(0x40)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+        mstore(mPtr, 0x67616d6d61) // "gamma"
+        mstore(add(mPtr, 0x20), mload(add(state, STATE_ZETA)))
+        let _mPtr := store_point_raw_mem(add(mPtr, 0x40), add(state, STATE_FOLDED_H))
+        _mPtr := store_point_raw_mem(_mPtr, add(state, STATE_LINEARISED_POLYNOMIAL))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_L_COM))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_R_COM))
+        _mPtr := store_point_raw_calldata(_mPtr, add(aproof, PROOF_O_COM))
+        _mPtr := store_point_raw(_mPtr, VK_S1_COM_X_HI, VK_S1_COM_X_LO, VK_S1_COM_Y_HI, VK_S1_COM_Y_LO)
+        _mPtr := store_point_raw(_mPtr, VK_S2_COM_X_HI, VK_S2_COM_X_LO, VK_S2_COM_Y_HI, VK_S2_COM_Y_LO)
+        {{ range $index, $element := .CommitmentConstraintIndexes }}
+        _mPtr := store_point_raw(_mPtr, VK_QCP_{{ $index }}_X_HI, VK_QCP_{{ $index }}_X_LO, VK_QCP_{{ $index }}_Y_HI, VK_QCP_{{ $index }}_Y_LO)
+        {{ end }}
+
+        mstore(_mPtr, calldataload(add(aproof, PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA)))
+        mstore(add(_mPtr, 0x20), calldataload(add(aproof, PROOF_LINEARISED_POLYNOMIAL_AT_ZETA)))
+        mstore(add(_mPtr, 0x40), calldataload(add(aproof, PROOF_L_AT_ZETA)))
+        mstore(add(_mPtr, 0x60), calldataload(add(aproof, PROOF_R_AT_ZETA)))
+        mstore(add(_mPtr, 0x80), calldataload(add(aproof, PROOF_O_AT_ZETA)))
+        mstore(add(_mPtr, 0xa0), calldataload(add(aproof, PROOF_S1_AT_ZETA)))
+        mstore(add(_mPtr, 0xc0), calldataload(add(aproof, PROOF_S2_AT_ZETA)))
+        _mPtr := add(_mPtr, 0xe0)
+
+        let _poscaz := add(aproof, PROOF_OPENING_QCP_AT_ZETA)
+        for {let i:=0} lt(i, VK_NB_CUSTOM_GATES) {i:=add(i,1)}
+        {
+          mstore(_mPtr, calldataload(_poscaz))
+          _poscaz := add(_poscaz, 0x20)
+          _mPtr := add(_mPtr, 0x20)
+        }
+
+        mstore(_mPtr, calldataload(add(aproof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA)))
+        _mPtr := add(_mPtr, 0x20)
+        {{- if spansTwoRows }}
+        calldatacopy(_mPtr, add(aproof, PROOF_L_AT_ZETA_OMEGA), 0x60)
+        _mPtr := add(_mPtr, 0x60)
+        {{- end }}
+
+        let start_input := add(mPtr, 0x1b) // 00.."gamma"
+        let check_staticcall := staticcall(gas(), 0x2, start_input, sub(_mPtr, start_input), add(state, STATE_GAMMA_KZG), 0x20)
+        if iszero(check_staticcall) {
+          error_verify()
+        }
+        mstore(add(state, STATE_GAMMA_KZG), mod(mload(add(state, STATE_GAMMA_KZG)), R_MOD))
+      }
+
+      /// @notice compute the commitment to the linearised polynomial as a single MSM of
+      /// [Qₗ], [Qᵣ], [Qₘ], [Qₒ], [Qₖ], [BsbCommitmentᵢ], [S₃], [Z]{{ if (gt (len .Qg) 0 ) }}, [Qgᵢ]{{ end }}
+      /// @param aproof pointer to the proof
+      /// @param s1 scalar of [S₃]
+      /// @param s2 scalar of [Z]
+      function compute_commitment_linearised_polynomial_ec(aproof, s1, s2) {
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+
+        let _mPtr := store_msm_pair(mPtr, VK_QL_COM_X_HI, VK_QL_COM_X_LO, VK_QL_COM_Y_HI, VK_QL_COM_Y_LO, calldataload(add(aproof, PROOF_L_AT_ZETA)))
+        _mPtr := store_msm_pair(_mPtr, VK_QR_COM_X_HI, VK_QR_COM_X_LO, VK_QR_COM_Y_HI, VK_QR_COM_Y_LO, calldataload(add(aproof, PROOF_R_AT_ZETA)))
+        let rl := mulmod(calldataload(add(aproof, PROOF_L_AT_ZETA)), calldataload(add(aproof, PROOF_R_AT_ZETA)), R_MOD)
+        _mPtr := store_msm_pair(_mPtr, VK_QM_COM_X_HI, VK_QM_COM_X_LO, VK_QM_COM_Y_HI, VK_QM_COM_Y_LO, rl)
+        _mPtr := store_msm_pair(_mPtr, VK_QO_COM_X_HI, VK_QO_COM_X_LO, VK_QO_COM_Y_HI, VK_QO_COM_Y_LO, calldataload(add(aproof, PROOF_O_AT_ZETA)))
+        _mPtr := store_msm_pair(_mPtr, VK_QK_COM_X_HI, VK_QK_COM_X_LO, VK_QK_COM_Y_HI, VK_QK_COM_Y_LO, 1)
+
+        let commits_api_at_zeta := add(aproof, PROOF_OPENING_QCP_AT_ZETA)
+        let commits_api := add(aproof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES)
+        for {
+          let i := 0
+        } lt(i, VK_NB_CUSTOM_GATES) {
+          i := add(i, 1)
+        } {
+          _mPtr := calldata_msm_pair(_mPtr, commits_api, calldataload(commits_api_at_zeta))
+          commits_api_at_zeta := add(commits_api_at_zeta, 0x20)
+          commits_api := add(commits_api, 0x80)
+        }
+
+        _mPtr := store_msm_pair(_mPtr, VK_S3_COM_X_HI, VK_S3_COM_X_LO, VK_S3_COM_Y_HI, VK_S3_COM_Y_LO, s1)
+        {{ if (gt (len .Qg) 0 ) -}}
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_GRAND_PRODUCT_COMMITMENT), s2)
+        store_custom_gates_msm_pairs(aproof, _mPtr)
+        {{ else -}}
+        pop(calldata_msm_pair(_mPtr, add(aproof, PROOF_GRAND_PRODUCT_COMMITMENT), s2))
+        {{ end }}
+        msm(add(mload(0x40), STATE_LINEARISED_POLYNOMIAL), mPtr, add({{ add 7 (len .Qg) }}, VK_NB_CUSTOM_GATES))
+      }
+      {{ if (gt (len .Qg) 0 ) }}
+      /// @notice writes the pairs ([Qgᵢ], gᵢ(ζ)) of the MSM of the linearised polynomial, where
+      /// gᵢ(ζ) is the sum of the identities of the i-th custom gate, evaluated on l(ζ), r(ζ), o(ζ)
+      /// and l(ζω), r(ζω), o(ζω). The j-th identity (counted over all the gates) is scaled by α³⁺ʲ,
+      /// α and α² being used by the permutation argument.
+      /// @param aproof pointer to the proof
+      /// @param dst pointer storing the pairs
+      function store_custom_gates_msm_pairs(aproof, dst) {
+        let l_alpha := mload(add(mload(0x40), STATE_ALPHA))
+        let alpha_power := mulmod(mulmod(l_alpha, l_alpha, R_MOD), l_alpha, R_MOD)
+        let gate, identity
+        {{ range $index, $gate := customGates }}
+        gate := 0
+        {{- range $identity := $gate }}
+        identity := 0
+        {{- range $term := $identity }}
+        identity := addmod(identity, {{ $term }}, R_MOD)
+        {{- end }}
+        gate := addmod(gate, mulmod(identity, alpha_power, R_MOD), R_MOD)
+        alpha_power := mulmod(alpha_power, l_alpha, R_MOD)
+        {{- end }}
+        dst := store_msm_pair(dst, VK_QG_{{ $index }}_X_HI, VK_QG_{{ $index }}_X_LO, VK_QG_{{ $index }}_Y_HI, VK_QG_{{ $index }}_Y_LO, gate)
+        {{ end }}
+      }
+      {{ end }}
+
+      /// @notice Compute the commitment to the linearized polynomial equal to
+      ///	L(ζ)[Qₗ]+r(ζ)[Qᵣ]+R(ζ)L(ζ)[Qₘ]+O(ζ)[Qₒ]+[Qₖ]+Σᵢqc'ᵢ(ζ)[BsbCommitmentᵢ] +
+      ///	α*( Z(μζ)(L(ζ)+β*S₁(ζ)+γ)*(R(ζ)+β*S₂(ζ)+γ)[S₃]-[Z](L(ζ)+β*id_{1}(ζ)+γ)*(R(ζ)+β*id_{2(ζ)+γ)*(O(ζ)+β*id_{3}(ζ)+γ) ) +
+      ///	α²*L₁(ζ)[Z] + Σᵢgᵢ(ζ)[Qgᵢ]
+      /// where
+      /// * gᵢ(ζ) is the i-th custom gate evaluated on the claimed values of the wires, see store_custom_gates_msm_pairs
+      /// * id_1 = id, id_2 = vk_coset_shift*id, id_3 = vk_coset_shift^{2}*id
+      /// * the [] means that it's a commitment (i.e. a point on Bls12381(F_p))
+      /// @param aproof pointer to the proof
+      function compute_commitment_linearised_polynomial(aproof) {
+        let state := mload(0x40)
+        let l_beta := mload(add(state, STATE_BETA))
+        let l_gamma := mload(add(state, STATE_GAMMA))
+        let l_zeta := mload(add(state, STATE_ZETA))
+        let l_alpha := mload(add(state, STATE_ALPHA))
+
+        let u := mulmod(calldataload(add(aproof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA)), l_beta, R_MOD)
+        let v := mulmod(l_beta, calldataload(add(aproof, PROOF_S1_AT_ZETA)), R_MOD)
+        v := addmod(v, calldataload(add(aproof, PROOF_L_AT_ZETA)), R_MOD)
+        v := addmod(v, l_gamma, R_MOD)
+
+        let w := mulmod(l_beta, calldataload(add(aproof, PROOF_S2_AT_ZETA)), R_MOD)
+        w := addmod(w, calldataload(add(aproof, PROOF_R_AT_ZETA)), R_MOD)
+        w := addmod(w, l_gamma, R_MOD)
+
+        let s1 := mulmod(u, v, R_MOD)
+        s1 := mulmod(s1, w, R_MOD)
+        s1 := mulmod(s1, l_alpha, R_MOD)
+
+        let coset_square := mulmod(VK_COSET_SHIFT, VK_COSET_SHIFT, R_MOD)
+        let betazeta := mulmod(l_beta, l_zeta, R_MOD)
+        u := addmod(betazeta, calldataload(add(aproof, PROOF_L_AT_ZETA)), R_MOD)
+        u := addmod(u, l_gamma, R_MOD)
+
+        v := mulmod(betazeta, VK_COSET_SHIFT, R_MOD)
+        v := addmod(v, calldataload(add(aproof, PROOF_R_AT_ZETA)), R_MOD)
+        v := addmod(v, l_gamma, R_MOD)
+
+        w := mulmod(betazeta, coset_square, R_MOD)
+        w := addmod(w, calldataload(add(aproof, PROOF_O_AT_ZETA)), R_MOD)
+        w := addmod(w, l_gamma, R_MOD)
+
+        let s2 := mulmod(u, v, R_MOD)
+        s2 := mulmod(s2, w, R_MOD)
+        s2 := sub(R_MOD, s2)
+        s2 := mulmod(s2, l_alpha, R_MOD)
+        s2 := addmod(s2, mload(add(state, STATE_ALPHA_SQUARE_LAGRANGE_0)), R_MOD)
+
+        // at this stage:
+        // * s₁ = α*Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β
+        // * s₂ = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ) + α²*L₁(ζ)
+
+        compute_commitment_linearised_polynomial_ec(aproof, s1, s2)
+      }
+
+      /// @notice compute H₁ + ζᵐ⁺²*H₂ + ζ²⁽ᵐ⁺²⁾*H₃ and store the result at
+      /// state + state_folded_h
+      /// @param aproof pointer to the proof
+      function fold_h(aproof) {
+        let state := mload(0x40)
+        let n_plus_two := add(VK_DOMAIN_SIZE, 2)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+        let zeta_power_n_plus_two := pow(mload(add(state, STATE_ZETA)), n_plus_two, mPtr)
+        let _mPtr := calldata_msm_pair(mPtr, add(aproof, PROOF_H_0), 1)
+        _mPtr := calldata_msm_pair(_mPtr, add(aproof, PROOF_H_1), zeta_power_n_plus_two)
+        pop(calldata_msm_pair(_mPtr, add(aproof, PROOF_H_2), mulmod(zeta_power_n_plus_two, zeta_power_n_plus_two, R_MOD)))
+        msm(add(state, STATE_FOLDED_H), mPtr, 3)
+      }
+
+      /// @notice check that
+      ///	L(ζ)Qₗ(ζ)+r(ζ)Qᵣ(ζ)+R(ζ)L(ζ)Qₘ(ζ)+O(ζ)Qₒ(ζ)+Qₖ(ζ)+Σᵢqc'ᵢ(ζ)BsbCommitmentᵢ(ζ) +
+      ///  α*( Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β*s₃(X)-Z(X)(l(ζ)+β*id_1(ζ)+γ)*(r(ζ)+β*id_2(ζ)+γ)*(o(ζ)+β*id_3(ζ)+γ) ) )
+      /// + α²*L₁(ζ) =
+      /// (ζⁿ-1)H(ζ)
+      /// @param aproof pointer to the proof
+      function verify_quotient_poly_eval_at_zeta(aproof) {
+        let state := mload(0x40)
+
+        // (l(ζ)+β*s1(ζ)+γ)
+        let s1 := add(mload(0x40), STATE_LAST_MEM)
+        mstore(s1, mulmod(calldataload(add(aproof, PROOF_S1_AT_ZETA)), mload(add(state, STATE_BETA)), R_MOD))
+        mstore(s1, addmod(mload(s1), mload(add(state, STATE_GAMMA)), R_MOD))
+        mstore(s1, addmod(mload(s1), calldataload(add(aproof, PROOF_L_AT_ZETA)), R_MOD))
+
+        // (r(ζ)+β*s2(ζ)+γ)
+        let s2 := add(s1, 0x20)
+        mstore(s2, mulmod(calldataload(add(aproof, PROOF_S2_AT_ZETA)), mload(add(state, STATE_BETA)), R_MOD))
+        mstore(s2, addmod(mload(s2), mload(add(state, STATE_GAMMA)), R_MOD))
+        mstore(s2, addmod(mload(s2), calldataload(add(aproof, PROOF_R_AT_ZETA)), R_MOD))
+        // _s2 := mload(s2)
+
+        // (o(ζ)+γ)
+        let o := add(s1, 0x40)
+        mstore(o, addmod(calldataload(add(aproof, PROOF_O_AT_ZETA)), mload(add(state, STATE_GAMMA)), R_MOD))
+
+        //  α*(Z(μζ))*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*(o(ζ)+γ)
+        mstore(s1, mulmod(mload(s1), mload(s2), R_MOD))
+        mstore(s1, mulmod(mload(s1), mload(o), R_MOD))
+        mstore(s1, mulmod(mload(s1), mload(add(state, STATE_ALPHA)), R_MOD))
+        mstore(s1, mulmod(mload(s1), calldataload(add(aproof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA)), R_MOD))
+
+        let computed_quotient := add(s1, 0x60)
+
+        // linearizedpolynomial + pi(zeta)
+        mstore(computed_quotient,addmod(calldataload(add(aproof, PROOF_LINEARISED_POLYNOMIAL_AT_ZETA)), mload(add(state, STATE_PI)), R_MOD))
+        mstore(computed_quotient, addmod(mload(computed_quotient), mload(s1), R_MOD))
+        mstore(computed_quotient,addmod(mload(computed_quotient), sub(R_MOD, mload(add(state, STATE_ALPHA_SQUARE_LAGRANGE_0))), R_MOD))
+        mstore(s2,mulmod(calldataload(add(aproof, PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA)),mload(add(state, STATE_ZETA_POWER_N_MINUS_ONE)),R_MOD))
+
+        mstore(add(state, STATE_SUCCESS), eq(mload(computed_quotient), mload(s2)))
+      }
+
+      // BEGINNING utils math functions -------------------------------------------------
+
+      /// @notice writes a point at dst, in the encoding of EIP-2537
+      /// @param dst pointer storing the point
+      function store_point(dst, x_hi, x_lo, y_hi, y_lo) {
+        mstore(dst, x_hi)
+        mstore(add(dst, 0x20), x_lo)
+        mstore(add(dst, 0x40), y_hi)
+        mstore(add(dst, 0x60), y_lo)
+      }
+
+      /// @param dst pointer storing the point
+      /// @param src pointer to the point to copy
+      function copy_point(dst, src) {
+        store_point(dst, mload(src), mload(add(src, 0x20)), mload(add(src, 0x40)), mload(add(src, 0x60)))
+      }
+
+      /// @notice writes a point at dst as in gnark's transcripts: the 48 bytes of x
+      /// followed by the 48 bytes of y, without the padding of EIP-2537. The point
+      /// at infinity (encoded with zeroes) is flagged with 0x40 in the first byte.
+      /// @param dst pointer storing the point
+      /// @return next pointer to the end of the written point
+      function store_point_raw(dst, x_hi, x_lo, y_hi, y_lo)->next {
+        mstore(dst, shl(128, x_hi))
+        mstore(add(dst, 0x10), x_lo)
+        mstore(add(dst, 0x30), shl(128, y_hi))
+        mstore(add(dst, 0x40), y_lo)
+        if iszero(or(or(x_hi, x_lo), or(y_hi, y_lo))) {
+          mstore8(dst, 0x40)
+        }
+        next := add(dst, 0x60)
+      }
+
+      /// @param dst pointer storing the point
+      /// @param src pointer to a point in the encoding of EIP-2537
+      /// @return next pointer to the end of the written point
+      function store_point_raw_mem(dst, src)->next {
+        next := store_point_raw(dst, mload(src), mload(add(src, 0x20)), mload(add(src, 0x40)), mload(add(src, 0x60)))
+      }
+
+      /// @param dst pointer storing the point
+      /// @param src pointer to a point in the encoding of EIP-2537 (calldata)
+      /// @return next pointer to the end of the written point
+      function store_point_raw_calldata(dst, src)->next {
+        next := store_point_raw(dst, calldataload(src), calldataload(add(src, 0x20)), calldataload(add(src, 0x40)), calldataload(add(src, 0x60)))
+      }
+
+      /// @notice writes the pair (point, s) of an MSM at dst
+      /// @param dst pointer storing the pair
+      /// @param s scalar
+      /// @return next pointer to the next pair
+      function store_msm_pair(dst, x_hi, x_lo, y_hi, y_lo, s)->next {
+        store_point(dst, x_hi, x_lo, y_hi, y_lo)
+        mstore(add(dst, 0x80), s)
+        next := add(dst, 0xa0)
+      }
+
+      /// @param dst pointer storing the pair
+      /// @param src pointer to the point
+      /// @param s scalar
+      /// @return next pointer to the next pair
+      function memory_msm_pair(dst, src, s)->next {
+        copy_point(dst, src)
+        mstore(add(dst, 0x80), s)
+        next := add(dst, 0xa0)
+      }
+
+      /// @param dst pointer storing the pair
+      /// @param src pointer to the point (calldata)
+      /// @param s scalar
+      /// @return next pointer to the next pair
+      function calldata_msm_pair(dst, src, s)->next {
+        calldatacopy(dst, src, 0x80)
+        mstore(add(dst, 0x80), s)
+        next := add(dst, 0xa0)
+      }
+
+      /// @notice dst <- ∑ᵢ[sᵢ]pᵢ (Elliptic curve)
+      /// The precompile checks that the points are in G1.
+      /// @param dst pointer storing the result
+      /// @param pairs pointer to the n pairs (pᵢ, sᵢ)
+      /// @param n number of pairs
+      function msm(dst, pairs, n) {
+        let l_success := staticcall(gas(), PRECOMPILE_G1_MSM, pairs, mul(n, 0xa0), dst, 0x80)
+        if iszero(l_success) {
+          error_ec_op()
+        }
+      }
+
+      /// @notice dst <- dst + src*s (Fr) dst,src are addresses, s is a value
+      /// @param dst pointer storing the result
+      /// @param src pointer to the scalar to multiply and add (on calldata)
+      /// @param s scalar
+      function fr_acc_mul_calldata(dst, src, s) {
+        let tmp :=  mulmod(calldataload(src), s, R_MOD)
+        mstore(dst, addmod(mload(dst), tmp, R_MOD))
+      }
+
+      /// @param x element to exponentiate
+      /// @param e exponent
+      /// @param mPtr free memory
+      /// @return res x ** e mod r
+      function pow(x, e, mPtr)->res {
+        mstore(mPtr, 0x20)
+        mstore(add(mPtr, 0x20), 0x20)
+        mstore(add(mPtr, 0x40), 0x20)
+        mstore(add(mPtr, 0x60), x)
+        mstore(add(mPtr, 0x80), e)
+        mstore(add(mPtr, 0xa0), R_MOD)
+        let check_staticcall := staticcall(gas(),0x05,mPtr,0xc0,mPtr,0x20)
+        if eq(check_staticcall, 0) {
+          error_verify()
+        }
+        res := mload(mPtr)
+      }
+    }
+  }
+}
+`
+
+// MarshalSolidity converts a proof to a byte array that can be used in a
+// Solidity contract. The points are encoded as in EIP-2537.
+func (proof *Proof) MarshalSolidity() []byte {
+
+	res := make([]byte, 0, 2048)
+
+	// uint256[4] l_com;
+	// uint256[4] r_com;
+	// uint256[4] o_com;
+	for i := 0; i < 3; i++ {
+		res = appendG1Solidity(res, &proof.LRO[i])
+	}
+
+	// uint256[4] h_0;
+	// uint256[4] h_1;
+	// uint256[4] h_2;
+	for i := 0; i < 3; i++ {
+		res = appendG1Solidity(res, &proof.H[i])
+	}
+
+	// uint256 l_at_zeta;
+	// uint256 r_at_zeta;
+	// uint256 o_at_zeta;
+	// uint256 s1_at_zeta;
+	// uint256 s2_at_zeta;
+	for i := 2; i < 7; i++ {
+		res = appendFrSolidity(res, &proof.BatchedProof.ClaimedValues[i])
+	}
+
+	// uint256[4] grand_product_commitment;
+	res = appendG1Solidity(res, &proof.Z)
+
+	// uint256 grand_product_at_zeta_omega;
+	res = appendFrSolidity(res, &proof.ZShiftedOpening.ClaimedValue)
+
+	// uint256 quotient_polynomial_at_zeta;
+	// uint256 linearization_polynomial_at_zeta;
+	res = appendFrSolidity(res, &proof.BatchedProof.ClaimedValues[0])
+	res = appendFrSolidity(res, &proof.BatchedProof.ClaimedValues[1])
+
+	// uint256[4] opening_at_zeta_proof;
+	res = appendG1Solidity(res, &proof.BatchedProof.H)
+
+	// uint256[4] opening_at_zeta_omega_proof;
+	res = appendG1Solidity(res, &proof.ZShiftedOpening.H)
+
+	// uint256[] selector_commit_api_at_zeta;
+	// uint256[4][] wire_committed_commitments;
+	if len(proof.Bsb22Commitments) > 0 {
+		for i := 0; i < len(proof.Bsb22Commitments); i++ {
+			res = appendFrSolidity(res, &proof.BatchedProof.ClaimedValues[7+i])
+		}
+
+		for i := range proof.Bsb22Commitments {
+			res = appendG1Solidity(res, &proof.Bsb22Commitments[i])
+		}
+	}
+
+	// uint256 l_at_zeta_omega;
+	// uint256 r_at_zeta_omega;
+	// uint256 o_at_zeta_omega;
+	// uint256[4] opening_lro_at_zeta_omega_proof;
+	if len(proof.LROShiftedOpening.ClaimedValues) > 0 {
+		for i := range proof.LROShiftedOpening.ClaimedValues {
+			res = appendFrSolidity(res, &proof.LROShiftedOpening.ClaimedValues[i])
+		}
+		res = appendG1Solidity(res, &proof.LROShiftedOpening.H)
+	}
+
+	return res
+}
+
+// appendG1Solidity appends p encoded as in EIP-2537: each coordinate is left
+// padded with zeroes to 64 bytes. The point at infinity is encoded with zeroes.
+func appendG1Solidity(res []byte, p *curve.G1Affine) []byte {
+	var padding [64 - fp.Bytes]byte
+	x, y := p.X.Bytes(), p.Y.Bytes()
+	res = append(res, padding[:]...)
+	res = append(res, x[:]...)
+	res = append(res, padding[:]...)
+	return append(res, y[:]...)
+}
+
+func appendFrSolidity(res []byte, x *fr.Element) []byte {
+	b := x.Bytes()
+	return append(res, b[:]...)
+}
+
+// fpHi returns the first word of the EIP-2537 encoding of x, i.e. its 128 high bits.
+func fpHi(x fp.Element) string {
+	b := x.Bytes()
+	return "0x" + hex.EncodeToString(b[:fp.Bytes-32])
+}
+
+// fpLo returns the second word of the EIP-2537 encoding of x, i.e. its 256 low bits.
+func fpLo(x fp.Element) string {
+	b := x.Bytes()
+	return "0x" + hex.EncodeToString(b[fp.Bytes-32:])
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	gnarkio "github.com/consensys/gnark/io"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPowersOfTau(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	const nContributions = 3
+
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(curve.ID.ScalarField(), scs.NewBuilder, &Circuit{})
+	assert.NoError(err)
+	domainSize := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints() + ccs.GetNbPublicVariables()))
+
+	srs := InitPowersOfTau(int(domainSize) + 3)
+
+	// Make and verify contributions
+	contributions := []*PowersOfTau{}
+	for i := 0; i < nContributions; i++ {
+		// we clone for test purposes; but in practice, participant will receive a []byte, deserialize it,
+		// add his contribution and send back to coordinator.
+		prev := srs.clone()
+		contributions = append(contributions, &prev)
+
+		srs.Contribute()
+		assert.NoError(VerifyPowersOfTau(&prev, &srs))
+	}
+	contributions = append(contributions, &srs)
+	assert.NoError(VerifyPowersOfTau(contributions[0], contributions[1], contributions[2:]...))
+
+	// a contribution must be based on the previous one
+	assert.Error(VerifyPowersOfTau(contributions[0], contributions[2]))
+
+	// a contribution must be made of powers of the same τ
+	tampered := srs.clone()
+	tampered.Parameters.G1.Tau[2], tampered.Parameters.G1.Tau[3] = tampered.Parameters.G1.Tau[3], tampered.Parameters.G1.Tau[2]
+	tampered.Hash = tampered.hash()
+	assert.Error(VerifyPowersOfTau(contributions[nContributions-1], &tampered))
+
+	// Extract the SRS and run plonk
+	canonical, lagrange, err := srs.SRS(domainSize)
+	assert.NoError(err)
+	_, _, err = srs.SRS(2 * domainSize)
+	assert.Error(err)
+
+	pk, vk, err := plonk.Setup(ccs, canonical, lagrange)
+	assert.NoError(err)
+
+	witness, err := frontend.NewWitness(&Circuit{X: 3, Y: 9}, curve.ID.ScalarField())
+	assert.NoError(err)
+	pubWitness, err := witness.Public()
+	assert.NoError(err)
+
+	proof, err := plonk.Prove(ccs, pk, witness)
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, pubWitness))
+}
+
+func TestPowersOfTauSerialization(t *testing.T) {
+	assert := require.New(t)
+
+	srs := InitPowersOfTau(16)
+	srs.Contribute()
+
+	assert.NoError(gnarkio.RoundTripCheck(&srs, func() interface{} { return new(PowersOfTau) }))
+}
+
+func BenchmarkPowersOfTau(b *testing.B) {
+	const size = 1 << 14
+
+	b.Run("init", func(b *testing.B) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = InitPowersOfTau(size)
+		}
+	})
+
+	b.Run("contrib", func(b *testing.B) {
+		srs := InitPowersOfTau(size)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			srs.Contribute()
+		}
+	})
+
+}
+
+// Circuit defines a square root knowledge proof
+type Circuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+// Define declares the circuit's constraints
+// Y = X²
+func (circuit *Circuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(circuit.X, circuit.X), circuit.Y)
+	return nil
+}
+
+func (p *PowersOfTau) clone() PowersOfTau {
+	r := PowersOfTau{}
+	r.Parameters.G1.Tau = append(r.Parameters.G1.Tau, p.Parameters.G1.Tau...)
+	r.Parameters.G2.Tau = p.Parameters.G2.Tau
+	r.PublicKey = p.PublicKey
+	r.Hash = append(r.Hash, p.Hash...)
+
+	return r
+}
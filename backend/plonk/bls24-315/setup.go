@@ -55,6 +55,15 @@ type VerifyingKey struct {
 	Qcp                []kzg.Digest
 
 	CommitmentConstraintIndexes []uint64
+
+	// Commitments to the selectors of the custom gates, and the gates themselves
+	Qg          []kzg.Digest
+	CustomGates []constraint.CustomGate
+
+	// Commitments to the lookup selector, the table index of the lookups, and
+	// the entries of the fixed tables with their table index
+	Qlk, Qlkid, Tval, Tid kzg.Digest
+	NbFixedTables         uint64
 }
 
 // Trace stores a plonk trace as columns
@@ -67,6 +76,16 @@ type Trace struct {
 	Ql, Qr, Qm, Qo, Qk *iop.Polynomial
 	Qcp                []*iop.Polynomial
 
+	// Qg[i] is the selector of the i-th custom gate: it is one on the rows
+	// where the gate applies, zero elsewhere.
+	Qg []*iop.Polynomial
+
+	// Qlk is one on the rows asserting that l is an entry of the fixed table
+	// Qlkid. Tval, Tid are the concatenated entries of the fixed tables and
+	// their table index, padded with the first entry of the first table.
+	// They are nil if the circuit has no fixed table.
+	Qlk, Qlkid, Tval, Tid *iop.Polynomial
+
 	// Polynomials representing the splitted permutation. The full permutation's support is 3*N where N=nb wires.
 	// The set of interpolation is <g> of size N, so to represent the permutation S we let S acts on the
 	// set A=(<g>, u*<g>, u^{2}*<g>) of size 3*N, where u is outside <g> (its use is to shift the set <g>).
@@ -92,6 +111,8 @@ func Setup(spr *cs.SparseR1CS, srs, srsLagrange kzg.SRS) (*ProvingKey, *Verifyin
 	var vk VerifyingKey
 	pk.Vk = &vk
 	vk.CommitmentConstraintIndexes = internal.IntSliceToUint64Slice(spr.CommitmentInfo.CommitmentIndexes())
+	vk.CustomGates = spr.GetCustomGates()
+	vk.NbFixedTables = uint64(len(spr.GetFixedTables()))
 
 	// step 0: set the fft domains
 	domain := initFFTDomain(spr)
@@ -104,6 +125,11 @@ func Setup(spr *cs.SparseR1CS, srs, srsLagrange kzg.SRS) (*ProvingKey, *Verifyin
 		return nil, nil, fmt.Errorf("kzg srs is too small: got %d, need %d", len(srs.Pk.G1), domain.Cardinality+3)
 	}
 
+	// the entries of the fixed tables are laid out along the rows
+	if size := fixedTablesSize(spr); size > int(domain.Cardinality) {
+		return nil, nil, fmt.Errorf("fixed tables have %d entries, more than the %d rows of the circuit", size, domain.Cardinality)
+	}
+
 	// same for the lagrange form
 	if len(srsLagrange.Pk.G1) != int(domain.Cardinality) {
 		return nil, nil, fmt.Errorf("kzg srs lagrange is too small: got %d, need %d", len(srsLagrange.Pk.G1), domain.Cardinality)
@@ -148,7 +174,8 @@ func (pk *ProvingKey) VerifyingKey() interface{} {
 
 // NewTrace returns a new Trace object from the constraint system.
 // It fills the constant columns ql, qr, qm, qo, qk, and qcp with the
-// coefficients of the constraints.
+// coefficients of the constraints, the selectors qg of the custom gates, and
+// the lookup selectors and fixed tables.
 // Size is the size of the system that is next power of 2 (nb_constraints+nb_public_variables)
 // The permutation is also computed and stored in the Trace.
 func NewTrace(spr *cs.SparseR1CS, domain *fft.Domain) *Trace {
@@ -165,6 +192,29 @@ func NewTrace(spr *cs.SparseR1CS, domain *fft.Domain) *Trace {
 	qo := make([]fr.Element, size)
 	qk := make([]fr.Element, size)
 	qcp := make([][]fr.Element, len(commitmentInfo))
+	qg := make([][]fr.Element, len(spr.GetCustomGates()))
+	for i := range qg {
+		qg[i] = make([]fr.Element, size)
+	}
+	tables := spr.GetFixedTables()
+	var qlk, qlkid, tval, tid []fr.Element
+	if len(tables) != 0 {
+		qlk = make([]fr.Element, size)
+		qlkid = make([]fr.Element, size)
+		tval = make([]fr.Element, size)
+		tid = make([]fr.Element, size)
+		row := 0
+		for i := range tables {
+			for j := range tables[i] {
+				copy(tval[row][:], tables[i][j][:])
+				tid[row].SetUint64(uint64(i))
+				row++
+			}
+		}
+		for ; row < len(tval); row++ {
+			tval[row] = tval[0]
+		}
+	}
 
 	for i := 0; i < len(spr.Public); i++ { // placeholders (-PUB_INPUT_i + qk_i = 0) TODO should return error if size is inconsistent
 		ql[i].SetOne().Neg(&ql[i])
@@ -183,6 +233,13 @@ func NewTrace(spr *cs.SparseR1CS, domain *fft.Domain) *Trace {
 		qm[offset+j].Set(&spr.Coefficients[c.QM])
 		qo[offset+j].Set(&spr.Coefficients[c.QO])
 		qk[offset+j].Set(&spr.Coefficients[c.QC])
+		if c.CustomGate != 0 {
+			qg[c.CustomGate-1][offset+j].SetOne()
+		}
+		if c.Lookup != 0 {
+			qlk[offset+j].SetOne()
+			qlkid[offset+j].SetUint64(uint64(c.Lookup - 1))
+		}
 		j++
 	}
 
@@ -203,6 +260,18 @@ func NewTrace(spr *cs.SparseR1CS, domain *fft.Domain) *Trace {
 		trace.Qcp[i] = iop.NewPolynomial(&qcp[i], lagReg)
 	}
 
+	trace.Qg = make([]*iop.Polynomial, len(qg))
+	for i := range qg {
+		trace.Qg[i] = iop.NewPolynomial(&qg[i], lagReg)
+	}
+
+	if len(tables) != 0 {
+		trace.Qlk = iop.NewPolynomial(&qlk, lagReg)
+		trace.Qlkid = iop.NewPolynomial(&qlkid, lagReg)
+		trace.Tval = iop.NewPolynomial(&tval, lagReg)
+		trace.Tid = iop.NewPolynomial(&tid, lagReg)
+	}
+
 	// build the permutation and build the polynomials S1, S2, S3 to encode the permutation.
 	// Note: at this stage, the permutation takes in account the placeholders
 	nbVariables := spr.NbInternalVariables + len(spr.Public) + len(spr.Secret)
@@ -226,6 +295,26 @@ func (vk *VerifyingKey) commitTrace(trace *Trace, domain *fft.Domain, srsPk kzg.
 			return err
 		}
 	}
+	vk.Qg = make([]kzg.Digest, len(trace.Qg))
+	for i := range trace.Qg {
+		if vk.Qg[i], err = kzg.Commit(trace.Qg[i].Coefficients(), srsPk); err != nil {
+			return err
+		}
+	}
+	if trace.Qlk != nil {
+		if vk.Qlk, err = kzg.Commit(trace.Qlk.Coefficients(), srsPk); err != nil {
+			return err
+		}
+		if vk.Qlkid, err = kzg.Commit(trace.Qlkid.Coefficients(), srsPk); err != nil {
+			return err
+		}
+		if vk.Tval, err = kzg.Commit(trace.Tval.Coefficients(), srsPk); err != nil {
+			return err
+		}
+		if vk.Tid, err = kzg.Commit(trace.Tid.Coefficients(), srsPk); err != nil {
+			return err
+		}
+	}
 	if vk.Ql, err = kzg.Commit(trace.Ql.Coefficients(), srsPk); err != nil {
 		return err
 	}
@@ -259,6 +348,15 @@ func initFFTDomain(spr *cs.SparseR1CS) *fft.Domain {
 	return fft.NewDomain(sizeSystem, fft.WithoutPrecompute())
 }
 
+// fixedTablesSize returns the total number of entries of the fixed tables.
+func fixedTablesSize(spr *cs.SparseR1CS) int {
+	size := 0
+	for _, t := range spr.GetFixedTables() {
+		size += len(t)
+	}
+	return size
+}
+
 // buildPermutation builds the Permutation associated with a circuit.
 //
 // The permutation s is composed of cycles of maximum length such that
@@ -33,14 +33,16 @@ import (
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/hash_to_field"
 
 	"github.com/consensys/gnark-crypto/ecc/bls24-315/kzg"
-	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
 	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/solidity"
+	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/logger"
 )
 
 var (
 	errWrongClaimedQuotient = errors.New("claimed quotient is not as expected")
 	errInvalidWitness       = errors.New("witness length is invalid")
+	errInvalidProofShape    = errors.New("proof doesn't match the shape of the verifying key")
 )
 
 func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...backend.VerifierOption) error {
@@ -59,8 +61,25 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		return errInvalidWitness
 	}
 
+	nbShiftedLRO := 0
+	if vk.customGatesSpanTwoRows() {
+		nbShiftedLRO = 3
+	}
+	nbLookupClaims := 0
+	if vk.hasLookups() {
+		nbLookupClaims = 4
+	}
+	if len(vk.Qg) != len(vk.CustomGates) ||
+		len(proof.BatchedProof.ClaimedValues) != 7+len(vk.Qcp)+nbLookupClaims ||
+		len(proof.LROShiftedOpening.ClaimedValues) != nbShiftedLRO {
+		return errInvalidProofShape
+	}
+
 	// transcript to derive the challenge
-	fs := fiatshamir.NewTranscript(cfg.ChallengeHash, "gamma", "beta", "alpha", "zeta")
+	fs, err := cfg.NewTranscript("gamma", "beta", "alpha", "zeta")
+	if err != nil {
+		return fmt.Errorf("new transcript: %w", err)
+	}
 
 	// The first challenge is derived using the public data: the commitments to the permutation,
 	// the coefficients of the circuit, and the public inputs.
@@ -68,7 +87,11 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	if err := bindPublicData(fs, "gamma", vk, publicWitness); err != nil {
 		return err
 	}
-	gamma, err := deriveRandomness(fs, "gamma", &proof.LRO[0], &proof.LRO[1], &proof.LRO[2])
+	gammaDeps := []*curve.G1Affine{&proof.LRO[0], &proof.LRO[1], &proof.LRO[2]}
+	if vk.hasLookups() {
+		gammaDeps = append(gammaDeps, &proof.Multiplicities)
+	}
+	gamma, err := deriveRandomness(fs, "gamma", gammaDeps...)
 	if err != nil {
 		return err
 	}
@@ -79,17 +102,23 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		return err
 	}
 
-	// derive alpha from Comm(l), Comm(r), Comm(o), Com(Z), Bsb22Commitments
+	// derive alpha from Comm(l), Comm(r), Comm(o), Com(Z), Bsb22Commitments, Comm(S)
 	alphaDeps := make([]*curve.G1Affine, len(proof.Bsb22Commitments)+1)
 	for i := range proof.Bsb22Commitments {
 		alphaDeps[i] = &proof.Bsb22Commitments[i]
 	}
 	alphaDeps[len(alphaDeps)-1] = &proof.Z
+	if vk.hasLookups() {
+		alphaDeps = append(alphaDeps, &proof.LookupSum)
+	}
 	alpha, err := deriveRandomness(fs, "alpha", alphaDeps...)
 	if err != nil {
 		return err
 	}
 
+	customGates := compileCustomGates(vk.CustomGates, alpha)
+	alphaLookup := lookupAlpha(vk.CustomGates, alpha)
+
 	// derive zeta, the point of evaluation
 	zeta, err := deriveRandomness(fs, "zeta", &proof.H[0], &proof.H[1], &proof.H[2])
 	if err != nil {
@@ -172,6 +201,14 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	s1 := proof.BatchedProof.ClaimedValues[5]
 	s2 := proof.BatchedProof.ClaimedValues[6]
 
+	// evaluations of the custom gates, using l(ωζ), r(ωζ), o(ωζ) for the gates spanning two rows
+	wires := [6]fr.Element{l, r, o}
+	copy(wires[3:], proof.LROShiftedOpening.ClaimedValues)
+	customGatesZeta := make([]fr.Element, len(customGates))
+	for i := range customGates {
+		customGatesZeta[i] = evaluateCustomGate(customGates[i], &wires)
+	}
+
 	_s1.Mul(&s1, &beta).Add(&_s1, &l).Add(&_s1, &gamma) // (l(ζ)+β*s1(ζ)+γ)
 	_s2.Mul(&s2, &beta).Add(&_s2, &r).Add(&_s2, &gamma) // (r(ζ)+β*s2(ζ)+γ)
 	_o.Add(&o, &gamma)                                  // (o(ζ)+γ)
@@ -189,6 +226,21 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		Add(&linearizedPolynomialZeta, &_s1).                // linearizedpolynomial+pi(zeta)+α*(Z(μζ))*(l(ζ)+s1(ζ)+γ)*(r(ζ)+s2(ζ)+γ)*(o(ζ)+γ)
 		Sub(&linearizedPolynomialZeta, &alphaSquareLagrange) // linearizedpolynomial+pi(zeta)+α*(Z(μζ))*(l(ζ)+s1(ζ)+γ)*(r(ζ)+s2(ζ)+γ)*(o(ζ)+γ)-α²*L₁(ζ)
 
+	// lookup argument, with f = l+γ*qlkid and t = tval+γ*tid:
+	// α_lk*(qlk(ζ)*(β-t(ζ)) - S(μζ)*(β-f(ζ))*(β-t(ζ)))
+	var lookupF, lookupT fr.Element
+	if vk.hasLookups() {
+		lookupClaims := proof.BatchedProof.ClaimedValues[7+len(vk.Qcp):]
+		lookupF.Mul(&lookupClaims[1], &gamma).Add(&lookupF, &l).Sub(&beta, &lookupF)               // β-f(ζ)
+		lookupT.Mul(&lookupClaims[3], &gamma).Add(&lookupT, &lookupClaims[2]).Sub(&beta, &lookupT) // β-t(ζ)
+
+		var c, tmp fr.Element
+		c.Mul(&lookupClaims[0], &lookupT)
+		tmp.Mul(&proof.LookupSumShiftedOpening.ClaimedValue, &lookupF).Mul(&tmp, &lookupT)
+		c.Sub(&c, &tmp).Mul(&c, &alphaLookup)
+		linearizedPolynomialZeta.Add(&linearizedPolynomialZeta, &c)
+	}
+
 	// Compute H(ζ) using the previous result: H(ζ) = prev_result/(ζⁿ-1)
 	var zetaPowerMMinusOne fr.Element
 	zetaPowerMMinusOne.Sub(&zetaPowerM, &one)
@@ -213,7 +265,7 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 
 	// Compute the commitment to the linearized polynomial
 	// linearizedPolynomialDigest =
-	// 		l(ζ)*ql+r(ζ)*qr+r(ζ)l(ζ)*qm+o(ζ)*qo+qk+Σᵢqc'ᵢ(ζ)*BsbCommitmentᵢ +
+	// 		l(ζ)*ql+r(ζ)*qr+r(ζ)l(ζ)*qm+o(ζ)*qo+qk+Σᵢqc'ᵢ(ζ)*BsbCommitmentᵢ + Σᵢgᵢ(ζ)*qgᵢ +
 	// 		α*( Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*s₃(X)-Z(X)(l(ζ)+β*id_1(ζ)+γ)*(r(ζ)+β*id_2(ζ)+γ)*(o(ζ)+β*id_3(ζ)+γ) ) +
 	// 		α²*L₁(ζ)*Z
 	// first part: individual constraints
@@ -243,6 +295,7 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		vk.Ql, vk.Qr, vk.Qm, vk.Qo, vk.Qk, // first part
 		vk.S[2], proof.Z, // second & third part
 	)
+	points = append(points, vk.Qg...) // custom gates
 
 	qC := make([]fr.Element, len(proof.Bsb22Commitments))
 	copy(qC, proof.BatchedProof.ClaimedValues[7:])
@@ -250,6 +303,16 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 		l, r, rl, o, one, /* TODO Perf @Tabaie Consider just adding Qk instead */ // first part
 		_s1, _s2, // second & third part
 	)
+	scalars = append(scalars, customGatesZeta...) // custom gates
+	if vk.hasLookups() {
+		// α_lk*(β-f(ζ))*(β-t(ζ))*S(X) - α_lk*(β-f(ζ))*M(X)
+		var cS, cM fr.Element
+		cM.Mul(&alphaLookup, &lookupF)
+		cS.Mul(&cM, &lookupT)
+		cM.Neg(&cM)
+		points = append(points, proof.LookupSum, proof.Multiplicities)
+		scalars = append(scalars, cS, cM)
+	}
 	if _, err := linearizedPolynomialDigest.MultiExp(points, scalars, ecc.MultiExpConfig{}); err != nil {
 		return err
 	}
@@ -264,12 +327,15 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	digestsToFold[4] = proof.LRO[2]
 	digestsToFold[5] = vk.S[0]
 	digestsToFold[6] = vk.S[1]
+	if vk.hasLookups() {
+		digestsToFold = append(digestsToFold, vk.Qlk, vk.Qlkid, vk.Tval, vk.Tid)
+	}
 	foldedProof, foldedDigest, err := kzg.FoldProof(
 		digestsToFold,
 		&proof.BatchedProof,
 		zeta,
 		cfg.KZGFoldingHash,
-		zu.Marshal(),
+		shiftedClaimedValues(proof, vk)...,
 	)
 	if err != nil {
 		return err
@@ -278,27 +344,37 @@ func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...bac
 	// Batch verify
 	var shiftedZeta fr.Element
 	shiftedZeta.Mul(&zeta, &vk.Generator)
-	err = kzg.BatchVerifyMultiPoints([]kzg.Digest{
-		foldedDigest,
-		proof.Z,
-	},
-		[]kzg.OpeningProof{
-			foldedProof,
-			proof.ZShiftedOpening,
-		},
-		[]fr.Element{
-			zeta,
+	digests := []kzg.Digest{foldedDigest, proof.Z}
+	proofs := []kzg.OpeningProof{foldedProof, proof.ZShiftedOpening}
+	evaluationPoints := []fr.Element{zeta, shiftedZeta}
+	if nbShiftedLRO != 0 {
+		// fold the opening of l, r, o at ωζ
+		foldedShiftedProof, foldedShiftedDigest, err := kzg.FoldProof(
+			proof.LRO[:],
+			&proof.LROShiftedOpening,
 			shiftedZeta,
-		},
-		vk.Kzg,
-	)
+			cfg.KZGFoldingHash,
+		)
+		if err != nil {
+			return err
+		}
+		digests = append(digests, foldedShiftedDigest)
+		proofs = append(proofs, foldedShiftedProof)
+		evaluationPoints = append(evaluationPoints, shiftedZeta)
+	}
+	if vk.hasLookups() {
+		digests = append(digests, proof.LookupSum)
+		proofs = append(proofs, proof.LookupSumShiftedOpening)
+		evaluationPoints = append(evaluationPoints, shiftedZeta)
+	}
+	err = kzg.BatchVerifyMultiPoints(digests, proofs, evaluationPoints, vk.Kzg)
 
 	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
 
 	return err
 }
 
-func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk *VerifyingKey, publicInputs []fr.Element) error {
+func bindPublicData(fs backend.Transcript, challenge string, vk *VerifyingKey, publicInputs []fr.Element) error {
 
 	// permutation
 	if err := fs.Bind(challenge, vk.S[0].Marshal()); err != nil {
@@ -332,6 +408,18 @@ func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk *VerifyingKe
 			return err
 		}
 	}
+	for i := range vk.Qg {
+		if err := fs.Bind(challenge, vk.Qg[i].Marshal()); err != nil {
+			return err
+		}
+	}
+	if vk.hasLookups() {
+		for _, d := range []kzg.Digest{vk.Qlk, vk.Qlkid, vk.Tval, vk.Tid} {
+			if err := fs.Bind(challenge, d.Marshal()); err != nil {
+				return err
+			}
+		}
+	}
 
 	// public inputs
 	for i := 0; i < len(publicInputs); i++ {
@@ -344,7 +432,7 @@ func bindPublicData(fs *fiatshamir.Transcript, challenge string, vk *VerifyingKe
 
 }
 
-func deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...*curve.G1Affine) (fr.Element, error) {
+func deriveRandomness(fs backend.Transcript, challenge string, points ...*curve.G1Affine) (fr.Element, error) {
 
 	var buf [curve.SizeOfG1AffineUncompressed]byte
 	var r fr.Element
@@ -364,7 +452,104 @@ func deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...*cu
 	return r, nil
 }
 
+// shiftedClaimedValues returns the values opened at ωζ, that are bound to the
+// batch opening at ζ: Z(ωζ), followed by l(ωζ), r(ωζ), o(ωζ) if a custom gate
+// spans two rows, and by S(ωζ) if the circuit has fixed tables.
+func shiftedClaimedValues(proof *Proof, vk *VerifyingKey) [][]byte {
+	res := make([][]byte, 1, 2+len(proof.LROShiftedOpening.ClaimedValues))
+	res[0] = proof.ZShiftedOpening.ClaimedValue.Marshal()
+	for i := range proof.LROShiftedOpening.ClaimedValues {
+		res = append(res, proof.LROShiftedOpening.ClaimedValues[i].Marshal())
+	}
+	if vk.hasLookups() {
+		res = append(res, proof.LookupSumShiftedOpening.ClaimedValue.Marshal())
+	}
+	return res
+}
+
+// hasLookups returns true if the circuit has fixed tables, in which case the
+// proof holds the lookup argument.
+func (vk *VerifyingKey) hasLookups() bool {
+	return vk.NbFixedTables != 0
+}
+
+// customGatesSpanTwoRows returns true if a custom gate reads the wires of the next row,
+// in which case the proof opens l, r, o at ωζ.
+func (vk *VerifyingKey) customGatesSpanTwoRows() bool {
+	for i := range vk.CustomGates {
+		if vk.CustomGates[i].SpansTwoRows() {
+			return true
+		}
+	}
+	return false
+}
+
+// customGateTerm is a monomial of an identity of a custom gate, scaled by the
+// power of α of the identity.
+type customGateTerm struct {
+	coeff fr.Element
+	wires []constraint.GateWire
+}
+
+// compileCustomGates returns the monomials of the identities of the custom
+// gates, the j-th identity (counted over all the gates) being scaled by α³⁺ʲ,
+// after the gate, copy and Z(1)=1 constraints.
+func compileCustomGates(gates []constraint.CustomGate, alpha fr.Element) [][]customGateTerm {
+	var alphaPower fr.Element
+	alphaPower.Square(&alpha).Mul(&alphaPower, &alpha)
+	res := make([][]customGateTerm, len(gates))
+	for i := range gates {
+		for _, identity := range gates[i].Identities {
+			for _, t := range identity {
+				var term customGateTerm
+				term.coeff.SetInt64(t.Coeff).Mul(&term.coeff, &alphaPower)
+				term.wires = t.Wires
+				res[i] = append(res[i], term)
+			}
+			alphaPower.Mul(&alphaPower, &alpha)
+		}
+	}
+	return res
+}
+
+// lookupAlpha returns α³⁺ᴶ, the power of α scaling the identity of the lookup
+// argument, J being the number of identities of the custom gates.
+func lookupAlpha(gates []constraint.CustomGate, alpha fr.Element) fr.Element {
+	var res fr.Element
+	res.Square(&alpha).Mul(&res, &alpha)
+	for i := range gates {
+		for range gates[i].Identities {
+			res.Mul(&res, &alpha)
+		}
+	}
+	return res
+}
+
+// evaluateCustomGate returns ∑ coeff⋅∏wires over the terms of a compiled gate,
+// wires being indexed by constraint.GateWire.
+func evaluateCustomGate(terms []customGateTerm, wires *[6]fr.Element) fr.Element {
+	var res, m fr.Element
+	for i := range terms {
+		m = terms[i].coeff
+		for _, w := range terms[i].wires {
+			m.Mul(&m, &wires[w])
+		}
+		res.Add(&res, &m)
+	}
+	return res
+}
+
+// Export writes a verifier contract for vk in the target language. Solidity is
+// handled by ExportSolidity, the other targets by the exporter registered with
+// backend.RegisterExporter.
+func (vk *VerifyingKey) Export(w io.Writer, target backend.Target) error {
+	if target == backend.Solidity {
+		return vk.ExportSolidity(w)
+	}
+	return backend.Export(w, vk, backend.PLONK, ecc.BLS24_315, target)
+}
+
 // ExportSolidity not implemented for BLS24-315
-func (vk *VerifyingKey) ExportSolidity(w io.Writer) error {
+func (vk *VerifyingKey) ExportSolidity(w io.Writer, exportOpts ...solidity.ExportOption) error {
 	return errors.New("not implemented")
 }
@@ -0,0 +1,822 @@
+package plonk
+
+// tmplCairoVerifier is the Cairo port of tmplSolidityVerifier, for Starknet.
+// The proof is given as the 32 bytes words of MarshalSolidity.
+//
+// Starknet has no precompiles for BN254, so the operations on the curve are
+// delegated to a contract implementing IBn254Curve.
+//
+// The generated contract is compiled and executed with Scarb in the tests with
+// the cairocheck build tag.
+const tmplCairoVerifier = `// SPDX-License-Identifier: Apache-2.0
+
+// Copyright 2023 Consensys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+//! PlonK verifier on BN254 for Starknet.
+//!
+//! Starknet doesn't provide the BN254 precompiles of the EVM: the multi scalar
+//! multiplications and the pairing check are delegated to a contract implementing
+//! IBn254Curve (for instance built with Garaga), whose address is given to the
+//! constructor.
+
+/// A point of G1, in affine coordinates.
+#[derive(Copy, Drop, Serde)]
+pub struct G1Point {
+    pub x: u256,
+    pub y: u256,
+}
+
+/// A point of G2, in affine coordinates x = x0 + x1⋅i, y = y0 + y1⋅i.
+#[derive(Copy, Drop, Serde)]
+pub struct G2Point {
+    pub x0: u256,
+    pub x1: u256,
+    pub y0: u256,
+    pub y1: u256,
+}
+
+#[starknet::interface]
+pub trait IBn254Curve<TContractState> {
+    /// Returns ∑ᵢ scalarsᵢ⋅pointsᵢ. Reverts if a point is not on the curve.
+    fn msm(self: @TContractState, points: Span<G1Point>, scalars: Span<u256>) -> G1Point;
+    /// Returns true if ∏ᵢ e(aᵢ, bᵢ) = 1. Reverts if a point is not in its group.
+    fn pairing_check(self: @TContractState, a: Span<G1Point>, b: Span<G2Point>) -> bool;
+}
+
+#[starknet::interface]
+pub trait IPlonkVerifier<TContractState> {
+    /// Verify a Plonk proof.
+    /// Reverts if the proof or the public inputs are malformed.
+    /// proof: the 32 bytes words of the proof serialised with gnark's MarshalSolidity.
+    /// public_inputs: the public inputs (must be reduced).
+    fn verify(self: @TContractState, proof: Span<u256>, public_inputs: Span<u256>) -> bool;
+}
+
+#[starknet::contract]
+pub mod PlonkVerifier {
+    use core::math::{u256_inv_mod, u256_mul_mod_n};
+    use core::sha256::compute_sha256_byte_array;
+    use starknet::ContractAddress;
+    use starknet::storage::{StoragePointerReadAccess, StoragePointerWriteAccess};
+    use super::{G1Point, G2Point, IBn254CurveDispatcher, IBn254CurveDispatcherTrait};
+
+    const R_MOD: u256 = 21888242871839275222246405745257275088548364400416034343698204186575808495617;
+    const P_MOD: u256 = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+    const TWO_128: u256 = 0x100000000000000000000000000000000;
+    {{- range $index, $element := .Kzg.G2 }}
+
+    const G2_SRS_{{ $index }}_X_0: u256 = {{ fpstr $element.X.A0 }};
+    const G2_SRS_{{ $index }}_X_1: u256 = {{ fpstr $element.X.A1 }};
+    const G2_SRS_{{ $index }}_Y_0: u256 = {{ fpstr $element.Y.A0 }};
+    const G2_SRS_{{ $index }}_Y_1: u256 = {{ fpstr $element.Y.A1 }};
+    {{- end }}
+
+    const G1_SRS_X: u256 = {{ fpstr .Kzg.G1.X }};
+    const G1_SRS_Y: u256 = {{ fpstr .Kzg.G1.Y }};
+
+    // ----------------------- vk ---------------------
+    const VK_NB_PUBLIC_INPUTS: u32 = {{ .NbPublicVariables }};
+    const VK_DOMAIN_SIZE: u64 = {{ .Size }};
+    const VK_INV_DOMAIN_SIZE: u256 = {{ frstr .SizeInv }};
+    const VK_OMEGA: u256 = {{ frstr .Generator }};
+    const VK_QL_COM_X: u256 = {{ fpstr .Ql.X }};
+    const VK_QL_COM_Y: u256 = {{ fpstr .Ql.Y }};
+    const VK_QR_COM_X: u256 = {{ fpstr .Qr.X }};
+    const VK_QR_COM_Y: u256 = {{ fpstr .Qr.Y }};
+    const VK_QM_COM_X: u256 = {{ fpstr .Qm.X }};
+    const VK_QM_COM_Y: u256 = {{ fpstr .Qm.Y }};
+    const VK_QO_COM_X: u256 = {{ fpstr .Qo.X }};
+    const VK_QO_COM_Y: u256 = {{ fpstr .Qo.Y }};
+    const VK_QK_COM_X: u256 = {{ fpstr .Qk.X }};
+    const VK_QK_COM_Y: u256 = {{ fpstr .Qk.Y }};
+    {{- range $index, $element := .S }}
+    const VK_S{{ inc $index }}_COM_X: u256 = {{ fpstr $element.X }};
+    const VK_S{{ inc $index }}_COM_Y: u256 = {{ fpstr $element.Y }};
+    {{- end }}
+    const VK_COSET_SHIFT: u256 = {{ frstr .CosetShift }};
+    {{- range $index, $element := .Qcp }}
+    const VK_QCP_{{ $index }}_X: u256 = {{ fpstr $element.X }};
+    const VK_QCP_{{ $index }}_Y: u256 = {{ fpstr $element.Y }};
+    {{- end }}
+    {{- range $index, $element := .CommitmentConstraintIndexes }}
+    const VK_INDEX_COMMIT_API_{{ $index }}: u64 = {{ $element }};
+    {{- end }}
+    const VK_NB_CUSTOM_GATES: u32 = {{ len .CommitmentConstraintIndexes }};
+    {{- range $index, $element := .Qg }}
+    const VK_QG_{{ $index }}_X: u256 = {{ fpstr $element.X }};
+    const VK_QG_{{ $index }}_Y: u256 = {{ fpstr $element.Y }};
+    {{- end }}
+
+    // ------------------------------------------------
+
+    // offset proof, in 32 bytes words
+    const PROOF_NB_WORDS: u32 = {{ if spansTwoRows }}{{ add 31 (mul (len .CommitmentConstraintIndexes) 3) }}{{ else }}{{ add 26 (mul (len .CommitmentConstraintIndexes) 3) }}{{ end }};
+
+    const PROOF_L_COM: u32 = 0;
+    const PROOF_R_COM: u32 = 2;
+    const PROOF_O_COM: u32 = 4;
+
+    // h = h_0 + x^{n+2}h_1 + x^{2(n+2)}h_2
+    const PROOF_H_0: u32 = 6;
+    const PROOF_H_1: u32 = 8;
+    const PROOF_H_2: u32 = 10;
+
+    // wire values at zeta
+    const PROOF_L_AT_ZETA: u32 = 12;
+    const PROOF_R_AT_ZETA: u32 = 13;
+    const PROOF_O_AT_ZETA: u32 = 14;
+
+    // Sσ1(zeta), Sσ2(zeta)
+    const PROOF_S1_AT_ZETA: u32 = 15;
+    const PROOF_S2_AT_ZETA: u32 = 16;
+
+    // [z(x)]
+    const PROOF_GRAND_PRODUCT_COMMITMENT: u32 = 17;
+
+    const PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA: u32 = 19; // z(w*zeta)
+    const PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA: u32 = 20; // t(zeta)
+    const PROOF_LINEARISED_POLYNOMIAL_AT_ZETA: u32 = 21; // r(zeta)
+
+    // Folded proof for the opening of linearised poly, l, r, o, s_1, s_2, qcp
+    const PROOF_BATCH_OPENING_AT_ZETA: u32 = 22; // [Wzeta]
+    const PROOF_OPENING_AT_ZETA_OMEGA: u32 = 24;
+
+    const PROOF_OPENING_QCP_AT_ZETA: u32 = 26;
+    const PROOF_COMMITMENTS_WIRES_CUSTOM_GATES: u32 = {{ add 26 (len .CommitmentConstraintIndexes) }};
+    {{- if spansTwoRows }}
+
+    // openings of l, r, o at zeta*omega, read by the custom gates spanning two rows
+    const PROOF_L_AT_ZETA_OMEGA: u32 = {{ add 26 (mul (len .CommitmentConstraintIndexes) 3) }};
+    const PROOF_R_AT_ZETA_OMEGA: u32 = {{ add 27 (mul (len .CommitmentConstraintIndexes) 3) }};
+    const PROOF_O_AT_ZETA_OMEGA: u32 = {{ add 28 (mul (len .CommitmentConstraintIndexes) 3) }};
+    const PROOF_OPENING_LRO_AT_ZETA_OMEGA: u32 = {{ add 29 (mul (len .CommitmentConstraintIndexes) 3) }};
+    {{- end }}
+
+    #[storage]
+    struct Storage {
+        curve: ContractAddress,
+    }
+
+    #[constructor]
+    fn constructor(ref self: ContractState, curve: ContractAddress) {
+        self.curve.write(curve);
+    }
+
+    #[abi(embed_v0)]
+    impl PlonkVerifierImpl of super::IPlonkVerifier<ContractState> {
+        fn verify(self: @ContractState, proof: Span<u256>, public_inputs: Span<u256>) -> bool {
+            // sanity checks
+            assert(public_inputs.len() == VK_NB_PUBLIC_INPUTS, 'wrong number of public inputs');
+            assert(proof.len() == PROOF_NB_WORDS, 'wrong proof size');
+            for input in public_inputs {
+                assert(*input < R_MOD, 'inputs are bigger than r');
+            };
+            check_proof_openings_size(proof);
+            let curve = IBn254CurveDispatcher { contract_address: self.curve.read() };
+
+            // compute the challenges
+            let (gamma, beta, alpha, zeta) = derive_challenges(proof, public_inputs);
+
+            // evaluation of Z=Xⁿ-1 at ζ
+            let zeta_power_n_minus_one = sub_mod(pow_mod(zeta, VK_DOMAIN_SIZE), 1);
+
+            // public inputs contribution
+            let pi = compute_pi(proof, public_inputs, zeta, zeta_power_n_minus_one);
+
+            // α²⋅L₀(ζ) = α²⋅1/n⋅(ζⁿ-1)/(ζ-1)
+            let mut alpha_square_lagrange_0 = inv_mod(sub_mod(zeta, 1));
+            alpha_square_lagrange_0 = mul_mod(alpha_square_lagrange_0, VK_INV_DOMAIN_SIZE);
+            alpha_square_lagrange_0 = mul_mod(alpha_square_lagrange_0, zeta_power_n_minus_one);
+            alpha_square_lagrange_0 = mul_mod(alpha_square_lagrange_0, mul_mod(alpha, alpha));
+
+            if !verify_quotient_poly_eval_at_zeta(
+                proof, beta, gamma, alpha, pi, alpha_square_lagrange_0, zeta_power_n_minus_one,
+            ) {
+                return false;
+            }
+
+            // H₀ + ζⁿ⁺²⋅H₁ + ζ²⁽ⁿ⁺²⁾⋅H₂
+            let zeta_power_n_plus_two = pow_mod(zeta, VK_DOMAIN_SIZE + 2);
+            let folded_h = curve
+                .msm(
+                    array![point_at(proof, PROOF_H_0), point_at(proof, PROOF_H_1), point_at(proof, PROOF_H_2)].span(),
+                    array![1, zeta_power_n_plus_two, mul_mod(zeta_power_n_plus_two, zeta_power_n_plus_two)].span(),
+                );
+
+            let linearised_polynomial = compute_commitment_linearised_polynomial(
+                curve, proof, beta, gamma, alpha, zeta, alpha_square_lagrange_0,
+            );
+            let gamma_kzg = compute_gamma_kzg(proof, zeta, folded_h, linearised_polynomial);
+            let (folded_digests, folded_claimed_values) = fold_state(
+                curve, proof, gamma_kzg, folded_h, linearised_polynomial,
+            );
+            batch_verify_multi_points(curve, proof, zeta, gamma_kzg, folded_digests, folded_claimed_values)
+        }
+    }
+
+    /// Checks that the claimed evaluations of the proof are reduced.
+    fn check_proof_openings_size(proof: Span<u256>) {
+        let mut i = PROOF_L_AT_ZETA;
+        while i <= PROOF_S2_AT_ZETA {
+            assert(*proof.at(i) < R_MOD, 'openings bigger than r');
+            i += 1;
+        };
+        i = PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA;
+        while i <= PROOF_LINEARISED_POLYNOMIAL_AT_ZETA {
+            assert(*proof.at(i) < R_MOD, 'openings bigger than r');
+            i += 1;
+        };
+        i = PROOF_OPENING_QCP_AT_ZETA;
+        while i < PROOF_OPENING_QCP_AT_ZETA + VK_NB_CUSTOM_GATES {
+            assert(*proof.at(i) < R_MOD, 'openings bigger than r');
+            i += 1;
+        };
+        {{- if spansTwoRows }}
+        i = PROOF_L_AT_ZETA_OMEGA;
+        while i <= PROOF_O_AT_ZETA_OMEGA {
+            assert(*proof.at(i) < R_MOD, 'openings bigger than r');
+            i += 1;
+        };
+        {{- end }}
+    }
+
+    // Beginning challenges -------------------------------------------------
+
+    /// Derives the challenges gamma, beta, alpha and zeta as in the Solidity verifier:
+    /// * gamma = Sha256("gamma" || [S₁] || [S₂] || [S₃] || [Qₗ] || [Qᵣ] || [Qₘ] || [Qₒ] || [Qₖ] || [Qcpᵢ] || [Qgᵢ] || public inputs || [L] || [R] || [O])
+    /// * beta = Sha256("beta" || gamma)
+    /// * alpha = Sha256("alpha" || beta || [BsbCommitmentᵢ] || [Z])
+    /// * zeta = Sha256("zeta" || alpha || [H₀] || [H₁] || [H₂])
+    /// where the previous challenges are not reduced.
+    fn derive_challenges(proof: Span<u256>, public_inputs: Span<u256>) -> (u256, u256, u256, u256) {
+        let mut transcript: ByteArray = "gamma";
+        append_u256(ref transcript, VK_S1_COM_X);
+        append_u256(ref transcript, VK_S1_COM_Y);
+        append_u256(ref transcript, VK_S2_COM_X);
+        append_u256(ref transcript, VK_S2_COM_Y);
+        append_u256(ref transcript, VK_S3_COM_X);
+        append_u256(ref transcript, VK_S3_COM_Y);
+        append_u256(ref transcript, VK_QL_COM_X);
+        append_u256(ref transcript, VK_QL_COM_Y);
+        append_u256(ref transcript, VK_QR_COM_X);
+        append_u256(ref transcript, VK_QR_COM_Y);
+        append_u256(ref transcript, VK_QM_COM_X);
+        append_u256(ref transcript, VK_QM_COM_Y);
+        append_u256(ref transcript, VK_QO_COM_X);
+        append_u256(ref transcript, VK_QO_COM_Y);
+        append_u256(ref transcript, VK_QK_COM_X);
+        append_u256(ref transcript, VK_QK_COM_Y);
+        {{- range $index, $element := .CommitmentConstraintIndexes }}
+        append_u256(ref transcript, VK_QCP_{{ $index }}_X);
+        append_u256(ref transcript, VK_QCP_{{ $index }}_Y);
+        {{- end }}
+        {{- range $index, $element := .Qg }}
+        append_u256(ref transcript, VK_QG_{{ $index }}_X);
+        append_u256(ref transcript, VK_QG_{{ $index }}_Y);
+        {{- end }}
+        for input in public_inputs {
+            append_u256(ref transcript, *input);
+        };
+        append_words(ref transcript, proof, PROOF_L_COM, 6);
+        let gamma_not_reduced = sha256(@transcript);
+
+        let mut transcript: ByteArray = "beta";
+        append_u256(ref transcript, gamma_not_reduced);
+        let beta_not_reduced = sha256(@transcript);
+
+        let mut transcript: ByteArray = "alpha";
+        append_u256(ref transcript, beta_not_reduced);
+        append_words(ref transcript, proof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES, 2 * VK_NB_CUSTOM_GATES);
+        append_words(ref transcript, proof, PROOF_GRAND_PRODUCT_COMMITMENT, 2);
+        let alpha_not_reduced = sha256(@transcript);
+
+        let mut transcript: ByteArray = "zeta";
+        append_u256(ref transcript, alpha_not_reduced);
+        append_words(ref transcript, proof, PROOF_H_0, 6);
+        let zeta_not_reduced = sha256(@transcript);
+
+        (gamma_not_reduced % R_MOD, beta_not_reduced % R_MOD, alpha_not_reduced % R_MOD, zeta_not_reduced % R_MOD)
+    }
+
+    // END challenges -------------------------------------------------
+
+    // BEGINNING compute_pi -------------------------------------------------
+
+    /// Computes the public inputs contribution ∑ᵢLᵢ(ζ)⋅piᵢ, including the public
+    /// inputs coming from the custom gates.
+    fn compute_pi(proof: Span<u256>, public_inputs: Span<u256>, zeta: u256, zpnmo: u256) -> u256 {
+        let mut pi = 0;
+        let mut i: u64 = 0;
+        for input in public_inputs {
+            pi = add_mod(pi, mul_mod(compute_ith_lagrange_at_z(zeta, zpnmo, i), *input));
+            i += 1;
+        };
+        {{- range $index, $element := .CommitmentConstraintIndexes }}
+        let h_fr = hash_fr(point_at(proof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + {{ mul $index 2 }}));
+        let ith_lagrange = compute_ith_lagrange_at_z(zeta, zpnmo, VK_NB_PUBLIC_INPUTS.into() + VK_INDEX_COMMIT_API_{{ $index }});
+        pi = add_mod(pi, mul_mod(h_fr, ith_lagrange));
+        {{- end }}
+        pi
+    }
+
+    /// Computes Lᵢ(ζ) = ωⁱ/n * (ζⁿ-1)/(ζ-ωⁱ)
+    fn compute_ith_lagrange_at_z(z: u256, zpnmo: u256, i: u64) -> u256 {
+        let w = pow_mod(VK_OMEGA, i);
+        let mut res = inv_mod(sub_mod(z, w));
+        res = mul_mod(res, w);
+        res = mul_mod(res, VK_INV_DOMAIN_SIZE);
+        mul_mod(res, zpnmo)
+    }
+
+    /// Hashes p to the field, with expand_message_xmd, see
+    /// https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-06#section-5.2
+    fn hash_fr(p: G1Point) -> u256 {
+        let dst: ByteArray = "BSB22-Plonk";
+
+        // [0x00, .. , 0x00 || x, y, || 0, 48, 0, dst, size domain]
+        let mut msg: ByteArray = Default::default();
+        append_u256(ref msg, 0);
+        append_u256(ref msg, 0);
+        append_u256(ref msg, p.x);
+        append_u256(ref msg, p.y);
+        msg.append_byte(0);
+        msg.append_byte(48);
+        msg.append_byte(0);
+        msg.append(@dst);
+        msg.append_byte(11);
+        let b0 = sha256(@msg);
+
+        // [b0 || 1 || dst || size domain]
+        let mut msg: ByteArray = Default::default();
+        append_u256(ref msg, b0);
+        msg.append_byte(1);
+        msg.append(@dst);
+        msg.append_byte(11);
+        let b1 = sha256(@msg);
+
+        // [b0 ^ b1 || 2 || dst || size domain]
+        let mut msg: ByteArray = Default::default();
+        append_u256(ref msg, b0 ^ b1);
+        msg.append_byte(2);
+        msg.append(@dst);
+        msg.append_byte(11);
+        let b2 = sha256(@msg);
+
+        // [ b1 || b2[:16] ] is interpreted as a big integer in big endian
+        add_mod(mul_mod(b1 % R_MOD, TWO_128), b2.high.into())
+    }
+
+    // END compute_pi -------------------------------------------------
+
+    /// check that
+    ///	L(ζ)Qₗ(ζ)+r(ζ)Qᵣ(ζ)+R(ζ)L(ζ)Qₘ(ζ)+O(ζ)Qₒ(ζ)+Qₖ(ζ)+Σᵢqc'ᵢ(ζ)BsbCommitmentᵢ(ζ) +
+    ///  α*( Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β*s₃(X)-Z(X)(l(ζ)+β*id_1(ζ)+γ)*(r(ζ)+β*id_2(ζ)+γ)*(o(ζ)+β*id_3(ζ)+γ) ) )
+    /// + α²*L₁(ζ) =
+    /// (ζⁿ-1)H(ζ)
+    fn verify_quotient_poly_eval_at_zeta(
+        proof: Span<u256>,
+        beta: u256,
+        gamma: u256,
+        alpha: u256,
+        pi: u256,
+        alpha_square_lagrange_0: u256,
+        zpnmo: u256,
+    ) -> bool {
+        // (l(ζ)+β*s1(ζ)+γ)
+        let mut s1 = mul_mod(*proof.at(PROOF_S1_AT_ZETA), beta);
+        s1 = add_mod(s1, gamma);
+        s1 = add_mod(s1, *proof.at(PROOF_L_AT_ZETA));
+
+        // (r(ζ)+β*s2(ζ)+γ)
+        let mut s2 = mul_mod(*proof.at(PROOF_S2_AT_ZETA), beta);
+        s2 = add_mod(s2, gamma);
+        s2 = add_mod(s2, *proof.at(PROOF_R_AT_ZETA));
+
+        // (o(ζ)+γ)
+        let o = add_mod(*proof.at(PROOF_O_AT_ZETA), gamma);
+
+        //  α*(Z(μζ))*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*(o(ζ)+γ)
+        s1 = mul_mod(s1, s2);
+        s1 = mul_mod(s1, o);
+        s1 = mul_mod(s1, alpha);
+        s1 = mul_mod(s1, *proof.at(PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA));
+
+        // linearizedpolynomial + pi(zeta)
+        let mut computed_quotient = add_mod(*proof.at(PROOF_LINEARISED_POLYNOMIAL_AT_ZETA), pi);
+        computed_quotient = add_mod(computed_quotient, s1);
+        computed_quotient = sub_mod(computed_quotient, alpha_square_lagrange_0);
+
+        computed_quotient == mul_mod(*proof.at(PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA), zpnmo)
+    }
+
+    /// Compute the commitment to the linearized polynomial equal to
+    ///	L(ζ)[Qₗ]+r(ζ)[Qᵣ]+R(ζ)L(ζ)[Qₘ]+O(ζ)[Qₒ]+[Qₖ]+Σᵢqc'ᵢ(ζ)[BsbCommitmentᵢ] +
+    ///	α*( Z(μζ)(L(ζ)+β*S₁(ζ)+γ)*(R(ζ)+β*S₂(ζ)+γ)[S₃]-[Z](L(ζ)+β*id_{1}(ζ)+γ)*(R(ζ)+β*id_{2(ζ)+γ)*(O(ζ)+β*id_{3}(ζ)+γ) ) +
+    ///	α²*L₁(ζ)[Z] + Σᵢgᵢ(ζ)[Qgᵢ]
+    /// where
+    /// * id_1 = id, id_2 = vk_coset_shift*id, id_3 = vk_coset_shift^{2}*id
+    /// * the [] means that it's a commitment (i.e. a point on Bn254(F_p))
+    fn compute_commitment_linearised_polynomial(
+        curve: IBn254CurveDispatcher,
+        proof: Span<u256>,
+        beta: u256,
+        gamma: u256,
+        alpha: u256,
+        zeta: u256,
+        alpha_square_lagrange_0: u256,
+    ) -> G1Point {
+        let l = *proof.at(PROOF_L_AT_ZETA);
+        let r = *proof.at(PROOF_R_AT_ZETA);
+        let o = *proof.at(PROOF_O_AT_ZETA);
+
+        let mut u = mul_mod(*proof.at(PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA), beta);
+        let mut v = mul_mod(beta, *proof.at(PROOF_S1_AT_ZETA));
+        v = add_mod(v, l);
+        v = add_mod(v, gamma);
+
+        let mut w = mul_mod(beta, *proof.at(PROOF_S2_AT_ZETA));
+        w = add_mod(w, r);
+        w = add_mod(w, gamma);
+
+        let mut s1 = mul_mod(u, v);
+        s1 = mul_mod(s1, w);
+        s1 = mul_mod(s1, alpha);
+
+        let betazeta = mul_mod(beta, zeta);
+        u = add_mod(betazeta, l);
+        u = add_mod(u, gamma);
+
+        v = mul_mod(betazeta, VK_COSET_SHIFT);
+        v = add_mod(v, r);
+        v = add_mod(v, gamma);
+
+        w = mul_mod(betazeta, mul_mod(VK_COSET_SHIFT, VK_COSET_SHIFT));
+        w = add_mod(w, o);
+        w = add_mod(w, gamma);
+
+        let mut s2 = mul_mod(u, v);
+        s2 = mul_mod(s2, w);
+        s2 = sub_mod(0, s2);
+        s2 = mul_mod(s2, alpha);
+        s2 = add_mod(s2, alpha_square_lagrange_0);
+
+        // at this stage:
+        // * s₁ = α*Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β
+        // * s₂ = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ) + α²*L₁(ζ)
+
+        let mut points = array![
+            G1Point { x: VK_QL_COM_X, y: VK_QL_COM_Y },
+            G1Point { x: VK_QR_COM_X, y: VK_QR_COM_Y },
+            G1Point { x: VK_QM_COM_X, y: VK_QM_COM_Y },
+            G1Point { x: VK_QO_COM_X, y: VK_QO_COM_Y },
+            G1Point { x: VK_QK_COM_X, y: VK_QK_COM_Y },
+        ];
+        let mut scalars = array![l, r, mul_mod(l, r), o, 1];
+        {{- range $index, $element := .CommitmentConstraintIndexes }}
+        points.append(point_at(proof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + {{ mul $index 2 }}));
+        scalars.append(*proof.at(PROOF_OPENING_QCP_AT_ZETA + {{ $index }}));
+        {{- end }}
+        points.append(G1Point { x: VK_S3_COM_X, y: VK_S3_COM_Y });
+        scalars.append(s1);
+        points.append(point_at(proof, PROOF_GRAND_PRODUCT_COMMITMENT));
+        scalars.append(s2);
+        {{- if gt (len .Qg) 0 }}
+        let custom_gates = compute_custom_gates(proof, alpha);
+        {{- range $index, $element := .Qg }}
+        points.append(G1Point { x: VK_QG_{{ $index }}_X, y: VK_QG_{{ $index }}_Y });
+        scalars.append(*custom_gates.at({{ $index }}));
+        {{- end }}
+        {{- end }}
+        curve.msm(points.span(), scalars.span())
+    }
+    {{- if gt (len .Qg) 0 }}
+
+    /// Computes the gᵢ(ζ), where gᵢ(ζ) is the sum of the identities of the i-th
+    /// custom gate, evaluated on l(ζ), r(ζ), o(ζ) and l(ζω), r(ζω), o(ζω).
+    /// The j-th identity (counted over all the gates) is scaled by α³⁺ʲ,
+    /// α and α² being used by the permutation argument.
+    fn compute_custom_gates(proof: Span<u256>, alpha: u256) -> Array<u256> {
+        let mut alpha_power = mul_mod(mul_mod(alpha, alpha), alpha);
+        let mut gates = array![];
+        {{- range $index, $gate := customGates }}
+
+        let mut gate = 0;
+        {{- range $identity := $gate }}
+        let mut identity = 0;
+        {{- range $term := $identity }}
+        identity = add_mod(identity, {{ $term }});
+        {{- end }}
+        gate = add_mod(gate, mul_mod(identity, alpha_power));
+        alpha_power = mul_mod(alpha_power, alpha);
+        {{- end }}
+        gates.append(gate);
+        {{- end }}
+        gates
+    }
+    {{- end }}
+
+    /// generate the challenge (using Fiat Shamir) to fold the opening proofs
+    /// at ζ, as Sha256("gamma" || ζ || [H] || [Linearised polynomial] || [L] || [R] || [O] || [S₁] || [S₂] || [Pi_{i}]
+    /// || H(ζ) || Linearised_polynomial(ζ) || L(ζ) || R(ζ) || O(ζ) || S₁(ζ) || S₂(ζ) || Pi_{i}(ζ) || Z(ζω){{ if spansTwoRows }} || L(ζω) || R(ζω) || O(ζω){{ end }})
+    fn compute_gamma_kzg(proof: Span<u256>, zeta: u256, folded_h: G1Point, linearised_polynomial: G1Point) -> u256 {
+        let mut transcript: ByteArray = "gamma";
+        append_u256(ref transcript, zeta);
+        append_u256(ref transcript, folded_h.x);
+        append_u256(ref transcript, folded_h.y);
+        append_u256(ref transcript, linearised_polynomial.x);
+        append_u256(ref transcript, linearised_polynomial.y);
+        append_words(ref transcript, proof, PROOF_L_COM, 6);
+        append_u256(ref transcript, VK_S1_COM_X);
+        append_u256(ref transcript, VK_S1_COM_Y);
+        append_u256(ref transcript, VK_S2_COM_X);
+        append_u256(ref transcript, VK_S2_COM_Y);
+        {{- range $index, $element := .CommitmentConstraintIndexes }}
+        append_u256(ref transcript, VK_QCP_{{ $index }}_X);
+        append_u256(ref transcript, VK_QCP_{{ $index }}_Y);
+        {{- end }}
+        append_words(ref transcript, proof, PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA, 2);
+        append_words(ref transcript, proof, PROOF_L_AT_ZETA, 5);
+        append_words(ref transcript, proof, PROOF_OPENING_QCP_AT_ZETA, VK_NB_CUSTOM_GATES);
+        append_words(ref transcript, proof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA, 1);
+        {{- if spansTwoRows }}
+        append_words(ref transcript, proof, PROOF_L_AT_ZETA_OMEGA, 3);
+        {{- end }}
+        sha256(@transcript) % R_MOD
+    }
+
+    /// Fold the opening proofs at ζ:
+    /// * [H] + γ[Linearised_polynomial]+γ²[L] + γ³[R] + γ⁴[O] + γ⁵[S₁] +γ⁶[S₂] + ∑ᵢγ⁶⁺ⁱ[Pi_{i}]
+    /// * H(ζ) + γLinearised_polynomial(ζ)+γ²L(ζ) + γ³R(ζ)+ γ⁴O(ζ) + γ⁵S₁(ζ) +γ⁶S₂(ζ) + ∑ᵢγ⁶⁺ⁱPi_{i}(ζ)
+    /// returns the folded digests and the folded claimed values
+    fn fold_state(
+        curve: IBn254CurveDispatcher,
+        proof: Span<u256>,
+        gamma_kzg: u256,
+        folded_h: G1Point,
+        linearised_polynomial: G1Point,
+    ) -> (G1Point, u256) {
+        let points = array![
+            folded_h,
+            linearised_polynomial,
+            point_at(proof, PROOF_L_COM),
+            point_at(proof, PROOF_R_COM),
+            point_at(proof, PROOF_O_COM),
+            G1Point { x: VK_S1_COM_X, y: VK_S1_COM_Y },
+            G1Point { x: VK_S2_COM_X, y: VK_S2_COM_Y },
+            {{- range $index, $element := .CommitmentConstraintIndexes }}
+            G1Point { x: VK_QCP_{{ $index }}_X, y: VK_QCP_{{ $index }}_Y },
+            {{- end }}
+        ];
+        let claimed_values = array![
+            *proof.at(PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA),
+            *proof.at(PROOF_LINEARISED_POLYNOMIAL_AT_ZETA),
+            *proof.at(PROOF_L_AT_ZETA),
+            *proof.at(PROOF_R_AT_ZETA),
+            *proof.at(PROOF_O_AT_ZETA),
+            *proof.at(PROOF_S1_AT_ZETA),
+            *proof.at(PROOF_S2_AT_ZETA),
+            {{- range $index, $element := .CommitmentConstraintIndexes }}
+            *proof.at(PROOF_OPENING_QCP_AT_ZETA + {{ $index }}),
+            {{- end }}
+        ];
+
+        let mut scalars = array![];
+        let mut folded_claimed_values = 0;
+        let mut acc_gamma = 1;
+        for claimed_value in claimed_values {
+            folded_claimed_values = add_mod(folded_claimed_values, mul_mod(claimed_value, acc_gamma));
+            scalars.append(acc_gamma);
+            acc_gamma = mul_mod(acc_gamma, gamma_kzg);
+        };
+        (curve.msm(points.span(), scalars.span()), folded_claimed_values)
+    }
+    {{- if spansTwoRows }}
+
+    /// Fold the openings of l, r, o at ζω:
+    /// * [L] + γ[R] + γ²[O]
+    /// * L(ζω) + γR(ζω) + γ²O(ζω)
+    /// where γ = Sha256("gamma" || ζω || [L] || [R] || [O] || L(ζω) || R(ζω) || O(ζω)).
+    /// returns the folded digests and the folded claimed values
+    fn fold_lro_at_zeta_omega(curve: IBn254CurveDispatcher, proof: Span<u256>, zeta: u256) -> (G1Point, u256) {
+        let mut transcript: ByteArray = "gamma";
+        append_u256(ref transcript, mul_mod(zeta, VK_OMEGA));
+        append_words(ref transcript, proof, PROOF_L_COM, 6);
+        append_words(ref transcript, proof, PROOF_L_AT_ZETA_OMEGA, 3);
+        let gamma = sha256(@transcript) % R_MOD;
+        let gamma_square = mul_mod(gamma, gamma);
+
+        let folded_lro = curve
+            .msm(
+                array![point_at(proof, PROOF_L_COM), point_at(proof, PROOF_R_COM), point_at(proof, PROOF_O_COM)].span(),
+                array![1, gamma, gamma_square].span(),
+            );
+        let mut folded_claimed_value = *proof.at(PROOF_L_AT_ZETA_OMEGA);
+        folded_claimed_value = add_mod(folded_claimed_value, mul_mod(*proof.at(PROOF_R_AT_ZETA_OMEGA), gamma));
+        folded_claimed_value = add_mod(folded_claimed_value, mul_mod(*proof.at(PROOF_O_AT_ZETA_OMEGA), gamma_square));
+        (folded_lro, folded_claimed_value)
+    }
+    {{- end }}
+
+    /// follows alg. p.13 of https://eprint.iacr.org/2019/953.pdf
+    /// with t₁ = t₂ = 1, and the proofs are ([digest] + [quotient] +purported evaluation):
+    /// * [folded_digests], [proof_batch_opening_at_zeta], folded_claimed_values
+    /// * [proof_grand_product_commitment], [proof_opening_at_zeta_omega], [proof_grand_product_at_zeta_omega]
+    {{- if spansTwoRows }}
+    /// * [folded_lro], [proof_opening_lro_at_zeta_omega], folded_lro_claimed_value
+    {{- end }}
+    fn batch_verify_multi_points(
+        curve: IBn254CurveDispatcher,
+        proof: Span<u256>,
+        zeta: u256,
+        gamma_kzg: u256,
+        folded_digests: G1Point,
+        folded_claimed_values: u256,
+    ) -> bool {
+        // derive a random number. As there is no random generator, we
+        // do an FS like challenge derivation, depending on both digests and
+        // ζ to ensure that the prover cannot control the random number.
+        // Note: adding the other point ζω is not needed, as ω is known beforehand.
+        let mut transcript: ByteArray = Default::default();
+        append_u256(ref transcript, folded_digests.x);
+        append_u256(ref transcript, folded_digests.y);
+        append_words(ref transcript, proof, PROOF_BATCH_OPENING_AT_ZETA, 2);
+        append_words(ref transcript, proof, PROOF_GRAND_PRODUCT_COMMITMENT, 2);
+        append_words(ref transcript, proof, PROOF_OPENING_AT_ZETA_OMEGA, 2);
+        append_u256(ref transcript, zeta);
+        append_u256(ref transcript, gamma_kzg);
+        {{- if spansTwoRows }}
+        append_words(ref transcript, proof, PROOF_OPENING_LRO_AT_ZETA_OMEGA, 2);
+        {{- end }}
+        let random = sha256(@transcript) % R_MOD;
+
+        let opening_at_zeta = point_at(proof, PROOF_BATCH_OPENING_AT_ZETA);
+        let opening_at_zeta_omega = point_at(proof, PROOF_OPENING_AT_ZETA_OMEGA);
+        {{- if spansTwoRows }}
+
+        // the opening of l, r, o at ζω is batched with λ = random²
+        let (folded_lro, folded_lro_claimed_value) = fold_lro_at_zeta_omega(curve, proof, zeta);
+        let opening_lro_at_zeta_omega = point_at(proof, PROOF_OPENING_LRO_AT_ZETA_OMEGA);
+        let random_square = mul_mod(random, random);
+
+        // [Wζ] + random⋅[Wζω] + λ⋅[Wlro]
+        let folded_quotients = curve
+            .msm(
+                array![opening_at_zeta, opening_at_zeta_omega, opening_lro_at_zeta_omega].span(),
+                array![1, random, random_square].span(),
+            );
+        {{- else }}
+
+        // [Wζ] + random⋅[Wζω]
+        let folded_quotients = curve
+            .msm(array![opening_at_zeta, opening_at_zeta_omega].span(), array![1, random].span());
+        {{- end }}
+
+        // [folded_digests] + random⋅[Z] - (folded_evals + random⋅Z(ζω))⋅[1] + ζ⋅[Wζ] + random⋅ζω⋅[Wζω]
+        let folded_evals = add_mod(
+            folded_claimed_values, mul_mod(random, *proof.at(PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA)),
+        );
+        {{- if spansTwoRows }}
+        // + λ⋅[folded_lro] - λ⋅folded_lro_claimed_value⋅[1] + λ⋅ζω⋅[Wlro]
+        let folded_evals = add_mod(folded_evals, mul_mod(random_square, folded_lro_claimed_value));
+        {{- end }}
+        let zeta_omega = mul_mod(zeta, VK_OMEGA);
+        let digests = curve
+            .msm(
+                array![
+                    folded_digests,
+                    point_at(proof, PROOF_GRAND_PRODUCT_COMMITMENT),
+                    G1Point { x: G1_SRS_X, y: G1_SRS_Y },
+                    opening_at_zeta,
+                    opening_at_zeta_omega,
+                    {{- if spansTwoRows }}
+                    folded_lro,
+                    opening_lro_at_zeta_omega,
+                    {{- end }}
+                ]
+                    .span(),
+                {{- if spansTwoRows }}
+                array![
+                    1,
+                    random,
+                    sub_mod(0, folded_evals),
+                    zeta,
+                    mul_mod(random, zeta_omega),
+                    random_square,
+                    mul_mod(random_square, zeta_omega),
+                ]
+                    .span(),
+                {{- else }}
+                array![1, random, sub_mod(0, folded_evals), zeta, mul_mod(random, zeta_omega)].span(),
+                {{- end }}
+            );
+
+        // e([digests], [1]) ⋅ e(-[folded_quotients], [x]) = 1
+        curve
+            .pairing_check(
+                array![digests, neg(folded_quotients)].span(),
+                array![
+                    G2Point { x0: G2_SRS_0_X_0, x1: G2_SRS_0_X_1, y0: G2_SRS_0_Y_0, y1: G2_SRS_0_Y_1 },
+                    G2Point { x0: G2_SRS_1_X_0, x1: G2_SRS_1_X_1, y0: G2_SRS_1_Y_0, y1: G2_SRS_1_Y_1 },
+                ]
+                    .span(),
+            )
+    }
+
+    // BEGINNING utils math functions -------------------------------------------------
+
+    fn add_mod(a: u256, b: u256) -> u256 {
+        // a, b < r < 2²⁵⁴ so the sum doesn't overflow
+        let res = a + b;
+        if res >= R_MOD {
+            res - R_MOD
+        } else {
+            res
+        }
+    }
+
+    fn sub_mod(a: u256, b: u256) -> u256 {
+        if a >= b {
+            a - b
+        } else {
+            a + (R_MOD - b)
+        }
+    }
+
+    fn mul_mod(a: u256, b: u256) -> u256 {
+        u256_mul_mod_n(a, b, R_MOD.try_into().unwrap())
+    }
+
+    fn inv_mod(x: u256) -> u256 {
+        u256_inv_mod(x, R_MOD.try_into().unwrap()).unwrap().into()
+    }
+
+    /// returns x ** e mod r
+    fn pow_mod(x: u256, e: u64) -> u256 {
+        let mut res = 1;
+        let mut base = x;
+        let mut e = e;
+        while e > 0 {
+            if e % 2 == 1 {
+                res = mul_mod(res, base);
+            }
+            base = mul_mod(base, base);
+            e /= 2;
+        };
+        res
+    }
+
+    fn neg(p: G1Point) -> G1Point {
+        if p.y == 0 {
+            p
+        } else {
+            G1Point { x: p.x, y: P_MOD - p.y }
+        }
+    }
+
+    fn point_at(proof: Span<u256>, offset: u32) -> G1Point {
+        G1Point { x: *proof.at(offset), y: *proof.at(offset + 1) }
+    }
+
+    /// Appends x to transcript, in big endian.
+    fn append_u256(ref transcript: ByteArray, x: u256) {
+        transcript.append_word(x.high.into(), 16);
+        transcript.append_word(x.low.into(), 16);
+    }
+
+    /// Appends the words of the proof in [offset, offset+n[ to transcript.
+    fn append_words(ref transcript: ByteArray, proof: Span<u256>, offset: u32, n: u32) {
+        let mut i = offset;
+        while i < offset + n {
+            append_u256(ref transcript, *proof.at(i));
+            i += 1;
+        };
+    }
+
+    /// Returns the Sha256 digest of data, interpreted in big endian.
+    fn sha256(data: @ByteArray) -> u256 {
+        let [w0, w1, w2, w3, w4, w5, w6, w7] = compute_sha256_byte_array(data);
+        u256 { high: u128_from_words(w0, w1, w2, w3), low: u128_from_words(w4, w5, w6, w7) }
+    }
+
+    fn u128_from_words(a: u32, b: u32, c: u32, d: u32) -> u128 {
+        let mut res: u128 = a.into();
+        res = res * 0x100000000 + b.into();
+        res = res * 0x100000000 + c.into();
+        res * 0x100000000 + d.into()
+    }
+}
+`
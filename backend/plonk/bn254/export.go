@@ -0,0 +1,126 @@
+package plonk
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"text/template"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+)
+
+// wireNames are the names of the offsets of the claimed values of the wires in
+// the proof, indexed by constraint.GateWire.
+var wireNames = [...]string{
+	"PROOF_L_AT_ZETA", "PROOF_R_AT_ZETA", "PROOF_O_AT_ZETA",
+	"PROOF_L_AT_ZETA_OMEGA", "PROOF_R_AT_ZETA_OMEGA", "PROOF_O_AT_ZETA_OMEGA",
+}
+
+// exportSyntax is the syntax of the expressions of a verifier contract in
+// which the identities of the custom gates are written.
+type exportSyntax struct {
+	wire     string // format of the claimed value of a wire, from its offset
+	constant string // format of a constant, from its decimal representation
+	mul      string // format of the product modulo r of two expressions
+}
+
+func init() {
+	for target, t := range map[backend.Target]struct {
+		src    string
+		syntax exportSyntax
+	}{
+		backend.Vyper: {tmplVyperVerifier, exportSyntax{"p[%s]", "%s", "uint256_mulmod(%s, %s, R_MOD)"}},
+		backend.Move:  {tmplMoveVerifier, exportSyntax{"fr_at(proof, %s)", "fr_from_u256(%s, 0)", "mul(&%s, &%s)"}},
+		backend.Cairo: {tmplCairoVerifier, exportSyntax{"*proof.at(%s)", "%s", "mul_mod(%s, %s)"}},
+	} {
+		t := t
+		backend.RegisterExporter(backend.PLONK, ecc.BN254, target, func(w io.Writer, vk any) error {
+			return vk.(*VerifyingKey).exportTemplate(w, t.src, t.syntax)
+		})
+	}
+}
+
+// exportTemplate executes the template of a verifier contract with vk, the
+// identities of the custom gates being written with syntax.
+func (vk *VerifyingKey) exportTemplate(w io.Writer, src string, syntax exportSyntax) error {
+	if vk.hasLookups() {
+		return errors.New("lookups are not supported by the exported verifier")
+	}
+	var wires [len(wireNames)]string
+	for i, name := range wireNames {
+		wires[i] = fmt.Sprintf(syntax.wire, name)
+	}
+	customGates := vk.customGateTerms(
+		wires,
+		func(c string) string { return fmt.Sprintf(syntax.constant, c) },
+		func(a, b string) string { return fmt.Sprintf(syntax.mul, a, b) },
+	)
+	funcMap := template.FuncMap{
+		"hex": func(i int) string {
+			return fmt.Sprintf("0x%x", i)
+		},
+		"mul": func(a, b int) int {
+			return a * b
+		},
+		"inc": func(i int) int {
+			return i + 1
+		},
+		"frstr": func(x fr.Element) string {
+			bv := new(big.Int)
+			x.BigInt(bv)
+			return bv.String()
+		},
+		"fpstr": func(x fp.Element) string {
+			bv := new(big.Int)
+			x.BigInt(bv)
+			return bv.String()
+		},
+		"add": func(i, j int) int {
+			return i + j
+		},
+		"intRange": func(max uint64) []uint64 {
+			out := make([]uint64, max)
+			for i := range out {
+				out[i] = uint64(i)
+			}
+			return out
+		},
+		"evmG1":  evmG1,
+		"moveG2": moveG2,
+		"customGates": func() [][][]string {
+			return customGates
+		},
+		"spansTwoRows": vk.customGatesSpanTwoRows,
+	}
+
+	t, err := template.New("t").Funcs(funcMap).Parse(src)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, vk)
+}
+
+// evmG1 returns the byte string literal of p encoded as in EIP-196: x then y,
+// in big endian.
+func evmG1(p curve.G1Affine) string {
+	return `x"` + hex.EncodeToString(p.Marshal()) + `"`
+}
+
+// moveG2 returns the byte string literal of p in the FormatG2Uncompr format of
+// the bn254_algebra module of Aptos: x.A0, x.A1, y.A0 then y.A1, in little endian.
+func moveG2(p curve.G2Affine) string {
+	b := make([]byte, 0, 4*fp.Bytes)
+	for _, x := range []*fp.Element{&p.X.A0, &p.X.A1, &p.Y.A0, &p.Y.A1} {
+		xb := x.Bytes()
+		for i := len(xb) - 1; i >= 0; i-- {
+			b = append(b, xb[i])
+		}
+	}
+	return `x"` + hex.EncodeToString(b) + `"`
+}
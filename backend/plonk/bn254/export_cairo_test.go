@@ -0,0 +1,292 @@
+//go:build cairocheck
+
+package plonk_test
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+const cairoManifest = `[package]
+name = "plonk_verifier"
+version = "0.1.0"
+edition = "2024_07"
+
+[dependencies]
+starknet = "2.8.4"
+
+[dev-dependencies]
+cairo_test = "2.8.4"
+
+[[target.starknet-contract]]
+`
+
+const cairoLib = `pub mod verifier;
+#[cfg(test)]
+mod test_curve;
+#[cfg(test)]
+mod tests;
+`
+
+// cairoTestCurve implements IBn254Curve for the SRS of toxic waste τ: the
+// multi scalar multiplications are computed with double-and-add in affine
+// coordinates, and the pairing check e(a₀, [1])⋅e(a₁, [τ]) = 1 of the verifier
+// is a₀ + τ⋅a₁ = 0. Starknet has no BN254 precompiles.
+var cairoTestCurve = template.Must(template.New("").Parse(`use crate::verifier::{G1Point, G2Point, IBn254Curve};
+
+#[starknet::contract]
+pub mod TestCurve {
+    use core::math::{u256_inv_mod, u256_mul_mod_n};
+    use super::{G1Point, G2Point, IBn254Curve};
+
+    const P_MOD: u256 = 21888242871839275222246405745257275088696311157297823662689037894645226208583;
+    const TAU: u256 = {{ .Tau }};
+
+    #[storage]
+    struct Storage {}
+
+    #[abi(embed_v0)]
+    impl TestCurveImpl of IBn254Curve<ContractState> {
+        fn msm(self: @ContractState, points: Span<G1Point>, scalars: Span<u256>) -> G1Point {
+            assert(points.len() == scalars.len(), 'msm: lengths differ');
+            let mut res = G1Point { x: 0, y: 0 };
+            let mut i = 0;
+            while i < points.len() {
+                assert(on_curve(*points.at(i)), 'msm: point not on curve');
+                res = add(res, scalar_mul(*points.at(i), *scalars.at(i)));
+                i += 1;
+            };
+            res
+        }
+
+        fn pairing_check(self: @ContractState, a: Span<G1Point>, b: Span<G2Point>) -> bool {
+            assert(a.len() == 2 && b.len() == 2, 'unexpected pairing check');
+            assert(
+                g2_eq(*b.at(0), {{ .G2One }}) && g2_eq(*b.at(1), {{ .G2Tau }}),
+                'unexpected pairing check',
+            );
+            assert(on_curve(*a.at(0)) && on_curve(*a.at(1)), 'pairing: point not on curve');
+            is_zero(add(*a.at(0), scalar_mul(*a.at(1), TAU)))
+        }
+    }
+
+    fn g2_eq(a: G2Point, b: G2Point) -> bool {
+        a.x0 == b.x0 && a.x1 == b.x1 && a.y0 == b.y0 && a.y1 == b.y1
+    }
+
+    /// The point at infinity is encoded as (0, 0), as in EIP-196.
+    fn is_zero(p: G1Point) -> bool {
+        p.x == 0 && p.y == 0
+    }
+
+    /// Returns true if y² = x³ + 3.
+    fn on_curve(p: G1Point) -> bool {
+        if is_zero(p) {
+            return true;
+        }
+        if p.x >= P_MOD || p.y >= P_MOD {
+            return false;
+        }
+        mul_mod(p.y, p.y) == add_mod(mul_mod(mul_mod(p.x, p.x), p.x), 3)
+    }
+
+    fn add(p: G1Point, q: G1Point) -> G1Point {
+        if is_zero(p) {
+            return q;
+        }
+        if is_zero(q) {
+            return p;
+        }
+        if p.x == q.x {
+            if p.y == q.y {
+                return double(p);
+            }
+            return G1Point { x: 0, y: 0 };
+        }
+        let lambda = mul_mod(sub_mod(q.y, p.y), inv_mod(sub_mod(q.x, p.x)));
+        let x = sub_mod(sub_mod(mul_mod(lambda, lambda), p.x), q.x);
+        G1Point { x, y: sub_mod(mul_mod(lambda, sub_mod(p.x, x)), p.y) }
+    }
+
+    /// G1 has no point of order 2, so y ≠ 0.
+    fn double(p: G1Point) -> G1Point {
+        if is_zero(p) {
+            return p;
+        }
+        let x_square = mul_mod(p.x, p.x);
+        let lambda = mul_mod(add_mod(add_mod(x_square, x_square), x_square), inv_mod(add_mod(p.y, p.y)));
+        let x = sub_mod(mul_mod(lambda, lambda), add_mod(p.x, p.x));
+        G1Point { x, y: sub_mod(mul_mod(lambda, sub_mod(p.x, x)), p.y) }
+    }
+
+    fn scalar_mul(p: G1Point, s: u256) -> G1Point {
+        let mut res = G1Point { x: 0, y: 0 };
+        let mut base = p;
+        let mut s = s;
+        while s != 0 {
+            if s % 2 == 1 {
+                res = add(res, base);
+            }
+            base = double(base);
+            s = s / 2;
+        };
+        res
+    }
+
+    fn add_mod(a: u256, b: u256) -> u256 {
+        // a, b < p < 2²⁵⁴ so the sum doesn't overflow
+        let res = a + b;
+        if res >= P_MOD {
+            res - P_MOD
+        } else {
+            res
+        }
+    }
+
+    fn sub_mod(a: u256, b: u256) -> u256 {
+        if a >= b {
+            a - b
+        } else {
+            a + (P_MOD - b)
+        }
+    }
+
+    fn mul_mod(a: u256, b: u256) -> u256 {
+        u256_mul_mod_n(a, b, P_MOD.try_into().unwrap())
+    }
+
+    fn inv_mod(x: u256) -> u256 {
+        u256_inv_mod(x, P_MOD.try_into().unwrap()).unwrap().into()
+    }
+}
+`))
+
+var cairoTests = template.Must(template.New("").Parse(`use starknet::SyscallResultTrait;
+use starknet::syscalls::deploy_syscall;
+use crate::test_curve::TestCurve;
+use crate::verifier::{IPlonkVerifierDispatcher, IPlonkVerifierDispatcherTrait, PlonkVerifier};
+
+fn deploy() -> IPlonkVerifierDispatcher {
+    let (curve, _) = deploy_syscall(TestCurve::TEST_CLASS_HASH.try_into().unwrap(), 0, array![].span(), false)
+        .unwrap_syscall();
+    let (verifier, _) = deploy_syscall(
+        PlonkVerifier::TEST_CLASS_HASH.try_into().unwrap(), 0, array![curve.into()].span(), false,
+    )
+        .unwrap_syscall();
+    IPlonkVerifierDispatcher { contract_address: verifier }
+}
+
+// the multi scalar multiplications of the test curve are expensive
+#[test]
+#[available_gas(100000000000)]
+fn test_verify_proof() {
+    assert(deploy().verify(array![{{.Proof}}].span(), array![{{.PublicInputs}}].span()), 'proof rejected');
+}
+
+#[test]
+#[available_gas(100000000000)]
+fn test_wrong_public_inputs() {
+    assert(!deploy().verify(array![{{.Proof}}].span(), array![{{.WrongPublicInputs}}].span()), 'proof accepted');
+}
+
+#[test]
+#[available_gas(100000000000)]
+fn test_tampered_proof() {
+    assert(!deploy().verify(array![{{.TamperedProof}}].span(), array![{{.PublicInputs}}].span()), 'proof accepted');
+}
+`))
+
+// TestExportCairo runs Cairo tests verifying proofs with the Cairo verifiers,
+// with Scarb, which must be in the PATH.
+func TestExportCairo(t *testing.T) {
+	for name, p := range exportCheckProofs(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := require.New(t)
+			dir := t.TempDir()
+			src := filepath.Join(dir, "src")
+			assert.NoError(os.Mkdir(src, 0700))
+			assert.NoError(os.WriteFile(filepath.Join(dir, "Scarb.toml"), []byte(cairoManifest), 0600))
+			assert.NoError(os.WriteFile(filepath.Join(src, "lib.cairo"), []byte(cairoLib), 0600))
+
+			f, err := os.Create(filepath.Join(src, "verifier.cairo"))
+			assert.NoError(err)
+			assert.NoError(p.vk.Export(f, backend.Cairo))
+			assert.NoError(f.Close())
+
+			tau := exportToxicWaste()
+			var g2Tau curve.G2Affine
+			g2Tau.ScalarMultiplication(&p.vk.Kzg.G2[0], tau)
+			assert.True(g2Tau.Equal(&p.vk.Kzg.G2[1]), "the SRS is not derived from exportToxicSeed")
+			f, err = os.Create(filepath.Join(src, "test_curve.cairo"))
+			assert.NoError(err)
+			assert.NoError(cairoTestCurve.Execute(f, map[string]string{
+				"Tau":   tau.String(),
+				"G2One": cairoG2(&p.vk.Kzg.G2[0]),
+				"G2Tau": cairoG2(&p.vk.Kzg.G2[1]),
+			}))
+			assert.NoError(f.Close())
+
+			f, err = os.Create(filepath.Join(src, "tests.cairo"))
+			assert.NoError(err)
+			assert.NoError(cairoTests.Execute(f, map[string]string{
+				"Proof":             cairoProof(p.proof),
+				"TamperedProof":     cairoProof(p.tamperedProof),
+				"PublicInputs":      cairoU256s(p.publicInputs),
+				"WrongPublicInputs": cairoU256s(p.wrongPublicInputs),
+			}))
+			assert.NoError(f.Close())
+
+			cmd := exec.Command("scarb", "test")
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			assert.NoError(err, string(out))
+		})
+	}
+}
+
+// exportToxicWaste returns the toxic waste τ derived from exportToxicSeed, see
+// unsafekzg.WithToxicSeed.
+func exportToxicWaste() *big.Int {
+	digest := sha256.Sum256(exportToxicSeed)
+	tau := new(big.Int).SetBytes(digest[:])
+	return tau.Mod(tau, ecc.BN254.ScalarField())
+}
+
+// cairoG2 returns the G2Point literal of p.
+func cairoG2(p *curve.G2Affine) string {
+	return "G2Point { x0: " + p.X.A0.String() + ", x1: " + p.X.A1.String() +
+		", y0: " + p.Y.A0.String() + ", y1: " + p.Y.A1.String() + " }"
+}
+
+// cairoProof returns the 32 bytes words of the proof serialised with
+// MarshalSolidity, as u256 literals.
+func cairoProof(proof *plonk_bn254.Proof) string {
+	words := proof.MarshalSolidity()
+	res := make([]string, len(words)/32)
+	for i := range res {
+		res[i] = new(big.Int).SetBytes(words[32*i : 32*(i+1)]).String()
+	}
+	return strings.Join(res, ", ")
+}
+
+// cairoU256s returns the elements of v as u256 literals.
+func cairoU256s(v fr.Vector) string {
+	elems := make([]string, len(v))
+	for i := range v {
+		elems[i] = v[i].String()
+	}
+	return strings.Join(elems, ", ")
+}
@@ -0,0 +1,142 @@
+//go:build vypercheck || movecheck || cairocheck
+
+package plonk_test
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test/unsafekzg"
+	"github.com/stretchr/testify/require"
+)
+
+// exportCheckProof is a proof to be checked by an exported verifier, along
+// with a tampered proof and public inputs it must reject.
+type exportCheckProof struct {
+	vk                *plonk_bn254.VerifyingKey
+	proof             *plonk_bn254.Proof
+	tamperedProof     *plonk_bn254.Proof
+	publicInputs      fr.Vector
+	wrongPublicInputs fr.Vector
+}
+
+// exportCheckProofs returns the proof of the golden files in testdata and a
+// proof of a circuit with custom gates, one of them spanning two rows. Both
+// SRS are derived from exportToxicSeed.
+func exportCheckProofs(t *testing.T) map[string]exportCheckProof {
+	assert := require.New(t)
+	res := make(map[string]exportCheckProof)
+
+	vk := plonk.NewVerifyingKey(ecc.BN254)
+	readTestdata(t, "vk.bin", vk)
+	proof := plonk.NewProof(ecc.BN254)
+	readTestdata(t, "proof.bin", proof)
+	public, err := witness.New(ecc.BN254.ScalarField())
+	assert.NoError(err)
+	readTestdata(t, "public.wtns", public)
+	res["testdata"] = newExportCheckProof(t, vk, proof, public)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &exportCustomGateCircuit{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs, unsafekzg.WithToxicSeed(exportToxicSeed))
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(&exportCustomGateCircuit{X: 3, Y: 27, W: 5, Z: 86}, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	proof, err = plonk.Prove(ccs, pk, w)
+	assert.NoError(err)
+	public, err = w.Public()
+	assert.NoError(err)
+	res["customGates"] = newExportCheckProof(t, vk, proof, public)
+
+	return res
+}
+
+// newExportCheckProof checks the proof with the Go verifier and tampers with
+// its claimed value of Z at ζω.
+func newExportCheckProof(t *testing.T, vk plonk.VerifyingKey, proof plonk.Proof, public witness.Witness) exportCheckProof {
+	assert := require.New(t)
+	assert.NoError(plonk.Verify(proof, vk, public))
+
+	res := exportCheckProof{
+		vk:           vk.(*plonk_bn254.VerifyingKey),
+		proof:        proof.(*plonk_bn254.Proof),
+		publicInputs: public.Vector().(fr.Vector),
+	}
+	var one fr.Element
+	one.SetOne()
+	tampered := *res.proof
+	tampered.ZShiftedOpening.ClaimedValue.Add(&tampered.ZShiftedOpening.ClaimedValue, &one)
+	res.tamperedProof = &tampered
+	assert.Error(plonk.Verify(res.tamperedProof, vk, public))
+
+	res.wrongPublicInputs = make(fr.Vector, len(res.publicInputs))
+	copy(res.wrongPublicInputs, res.publicInputs)
+	res.wrongPublicInputs[0].Add(&res.wrongPublicInputs[0], &one)
+	return res
+}
+
+// marshalPublicInputs returns the public inputs in big endian, 32 bytes each.
+func marshalPublicInputs(v fr.Vector) []byte {
+	res := make([]byte, 0, len(v)*fr.Bytes)
+	for i := range v {
+		res = append(res, v[i].Marshal()...)
+	}
+	return res
+}
+
+var (
+	// y = x³ on the row (x, x², y)
+	cubeGate = constraint.CustomGate{
+		Name: "cube",
+		Identities: [][]constraint.GateTerm{
+			{{Coeff: 1, Wires: []constraint.GateWire{constraint.GateR}}, {Coeff: -1, Wires: []constraint.GateWire{constraint.GateL, constraint.GateL}}},
+			{{Coeff: 1, Wires: []constraint.GateWire{constraint.GateO}}, {Coeff: -1, Wires: []constraint.GateWire{constraint.GateR, constraint.GateL}}},
+		},
+	}
+
+	// z = x⋅y + w on the rows (x, y, w), (z, _, _)
+	mulAddGate = constraint.CustomGate{
+		Name: "mulAdd",
+		Identities: [][]constraint.GateTerm{{
+			{Coeff: 1, Wires: []constraint.GateWire{constraint.GateNextL}},
+			{Coeff: -1, Wires: []constraint.GateWire{constraint.GateL, constraint.GateR}},
+			{Coeff: -1, Wires: []constraint.GateWire{constraint.GateO}},
+		}},
+	}
+)
+
+type exportCustomGateCircuit struct {
+	X, Y frontend.Variable `gnark:",public"`
+	W, Z frontend.Variable
+}
+
+func (c *exportCustomGateCircuit) Define(api frontend.API) error {
+	papi := api.(frontend.PlonkAPI)
+	cube, err := papi.AddCustomGate(cubeGate)
+	if err != nil {
+		return err
+	}
+	mulAdd, err := papi.AddCustomGate(mulAddGate)
+	if err != nil {
+		return err
+	}
+	papi.AddCustomConstraint(cube, c.X, api.Mul(c.X, c.X), c.Y)
+	papi.AddCustomConstraint(mulAdd, c.X, c.Y, c.W, c.Z, 0, 0)
+
+	// the custom gate selectors come after the BSB22 commitment ones
+	cmt, err := api.(frontend.Committer).Commit(c.X, c.Z)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(cmt, 0)
+	return nil
+}
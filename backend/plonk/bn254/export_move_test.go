@@ -0,0 +1,96 @@
+//go:build movecheck
+
+package plonk_test
+
+import (
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	"github.com/stretchr/testify/require"
+)
+
+const moveManifest = `[package]
+name = "PlonkVerifier"
+version = "1.0.0"
+
+[addresses]
+verifier = "0xcafe"
+
+[dependencies.AptosStdlib]
+git = "https://github.com/aptos-labs/aptos-core.git"
+rev = "mainnet"
+subdir = "aptos-move/framework/aptos-stdlib"
+`
+
+var moveTests = template.Must(template.New("").Funcs(template.FuncMap{"u256s": moveU256s}).Parse(`#[test_only]
+module verifier::plonk_verifier_tests {
+    use aptos_std::crypto_algebra;
+    use verifier::plonk_verifier;
+
+    #[test(fx = @std)]
+    fun test_verify_proof(fx: signer) {
+        crypto_algebra::enable_cryptography_algebra_natives(&fx);
+        assert!(plonk_verifier::verify(x"{{.Proof}}", {{u256s .PublicInputs}}), 0);
+    }
+
+    #[test(fx = @std)]
+    fun test_wrong_public_inputs(fx: signer) {
+        crypto_algebra::enable_cryptography_algebra_natives(&fx);
+        assert!(!plonk_verifier::verify(x"{{.Proof}}", {{u256s .WrongPublicInputs}}), 0);
+    }
+
+    #[test(fx = @std)]
+    fun test_tampered_proof(fx: signer) {
+        crypto_algebra::enable_cryptography_algebra_natives(&fx);
+        assert!(!plonk_verifier::verify(x"{{.TamperedProof}}", {{u256s .PublicInputs}}), 0);
+    }
+}
+`))
+
+// TestExportMove runs Move unit tests verifying proofs with the Move verifiers,
+// with the Aptos CLI, which must be in the PATH.
+func TestExportMove(t *testing.T) {
+	for name, p := range exportCheckProofs(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := require.New(t)
+			dir := t.TempDir()
+			assert.NoError(os.Mkdir(filepath.Join(dir, "sources"), 0700))
+			assert.NoError(os.Mkdir(filepath.Join(dir, "tests"), 0700))
+			assert.NoError(os.WriteFile(filepath.Join(dir, "Move.toml"), []byte(moveManifest), 0600))
+
+			f, err := os.Create(filepath.Join(dir, "sources", "verifier.move"))
+			assert.NoError(err)
+			assert.NoError(p.vk.Export(f, backend.Move))
+			assert.NoError(f.Close())
+
+			f, err = os.Create(filepath.Join(dir, "tests", "verifier_tests.move"))
+			assert.NoError(err)
+			assert.NoError(moveTests.Execute(f, map[string]any{
+				"Proof":             hex.EncodeToString(p.proof.MarshalSolidity()),
+				"TamperedProof":     hex.EncodeToString(p.tamperedProof.MarshalSolidity()),
+				"PublicInputs":      p.publicInputs,
+				"WrongPublicInputs": p.wrongPublicInputs,
+			}))
+			assert.NoError(f.Close())
+
+			out, err := exec.Command("aptos", "move", "test", "--package-dir", dir).CombinedOutput()
+			assert.NoError(err, string(out))
+		})
+	}
+}
+
+// moveU256s returns the vector<u256> literal of v.
+func moveU256s(v fr.Vector) string {
+	elems := make([]string, len(v))
+	for i := range v {
+		elems[i] = v[i].String() + "u256"
+	}
+	return "vector[" + strings.Join(elems, ", ") + "]"
+}
@@ -0,0 +1,111 @@
+package plonk_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test/unsafekzg"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "regenerate the proof, the verifying key and the golden verifiers in testdata")
+
+// exportToxicSeed is the seed of the SRS of the testdata, see
+// unsafekzg.WithToxicSeed. Knowing τ, the tests running the exported verifiers
+// can check the pairings with scalar multiplications in G1.
+var exportToxicSeed = []byte("gnark plonk export testdata")
+
+type exportCircuit struct {
+	X    frontend.Variable
+	Y, Z frontend.Variable `gnark:",public"`
+}
+
+func (c *exportCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	api.AssertIsEqual(api.Add(c.X, c.Y), c.Z)
+	cmt, err := api.(frontend.Committer).Commit(c.X, c.Z)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(cmt, 0)
+	return nil
+}
+
+// TestExportGolden checks the verifiers exported for a known proof/vk pair
+// against the golden files in testdata.
+func TestExportGolden(t *testing.T) {
+	assert := require.New(t)
+
+	if *update {
+		ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &exportCircuit{})
+		assert.NoError(err)
+		srs, srsLagrange, err := unsafekzg.NewSRS(ccs, unsafekzg.WithToxicSeed(exportToxicSeed))
+		assert.NoError(err)
+		pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+		assert.NoError(err)
+		w, err := frontend.NewWitness(&exportCircuit{X: 3, Y: 9, Z: 12}, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		proof, err := plonk.Prove(ccs, pk, w)
+		assert.NoError(err)
+		public, err := w.Public()
+		assert.NoError(err)
+
+		writeTestdata(t, "vk.bin", vk)
+		writeTestdata(t, "proof.bin", proof)
+		writeTestdata(t, "public.wtns", public)
+	}
+
+	vk := plonk.NewVerifyingKey(ecc.BN254)
+	readTestdata(t, "vk.bin", vk)
+	proof := plonk.NewProof(ecc.BN254)
+	readTestdata(t, "proof.bin", proof)
+	public, err := witness.New(ecc.BN254.ScalarField())
+	assert.NoError(err)
+	readTestdata(t, "public.wtns", public)
+	assert.NoError(plonk.Verify(proof, vk, public))
+
+	for target, name := range map[backend.Target]string{
+		backend.Vyper: "verifier.vy",
+		backend.Move:  "verifier.move",
+		backend.Cairo: "verifier.cairo",
+	} {
+		var buf bytes.Buffer
+		assert.NoError(vk.Export(&buf, target), target)
+		path := filepath.Join("testdata", name)
+		if *update {
+			assert.NoError(os.WriteFile(path, buf.Bytes(), 0600))
+			continue
+		}
+		golden, err := os.ReadFile(path)
+		assert.NoError(err)
+		assert.Equal(string(golden), buf.String(), "%s verifier differs from %s, run the tests with -update", target, path)
+	}
+
+	assert.ErrorIs(vk.Export(io.Discard, backend.Target("noir")), backend.ErrUnsupportedTarget)
+}
+
+func writeTestdata(t *testing.T, name string, v io.WriterTo) {
+	f, err := os.Create(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = v.WriteTo(f)
+	require.NoError(t, err)
+}
+
+func readTestdata(t *testing.T, name string, v io.ReaderFrom) {
+	f, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = v.ReadFrom(f)
+	require.NoError(t, err)
+}
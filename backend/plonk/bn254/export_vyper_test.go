@@ -0,0 +1,43 @@
+//go:build vypercheck
+
+package plonk_test
+
+import (
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark/backend"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportVyper compiles the Vyper verifiers with vyper and checks them on an
+// EVM with evmchecker, see test/evmchecker. Both must be in the PATH.
+func TestExportVyper(t *testing.T) {
+	for name, p := range exportCheckProofs(t) {
+		t.Run(name, func(t *testing.T) {
+			assert := require.New(t)
+			path := filepath.Join(t.TempDir(), "verifier.vy")
+			f, err := os.Create(path)
+			assert.NoError(err)
+			assert.NoError(p.vk.Export(f, backend.Vyper))
+			assert.NoError(f.Close())
+
+			evmchecker := func(proof *plonk_bn254.Proof, publicInputs fr.Vector) ([]byte, error) {
+				return exec.Command("evmchecker", "--vyper", path, "--function", "Verify",
+					"--proof", hex.EncodeToString(proof.MarshalSolidity()),
+					"--public-inputs", hex.EncodeToString(marshalPublicInputs(publicInputs))).CombinedOutput()
+			}
+			out, err := evmchecker(p.proof, p.publicInputs)
+			assert.NoError(err, string(out))
+			out, err = evmchecker(p.proof, p.wrongPublicInputs)
+			assert.Error(err, string(out))
+			out, err = evmchecker(p.tamperedProof, p.publicInputs)
+			assert.Error(err, string(out))
+		})
+	}
+}
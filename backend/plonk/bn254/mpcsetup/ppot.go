@@ -0,0 +1,108 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"errors"
+	"fmt"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark/internal/utils"
+	"io"
+)
+
+// ppotHashSize is the size of the BLAKE2b hash starting the files of the
+// Perpetual Powers of Tau ceremony.
+const ppotHashSize = 64
+
+// ImportPerpetualPowersOfTau imports the first size powers of τ from a
+// challenge file of the Perpetual Powers of Tau ceremony
+// (https://github.com/privacy-scaling-explorations/perpetualpowersoftau), for
+// example the last one, which holds the result of all the contributions.
+// power is the size of the ceremony the file comes from, which has 2ᵖᵒʷᵉʳ⁺¹-1
+// powers of τ in G₁ (28 for the main ceremony). Only the challenge files are
+// supported, the response files use compressed points.
+//
+// The returned ceremony can receive further contributions, or directly be
+// turned into a KZG SRS (see SRS()).
+func ImportPerpetualPowersOfTau(r io.Reader, power uint8, size int) (PowersOfTau, error) {
+	var p PowersOfTau
+	if power > 32 {
+		return p, errors.New("invalid ceremony power")
+	}
+	nbG1 := int64(1)<<(power+1) - 1
+	if size < 2 || int64(size) > nbG1 {
+		return p, fmt.Errorf("can't import %d powers of τ from a ceremony with %d of them", size, nbG1)
+	}
+
+	// the file starts with the hash of the previous response, then come the
+	// uncompressed {[τ⁰]₁, …, [τ²ⁿ⁻²]₁}, {[τ⁰]₂, …, [τⁿ⁻¹]₂}, {α[τ⁰]₁, …} and so on.
+	if _, err := io.CopyN(io.Discard, r, ppotHashSize); err != nil {
+		return p, err
+	}
+	buf := make([]byte, size*curve.SizeOfG1AffineUncompressed)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return p, err
+	}
+	p.Parameters.G1.Tau = make([]curve.G1Affine, size)
+	errs := make([]error, size)
+	utils.Parallelize(size, func(start, end int) {
+		for i := start; i < end; i++ {
+			errs[i] = setPPoTPoint(&p.Parameters.G1.Tau[i], buf[i*curve.SizeOfG1AffineUncompressed:(i+1)*curve.SizeOfG1AffineUncompressed])
+		}
+	})
+	for i := range errs {
+		if errs[i] != nil {
+			return p, fmt.Errorf("power %d of τ in G₁: %w", i, errs[i])
+		}
+	}
+
+	if _, err := io.CopyN(io.Discard, r, (nbG1-int64(size))*curve.SizeOfG1AffineUncompressed); err != nil {
+		return p, err
+	}
+	buf = make([]byte, curve.SizeOfG2AffineUncompressed)
+	for i := range p.Parameters.G2.Tau {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return p, err
+		}
+		if err := setPPoTPoint(&p.Parameters.G2.Tau[i], buf); err != nil {
+			return p, fmt.Errorf("power %d of τ in G₂: %w", i, err)
+		}
+	}
+
+	if err := p.checkPowers(); err != nil {
+		return p, err
+	}
+	p.Hash = p.hash()
+
+	return p, nil
+}
+
+// setPPoTPoint decodes an uncompressed point of the Perpetual Powers of Tau
+// ceremony, which uses the same encoding as gnark-crypto except for the point
+// at infinity, never found among the powers of a non-zero τ.
+func setPPoTPoint[P interface {
+	SetBytes([]byte) (int, error)
+}](point P, buf []byte) error {
+	n, err := point.SetBytes(buf)
+	if err != nil {
+		return err
+	}
+	if n != len(buf) {
+		return errors.New("unexpected point at infinity")
+	}
+	return nil
+}
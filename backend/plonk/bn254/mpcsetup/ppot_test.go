@@ -0,0 +1,72 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"bytes"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestImportPerpetualPowersOfTau(t *testing.T) {
+	const power = 4
+	assert := require.New(t)
+
+	// write a challenge file of a ceremony of the given power
+	var tau fr.Element
+	tau.SetRandom()
+	_, _, g1, g2 := curve.Generators()
+	nbG1 := 1<<(power+1) - 1
+	g1Tau := curve.BatchScalarMultiplicationG1(&g1, powers(tau, nbG1))
+	g2Tau := curve.BatchScalarMultiplicationG2(&g2, powers(tau, 1<<power))
+
+	var buf bytes.Buffer
+	buf.Write(make([]byte, ppotHashSize))
+	for i := range g1Tau {
+		b := g1Tau[i].RawBytes()
+		buf.Write(b[:])
+	}
+	for i := range g2Tau {
+		b := g2Tau[i].RawBytes()
+		buf.Write(b[:])
+	}
+	// the α and β powers, which aren't imported
+	buf.Write(make([]byte, 3*(1<<power)*curve.SizeOfG1AffineUncompressed))
+	challenge := buf.Bytes()
+
+	srs, err := ImportPerpetualPowersOfTau(bytes.NewReader(challenge), power, 8+3)
+	assert.NoError(err)
+	assert.Equal(g1Tau[:8+3], srs.Parameters.G1.Tau)
+	assert.Equal(g2Tau[:2], srs.Parameters.G2.Tau[:])
+
+	// the imported ceremony can be extended
+	prev := srs.clone()
+	srs.Contribute()
+	assert.NoError(VerifyPowersOfTau(&prev, &srs))
+	_, _, err = srs.SRS(8)
+	assert.NoError(err)
+
+	_, err = ImportPerpetualPowersOfTau(bytes.NewReader(challenge), power, nbG1+1)
+	assert.Error(err)
+
+	// a tampered point is detected
+	challenge[ppotHashSize+2*curve.SizeOfG1AffineUncompressed-1] ^= 1
+	_, err = ImportPerpetualPowersOfTau(bytes.NewReader(challenge), power, 8+3)
+	assert.Error(err)
+}
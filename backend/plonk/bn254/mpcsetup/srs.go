@@ -0,0 +1,54 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package mpcsetup
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+)
+
+// SRS returns the canonical and Lagrange KZG SRS for PLONK circuits whose
+// domain has domainSize elements, as expected by plonk.Setup. domainSize must
+// be a power of two, and the ceremony must have at least domainSize+3 powers
+// of τ in G₁.
+func (p *PowersOfTau) SRS(domainSize uint64) (canonical, lagrange *kzg.SRS, err error) {
+	if domainSize < 2 || ecc.NextPowerOfTwo(domainSize) != domainSize {
+		return nil, nil, errors.New("the domain size must be a power of two")
+	}
+	if uint64(len(p.Parameters.G1.Tau)) < domainSize+3 {
+		return nil, nil, fmt.Errorf("the ceremony is too small: got %d powers of τ, need %d", len(p.Parameters.G1.Tau), domainSize+3)
+	}
+
+	canonical = new(kzg.SRS)
+	canonical.Pk.G1 = make([]curve.G1Affine, domainSize+3)
+	copy(canonical.Pk.G1, p.Parameters.G1.Tau)
+	canonical.Vk.G1 = p.Parameters.G1.Tau[0]
+	canonical.Vk.G2 = p.Parameters.G2.Tau
+	canonical.Vk.Lines[0] = curve.PrecomputeLines(canonical.Vk.G2[0])
+	canonical.Vk.Lines[1] = curve.PrecomputeLines(canonical.Vk.G2[1])
+
+	lagrange = &kzg.SRS{Vk: canonical.Vk}
+	if lagrange.Pk.G1, err = kzg.ToLagrangeG1(canonical.Pk.G1[:domainSize]); err != nil {
+		return nil, nil, err
+	}
+
+	return canonical, lagrange, nil
+}
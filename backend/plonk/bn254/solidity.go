@@ -64,6 +64,10 @@ contract PlonkVerifier {
   uint256 private constant VK_INDEX_COMMIT_API{{ $index }} = {{ $element }};
   {{ end -}}
   uint256 private constant VK_NB_CUSTOM_GATES = {{ len .CommitmentConstraintIndexes }};
+  {{ range $index, $element := .Qg }}
+  uint256 private constant VK_QG_{{ $index }}_X = {{ (fpstr $element.X) }};
+  uint256 private constant VK_QG_{{ $index }}_Y = {{ (fpstr $element.Y) }};
+  {{ end }}
 
   // ------------------------------------------------
 
@@ -112,6 +116,14 @@ contract PlonkVerifier {
 
   // -> next part of proof is
   // [ openings_selector_commits || commitments_wires_commit_api]
+  {{ if spansTwoRows }}
+  // openings of l, r, o at zeta*omega, read by the custom gates spanning two rows
+  uint256 private constant PROOF_L_AT_ZETA_OMEGA = {{ hex (add 832 (mul (len .CommitmentConstraintIndexes) 96 ) )}};
+  uint256 private constant PROOF_R_AT_ZETA_OMEGA = {{ hex (add 864 (mul (len .CommitmentConstraintIndexes) 96 ) )}};
+  uint256 private constant PROOF_O_AT_ZETA_OMEGA = {{ hex (add 896 (mul (len .CommitmentConstraintIndexes) 96 ) )}};
+  uint256 private constant PROOF_OPENING_LRO_AT_ZETA_OMEGA_X = {{ hex (add 928 (mul (len .CommitmentConstraintIndexes) 96 ) )}};
+  uint256 private constant PROOF_OPENING_LRO_AT_ZETA_OMEGA_Y = {{ hex (add 960 (mul (len .CommitmentConstraintIndexes) 96 ) )}};
+  {{ end }}
 
   // -------- offset state
 
@@ -147,8 +159,16 @@ contract PlonkVerifier {
 
   uint256 private constant STATE_SUCCESS = 0x1e0;
   uint256 private constant STATE_CHECK_VAR = 0x200; // /!\ this slot is used for debugging only
-
+  {{ if spansTwoRows }}
+  // folded digests and claimed values of l, r, o at zeta*omega
+  uint256 private constant STATE_FOLDED_LRO_X = 0x220;
+  uint256 private constant STATE_FOLDED_LRO_Y = 0x240;
+  uint256 private constant STATE_FOLDED_LRO_CLAIMED_VALUE = 0x260;
+
+  uint256 private constant STATE_LAST_MEM = 0x280;
+  {{ else }}
   uint256 private constant STATE_LAST_MEM = 0x220;
+  {{ end }}
 
   // -------- errors
   uint256 private constant ERROR_STRING_ID = 0x08c379a000000000000000000000000000000000000000000000000000000000; // selector for function Error(string)
@@ -209,6 +229,9 @@ contract PlonkVerifier {
       compute_commitment_linearised_polynomial(proof.offset)
       compute_gamma_kzg(proof.offset)
       fold_state(proof.offset)
+      {{ if spansTwoRows -}}
+      fold_lro_at_zeta_omega(proof.offset)
+      {{ end -}}
       batch_verify_multi_points(proof.offset)
 
       success := mload(add(mem, STATE_SUCCESS))
@@ -313,6 +336,9 @@ contract PlonkVerifier {
       /// @param actual_proof_size size of the proof (not the expected size)
       function check_proof_size(actual_proof_size) {
         let expected_proof_size := add(0x340, mul(VK_NB_CUSTOM_GATES,0x60))
+        {{ if spansTwoRows -}}
+        expected_proof_size := add(expected_proof_size, 0xa0)
+        {{ end -}}
         if iszero(eq(actual_proof_size, expected_proof_size)) {
          error_proof_size() 
         }
@@ -382,7 +408,17 @@ contract PlonkVerifier {
           }
           p := add(p, 0x20)
         }
-
+        {{ if spansTwoRows }}
+        // PROOF_L_AT_ZETA_OMEGA, PROOF_R_AT_ZETA_OMEGA, PROOF_O_AT_ZETA_OMEGA
+        p := add(aproof, PROOF_L_AT_ZETA_OMEGA)
+        for {let i:=0} lt(i, 3) {i:=add(i,1)}
+        {
+          if gt(calldataload(p), R_MOD_MINUS_ONE) {
+            error_proof_openings_size()
+          }
+          p := add(p, 0x20)
+        }
+        {{ end }}
       }
       // end checks -------------------------------------------------
 
@@ -397,6 +433,7 @@ contract PlonkVerifier {
       /// * the word "gamma" in ascii, equal to [0x67,0x61,0x6d, 0x6d, 0x61] and encoded as a uint256.
       /// * the commitments to the permutation polynomials S1, S2, S3, where we concatenate the coordinates of those points
       /// * the commitments of Ql, Qr, Qm, Qo, Qk
+      /// * the commitments of the selectors Qcp_i, then of the selectors of the custom gates Qg_i
       /// * the public inputs
       /// * the commitments of the wires related to the custom gates (commitments_wires_commit_api)
       /// * commitments to L, R, O (proof_<l,r,o>_com_<x,y>)
@@ -434,8 +471,12 @@ contract PlonkVerifier {
         mstore(add(mPtr, {{ hex (add 544 (mul $index 64)) }}), VK_QCP_{{ $index }}_X)
         mstore(add(mPtr, {{ hex (add 576 (mul $index 64)) }}), VK_QCP_{{ $index }}_Y)
         {{ end }}
+        {{- range $index, $element := .Qg }}
+        mstore(add(mPtr, {{ hex (add 544 (mul (add $index (len $.CommitmentConstraintIndexes)) 64)) }}), VK_QG_{{ $index }}_X)
+        mstore(add(mPtr, {{ hex (add 576 (mul (add $index (len $.CommitmentConstraintIndexes)) 64)) }}), VK_QG_{{ $index }}_Y)
+        {{ end }}
         // public inputs
-        let _mPtr := add(mPtr, {{ hex (add (mul (len .CommitmentConstraintIndexes) 64) 544) }})
+        let _mPtr := add(mPtr, {{ hex (add (mul (add (len .CommitmentConstraintIndexes) (len .Qg)) 64) 544) }})
         let size_pi_in_bytes := mul(nb_pi, 0x20)
         calldatacopy(_mPtr, pi, size_pi_in_bytes)
         _mPtr := add(_mPtr, size_pi_in_bytes)
@@ -449,10 +490,14 @@ contract PlonkVerifier {
         // sizegamma(=0x5) + 11*64(=0x2c0)
         // + nb_public_inputs*0x20
         // + nb_custom gates*0x40
+        // + nb selectors of the custom gates*0x40
         let size := add(0x2c5, size_pi_in_bytes)
         {{ if (gt (len .CommitmentConstraintIndexes) 0 )}}
         size := add(size, mul(VK_NB_CUSTOM_GATES, 0x40))
         {{ end -}}
+        {{ if (gt (len .Qg) 0 ) -}}
+        size := add(size, {{ hex (mul (len .Qg) 64) }})
+        {{ end -}}
         let l_success := staticcall(gas(), 0x2, add(mPtr, 0x1b), size, mPtr, 0x20) //0x1b -> 000.."gamma"
         if iszero(l_success) {
           error_verify()
@@ -800,6 +845,9 @@ contract PlonkVerifier {
       /// with t₁ = t₂ = 1, and the proofs are ([digest] + [quotient] +purported evaluation):
       /// * [state_folded_state_digests], [proof_batch_opening_at_zeta_x], state_folded_evals
       /// * [proof_grand_product_commitment], [proof_opening_at_zeta_omega_x], [proof_grand_product_at_zeta_omega]
+      {{- if spansTwoRows }}
+      /// * [state_folded_lro], [proof_opening_lro_at_zeta_omega_x], state_folded_lro_claimed_value
+      {{- end }}
       /// @param aproof pointer to the proof
       function batch_verify_multi_points(aproof) {
         let state := mload(0x40)
@@ -819,7 +867,13 @@ contract PlonkVerifier {
         mstore(add(mPtr, 0xe0), calldataload(add(aproof, PROOF_OPENING_AT_ZETA_OMEGA_Y)))
         mstore(add(mPtr, 0x100), mload(add(state, STATE_ZETA)))
         mstore(add(mPtr, 0x120), mload(add(state, STATE_GAMMA_KZG)))
+        {{ if spansTwoRows -}}
+        mstore(add(mPtr, 0x140), calldataload(add(aproof, PROOF_OPENING_LRO_AT_ZETA_OMEGA_X)))
+        mstore(add(mPtr, 0x160), calldataload(add(aproof, PROOF_OPENING_LRO_AT_ZETA_OMEGA_Y)))
+        let random := staticcall(gas(), 0x2, mPtr, 0x180, mPtr, 0x20)
+        {{ else -}}
         let random := staticcall(gas(), 0x2, mPtr, 0x140, mPtr, 0x20)
+        {{ end -}}
         if iszero(random){
           error_random_generation()
         }
@@ -836,7 +890,9 @@ contract PlonkVerifier {
 
         let folded_evals := add(state, STATE_FOLDED_CLAIMED_VALUES)
         fr_acc_mul_calldata(folded_evals, add(aproof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA), random)
-
+        {{ if spansTwoRows -}}
+        acc_lro_opening_at_zeta_omega(aproof, folded_quotients, mulmod(random, random, R_MOD), mPtr)
+        {{ end }}
         let folded_evals_commit := mPtr
         mPtr := add(folded_evals_commit, 0x40)
         mstore(folded_evals_commit, G1_SRS_X)
@@ -883,6 +939,31 @@ contract PlonkVerifier {
         check_pairing_kzg(mPtr)
       }
 
+      {{ if spansTwoRows -}}
+      /// @notice accumulate the opening of l, r, o at ζω, folded by fold_lro_at_zeta_omega,
+      /// in the batched proof of batch_verify_multi_points, with the random number λ:
+      /// * folded_quotients += λ[proof_opening_lro_at_zeta_omega]
+      /// * state_folded_digests += λ[state_folded_lro] + λζω[proof_opening_lro_at_zeta_omega]
+      /// * state_folded_claimed_values += λ*state_folded_lro_claimed_value
+      /// @param aproof pointer to the proof
+      /// @param folded_quotients pointer to the folded quotients
+      /// @param lambda random number scaling the opening
+      /// @param mPtr free memory
+      function acc_lro_opening_at_zeta_omega(aproof, folded_quotients, lambda, mPtr) {
+        let state := mload(0x40)
+        let folded_digests := add(state, STATE_FOLDED_DIGESTS_X)
+        let zeta_omega := mulmod(mload(add(state, STATE_ZETA)), VK_OMEGA, R_MOD)
+
+        point_acc_mul_calldata(folded_quotients, add(aproof, PROOF_OPENING_LRO_AT_ZETA_OMEGA_X), lambda, mPtr)
+        point_acc_mul(folded_digests, add(state, STATE_FOLDED_LRO_X), lambda, mPtr)
+        point_acc_mul_calldata(folded_digests, add(aproof, PROOF_OPENING_LRO_AT_ZETA_OMEGA_X), mulmod(lambda, zeta_omega, R_MOD), mPtr)
+
+        let folded_evals := add(state, STATE_FOLDED_CLAIMED_VALUES)
+        let tmp := mulmod(mload(add(state, STATE_FOLDED_LRO_CLAIMED_VALUE)), lambda, R_MOD)
+        mstore(folded_evals, addmod(mload(folded_evals), tmp, R_MOD))
+      }
+
+      {{ end -}}
       /// @notice check_pairing_kzg checks the result of the final pairing product of the batched
       /// kzg verification. The purpose of this function is to avoid exhausting the stack
       /// in the function batch_verify_multi_points.
@@ -960,6 +1041,40 @@ contract PlonkVerifier {
 
       }
 
+      {{ if spansTwoRows -}}
+      /// @notice Fold the openings of l, r, o at ζω:
+      /// * at state+state_folded_lro we store: [L] + γ[R] + γ²[O]
+      /// * at state+state_folded_lro_claimed_value we store: l(ζω) + γr(ζω) + γ²o(ζω)
+      /// where γ is derived as in compute_gamma_kzg, from ζω, [L], [R], [O], l(ζω), r(ζω), o(ζω).
+      /// @param aproof pointer to the proof
+      function fold_lro_at_zeta_omega(aproof) {
+
+        let state := mload(0x40)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+        mstore(mPtr, 0x67616d6d61) // "gamma"
+        mstore(add(mPtr, 0x20), mulmod(mload(add(state, STATE_ZETA)), VK_OMEGA, R_MOD))
+        calldatacopy(add(mPtr, 0x40), add(aproof, PROOF_L_COM_X), 0xc0)
+        calldatacopy(add(mPtr, 0x100), add(aproof, PROOF_L_AT_ZETA_OMEGA), 0x60)
+        let check_staticcall := staticcall(gas(), 0x2, add(mPtr, 0x1b), 0x145, mPtr, 0x20)
+        if iszero(check_staticcall) {
+          error_verify()
+        }
+        let l_gamma := mod(mload(mPtr), R_MOD)
+        let gamma_square := mulmod(l_gamma, l_gamma, R_MOD)
+
+        let folded_lro := add(state, STATE_FOLDED_LRO_X)
+        mstore(folded_lro, calldataload(add(aproof, PROOF_L_COM_X)))
+        mstore(add(folded_lro, 0x20), calldataload(add(aproof, PROOF_L_COM_Y)))
+        point_acc_mul_calldata(folded_lro, add(aproof, PROOF_R_COM_X), l_gamma, mPtr)
+        point_acc_mul_calldata(folded_lro, add(aproof, PROOF_O_COM_X), gamma_square, mPtr)
+
+        let folded_claimed_value := add(state, STATE_FOLDED_LRO_CLAIMED_VALUE)
+        mstore(folded_claimed_value, calldataload(add(aproof, PROOF_L_AT_ZETA_OMEGA)))
+        fr_acc_mul_calldata(folded_claimed_value, add(aproof, PROOF_R_AT_ZETA_OMEGA), l_gamma)
+        fr_acc_mul_calldata(folded_claimed_value, add(aproof, PROOF_O_AT_ZETA_OMEGA), gamma_square)
+      }
+
+      {{ end -}}
       /// @notice generate the challenge (using Fiat Shamir) to fold the opening proofs
       /// at ζ.
       /// The process for deriving γ is the same as in derive_gamma but this time the inputs are
@@ -976,6 +1091,9 @@ contract PlonkVerifier {
       /// * L(ζ), R(ζ), O(ζ), S₁(ζ), S₂(ζ)
       /// * Pi_{i}(ζ)
       /// * Z(ζω)
+      {{- if spansTwoRows }}
+      /// * l(ζω), r(ζω), o(ζω)
+      {{- end }}
       /// @param aproof pointer to the proof
       function compute_gamma_kzg(aproof) {
 
@@ -1020,9 +1138,15 @@ contract PlonkVerifier {
         {{ end }}
 
         mstore(_mPtr, calldataload(add(aproof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA)))
+        {{- if spansTwoRows }}
+        calldatacopy(add(_mPtr, 0x20), add(aproof, PROOF_L_AT_ZETA_OMEGA), 0x60)
+        {{- end }}
 
         let start_input := 0x1b // 00.."gamma"
         let size_input := add(0x17, mul(VK_NB_CUSTOM_GATES,3)) // number of 32bytes elmts = 0x17 (zeta+2*7+7 for the digests+openings) + 2*VK_NB_CUSTOM_GATES (for the commitments of the selectors) + VK_NB_CUSTOM_GATES (for the openings of the selectors)
+        {{- if spansTwoRows }}
+        size_input := add(size_input, 3) // l(ζω), r(ζω), o(ζω)
+        {{- end }}
         size_input := add(0x5, mul(size_input, 0x20)) // size in bytes: 15*32 bytes + 5 bytes for gamma
         let check_staticcall := staticcall(gas(), 0x2, add(mPtr,start_input), size_input, add(state, STATE_GAMMA_KZG), 0x20)
         if iszero(check_staticcall) {
@@ -1107,8 +1231,9 @@ contract PlonkVerifier {
       /// @notice Compute the commitment to the linearized polynomial equal to
       ///	L(ζ)[Qₗ]+r(ζ)[Qᵣ]+R(ζ)L(ζ)[Qₘ]+O(ζ)[Qₒ]+[Qₖ]+Σᵢqc'ᵢ(ζ)[BsbCommitmentᵢ] +
       ///	α*( Z(μζ)(L(ζ)+β*S₁(ζ)+γ)*(R(ζ)+β*S₂(ζ)+γ)[S₃]-[Z](L(ζ)+β*id_{1}(ζ)+γ)*(R(ζ)+β*id_{2(ζ)+γ)*(O(ζ)+β*id_{3}(ζ)+γ) ) +
-      ///	α²*L₁(ζ)[Z]
+      ///	α²*L₁(ζ)[Z] + Σᵢgᵢ(ζ)[Qgᵢ]
       /// where
+      /// * gᵢ(ζ) is the i-th custom gate evaluated on the claimed values of the wires, see compute_custom_gates
       /// * id_1 = id, id_2 = vk_coset_shift*id, id_3 = vk_coset_shift^{2}*id
       /// * the [] means that it's a commitment (i.e. a point on Bn254(F_p))
       /// @param aproof pointer to the proof
@@ -1156,7 +1281,38 @@ contract PlonkVerifier {
         // * s₂ = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ) + α²*L₁(ζ)
 
         compute_commitment_linearised_polynomial_ec(aproof, s1, s2)
+        {{- if (gt (len .Qg) 0 ) }}
+        compute_custom_gates(aproof)
+        {{- end }}
+      }
+      {{ if (gt (len .Qg) 0 ) }}
+      /// @notice add Σᵢgᵢ(ζ)[Qgᵢ] to the commitment to the linearised polynomial, where
+      /// gᵢ(ζ) is the sum of the identities of the i-th custom gate, evaluated on l(ζ), r(ζ), o(ζ)
+      /// and l(ζω), r(ζω), o(ζω). The j-th identity (counted over all the gates) is scaled by α³⁺ʲ,
+      /// α and α² being used by the permutation argument.
+      /// @param aproof pointer to the proof
+      function compute_custom_gates(aproof) {
+        let state := mload(0x40)
+        let mPtr := add(mload(0x40), STATE_LAST_MEM)
+        let l_alpha := mload(add(state, STATE_ALPHA))
+        let alpha_power := mulmod(mulmod(l_alpha, l_alpha, R_MOD), l_alpha, R_MOD)
+        let gate, identity
+        {{ range $index, $gate := customGates }}
+        gate := 0
+        {{- range $identity := $gate }}
+        identity := 0
+        {{- range $term := $identity }}
+        identity := addmod(identity, {{ $term }}, R_MOD)
+        {{- end }}
+        gate := addmod(gate, mulmod(identity, alpha_power, R_MOD), R_MOD)
+        alpha_power := mulmod(alpha_power, l_alpha, R_MOD)
+        {{- end }}
+        mstore(mPtr, VK_QG_{{ $index }}_X)
+        mstore(add(mPtr, 0x20), VK_QG_{{ $index }}_Y)
+        point_acc_mul(add(state, STATE_LINEARISED_POLYNOMIAL_X), mPtr, gate, add(mPtr, 0x40))
+        {{ end }}
       }
+      {{ end }}
 
       /// @notice compute H₁ + ζᵐ⁺²*H₂ + ζ²⁽ᵐ⁺²⁾*H₃ and store the result at
       /// state + state_folded_h
@@ -1428,5 +1584,19 @@ func (proof *Proof) MarshalSolidity() []byte {
 		}
 	}
 
+	// uint256 l_at_zeta_omega;
+	// uint256 r_at_zeta_omega;
+	// uint256 o_at_zeta_omega;
+	// uint256 opening_lro_at_zeta_omega_x;
+	// uint256 opening_lro_at_zeta_omega_y;
+	if len(proof.LROShiftedOpening.ClaimedValues) > 0 {
+		for i := range proof.LROShiftedOpening.ClaimedValues {
+			tmp32 = proof.LROShiftedOpening.ClaimedValues[i].Bytes()
+			res = append(res, tmp32[:]...)
+		}
+		tmp64 = proof.LROShiftedOpening.H.RawBytes()
+		res = append(res, tmp64[:]...)
+	}
+
 	return res
 }
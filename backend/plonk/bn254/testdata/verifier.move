@@ -0,0 +1,569 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Copyright 2023 Consensys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+/// PlonK verifier on BN254, using the crypto_algebra module of Aptos.
+/// The module is published at the named address verifier.
+module verifier::plonk_verifier {
+    use std::bcs;
+    use std::hash;
+    use std::option;
+    use std::vector;
+    use aptos_std::bn254_algebra::{Fr, FormatFrLsb, FormatFrMsb, G1, FormatG1Uncompr, G2, FormatG2Uncompr, Gt};
+    use aptos_std::crypto_algebra::{Element, add, sub, mul, sqr, inv, eq, one, zero, deserialize, serialize, multi_scalar_mul, multi_pairing};
+
+    /// The number of public inputs is not the one of the verifying key.
+    const EWRONG_NUMBER_OF_PUBLIC_INPUTS: u64 = 1;
+    /// Some of the public inputs are bigger than r.
+    const EINPUTS_BIGGER_THAN_R: u64 = 2;
+    /// The proof doesn't have the expected size.
+    const EWRONG_PROOF_SIZE: u64 = 3;
+    /// Some of the claimed evaluations of the proof are bigger than r.
+    const EOPENINGS_BIGGER_THAN_R: u64 = 4;
+    /// Some of the points of the proof are not in G1.
+    const EINVALID_POINT: u64 = 5;
+
+    // The points of G1 are encoded as in EIP-196 (x then y, in big endian),
+    // the points of G2 in the format FormatG2Uncompr.
+    const G2_SRS_0: vector<u8> = x"edf692d95cbdde46ddda5ef7d422436779445c5e66006a42761e1f12efde0018c212f3aeb785e49712e7a9353349aaf1255dfb31b7bf60723a480d9293938e19aa7dfa6601cce64c7bd3430c69e7d1e38f40cb8d8071ab4aeb6d8cdba55ec8125b9722d1dcdaac55f38eb37033314bbc95330c69ad999eec75f05f58d0890609";
+    const G2_SRS_1: vector<u8> = x"b01c2f7784d3a31619cc31f53b103fcc34f5d0ad17429354ca86ebd0f07d6325384c52a66bfbc44e20249def7a5a3281ad5879dfb92de8124f1bf0726dcdfe2578a8737816d18985f3727e51667bf1581f1846ff7cf91fd876a8bb4c612c03130a19f3ec27c0e778c1bafae657aaa822974bc267224a484bf105d712a9adda1c";
+    const G1_SRS: vector<u8> = x"00000000000000000000000000000000000000000000000000000000000000010000000000000000000000000000000000000000000000000000000000000002";
+
+    // ----------------------- vk ---------------------
+    const VK_NB_PUBLIC_INPUTS: u64 = 2;
+    const VK_DOMAIN_SIZE: u64 = 16;
+    const VK_INV_DOMAIN_SIZE: u256 = 20520227692349320520856005386178695395514091625390032197217066424914820464641;
+    const VK_OMEGA: u256 = 14940766826517323942636479241147756311199852622225275649687664389641784935947;
+    const VK_QL_COM: vector<u8> = x"2894690f8881747c943e97a8f7db8f9067dcee5d594b119f6d8824f0168b65241838822db32994a1188a35ee6552047f6426bc0dc23482c7c771615ad6e33a9b";
+    const VK_QR_COM: vector<u8> = x"142089f588ba15ff2cec49b5d43583045ee462bccef90d0dedc2d4f6333bf55507132ed43117d550e40be79b499feca539155f88ef6a75532221ccc2e106211d";
+    const VK_QM_COM: vector<u8> = x"2c46725a2c6a57de9bf9cfdd56ca04e69d07df9a84cd8658bc21ae801e6e6bbd0bd0e670bf572c606da8f153350517eddcb2a6bcb508fb16f93f6d0077df1727";
+    const VK_QO_COM: vector<u8> = x"01d85c3374593144bc6e1996f74b60d7853b8c8a41804fea6370db68fbd3ebfd2e51187ae87260e213855207062ae4a0b4cddd41aed4651409ac575db14e4c7e";
+    const VK_QK_COM: vector<u8> = x"11a45d238696e40f5850017341afabab34853d3360470af7968c83e96a6fad332038e8a446a90a55eb7452b83297d630708fc8d25c24bce28d9063b15af29cb4";
+    const VK_S1_COM: vector<u8> = x"0f63139179e84e5b525bda1c1bc0340008d8198e540b170670ab56a1ce3e2e730e1c7f945338b8131905bc3fa9088205841ef1fa641d3555ad2d11ffd6592d23";
+    const VK_S2_COM: vector<u8> = x"1952dc4d9e88cdd80cba26e6825f4125496056130779bad8b8aa63e2210134061994ca5a37ead8d3104ed52ba71c8c4e910ab35a8ab1ecb5dd8ec1463548d378";
+    const VK_S3_COM: vector<u8> = x"2e637b302b2c95b78f1e4cb9f99fec29035e05e68b429fbc30b51be95757249a2b85889695af3c6432abf35b5a2ff8f45138968a3cf8f9387956359285274e1d";
+    const VK_COSET_SHIFT: u256 = 5;
+    const VK_QCP_0: vector<u8> = x"197afb7115da5608375b11223870a14c59657bc6933f4bc6dfc540998fa2fbfc08ec3071a47c0fbd08b0d3d15ec8a968400e3500a59888c26d7c4654c48cd064";
+    const VK_INDEX_COMMIT_API_0: u64 = 6;
+    const VK_NB_CUSTOM_GATES: u64 = 1;
+
+    // ------------------------------------------------
+
+    const PROOF_SIZE: u64 = 0x3a0;
+
+    // offset proof
+    const PROOF_L_COM: u64 = 0x00;
+    const PROOF_R_COM: u64 = 0x40;
+    const PROOF_O_COM: u64 = 0x80;
+
+    // h = h_0 + x^{n+2}h_1 + x^{2(n+2)}h_2
+    const PROOF_H_0: u64 = 0xc0;
+    const PROOF_H_1: u64 = 0x100;
+    const PROOF_H_2: u64 = 0x140;
+
+    // wire values at zeta
+    const PROOF_L_AT_ZETA: u64 = 0x180;
+    const PROOF_R_AT_ZETA: u64 = 0x1a0;
+    const PROOF_O_AT_ZETA: u64 = 0x1c0;
+
+    // Sσ1(zeta), Sσ2(zeta)
+    const PROOF_S1_AT_ZETA: u64 = 0x1e0;
+    const PROOF_S2_AT_ZETA: u64 = 0x200;
+
+    // [z(x)]
+    const PROOF_GRAND_PRODUCT_COMMITMENT: u64 = 0x220;
+
+    const PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA: u64 = 0x260; // z(w*zeta)
+    const PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA: u64 = 0x280; // t(zeta)
+    const PROOF_LINEARISED_POLYNOMIAL_AT_ZETA: u64 = 0x2a0; // r(zeta)
+
+    // Folded proof for the opening of linearised poly, l, r, o, s_1, s_2, qcp
+    const PROOF_BATCH_OPENING_AT_ZETA: u64 = 0x2c0; // [Wzeta]
+    const PROOF_OPENING_AT_ZETA_OMEGA: u64 = 0x300;
+
+    const PROOF_OPENING_QCP_AT_ZETA: u64 = 0x340;
+    const PROOF_COMMITMENTS_WIRES_CUSTOM_GATES: u64 = 0x360;
+
+    // domain separation tag of hash_fr
+    const HASH_FR_DST: vector<u8> = b"BSB22-Plonk";
+
+    #[view]
+    /// Verify a Plonk proof.
+    /// Aborts if the proof or the public inputs are malformed.
+    /// @param proof serialised plonk proof (using gnark's MarshalSolidity)
+    /// @param public_inputs (must be reduced)
+    /// @return true if the proof passes false otherwise
+    public fun verify(proof: vector<u8>, public_inputs: vector<u256>): bool {
+        // sanity checks
+        assert!(vector::length(&public_inputs) == VK_NB_PUBLIC_INPUTS, EWRONG_NUMBER_OF_PUBLIC_INPUTS);
+        assert!(vector::length(&proof) == PROOF_SIZE, EWRONG_PROOF_SIZE);
+        let inputs = vector::empty<Element<Fr>>();
+        let i = 0;
+        while (i < VK_NB_PUBLIC_INPUTS) {
+            vector::push_back(&mut inputs, fr_from_u256(*vector::borrow(&public_inputs, i), EINPUTS_BIGGER_THAN_R));
+            i = i + 1;
+        };
+
+        // compute the challenges
+        let (gamma, beta, alpha, zeta) = derive_challenges(&proof, &inputs);
+
+        // evaluation of Z=Xⁿ-1 at ζ
+        let zeta_power_n_minus_one = sub(&pow(&zeta, VK_DOMAIN_SIZE), &one<Fr>());
+
+        // public inputs contribution
+        let pi = compute_pi(&proof, &inputs, &zeta, &zeta_power_n_minus_one);
+
+        // α²⋅L₀(ζ) = α²⋅1/n⋅(ζⁿ-1)/(ζ-1)
+        let alpha_square_lagrange_0 = option::destroy_some(inv(&sub(&zeta, &one<Fr>())));
+        alpha_square_lagrange_0 = mul(&alpha_square_lagrange_0, &fr_from_u256(VK_INV_DOMAIN_SIZE, 0));
+        alpha_square_lagrange_0 = mul(&alpha_square_lagrange_0, &zeta_power_n_minus_one);
+        alpha_square_lagrange_0 = mul(&alpha_square_lagrange_0, &sqr(&alpha));
+
+        if (!verify_quotient_poly_eval_at_zeta(&proof, &beta, &gamma, &alpha, &pi, &alpha_square_lagrange_0, &zeta_power_n_minus_one)) {
+            return false
+        };
+
+        // H₀ + ζⁿ⁺²⋅H₁ + ζ²⁽ⁿ⁺²⁾⋅H₂
+        let zeta_power_n_plus_two = pow(&zeta, VK_DOMAIN_SIZE + 2);
+        let folded_h = multi_scalar_mul(
+            &vector[g1_from_evm(&proof, PROOF_H_0), g1_from_evm(&proof, PROOF_H_1), g1_from_evm(&proof, PROOF_H_2)],
+            &vector[one<Fr>(), zeta_power_n_plus_two, sqr(&zeta_power_n_plus_two)],
+        );
+
+        let linearised_polynomial = compute_commitment_linearised_polynomial(&proof, &beta, &gamma, &alpha, &zeta, &alpha_square_lagrange_0);
+        let gamma_kzg = compute_gamma_kzg(&proof, &zeta, &folded_h, &linearised_polynomial);
+        let (folded_digests, folded_claimed_values) = fold_state(&proof, &gamma_kzg, folded_h, linearised_polynomial);
+        batch_verify_multi_points(&proof, &zeta, &gamma_kzg, &folded_digests, &folded_claimed_values)
+    }
+
+    // Beginning challenges -------------------------------------------------
+
+    /// Derives the challenges gamma, beta, alpha and zeta as in the Solidity verifier:
+    /// * gamma = Sha256("gamma" || [S₁] || [S₂] || [S₃] || [Qₗ] || [Qᵣ] || [Qₘ] || [Qₒ] || [Qₖ] || [Qcpᵢ] || [Qgᵢ] || public inputs || [L] || [R] || [O])
+    /// * beta = Sha256("beta" || gamma)
+    /// * alpha = Sha256("alpha" || beta || [BsbCommitmentᵢ] || [Z])
+    /// * zeta = Sha256("zeta" || alpha || [H₀] || [H₁] || [H₂])
+    /// where the previous challenges are not reduced.
+    fun derive_challenges(proof: &vector<u8>, inputs: &vector<Element<Fr>>): (Element<Fr>, Element<Fr>, Element<Fr>, Element<Fr>) {
+        let transcript = b"gamma";
+        vector::append(&mut transcript, VK_S1_COM);
+        vector::append(&mut transcript, VK_S2_COM);
+        vector::append(&mut transcript, VK_S3_COM);
+        vector::append(&mut transcript, VK_QL_COM);
+        vector::append(&mut transcript, VK_QR_COM);
+        vector::append(&mut transcript, VK_QM_COM);
+        vector::append(&mut transcript, VK_QO_COM);
+        vector::append(&mut transcript, VK_QK_COM);
+        vector::append(&mut transcript, VK_QCP_0);
+        let i = 0;
+        while (i < VK_NB_PUBLIC_INPUTS) {
+            vector::append(&mut transcript, serialize<Fr, FormatFrMsb>(vector::borrow(inputs, i)));
+            i = i + 1;
+        };
+        append_bytes(&mut transcript, proof, PROOF_L_COM, 0xc0);
+        let gamma_not_reduced = hash::sha2_256(transcript);
+        let gamma = fr_from_digest(&gamma_not_reduced);
+
+        let transcript = b"beta";
+        vector::append(&mut transcript, gamma_not_reduced);
+        let beta_not_reduced = hash::sha2_256(transcript);
+        let beta = fr_from_digest(&beta_not_reduced);
+
+        let transcript = b"alpha";
+        vector::append(&mut transcript, beta_not_reduced);
+        append_bytes(&mut transcript, proof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES, 0x40 * VK_NB_CUSTOM_GATES);
+        append_bytes(&mut transcript, proof, PROOF_GRAND_PRODUCT_COMMITMENT, 0x40);
+        let alpha_not_reduced = hash::sha2_256(transcript);
+        let alpha = fr_from_digest(&alpha_not_reduced);
+
+        let transcript = b"zeta";
+        vector::append(&mut transcript, alpha_not_reduced);
+        append_bytes(&mut transcript, proof, PROOF_H_0, 0xc0);
+        let zeta = fr_from_digest(&hash::sha2_256(transcript));
+
+        (gamma, beta, alpha, zeta)
+    }
+
+    // END challenges -------------------------------------------------
+
+    // BEGINNING compute_pi -------------------------------------------------
+
+    /// Computes the public inputs contribution ∑ᵢLᵢ(ζ)⋅piᵢ, including the public
+    /// inputs coming from the custom gates.
+    fun compute_pi(proof: &vector<u8>, inputs: &vector<Element<Fr>>, zeta: &Element<Fr>, zpnmo: &Element<Fr>): Element<Fr> {
+        let pi = zero<Fr>();
+        let i = 0;
+        while (i < VK_NB_PUBLIC_INPUTS) {
+            pi = add(&pi, &mul(&compute_ith_lagrange_at_z(zeta, zpnmo, i), vector::borrow(inputs, i)));
+            i = i + 1;
+        };
+        let h_fr = hash_fr(proof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + 0x0);
+        pi = add(&pi, &mul(&h_fr, &compute_ith_lagrange_at_z(zeta, zpnmo, VK_NB_PUBLIC_INPUTS + VK_INDEX_COMMIT_API_0)));
+        pi
+    }
+
+    /// Computes Lᵢ(ζ) = ωⁱ/n * (ζⁿ-1)/(ζ-ωⁱ)
+    fun compute_ith_lagrange_at_z(z: &Element<Fr>, zpnmo: &Element<Fr>, i: u64): Element<Fr> {
+        let w = pow(&fr_from_u256(VK_OMEGA, 0), i);
+        let res = option::destroy_some(inv(&sub(z, &w)));
+        res = mul(&res, &w);
+        res = mul(&res, &fr_from_u256(VK_INV_DOMAIN_SIZE, 0));
+        mul(&res, zpnmo)
+    }
+
+    /// Hashes the point at offset in the proof to the field, with expand_message_xmd,
+    /// see https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-06#section-5.2
+    fun hash_fr(proof: &vector<u8>, offset: u64): Element<Fr> {
+        // [0x00, .. , 0x00 || x, y, || 0, 48, 0, dst, size domain]
+        let msg = vector::empty<u8>();
+        let i = 0;
+        while (i < 64) {
+            vector::push_back(&mut msg, 0);
+            i = i + 1;
+        };
+        append_bytes(&mut msg, proof, offset, 0x40);
+        vector::append(&mut msg, vector[0, 48, 0]);
+        vector::append(&mut msg, HASH_FR_DST);
+        vector::push_back(&mut msg, 11);
+        let b0 = hash::sha2_256(msg);
+
+        // [b0 || 1 || dst || size domain]
+        let msg = b0;
+        vector::push_back(&mut msg, 1);
+        vector::append(&mut msg, HASH_FR_DST);
+        vector::push_back(&mut msg, 11);
+        let b1 = hash::sha2_256(msg);
+
+        // [b0 ^ b1 || 2 || dst || size domain]
+        let msg = vector::empty<u8>();
+        i = 0;
+        while (i < 32) {
+            vector::push_back(&mut msg, *vector::borrow(&b0, i) ^ *vector::borrow(&b1, i));
+            i = i + 1;
+        };
+        vector::push_back(&mut msg, 2);
+        vector::append(&mut msg, HASH_FR_DST);
+        vector::push_back(&mut msg, 11);
+        let b2 = hash::sha2_256(msg);
+
+        // [ b1 || b2[:16] ] is interpreted as a big integer in big endian
+        let hi = fr_from_digest(&b1);
+        let lo = vector::empty<u8>();
+        append_bytes(&mut lo, &vector[0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0], 0, 16);
+        append_bytes(&mut lo, &b2, 0, 16);
+        add(&mul(&hi, &fr_from_u256(1 << 128, 0)), &option::destroy_some(deserialize<Fr, FormatFrMsb>(&lo)))
+    }
+
+    // END compute_pi -------------------------------------------------
+
+    /// check that
+    ///	L(ζ)Qₗ(ζ)+r(ζ)Qᵣ(ζ)+R(ζ)L(ζ)Qₘ(ζ)+O(ζ)Qₒ(ζ)+Qₖ(ζ)+Σᵢqc'ᵢ(ζ)BsbCommitmentᵢ(ζ) +
+    ///  α*( Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β*s₃(X)-Z(X)(l(ζ)+β*id_1(ζ)+γ)*(r(ζ)+β*id_2(ζ)+γ)*(o(ζ)+β*id_3(ζ)+γ) ) )
+    /// + α²*L₁(ζ) =
+    /// (ζⁿ-1)H(ζ)
+    fun verify_quotient_poly_eval_at_zeta(
+        proof: &vector<u8>,
+        beta: &Element<Fr>,
+        gamma: &Element<Fr>,
+        alpha: &Element<Fr>,
+        pi: &Element<Fr>,
+        alpha_square_lagrange_0: &Element<Fr>,
+        zpnmo: &Element<Fr>,
+    ): bool {
+        // (l(ζ)+β*s1(ζ)+γ)
+        let s1 = mul(&fr_at(proof, PROOF_S1_AT_ZETA), beta);
+        s1 = add(&s1, gamma);
+        s1 = add(&s1, &fr_at(proof, PROOF_L_AT_ZETA));
+
+        // (r(ζ)+β*s2(ζ)+γ)
+        let s2 = mul(&fr_at(proof, PROOF_S2_AT_ZETA), beta);
+        s2 = add(&s2, gamma);
+        s2 = add(&s2, &fr_at(proof, PROOF_R_AT_ZETA));
+
+        // (o(ζ)+γ)
+        let o = add(&fr_at(proof, PROOF_O_AT_ZETA), gamma);
+
+        //  α*(Z(μζ))*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*(o(ζ)+γ)
+        s1 = mul(&s1, &s2);
+        s1 = mul(&s1, &o);
+        s1 = mul(&s1, alpha);
+        s1 = mul(&s1, &fr_at(proof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA));
+
+        // linearizedpolynomial + pi(zeta)
+        let computed_quotient = add(&fr_at(proof, PROOF_LINEARISED_POLYNOMIAL_AT_ZETA), pi);
+        computed_quotient = add(&computed_quotient, &s1);
+        computed_quotient = sub(&computed_quotient, alpha_square_lagrange_0);
+
+        eq(&computed_quotient, &mul(&fr_at(proof, PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA), zpnmo))
+    }
+
+    /// Compute the commitment to the linearized polynomial equal to
+    ///	L(ζ)[Qₗ]+r(ζ)[Qᵣ]+R(ζ)L(ζ)[Qₘ]+O(ζ)[Qₒ]+[Qₖ]+Σᵢqc'ᵢ(ζ)[BsbCommitmentᵢ] +
+    ///	α*( Z(μζ)(L(ζ)+β*S₁(ζ)+γ)*(R(ζ)+β*S₂(ζ)+γ)[S₃]-[Z](L(ζ)+β*id_{1}(ζ)+γ)*(R(ζ)+β*id_{2(ζ)+γ)*(O(ζ)+β*id_{3}(ζ)+γ) ) +
+    ///	α²*L₁(ζ)[Z] + Σᵢgᵢ(ζ)[Qgᵢ]
+    /// where
+    /// * id_1 = id, id_2 = vk_coset_shift*id, id_3 = vk_coset_shift^{2}*id
+    /// * the [] means that it's a commitment (i.e. a point on Bn254(F_p))
+    fun compute_commitment_linearised_polynomial(
+        proof: &vector<u8>,
+        beta: &Element<Fr>,
+        gamma: &Element<Fr>,
+        alpha: &Element<Fr>,
+        zeta: &Element<Fr>,
+        alpha_square_lagrange_0: &Element<Fr>,
+    ): Element<G1> {
+        let l = fr_at(proof, PROOF_L_AT_ZETA);
+        let r = fr_at(proof, PROOF_R_AT_ZETA);
+        let o = fr_at(proof, PROOF_O_AT_ZETA);
+
+        let u = mul(&fr_at(proof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA), beta);
+        let v = mul(beta, &fr_at(proof, PROOF_S1_AT_ZETA));
+        v = add(&v, &l);
+        v = add(&v, gamma);
+
+        let w = mul(beta, &fr_at(proof, PROOF_S2_AT_ZETA));
+        w = add(&w, &r);
+        w = add(&w, gamma);
+
+        let s1 = mul(&u, &v);
+        s1 = mul(&s1, &w);
+        s1 = mul(&s1, alpha);
+
+        let coset_shift = fr_from_u256(VK_COSET_SHIFT, 0);
+        let betazeta = mul(beta, zeta);
+        u = add(&betazeta, &l);
+        u = add(&u, gamma);
+
+        v = mul(&betazeta, &coset_shift);
+        v = add(&v, &r);
+        v = add(&v, gamma);
+
+        w = mul(&betazeta, &sqr(&coset_shift));
+        w = add(&w, &o);
+        w = add(&w, gamma);
+
+        let s2 = mul(&u, &v);
+        s2 = mul(&s2, &w);
+        s2 = sub(&zero<Fr>(), &s2);
+        s2 = mul(&s2, alpha);
+        s2 = add(&s2, alpha_square_lagrange_0);
+
+        // at this stage:
+        // * s₁ = α*Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β
+        // * s₂ = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ) + α²*L₁(ζ)
+
+        let points = vector[g1(&VK_QL_COM), g1(&VK_QR_COM), g1(&VK_QM_COM), g1(&VK_QO_COM), g1(&VK_QK_COM)];
+        let scalars = vector[l, r, mul(&l, &r), o, one<Fr>()];
+        vector::push_back(&mut points, g1_from_evm(proof, PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + 0x0));
+        vector::push_back(&mut scalars, fr_at(proof, PROOF_OPENING_QCP_AT_ZETA + 0x0));
+        vector::push_back(&mut points, g1(&VK_S3_COM));
+        vector::push_back(&mut scalars, s1);
+        vector::push_back(&mut points, g1_from_evm(proof, PROOF_GRAND_PRODUCT_COMMITMENT));
+        vector::push_back(&mut scalars, s2);
+        multi_scalar_mul(&points, &scalars)
+    }
+
+    /// generate the challenge (using Fiat Shamir) to fold the opening proofs
+    /// at ζ, as Sha256("gamma" || ζ || [H] || [Linearised polynomial] || [L] || [R] || [O] || [S₁] || [S₂] || [Pi_{i}]
+    /// || H(ζ) || Linearised_polynomial(ζ) || L(ζ) || R(ζ) || O(ζ) || S₁(ζ) || S₂(ζ) || Pi_{i}(ζ) || Z(ζω))
+    fun compute_gamma_kzg(proof: &vector<u8>, zeta: &Element<Fr>, folded_h: &Element<G1>, linearised_polynomial: &Element<G1>): Element<Fr> {
+        let transcript = b"gamma";
+        vector::append(&mut transcript, serialize<Fr, FormatFrMsb>(zeta));
+        append_g1_evm(&mut transcript, folded_h);
+        append_g1_evm(&mut transcript, linearised_polynomial);
+        append_bytes(&mut transcript, proof, PROOF_L_COM, 0xc0);
+        vector::append(&mut transcript, VK_S1_COM);
+        vector::append(&mut transcript, VK_S2_COM);
+        vector::append(&mut transcript, VK_QCP_0);
+        append_bytes(&mut transcript, proof, PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA, 0x40);
+        append_bytes(&mut transcript, proof, PROOF_L_AT_ZETA, 0xa0);
+        append_bytes(&mut transcript, proof, PROOF_OPENING_QCP_AT_ZETA, 0x20 * VK_NB_CUSTOM_GATES);
+        append_bytes(&mut transcript, proof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA, 0x20);
+        fr_from_digest(&hash::sha2_256(transcript))
+    }
+
+    /// Fold the opening proofs at ζ:
+    /// * [H] + γ[Linearised_polynomial]+γ²[L] + γ³[R] + γ⁴[O] + γ⁵[S₁] +γ⁶[S₂] + ∑ᵢγ⁶⁺ⁱ[Pi_{i}]
+    /// * H(ζ) + γLinearised_polynomial(ζ)+γ²L(ζ) + γ³R(ζ)+ γ⁴O(ζ) + γ⁵S₁(ζ) +γ⁶S₂(ζ) + ∑ᵢγ⁶⁺ⁱPi_{i}(ζ)
+    /// @return the folded digests and the folded claimed values
+    fun fold_state(proof: &vector<u8>, gamma_kzg: &Element<Fr>, folded_h: Element<G1>, linearised_polynomial: Element<G1>): (Element<G1>, Element<Fr>) {
+        let points = vector[
+            folded_h,
+            linearised_polynomial,
+            g1_from_evm(proof, PROOF_L_COM),
+            g1_from_evm(proof, PROOF_R_COM),
+            g1_from_evm(proof, PROOF_O_COM),
+            g1(&VK_S1_COM),
+            g1(&VK_S2_COM),
+            g1(&VK_QCP_0),
+        ];
+        let claimed_values = vector[
+            fr_at(proof, PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA),
+            fr_at(proof, PROOF_LINEARISED_POLYNOMIAL_AT_ZETA),
+            fr_at(proof, PROOF_L_AT_ZETA),
+            fr_at(proof, PROOF_R_AT_ZETA),
+            fr_at(proof, PROOF_O_AT_ZETA),
+            fr_at(proof, PROOF_S1_AT_ZETA),
+            fr_at(proof, PROOF_S2_AT_ZETA),
+            fr_at(proof, PROOF_OPENING_QCP_AT_ZETA + 0x0),
+        ];
+
+        let scalars = vector::empty<Element<Fr>>();
+        let folded_claimed_values = zero<Fr>();
+        let acc_gamma = one<Fr>();
+        let i = 0;
+        while (i < vector::length(&claimed_values)) {
+            folded_claimed_values = add(&folded_claimed_values, &mul(vector::borrow(&claimed_values, i), &acc_gamma));
+            vector::push_back(&mut scalars, acc_gamma);
+            acc_gamma = mul(&acc_gamma, gamma_kzg);
+            i = i + 1;
+        };
+        (multi_scalar_mul(&points, &scalars), folded_claimed_values)
+    }
+
+    /// follows alg. p.13 of https://eprint.iacr.org/2019/953.pdf
+    /// with t₁ = t₂ = 1, and the proofs are ([digest] + [quotient] +purported evaluation):
+    /// * [folded_digests], [proof_batch_opening_at_zeta], folded_claimed_values
+    /// * [proof_grand_product_commitment], [proof_opening_at_zeta_omega], [proof_grand_product_at_zeta_omega]
+    fun batch_verify_multi_points(
+        proof: &vector<u8>,
+        zeta: &Element<Fr>,
+        gamma_kzg: &Element<Fr>,
+        folded_digests: &Element<G1>,
+        folded_claimed_values: &Element<Fr>,
+    ): bool {
+        // derive a random number. As there is no random generator, we
+        // do an FS like challenge derivation, depending on both digests and
+        // ζ to ensure that the prover cannot control the random number.
+        // Note: adding the other point ζω is not needed, as ω is known beforehand.
+        let transcript = vector::empty<u8>();
+        append_g1_evm(&mut transcript, folded_digests);
+        append_bytes(&mut transcript, proof, PROOF_BATCH_OPENING_AT_ZETA, 0x40);
+        append_bytes(&mut transcript, proof, PROOF_GRAND_PRODUCT_COMMITMENT, 0x40);
+        append_bytes(&mut transcript, proof, PROOF_OPENING_AT_ZETA_OMEGA, 0x40);
+        vector::append(&mut transcript, serialize<Fr, FormatFrMsb>(zeta));
+        vector::append(&mut transcript, serialize<Fr, FormatFrMsb>(gamma_kzg));
+        let random = fr_from_digest(&hash::sha2_256(transcript));
+
+        let opening_at_zeta = g1_from_evm(proof, PROOF_BATCH_OPENING_AT_ZETA);
+        let opening_at_zeta_omega = g1_from_evm(proof, PROOF_OPENING_AT_ZETA_OMEGA);
+
+        // [Wζ] + random⋅[Wζω]
+        let folded_quotients = multi_scalar_mul(&vector[opening_at_zeta, opening_at_zeta_omega], &vector[one<Fr>(), random]);
+
+        // [folded_digests] + random⋅[Z] - (folded_evals + random⋅Z(ζω))⋅[1] + ζ⋅[Wζ] + random⋅ζω⋅[Wζω]
+        let folded_evals = add(folded_claimed_values, &mul(&random, &fr_at(proof, PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA)));
+        let zeta_omega = mul(zeta, &fr_from_u256(VK_OMEGA, 0));
+        let digests = multi_scalar_mul(
+            &vector[*folded_digests, g1_from_evm(proof, PROOF_GRAND_PRODUCT_COMMITMENT), g1(&G1_SRS), opening_at_zeta, opening_at_zeta_omega],
+            &vector[one<Fr>(), random, sub(&zero<Fr>(), &folded_evals), *zeta, mul(&random, &zeta_omega)],
+        );
+
+        // e([digests], [1]) ⋅ e(-[folded_quotients], [x]) = 1
+        let res = multi_pairing<G1, G2, Gt>(
+            &vector[digests, sub(&zero<G1>(), &folded_quotients)],
+            &vector[g2(&G2_SRS_0), g2(&G2_SRS_1)],
+        );
+        eq(&res, &zero<Gt>())
+    }
+
+    // BEGINNING utils math functions -------------------------------------------------
+
+    /// @return x ** e
+    fun pow(x: &Element<Fr>, e: u64): Element<Fr> {
+        let res = one<Fr>();
+        let base = *x;
+        while (e > 0) {
+            if (e & 1 == 1) {
+                res = mul(&res, &base);
+            };
+            base = sqr(&base);
+            e = e >> 1;
+        };
+        res
+    }
+
+    /// Reads the claimed evaluation at offset in the proof, in big endian.
+    fun fr_at(proof: &vector<u8>, offset: u64): Element<Fr> {
+        let b = vector::empty<u8>();
+        append_bytes(&mut b, proof, offset, 32);
+        let e = deserialize<Fr, FormatFrMsb>(&b);
+        assert!(option::is_some(&e), EOPENINGS_BIGGER_THAN_R);
+        option::destroy_some(e)
+    }
+
+    /// Reduces a digest of 32 bytes, interpreted in big endian, modulo r.
+    fun fr_from_digest(digest: &vector<u8>): Element<Fr> {
+        // digest = hi⋅2¹²⁸ + lo where hi, lo < 2¹²⁸ < r
+        let zeroes = vector[0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0];
+        let hi = zeroes;
+        append_bytes(&mut hi, digest, 0, 16);
+        let lo = zeroes;
+        append_bytes(&mut lo, digest, 16, 16);
+        let res = mul(&option::destroy_some(deserialize<Fr, FormatFrMsb>(&hi)), &fr_from_u256(1 << 128, 0));
+        add(&res, &option::destroy_some(deserialize<Fr, FormatFrMsb>(&lo)))
+    }
+
+    fun fr_from_u256(x: u256, error: u64): Element<Fr> {
+        let e = deserialize<Fr, FormatFrLsb>(&bcs::to_bytes(&x));
+        assert!(option::is_some(&e), error);
+        option::destroy_some(e)
+    }
+
+    /// Reads the point of G1 encoded as in EIP-196, i.e. x then y in big endian.
+    fun g1(bytes: &vector<u8>): Element<G1> {
+        g1_from_evm(bytes, 0)
+    }
+
+    fun g1_from_evm(bytes: &vector<u8>, offset: u64): Element<G1> {
+        let b = vector::empty<u8>();
+        append_reversed(&mut b, bytes, offset);
+        append_reversed(&mut b, bytes, offset + 32);
+        let p = deserialize<G1, FormatG1Uncompr>(&b);
+        assert!(option::is_some(&p), EINVALID_POINT);
+        option::destroy_some(p)
+    }
+
+    fun g2(bytes: &vector<u8>): Element<G2> {
+        option::destroy_some(deserialize<G2, FormatG2Uncompr>(bytes))
+    }
+
+    /// Appends p to dst, encoded as in EIP-196.
+    fun append_g1_evm(dst: &mut vector<u8>, p: &Element<G1>) {
+        let b = serialize<G1, FormatG1Uncompr>(p);
+        append_reversed(dst, &b, 0);
+        append_reversed(dst, &b, 32);
+    }
+
+    /// Appends the 32 bytes of src at offset to dst, in reverse order.
+    fun append_reversed(dst: &mut vector<u8>, src: &vector<u8>, offset: u64) {
+        let i = 32;
+        while (i > 0) {
+            i = i - 1;
+            vector::push_back(dst, *vector::borrow(src, offset + i));
+        };
+    }
+
+    /// Appends the size bytes of src at offset to dst.
+    fun append_bytes(dst: &mut vector<u8>, src: &vector<u8>, offset: u64, size: u64) {
+        let i = 0;
+        while (i < size) {
+            vector::push_back(dst, *vector::borrow(src, offset + i));
+            i = i + 1;
+        };
+    }
+}
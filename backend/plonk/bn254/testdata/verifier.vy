@@ -0,0 +1,600 @@
+# @version ^0.3.10
+# SPDX-License-Identifier: Apache-2.0
+
+# Copyright 2023 Consensys Software Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Code generated by gnark DO NOT EDIT
+
+"""
+@title PlonK verifier
+@notice Verifies PlonK proofs on BN254, using the precompiles of EIP-196,
+        EIP-197 and EIP-198.
+"""
+
+PRECOMPILE_MODEXP: constant(address) = 0x0000000000000000000000000000000000000005
+PRECOMPILE_PAIRING: constant(address) = 0x0000000000000000000000000000000000000008
+
+R_MOD: constant(uint256) = 21888242871839275222246405745257275088548364400416034343698204186575808495617
+P_MOD: constant(uint256) = 21888242871839275222246405745257275088696311157297823662689037894645226208583
+
+G2_SRS_0_X_0: constant(uint256) = 11559732032986387107991004021392285783925812861821192530917403151452391805634
+G2_SRS_0_X_1: constant(uint256) = 10857046999023057135944570762232829481370756359578518086990519993285655852781
+G2_SRS_0_Y_0: constant(uint256) = 4082367875863433681332203403145435568316851327593401208105741076214120093531
+G2_SRS_0_Y_1: constant(uint256) = 8495653923123431417604973247489272438418190587263600148770280649306958101930
+
+G2_SRS_1_X_0: constant(uint256) = 17185772360774220181859933979523617438047871547077376366567544550505172847672
+G2_SRS_1_X_1: constant(uint256) = 16911362467676312014617495922970847043202601636279756745525199580670672706736
+G2_SRS_1_Y_0: constant(uint256) = 13051130980786204843603289176390111050308624894498336518283532860869775137034
+G2_SRS_1_Y_1: constant(uint256) = 8599550964311414246954536149568192546397535889105946665787306037539140511864
+
+G1_SRS_X: constant(uint256) = 1
+G1_SRS_Y: constant(uint256) = 2
+
+# ----------------------- vk ---------------------
+VK_NB_PUBLIC_INPUTS: constant(uint256) = 2
+VK_DOMAIN_SIZE: constant(uint256) = 16
+VK_INV_DOMAIN_SIZE: constant(uint256) = 20520227692349320520856005386178695395514091625390032197217066424914820464641
+VK_OMEGA: constant(uint256) = 14940766826517323942636479241147756311199852622225275649687664389641784935947
+VK_QL_COM_X: constant(uint256) = 18354732411059195807874652127430795000421297559161269080417115050745046721828
+VK_QL_COM_Y: constant(uint256) = 10955350260716671106917526531362180028988833246419686717477653996743648557723
+VK_QR_COM_X: constant(uint256) = 9103748236573702801459371161359035266997156975409783447744846187069989188949
+VK_QR_COM_Y: constant(uint256) = 3200083235324406551272359298902824197509415969302540852863610920877452304669
+VK_QM_COM_X: constant(uint256) = 20026233862354430064462923093026732931891256940690972456664165656088837778365
+VK_QM_COM_Y: constant(uint256) = 5344535965214131506583385239074834862728147965031538845481375946447819577127
+VK_QO_COM_X: constant(uint256) = 834588162449499641494799202137562138065107692928876274231928182589491506173
+VK_QO_COM_Y: constant(uint256) = 20949674602582623593922922800114794524652379794331448623896254537323342875774
+VK_QK_COM_X: constant(uint256) = 7979724164721488487785037825516212296980312694680384588984198701745999686963
+VK_QK_COM_Y: constant(uint256) = 14574560224317222366959887872158571721581700956232092385778598031640264613044
+
+VK_S1_COM_X: constant(uint256) = 6959745643373264102406531827396010216671383176744531514220112756136193437299
+VK_S1_COM_Y: constant(uint256) = 6382732118601926407121931957864453760371237237401428478840349119187235974435
+
+VK_S2_COM_X: constant(uint256) = 11454223150701294536037507634585723088593179731848209563399801253722511717382
+VK_S2_COM_Y: constant(uint256) = 11570711165214898809413906836704161328166884896455055905718482345652700894072
+
+VK_S3_COM_X: constant(uint256) = 20982159107668178854244178833516798170906249388612051129031950905064365171866
+VK_S3_COM_Y: constant(uint256) = 19685385845954762733391696472436161184311923800363857662745527277053505130013
+
+VK_COSET_SHIFT: constant(uint256) = 5
+
+VK_QCP_0_X: constant(uint256) = 11525111943593016788734854581969371537330544022223130625170342685873652562940
+VK_QCP_0_Y: constant(uint256) = 4035813043574700909428669166700840958237301721987814250835806408653021171812
+
+VK_INDEX_COMMIT_API_0: constant(uint256) = 6
+VK_NB_CUSTOM_GATES: constant(uint256) = 1
+
+# ------------------------------------------------
+
+# size of the proof in bytes, and in words
+PROOF_SIZE: constant(uint256) = 928
+PROOF_NB_WORDS: constant(uint256) = 29
+
+# offsets in the proof, in words
+PROOF_L_COM: constant(uint256) = 0
+PROOF_R_COM: constant(uint256) = 2
+PROOF_O_COM: constant(uint256) = 4
+
+# h = h_0 + x^{n+2}h_1 + x^{2(n+2)}h_2
+PROOF_H_0: constant(uint256) = 6
+PROOF_H_1: constant(uint256) = 8
+PROOF_H_2: constant(uint256) = 10
+
+# wire values at zeta
+PROOF_L_AT_ZETA: constant(uint256) = 12
+PROOF_R_AT_ZETA: constant(uint256) = 13
+PROOF_O_AT_ZETA: constant(uint256) = 14
+
+# Sσ1(zeta), Sσ2(zeta)
+PROOF_S1_AT_ZETA: constant(uint256) = 15
+PROOF_S2_AT_ZETA: constant(uint256) = 16
+
+# [z(x)]
+PROOF_GRAND_PRODUCT_COMMITMENT: constant(uint256) = 17
+
+PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA: constant(uint256) = 19 # z(w*zeta)
+PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA: constant(uint256) = 20 # t(zeta)
+PROOF_LINEARISED_POLYNOMIAL_AT_ZETA: constant(uint256) = 21 # r(zeta)
+
+# Folded proof for the opening of linearised poly, l, r, o, s_1, s_2, qcp
+PROOF_BATCH_OPENING_AT_ZETA: constant(uint256) = 22 # [Wzeta]
+PROOF_OPENING_AT_ZETA_OMEGA: constant(uint256) = 24
+
+PROOF_OPENING_QCP_AT_ZETA: constant(uint256) = 26
+PROOF_COMMITMENTS_WIRES_CUSTOM_GATES: constant(uint256) = 27
+
+# domain separation tag of hash_fr
+HASH_FR_DST: constant(Bytes[11]) = b"BSB22-Plonk"
+
+
+@external
+@view
+def Verify(proof: Bytes[PROOF_SIZE], public_inputs: uint256[VK_NB_PUBLIC_INPUTS]) -> bool:
+    """
+    @notice Verify a Plonk proof.
+    @dev Reverts if the proof or the public inputs are malformed.
+    @param proof serialised plonk proof (using gnark's MarshalSolidity)
+    @param public_inputs (must be reduced)
+    @return success true if the proof passes false otherwise
+    """
+    # sanity checks
+    assert len(proof) == PROOF_SIZE, "wrong proof size"
+    for i in range(VK_NB_PUBLIC_INPUTS):
+        assert public_inputs[i] < R_MOD, "inputs are bigger than r"
+    p: uint256[PROOF_NB_WORDS] = empty(uint256[PROOF_NB_WORDS])
+    for i in range(PROOF_NB_WORDS):
+        p[i] = extract32(proof, i * 32, output_type=uint256)
+    self._check_proof_openings_size(p)
+
+    # compute the challenges
+    prev_challenge_non_reduced: uint256 = self._derive_gamma(p, public_inputs)
+    gamma: uint256 = prev_challenge_non_reduced % R_MOD
+    prev_challenge_non_reduced = convert(sha256(concat(b"beta", convert(prev_challenge_non_reduced, bytes32))), uint256)
+    beta: uint256 = prev_challenge_non_reduced % R_MOD
+    prev_challenge_non_reduced = self._derive_alpha(p, prev_challenge_non_reduced)
+    alpha: uint256 = prev_challenge_non_reduced % R_MOD
+    zeta: uint256 = self._derive_zeta(p, prev_challenge_non_reduced) % R_MOD
+
+    # evaluation of Z=Xⁿ-1 at ζ
+    zeta_power_n_minus_one: uint256 = uint256_addmod(self._pow(zeta, VK_DOMAIN_SIZE), R_MOD - 1, R_MOD)
+
+    # public inputs contribution
+    pi: uint256 = self._compute_pi(p, public_inputs, zeta, zeta_power_n_minus_one)
+
+    alpha_square_lagrange_0: uint256 = self._compute_alpha_square_lagrange_0(alpha, zeta, zeta_power_n_minus_one)
+    if not self._verify_quotient_poly_eval_at_zeta(p, beta, gamma, alpha, pi, alpha_square_lagrange_0, zeta_power_n_minus_one):
+        return False
+
+    folded_h: uint256[2] = self._fold_h(p, zeta)
+    linearised_polynomial: uint256[2] = self._compute_commitment_linearised_polynomial(p, beta, gamma, alpha, zeta, alpha_square_lagrange_0)
+    gamma_kzg: uint256 = self._compute_gamma_kzg(p, zeta, folded_h, linearised_polynomial)
+    folded_digests: uint256[2] = empty(uint256[2])
+    folded_claimed_values: uint256 = 0
+    folded_digests, folded_claimed_values = self._fold_state(p, gamma_kzg, folded_h, linearised_polynomial)
+    return self._batch_verify_multi_points(p, zeta, gamma_kzg, folded_digests, folded_claimed_values)
+
+
+@internal
+@pure
+def _check_proof_openings_size(p: uint256[PROOF_NB_WORDS]):
+    """
+    @notice Checks that the claimed evaluations of the polynomials are < R_MOD.
+    """
+    assert p[PROOF_LINEARISED_POLYNOMIAL_AT_ZETA] < R_MOD, "openings bigger than r"
+    assert p[PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA] < R_MOD, "openings bigger than r"
+    assert p[PROOF_L_AT_ZETA] < R_MOD, "openings bigger than r"
+    assert p[PROOF_R_AT_ZETA] < R_MOD, "openings bigger than r"
+    assert p[PROOF_O_AT_ZETA] < R_MOD, "openings bigger than r"
+    assert p[PROOF_S1_AT_ZETA] < R_MOD, "openings bigger than r"
+    assert p[PROOF_S2_AT_ZETA] < R_MOD, "openings bigger than r"
+    assert p[PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA] < R_MOD, "openings bigger than r"
+    assert p[PROOF_OPENING_QCP_AT_ZETA + 0] < R_MOD, "openings bigger than r"
+
+
+# Beginning challenges -------------------------------------------------
+
+@internal
+@pure
+def _derive_gamma(p: uint256[PROOF_NB_WORDS], public_inputs: uint256[VK_NB_PUBLIC_INPUTS]) -> uint256:
+    """
+    @notice Derive gamma as Sha256(<transcript>), where the transcript is the
+            concatenation of "gamma", the commitments to S1, S2, S3, Ql, Qr,
+            Qm, Qo, Qk, Qcp, Qg, the public inputs and the commitments to L, R, O.
+    @return the challenge gamma, not reduced
+    """
+    return convert(sha256(concat(
+        b"gamma",
+        convert(VK_S1_COM_X, bytes32),
+        convert(VK_S1_COM_Y, bytes32),
+        convert(VK_S2_COM_X, bytes32),
+        convert(VK_S2_COM_Y, bytes32),
+        convert(VK_S3_COM_X, bytes32),
+        convert(VK_S3_COM_Y, bytes32),
+        convert(VK_QL_COM_X, bytes32),
+        convert(VK_QL_COM_Y, bytes32),
+        convert(VK_QR_COM_X, bytes32),
+        convert(VK_QR_COM_Y, bytes32),
+        convert(VK_QM_COM_X, bytes32),
+        convert(VK_QM_COM_Y, bytes32),
+        convert(VK_QO_COM_X, bytes32),
+        convert(VK_QO_COM_Y, bytes32),
+        convert(VK_QK_COM_X, bytes32),
+        convert(VK_QK_COM_Y, bytes32),
+        convert(VK_QCP_0_X, bytes32),
+        convert(VK_QCP_0_Y, bytes32),
+        convert(public_inputs[0], bytes32),
+        convert(public_inputs[1], bytes32),
+        convert(p[PROOF_L_COM], bytes32),
+        convert(p[PROOF_L_COM + 1], bytes32),
+        convert(p[PROOF_R_COM], bytes32),
+        convert(p[PROOF_R_COM + 1], bytes32),
+        convert(p[PROOF_O_COM], bytes32),
+        convert(p[PROOF_O_COM + 1], bytes32),
+    )), uint256)
+
+
+@internal
+@pure
+def _derive_alpha(p: uint256[PROOF_NB_WORDS], beta_not_reduced: uint256) -> uint256:
+    """
+    @notice Derive alpha as Sha256("alpha" || beta || Bsb22 commitments || [Z]).
+    @return the challenge alpha, not reduced
+    """
+    return convert(sha256(concat(
+        b"alpha",
+        convert(beta_not_reduced, bytes32),
+        convert(p[PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + 0], bytes32),
+        convert(p[PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + 1], bytes32),
+        convert(p[PROOF_GRAND_PRODUCT_COMMITMENT], bytes32),
+        convert(p[PROOF_GRAND_PRODUCT_COMMITMENT + 1], bytes32),
+    )), uint256)
+
+
+@internal
+@pure
+def _derive_zeta(p: uint256[PROOF_NB_WORDS], alpha_not_reduced: uint256) -> uint256:
+    """
+    @notice Derive zeta as Sha256("zeta" || alpha || [H₀] || [H₁] || [H₂]).
+    @return the challenge zeta, not reduced
+    """
+    return convert(sha256(concat(
+        b"zeta",
+        convert(alpha_not_reduced, bytes32),
+        convert(p[PROOF_H_0], bytes32),
+        convert(p[PROOF_H_0 + 1], bytes32),
+        convert(p[PROOF_H_1], bytes32),
+        convert(p[PROOF_H_1 + 1], bytes32),
+        convert(p[PROOF_H_2], bytes32),
+        convert(p[PROOF_H_2 + 1], bytes32),
+    )), uint256)
+
+# END challenges -------------------------------------------------
+
+# BEGINNING compute_pi -------------------------------------------------
+
+@internal
+@view
+def _compute_pi(p: uint256[PROOF_NB_WORDS], public_inputs: uint256[VK_NB_PUBLIC_INPUTS], zeta: uint256, zpnmo: uint256) -> uint256:
+    """
+    @notice Computes the public inputs contribution ∑ᵢLᵢ(ζ)⋅piᵢ, including
+            the public inputs coming from the custom gates.
+    """
+    pi: uint256 = 0
+    for i in range(VK_NB_PUBLIC_INPUTS):
+        pi = uint256_addmod(pi, uint256_mulmod(self._compute_ith_lagrange_at_z(zeta, zpnmo, i), public_inputs[i], R_MOD), R_MOD)
+    h_fr_0: uint256 = self._hash_fr(p[PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + 0], p[PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + 1])
+    pi = uint256_addmod(pi, uint256_mulmod(h_fr_0, self._compute_ith_lagrange_at_z(zeta, zpnmo, VK_NB_PUBLIC_INPUTS + VK_INDEX_COMMIT_API_0), R_MOD), R_MOD)
+    return pi
+
+
+@internal
+@view
+def _compute_ith_lagrange_at_z(z: uint256, zpnmo: uint256, i: uint256) -> uint256:
+    """
+    @notice Computes Lᵢ(ζ) = ωⁱ/n * (ζⁿ-1)/(ζ-ωⁱ)
+    """
+    w: uint256 = self._pow(VK_OMEGA, i)
+    den: uint256 = self._pow(uint256_addmod(z, R_MOD - w, R_MOD), R_MOD - 2)
+    w = uint256_mulmod(w, VK_INV_DOMAIN_SIZE, R_MOD)
+    w = uint256_mulmod(w, den, R_MOD)
+    return uint256_mulmod(w, zpnmo, R_MOD)
+
+
+@internal
+@pure
+def _hash_fr(x: uint256, y: uint256) -> uint256:
+    """
+    @notice Hashes the point (x, y) to the field, with expand_message_xmd,
+            see https://tools.ietf.org/html/draft-irtf-cfrg-hash-to-curve-06#section-5.2
+    """
+    # [0x00, .. , 0x00 || x, y, || 0, 48, 0, dst, size domain]
+    b0: bytes32 = sha256(concat(empty(bytes32), empty(bytes32), convert(x, bytes32), convert(y, bytes32), b"\x00\x30\x00", HASH_FR_DST, b"\x0b"))
+    b1: bytes32 = sha256(concat(b0, b"\x01", HASH_FR_DST, b"\x0b"))
+    b2: bytes32 = sha256(concat(convert(convert(b0, uint256) ^ convert(b1, uint256), bytes32), b"\x02", HASH_FR_DST, b"\x0b"))
+
+    # [ b1 || b2[:16] ] is interpreted as a big integer in big endian
+    res: uint256 = uint256_mulmod(convert(b1, uint256), 2**128, R_MOD)
+    return uint256_addmod(res, convert(b2, uint256) >> 128, R_MOD)
+
+# END compute_pi -------------------------------------------------
+
+
+@internal
+@view
+def _compute_alpha_square_lagrange_0(alpha: uint256, zeta: uint256, zpnmo: uint256) -> uint256:
+    """
+    @notice compute α² * 1/n * (ζ{n}-1)/(ζ - 1)
+    """
+    den: uint256 = self._pow(uint256_addmod(zeta, R_MOD - 1, R_MOD), R_MOD - 2)
+    den = uint256_mulmod(den, VK_INV_DOMAIN_SIZE, R_MOD)
+    res: uint256 = uint256_mulmod(den, zpnmo, R_MOD)
+    res = uint256_mulmod(res, alpha, R_MOD)
+    return uint256_mulmod(res, alpha, R_MOD)
+
+
+@internal
+@pure
+def _verify_quotient_poly_eval_at_zeta(p: uint256[PROOF_NB_WORDS], beta: uint256, gamma: uint256, alpha: uint256, pi: uint256, alpha_square_lagrange_0: uint256, zpnmo: uint256) -> bool:
+    """
+    @notice check that
+            L(ζ)Qₗ(ζ)+r(ζ)Qᵣ(ζ)+R(ζ)L(ζ)Qₘ(ζ)+O(ζ)Qₒ(ζ)+Qₖ(ζ)+Σᵢqc'ᵢ(ζ)BsbCommitmentᵢ(ζ) +
+            α*( Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β*s₃(X)-Z(X)(l(ζ)+β*id_1(ζ)+γ)*(r(ζ)+β*id_2(ζ)+γ)*(o(ζ)+β*id_3(ζ)+γ) ) )
+            + α²*L₁(ζ) = (ζⁿ-1)H(ζ)
+    """
+    # (l(ζ)+β*s1(ζ)+γ)
+    s1: uint256 = uint256_mulmod(p[PROOF_S1_AT_ZETA], beta, R_MOD)
+    s1 = uint256_addmod(s1, gamma, R_MOD)
+    s1 = uint256_addmod(s1, p[PROOF_L_AT_ZETA], R_MOD)
+
+    # (r(ζ)+β*s2(ζ)+γ)
+    s2: uint256 = uint256_mulmod(p[PROOF_S2_AT_ZETA], beta, R_MOD)
+    s2 = uint256_addmod(s2, gamma, R_MOD)
+    s2 = uint256_addmod(s2, p[PROOF_R_AT_ZETA], R_MOD)
+
+    # (o(ζ)+γ)
+    o: uint256 = uint256_addmod(p[PROOF_O_AT_ZETA], gamma, R_MOD)
+
+    # α*(Z(μζ))*(l(ζ)+β*s1(ζ)+γ)*(r(ζ)+β*s2(ζ)+γ)*(o(ζ)+γ)
+    s1 = uint256_mulmod(s1, s2, R_MOD)
+    s1 = uint256_mulmod(s1, o, R_MOD)
+    s1 = uint256_mulmod(s1, alpha, R_MOD)
+    s1 = uint256_mulmod(s1, p[PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA], R_MOD)
+
+    # linearizedpolynomial + pi(zeta)
+    computed_quotient: uint256 = uint256_addmod(p[PROOF_LINEARISED_POLYNOMIAL_AT_ZETA], pi, R_MOD)
+    computed_quotient = uint256_addmod(computed_quotient, s1, R_MOD)
+    computed_quotient = uint256_addmod(computed_quotient, R_MOD - alpha_square_lagrange_0, R_MOD)
+    return computed_quotient == uint256_mulmod(p[PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA], zpnmo, R_MOD)
+
+
+@internal
+@view
+def _fold_h(p: uint256[PROOF_NB_WORDS], zeta: uint256) -> uint256[2]:
+    """
+    @notice compute H₁ + ζᵐ⁺²*H₂ + ζ²⁽ᵐ⁺²⁾*H₃
+    """
+    zeta_power_n_plus_two: uint256 = self._pow(zeta, VK_DOMAIN_SIZE + 2)
+    folded_h: uint256[2] = ecmul([p[PROOF_H_2], p[PROOF_H_2 + 1]], zeta_power_n_plus_two)
+    folded_h = ecadd(folded_h, [p[PROOF_H_1], p[PROOF_H_1 + 1]])
+    folded_h = ecmul(folded_h, zeta_power_n_plus_two)
+    return ecadd(folded_h, [p[PROOF_H_0], p[PROOF_H_0 + 1]])
+
+
+@internal
+@view
+def _compute_commitment_linearised_polynomial(p: uint256[PROOF_NB_WORDS], beta: uint256, gamma: uint256, alpha: uint256, zeta: uint256, alpha_square_lagrange_0: uint256) -> uint256[2]:
+    """
+    @notice Compute the commitment to the linearized polynomial equal to
+            L(ζ)[Qₗ]+r(ζ)[Qᵣ]+R(ζ)L(ζ)[Qₘ]+O(ζ)[Qₒ]+[Qₖ]+Σᵢqc'ᵢ(ζ)[BsbCommitmentᵢ] +
+            α*( Z(μζ)(L(ζ)+β*S₁(ζ)+γ)*(R(ζ)+β*S₂(ζ)+γ)[S₃]-[Z](L(ζ)+β*id_{1}(ζ)+γ)*(R(ζ)+β*id_{2(ζ)+γ)*(O(ζ)+β*id_{3}(ζ)+γ) ) +
+            α²*L₁(ζ)[Z] + Σᵢgᵢ(ζ)[Qgᵢ]
+    """
+    u: uint256 = uint256_mulmod(p[PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA], beta, R_MOD)
+    v: uint256 = uint256_mulmod(beta, p[PROOF_S1_AT_ZETA], R_MOD)
+    v = uint256_addmod(v, p[PROOF_L_AT_ZETA], R_MOD)
+    v = uint256_addmod(v, gamma, R_MOD)
+
+    w: uint256 = uint256_mulmod(beta, p[PROOF_S2_AT_ZETA], R_MOD)
+    w = uint256_addmod(w, p[PROOF_R_AT_ZETA], R_MOD)
+    w = uint256_addmod(w, gamma, R_MOD)
+
+    s1: uint256 = uint256_mulmod(u, v, R_MOD)
+    s1 = uint256_mulmod(s1, w, R_MOD)
+    s1 = uint256_mulmod(s1, alpha, R_MOD)
+
+    coset_square: uint256 = uint256_mulmod(VK_COSET_SHIFT, VK_COSET_SHIFT, R_MOD)
+    betazeta: uint256 = uint256_mulmod(beta, zeta, R_MOD)
+    u = uint256_addmod(betazeta, p[PROOF_L_AT_ZETA], R_MOD)
+    u = uint256_addmod(u, gamma, R_MOD)
+
+    v = uint256_mulmod(betazeta, VK_COSET_SHIFT, R_MOD)
+    v = uint256_addmod(v, p[PROOF_R_AT_ZETA], R_MOD)
+    v = uint256_addmod(v, gamma, R_MOD)
+
+    w = uint256_mulmod(betazeta, coset_square, R_MOD)
+    w = uint256_addmod(w, p[PROOF_O_AT_ZETA], R_MOD)
+    w = uint256_addmod(w, gamma, R_MOD)
+
+    s2: uint256 = uint256_mulmod(u, v, R_MOD)
+    s2 = uint256_mulmod(s2, w, R_MOD)
+    s2 = R_MOD - s2
+    s2 = uint256_mulmod(s2, alpha, R_MOD)
+    s2 = uint256_addmod(s2, alpha_square_lagrange_0, R_MOD)
+
+    # at this stage:
+    # * s₁ = α*Z(μζ)(l(ζ)+β*s₁(ζ)+γ)*(r(ζ)+β*s₂(ζ)+γ)*β
+    # * s₂ = -α*(l(ζ)+β*ζ+γ)*(r(ζ)+β*u*ζ+γ)*(o(ζ)+β*u²*ζ+γ) + α²*L₁(ζ)
+
+    res: uint256[2] = ecmul([VK_QL_COM_X, VK_QL_COM_Y], p[PROOF_L_AT_ZETA])
+    res = ecadd(res, ecmul([VK_QR_COM_X, VK_QR_COM_Y], p[PROOF_R_AT_ZETA]))
+    rl: uint256 = uint256_mulmod(p[PROOF_L_AT_ZETA], p[PROOF_R_AT_ZETA], R_MOD)
+    res = ecadd(res, ecmul([VK_QM_COM_X, VK_QM_COM_Y], rl))
+    res = ecadd(res, ecmul([VK_QO_COM_X, VK_QO_COM_Y], p[PROOF_O_AT_ZETA]))
+    res = ecadd(res, [VK_QK_COM_X, VK_QK_COM_Y])
+    res = ecadd(res, ecmul([p[PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + 0], p[PROOF_COMMITMENTS_WIRES_CUSTOM_GATES + 1]], p[PROOF_OPENING_QCP_AT_ZETA + 0]))
+    res = ecadd(res, ecmul([VK_S3_COM_X, VK_S3_COM_Y], s1))
+    return ecadd(res, ecmul([p[PROOF_GRAND_PRODUCT_COMMITMENT], p[PROOF_GRAND_PRODUCT_COMMITMENT + 1]], s2))
+
+
+@internal
+@pure
+def _compute_gamma_kzg(p: uint256[PROOF_NB_WORDS], zeta: uint256, folded_h: uint256[2], linearised_polynomial: uint256[2]) -> uint256:
+    """
+    @notice generate the challenge (using Fiat Shamir) to fold the opening proofs
+            at ζ, from ζ, the commitments [H], [Linearised polynomial], [L], [R],
+            [O], [S₁], [S₂], [Pi_{i}], and the evaluations at ζ of those
+            polynomials, followed by Z(ζω).
+    """
+    return convert(sha256(concat(
+        b"gamma",
+        convert(zeta, bytes32),
+        convert(folded_h[0], bytes32),
+        convert(folded_h[1], bytes32),
+        convert(linearised_polynomial[0], bytes32),
+        convert(linearised_polynomial[1], bytes32),
+        convert(p[PROOF_L_COM], bytes32),
+        convert(p[PROOF_L_COM + 1], bytes32),
+        convert(p[PROOF_R_COM], bytes32),
+        convert(p[PROOF_R_COM + 1], bytes32),
+        convert(p[PROOF_O_COM], bytes32),
+        convert(p[PROOF_O_COM + 1], bytes32),
+        convert(VK_S1_COM_X, bytes32),
+        convert(VK_S1_COM_Y, bytes32),
+        convert(VK_S2_COM_X, bytes32),
+        convert(VK_S2_COM_Y, bytes32),
+        convert(VK_QCP_0_X, bytes32),
+        convert(VK_QCP_0_Y, bytes32),
+        convert(p[PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA], bytes32),
+        convert(p[PROOF_LINEARISED_POLYNOMIAL_AT_ZETA], bytes32),
+        convert(p[PROOF_L_AT_ZETA], bytes32),
+        convert(p[PROOF_R_AT_ZETA], bytes32),
+        convert(p[PROOF_O_AT_ZETA], bytes32),
+        convert(p[PROOF_S1_AT_ZETA], bytes32),
+        convert(p[PROOF_S2_AT_ZETA], bytes32),
+        convert(p[PROOF_OPENING_QCP_AT_ZETA + 0], bytes32),
+        convert(p[PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA], bytes32),
+    )), uint256) % R_MOD
+
+
+@internal
+@view
+def _fold_state(p: uint256[PROOF_NB_WORDS], gamma_kzg: uint256, folded_h: uint256[2], linearised_polynomial: uint256[2]) -> (uint256[2], uint256):
+    """
+    @notice Fold the opening proofs at ζ:
+            * [H] + γ[Linearised_polynomial]+γ²[L] + γ³[R] + γ⁴[O] + γ⁵[S₁] +γ⁶[S₂] + ∑ᵢγ⁶⁺ⁱ[Pi_{i}]
+            * H(ζ) + γLinearised_polynomial(ζ)+γ²L(ζ) + γ³R(ζ)+ γ⁴O(ζ) + γ⁵S₁(ζ) +γ⁶S₂(ζ) + ∑ᵢγ⁶⁺ⁱPi_{i}(ζ)
+    @return the folded digests and the folded claimed values
+    """
+    acc_gamma: uint256 = gamma_kzg
+    folded_digests: uint256[2] = ecadd(folded_h, ecmul(linearised_polynomial, acc_gamma))
+    folded_claimed_values: uint256 = uint256_addmod(p[PROOF_QUOTIENT_POLYNOMIAL_AT_ZETA], uint256_mulmod(p[PROOF_LINEARISED_POLYNOMIAL_AT_ZETA], acc_gamma, R_MOD), R_MOD)
+
+    acc_gamma = uint256_mulmod(acc_gamma, gamma_kzg, R_MOD)
+    folded_digests = ecadd(folded_digests, ecmul([p[PROOF_L_COM], p[PROOF_L_COM + 1]], acc_gamma))
+    folded_claimed_values = uint256_addmod(folded_claimed_values, uint256_mulmod(p[PROOF_L_AT_ZETA], acc_gamma, R_MOD), R_MOD)
+
+    acc_gamma = uint256_mulmod(acc_gamma, gamma_kzg, R_MOD)
+    folded_digests = ecadd(folded_digests, ecmul([p[PROOF_R_COM], p[PROOF_R_COM + 1]], acc_gamma))
+    folded_claimed_values = uint256_addmod(folded_claimed_values, uint256_mulmod(p[PROOF_R_AT_ZETA], acc_gamma, R_MOD), R_MOD)
+
+    acc_gamma = uint256_mulmod(acc_gamma, gamma_kzg, R_MOD)
+    folded_digests = ecadd(folded_digests, ecmul([p[PROOF_O_COM], p[PROOF_O_COM + 1]], acc_gamma))
+    folded_claimed_values = uint256_addmod(folded_claimed_values, uint256_mulmod(p[PROOF_O_AT_ZETA], acc_gamma, R_MOD), R_MOD)
+
+    acc_gamma = uint256_mulmod(acc_gamma, gamma_kzg, R_MOD)
+    folded_digests = ecadd(folded_digests, ecmul([VK_S1_COM_X, VK_S1_COM_Y], acc_gamma))
+    folded_claimed_values = uint256_addmod(folded_claimed_values, uint256_mulmod(p[PROOF_S1_AT_ZETA], acc_gamma, R_MOD), R_MOD)
+
+    acc_gamma = uint256_mulmod(acc_gamma, gamma_kzg, R_MOD)
+    folded_digests = ecadd(folded_digests, ecmul([VK_S2_COM_X, VK_S2_COM_Y], acc_gamma))
+    folded_claimed_values = uint256_addmod(folded_claimed_values, uint256_mulmod(p[PROOF_S2_AT_ZETA], acc_gamma, R_MOD), R_MOD)
+
+    acc_gamma = uint256_mulmod(acc_gamma, gamma_kzg, R_MOD)
+    folded_digests = ecadd(folded_digests, ecmul([VK_QCP_0_X, VK_QCP_0_Y], acc_gamma))
+    folded_claimed_values = uint256_addmod(folded_claimed_values, uint256_mulmod(p[PROOF_OPENING_QCP_AT_ZETA + 0], acc_gamma, R_MOD), R_MOD)
+
+    return folded_digests, folded_claimed_values
+
+
+@internal
+@view
+def _batch_verify_multi_points(p: uint256[PROOF_NB_WORDS], zeta: uint256, gamma_kzg: uint256, folded_digests: uint256[2], folded_claimed_values: uint256) -> bool:
+    """
+    @notice follows alg. p.13 of https://eprint.iacr.org/2019/953.pdf
+            with t₁ = t₂ = 1, and the proofs are ([digest] + [quotient] +purported evaluation):
+            * [folded_digests], [proof_batch_opening_at_zeta], folded_claimed_values
+            * [proof_grand_product_commitment], [proof_opening_at_zeta_omega], [proof_grand_product_at_zeta_omega]
+    """
+    # derive a random number. As there is no random generator, we
+    # do an FS like challenge derivation, depending on both digests and
+    # ζ to ensure that the prover cannot control the random number.
+    # Note: adding the other point ζω is not needed, as ω is known beforehand.
+    random: uint256 = convert(sha256(concat(
+        convert(folded_digests[0], bytes32),
+        convert(folded_digests[1], bytes32),
+        convert(p[PROOF_BATCH_OPENING_AT_ZETA], bytes32),
+        convert(p[PROOF_BATCH_OPENING_AT_ZETA + 1], bytes32),
+        convert(p[PROOF_GRAND_PRODUCT_COMMITMENT], bytes32),
+        convert(p[PROOF_GRAND_PRODUCT_COMMITMENT + 1], bytes32),
+        convert(p[PROOF_OPENING_AT_ZETA_OMEGA], bytes32),
+        convert(p[PROOF_OPENING_AT_ZETA_OMEGA + 1], bytes32),
+        convert(zeta, bytes32),
+        convert(gamma_kzg, bytes32),
+    )), uint256) % R_MOD
+
+    opening_at_zeta: uint256[2] = [p[PROOF_BATCH_OPENING_AT_ZETA], p[PROOF_BATCH_OPENING_AT_ZETA + 1]]
+    opening_at_zeta_omega: uint256[2] = [p[PROOF_OPENING_AT_ZETA_OMEGA], p[PROOF_OPENING_AT_ZETA_OMEGA + 1]]
+
+    folded_quotients: uint256[2] = ecadd(opening_at_zeta, ecmul(opening_at_zeta_omega, random))
+
+    digests: uint256[2] = ecadd(folded_digests, ecmul([p[PROOF_GRAND_PRODUCT_COMMITMENT], p[PROOF_GRAND_PRODUCT_COMMITMENT + 1]], random))
+    evals: uint256 = uint256_addmod(folded_claimed_values, uint256_mulmod(p[PROOF_GRAND_PRODUCT_AT_ZETA_OMEGA], random, R_MOD), R_MOD)
+
+    folded_evals_commit: uint256[2] = ecmul([G1_SRS_X, G1_SRS_Y], evals)
+    digests = ecadd(digests, [folded_evals_commit[0], P_MOD - folded_evals_commit[1]])
+
+    folded_points_quotients: uint256[2] = ecmul(opening_at_zeta, zeta)
+    zeta_omega: uint256 = uint256_mulmod(zeta, VK_OMEGA, R_MOD)
+    folded_points_quotients = ecadd(folded_points_quotients, ecmul(opening_at_zeta_omega, uint256_mulmod(random, zeta_omega, R_MOD)))
+    digests = ecadd(digests, folded_points_quotients)
+
+    # e([digests], [1]) ⋅ e(-[folded_quotients], [x]) = 1
+    data: Bytes[384] = concat(
+        convert(digests[0], bytes32),
+        convert(digests[1], bytes32),
+        convert(G2_SRS_0_X_0, bytes32),
+        convert(G2_SRS_0_X_1, bytes32),
+        convert(G2_SRS_0_Y_0, bytes32),
+        convert(G2_SRS_0_Y_1, bytes32),
+        convert(folded_quotients[0], bytes32),
+        convert(P_MOD - folded_quotients[1], bytes32),
+        convert(G2_SRS_1_X_0, bytes32),
+        convert(G2_SRS_1_X_1, bytes32),
+        convert(G2_SRS_1_Y_0, bytes32),
+        convert(G2_SRS_1_Y_1, bytes32),
+    )
+    success: bool = False
+    response: Bytes[32] = b""
+    success, response = raw_call(
+        PRECOMPILE_PAIRING,
+        data,
+        max_outsize=32,
+        is_static_call=True,
+        revert_on_failure=False,
+    )
+    return success and len(response) == 32 and convert(response, uint256) == 1
+
+
+# BEGINNING utils math functions -------------------------------------------------
+
+@internal
+@view
+def _pow(x: uint256, e: uint256) -> uint256:
+    """
+    @return x ** e mod r
+    """
+    response: Bytes[32] = raw_call(
+        PRECOMPILE_MODEXP,
+        concat(
+            convert(32, bytes32),
+            convert(32, bytes32),
+            convert(32, bytes32),
+            convert(x, bytes32),
+            convert(e, bytes32),
+            convert(R_MOD, bytes32),
+        ),
+        max_outsize=32,
+        is_static_call=True,
+    )
+    return convert(response, uint256)
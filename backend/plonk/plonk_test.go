@@ -2,15 +2,19 @@ package plonk_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
 
 	"github.com/consensys/gnark"
 	"github.com/consensys/gnark-crypto/ecc"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
 	"github.com/consensys/gnark-crypto/kzg"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/plonk"
+	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/scs"
@@ -108,6 +112,72 @@ func TestCustomHashToField(t *testing.T) {
 	}
 }
 
+func TestProveCanceled(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range getCurves() {
+		curve := curve
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &smallCircuit{})
+			assert.NoError(err)
+			srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&smallCircuit{X: 1}, curve.ScalarField())
+			assert.NoError(err)
+			pk, _, err := plonk.Setup(ccs, srs, srsLagrange)
+			assert.NoError(err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = plonk.Prove(ccs, pk, fullWitness, backend.WithContext(ctx))
+			assert.Error(err)
+			assert.True(errors.Is(err, context.Canceled))
+			var errCanceled *backend.ProverCanceledError
+			assert.True(errors.As(err, &errCanceled))
+
+			// the same inputs are still valid with a live context
+			_, err = plonk.Prove(ccs, pk, fullWitness, backend.WithContext(context.Background()))
+			assert.NoError(err)
+		}, curve.String())
+	}
+}
+
+func TestProveProgress(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range getCurves() {
+		curve := curve
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &smallCircuit{})
+			assert.NoError(err)
+			srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+			assert.NoError(err)
+			fullWitness, err := frontend.NewWitness(&smallCircuit{X: 1}, curve.ScalarField())
+			assert.NoError(err)
+			pk, _, err := plonk.Setup(ccs, srs, srsLagrange)
+			assert.NoError(err)
+
+			var events []backend.ProgressEvent
+			handler := func(ev backend.ProgressEvent) {
+				events = append(events, ev)
+			}
+			_, err = plonk.Prove(ccs, pk, fullWitness, backend.WithProgressHandler(handler))
+			assert.NoError(err)
+
+			phases := make(map[backend.ProgressPhase]bool)
+			lastPercent := 0.0
+			for _, ev := range events {
+				phases[ev.Phase] = true
+				assert.True(ev.Step <= ev.NbSteps)
+				assert.True(ev.Percent >= lastPercent)
+				lastPercent = ev.Percent
+			}
+			for _, phase := range []backend.ProgressPhase{backend.PhaseSolve, backend.PhaseCommitments, backend.PhaseQuotient, backend.PhaseOpening} {
+				assert.True(phases[phase], "missing phase %s", phase)
+			}
+			assert.InDelta(100, lastPercent, 1e-9)
+		}, curve.String())
+	}
+}
+
 func TestCustomChallengeHash(t *testing.T) {
 	assert := test.NewAssert(t)
 	assignment := &smallCircuit{X: 1}
@@ -194,6 +264,148 @@ func TestCustomKZGFoldingHash(t *testing.T) {
 	}
 }
 
+func TestCustomGates(t *testing.T) {
+	assert := test.NewAssert(t)
+	good := &customGateCircuit{X: 3, Y: 243, X1: 1, Y1: 2, X2: 2, Y2: 7, X3: 22, Y3: -107}
+	badSbox := &customGateCircuit{X: 3, Y: 244, X1: 1, Y1: 2, X2: 2, Y2: 7, X3: 22, Y3: -107}
+	badAdd := &customGateCircuit{X: 3, Y: 243, X1: 1, Y1: 2, X2: 2, Y2: 7, X3: 22, Y3: 107}
+	for _, curve := range getCurves() {
+		curve := curve
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &customGateCircuit{})
+			assert.NoError(err)
+			srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+			assert.NoError(err)
+
+			// the verifying key embeds the custom gates
+			var buf bytes.Buffer
+			_, err = vk.WriteTo(&buf)
+			assert.NoError(err)
+			vkReloaded := plonk.NewVerifyingKey(curve)
+			_, err = vkReloaded.ReadFrom(&buf)
+			assert.NoError(err)
+
+			witness, err := frontend.NewWitness(good, curve.ScalarField())
+			assert.NoError(err)
+			pubWitness, err := witness.Public()
+			assert.NoError(err)
+			proof, err := plonk.Prove(ccs, pk, witness)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, pubWitness))
+			assert.NoError(plonk.Verify(proof, vkReloaded, pubWitness))
+
+			buf.Reset()
+			_, err = proof.WriteTo(&buf)
+			assert.NoError(err)
+			proofReloaded := plonk.NewProof(curve)
+			_, err = proofReloaded.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proofReloaded, vk, pubWitness))
+
+			for _, bad := range []*customGateCircuit{badSbox, badAdd} {
+				witness, err := frontend.NewWitness(bad, curve.ScalarField())
+				assert.NoError(err)
+				_, err = plonk.Prove(ccs, pk, witness)
+				assert.Error(err)
+			}
+		}, curve.String())
+	}
+}
+
+// TestSolidityCustomGates checks a proof of a circuit with custom gates, one of
+// them spanning two rows, against the exported Solidity verifier.
+func TestSolidityCustomGates(t *testing.T) {
+	assert := test.NewAssert(t)
+	// the test engine doesn't implement frontend.PlonkAPI
+	assert.CheckCircuit(&customGateCircuit{},
+		test.WithValidAssignment(&customGateCircuit{X: 3, Y: 243, X1: 1, Y1: 2, X2: 2, Y2: 7, X3: 22, Y3: -107}),
+		test.WithCurves(ecc.BN254, ecc.BLS12_381),
+		test.WithBackends(backend.PLONK),
+		test.NoTestEngine(),
+		test.NoFuzzing(),
+	)
+}
+
+// TestExportCustomGates checks that the Vyper, Move and Cairo verifiers are
+// exported for a circuit with custom gates, one of them spanning two rows.
+func TestExportCustomGates(t *testing.T) {
+	assert := require.New(t)
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &customGateCircuit{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	assert.NoError(err)
+	_, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	assert.NoError(err)
+
+	for _, target := range []backend.Target{backend.Vyper, backend.Move, backend.Cairo} {
+		var buf bytes.Buffer
+		assert.NoError(vk.Export(&buf, target), target)
+		assert.Contains(buf.String(), "compute_custom_gates", target)
+		assert.Contains(buf.String(), "fold_lro_at_zeta_omega", target)
+	}
+}
+
+func TestLookups(t *testing.T) {
+	assert := test.NewAssert(t)
+	good := &lookupCircuit{X: [3]frontend.Variable{0, 17, 255}, Y: 7, Z: 9, Z5: 59049}
+	badX := &lookupCircuit{X: [3]frontend.Variable{0, 256, 255}, Y: 7, Z: 9, Z5: 59049}
+	badY := &lookupCircuit{X: [3]frontend.Variable{0, 17, 255}, Y: 6, Z: 9, Z5: 59049}
+
+	// fixed tables are opt-in
+	_, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &lookupCircuit{})
+	assert.Error(err)
+
+	for _, curve := range getCurves() {
+		curve := curve
+		assert.Run(func(assert *test.Assert) {
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &lookupCircuit{}, frontend.WithNativeLookups())
+			assert.NoError(err)
+			srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+			assert.NoError(err)
+			pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+			assert.NoError(err)
+
+			var buf bytes.Buffer
+			_, err = vk.WriteTo(&buf)
+			assert.NoError(err)
+			vkReloaded := plonk.NewVerifyingKey(curve)
+			_, err = vkReloaded.ReadFrom(&buf)
+			assert.NoError(err)
+
+			witness, err := frontend.NewWitness(good, curve.ScalarField())
+			assert.NoError(err)
+			pubWitness, err := witness.Public()
+			assert.NoError(err)
+			proof, err := plonk.Prove(ccs, pk, witness)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proof, vk, pubWitness))
+			assert.NoError(plonk.Verify(proof, vkReloaded, pubWitness))
+
+			buf.Reset()
+			_, err = proof.WriteTo(&buf)
+			assert.NoError(err)
+			proofReloaded := plonk.NewProof(curve)
+			_, err = proofReloaded.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.NoError(plonk.Verify(proofReloaded, vk, pubWitness))
+
+			for _, bad := range []*lookupCircuit{badX, badY} {
+				witness, err := frontend.NewWitness(bad, curve.ScalarField())
+				assert.NoError(err)
+				_, err = plonk.Prove(ccs, pk, witness)
+				assert.Error(err)
+			}
+
+			// the exported verifiers don't support lookups
+			if curve == ecc.BN254 {
+				assert.Error(vk.ExportSolidity(&buf))
+			}
+		}, curve.String())
+	}
+}
+
 func BenchmarkSetup(b *testing.B) {
 	for _, curve := range getCurves() {
 		b.Run(curve.String(), func(b *testing.B) {
@@ -320,6 +532,167 @@ func (c *smallCircuit) Define(api frontend.API) error {
 	return nil
 }
 
+var (
+	// y = x⁵ on the row (x, x², y)
+	sboxGate = constraint.CustomGate{
+		Name: "sbox",
+		Identities: [][]constraint.GateTerm{
+			{{Coeff: 1, Wires: []constraint.GateWire{constraint.GateR}}, {Coeff: -1, Wires: []constraint.GateWire{constraint.GateL, constraint.GateL}}},
+			{{Coeff: 1, Wires: []constraint.GateWire{constraint.GateO}}, {Coeff: -1, Wires: []constraint.GateWire{constraint.GateR, constraint.GateR, constraint.GateL}}},
+		},
+	}
+
+	// (x₃, y₃) = (x₁, y₁) + (x₂, y₂) on the rows (x₁, y₁, x₂), (y₂, x₃, y₃), using
+	//
+	//	(x₃ + x₁ + x₂)(x₂ - x₁)² - (y₂ - y₁)² == 0
+	//	(y₃ + y₁)(x₂ - x₁) - (y₂ - y₁)(x₁ - x₃) == 0
+	ecAddGate = func() constraint.CustomGate {
+		x1, y1, x2 := constraint.GateL, constraint.GateR, constraint.GateO
+		y2, x3, y3 := constraint.GateNextL, constraint.GateNextR, constraint.GateNextO
+		term := func(coeff int64, wires ...constraint.GateWire) constraint.GateTerm {
+			return constraint.GateTerm{Coeff: coeff, Wires: wires}
+		}
+		return constraint.CustomGate{
+			Name: "ecAdd",
+			Identities: [][]constraint.GateTerm{
+				{
+					term(1, x2, x2, x3), term(-2, x1, x2, x3), term(1, x1, x1, x3),
+					term(-1, x1, x2, x2), term(-1, x1, x1, x2), term(1, x1, x1, x1), term(1, x2, x2, x2),
+					term(-1, y2, y2), term(2, y1, y2), term(-1, y1, y1),
+				},
+				{
+					term(1, x2, y3), term(-1, x1, y3), term(1, x2, y1),
+					term(-1, x1, y2), term(1, x3, y2), term(-1, x3, y1),
+				},
+			},
+		}
+	}()
+)
+
+// TestCustomGatesTamperedProof checks that the verifier recomputes the custom
+// gates on the claimed values: changing a claimed value of the shifted wires,
+// or a gate of the verifying key, must make the verification fail.
+func TestCustomGatesTamperedProof(t *testing.T) {
+	assert := test.NewAssert(t)
+	good := &customGateCircuit{X: 3, Y: 243, X1: 1, Y1: 2, X2: 2, Y2: 7, X3: 22, Y3: -107}
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &customGateCircuit{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(ccs)
+	assert.NoError(err)
+	pk, vk, err := plonk.Setup(ccs, srs, srsLagrange)
+	assert.NoError(err)
+	witness, err := frontend.NewWitness(good, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	pubWitness, err := witness.Public()
+	assert.NoError(err)
+	proof, err := plonk.Prove(ccs, pk, witness)
+	assert.NoError(err)
+	assert.NoError(plonk.Verify(proof, vk, pubWitness))
+
+	_proof := proof.(*plonk_bn254.Proof)
+	assert.Equal(3, len(_proof.LROShiftedOpening.ClaimedValues))
+	for i := range _proof.LROShiftedOpening.ClaimedValues {
+		var one fr_bn254.Element
+		one.SetOne()
+		_proof.LROShiftedOpening.ClaimedValues[i].Add(&_proof.LROShiftedOpening.ClaimedValues[i], &one)
+		assert.Error(plonk.Verify(proof, vk, pubWitness), "shifted wire %d", i)
+		_proof.LROShiftedOpening.ClaimedValues[i].Sub(&_proof.LROShiftedOpening.ClaimedValues[i], &one)
+	}
+	assert.NoError(plonk.Verify(proof, vk, pubWitness))
+
+	// same selectors, but the verifier evaluates another identity
+	var buf bytes.Buffer
+	_, err = vk.WriteTo(&buf)
+	assert.NoError(err)
+	wrongVk := plonk.NewVerifyingKey(ecc.BN254)
+	_, err = wrongVk.ReadFrom(&buf)
+	assert.NoError(err)
+	gates := wrongVk.(*plonk_bn254.VerifyingKey).CustomGates
+	gates[len(gates)-1].Identities[0][0].Coeff++
+	assert.Error(plonk.Verify(proof, wrongVk, pubWitness))
+}
+
+type customGateCircuit struct {
+	X, Y                   frontend.Variable `gnark:",public"`
+	X1, Y1, X2, Y2, X3, Y3 frontend.Variable
+}
+
+func (c *customGateCircuit) Define(api frontend.API) error {
+	papi := api.(frontend.PlonkAPI)
+	sbox, err := papi.AddCustomGate(sboxGate)
+	if err != nil {
+		return err
+	}
+	ecAdd, err := papi.AddCustomGate(ecAddGate)
+	if err != nil {
+		return err
+	}
+	x2 := api.Mul(c.X, c.X)
+	papi.AddCustomConstraint(sbox, c.X, x2, c.Y)
+	// scaled terms and constants are moved to wires of their own
+	papi.AddCustomConstraint(sbox, api.Mul(c.X, 2), api.Mul(x2, 4), api.Mul(c.Y, 32))
+	papi.AddCustomConstraint(sbox, 2, 4, 32)
+	papi.AddCustomConstraint(ecAdd, c.X1, c.Y1, c.X2, c.Y2, c.X3, c.Y3)
+
+	// the custom gate selectors come after the BSB22 commitment ones
+	cmt, err := api.(frontend.Committer).Commit(c.X, c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(cmt, 0)
+	return nil
+}
+
+type lookupCircuit struct {
+	X     [3]frontend.Variable
+	Y     frontend.Variable
+	Z, Z5 frontend.Variable `gnark:",public"`
+}
+
+func (c *lookupCircuit) Define(api frontend.API) error {
+	lookuper, ok := api.Compiler().(frontend.FixedTableLookuper)
+	if !ok {
+		return errors.New("fixed tables not supported")
+	}
+	bytesTable := make([]*big.Int, 256)
+	for i := range bytesTable {
+		bytesTable[i] = big.NewInt(int64(i))
+	}
+	bytesID, err := lookuper.NewFixedTable(bytesTable)
+	if err != nil {
+		return err
+	}
+	// entries may repeat
+	oddID, err := lookuper.NewFixedTable([]*big.Int{big.NewInt(3), big.NewInt(7), big.NewInt(5), big.NewInt(7)})
+	if err != nil {
+		return err
+	}
+	for i := range c.X {
+		lookuper.AssertIsInFixedTable(bytesID, c.X[i])
+	}
+	lookuper.AssertIsInFixedTable(bytesID, api.Add(c.X[0], c.X[1]))
+	lookuper.AssertIsInFixedTable(oddID, c.Y)
+	lookuper.AssertIsInFixedTable(oddID, c.Y)
+	lookuper.AssertIsInFixedTable(oddID, 5)
+	// 7 is an entry of the second table only
+	api.AssertIsDifferent(api.Add(c.X[2], 1), c.Y)
+
+	// the lookup identity comes after the ones of the custom gates
+	papi := api.(frontend.PlonkAPI)
+	sbox, err := papi.AddCustomGate(sboxGate)
+	if err != nil {
+		return err
+	}
+	papi.AddCustomConstraint(sbox, c.Z, api.Mul(c.Z, c.Z), c.Z5)
+
+	cmt, err := api.(frontend.Committer).Commit(c.X[0], c.Y)
+	if err != nil {
+		return err
+	}
+	api.AssertIsDifferent(cmt, 0)
+	return nil
+}
+
 type constantHash struct{}
 
 func (h constantHash) Write(p []byte) (n int, err error) { return len(p), nil }
@@ -0,0 +1,429 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/mimc"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+var (
+	errFriProofShape   = errors.New("fri proof does not match the parameters")
+	errFriMerklePath   = errors.New("fri merkle path is wrong")
+	errFriFolding      = errors.New("fri folding is not consistent")
+	errFriFinalPolynom = errors.New("fri final polynomial is not consistent")
+)
+
+// FriStep is the opening of a committed layer of FRI at a query.
+type FriStep struct {
+	// Evals stores the evaluations at the points x and -x of the layer,
+	// which form a single Merkle leaf.
+	Evals [2]fr.Element
+	// MerkleProof stores the siblings from the leaf to the root.
+	MerkleProof []fr.Element
+}
+
+// FriProof is a FRI proof of proximity, with the same layout as
+// gnark/std/commitments/fri.NativeProof so that it can be verified in a
+// circuit.
+//
+// The committed polynomial f₀ of degree less than D, the degree bound of the
+// verifying key, is evaluated on the coset g·H of the subgroup H of size
+// N = D·2^RateBits. The evaluations are stored in bit-reversed order so that
+// the points x and -x are adjacent, and a Merkle tree with MiMC is built where
+// every leaf is the hash of such a pair.
+// At every layer the polynomial is folded with a challenge β as
+//
+//	fᵢ₊₁(x²) = (fᵢ(x) + fᵢ(-x))/2 + β·(fᵢ(x) - fᵢ(-x))/(2x)
+//
+// until it is constant, and the constant is sent in clear.
+type FriProof struct {
+	// Commitments are the Merkle roots of the layers, starting with the
+	// committed polynomial.
+	Commitments []fr.Element
+	// QueryRounds stores the openings of every layer for every query.
+	QueryRounds [][]FriStep
+	// FinalPoly stores the coefficients of the last folded polynomial.
+	FinalPoly []fr.Element
+}
+
+// friQuery is a query of the verifier in the first evaluation domain.
+type friQuery struct {
+	// position of the query in the first evaluation domain, in bit-reversed
+	// order.
+	position uint64
+	// point of the first evaluation domain at position.
+	point fr.Element
+	// eval is the opened evaluation of the committed polynomial at point.
+	eval fr.Element
+}
+
+// friParams are the parameters of an instance of FRI.
+type friParams struct {
+	// logDegree is the logarithm of the degree bound of the committed
+	// polynomial, which is also the number of foldings.
+	logDegree int
+	nbQueries int
+	// domain is the first evaluation domain, its shift is
+	// domain.FrMultiplicativeGen.
+	domain *fft.Domain
+}
+
+// challengeIDs returns the names of the challenges derived by FRI, see
+// gnark/std/commitments/fri.NativeChallengeIDs.
+func (p *friParams) challengeIDs() []string {
+	res := make([]string, 0, p.logDegree+p.nbQueries)
+	for i := 0; i < p.logDegree; i++ {
+		res = append(res, fmt.Sprintf("fri%d", i))
+	}
+	for i := 0; i < p.nbQueries; i++ {
+		res = append(res, fmt.Sprintf("fq%d", i))
+	}
+	return res
+}
+
+// logDomainSize returns the logarithm of the size of the first evaluation
+// domain.
+func (p *friParams) logDomainSize() int {
+	return bits.TrailingZeros64(p.domain.Cardinality)
+}
+
+// buildProof computes the proof of proximity of the polynomial of degree less
+// than 2^logDegree whose evaluations on the first evaluation domain are given
+// in bit-reversed order. The challenges are derived from fs, whose last
+// challenges must be challengeIDs(). It returns the proof and the positions of
+// the queries.
+func (p *friParams) buildProof(fs *fiatshamir.Transcript, evals []fr.Element) (FriProof, []uint64, error) {
+	ids := p.challengeIDs()
+	proof := FriProof{
+		Commitments: make([]fr.Element, p.logDegree),
+		QueryRounds: make([][]FriStep, p.nbQueries),
+	}
+
+	layers := make([][]fr.Element, p.logDegree)
+	trees := make([]merkleTree, p.logDegree)
+	layer := evals
+	var shift, generator, half fr.Element
+	shift.Set(&p.domain.FrMultiplicativeGen)
+	generator.Set(&p.domain.Generator)
+	half.SetUint64(2).Inverse(&half)
+
+	for i := 0; i < p.logDegree; i++ {
+		layers[i] = layer
+		trees[i] = commitEvaluations(layer)
+		proof.Commitments[i] = trees[i].root()
+		beta, err := deriveRandomness(fs, ids[i], &proof.Commitments[i])
+		if err != nil {
+			return FriProof{}, nil, err
+		}
+
+		// the point at position 2j of the layer is shift·ω^rev(j), where the
+		// bit reversal is on the number of leaves.
+		nbLeaves := len(layer) / 2
+		xInv := make([]fr.Element, nbLeaves)
+		fft.BuildExpTable(generator, xInv)
+		for j := range xInv {
+			xInv[j].Mul(&xInv[j], &shift)
+		}
+		if nbLeaves > 1 {
+			fft.BitReverse(xInv)
+		}
+		xInv = fr.BatchInvert(xInv)
+
+		next := make([]fr.Element, nbLeaves)
+		utils.Parallelize(nbLeaves, func(start, end int) {
+			var sum, diff fr.Element
+			for j := start; j < end; j++ {
+				sum.Add(&layer[2*j], &layer[2*j+1])
+				diff.Sub(&layer[2*j], &layer[2*j+1]).Mul(&diff, &beta).Mul(&diff, &xInv[j])
+				next[j].Add(&sum, &diff).Mul(&next[j], &half)
+			}
+		})
+		layer = next
+		shift.Square(&shift)
+		generator.Square(&generator)
+	}
+
+	// the last folded polynomial is constant, its coefficient is the mean of
+	// its evaluations on the coset.
+	var sum, nInv fr.Element
+	for j := range layer {
+		sum.Add(&sum, &layer[j])
+	}
+	nInv.SetUint64(uint64(len(layer))).Inverse(&nInv)
+	proof.FinalPoly = []fr.Element{*sum.Mul(&sum, &nInv)}
+
+	positions, err := p.deriveQueries(fs, &proof)
+	if err != nil {
+		return FriProof{}, nil, err
+	}
+	for q, position := range positions {
+		proof.QueryRounds[q] = make([]FriStep, p.logDegree)
+		for i := 0; i < p.logDegree; i++ {
+			leaf := int(position >> (i + 1))
+			proof.QueryRounds[q][i].Evals = [2]fr.Element{layers[i][2*leaf], layers[i][2*leaf+1]}
+			proof.QueryRounds[q][i].MerkleProof = trees[i].path(leaf)
+		}
+	}
+
+	return proof, positions, nil
+}
+
+// deriveQueries binds the final polynomial and derives the positions of the
+// queries.
+func (p *friParams) deriveQueries(fs *fiatshamir.Transcript, proof *FriProof) ([]uint64, error) {
+	ids := p.challengeIDs()
+	for i := range proof.FinalPoly {
+		if err := fs.Bind(ids[p.logDegree], proof.FinalPoly[i].Marshal()); err != nil {
+			return nil, err
+		}
+	}
+	positions := make([]uint64, p.nbQueries)
+	var b big.Int
+	for q := range positions {
+		c, err := deriveRandomness(fs, ids[p.logDegree+q])
+		if err != nil {
+			return nil, err
+		}
+		c.BigInt(&b)
+		positions[q] = b.Uint64() & (p.domain.Cardinality - 1)
+	}
+	return positions, nil
+}
+
+// verify verifies the proof of proximity, deriving the challenges from fs. It
+// returns the queries of the verifier, the caller is responsible for checking
+// them against the other oracles of its protocol.
+func (p *friParams) verify(fs *fiatshamir.Transcript, proof *FriProof) ([]friQuery, error) {
+	if err := p.checkShape(proof); err != nil {
+		return nil, err
+	}
+	ids := p.challengeIDs()
+
+	// commit phase: derive the folding challenges
+	betas := make([]fr.Element, p.logDegree)
+	for i := range betas {
+		var err error
+		if betas[i], err = deriveRandomness(fs, ids[i], &proof.Commitments[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	// query phase
+	positions, err := p.deriveQueries(fs, proof)
+	if err != nil {
+		return nil, err
+	}
+	logN := p.logDomainSize()
+	var half fr.Element
+	half.SetUint64(2).Inverse(&half)
+	res := make([]friQuery, p.nbQueries)
+	for q, position := range positions {
+		res[q].position = position
+		point := queryPoint(p.domain, position)
+		res[q].point = point
+
+		var folded fr.Element
+		for i, step := range proof.QueryRounds[q] {
+			slot := (position >> i) & 1
+			leaf := position >> (i + 1)
+			if i == 0 {
+				res[q].eval = step.Evals[slot]
+			} else if !step.Evals[slot].Equal(&folded) {
+				return nil, errFriFolding
+			}
+			if !verifyMerklePath(proof.Commitments[i], step.Evals[:], step.MerkleProof, leaf, logN-i-1) {
+				return nil, errFriMerklePath
+			}
+
+			// x is the point of the first evaluation of the leaf
+			var x, sum, diff fr.Element
+			x.Set(&point)
+			if slot == 1 {
+				x.Neg(&x)
+			}
+			sum.Add(&step.Evals[0], &step.Evals[1])
+			diff.Sub(&step.Evals[0], &step.Evals[1]).Mul(&diff, &betas[i]).Div(&diff, &x)
+			folded.Add(&sum, &diff).Mul(&folded, &half)
+
+			point.Square(&point)
+		}
+
+		// evaluate the final polynomial at the last point
+		var acc fr.Element
+		for i := len(proof.FinalPoly) - 1; i >= 0; i-- {
+			acc.Mul(&acc, &point).Add(&acc, &proof.FinalPoly[i])
+		}
+		if !acc.Equal(&folded) {
+			return nil, errFriFinalPolynom
+		}
+	}
+	return res, nil
+}
+
+func (p *friParams) checkShape(proof *FriProof) error {
+	if len(proof.Commitments) != p.logDegree ||
+		len(proof.QueryRounds) != p.nbQueries ||
+		len(proof.FinalPoly) != 1 {
+		return errFriProofShape
+	}
+	logN := p.logDomainSize()
+	for q := range proof.QueryRounds {
+		if len(proof.QueryRounds[q]) != p.logDegree {
+			return errFriProofShape
+		}
+		for i := range proof.QueryRounds[q] {
+			if len(proof.QueryRounds[q][i].MerkleProof) != logN-i-1 {
+				return errFriProofShape
+			}
+		}
+	}
+	return nil
+}
+
+// queryPoint returns the point of the evaluation domain at the given position
+// in bit-reversed order.
+func queryPoint(domain *fft.Domain, position uint64) fr.Element {
+	logN := bits.TrailingZeros64(domain.Cardinality)
+	rev := bits.Reverse64(position) >> (64 - logN)
+	var res fr.Element
+	res.Exp(domain.Generator, new(big.Int).SetUint64(rev)).
+		Mul(&res, &domain.FrMultiplicativeGen)
+	return res
+}
+
+// lde returns the evaluations of p, given in canonical basis, on the
+// evaluation domain in bit-reversed order.
+func lde(p []fr.Element, domain *fft.Domain) []fr.Element {
+	res := make([]fr.Element, domain.Cardinality)
+	copy(res, p)
+	domain.FFT(res, fft.DIF, fft.OnCoset())
+	return res
+}
+
+// merkleTree stores the levels of a Merkle tree built with MiMC, from the
+// hashes of the leaves up to the root.
+type merkleTree [][]fr.Element
+
+// commitEvaluations builds the Merkle tree whose j-th leaf is the hash of the
+// evaluations of the polynomials at the positions 2j, followed by their
+// evaluations at the position 2j+1. The evaluations are in bit-reversed order,
+// so that those positions correspond to the points x and -x.
+func commitEvaluations(evals ...[]fr.Element) merkleTree {
+	nbLeaves := len(evals[0]) / 2
+	depth := bits.TrailingZeros(uint(nbLeaves))
+	t := make(merkleTree, depth+1)
+	t[0] = make([]fr.Element, nbLeaves)
+	utils.Parallelize(nbLeaves, func(start, end int) {
+		h := mimc.NewMiMC()
+		for j := start; j < end; j++ {
+			t[0][j] = hashElements(h, leafValues(j, evals...)...)
+		}
+	})
+	for l := 1; l <= depth; l++ {
+		t[l] = make([]fr.Element, len(t[l-1])/2)
+		utils.Parallelize(len(t[l]), func(start, end int) {
+			h := mimc.NewMiMC()
+			for j := start; j < end; j++ {
+				t[l][j] = hashElements(h, t[l-1][2*j], t[l-1][2*j+1])
+			}
+		})
+	}
+	return t
+}
+
+// leafValues returns the content of the j-th leaf of the tree built by
+// commitEvaluations.
+func leafValues(j int, evals ...[]fr.Element) []fr.Element {
+	res := make([]fr.Element, 2*len(evals))
+	for k := range evals {
+		res[k] = evals[k][2*j]
+		res[len(evals)+k] = evals[k][2*j+1]
+	}
+	return res
+}
+
+func (t merkleTree) root() fr.Element {
+	return t[len(t)-1][0]
+}
+
+// path returns the siblings of the nodes from the j-th leaf up to the root.
+func (t merkleTree) path(j int) []fr.Element {
+	res := make([]fr.Element, len(t)-1)
+	for l := range res {
+		res[l] = t[l][j^1]
+		j >>= 1
+	}
+	return res
+}
+
+// verifyMerklePath returns true if leaf is the content of the leaf at position
+// j of the tree of the given depth and root.
+func verifyMerklePath(root fr.Element, leaf, path []fr.Element, j uint64, depth int) bool {
+	if len(path) != depth || j>>depth != 0 {
+		return false
+	}
+	h := mimc.NewMiMC()
+	node := hashElements(h, leaf...)
+	for _, sibling := range path {
+		if j&1 == 0 {
+			node = hashElements(h, node, sibling)
+		} else {
+			node = hashElements(h, sibling, node)
+		}
+		j >>= 1
+	}
+	return node.Equal(&root)
+}
+
+// hashElements returns the MiMC hash of the given elements, as computed by
+// gnark/std/hash/mimc in a circuit.
+func hashElements(h hash.Hash, elements ...fr.Element) fr.Element {
+	h.Reset()
+	for i := range elements {
+		b := elements[i].Bytes()
+		h.Write(b[:])
+	}
+	var res fr.Element
+	res.SetBytes(h.Sum(nil))
+	return res
+}
+
+// deriveRandomness binds the elements to the challenge and computes it.
+func deriveRandomness(fs *fiatshamir.Transcript, challenge string, elements ...*fr.Element) (fr.Element, error) {
+	var res fr.Element
+	for _, e := range elements {
+		if err := fs.Bind(challenge, e.Marshal()); err != nil {
+			return res, err
+		}
+	}
+	b, err := fs.ComputeChallenge(challenge)
+	if err != nil {
+		return res, err
+	}
+	res.SetBytes(b)
+	return res, nil
+}
@@ -0,0 +1,233 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	"errors"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/logger"
+	"math/big"
+	"time"
+)
+
+var (
+	errWrongClaimedQuotient = errors.New("claimed quotient is not as expected")
+	errInvalidWitness       = errors.New("witness length is invalid")
+	errInvalidProofShape    = errors.New("proof does not match the verifying key")
+	errOpeningMerklePath    = errors.New("merkle path of an opening is wrong")
+	errDeepQuotient         = errors.New("opened values do not match the DEEP quotient")
+)
+
+// Verify verifies a PLONK with FRI proof from the public data.
+//
+// The challenges are derived with MiMC, hence the options ChallengeHash,
+// HashToFieldFn and KZGFoldingHash are ignored.
+func Verify(proof *Proof, vk *VerifyingKey, publicWitness fr.Vector, opts ...backend.VerifierOption) error {
+	log := logger.Logger().With().Str("curve", "bls12-377").Str("backend", "plonk_fri").Logger()
+	start := time.Now()
+	if _, err := backend.NewVerifierConfig(opts...); err != nil {
+		return err
+	}
+
+	if len(publicWitness) != int(vk.NbPublicVariables) {
+		return errInvalidWitness
+	}
+	fp := vk.friParams(fft.WithoutPrecompute())
+	if err := checkProofShape(proof, fp); err != nil {
+		return err
+	}
+
+	// derive the challenges
+	fs := newTranscript(fp)
+	bindings := make([]*fr.Element, 0, len(publicWitness)+2)
+	bindings = append(bindings, &vk.Preprocessed)
+	for i := range publicWitness {
+		bindings = append(bindings, &publicWitness[i])
+	}
+	bindings = append(bindings, &proof.LRO)
+	gamma, err := deriveRandomness(fs, "gamma", bindings...)
+	if err != nil {
+		return err
+	}
+	beta, err := deriveRandomness(fs, "beta")
+	if err != nil {
+		return err
+	}
+	alpha, err := deriveRandomness(fs, "alpha", &proof.Z)
+	if err != nil {
+		return err
+	}
+	zeta, err := deriveRandomness(fs, "zeta", &proof.H)
+	if err != nil {
+		return err
+	}
+	bindings = make([]*fr.Element, 0, nb_zeta_evaluations+1)
+	for i := range proof.ZetaEvaluations {
+		bindings = append(bindings, &proof.ZetaEvaluations[i])
+	}
+	bindings = append(bindings, &proof.ZetaShiftedEvaluation)
+	lambda, err := deriveRandomness(fs, "lambda", bindings...)
+	if err != nil {
+		return err
+	}
+
+	// check the identity at ζ
+	if err := checkQuotient(proof, vk, publicWitness, alpha, beta, gamma, zeta); err != nil {
+		return err
+	}
+
+	// check that the opened values are consistent with the proof of proximity
+	// of the DEEP quotient
+	queries, err := fp.verify(fs, &proof.Fri)
+	if err != nil {
+		return err
+	}
+	var zetaShifted fr.Element
+	zetaShifted.Mul(&zeta, &vk.Generator)
+	depth := fp.logDomainSize() - 1
+	for i, q := range queries {
+		opening := &proof.Openings[i]
+		leaf, slot := q.position>>1, int(q.position&1)
+		if !verifyMerklePath(vk.Preprocessed, opening.Preprocessed.Values, opening.Preprocessed.MerkleProof, leaf, depth) ||
+			!verifyMerklePath(proof.LRO, opening.LRO.Values, opening.LRO.MerkleProof, leaf, depth) ||
+			!verifyMerklePath(proof.Z, opening.Z.Values, opening.Z.MerkleProof, leaf, depth) ||
+			!verifyMerklePath(proof.H, opening.H.Values, opening.H.MerkleProof, leaf, depth) {
+			return errOpeningMerklePath
+		}
+
+		values := make([]fr.Element, 0, nb_zeta_evaluations+1)
+		for _, t := range []*TreeOpening{&opening.Preprocessed, &opening.LRO, &opening.Z, &opening.H} {
+			k := len(t.Values) / 2
+			values = append(values, t.Values[slot*k:(slot+1)*k]...)
+		}
+		deep := evaluateDeepQuotient(values, proof.ZetaEvaluations, proof.ZetaShiftedEvaluation, q.point, zeta, zetaShifted, lambda)
+		if !deep.Equal(&q.eval) {
+			return errDeepQuotient
+		}
+	}
+
+	log.Debug().Dur("took", time.Since(start)).Msg("verifier done")
+	return nil
+}
+
+// checkProofShape checks that the sizes of the slices of the proof match vk.
+func checkProofShape(proof *Proof, fp friParams) error {
+	if len(proof.ZetaEvaluations) != nb_zeta_evaluations || len(proof.Openings) != fp.nbQueries {
+		return errInvalidProofShape
+	}
+	depth := fp.logDomainSize() - 1
+	for i := range proof.Openings {
+		for _, t := range []struct {
+			opening *TreeOpening
+			nbPolys int
+		}{
+			{&proof.Openings[i].Preprocessed, nbPreprocessed},
+			{&proof.Openings[i].LRO, 3},
+			{&proof.Openings[i].Z, 1},
+			{&proof.Openings[i].H, 4},
+		} {
+			if len(t.opening.Values) != 2*t.nbPolys || len(t.opening.MerkleProof) != depth {
+				return errInvalidProofShape
+			}
+		}
+	}
+	return nil
+}
+
+// checkQuotient checks that
+//
+//	gate(ζ) + α·perm(ζ) + α²·L₁(ζ)(Z(ζ)-1) = (h1(ζ) + ζⁿh2(ζ) + ζ²ⁿh3(ζ))(ζⁿ-1)
+//
+// from the claimed evaluations at ζ, see evaluateQuotient.
+func checkQuotient(proof *Proof, vk *VerifyingKey, publicWitness []fr.Element, alpha, beta, gamma, zeta fr.Element) error {
+	v := proof.ZetaEvaluations
+	var one, zetaPowerN, zh, t, den fr.Element
+	one.SetOne()
+	zetaPowerN.Exp(zeta, big.NewInt(int64(vk.Size)))
+	zh.Sub(&zetaPowerN, &one)
+
+	// L₁(ζ) = (ζⁿ-1)/(n(ζ-1)) and PI(ζ) = ∑ᵢ wᵢLᵢ(ζ) with Lᵢ(ζ) = ωⁱ(ζⁿ-1)/(n(ζ-ωⁱ))
+	var l1, pi, acc fr.Element
+	den.Sub(&zeta, &one).Inverse(&den)
+	l1.Mul(&zh, &den).Mul(&l1, &vk.SizeInv)
+	acc.SetOne()
+	for i := range publicWitness {
+		den.Sub(&zeta, &acc).Inverse(&den)
+		t.Mul(&acc, &den).Mul(&t, &publicWitness[i])
+		pi.Add(&pi, &t)
+		acc.Mul(&acc, &vk.Generator)
+	}
+	pi.Mul(&pi, &zh).Mul(&pi, &vk.SizeInv)
+
+	// gate
+	var gate fr.Element
+	gate.Mul(&v[id_Ql], &v[id_L])
+	t.Mul(&v[id_Qr], &v[id_R])
+	gate.Add(&gate, &t)
+	t.Mul(&v[id_Qm], &v[id_L]).Mul(&t, &v[id_R])
+	gate.Add(&gate, &t)
+	t.Mul(&v[id_Qo], &v[id_O])
+	gate.Add(&gate, &t).Add(&gate, &v[id_Qk]).Add(&gate, &pi)
+
+	// perm
+	var num, a, u fr.Element
+	num.Set(&v[id_Z])
+	den.Set(&proof.ZetaShiftedEvaluation)
+	u.SetOne()
+	for j := 0; j < 3; j++ {
+		a.Mul(&u, &zeta).Mul(&a, &beta).Add(&a, &v[id_L+j]).Add(&a, &gamma)
+		num.Mul(&num, &a)
+		a.Mul(&v[id_S1+j], &beta).Add(&a, &v[id_L+j]).Add(&a, &gamma)
+		den.Mul(&den, &a)
+		u.Mul(&u, &vk.CosetShift)
+	}
+	t.Sub(&den, &num)
+
+	var lhs, rhs fr.Element
+	a.Sub(&v[id_Z], &one).Mul(&a, &l1).Mul(&a, &alpha)
+	lhs.Add(&t, &a).Mul(&lhs, &alpha).Add(&lhs, &gate)
+
+	rhs.Mul(&v[id_H3], &zetaPowerN).Add(&rhs, &v[id_H2]).Mul(&rhs, &zetaPowerN).Add(&rhs, &v[id_H1]).Mul(&rhs, &zh)
+
+	if !lhs.Equal(&rhs) {
+		return errWrongClaimedQuotient
+	}
+	return nil
+}
+
+// evaluateDeepQuotient returns the evaluation of the DEEP quotient at x from
+// the values of the committed polynomials at x, the mask M being the last one,
+// see deepQuotient.
+func evaluateDeepQuotient(values, zetaEvaluations []fr.Element, zetaShiftedEvaluation, x, zeta, zetaShifted, lambda fr.Element) fr.Element {
+	var acc, t, d fr.Element
+	for j := nb_zeta_evaluations - 1; j >= 0; j-- {
+		t.Sub(&values[j], &zetaEvaluations[j])
+		acc.Mul(&acc, &lambda).Add(&acc, &t)
+	}
+	d.Sub(&x, &zeta).Inverse(&d)
+	acc.Mul(&acc, &d)
+
+	var res fr.Element
+	d.Sub(&x, &zetaShifted).Inverse(&d)
+	t.Sub(&values[id_Z], &zetaShiftedEvaluation).Mul(&t, &d)
+	d.Mul(&values[nb_zeta_evaluations], &lambda)
+	t.Add(&t, &d)
+	res.Exp(lambda, big.NewInt(int64(nb_zeta_evaluations))).Mul(&res, &t).Add(&res, &acc)
+	return res
+}
@@ -0,0 +1,250 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	curve "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"io"
+)
+
+// WriteRawTo writes binary encoding of Proof to w. The proof only contains
+// field elements, so it is the same as WriteTo.
+func (proof *Proof) WriteRawTo(w io.Writer) (int64, error) {
+	return proof.WriteTo(w)
+}
+
+// WriteTo writes binary encoding of Proof to w
+func (proof *Proof) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+
+	toEncode := []interface{}{
+		&proof.LRO,
+		&proof.Z,
+		&proof.H,
+		proof.ZetaEvaluations,
+		&proof.ZetaShiftedEvaluation,
+		uint64(len(proof.Openings)),
+	}
+	for i := range proof.Openings {
+		for _, t := range proof.Openings[i].trees() {
+			toEncode = append(toEncode, t.Values, t.MerkleProof)
+		}
+	}
+	toEncode = append(toEncode, proof.Fri.Commitments, uint64(len(proof.Fri.QueryRounds)))
+	for _, round := range proof.Fri.QueryRounds {
+		toEncode = append(toEncode, uint64(len(round)))
+		for i := range round {
+			toEncode = append(toEncode, &round[i].Evals[0], &round[i].Evals[1], round[i].MerkleProof)
+		}
+	}
+	toEncode = append(toEncode, proof.Fri.FinalPoly)
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// ReadFrom reads binary representation of Proof from r
+func (proof *Proof) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+
+	var nbOpenings uint64
+	toDecode := []interface{}{
+		&proof.LRO,
+		&proof.Z,
+		&proof.H,
+		&proof.ZetaEvaluations,
+		&proof.ZetaShiftedEvaluation,
+		&nbOpenings,
+	}
+	for _, v := range toDecode {
+		if err := dec.Decode(v); err != nil {
+			return dec.BytesRead(), err
+		}
+	}
+
+	proof.Openings = make([]QueryOpening, nbOpenings)
+	for i := range proof.Openings {
+		for _, t := range proof.Openings[i].trees() {
+			if err := decodeAll(dec, &t.Values, &t.MerkleProof); err != nil {
+				return dec.BytesRead(), err
+			}
+		}
+	}
+
+	var nbRounds uint64
+	if err := decodeAll(dec, &proof.Fri.Commitments, &nbRounds); err != nil {
+		return dec.BytesRead(), err
+	}
+	proof.Fri.QueryRounds = make([][]FriStep, nbRounds)
+	for i := range proof.Fri.QueryRounds {
+		var nbSteps uint64
+		if err := dec.Decode(&nbSteps); err != nil {
+			return dec.BytesRead(), err
+		}
+		round := make([]FriStep, nbSteps)
+		for j := range round {
+			if err := decodeAll(dec, &round[j].Evals[0], &round[j].Evals[1], &round[j].MerkleProof); err != nil {
+				return dec.BytesRead(), err
+			}
+		}
+		proof.Fri.QueryRounds[i] = round
+	}
+	if err := dec.Decode(&proof.Fri.FinalPoly); err != nil {
+		return dec.BytesRead(), err
+	}
+
+	return dec.BytesRead(), nil
+}
+
+// trees returns the openings of the trees in the order in which they are
+// serialized.
+func (o *QueryOpening) trees() []*TreeOpening {
+	return []*TreeOpening{&o.Preprocessed, &o.LRO, &o.Z, &o.H}
+}
+
+// WriteTo writes binary encoding of ProvingKey to w
+func (pk *ProvingKey) WriteTo(w io.Writer) (int64, error) {
+	n, err := pk.Vk.WriteTo(w)
+	if err != nil {
+		return n, err
+	}
+
+	enc := curve.NewEncoder(w)
+	permutation := make([]uint64, len(pk.Permutation))
+	for i := range pk.Permutation {
+		permutation[i] = uint64(pk.Permutation[i])
+	}
+	toEncode := []interface{}{
+		pk.Ql,
+		pk.Qr,
+		pk.Qm,
+		pk.Qo,
+		pk.Qk,
+		pk.S1,
+		pk.S2,
+		pk.S3,
+		permutation,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return n + enc.BytesWritten(), err
+		}
+	}
+
+	return n + enc.BytesWritten(), nil
+}
+
+// WriteRawTo writes binary encoding of ProvingKey to w. The proving key only
+// contains field elements, so it is the same as WriteTo.
+func (pk *ProvingKey) WriteRawTo(w io.Writer) (int64, error) {
+	return pk.WriteTo(w)
+}
+
+// ReadFrom reads from binary representation in r into ProvingKey
+func (pk *ProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	pk.Vk = &VerifyingKey{}
+	n, err := pk.Vk.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+
+	dec := curve.NewDecoder(r)
+	var permutation []uint64
+	if err := decodeAll(dec, &pk.Ql, &pk.Qr, &pk.Qm, &pk.Qo, &pk.Qk, &pk.S1, &pk.S2, &pk.S3, &permutation); err != nil {
+		return n + dec.BytesRead(), err
+	}
+	pk.Permutation = make([]int64, len(permutation))
+	for i := range permutation {
+		pk.Permutation[i] = int64(permutation[i])
+	}
+
+	return n + dec.BytesRead(), nil
+}
+
+// UnsafeReadFrom reads from binary representation in r into ProvingKey.
+// Current implementation is a passthrough to ReadFrom
+func (pk *ProvingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return pk.ReadFrom(r)
+}
+
+// WriteTo writes binary encoding of VerifyingKey to w
+func (vk *VerifyingKey) WriteTo(w io.Writer) (int64, error) {
+	enc := curve.NewEncoder(w)
+
+	toEncode := []interface{}{
+		vk.Size,
+		&vk.SizeInv,
+		&vk.Generator,
+		vk.NbPublicVariables,
+		&vk.CosetShift,
+		vk.RateBits,
+		vk.NbQueries,
+		&vk.Preprocessed,
+	}
+
+	for _, v := range toEncode {
+		if err := enc.Encode(v); err != nil {
+			return enc.BytesWritten(), err
+		}
+	}
+
+	return enc.BytesWritten(), nil
+}
+
+// WriteRawTo writes binary encoding of VerifyingKey to w. The verifying key
+// only contains field elements, so it is the same as WriteTo.
+func (vk *VerifyingKey) WriteRawTo(w io.Writer) (int64, error) {
+	return vk.WriteTo(w)
+}
+
+// ReadFrom reads from binary representation in r into VerifyingKey
+func (vk *VerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	dec := curve.NewDecoder(r)
+	err := decodeAll(dec,
+		&vk.Size,
+		&vk.SizeInv,
+		&vk.Generator,
+		&vk.NbPublicVariables,
+		&vk.CosetShift,
+		&vk.RateBits,
+		&vk.NbQueries,
+		&vk.Preprocessed,
+	)
+	return dec.BytesRead(), err
+}
+
+// UnsafeReadFrom reads from binary representation in r into VerifyingKey.
+// Current implementation is a passthrough to ReadFrom
+func (vk *VerifyingKey) UnsafeReadFrom(r io.Reader) (int64, error) {
+	return vk.ReadFrom(r)
+}
+
+// decodeAll decodes the values in order.
+func decodeAll(dec *curve.Decoder, values ...interface{}) error {
+	for _, v := range values {
+		if err := dec.Decode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
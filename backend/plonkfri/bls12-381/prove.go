@@ -0,0 +1,565 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr/mimc"
+	fiatshamir "github.com/consensys/gnark-crypto/fiat-shamir"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/witness"
+	cs "github.com/consensys/gnark/constraint/bls12-381"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/internal/utils"
+	"github.com/consensys/gnark/logger"
+)
+
+// indices of the polynomials opened at ζ in Proof.ZetaEvaluations
+const (
+	id_Ql int = iota
+	id_Qr
+	id_Qm
+	id_Qo
+	id_Qk
+	id_S1
+	id_S2
+	id_S3
+	id_L
+	id_R
+	id_O
+	id_Z
+	id_H1
+	id_H2
+	id_H3
+	nb_zeta_evaluations
+)
+
+// TreeOpening is the opening of a leaf of one of the Merkle trees committing to
+// the polynomials of the proof.
+type TreeOpening struct {
+	// Values are the evaluations of the committed polynomials at the points x
+	// and -x of the leaf, see commitEvaluations.
+	Values []fr.Element
+	// MerkleProof stores the siblings from the leaf to the root.
+	MerkleProof []fr.Element
+}
+
+// QueryOpening stores the openings of the committed polynomials at a query of
+// FRI.
+type QueryOpening struct {
+	Preprocessed, LRO, Z, H TreeOpening
+}
+
+// Proof is a PLONK proof where the polynomials are committed with Merkle trees
+// of their evaluations, and opened at ζ using FRI on the DEEP quotient
+//
+//	Q(X) = ∑ⱼ λʲ(Pⱼ(X)-Pⱼ(ζ))/(X-ζ) + λ¹⁵(Z(X)-Z(ωζ))/(X-ωζ) + λ¹⁶M(X)
+//
+// where the Pⱼ are listed in ZetaEvaluations and M is a random polynomial
+// masking the layers of FRI.
+type Proof struct {
+
+	// Merkle roots of the evaluations of l, r, o, of the permutation
+	// polynomial Z and of h1, h2, h3, M such that h = h1 + Xⁿh2 + X²ⁿh3 is the
+	// quotient polynomial
+	LRO, Z, H fr.Element
+
+	// ZetaEvaluations are the evaluations at ζ of ql, qr, qm, qo, qk, s1, s2,
+	// s3, l, r, o, Z, h1, h2, h3
+	ZetaEvaluations []fr.Element
+
+	// ZetaShiftedEvaluation is the evaluation of Z at ωζ
+	ZetaShiftedEvaluation fr.Element
+
+	// Openings of the committed polynomials at the queries of FRI
+	Openings []QueryOpening
+
+	// Fri is the proof of proximity of the DEEP quotient
+	Fri FriProof
+}
+
+// Prove from the public data.
+//
+// The polynomials l, r, o and Z are blinded with random multiples of Xⁿ-1 and
+// the parts of the quotient with random polynomials cancelling out in
+// h1 + Xⁿh2 + X²ⁿh3, see blind and splitQuotient. The challenges are derived
+// with MiMC so that the proofs can be verified in a circuit, hence the options
+// ChallengeHash, HashToFieldFn and KZGFoldingHash are ignored.
+func Prove(spr *cs.SparseR1CS, pk *ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (*Proof, error) {
+
+	log := logger.Logger().With().
+		Str("curve", spr.CurveID().String()).
+		Int("nbConstraints", spr.GetNbConstraints()).
+		Str("backend", "plonk_fri").Logger()
+
+	// parse the options
+	opt, err := backend.NewProverConfig(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("get prover options: %w", err)
+	}
+	opt.SolverOpts = append(opt.SolverOpts, solver.WithContext(opt.Ctx))
+	// the weights are rough estimates of the relative costs of the phases
+	progress := opt.NewProgressReporter(
+		backend.PhaseWeight{Phase: backend.PhaseSolve, Weight: 2},
+		backend.PhaseWeight{Phase: backend.PhaseCommitments, Weight: 2},
+		backend.PhaseWeight{Phase: backend.PhaseQuotient, Weight: 3},
+		backend.PhaseWeight{Phase: backend.PhaseOpening, Weight: 3},
+	)
+
+	start := time.Now()
+
+	fw, ok := fullWitness.Vector().(fr.Vector)
+	if !ok {
+		return nil, witness.ErrInvalidWitness
+	}
+	publicInputs := fw[:len(spr.Public)]
+
+	vk := pk.Vk
+	domain := fft.NewDomain(vk.Size)
+	nbBlindings := vk.nbBlindings()
+	fp := vk.friParams()
+	proof := &Proof{}
+	fs := newTranscript(fp)
+
+	// solve constraints
+	progress.Start(backend.PhaseSolve, 1)
+	_solution, err := spr.Solve(fullWitness, opt.SolverOpts...)
+	if err != nil {
+		if errCtx := opt.CheckContext(backend.PhaseSolve); errCtx != nil {
+			return nil, errCtx
+		}
+		return nil, err
+	}
+	progress.Step(backend.PhaseSolve)
+	solution := _solution.(*cs.SparseR1CSSolution)
+
+	// commit to l, r, o
+	if err := opt.CheckContext(backend.PhaseCommitments); err != nil {
+		return nil, err
+	}
+	progress.Start(backend.PhaseCommitments, 2)
+	lro := [3][]fr.Element{solution.L, solution.R, solution.O}
+	var lroCanonical, lroEvals [3][]fr.Element
+	for i := range lro {
+		if lroCanonical[i], err = blind(canonical(lro[i], domain), nbBlindings); err != nil {
+			return nil, err
+		}
+		lroEvals[i] = lde(lroCanonical[i], fp.domain)
+	}
+	lroTree := commitEvaluations(lroEvals[:]...)
+	proof.LRO = lroTree.root()
+	progress.Step(backend.PhaseCommitments)
+
+	// derive gamma from the preprocessed polynomials, the public inputs and l, r, o
+	bindings := make([]*fr.Element, 0, len(publicInputs)+2)
+	bindings = append(bindings, &vk.Preprocessed)
+	for i := range publicInputs {
+		bindings = append(bindings, &publicInputs[i])
+	}
+	bindings = append(bindings, &proof.LRO)
+	gamma, err := deriveRandomness(fs, "gamma", bindings...)
+	if err != nil {
+		return nil, err
+	}
+	beta, err := deriveRandomness(fs, "beta")
+	if err != nil {
+		return nil, err
+	}
+
+	// compute and commit to the accumulating ratio for the copy constraint
+	z := buildRatioCopyConstraint(lro, pk, domain, beta, gamma)
+	zCanonical, err := blind(canonical(z, domain), nbBlindings)
+	if err != nil {
+		return nil, err
+	}
+	zEvals := lde(zCanonical, fp.domain)
+	zTree := commitEvaluations(zEvals)
+	proof.Z = zTree.root()
+	progress.Step(backend.PhaseCommitments)
+
+	alpha, err := deriveRandomness(fs, "alpha", &proof.Z)
+	if err != nil {
+		return nil, err
+	}
+
+	// compute and commit to the quotient
+	if err := opt.CheckContext(backend.PhaseQuotient); err != nil {
+		return nil, err
+	}
+	progress.Start(backend.PhaseQuotient, 2)
+	preprocessedEvals := make([][]fr.Element, nbPreprocessed)
+	for i, p := range pk.preprocessed() {
+		preprocessedEvals[i] = lde(p, fp.domain)
+	}
+	h := evaluateQuotient(domain, fp.domain, publicInputs, preprocessedEvals, lroEvals, zEvals, zCanonical, alpha, beta, gamma)
+	progress.Step(backend.PhaseQuotient)
+	hCanonical, err := splitQuotient(h, int(vk.Size), nbBlindings)
+	if err != nil {
+		return nil, err
+	}
+	var hEvals [3][]fr.Element
+	for i := range hCanonical {
+		hEvals[i] = lde(hCanonical[i], fp.domain)
+	}
+	mask, err := randomPolynomial(int(vk.DegreeBound()))
+	if err != nil {
+		return nil, err
+	}
+	maskEvals := lde(mask, fp.domain)
+	hTree := commitEvaluations(hEvals[0], hEvals[1], hEvals[2], maskEvals)
+	proof.H = hTree.root()
+	progress.Step(backend.PhaseQuotient)
+
+	zeta, err := deriveRandomness(fs, "zeta", &proof.H)
+	if err != nil {
+		return nil, err
+	}
+
+	// open the polynomials at ζ and ωζ
+	if err := opt.CheckContext(backend.PhaseOpening); err != nil {
+		return nil, err
+	}
+	progress.Start(backend.PhaseOpening, 2)
+	polynomials := make([][]fr.Element, 0, nb_zeta_evaluations)
+	for _, p := range pk.preprocessed() {
+		polynomials = append(polynomials, p)
+	}
+	polynomials = append(polynomials, lroCanonical[:]...)
+	polynomials = append(polynomials, zCanonical)
+	polynomials = append(polynomials, hCanonical[:]...)
+	proof.ZetaEvaluations = make([]fr.Element, nb_zeta_evaluations)
+	bindings = make([]*fr.Element, 0, nb_zeta_evaluations+1)
+	for i := range polynomials {
+		proof.ZetaEvaluations[i] = evaluate(polynomials[i], zeta)
+		bindings = append(bindings, &proof.ZetaEvaluations[i])
+	}
+	var zetaShifted fr.Element
+	zetaShifted.Mul(&zeta, &vk.Generator)
+	proof.ZetaShiftedEvaluation = evaluate(zCanonical, zetaShifted)
+	bindings = append(bindings, &proof.ZetaShiftedEvaluation)
+	lambda, err := deriveRandomness(fs, "lambda", bindings...)
+	if err != nil {
+		return nil, err
+	}
+
+	// compute the DEEP quotient on the evaluation domain and prove its proximity
+	evals := make([][]fr.Element, 0, nb_zeta_evaluations)
+	evals = append(evals, preprocessedEvals...)
+	evals = append(evals, lroEvals[:]...)
+	evals = append(evals, zEvals)
+	evals = append(evals, hEvals[:]...)
+	q := deepQuotient(fp.domain, evals, maskEvals, proof.ZetaEvaluations, proof.ZetaShiftedEvaluation, zeta, zetaShifted, lambda)
+	progress.Step(backend.PhaseOpening)
+
+	var positions []uint64
+	if proof.Fri, positions, err = fp.buildProof(fs, q); err != nil {
+		return nil, err
+	}
+
+	// open the committed polynomials at the queries
+	preprocessedTree := commitEvaluations(preprocessedEvals...)
+	proof.Openings = make([]QueryOpening, len(positions))
+	for i, position := range positions {
+		leaf := int(position >> 1)
+		proof.Openings[i] = QueryOpening{
+			Preprocessed: openTree(preprocessedTree, leaf, preprocessedEvals...),
+			LRO:          openTree(lroTree, leaf, lroEvals[:]...),
+			Z:            openTree(zTree, leaf, zEvals),
+			H:            openTree(hTree, leaf, hEvals[0], hEvals[1], hEvals[2], maskEvals),
+		}
+	}
+	progress.Step(backend.PhaseOpening)
+
+	log.Debug().Dur("took", time.Since(start)).Msg("prover done")
+	return proof, nil
+}
+
+// newTranscript returns the Fiat-Shamir transcript of the protocol, with MiMC.
+func newTranscript(fp friParams) *fiatshamir.Transcript {
+	ids := append([]string{"gamma", "beta", "alpha", "zeta", "lambda"}, fp.challengeIDs()...)
+	return fiatshamir.NewTranscript(mimc.NewMiMC(), ids...)
+}
+
+// canonical returns the coefficients of the polynomial whose evaluations on
+// domain are given in regular order.
+func canonical(evals []fr.Element, domain *fft.Domain) []fr.Element {
+	res := make([]fr.Element, domain.Cardinality)
+	copy(res, evals)
+	domain.FFTInverse(res, fft.DIF)
+	fft.BitReverse(res)
+	return res
+}
+
+// randomPolynomial returns a polynomial with size random coefficients.
+func randomPolynomial(size int) ([]fr.Element, error) {
+	res := make([]fr.Element, size)
+	for i := range res {
+		if _, err := res[i].SetRandom(); err != nil {
+			return nil, fmt.Errorf("random coefficient: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// blind returns the coefficients of p + b(Xⁿ-1), where p is given by its n
+// coefficients and b is a random polynomial with nbBlindings coefficients.
+// The blinded polynomial has the same evaluations as p on the domain of size
+// n.
+func blind(p []fr.Element, nbBlindings int) ([]fr.Element, error) {
+	b, err := randomPolynomial(nbBlindings)
+	if err != nil {
+		return nil, err
+	}
+	n := len(p)
+	res := make([]fr.Element, n+nbBlindings)
+	copy(res, p)
+	for i := range b {
+		res[i].Sub(&res[i], &b[i])
+		res[n+i].Add(&res[n+i], &b[i])
+	}
+	return res, nil
+}
+
+// splitQuotient returns the parts
+//
+//	h1 = h[:n] + Xⁿb1, h2 = h[n:2n] - b1 + Xⁿb2, h3 = h[2n:] - b2
+//
+// of the quotient h, where b1 and b2 are random polynomials with nbBlindings
+// coefficients, so that h = h1 + Xⁿh2 + X²ⁿh3.
+func splitQuotient(h []fr.Element, n, nbBlindings int) ([3][]fr.Element, error) {
+	var res [3][]fr.Element
+	var b [2][]fr.Element
+	for i := range b {
+		var err error
+		if b[i], err = randomPolynomial(nbBlindings); err != nil {
+			return res, err
+		}
+	}
+	res[0] = make([]fr.Element, n+nbBlindings)
+	res[1] = make([]fr.Element, n+nbBlindings)
+	size := len(h) - 2*n
+	if size < nbBlindings {
+		size = nbBlindings
+	}
+	res[2] = make([]fr.Element, size)
+	copy(res[0], h[:n])
+	copy(res[1], h[n:2*n])
+	copy(res[2], h[2*n:])
+	for i := 0; i < nbBlindings; i++ {
+		res[0][n+i].Add(&res[0][n+i], &b[0][i])
+		res[1][i].Sub(&res[1][i], &b[0][i])
+		res[1][n+i].Add(&res[1][n+i], &b[1][i])
+		res[2][i].Sub(&res[2][i], &b[1][i])
+	}
+	return res, nil
+}
+
+// evaluate returns p(x) where p is given in canonical basis.
+func evaluate(p []fr.Element, x fr.Element) fr.Element {
+	var res fr.Element
+	for i := len(p) - 1; i >= 0; i-- {
+		res.Mul(&res, &x).Add(&res, &p[i])
+	}
+	return res
+}
+
+// openTree returns the opening of the leaf of t, built over evals.
+func openTree(t merkleTree, leaf int, evals ...[]fr.Element) TreeOpening {
+	return TreeOpening{
+		Values:      leafValues(leaf, evals...),
+		MerkleProof: t.path(leaf),
+	}
+}
+
+// buildRatioCopyConstraint returns the evaluations on domain of the
+// accumulating ratio Z of the copy constraint, such that Z(1) = 1 and
+//
+//	Z(ωx)(l(x)+β·s1(x)+γ)(r(x)+β·s2(x)+γ)(o(x)+β·s3(x)+γ) =
+//	Z(x)(l(x)+β·x+γ)(r(x)+β·u·x+γ)(o(x)+β·u²·x+γ)
+//
+// on domain.
+func buildRatioCopyConstraint(lro [3][]fr.Element, pk *ProvingKey, domain *fft.Domain, beta, gamma fr.Element) []fr.Element {
+	n := int(domain.Cardinality)
+	support := getSupportPermutation(domain)
+	num := make([]fr.Element, n)
+	den := make([]fr.Element, n)
+	utils.Parallelize(n, func(start, end int) {
+		var a, b fr.Element
+		for i := start; i < end; i++ {
+			num[i].SetOne()
+			den[i].SetOne()
+			for j := 0; j < 3; j++ {
+				a.Mul(&beta, &support[j*n+i]).Add(&a, &lro[j][i]).Add(&a, &gamma)
+				num[i].Mul(&num[i], &a)
+				b.Mul(&beta, &support[pk.Permutation[j*n+i]]).Add(&b, &lro[j][i]).Add(&b, &gamma)
+				den[i].Mul(&den[i], &b)
+			}
+		}
+	})
+	den = fr.BatchInvert(den)
+
+	z := make([]fr.Element, n)
+	z[0].SetOne()
+	for i := 0; i < n-1; i++ {
+		z[i+1].Mul(&z[i], &num[i]).Mul(&z[i+1], &den[i])
+	}
+	return z
+}
+
+// evaluationPoints returns the points of the evaluation domain in bit-reversed
+// order.
+func evaluationPoints(domain *fft.Domain) []fr.Element {
+	res := make([]fr.Element, domain.Cardinality)
+	fft.BuildExpTable(domain.Generator, res)
+	for i := range res {
+		res[i].Mul(&res[i], &domain.FrMultiplicativeGen)
+	}
+	fft.BitReverse(res)
+	return res
+}
+
+// evaluateQuotient returns the coefficients of the quotient
+//
+//	h = (gate + α·perm + α²·L₁(Z-1))/(Xⁿ-1)
+//
+// where gate = ql·l + qr·r + qm·l·r + qo·o + qk + PI and perm is the identity of
+// the copy constraint, see buildRatioCopyConstraint. The polynomials are given
+// by their evaluations on the evaluation domain, in bit-reversed order.
+func evaluateQuotient(domain, domainBig *fft.Domain, publicInputs []fr.Element, preprocessed [][]fr.Element, lro [3][]fr.Element, z, zCanonical []fr.Element, alpha, beta, gamma fr.Element) []fr.Element {
+	n := domain.Cardinality
+	nbPoints := int(domainBig.Cardinality)
+
+	// PI = ∑ᵢ wᵢLᵢ
+	pi := make([]fr.Element, n)
+	copy(pi, publicInputs)
+	pi = lde(canonical(pi, domain), domainBig)
+
+	// Z(ωX)
+	zShifted := make([]fr.Element, len(zCanonical))
+	var acc fr.Element
+	acc.SetOne()
+	for i := range zShifted {
+		zShifted[i].Mul(&zCanonical[i], &acc)
+		acc.Mul(&acc, &domain.Generator)
+	}
+	zShifted = lde(zShifted, domainBig)
+
+	// Xⁿ-1 and L₁ = (Xⁿ-1)/(n(X-1))
+	x := evaluationPoints(domainBig)
+	zh := make([]fr.Element, nbPoints)
+	l1 := make([]fr.Element, nbPoints)
+	var cardinality fr.Element
+	cardinality.SetUint64(n)
+	utils.Parallelize(nbPoints, func(start, end int) {
+		var one fr.Element
+		one.SetOne()
+		for i := start; i < end; i++ {
+			zh[i].Exp(x[i], new(big.Int).SetUint64(n)).Sub(&zh[i], &one)
+			l1[i].Sub(&x[i], &one).Mul(&l1[i], &cardinality)
+		}
+	})
+	l1 = fr.BatchInvert(l1)
+	zhInv := fr.BatchInvert(zh)
+
+	var u [3]fr.Element
+	u[0].SetOne()
+	u[1].Set(&domain.FrMultiplicativeGen)
+	u[2].Square(&domain.FrMultiplicativeGen)
+
+	h := make([]fr.Element, nbPoints)
+	utils.Parallelize(nbPoints, func(start, end int) {
+		var gate, num, den, a, t, one fr.Element
+		one.SetOne()
+		l, r, o := lro[0], lro[1], lro[2]
+		for i := start; i < end; i++ {
+			gate.Mul(&preprocessed[id_Ql][i], &l[i])
+			t.Mul(&preprocessed[id_Qr][i], &r[i])
+			gate.Add(&gate, &t)
+			t.Mul(&preprocessed[id_Qm][i], &l[i]).Mul(&t, &r[i])
+			gate.Add(&gate, &t)
+			t.Mul(&preprocessed[id_Qo][i], &o[i])
+			gate.Add(&gate, &t).
+				Add(&gate, &preprocessed[id_Qk][i]).
+				Add(&gate, &pi[i])
+
+			num.Set(&z[i])
+			den.Set(&zShifted[i])
+			for j := 0; j < 3; j++ {
+				a.Mul(&u[j], &x[i]).Mul(&a, &beta).Add(&a, &lro[j][i]).Add(&a, &gamma)
+				num.Mul(&num, &a)
+				a.Mul(&preprocessed[id_S1+j][i], &beta).Add(&a, &lro[j][i]).Add(&a, &gamma)
+				den.Mul(&den, &a)
+			}
+			t.Sub(&den, &num)
+
+			// α²·L₁(Z-1)
+			a.Sub(&z[i], &one).Mul(&a, &zh[i]).Mul(&a, &l1[i]).Mul(&a, &alpha)
+
+			h[i].Add(&t, &a).Mul(&h[i], &alpha).Add(&h[i], &gate).Mul(&h[i], &zhInv[i])
+		}
+	})
+
+	// l, r, o and Z are of degree less than m = len(zCanonical), so h is of
+	// degree less than 4m-3-n and the evaluation domain is of size at least 4m
+	domainBig.FFTInverse(h, fft.DIT, fft.OnCoset())
+	return h[:4*len(zCanonical)-3-int(n)]
+}
+
+// deepQuotient returns the evaluations on the evaluation domain, in
+// bit-reversed order, of
+//
+//	Q(X) = ∑ⱼ λʲ(Pⱼ(X)-Pⱼ(ζ))/(X-ζ) + λ¹⁵(Z(X)-Z(ωζ))/(X-ωζ) + λ¹⁶M(X)
+//
+// from those of the polynomials Pⱼ and of the mask M.
+func deepQuotient(domain *fft.Domain, evals [][]fr.Element, mask []fr.Element, zetaEvaluations []fr.Element, zetaShiftedEvaluation, zeta, zetaShifted, lambda fr.Element) []fr.Element {
+	nbPoints := int(domain.Cardinality)
+	x := evaluationPoints(domain)
+	d := make([]fr.Element, nbPoints)
+	ds := make([]fr.Element, nbPoints)
+	for i := range x {
+		d[i].Sub(&x[i], &zeta)
+		ds[i].Sub(&x[i], &zetaShifted)
+	}
+	d = fr.BatchInvert(d)
+	ds = fr.BatchInvert(ds)
+	var lambdaZ, lambdaM fr.Element
+	lambdaZ.Exp(lambda, big.NewInt(int64(nb_zeta_evaluations)))
+	lambdaM.Mul(&lambdaZ, &lambda)
+
+	res := make([]fr.Element, nbPoints)
+	utils.Parallelize(nbPoints, func(start, end int) {
+		var t, acc fr.Element
+		for i := start; i < end; i++ {
+			acc.SetZero()
+			for j := len(evals) - 1; j >= 0; j-- {
+				t.Sub(&evals[j][i], &zetaEvaluations[j])
+				acc.Mul(&acc, &lambda).Add(&acc, &t)
+			}
+			acc.Mul(&acc, &d[i])
+			t.Sub(&evals[id_Z][i], &zetaShiftedEvaluation).Mul(&t, &ds[i])
+			res[i].Mul(&lambdaZ, &t).Add(&res[i], &acc)
+			t.Mul(&lambdaM, &mask[i])
+			res[i].Add(&res[i], &t)
+		}
+	})
+	return res
+}
@@ -0,0 +1,314 @@
+// Copyright 2020 ConsenSys Software Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by gnark DO NOT EDIT
+
+package plonkfri
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr"
+	"github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+	"github.com/consensys/gnark/constraint"
+	cs "github.com/consensys/gnark/constraint/bls24-315"
+)
+
+var (
+	errCircuitTooSmall       = errors.New("the circuit must have at least two constraints and public inputs")
+	errInvalidRate           = errors.New("the rate must be of at least 2 bits")
+	errInvalidNbQueries      = errors.New("the number of queries must be positive")
+	errCommitmentUnsupported = errors.New("commitments are not supported by PLONK with FRI")
+	errCustomGateUnsupported = errors.New("custom gates are not supported by PLONK with FRI")
+	errLookupUnsupported     = errors.New("fixed table lookups are not supported by PLONK with FRI")
+)
+
+// VerifyingKey stores the data needed to verify a proof:
+// * the size of the circuit and the number of public inputs
+// * the parameters of FRI
+// * the Merkle root of the evaluations of ql, qr, qm, qo, qk, s1, s2, s3
+type VerifyingKey struct {
+	// Size circuit, that is the closest power of 2 bounding above
+	// number of constraints+number of public inputs
+	Size              uint64
+	SizeInv           fr.Element
+	Generator         fr.Element
+	NbPublicVariables uint64
+
+	// CosetShift generator of the coset on the small domain. It is also the
+	// shift of the evaluation domain of FRI.
+	CosetShift fr.Element
+
+	// RateBits is the logarithm of the blowup factor of FRI, the evaluation
+	// domain is of size DegreeBound()·2^RateBits.
+	RateBits uint64
+
+	// NbQueries is the number of queries of FRI.
+	NbQueries uint64
+
+	// Preprocessed is the Merkle root of the evaluations of ql, qr, qm, qo, qk
+	// (without the public inputs), s1, s2, s3 on the evaluation domain.
+	Preprocessed fr.Element
+}
+
+// ProvingKey stores the data needed to generate a proof:
+// * ql, prepended with as many ones as they are public inputs
+// * qr, qm, qo prepended with as many zeroes as there are public inputs.
+// * qk, prepended with as many zeroes as public inputs, to be completed by the prover
+// with the list of public inputs.
+// * s1, s2, s3
+// * the copy constraint permutation
+//
+// The polynomials are stored in canonical basis.
+type ProvingKey struct {
+	// Verifying Key is embedded into the proving key (needed by Prove)
+	Vk *VerifyingKey
+
+	Ql, Qr, Qm, Qo, Qk []fr.Element
+	S1, S2, S3         []fr.Element
+
+	// Permutation position -> permuted position (position in [0,3*Size-1])
+	Permutation []int64
+}
+
+// Setup sets proving and verifying keys. The evaluation domain of FRI is of
+// size 2^rateBits times the degree bound of the committed polynomials, see
+// VerifyingKey.DegreeBound, and nbQueries queries are made. No SRS is needed.
+func Setup(spr *cs.SparseR1CS, rateBits, nbQueries uint64) (*ProvingKey, *VerifyingKey, error) {
+	if rateBits < 2 {
+		return nil, nil, errInvalidRate
+	}
+	if nbQueries == 0 {
+		return nil, nil, errInvalidNbQueries
+	}
+	if len(spr.CommitmentInfo.(constraint.PlonkCommitments)) != 0 {
+		return nil, nil, errCommitmentUnsupported
+	}
+	if len(spr.GetCustomGates()) != 0 {
+		return nil, nil, errCustomGateUnsupported
+	}
+	if len(spr.GetFixedTables()) != 0 {
+		return nil, nil, errLookupUnsupported
+	}
+
+	var pk ProvingKey
+	var vk VerifyingKey
+	pk.Vk = &vk
+
+	// fft domains
+	sizeSystem := uint64(spr.GetNbConstraints() + len(spr.Public)) // len(spr.Public) is for the placeholder constraints
+	if sizeSystem < 2 {
+		return nil, nil, errCircuitTooSmall
+	}
+	domain := fft.NewDomain(ecc.NextPowerOfTwo(sizeSystem))
+
+	vk.Size = domain.Cardinality
+	vk.SizeInv.SetUint64(vk.Size).Inverse(&vk.SizeInv)
+	vk.Generator.Set(&domain.Generator)
+	vk.NbPublicVariables = uint64(len(spr.Public))
+	vk.CosetShift.Set(&domain.FrMultiplicativeGen)
+	vk.RateBits = rateBits
+	vk.NbQueries = nbQueries
+	domainBig := fft.NewDomain(vk.DegreeBound() << rateBits)
+
+	// public polynomials corresponding to constraints: [ placeholders | constraints | assertions ]
+	n := int(domain.Cardinality)
+	pk.Ql = make([]fr.Element, n)
+	pk.Qr = make([]fr.Element, n)
+	pk.Qm = make([]fr.Element, n)
+	pk.Qo = make([]fr.Element, n)
+	pk.Qk = make([]fr.Element, n)
+	for i := 0; i < len(spr.Public); i++ { // placeholders (-PUB_INPUT_i + qk_i = 0)
+		pk.Ql[i].SetOne().Neg(&pk.Ql[i]) // qk_i → to be completed by the prover
+	}
+	offset := len(spr.Public)
+	j := 0
+	it := spr.GetSparseR1CIterator()
+	for c := it.Next(); c != nil; c = it.Next() {
+		pk.Ql[offset+j].Set(&spr.Coefficients[c.QL])
+		pk.Qr[offset+j].Set(&spr.Coefficients[c.QR])
+		pk.Qm[offset+j].Set(&spr.Coefficients[c.QM])
+		pk.Qo[offset+j].Set(&spr.Coefficients[c.QO])
+		pk.Qk[offset+j].Set(&spr.Coefficients[c.QC])
+		j++
+	}
+
+	// build the permutation and the polynomials s1, s2, s3 encoding it
+	nbVariables := spr.NbInternalVariables + len(spr.Public) + len(spr.Secret)
+	pk.Permutation = buildPermutation(spr, n, nbVariables)
+	support := getSupportPermutation(domain)
+	pk.S1 = make([]fr.Element, n)
+	pk.S2 = make([]fr.Element, n)
+	pk.S3 = make([]fr.Element, n)
+	for i := 0; i < n; i++ {
+		pk.S1[i].Set(&support[pk.Permutation[i]])
+		pk.S2[i].Set(&support[pk.Permutation[n+i]])
+		pk.S3[i].Set(&support[pk.Permutation[2*n+i]])
+	}
+
+	// canonical basis
+	for _, p := range pk.preprocessed() {
+		domain.FFTInverse(p, fft.DIF)
+		fft.BitReverse(p)
+	}
+
+	// commit to the evaluations on the evaluation domain
+	evals := make([][]fr.Element, nbPreprocessed)
+	for i, p := range pk.preprocessed() {
+		evals[i] = lde(p, domainBig)
+	}
+	vk.Preprocessed = commitEvaluations(evals...).root()
+
+	return &pk, &vk, nil
+}
+
+// nbPreprocessed is the number of preprocessed polynomials.
+const nbPreprocessed = 8
+
+// preprocessed returns ql, qr, qm, qo, qk, s1, s2, s3 in the order in which
+// they are committed.
+func (pk *ProvingKey) preprocessed() [nbPreprocessed][]fr.Element {
+	return [nbPreprocessed][]fr.Element{pk.Ql, pk.Qr, pk.Qm, pk.Qo, pk.Qk, pk.S1, pk.S2, pk.S3}
+}
+
+// nbBlindings returns the number of random coefficients added to l, r, o, Z and
+// to the parts of the quotient. It is the number of evaluations of Z that a
+// proof reveals: at x, -x, ωx and -ωx for every query (the last two through the
+// quotient), at ζ and at ωζ.
+func (vk *VerifyingKey) nbBlindings() int {
+	return 4*int(vk.NbQueries) + 2
+}
+
+// DegreeBound returns the power of 2 bounding the degrees of the committed
+// polynomials. The blinded l, r, o and Z are of degree less than
+// Size+nbBlindings, so the third part of the quotient is of degree less than
+// Size+4·nbBlindings. The random polynomial masking the DEEP quotient is of
+// degree less than DegreeBound, which must exceed the number of its
+// evaluations revealed by FRI: two per query and per layer, and the final
+// constant.
+func (vk *VerifyingKey) DegreeBound() uint64 {
+	res := ecc.NextPowerOfTwo(vk.Size + 4*uint64(vk.nbBlindings()))
+	for res <= 2*vk.NbQueries*uint64(bits.TrailingZeros64(res))+1 {
+		res <<= 1
+	}
+	return res
+}
+
+// friParams returns the parameters of FRI for the commitment of polynomials of
+// degree less than DegreeBound().
+func (vk *VerifyingKey) friParams(opts ...fft.DomainOption) friParams {
+	degreeBound := vk.DegreeBound()
+	return friParams{
+		logDegree: bits.TrailingZeros64(degreeBound),
+		nbQueries: int(vk.NbQueries),
+		domain:    fft.NewDomain(degreeBound<<vk.RateBits, opts...),
+	}
+}
+
+// buildPermutation builds the Permutation associated with a circuit.
+//
+// The permutation s is composed of cycles of maximum length such that
+//
+//	s. (l∥r∥o) = (l∥r∥o)
+//
+// , where l∥r∥o is the concatenation of the indices of l, r, o in
+// ql.l+qr.r+qm.l.r+qo.O+k = 0.
+//
+// The permutation is encoded as a slice s of size 3*size(l), where the
+// i-th entry of l∥r∥o is sent to the s[i]-th entry, so it acts on a tab
+// like this: for i in tab: tab[i] = tab[permutation[i]]
+func buildPermutation(spr *cs.SparseR1CS, sizeSolution, nbVariables int) []int64 {
+
+	sizePermutation := 3 * sizeSolution
+
+	// init permutation
+	permutation := make([]int64, sizePermutation)
+	for i := 0; i < len(permutation); i++ {
+		permutation[i] = -1
+	}
+
+	// init LRO position -> variable_ID
+	lro := make([]int, sizePermutation) // position -> variable_ID
+	for i := 0; i < len(spr.Public); i++ {
+		lro[i] = i // IDs of LRO associated to placeholders (only L needs to be taken care of)
+	}
+
+	offset := len(spr.Public)
+
+	j := 0
+	it := spr.GetSparseR1CIterator()
+	for c := it.Next(); c != nil; c = it.Next() {
+		lro[offset+j] = int(c.XA)
+		lro[sizeSolution+offset+j] = int(c.XB)
+		lro[2*sizeSolution+offset+j] = int(c.XC)
+
+		j++
+	}
+
+	// init cycle:
+	// map ID -> last position the ID was seen
+	cycle := make([]int64, nbVariables)
+	for i := 0; i < len(cycle); i++ {
+		cycle[i] = -1
+	}
+
+	for i := 0; i < len(lro); i++ {
+		if cycle[lro[i]] != -1 {
+			// if != -1, it means we already encountered this value
+			// so we need to set the corresponding permutation index.
+			permutation[i] = cycle[lro[i]]
+		}
+		cycle[lro[i]] = int64(i)
+	}
+
+	// complete the Permutation by filling the first IDs encountered
+	for i := 0; i < sizePermutation; i++ {
+		if permutation[i] == -1 {
+			permutation[i] = cycle[lro[i]]
+		}
+	}
+
+	return permutation
+}
+
+// getSupportPermutation returns the support on which the permutation acts, it is
+// <g> || u<g> || u^{2}<g>
+func getSupportPermutation(domain *fft.Domain) []fr.Element {
+
+	res := make([]fr.Element, 3*domain.Cardinality)
+
+	res[0].SetOne()
+	res[domain.Cardinality].Set(&domain.FrMultiplicativeGen)
+	res[2*domain.Cardinality].Square(&domain.FrMultiplicativeGen)
+
+	for i := uint64(1); i < domain.Cardinality; i++ {
+		res[i].Mul(&res[i-1], &domain.Generator)
+		res[domain.Cardinality+i].Mul(&res[domain.Cardinality+i-1], &domain.Generator)
+		res[2*domain.Cardinality+i].Mul(&res[2*domain.Cardinality+i-1], &domain.Generator)
+	}
+
+	return res
+}
+
+// NbPublicWitness returns the expected public witness size (number of field elements)
+func (vk *VerifyingKey) NbPublicWitness() int {
+	return int(vk.NbPublicVariables)
+}
+
+// VerifyingKey returns pk.Vk
+func (pk *ProvingKey) VerifyingKey() interface{} {
+	return pk.Vk
+}
@@ -0,0 +1,198 @@
+package plonkfri_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/plonkfri"
+	plonkfri_bn254 "github.com/consensys/gnark/backend/plonkfri/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/stretchr/testify/require"
+)
+
+type cubicCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubicCircuit) Define(api frontend.API) error {
+	x := c.X
+	for i := 0; i < 5; i++ {
+		x3 := api.Mul(x, x, x)
+		x = api.Add(x3, x, 5)
+	}
+	api.AssertIsEqual(c.Y, x)
+	return nil
+}
+
+// solveCubic returns an assignment of cubicCircuit for the given x.
+func solveCubic(curve ecc.ID, x int64) *cubicCircuit {
+	modulus := curve.ScalarField()
+	y := big.NewInt(x)
+	for i := 0; i < 5; i++ {
+		y3 := new(big.Int).Exp(y, big.NewInt(3), modulus)
+		y.Add(y3, y).Add(y, big.NewInt(5)).Mod(y, modulus)
+	}
+	return &cubicCircuit{X: x, Y: y}
+}
+
+func TestProver(t *testing.T) {
+	for _, curve := range gnark.Curves() {
+		if curve == ecc.BW6_756 {
+			continue
+		}
+		t.Run(curve.String(), func(t *testing.T) {
+			assert := require.New(t)
+
+			ccs, err := frontend.Compile(curve.ScalarField(), scs.NewBuilder, &cubicCircuit{})
+			assert.NoError(err)
+
+			assignment := solveCubic(curve, 3)
+			fullWitness, err := frontend.NewWitness(assignment, curve.ScalarField())
+			assert.NoError(err)
+			publicWitness, err := fullWitness.Public()
+			assert.NoError(err)
+
+			pk, vk, err := plonkfri.Setup(ccs, plonkfri.WithNbQueries(8))
+			assert.NoError(err)
+
+			// write the PK to ensure it is not mutated
+			var b1, b2 bytes.Buffer
+			_, err = pk.WriteTo(&b1)
+			assert.NoError(err)
+
+			proof, err := plonkfri.Prove(ccs, pk, fullWitness)
+			assert.NoError(err)
+
+			_, err = pk.WriteTo(&b2)
+			assert.NoError(err)
+			assert.True(bytes.Equal(b1.Bytes(), b2.Bytes()), "plonkfri prover mutated the proving key")
+
+			assert.NoError(plonkfri.Verify(proof, vk, publicWitness))
+
+			// testing with full witness should output a clear error.
+			err = plonkfri.Verify(proof, vk, fullWitness)
+			assert.Error(err)
+			assert.Contains(err.Error(), "witness length is invalid")
+
+			// wrong public input
+			wrong, err := frontend.NewWitness(&cubicCircuit{X: 0, Y: 1}, curve.ScalarField(), frontend.PublicOnly())
+			assert.NoError(err)
+			assert.Error(plonkfri.Verify(proof, vk, wrong))
+
+			// serialization round trip
+			var buf bytes.Buffer
+			_, err = proof.WriteTo(&buf)
+			assert.NoError(err)
+			proof2 := plonkfri.NewProof(curve)
+			_, err = proof2.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.Equal(proof, proof2)
+
+			buf.Reset()
+			_, err = vk.WriteTo(&buf)
+			assert.NoError(err)
+			vk2 := plonkfri.NewVerifyingKey(curve)
+			_, err = vk2.ReadFrom(&buf)
+			assert.NoError(err)
+			assert.Equal(vk, vk2)
+
+			pk2 := plonkfri.NewProvingKey(curve)
+			_, err = pk2.ReadFrom(&b1)
+			assert.NoError(err)
+			assert.Equal(pk, pk2)
+
+			assert.NoError(plonkfri.Verify(proof2, vk2, publicWitness))
+		})
+	}
+}
+
+func TestTamperedProof(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	fullWitness, err := frontend.NewWitness(solveCubic(ecc.BN254, 3), ecc.BN254.ScalarField())
+	assert.NoError(err)
+	publicWitness, err := fullWitness.Public()
+	assert.NoError(err)
+
+	pk, vk, err := plonkfri.Setup(ccs, plonkfri.WithNbQueries(8))
+	assert.NoError(err)
+
+	tamper := []func(p *plonkfri_bn254.Proof){
+		func(p *plonkfri_bn254.Proof) { p.ZetaEvaluations[0].SetOne() },
+		func(p *plonkfri_bn254.Proof) { p.ZetaShiftedEvaluation.SetOne() },
+		func(p *plonkfri_bn254.Proof) { p.Openings[0].LRO.Values[0].SetOne() },
+		func(p *plonkfri_bn254.Proof) { p.Openings[1].H.MerkleProof[0].SetOne() },
+		func(p *plonkfri_bn254.Proof) { p.Fri.QueryRounds[0][1].Evals[0].SetOne() },
+		func(p *plonkfri_bn254.Proof) { p.Fri.FinalPoly[0].SetOne() },
+		func(p *plonkfri_bn254.Proof) { p.Openings = p.Openings[1:] },
+	}
+	for _, f := range tamper {
+		proof, err := plonkfri.Prove(ccs, pk, fullWitness)
+		assert.NoError(err)
+		assert.NoError(plonkfri.Verify(proof, vk, publicWitness))
+		f(proof.(*plonkfri_bn254.Proof))
+		assert.Error(plonkfri.Verify(proof, vk, publicWitness))
+	}
+}
+
+func TestBlinding(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	fullWitness, err := frontend.NewWitness(solveCubic(ecc.BN254, 3), ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	pk, _, err := plonkfri.Setup(ccs, plonkfri.WithNbQueries(8))
+	assert.NoError(err)
+
+	// the committed polynomials are blinded, so two proofs of the same witness
+	// commit to different evaluations
+	p1, err := plonkfri.Prove(ccs, pk, fullWitness)
+	assert.NoError(err)
+	p2, err := plonkfri.Prove(ccs, pk, fullWitness)
+	assert.NoError(err)
+	proof1, proof2 := p1.(*plonkfri_bn254.Proof), p2.(*plonkfri_bn254.Proof)
+	assert.NotEqual(proof1.LRO, proof2.LRO)
+	assert.NotEqual(proof1.Z, proof2.Z)
+	assert.NotEqual(proof1.H, proof2.H)
+}
+
+func TestSetupUnsupported(t *testing.T) {
+	assert := require.New(t)
+
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &cubicCircuit{})
+	assert.NoError(err)
+	_, _, err = plonkfri.Setup(ccs, plonkfri.WithRateBits(1))
+	assert.Error(err)
+
+	ccs, err = frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &lookupCircuit{}, frontend.WithNativeLookups())
+	assert.NoError(err)
+	_, _, err = plonkfri.Setup(ccs)
+	assert.Error(err)
+}
+
+type lookupCircuit struct {
+	X frontend.Variable
+}
+
+func (c *lookupCircuit) Define(api frontend.API) error {
+	lookuper, ok := api.Compiler().(frontend.FixedTableLookuper)
+	if !ok {
+		return errors.New("fixed tables not supported")
+	}
+	id, err := lookuper.NewFixedTable([]*big.Int{big.NewInt(1), big.NewInt(2)})
+	if err != nil {
+		return err
+	}
+	lookuper.AssertIsInFixedTable(id, c.X)
+	return nil
+}
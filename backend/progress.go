@@ -0,0 +1,167 @@
+package backend
+
+import (
+	"sync"
+	"time"
+)
+
+// ProgressPhase identifies a phase of the prover in a [ProgressEvent].
+type ProgressPhase string
+
+const (
+	// PhaseSolve is the solving of the constraint system.
+	PhaseSolve ProgressPhase = "solve"
+	// PhaseCommitments is the computation of the commitments to the solution
+	// (BSB22 commitments for Groth16, commitments to the wire and permutation
+	// polynomials for PLONK).
+	PhaseCommitments ProgressPhase = "commitments"
+	// PhaseFFT is the computation of the quotient polynomial H in Groth16, by
+	// batches of FFTs.
+	PhaseFFT ProgressPhase = "fft"
+	// PhaseMSM is the computation of the proof elements in Groth16, by batches
+	// of multi-scalar multiplications.
+	PhaseMSM ProgressPhase = "msm"
+	// PhaseQuotient is the computation and commitment of the quotient
+	// polynomial in PLONK. The steps are the cosets on which the constraints
+	// are evaluated.
+	PhaseQuotient ProgressPhase = "quotient"
+	// PhaseOpening is the computation of the opening proofs of the committed
+	// polynomials, whatever the commitment scheme: the KZG opening proofs in
+	// PLONK, or the FRI proof of proximity in PLONK with FRI.
+	PhaseOpening ProgressPhase = "opening"
+	// PhaseCrossTerm is the computation and commitment of the cross term of a
+	// Nova folding step.
+	PhaseCrossTerm ProgressPhase = "cross term"
+	// PhaseProve is reported by a [ProverCanceledError] when the prover
+	// notices the cancellation after its phases, while waiting for them.
+	PhaseProve ProgressPhase = "prove"
+)
+
+// ProgressEvent describes the progress of the prover. It is given to the
+// handler set with [WithProgressHandler] when a phase starts and every time a
+// step of a phase is done.
+type ProgressEvent struct {
+	// Phase is the phase the event refers to.
+	Phase ProgressPhase
+	// Step is the number of steps of the phase which are done, out of
+	// NbSteps. It is 0 when the phase starts and NbSteps when it is done.
+	Step, NbSteps int
+	// Elapsed is the time elapsed since the start of the proof.
+	Elapsed time.Duration
+	// PhaseElapsed is the time elapsed since the start of the phase.
+	PhaseElapsed time.Duration
+	// Percent is the estimated overall progress of the proof, between 0 and
+	// 100. It is computed from the fixed relative cost of the phases given by
+	// the prover, so it is only an estimate of the remaining time.
+	Percent float64
+}
+
+// ProgressHandler receives the progress events of the prover. It is called
+// from several goroutines, but never concurrently, so that the events are
+// received in order. It should return quickly, as the prover waits for it.
+type ProgressHandler func(ProgressEvent)
+
+// WithProgressHandler sets a handler which receives the progress events of the
+// prover, see [ProgressEvent]. If not set then no events are emitted.
+func WithProgressHandler(h ProgressHandler) ProverOption {
+	return func(pc *ProverConfig) error {
+		pc.ProgressHandler = h
+		return nil
+	}
+}
+
+// PhaseWeight is the estimated relative cost of a phase of the prover, used for
+// computing [ProgressEvent.Percent].
+type PhaseWeight struct {
+	Phase  ProgressPhase
+	Weight float64
+}
+
+// ProgressReporter is used by the provers for reporting their progress to the
+// handler set with [WithProgressHandler]. Its methods are safe for concurrent
+// use and do nothing if no handler is set.
+type ProgressReporter struct {
+	handler ProgressHandler
+	start   time.Time
+
+	mu          sync.Mutex
+	weights     map[ProgressPhase]float64
+	totalWeight float64
+	phases      map[ProgressPhase]*phaseProgress
+}
+
+type phaseProgress struct {
+	start         time.Time
+	step, nbSteps int
+}
+
+// NewProgressReporter returns a new reporter for the phases of a prover with
+// their relative costs. The elapsed times of the events are measured from the
+// call to this method.
+func (pc *ProverConfig) NewProgressReporter(phases ...PhaseWeight) *ProgressReporter {
+	r := &ProgressReporter{
+		handler: pc.ProgressHandler,
+		start:   time.Now(),
+		weights: make(map[ProgressPhase]float64, len(phases)),
+		phases:  make(map[ProgressPhase]*phaseProgress, len(phases)),
+	}
+	for _, p := range phases {
+		r.weights[p.Phase] = p.Weight
+		r.totalWeight += p.Weight
+	}
+	return r
+}
+
+// Start reports that the phase starts and will be done in nbSteps steps.
+func (r *ProgressReporter) Start(phase ProgressPhase, nbSteps int) {
+	if r == nil || r.handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.phases[phase] = &phaseProgress{start: time.Now(), nbSteps: nbSteps}
+	r.handler(r.event(phase))
+}
+
+// Step reports that one more step of the phase is done. It starts the phase
+// with one step if [ProgressReporter.Start] was not called.
+func (r *ProgressReporter) Step(phase ProgressPhase) {
+	if r == nil || r.handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.phases[phase]
+	if !ok {
+		p = &phaseProgress{start: time.Now(), nbSteps: 1}
+		r.phases[phase] = p
+	}
+	if p.step < p.nbSteps {
+		p.step++
+	}
+	r.handler(r.event(phase))
+}
+
+// event returns the event for the current state of the phase. The caller must
+// hold the lock.
+func (r *ProgressReporter) event(phase ProgressPhase) ProgressEvent {
+	now := time.Now()
+	p := r.phases[phase]
+	ev := ProgressEvent{
+		Phase:        phase,
+		Step:         p.step,
+		NbSteps:      p.nbSteps,
+		Elapsed:      now.Sub(r.start),
+		PhaseElapsed: now.Sub(p.start),
+	}
+	if r.totalWeight > 0 {
+		var done float64
+		for ph, pp := range r.phases {
+			if pp.nbSteps > 0 {
+				done += r.weights[ph] * float64(pp.step) / float64(pp.nbSteps)
+			}
+		}
+		ev.Percent = 100 * done / r.totalWeight
+	}
+	return ev
+}
@@ -0,0 +1,77 @@
+// Package solidity provides the options of the Solidity verifiers exported by
+// the backends (see the ExportSolidity methods of the verifying keys), and the
+// prover and verifier options producing proofs these verifiers accept.
+package solidity
+
+import (
+	"github.com/consensys/gnark/backend"
+	"golang.org/x/crypto/sha3"
+)
+
+// ExportOption defines option for altering the Solidity verifier exported by
+// ExportSolidity. See the descriptions of functions returning instances of
+// this type for implemented options.
+type ExportOption func(*ExportConfig) error
+
+// ExportConfig is the configuration of the exported verifier with the options
+// applied.
+type ExportConfig struct {
+	// CompressedProofs adds to the verifier the entry points taking proofs
+	// with point-compressed G1 and G2 elements, decompressed on chain. It is
+	// set by default, and only the Groth16 verifier on BN254 has such entry
+	// points.
+	CompressedProofs bool
+}
+
+// NewExportConfig returns a default ExportConfig with given export options
+// opts applied.
+func NewExportConfig(opts ...ExportOption) (ExportConfig, error) {
+	cfg := ExportConfig{CompressedProofs: true}
+	for _, option := range opts {
+		if err := option(&cfg); err != nil {
+			return ExportConfig{}, err
+		}
+	}
+	return cfg, nil
+}
+
+// WithoutCompressedProofs removes the functions compressProof and
+// verifyCompressedProof from the exported verifier, which only takes the proofs
+// encoded with MarshalSolidity then. The compressed proofs halve the calldata,
+// at the cost of the on-chain decompression (a few modular exponentiations per
+// point), so removing them only saves the size of the contract.
+//
+// It only alters the Groth16 verifier on BN254, the other verifiers have no
+// entry points taking compressed proofs.
+func WithoutCompressedProofs() ExportOption {
+	return func(cfg *ExportConfig) error {
+		cfg.CompressedProofs = false
+		return nil
+	}
+}
+
+// WithProverTargetSolidityVerifier returns the prover option making the proofs
+// of the backend bid verifiable by the exported Solidity verifier.
+//
+// For Groth16, the commitments are hashed to the field with keccak256, which
+// is cheaper on chain than the default hash based on RFC 9380. PlonK proofs are
+// verifiable with the default options.
+func WithProverTargetSolidityVerifier(bid backend.ID) backend.ProverOption {
+	return func(cfg *backend.ProverConfig) error {
+		if bid == backend.GROTH16 {
+			cfg.HashToFieldFn = sha3.NewLegacyKeccak256()
+		}
+		return nil
+	}
+}
+
+// WithVerifierTargetSolidityVerifier returns the verifier option matching
+// [WithProverTargetSolidityVerifier], to verify the proofs off chain.
+func WithVerifierTargetSolidityVerifier(bid backend.ID) backend.VerifierOption {
+	return func(cfg *backend.VerifierConfig) error {
+		if bid == backend.GROTH16 {
+			cfg.HashToFieldFn = sha3.NewLegacyKeccak256()
+		}
+		return nil
+	}
+}
@@ -377,6 +377,8 @@ func getTagSet() cbor.TagSet {
 	addType(reflect.TypeOf(constraint.BlueprintLookupHint{}))
 	addType(reflect.TypeOf(constraint.Groth16Commitments{}))
 	addType(reflect.TypeOf(constraint.PlonkCommitments{}))
+	addType(reflect.TypeOf(constraint.BlueprintSparseR1CCustomGate{}))
+	addType(reflect.TypeOf((*constraint.BlueprintSparseR1CLookup)(nil)).Elem())
 
 	return ts
 }
@@ -0,0 +1,310 @@
+package constraint
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// GateWire identifies a wire read by a custom gate: the left, right or output
+// wire of the row the gate is applied to, or of the row that follows it.
+type GateWire uint8
+
+const (
+	GateL GateWire = iota
+	GateR
+	GateO
+	GateNextL
+	GateNextR
+	GateNextO
+	nbGateWires
+)
+
+// MaxCustomGateDegree is the maximal degree of an identity of a custom gate.
+// Multiplied by its selector, such an identity has the same degree as the
+// copy constraint, so the quotient of the PlonK prover still fits in three
+// parts.
+const MaxCustomGateDegree = 3
+
+// GateTerm is the monomial Coeff⋅∏ᵢ Wires[i] of an identity of a custom gate.
+// A wire appears in Wires as many times as its power in the monomial.
+type GateTerm struct {
+	Coeff int64
+	Wires []GateWire
+}
+
+// CustomGate is a user defined PlonK gate. The PlonK backends add a selector
+// polynomial per custom gate; on every row where the selector is set, each
+// identity ∑ⱼ Termsⱼ == 0 must hold.
+//
+// For example, the Poseidon S-box y = x⁵ on a row (x, x², y) is
+//
+//	CustomGate{
+//		Name: "sbox",
+//		Identities: [][]GateTerm{
+//			{{Coeff: 1, Wires: []GateWire{GateR}}, {Coeff: -1, Wires: []GateWire{GateL, GateL}}},
+//			{{Coeff: 1, Wires: []GateWire{GateO}}, {Coeff: -1, Wires: []GateWire{GateR, GateR, GateL}}},
+//		},
+//	}
+type CustomGate struct {
+	Name       string
+	Identities [][]GateTerm
+}
+
+// Degree returns the maximal degree of the identities of the gate.
+func (g *CustomGate) Degree() int {
+	d := 0
+	for _, identity := range g.Identities {
+		for _, t := range identity {
+			if len(t.Wires) > d {
+				d = len(t.Wires)
+			}
+		}
+	}
+	return d
+}
+
+// SpansTwoRows returns true if the gate reads the wires of the next row.
+func (g *CustomGate) SpansTwoRows() bool {
+	for _, identity := range g.Identities {
+		for _, t := range identity {
+			for _, w := range t.Wires {
+				if w >= GateNextL {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// NbWires returns the number of wires the gate reads: 3 if it only reads the
+// row it is applied to, 6 otherwise.
+func (g *CustomGate) NbWires() int {
+	if g.SpansTwoRows() {
+		return 6
+	}
+	return 3
+}
+
+// Check returns an error if the gate is malformed or its degree exceeds
+// MaxCustomGateDegree.
+func (g *CustomGate) Check() error {
+	if len(g.Identities) == 0 {
+		return fmt.Errorf("custom gate %q has no identity", g.Name)
+	}
+	for i, identity := range g.Identities {
+		if len(identity) == 0 {
+			return fmt.Errorf("custom gate %q: identity %d is empty", g.Name, i)
+		}
+		for _, t := range identity {
+			for _, w := range t.Wires {
+				if w >= nbGateWires {
+					return fmt.Errorf("custom gate %q: invalid wire %d", g.Name, w)
+				}
+			}
+		}
+	}
+	if d := g.Degree(); d > MaxCustomGateDegree {
+		return fmt.Errorf("custom gate %q has degree %d, at most %d is supported", g.Name, d, MaxCustomGateDegree)
+	}
+	return nil
+}
+
+// EncodeCustomGates encodes gates in a slice of []uint64, one per gate, to be
+// serialized along with the keys of the PlonK backends.
+func EncodeCustomGates(gates []CustomGate) [][]uint64 {
+	res := make([][]uint64, len(gates))
+	for i := range gates {
+		enc := []uint64{uint64(len(gates[i].Name))}
+		for j := 0; j < len(gates[i].Name); j++ {
+			enc = append(enc, uint64(gates[i].Name[j]))
+		}
+		enc = append(enc, uint64(len(gates[i].Identities)))
+		for _, identity := range gates[i].Identities {
+			enc = append(enc, uint64(len(identity)))
+			for _, t := range identity {
+				enc = append(enc, uint64(t.Coeff), uint64(len(t.Wires)))
+				for _, w := range t.Wires {
+					enc = append(enc, uint64(w))
+				}
+			}
+		}
+		res[i] = enc
+	}
+	return res
+}
+
+// DecodeCustomGates decodes gates encoded with EncodeCustomGates.
+func DecodeCustomGates(enc [][]uint64) ([]CustomGate, error) {
+	if len(enc) == 0 {
+		return nil, nil
+	}
+	errInvalid := errors.New("invalid custom gate encoding")
+	res := make([]CustomGate, len(enc))
+	for i, e := range enc {
+		read := func() (uint64, error) {
+			if len(e) == 0 {
+				return 0, errInvalid
+			}
+			v := e[0]
+			e = e[1:]
+			return v, nil
+		}
+		readLen := func() (int, error) {
+			v, err := read()
+			if err != nil {
+				return 0, err
+			}
+			if v > uint64(len(e)) || v > math.MaxInt32 {
+				return 0, errInvalid
+			}
+			return int(v), nil
+		}
+		nameLen, err := readLen()
+		if err != nil {
+			return nil, err
+		}
+		name := make([]byte, nameLen)
+		for j := range name {
+			c, err := read()
+			if err != nil {
+				return nil, err
+			}
+			if c > math.MaxUint8 {
+				return nil, errInvalid
+			}
+			name[j] = byte(c)
+		}
+		res[i].Name = string(name)
+		nbIdentities, err := readLen()
+		if err != nil {
+			return nil, err
+		}
+		res[i].Identities = make([][]GateTerm, nbIdentities)
+		for j := range res[i].Identities {
+			nbTerms, err := readLen()
+			if err != nil {
+				return nil, err
+			}
+			res[i].Identities[j] = make([]GateTerm, nbTerms)
+			for k := range res[i].Identities[j] {
+				t := &res[i].Identities[j][k]
+				coeff, err := read()
+				if err != nil {
+					return nil, err
+				}
+				t.Coeff = int64(coeff)
+				nbWires, err := readLen()
+				if err != nil {
+					return nil, err
+				}
+				t.Wires = make([]GateWire, nbWires)
+				for l := range t.Wires {
+					w, err := read()
+					if err != nil {
+						return nil, err
+					}
+					t.Wires[l] = GateWire(w)
+				}
+			}
+		}
+		if len(e) != 0 {
+			return nil, errInvalid
+		}
+		if err := res[i].Check(); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// BlueprintSparseR1CCustomGate implements Blueprint, BlueprintSolvable and BlueprintSparseR1C.
+// Encodes the row (xa, xb, xc) on which the custom gate Gate is applied; the
+// calldata also holds the wires of the next row when the gate spans two rows.
+//
+// The solver doesn't solve custom gates, it only checks that the identities
+// hold; all the wires must be solved by previous instructions.
+type BlueprintSparseR1CCustomGate struct {
+	// Index of the gate in the custom gates of the constraint system
+	Index uint32
+	Gate  CustomGate
+}
+
+func (b *BlueprintSparseR1CCustomGate) CalldataSize() int {
+	return b.Gate.NbWires()
+}
+func (b *BlueprintSparseR1CCustomGate) NbConstraints() int {
+	return 1
+}
+func (b *BlueprintSparseR1CCustomGate) NbOutputs(inst Instruction) int {
+	return 0
+}
+
+func (b *BlueprintSparseR1CCustomGate) UpdateInstructionTree(inst Instruction, tree InstructionTree) Level {
+	return updateInstructionTree(inst.Calldata, tree)
+}
+
+func (b *BlueprintSparseR1CCustomGate) CompressSparseR1C(c *SparseR1C, to *[]uint32) {
+	if b.Gate.SpansTwoRows() {
+		panic("custom gate spanning two rows can't be compressed from a single SparseR1C")
+	}
+	*to = append(*to, c.XA, c.XB, c.XC)
+}
+
+func (b *BlueprintSparseR1CCustomGate) DecompressSparseR1C(c *SparseR1C, inst Instruction) {
+	c.Clear()
+	c.XA = inst.Calldata[0]
+	c.XB = inst.Calldata[1]
+	c.XC = inst.Calldata[2]
+	c.CustomGate = b.Index + 1
+}
+
+func (b *BlueprintSparseR1CCustomGate) Solve(s Solver, inst Instruction) error {
+	var values [nbGateWires]Element
+	for i, wireID := range inst.Calldata {
+		if !s.IsSolved(wireID) {
+			return fmt.Errorf("custom gate %q: wire %d is not solved", b.Gate.Name, wireID)
+		}
+		values[i] = s.GetValue(CoeffIdOne, wireID)
+	}
+	for i, identity := range b.Gate.Identities {
+		var res Element
+		for _, t := range identity {
+			m := s.FromInterface(t.Coeff)
+			for _, w := range t.Wires {
+				m = s.Mul(m, values[w])
+			}
+			res = s.Add(res, m)
+		}
+		if !res.IsZero() {
+			return fmt.Errorf("custom gate %q: identity %d doesn't hold", b.Gate.Name, i)
+		}
+	}
+	return nil
+}
+
+// AddCustomGate checks the gate and registers it in the constraint system.
+// It returns the ID of the blueprint to use to add instructions applying the
+// gate, with calldata the wires (xa, xb, xc) of the row, followed by the wires
+// of the next row if the gate spans two rows. In the latter case, the next
+// instruction must add a row made of these wires.
+func (system *System) AddCustomGate(gate CustomGate) (BlueprintID, error) {
+	if err := gate.Check(); err != nil {
+		return 0, err
+	}
+	index := uint32(len(system.GetCustomGates()))
+	return system.AddBlueprint(&BlueprintSparseR1CCustomGate{Index: index, Gate: gate}), nil
+}
+
+// GetCustomGates returns the custom gates registered in the constraint system,
+// ordered by index.
+func (system *System) GetCustomGates() []CustomGate {
+	var gates []CustomGate
+	for _, b := range system.Blueprints {
+		if bc, ok := b.(*BlueprintSparseR1CCustomGate); ok {
+			gates = append(gates, bc.Gate)
+		}
+	}
+	return gates
+}
@@ -0,0 +1,88 @@
+package constraint
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// BlueprintSparseR1CLookup implements Blueprint, BlueprintSolvable and BlueprintSparseR1C.
+// It holds a fixed table declared at compile time, and encodes the rows
+// (xa, 0, 0) asserting that xa is an entry of the table.
+//
+// The solver doesn't solve lookups, it only checks that xa is an entry of the
+// table; xa must be solved by previous instructions.
+type BlueprintSparseR1CLookup struct {
+	// Index of the table in the fixed tables of the constraint system
+	Table   uint32
+	Entries []Element
+
+	once    sync.Once
+	entries map[Element]struct{}
+}
+
+func (b *BlueprintSparseR1CLookup) CalldataSize() int {
+	return 1
+}
+func (b *BlueprintSparseR1CLookup) NbConstraints() int {
+	return 1
+}
+func (b *BlueprintSparseR1CLookup) NbOutputs(inst Instruction) int {
+	return 0
+}
+
+func (b *BlueprintSparseR1CLookup) UpdateInstructionTree(inst Instruction, tree InstructionTree) Level {
+	return updateInstructionTree(inst.Calldata, tree)
+}
+
+func (b *BlueprintSparseR1CLookup) CompressSparseR1C(c *SparseR1C, to *[]uint32) {
+	*to = append(*to, c.XA)
+}
+
+func (b *BlueprintSparseR1CLookup) DecompressSparseR1C(c *SparseR1C, inst Instruction) {
+	c.Clear()
+	c.XA = inst.Calldata[0]
+	c.XB = inst.Calldata[0]
+	c.XC = inst.Calldata[0]
+	c.Lookup = b.Table + 1
+}
+
+func (b *BlueprintSparseR1CLookup) Solve(s Solver, inst Instruction) error {
+	if !s.IsSolved(inst.Calldata[0]) {
+		return fmt.Errorf("lookup in table %d: wire %d is not solved", b.Table, inst.Calldata[0])
+	}
+	b.once.Do(func() {
+		b.entries = make(map[Element]struct{}, len(b.Entries))
+		for _, e := range b.Entries {
+			b.entries[e] = struct{}{}
+		}
+	})
+	v := s.GetValue(CoeffIdOne, inst.Calldata[0])
+	if _, ok := b.entries[v]; !ok {
+		return fmt.Errorf("lookup: %s is not an entry of table %d", s.String(v), b.Table)
+	}
+	return nil
+}
+
+// AddFixedTable registers a fixed table in the constraint system. It returns
+// the ID of the blueprint to use to add instructions asserting that a wire is
+// an entry of the table, with calldata the wire.
+func (system *System) AddFixedTable(entries []Element) (BlueprintID, error) {
+	if len(entries) == 0 {
+		return 0, errors.New("fixed table is empty")
+	}
+	table := uint32(len(system.GetFixedTables()))
+	return system.AddBlueprint(&BlueprintSparseR1CLookup{Table: table, Entries: entries}), nil
+}
+
+// GetFixedTables returns the entries of the fixed tables registered in the
+// constraint system, ordered by index.
+func (system *System) GetFixedTables() [][]Element {
+	var tables [][]Element
+	for _, b := range system.Blueprints {
+		if bl, ok := b.(*BlueprintSparseR1CLookup); ok {
+			tables = append(tables, bl.Entries)
+		}
+	}
+	return tables
+}
@@ -14,6 +14,8 @@
 
 package constraint
 
+import "strconv"
+
 type SparseR1CS interface {
 	ConstraintSystem
 
@@ -27,6 +29,20 @@ type SparseR1CS interface {
 
 	// GetSparseR1CIterator returns an SparseR1CIterator to iterate on the SparseR1C constraints of the system.
 	GetSparseR1CIterator() SparseR1CIterator
+
+	// AddCustomGate registers a custom gate in the constraint system
+	// and returns the ID of the blueprint applying it.
+	AddCustomGate(gate CustomGate) (BlueprintID, error)
+
+	// GetCustomGates returns the custom gates of the constraint system.
+	GetCustomGates() []CustomGate
+
+	// AddFixedTable registers a fixed table in the constraint system
+	// and returns the ID of the blueprint asserting membership in it.
+	AddFixedTable(entries []Element) (BlueprintID, error)
+
+	// GetFixedTables returns the entries of the fixed tables of the constraint system.
+	GetFixedTables() [][]Element
 }
 
 // SparseR1CIterator facilitates iterating through SparseR1C constraints.
@@ -140,10 +156,18 @@ const (
 
 // SparseR1C represent a PlonK-ish constraint
 // qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC -committed?*Bsb22Commitments-commitment?*commitmentValue == 0
+//
+// If CustomGate is not zero, the selectors are zero and the constraint applies
+// the custom gate of index CustomGate-1 on the row (xa, xb, xc).
+//
+// If Lookup is not zero, the selectors are zero and the constraint asserts
+// that xa is an entry of the fixed table of index Lookup-1.
 type SparseR1C struct {
 	XA, XB, XC         uint32
 	QL, QR, QO, QM, QC uint32
 	Commitment         CommitmentConstraint
+	CustomGate         uint32
+	Lookup             uint32
 }
 
 func (c *SparseR1C) Clear() {
@@ -153,6 +177,24 @@ func (c *SparseR1C) Clear() {
 // String formats the constraint as qL⋅xa + qR⋅xb + qO⋅xc + qM⋅(xaxb) + qC == 0
 func (c *SparseR1C) String(r Resolver) string {
 	sbb := NewStringBuilder(r)
+	if c.CustomGate != 0 {
+		sbb.WriteString("customGate")
+		sbb.WriteString(strconv.Itoa(int(c.CustomGate - 1)))
+		sbb.WriteByte('(')
+		sbb.WriteString(sbb.VariableToString(int(c.XA)))
+		sbb.WriteString(", ")
+		sbb.WriteString(sbb.VariableToString(int(c.XB)))
+		sbb.WriteString(", ")
+		sbb.WriteString(sbb.VariableToString(int(c.XC)))
+		sbb.WriteString(")")
+		return sbb.String()
+	}
+	if c.Lookup != 0 {
+		sbb.WriteString(sbb.VariableToString(int(c.XA)))
+		sbb.WriteString(" ∈ table")
+		sbb.WriteString(strconv.Itoa(int(c.Lookup - 1)))
+		return sbb.String()
+	}
 	sbb.WriteTerm(Term{CID: c.QL, VID: c.XA})
 	sbb.WriteString(" + ")
 	sbb.WriteTerm(Term{CID: c.QR, VID: c.XB})
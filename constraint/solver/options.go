@@ -1,6 +1,7 @@
 package solver
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 
@@ -18,6 +19,7 @@ type Config struct {
 	HintFunctions map[HintID]Hint // defaults to all built-in hint functions
 	Logger        zerolog.Logger  // defaults to gnark.Logger
 	NbTasks       int             // defaults to runtime.NumCPU()
+	Ctx           context.Context // defaults to context.Background()
 }
 
 // WithHints is a solver option that specifies additional hint functions to be used
@@ -79,12 +81,26 @@ func WithNbTasks(nbTasks int) Option {
 	}
 }
 
+// WithContext sets the context of the solver. The solver checks the context
+// between the levels of the constraint system and stops with the error of the
+// context when it is done.
+func WithContext(ctx context.Context) Option {
+	return func(opt *Config) error {
+		if ctx == nil {
+			return fmt.Errorf("nil context")
+		}
+		opt.Ctx = ctx
+		return nil
+	}
+}
+
 // NewConfig returns a default SolverConfig with given prover options opts applied.
 func NewConfig(opts ...Option) (Config, error) {
 	log := logger.Logger()
 	opt := Config{Logger: log}
 	opt.HintFunctions = cloneHintRegistry()
 	opt.NbTasks = runtime.NumCPU()
+	opt.Ctx = context.Background()
 	for _, option := range opts {
 		if err := option(&opt); err != nil {
 			return Config{}, err
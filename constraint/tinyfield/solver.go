@@ -17,6 +17,7 @@
 package cs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/consensys/gnark-crypto/ecc"
@@ -49,6 +50,7 @@ type solver struct {
 	// used to out api.Println
 	logger  zerolog.Logger
 	nbTasks int
+	ctx     context.Context
 
 	a, b, c fr.Vector // R1CS solver will compute the a,b,c matrices
 
@@ -97,6 +99,7 @@ func newSolver(cs *system, witness fr.Vector, opts ...csolver.Option) (*solver,
 		mHintsFunctions: hintFunctions,
 		logger:          opt.Logger,
 		nbTasks:         opt.NbTasks,
+		ctx:             opt.Ctx,
 		q:               cs.Field(),
 	}
 
@@ -463,6 +466,12 @@ func (solver *solver) run() error {
 	// for each level, we push the tasks
 	for _, level := range solver.Levels {
 
+		// stop if the context is done. The levels are the safe points, no
+		// worker is running at this point.
+		if err := solver.ctx.Err(); err != nil {
+			return err
+		}
+
 		// max CPU to use
 		maxCPU := float64(len(level)) / minWorkPerCPU
 
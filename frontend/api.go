@@ -19,6 +19,7 @@ package frontend
 import (
 	"math/big"
 
+	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/constraint/solver"
 )
 
@@ -163,4 +164,14 @@ type PlonkAPI interface {
 
 	// AddPlonkConstraint asserts qL.a + qR.b + qM.ab + qO.o + qC
 	AddPlonkConstraint(a, b, o Variable, qL, qR, qO, qM, qC int)
+
+	// AddCustomGate registers a custom gate and returns its index, to be used
+	// with AddCustomConstraint. The PlonK backends add a selector polynomial
+	// per custom gate.
+	AddCustomGate(gate constraint.CustomGate) (int, error)
+
+	// AddCustomConstraint asserts that the identities of the custom gate of
+	// index gate hold on wires, given in the order of constraint.GateWire:
+	// (l, r, o) or (l, r, o, nextL, nextR, nextO) if the gate spans two rows.
+	AddCustomConstraint(gate int, wires ...Variable)
 }
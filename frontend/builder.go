@@ -1,6 +1,7 @@
 package frontend
 
 import (
+	"errors"
 	"math/big"
 
 	"github.com/consensys/gnark/constraint"
@@ -100,6 +101,29 @@ type Rangechecker interface {
 	Check(v Variable, bits int)
 }
 
+// ErrNativeLookupsDisabled is returned by [FixedTableLookuper.NewFixedTable]
+// when the compiler supports fixed tables but the [WithNativeLookups] compile
+// option is not set. The callers may then fall back to in-circuit lookups.
+var ErrNativeLookupsDisabled = errors.New("fixed tables require the frontend.WithNativeLookups compile option")
+
+// FixedTableLookuper allows to declare fixed tables at compile time and to
+// assert that variables are entries of them. The tables are committed in the
+// proving key and the membership is checked by the lookup argument of the
+// backend, which is much cheaper than emulating the lookups in-circuit. Not
+// all compilers implement this interface, and the ones which do may require
+// [WithNativeLookups].
+type FixedTableLookuper interface {
+	// NewFixedTable declares a table with the given entries and returns its
+	// index. It returns [ErrNativeLookupsDisabled] if the compiler doesn't
+	// support fixed tables in its current configuration, or another error if
+	// the table is rejected.
+	NewFixedTable(entries []*big.Int) (int, error)
+
+	// AssertIsInFixedTable asserts that v is an entry of the table of index
+	// table.
+	AssertIsInFixedTable(table int, v Variable)
+}
+
 // CanonicalVariable represents a variable that's encoded in a constraint system specific way.
 // For example a R1CS builder may represent this as a constraint.LinearExpression,
 // a PLONK builder --> constraint.Term
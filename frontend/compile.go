@@ -51,6 +51,9 @@ func Compile(field *big.Int, newBuilder NewBuilder, circuit Circuit, opts ...Com
 		log.Err(err).Msg("instantiating builder")
 		return nil, fmt.Errorf("new compiler: %w", err)
 	}
+	if _, ok := builder.(FixedTableLookuper); opt.NativeLookups && !ok {
+		log.Warn().Msg("the compiler does not support fixed tables, the WithNativeLookups option is ignored")
+	}
 
 	// parse the circuit builds a schema of the circuit
 	// and call circuit.Define() method to initialize a list of constraints in the compiler
@@ -154,6 +157,7 @@ type CompileConfig struct {
 	Capacity                  int
 	IgnoreUnconstrainedInputs bool
 	CompressThreshold         int
+	NativeLookups             bool
 }
 
 // WithCapacity is a compile option that specifies the estimated capacity needed
@@ -203,6 +207,23 @@ func WithCompressThreshold(threshold int) CompileOption {
 	}
 }
 
+// WithNativeLookups is a compile option which enables the fixed tables of the
+// compilers implementing [FixedTableLookuper]. The tables are then checked by
+// the lookup argument of the backend, and the range checks and the byte
+// operations of the standard library use them instead of emulating lookups
+// in-circuit.
+//
+// The option is disabled by default as the exported verifiers (Solidity etc.)
+// do not support the lookup argument. If the compiler does not implement
+// [FixedTableLookuper], a warning is logged and this option does not change the
+// compile behaviour.
+func WithNativeLookups() CompileOption {
+	return func(opt *CompileConfig) error {
+		opt.NativeLookups = true
+		return nil
+	}
+}
+
 var tVariable reflect.Type
 
 func init() {
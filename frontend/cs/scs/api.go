@@ -18,6 +18,7 @@ package scs
 
 import (
 	"fmt"
+	"math/big"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -719,6 +720,99 @@ func (builder *builder) AddPlonkConstraint(a, b, o frontend.Variable, qL, qR, qO
 	})
 }
 
+// AddCustomGate registers a custom gate and returns its index
+func (builder *builder) AddCustomGate(gate constraint.CustomGate) (int, error) {
+	bID, err := builder.cs.AddCustomGate(gate)
+	if err != nil {
+		return 0, err
+	}
+	builder.customGates = append(builder.customGates, gate)
+	builder.customGateIDs = append(builder.customGateIDs, bID)
+	return len(builder.customGates) - 1, nil
+}
+
+// AddCustomConstraint asserts that the identities of the custom gate of index gate hold on wires
+func (builder *builder) AddCustomConstraint(gate int, wires ...frontend.Variable) {
+	if gate < 0 || gate >= len(builder.customGates) {
+		panic("unknown custom gate")
+	}
+	if nbWires := builder.customGates[gate].NbWires(); len(wires) != nbWires {
+		panic(fmt.Sprintf("custom gate %d expects %d wires, got %d", gate, nbWires, len(wires)))
+	}
+
+	// the gate reads the wires of the rows, so constants and scaled terms
+	// are first materialized in wires of their own.
+	calldata := make([]uint32, len(wires))
+	for i := range wires {
+		calldata[i] = uint32(builder.toWire(wires[i]).VID)
+	}
+	builder.cs.AddInstruction(builder.customGateIDs[gate], calldata)
+	if len(calldata) == 6 {
+		// the next row only holds the wires read by the gate.
+		builder.cs.AddSparseR1C(constraint.SparseR1C{
+			XA: calldata[3],
+			XB: calldata[4],
+			XC: calldata[5],
+		}, builder.genericGate)
+	}
+}
+
+// NewFixedTable declares a fixed table and returns its index
+func (builder *builder) NewFixedTable(entries []*big.Int) (int, error) {
+	if !builder.config.NativeLookups {
+		return 0, frontend.ErrNativeLookupsDisabled
+	}
+	elements := make([]constraint.Element, len(entries))
+	for i := range entries {
+		elements[i] = builder.cs.FromInterface(entries[i])
+	}
+	bID, err := builder.cs.AddFixedTable(elements)
+	if err != nil {
+		return 0, err
+	}
+	builder.fixedTableIDs = append(builder.fixedTableIDs, bID)
+	builder.fixedTablesSize += len(entries)
+	return len(builder.fixedTableIDs) - 1, nil
+}
+
+// AssertIsInFixedTable asserts that v is an entry of the fixed table of index table
+func (builder *builder) AssertIsInFixedTable(table int, v frontend.Variable) {
+	if table < 0 || table >= len(builder.fixedTableIDs) {
+		panic("unknown fixed table")
+	}
+	builder.cs.AddInstruction(builder.fixedTableIDs[table], []uint32{uint32(builder.toWire(v).VID)})
+}
+
+// toWire returns a term with coefficient 1 whose wire holds the value of v
+func (builder *builder) toWire(v frontend.Variable) expr.Term {
+	if c, isConstant := builder.constantValue(v); isConstant {
+		// -res + c == 0
+		res := builder.newInternalVariable()
+		builder.addPlonkConstraint(sparseR1C{
+			xa: res.VID,
+			xb: res.VID,
+			xc: res.VID,
+			qL: builder.tMinusOne,
+			qC: c,
+		})
+		return res
+	}
+	t := v.(expr.Term)
+	if builder.cs.IsOne(t.Coeff) {
+		return t
+	}
+	// qL⋅t - res == 0
+	res := builder.newInternalVariable()
+	builder.addPlonkConstraint(sparseR1C{
+		xa: t.VID,
+		xb: t.VID,
+		xc: res.VID,
+		qL: t.Coeff,
+		qO: builder.tMinusOne,
+	})
+	return res
+}
+
 func filterConstants(v []frontend.Variable) []frontend.Variable {
 	res := make([]frontend.Variable, 0, len(v))
 	for _, vI := range v {
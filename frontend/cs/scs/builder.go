@@ -71,6 +71,14 @@ type builder struct {
 	genericGate                constraint.BlueprintID
 	mulGate, addGate, boolGate constraint.BlueprintID
 
+	// custom gates and their blueprints, see AddCustomGate(...)
+	customGates   []constraint.CustomGate
+	customGateIDs []constraint.BlueprintID
+
+	// fixed tables blueprints and their total number of entries, see NewFixedTable(...)
+	fixedTableIDs   []constraint.BlueprintID
+	fixedTablesSize int
+
 	// used to avoid repeated allocations
 	bufL expr.LinearExpression
 	bufH []constraint.LinearExpression
@@ -299,6 +307,12 @@ func (builder *builder) Compile() (constraint.ConstraintSystem, error) {
 		}
 	}
 
+	// the entries of the fixed tables are laid out along the rows of the
+	// PLONK trace, so we pad the system for the tables to fit.
+	for builder.cs.GetNbConstraints()+builder.cs.GetNbPublicVariables() < builder.fixedTablesSize {
+		builder.cs.AddSparseR1C(constraint.SparseR1C{}, builder.genericGate)
+	}
+
 	return builder.cs, nil
 }
 
@@ -102,9 +102,13 @@ func main() {
 			defer wg.Done()
 
 			var (
-				groth16Dir         = strings.Replace(d.RootPath, "{?}", "groth16", 1)
-				groth16MpcSetupDir = filepath.Join(groth16Dir, "mpcsetup")
-				plonkDir           = strings.Replace(d.RootPath, "{?}", "plonk", 1)
+				groth16Dir          = strings.Replace(d.RootPath, "{?}", "groth16", 1)
+				groth16MpcSetupDir  = filepath.Join(groth16Dir, "mpcsetup")
+				groth16SnarkPackDir = filepath.Join(groth16Dir, "snarkpack")
+				plonkDir            = strings.Replace(d.RootPath, "{?}", "plonk", 1)
+				plonkMpcSetupDir    = filepath.Join(plonkDir, "mpcsetup")
+				plonkFriDir         = strings.Replace(d.RootPath, "{?}", "plonkfri", 1)
+				novaDir             = strings.Replace(d.RootPath, "{?}", "nova", 1)
 			)
 
 			if err := os.MkdirAll(groth16Dir, 0700); err != nil {
@@ -153,6 +157,12 @@ func main() {
 			if err := os.MkdirAll(plonkDir, 0700); err != nil {
 				panic(err)
 			}
+			if err := os.MkdirAll(plonkFriDir, 0700); err != nil {
+				panic(err)
+			}
+			if err := os.MkdirAll(novaDir, 0700); err != nil {
+				panic(err)
+			}
 
 			entries = []bavard.Entry{
 				{File: filepath.Join(groth16Dir, "verify.go"), Templates: []string{"groth16/groth16.verify.go.tmpl", importCurve}},
@@ -181,6 +191,8 @@ func main() {
 				{File: filepath.Join(groth16MpcSetupDir, "phase2.go"), Templates: []string{"groth16/mpcsetup/phase2.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16MpcSetupDir, "setup.go"), Templates: []string{"groth16/mpcsetup/setup.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16MpcSetupDir, "setup_test.go"), Templates: []string{"groth16/mpcsetup/setup_test.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16MpcSetupDir, "stream.go"), Templates: []string{"groth16/mpcsetup/stream.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16MpcSetupDir, "stream_test.go"), Templates: []string{"groth16/mpcsetup/stream_test.go.tmpl", importCurve}},
 				{File: filepath.Join(groth16MpcSetupDir, "utils.go"), Templates: []string{"groth16/mpcsetup/utils.go.tmpl", importCurve}},
 			}
 
@@ -188,6 +200,19 @@ func main() {
 				panic(err) // TODO handle
 			}
 
+			// groth16 snarkpack
+			entries = []bavard.Entry{
+				{File: filepath.Join(groth16SnarkPackDir, "setup.go"), Templates: []string{"groth16/snarkpack/setup.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16SnarkPackDir, "prove.go"), Templates: []string{"groth16/snarkpack/prove.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16SnarkPackDir, "verify.go"), Templates: []string{"groth16/snarkpack/verify.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16SnarkPackDir, "marshal.go"), Templates: []string{"groth16/snarkpack/marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(groth16SnarkPackDir, "snarkpack_test.go"), Templates: []string{"groth16/snarkpack/snarkpack_test.go.tmpl", importCurve}},
+			}
+
+			if err := bgen.Generate(d, "snarkpack", "./template/zkpschemes/", entries...); err != nil {
+				panic(err) // TODO handle
+			}
+
 			// plonk
 			entries = []bavard.Entry{
 				{File: filepath.Join(plonkDir, "verify.go"), Templates: []string{"plonk/plonk.verify.go.tmpl", importCurve}},
@@ -200,6 +225,54 @@ func main() {
 				panic(err)
 			}
 
+			// plonk with FRI
+			entries = []bavard.Entry{
+				{File: filepath.Join(plonkFriDir, "verify.go"), Templates: []string{"plonkfri/plonk.verify.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkFriDir, "prove.go"), Templates: []string{"plonkfri/plonk.prove.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkFriDir, "setup.go"), Templates: []string{"plonkfri/plonk.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkFriDir, "marshal.go"), Templates: []string{"plonkfri/plonk.marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkFriDir, "fri.go"), Templates: []string{"plonkfri/plonk.fri.go.tmpl", importCurve}},
+			}
+			if err := bgen.Generate(d, "plonkfri", "./template/zkpschemes/", entries...); err != nil {
+				panic(err)
+			}
+
+			// nova folding
+			entries = []bavard.Entry{
+				{File: filepath.Join(novaDir, "setup.go"), Templates: []string{"nova/nova.setup.go.tmpl", importCurve}},
+				{File: filepath.Join(novaDir, "fold.go"), Templates: []string{"nova/nova.fold.go.tmpl", importCurve}},
+				{File: filepath.Join(novaDir, "ivc.go"), Templates: []string{"nova/nova.ivc.go.tmpl", importCurve}},
+				{File: filepath.Join(novaDir, "decider.go"), Templates: []string{"nova/nova.decider.go.tmpl", importCurve}},
+				{File: filepath.Join(novaDir, "fold_test.go"), Templates: []string{"nova/tests/nova.go.tmpl", importCurve}},
+			}
+			if err := bgen.Generate(d, "nova", "./template/zkpschemes/", entries...); err != nil {
+				panic(err)
+			}
+
+			// plonk mpcsetup
+			entries = []bavard.Entry{
+				{File: filepath.Join(plonkMpcSetupDir, "marshal.go"), Templates: []string{"plonk/mpcsetup/marshal.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkMpcSetupDir, "powersoftau.go"), Templates: []string{"plonk/mpcsetup/powersoftau.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkMpcSetupDir, "powersoftau_test.go"), Templates: []string{"plonk/mpcsetup/powersoftau_test.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkMpcSetupDir, "srs.go"), Templates: []string{"plonk/mpcsetup/srs.go.tmpl", importCurve}},
+				{File: filepath.Join(plonkMpcSetupDir, "utils.go"), Templates: []string{"plonk/mpcsetup/utils.go.tmpl", importCurve}},
+			}
+			switch d.Curve {
+			case "BN254":
+				entries = append(entries,
+					bavard.Entry{File: filepath.Join(plonkMpcSetupDir, "ppot.go"), Templates: []string{"plonk/mpcsetup/ppot.go.tmpl", importCurve}},
+					bavard.Entry{File: filepath.Join(plonkMpcSetupDir, "ppot_test.go"), Templates: []string{"plonk/mpcsetup/ppot_test.go.tmpl", importCurve}},
+				)
+			case "BLS12-381":
+				entries = append(entries,
+					bavard.Entry{File: filepath.Join(plonkMpcSetupDir, "ethereum.go"), Templates: []string{"plonk/mpcsetup/ethereum.go.tmpl", importCurve}},
+					bavard.Entry{File: filepath.Join(plonkMpcSetupDir, "ethereum_test.go"), Templates: []string{"plonk/mpcsetup/ethereum_test.go.tmpl", importCurve}},
+				)
+			}
+			if err := bgen.Generate(d, "mpcsetup", "./template/zkpschemes/", entries...); err != nil {
+				panic(err)
+			}
+
 		}(d)
 
 	}
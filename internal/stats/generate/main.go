@@ -40,6 +40,10 @@ func main() {
 			defer wg.Done()
 			for _, curve := range circuit.Curves {
 				for _, backendID := range backend.Implemented() {
+					if backendID == backend.PLONK_FRI {
+						// same constraint system as PLONK
+						continue
+					}
 					cs, err := stats.NewSnippetStats(curve, backendID, circuit.Circuit)
 					if err != nil {
 						log.Fatalf("building stats for circuit %s %v", name, err)
@@ -59,6 +63,9 @@ func main() {
 		ss := s.Stats[name]
 		for _, curve := range c.Curves {
 			for _, backendID := range backend.Implemented() {
+				if backendID == backend.PLONK_FRI {
+					continue
+				}
 				cs := ss[backendID][stats.CurveIdx(curve)]
 				fmt.Printf("%s,%s,%s,%d,%d\n", name, curve, backendID, cs.NbConstraints, cs.NbInternalWires)
 			}
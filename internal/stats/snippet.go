@@ -15,6 +15,7 @@ import (
 	"github.com/consensys/gnark/std/hash/mimc"
 	"github.com/consensys/gnark/std/math/bits"
 	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
 )
 
 var (
@@ -77,6 +78,15 @@ func initSnippets() {
 		_ = bits.ToNAF(api, newVariable(), bits.WithUnconstrainedOutputs())
 	})
 
+	registerSnippet("math/uints.Add", func(api frontend.API, newVariable func() frontend.Variable) {
+		uapi, err := uints.New[uints.U64](api)
+		if err != nil {
+			panic(err)
+		}
+		a, b, c := uapi.ValueOf(newVariable()), uapi.ValueOf(newVariable()), uapi.ValueOf(newVariable())
+		_ = uapi.Add(a, b, c)
+	})
+
 	registerSnippet("hash/mimc", func(api frontend.API, newVariable func() frontend.Variable) {
 		mimc, _ := mimc.NewMiMC(api)
 		mimc.Write(newVariable())
@@ -28,6 +28,10 @@ func TestCircuitStatistics(t *testing.T) {
 		}
 		for _, curve := range c.Curves {
 			for _, b := range backend.Implemented() {
+				if b == backend.PLONK_FRI {
+					// same constraint system as PLONK
+					continue
+				}
 				curve := curve
 				backendID := b
 				name := name
@@ -0,0 +1,89 @@
+// Package smt provides ZKP-circuit functions to verify proofs in sparse Merkle
+// trees.
+//
+// A sparse Merkle tree of depth d stores a value for every key in [0, 2^d).
+// The leaf at index key is either empty, in which case its hash is 0, or holds
+// a value, in which case its hash is H(key, value). An internal node is
+// H(left, right). Proofs are the list of the d siblings on the path from the
+// leaf to the root, starting from the leaf level, and bit i of the key (least
+// significant bit first) is 1 if the node at level i is a right child.
+//
+// The tree is parameterised by a [hash.FieldHasher]. The native counterpart
+// building the tree and computing the proofs is [Tree].
+package smt
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// Proof is the path from a leaf to the root of a sparse Merkle tree.
+type Proof struct {
+	// Siblings are the siblings of the nodes on the path, starting from the
+	// leaf level. The length of the slice is the depth of the tree.
+	Siblings []frontend.Variable
+}
+
+// Leaf is the content of a leaf of the tree.
+type Leaf struct {
+	// Value is the value stored in the leaf. It is ignored when the leaf is
+	// empty.
+	Value frontend.Variable
+	// IsEmpty is 1 if the leaf is empty and 0 otherwise.
+	IsEmpty frontend.Variable
+}
+
+// leafHash returns the hash of the leaf at index key.
+func leafHash(api frontend.API, h hash.FieldHasher, key frontend.Variable, leaf Leaf) frontend.Variable {
+	api.AssertIsBoolean(leaf.IsEmpty)
+	h.Reset()
+	h.Write(key, leaf.Value)
+	res := h.Sum()
+	return api.Select(leaf.IsEmpty, 0, res)
+}
+
+// computeRoot returns the root of the tree with the leaf hash at the position
+// given by the bits of the key.
+func (p *Proof) computeRoot(api frontend.API, h hash.FieldHasher, keyBits []frontend.Variable, sum frontend.Variable) frontend.Variable {
+	for i := range p.Siblings {
+		left := api.Select(keyBits[i], p.Siblings[i], sum)
+		right := api.Select(keyBits[i], sum, p.Siblings[i])
+		h.Reset()
+		h.Write(left, right)
+		sum = h.Sum()
+	}
+	return sum
+}
+
+// VerifyLeaf asserts that leaf is the content of the leaf at index key in the
+// tree of the given root. The key must be less than 2^d where d is the depth of
+// the tree.
+func (p *Proof) VerifyLeaf(api frontend.API, h hash.FieldHasher, root, key frontend.Variable, leaf Leaf) {
+	keyBits := api.ToBinary(key, len(p.Siblings))
+	sum := p.computeRoot(api, h, keyBits, leafHash(api, h, key, leaf))
+	api.AssertIsEqual(sum, root)
+}
+
+// VerifyMembership asserts that the tree of the given root stores value at
+// index key.
+func (p *Proof) VerifyMembership(api frontend.API, h hash.FieldHasher, root, key, value frontend.Variable) {
+	p.VerifyLeaf(api, h, root, key, Leaf{Value: value, IsEmpty: 0})
+}
+
+// VerifyNonMembership asserts that the leaf at index key of the tree of the
+// given root is empty.
+func (p *Proof) VerifyNonMembership(api frontend.API, h hash.FieldHasher, root, key frontend.Variable) {
+	p.VerifyLeaf(api, h, root, key, Leaf{Value: 0, IsEmpty: 1})
+}
+
+// VerifyUpdate asserts that replacing the leaf oldLeaf at index key in the tree
+// of root oldRoot by newLeaf gives the tree of root newRoot. As the siblings do
+// not change when updating a single leaf, the same proof is used for both
+// roots. Insertions and deletions are updates from and to an empty leaf.
+func (p *Proof) VerifyUpdate(api frontend.API, h hash.FieldHasher, oldRoot, newRoot, key frontend.Variable, oldLeaf, newLeaf Leaf) {
+	keyBits := api.ToBinary(key, len(p.Siblings))
+	oldSum := p.computeRoot(api, h, keyBits, leafHash(api, h, key, oldLeaf))
+	api.AssertIsEqual(oldSum, oldRoot)
+	newSum := p.computeRoot(api, h, keyBits, leafHash(api, h, key, newLeaf))
+	api.AssertIsEqual(newSum, newRoot)
+}
@@ -0,0 +1,117 @@
+package smt
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/hash"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/mimc"
+	"github.com/consensys/gnark/test"
+)
+
+const testDepth = 16
+
+type smtCircuit struct {
+	OldRoot, NewRoot frontend.Variable
+	Key              frontend.Variable
+	OldLeaf, NewLeaf Leaf
+	Proof            Proof
+
+	MemberRoot  frontend.Variable
+	MemberKey   frontend.Variable
+	MemberValue frontend.Variable
+	MemberProof Proof
+
+	AbsentKey   frontend.Variable
+	AbsentProof Proof
+}
+
+func (c *smtCircuit) Define(api frontend.API) error {
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return err
+	}
+	c.Proof.VerifyUpdate(api, &h, c.OldRoot, c.NewRoot, c.Key, c.OldLeaf, c.NewLeaf)
+	c.MemberProof.VerifyMembership(api, &h, c.MemberRoot, c.MemberKey, c.MemberValue)
+	c.AbsentProof.VerifyNonMembership(api, &h, c.MemberRoot, c.AbsentKey)
+	return nil
+}
+
+func newCircuit() *smtCircuit {
+	return &smtCircuit{
+		Proof:       Proof{Siblings: make([]frontend.Variable, testDepth)},
+		MemberProof: Proof{Siblings: make([]frontend.Variable, testDepth)},
+		AbsentProof: Proof{Siblings: make([]frontend.Variable, testDepth)},
+	}
+}
+
+func toVariables(v []*big.Int) []frontend.Variable {
+	res := make([]frontend.Variable, len(v))
+	for i := range v {
+		res[i] = v[i]
+	}
+	return res
+}
+
+func TestSparseMerkleTree(t *testing.T) {
+	assert := test.NewAssert(t)
+	tree := NewTree(hash.MIMC_BN254.New(), testDepth, ecc.BN254.ScalarField())
+	emptyRoot := tree.Root()
+	for i := int64(0); i < 10; i++ {
+		assert.NoError(tree.Set(big.NewInt(i*i*97), big.NewInt(1000+i)))
+	}
+	assert.ErrorIs(tree.Set(big.NewInt(1<<testDepth), big.NewInt(1)), ErrKeyOutOfRange)
+
+	// insertion of a new leaf
+	key := big.NewInt(12345)
+	oldRoot := tree.Root()
+	siblings, err := tree.Prove(key)
+	assert.NoError(err)
+	assert.NoError(tree.Set(key, big.NewInt(42)))
+	newRoot := tree.Root()
+
+	memberKey := big.NewInt(9 * 9 * 97)
+	memberProof, err := tree.Prove(memberKey)
+	assert.NoError(err)
+	absentKey := big.NewInt(7)
+	absentProof, err := tree.Prove(absentKey)
+	assert.NoError(err)
+
+	witness := &smtCircuit{
+		OldRoot: oldRoot,
+		NewRoot: newRoot,
+		Key:     key,
+		OldLeaf: Leaf{Value: 0, IsEmpty: 1},
+		NewLeaf: Leaf{Value: 42, IsEmpty: 0},
+		Proof:   Proof{Siblings: toVariables(siblings)},
+
+		MemberRoot:  newRoot,
+		MemberKey:   memberKey,
+		MemberValue: 1009,
+		MemberProof: Proof{Siblings: toVariables(memberProof)},
+
+		AbsentKey:   absentKey,
+		AbsentProof: Proof{Siblings: toVariables(absentProof)},
+	}
+	assert.NoError(test.IsSolved(newCircuit(), witness, ecc.BN254.ScalarField()))
+
+	// wrong value
+	witness.MemberValue = 1010
+	assert.Error(test.IsSolved(newCircuit(), witness, ecc.BN254.ScalarField()))
+	witness.MemberValue = 1009
+
+	// non-membership of an existing key
+	witness.AbsentKey = memberKey
+	witness.AbsentProof = witness.MemberProof
+	assert.Error(test.IsSolved(newCircuit(), witness, ecc.BN254.ScalarField()))
+
+	// deleting all the leaves gives back the empty tree
+	assert.NoError(tree.Delete(key))
+	assert.Equal(oldRoot, tree.Root())
+	for i := int64(0); i < 10; i++ {
+		assert.NoError(tree.Delete(big.NewInt(i * i * 97)))
+	}
+	assert.Equal(emptyRoot, tree.Root())
+}
@@ -0,0 +1,163 @@
+package smt
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+)
+
+var (
+	ErrKeyOutOfRange = errors.New("smt: key is out of range for the depth of the tree")
+	ErrNonCanonical  = errors.New("smt: value is not a canonical field element")
+)
+
+// Tree is a native sparse Merkle tree matching the circuit functions of this
+// package. It computes the roots and the proofs to use as witnesses.
+//
+// The hash function must be the native counterpart of the in-circuit
+// [hash.FieldHasher], such as the MiMC hashers of gnark-crypto. The field
+// elements are written to it as big-endian encoded blocks of
+// [hash.Hash.BlockSize] bytes.
+type Tree struct {
+	h       hash.Hash
+	depth   int
+	modulus *big.Int
+
+	// empty[i] is the hash of an empty subtree of height i.
+	empty []*big.Int
+	// nodes[i] are the non-empty nodes at level i indexed by position.
+	nodes []map[string]*big.Int
+	// values are the values of the non-empty leaves indexed by key.
+	values map[string]*big.Int
+}
+
+// NewTree returns an empty tree of the given depth over the field of the given
+// modulus.
+func NewTree(h hash.Hash, depth int, modulus *big.Int) *Tree {
+	t := &Tree{
+		h:       h,
+		depth:   depth,
+		modulus: new(big.Int).Set(modulus),
+		empty:   make([]*big.Int, depth+1),
+		nodes:   make([]map[string]*big.Int, depth+1),
+		values:  make(map[string]*big.Int),
+	}
+	t.empty[0] = new(big.Int)
+	for i := 1; i <= depth; i++ {
+		t.empty[i] = t.hash(t.empty[i-1], t.empty[i-1])
+	}
+	for i := range t.nodes {
+		t.nodes[i] = make(map[string]*big.Int)
+	}
+	return t
+}
+
+// Depth returns the depth of the tree.
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+// Root returns the root of the tree.
+func (t *Tree) Root() *big.Int {
+	return new(big.Int).Set(t.node(t.depth, new(big.Int)))
+}
+
+// Get returns the value stored at index key and whether the leaf is non-empty.
+func (t *Tree) Get(key *big.Int) (*big.Int, bool) {
+	v, ok := t.values[key.String()]
+	if !ok {
+		return nil, false
+	}
+	return new(big.Int).Set(v), true
+}
+
+// Set stores value at index key.
+func (t *Tree) Set(key, value *big.Int) error {
+	if err := t.checkKey(key); err != nil {
+		return err
+	}
+	if value.Sign() < 0 || value.Cmp(t.modulus) >= 0 {
+		return ErrNonCanonical
+	}
+	t.values[key.String()] = new(big.Int).Set(value)
+	t.updatePath(key, t.hash(key, value))
+	return nil
+}
+
+// Delete empties the leaf at index key.
+func (t *Tree) Delete(key *big.Int) error {
+	if err := t.checkKey(key); err != nil {
+		return err
+	}
+	delete(t.values, key.String())
+	t.updatePath(key, nil)
+	return nil
+}
+
+// Prove returns the siblings of the path from the leaf at index key to the
+// root, starting from the leaf level. They are the same for membership,
+// non-membership and update proofs.
+func (t *Tree) Prove(key *big.Int) ([]*big.Int, error) {
+	if err := t.checkKey(key); err != nil {
+		return nil, err
+	}
+	siblings := make([]*big.Int, t.depth)
+	idx := new(big.Int).Set(key)
+	for i := 0; i < t.depth; i++ {
+		sibling := new(big.Int).Xor(idx, big.NewInt(1))
+		siblings[i] = new(big.Int).Set(t.node(i, sibling))
+		idx.Rsh(idx, 1)
+	}
+	return siblings, nil
+}
+
+// updatePath sets the leaf hash at index key (nil for an empty leaf) and
+// recomputes the nodes up to the root.
+func (t *Tree) updatePath(key, leaf *big.Int) {
+	idx := new(big.Int).Set(key)
+	t.setNode(0, idx, leaf)
+	for i := 1; i <= t.depth; i++ {
+		left := new(big.Int).AndNot(idx, big.NewInt(1))
+		right := new(big.Int).Or(idx, big.NewInt(1))
+		l, r := t.node(i-1, left), t.node(i-1, right)
+		idx.Rsh(idx, 1)
+		if l == t.empty[i-1] && r == t.empty[i-1] {
+			t.setNode(i, idx, nil)
+		} else {
+			t.setNode(i, idx, t.hash(l, r))
+		}
+	}
+}
+
+// node returns the node at the given level and position.
+func (t *Tree) node(level int, idx *big.Int) *big.Int {
+	if n, ok := t.nodes[level][idx.String()]; ok {
+		return n
+	}
+	return t.empty[level]
+}
+
+// setNode sets the node at the given level and position, nil removing it.
+func (t *Tree) setNode(level int, idx, n *big.Int) {
+	if n == nil {
+		delete(t.nodes[level], idx.String())
+		return
+	}
+	t.nodes[level][idx.String()] = n
+}
+
+func (t *Tree) checkKey(key *big.Int) error {
+	if key.Sign() < 0 || key.BitLen() > t.depth || key.Cmp(t.modulus) >= 0 {
+		return ErrKeyOutOfRange
+	}
+	return nil
+}
+
+// hash returns H(a, b).
+func (t *Tree) hash(a, b *big.Int) *big.Int {
+	buf := make([]byte, t.h.BlockSize())
+	t.h.Reset()
+	t.h.Write(a.FillBytes(buf))
+	t.h.Write(b.FillBytes(buf))
+	return new(big.Int).SetBytes(t.h.Sum(nil))
+}
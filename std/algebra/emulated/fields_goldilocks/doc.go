@@ -0,0 +1,8 @@
+// Package fields_goldilocks implements the arithmetic of the quadratic
+// extension of the Goldilocks field p = 2⁶⁴ - 2³² + 1 used by Plonky2:
+//
+//	𝔽p²[u] = 𝔽p/u²-7
+//
+// The base field is emulated using [emulated.Field] with [emulated.Goldilocks]
+// parameters.
+package fields_goldilocks
@@ -0,0 +1,244 @@
+package fields_goldilocks
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+type baseField = emulated.Field[emulated.Goldilocks]
+type baseEl = emulated.Element[emulated.Goldilocks]
+
+// W is the quadratic non-residue defining the extension, u² = W.
+const W = 7
+
+// E2 is an element A0 + A1*u of the quadratic extension.
+type E2 struct {
+	A0, A1 baseEl
+}
+
+// Ext2 implements the arithmetic of the quadratic extension.
+type Ext2 struct {
+	api frontend.API
+	fp  *baseField
+}
+
+// NewExt2 returns a new instance of the extension field arithmetic.
+func NewExt2(api frontend.API) *Ext2 {
+	fp, err := emulated.NewField[emulated.Goldilocks](api)
+	if err != nil {
+		panic(err)
+	}
+	return &Ext2{api: api, fp: fp}
+}
+
+// BaseField returns the emulated base field.
+func (e Ext2) BaseField() *baseField {
+	return e.fp
+}
+
+// FromBase returns the element x + 0*u.
+func (e Ext2) FromBase(x *baseEl) *E2 {
+	return &E2{
+		A0: *x,
+		A1: *e.fp.Zero(),
+	}
+}
+
+func (e Ext2) MulByElement(x *E2, y *baseEl) *E2 {
+	z0 := e.fp.Mul(&x.A0, y)
+	z1 := e.fp.Mul(&x.A1, y)
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) MulByConstElement(x *E2, y *big.Int) *E2 {
+	z0 := e.fp.MulConst(&x.A0, y)
+	z1 := e.fp.MulConst(&x.A1, y)
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+// MulByNonResidue returns x*u
+func (e Ext2) MulByNonResidue(x *E2) *E2 {
+	z0 := e.fp.MulConst(&x.A1, big.NewInt(W))
+	return &E2{
+		A0: *z0,
+		A1: x.A0,
+	}
+}
+
+func (e Ext2) Conjugate(x *E2) *E2 {
+	z0 := x.A0
+	z1 := e.fp.Neg(&x.A1)
+	return &E2{
+		A0: z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) Mul(x, y *E2) *E2 {
+	a := e.fp.Add(&x.A0, &x.A1)
+	b := e.fp.Add(&y.A0, &y.A1)
+	a = e.fp.Mul(a, b)
+	b = e.fp.Mul(&x.A0, &y.A0)
+	c := e.fp.Mul(&x.A1, &y.A1)
+	z1 := e.fp.Sub(a, b)
+	z1 = e.fp.Sub(z1, c)
+	z0 := e.fp.MulConst(c, big.NewInt(W))
+	z0 = e.fp.Add(z0, b)
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) Add(x, y *E2) *E2 {
+	z0 := e.fp.Add(&x.A0, &y.A0)
+	z1 := e.fp.Add(&x.A1, &y.A1)
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) Sub(x, y *E2) *E2 {
+	z0 := e.fp.Sub(&x.A0, &y.A0)
+	z1 := e.fp.Sub(&x.A1, &y.A1)
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) Neg(x *E2) *E2 {
+	z0 := e.fp.Neg(&x.A0)
+	z1 := e.fp.Neg(&x.A1)
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) One() *E2 {
+	z0 := e.fp.One()
+	z1 := e.fp.Zero()
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) Zero() *E2 {
+	z0 := e.fp.Zero()
+	z1 := e.fp.Zero()
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) IsZero(z *E2) frontend.Variable {
+	a0 := e.fp.IsZero(&z.A0)
+	a1 := e.fp.IsZero(&z.A1)
+	return e.api.And(a0, a1)
+}
+
+func (e Ext2) Square(x *E2) *E2 {
+	a := e.fp.Mul(&x.A0, &x.A0)
+	b := e.fp.Mul(&x.A1, &x.A1)
+	b = e.fp.MulConst(b, big.NewInt(W))
+	a = e.fp.Add(a, b)
+	b = e.fp.Mul(&x.A0, &x.A1)
+	b = e.fp.MulConst(b, big.NewInt(2))
+	return &E2{
+		A0: *a,
+		A1: *b,
+	}
+}
+
+func (e Ext2) Double(x *E2) *E2 {
+	two := big.NewInt(2)
+	z0 := e.fp.MulConst(&x.A0, two)
+	z1 := e.fp.MulConst(&x.A1, two)
+	return &E2{
+		A0: *z0,
+		A1: *z1,
+	}
+}
+
+func (e Ext2) AssertIsEqual(x, y *E2) {
+	e.fp.AssertIsEqual(&x.A0, &y.A0)
+	e.fp.AssertIsEqual(&x.A1, &y.A1)
+}
+
+func (e Ext2) Inverse(x *E2) *E2 {
+	res, err := e.fp.NewHint(inverseE2Hint, 2, &x.A0, &x.A1)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	inv := E2{
+		A0: *res[0],
+		A1: *res[1],
+	}
+	one := e.One()
+
+	// 1 == inv * x
+	_one := e.Mul(&inv, x)
+	e.AssertIsEqual(one, _one)
+
+	return &inv
+
+}
+
+func (e Ext2) DivUnchecked(x, y *E2) *E2 {
+	res, err := e.fp.NewHint(divE2Hint, 2, &x.A0, &x.A1, &y.A0, &y.A1)
+	if err != nil {
+		// err is non-nil only for invalid number of inputs
+		panic(err)
+	}
+
+	div := E2{
+		A0: *res[0],
+		A1: *res[1],
+	}
+
+	// x == div * y
+	_x := e.Mul(&div, y)
+	e.AssertIsEqual(x, _x)
+
+	return &div
+}
+
+func (e Ext2) Select(selector frontend.Variable, z1, z0 *E2) *E2 {
+	a0 := e.fp.Select(selector, &z1.A0, &z0.A0)
+	a1 := e.fp.Select(selector, &z1.A1, &z0.A1)
+	return &E2{A0: *a0, A1: *a1}
+}
+
+func (e Ext2) Lookup2(s1, s2 frontend.Variable, a, b, c, d *E2) *E2 {
+	a0 := e.fp.Lookup2(s1, s2, &a.A0, &b.A0, &c.A0, &d.A0)
+	a1 := e.fp.Lookup2(s1, s2, &a.A1, &b.A1, &c.A1, &d.A1)
+	return &E2{A0: *a0, A1: *a1}
+}
+
+// EvalPolynomial returns the evaluation at x of the polynomial with
+// coefficients coeffs in the extension, given in increasing degree order.
+func (e Ext2) EvalPolynomial(coeffs []E2, x *baseEl) *E2 {
+	if len(coeffs) == 0 {
+		return e.Zero()
+	}
+	res := &coeffs[len(coeffs)-1]
+	for i := len(coeffs) - 2; i >= 0; i-- {
+		res = e.MulByElement(res, x)
+		res = e.Add(res, &coeffs[i])
+	}
+	return res
+}
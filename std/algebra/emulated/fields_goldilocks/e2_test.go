@@ -0,0 +1,215 @@
+package fields_goldilocks
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+// e2 is a native element of the extension used to compute the witnesses.
+type e2 struct {
+	a0, a1 goldilocks.Element
+}
+
+func (z *e2) setRandom() *e2 {
+	z.a0.SetRandom()
+	z.a1.SetRandom()
+	return z
+}
+
+func (z *e2) mul(x, y *e2) *e2 {
+	var w, a, b, c goldilocks.Element
+	w.SetUint64(W)
+	a.Mul(&x.a0, &y.a0)
+	b.Mul(&x.a1, &y.a1)
+	b.Mul(&b, &w)
+	c.Mul(&x.a0, &y.a1)
+	z.a1.Mul(&x.a1, &y.a0)
+	z.a1.Add(&z.a1, &c)
+	z.a0.Add(&a, &b)
+	return z
+}
+
+func (z *e2) inverse(x *e2) *e2 {
+	var w, norm, t goldilocks.Element
+	w.SetUint64(W)
+	norm.Square(&x.a0)
+	t.Square(&x.a1)
+	t.Mul(&t, &w)
+	norm.Sub(&norm, &t)
+	norm.Inverse(&norm)
+	z.a0.Mul(&x.a0, &norm)
+	z.a1.Mul(&x.a1, &norm)
+	z.a1.Neg(&z.a1)
+	return z
+}
+
+func fromE2(x *e2) E2 {
+	return E2{
+		A0: emulated.ValueOf[emulated.Goldilocks](x.a0),
+		A1: emulated.ValueOf[emulated.Goldilocks](x.a1),
+	}
+}
+
+type e2Mul struct {
+	A, B, C E2
+}
+
+func (circuit *e2Mul) Define(api frontend.API) error {
+	e := NewExt2(api)
+	expected := e.Mul(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestMulFp2(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c e2
+	a.setRandom()
+	b.setRandom()
+	c.mul(&a, &b)
+
+	witness := e2Mul{
+		A: fromE2(&a),
+		B: fromE2(&b),
+		C: fromE2(&c),
+	}
+
+	err := test.IsSolved(&e2Mul{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	witness.C = fromE2(&a)
+	err = test.IsSolved(&e2Mul{}, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+type e2Square struct {
+	A, C E2
+}
+
+func (circuit *e2Square) Define(api frontend.API) error {
+	e := NewExt2(api)
+	expected := e.Square(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestSquareFp2(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c e2
+	a.setRandom()
+	c.mul(&a, &a)
+
+	witness := e2Square{
+		A: fromE2(&a),
+		C: fromE2(&c),
+	}
+
+	err := test.IsSolved(&e2Square{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type e2Inverse struct {
+	A, C E2
+}
+
+func (circuit *e2Inverse) Define(api frontend.API) error {
+	e := NewExt2(api)
+	expected := e.Inverse(&circuit.A)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestInverseFp2(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, c e2
+	a.setRandom()
+	c.inverse(&a)
+
+	witness := e2Inverse{
+		A: fromE2(&a),
+		C: fromE2(&c),
+	}
+
+	err := test.IsSolved(&e2Inverse{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type e2Div struct {
+	A, B, C E2
+}
+
+func (circuit *e2Div) Define(api frontend.API) error {
+	e := NewExt2(api)
+	expected := e.DivUnchecked(&circuit.A, &circuit.B)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestDivFp2(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var a, b, c e2
+	a.setRandom()
+	b.setRandom()
+	c.inverse(&b).mul(&c, &a)
+
+	witness := e2Div{
+		A: fromE2(&a),
+		B: fromE2(&b),
+		C: fromE2(&c),
+	}
+
+	err := test.IsSolved(&e2Div{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type e2EvalPolynomial struct {
+	Coeffs [4]E2
+	X      emulated.Element[emulated.Goldilocks]
+	C      E2
+}
+
+func (circuit *e2EvalPolynomial) Define(api frontend.API) error {
+	e := NewExt2(api)
+	expected := e.EvalPolynomial(circuit.Coeffs[:], &circuit.X)
+	e.AssertIsEqual(expected, &circuit.C)
+	return nil
+}
+
+func TestEvalPolynomialFp2(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	// witness values
+	var coeffs [4]e2
+	var x goldilocks.Element
+	var witness e2EvalPolynomial
+	x.SetRandom()
+	var c, xi e2
+	xi.a0.SetOne()
+	for i := range coeffs {
+		coeffs[i].setRandom()
+		witness.Coeffs[i] = fromE2(&coeffs[i])
+		var t e2
+		t.mul(&coeffs[i], &xi)
+		c.a0.Add(&c.a0, &t.a0)
+		c.a1.Add(&c.a1, &t.a1)
+		xi.a0.Mul(&xi.a0, &x)
+	}
+	witness.X = emulated.ValueOf[emulated.Goldilocks](x)
+	witness.C = fromE2(&c)
+
+	err := test.IsSolved(&e2EvalPolynomial{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
@@ -0,0 +1,62 @@
+package fields_goldilocks
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all hint functions used in the package.
+func GetHints() []solver.Hint {
+	return []solver.Hint{
+		divE2Hint,
+		inverseE2Hint,
+	}
+}
+
+func inverseE2Hint(nativeMod *big.Int, nativeInputs, nativeOutputs []*big.Int) error {
+	return emulated.UnwrapHint(nativeInputs, nativeOutputs,
+		func(mod *big.Int, inputs, outputs []*big.Int) error {
+			inverseE2(mod, inputs[0], inputs[1], outputs[0], outputs[1])
+			return nil
+		})
+}
+
+func divE2Hint(nativeMod *big.Int, nativeInputs, nativeOutputs []*big.Int) error {
+	return emulated.UnwrapHint(nativeInputs, nativeOutputs,
+		func(mod *big.Int, inputs, outputs []*big.Int) error {
+			var c0, c1 big.Int
+			inverseE2(mod, inputs[2], inputs[3], &c0, &c1)
+
+			// (a0 + a1*u)(c0 + c1*u) = a0*c0 + W*a1*c1 + (a0*c1 + a1*c0)*u
+			var t big.Int
+			outputs[0].Mul(inputs[0], &c0)
+			t.Mul(inputs[1], &c1).Mul(&t, big.NewInt(W))
+			outputs[0].Add(outputs[0], &t).Mod(outputs[0], mod)
+			outputs[1].Mul(inputs[0], &c1)
+			t.Mul(inputs[1], &c0)
+			outputs[1].Add(outputs[1], &t).Mod(outputs[1], mod)
+			return nil
+		})
+}
+
+// inverseE2 sets (z0, z1) to the inverse of a0 + a1*u, which is (a0 - a1*u) /
+// (a0² - W*a1²). The inverse of zero is set to zero.
+func inverseE2(mod, a0, a1, z0, z1 *big.Int) {
+	var norm, t big.Int
+	norm.Mul(a0, a0)
+	t.Mul(a1, a1).Mul(&t, big.NewInt(W))
+	norm.Sub(&norm, &t).Mod(&norm, mod)
+	if norm.ModInverse(&norm, mod) == nil {
+		z0.SetUint64(0)
+		z1.SetUint64(0)
+		return
+	}
+	z0.Mul(a0, &norm).Mod(z0, mod)
+	z1.Mul(a1, &norm).Neg(z1).Mod(z1, mod)
+}
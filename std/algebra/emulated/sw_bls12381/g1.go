@@ -28,7 +28,9 @@ func NewG1Affine(v bls12381.G1Affine) G1Affine {
 }
 
 type G1 struct {
+	api    frontend.API
 	curveF *emulated.Field[BaseField]
+	curve  *sw_emulated.Curve[BaseField, ScalarField]
 	w      *emulated.Element[BaseField]
 }
 
@@ -37,9 +39,15 @@ func NewG1(api frontend.API) (*G1, error) {
 	if err != nil {
 		return nil, fmt.Errorf("new base api: %w", err)
 	}
+	curve, err := sw_emulated.New[BaseField, ScalarField](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return nil, fmt.Errorf("new curve: %w", err)
+	}
 	w := emulated.ValueOf[BaseField]("4002409555221667392624310435006688643935503118305586438271171395842971157480381377015405980053539358417135540939436")
 	return &G1{
+		api:    api,
 		curveF: ba,
+		curve:  curve,
 		w:      &w,
 	}, nil
 }
@@ -168,6 +176,20 @@ func (g1 *G1) scalarMulBySeedSquare(q *G1Affine) *G1Affine {
 	return z
 }
 
+// scalarMulBySeedSquareUnified computes [x₀²]q as [scalarMulBySeedSquare] but
+// using the unified addition formulas, so that it is defined for any point on
+// the curve.
+func (g1 *G1) scalarMulBySeedSquareUnified(q *G1Affine) *G1Affine {
+	res := q
+	for i := seedSquare.BitLen() - 2; i >= 0; i-- {
+		res = g1.curve.AddUnified(res, res)
+		if seedSquare.Bit(i) == 1 {
+			res = g1.curve.AddUnified(res, q)
+		}
+	}
+	return res
+}
+
 // NewScalar allocates a witness from the native scalar and returns it.
 func NewScalar(v fr_bls12381.Element) Scalar {
 	return emulated.ValueOf[ScalarField](v)
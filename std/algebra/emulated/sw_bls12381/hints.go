@@ -0,0 +1,62 @@
+package sw_bls12381
+
+import (
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/constraint/solver"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+func init() {
+	solver.RegisterHint(GetHints()...)
+}
+
+// GetHints returns all the hints used in this package.
+func GetHints() []solver.Hint {
+	return []solver.Hint{isQuadraticResidueHint, sqrtE2Hint}
+}
+
+// isQuadraticResidueHint returns 1 if the input is a square in the emulated
+// field and 0 otherwise.
+func isQuadraticResidueHint(_ *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	return emulated.UnwrapHintWithNativeOutput(inputs, outputs, func(field *big.Int, inputs, outputs []*big.Int) error {
+		if len(inputs) != 1 {
+			return fmt.Errorf("expecting one input")
+		}
+		if len(outputs) != 1 {
+			return fmt.Errorf("expecting one output")
+		}
+		x := new(big.Int).Mod(inputs[0], field)
+		if x.Sign() == 0 || big.Jacobi(x, field) == 1 {
+			outputs[0].SetUint64(1)
+		} else {
+			outputs[0].SetUint64(0)
+		}
+		return nil
+	})
+}
+
+// sqrtE2Hint returns a square root of the input in Fp2, given by its two
+// coordinates.
+func sqrtE2Hint(nativeMod *big.Int, nativeInputs, nativeOutputs []*big.Int) error {
+	return emulated.UnwrapHint(nativeInputs, nativeOutputs, func(_ *big.Int, inputs, outputs []*big.Int) error {
+		if len(inputs) != 2 {
+			return fmt.Errorf("expecting two inputs")
+		}
+		if len(outputs) != 2 {
+			return fmt.Errorf("expecting two outputs")
+		}
+		var x, y bls12381.E2
+		x.A0.SetBigInt(inputs[0])
+		x.A1.SetBigInt(inputs[1])
+		if x.Legendre() == -1 {
+			return fmt.Errorf("not a square")
+		}
+		y.Sqrt(&x)
+		y.A0.BigInt(outputs[0])
+		y.A1.BigInt(outputs[1])
+		return nil
+	})
+}
@@ -0,0 +1,42 @@
+package sw_bls12381
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type mapToG1Circuit struct {
+	In  emulated.Element[BaseField]
+	Res G1Affine
+}
+
+func (c *mapToG1Circuit) Define(api frontend.API) error {
+	g1, err := NewG1(api)
+	if err != nil {
+		return err
+	}
+	res := g1.MapToG1(&c.In)
+	g1.curve.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestMapToG1TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u fp.Element
+	u.SetRandom()
+	for _, in := range []fp.Element{u, fp.NewElement(0), fp.NewElement(1)} {
+		res := bls12381.MapToG1(in)
+		witness := mapToG1Circuit{
+			In:  emulated.ValueOf[BaseField](in),
+			Res: NewG1Affine(res),
+		}
+		err := test.IsSolved(&mapToG1Circuit{}, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, in.String())
+	}
+}
@@ -0,0 +1,215 @@
+package sw_bls12381
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// the constants of the simplified SWU map to the 3-isogenous curve
+// E2': y² = x³ + A'x + B' and of the 3-isogeny E2' → E2, as defined in RFC 9380
+// (Section 8.8.2 and Appendix E.3). The elements of Fp2 are given as [a0, a1]
+// for a0 + a1·i.
+var (
+	g2SswuA = [2]string{"0", "240"}
+	g2SswuB = [2]string{"1012", "1012"}
+	// Z = -(2 + i)
+	g2SswuZ = [2]string{
+		"4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559785",
+		"4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559786",
+	}
+
+	// coefficients of the x numerator, in increasing degree
+	g2IsogenyXNum = [][2]string{
+		{"889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235542", "889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235542"},
+		{"0", "2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706522"},
+		{"2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706526", "1334136518407222464472596608578634718852294273313002628444019378708010550163612621480895876376338554679298090853261"},
+		{"3557697382419259905260257622876359250272784728834673675850718343221361467102966990615722337003569479144794908942033", "0"},
+	}
+
+	// coefficients of the monic x denominator, in increasing degree and without
+	// the leading one
+	g2IsogenyXDen = [][2]string{
+		{"0", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559715"},
+		{"12", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559775"},
+	}
+
+	// coefficients of the y numerator, in increasing degree
+	g2IsogenyYNum = [][2]string{
+		{"3261222600550988246488569487636662646083386001431784202863158481286248011511053074731078808919938689216061999863558", "3261222600550988246488569487636662646083386001431784202863158481286248011511053074731078808919938689216061999863558"},
+		{"0", "889424345604814976315064405719089812568196182208668418962679585805340366775741747653930584250892369786198727235518"},
+		{"2668273036814444928945193217157269437704588546626005256888038757416021100327225242961791752752677109358596181706524", "1334136518407222464472596608578634718852294273313002628444019378708010550163612621480895876376338554679298090853263"},
+		{"2816510427748580758331037284777117739799287910327449993381818688383577828123182200904113516794492504322962636245776", "0"},
+	}
+
+	// coefficients of the monic y denominator, in increasing degree and without
+	// the leading one
+	g2IsogenyYDen = [][2]string{
+		{"4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559355", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559355"},
+		{"0", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559571"},
+		{"18", "4002409555221667393417789825735904156556882819939007885332058136124031650490837864442687629129015664037894272559769"},
+	}
+)
+
+// e2ValueOf returns the constant a0 + a1·i.
+func e2ValueOf(a [2]string) *fields_bls12381.E2 {
+	return &fields_bls12381.E2{
+		A0: emulated.ValueOf[BaseField](a[0]),
+		A1: emulated.ValueOf[BaseField](a[1]),
+	}
+}
+
+// MapToG2 maps the element u of Fp2 to a point in G2 as defined by the
+// map_to_curve and clear_cofactor functions of the BLS12381G2_XMD:SHA-256_SSWU_
+// suites of RFC 9380. The result matches [bls12381.MapToG2] and the
+// MAP_FP2_TO_G2 precompile of EIP-2537.
+//
+// [bls12381.MapToG2]: https://pkg.go.dev/github.com/consensys/gnark-crypto/ecc/bls12-381#MapToG2
+func (g2 *G2) MapToG2(u *fields_bls12381.E2) *G2Affine {
+	P := g2.mapToCurve2(u)
+	P = g2.isogeny(P)
+	return g2.clearCofactor(P)
+}
+
+// mapToCurve2 implements the simplified SWU map to the isogenous curve E2'.
+// The square root and the quadratic residuosity are computed with hints and
+// verified in-circuit.
+func (g2 *G2) mapToCurve2(u *fields_bls12381.E2) *G2Affine {
+	A := e2ValueOf(g2SswuA)
+	B := e2ValueOf(g2SswuB)
+	Z := e2ValueOf(g2SswuZ)
+
+	// tv1 = Z·u²
+	tv1 := g2.Ext2.Square(u)
+	tv1 = g2.Ext2.Mul(tv1, Z)
+	// den = tv1² + tv1, which is zero only if u = 0 as -1/Z is not a square
+	den := g2.Ext2.Square(tv1)
+	den = g2.Ext2.Add(den, tv1)
+	isDenZero := g2.Ext2.IsZero(den)
+
+	// x1 = -B·(den+1)/(A·den), or B/(Z·A) in the exceptional case den = 0
+	x1Num := g2.Ext2.Mul(B, g2.Ext2.Add(den, g2.Ext2.One()))
+	x1Num = g2.Ext2.Select(isDenZero, B, x1Num)
+	x1Den := g2.Ext2.Neg(g2.Ext2.Mul(A, den))
+	x1Den = g2.Ext2.Select(isDenZero, g2.Ext2.Mul(Z, A), x1Den)
+	x1 := g2.Ext2.DivUnchecked(x1Num, x1Den)
+	gx1 := g2.evalIsogenousCurve(x1, A, B)
+
+	// x2 = Z·u²·x1
+	x2 := g2.Ext2.Mul(tv1, x1)
+	gx2 := g2.evalIsogenousCurve(x2, A, B)
+
+	// exactly one of gx1 and gx2 is a square when den ≠ 0, and Z is chosen
+	// such that gx1 is a square otherwise. An element of Fp2 is a square if and
+	// only if its norm is a square in Fp. The square root assertion below
+	// ensures that the hinted residuosity is correct.
+	norm := g2.curveF.Add(g2.curveF.Mul(&gx1.A0, &gx1.A0), g2.curveF.Mul(&gx1.A1, &gx1.A1))
+	res, err := g2.curveF.NewHintWithNativeOutput(isQuadraticResidueHint, 1, norm)
+	if err != nil {
+		panic(fmt.Sprintf("compute quadratic residuosity: %v", err))
+	}
+	isSquare := res[0]
+	g2.api.AssertIsBoolean(isSquare)
+	g2.api.AssertIsEqual(g2.api.Mul(isDenZero, g2.api.Sub(1, isSquare)), 0)
+	x := g2.Ext2.Select(isSquare, x1, x2)
+	gx := g2.Ext2.Select(isSquare, gx1, gx2)
+	y := g2.sqrt(gx)
+
+	// the sign of y must be the same as the sign of u
+	sameSign := g2.api.IsZero(g2.api.Sub(g2.sgn0(y), g2.sgn0(u)))
+	y = g2.Ext2.Select(sameSign, y, g2.Ext2.Neg(y))
+
+	return &G2Affine{P: g2AffP{X: *x, Y: *y}}
+}
+
+// evalIsogenousCurve returns x³ + A·x + B.
+func (g2 *G2) evalIsogenousCurve(x, A, B *fields_bls12381.E2) *fields_bls12381.E2 {
+	res := g2.Ext2.Square(x)
+	res = g2.Ext2.Add(res, A)
+	res = g2.Ext2.Mul(res, x)
+	return g2.Ext2.Add(res, B)
+}
+
+// sqrt returns a square root of x, which must be a square. The root is
+// computed with a hint and checked in-circuit.
+func (g2 *G2) sqrt(x *fields_bls12381.E2) *fields_bls12381.E2 {
+	res, err := g2.curveF.NewHint(sqrtE2Hint, 2, &x.A0, &x.A1)
+	if err != nil {
+		panic(fmt.Sprintf("compute square root: %v", err))
+	}
+	y := &fields_bls12381.E2{A0: *res[0], A1: *res[1]}
+	g2.Ext2.AssertIsEqual(g2.Ext2.Square(y), x)
+	return y
+}
+
+// sgn0 returns the sign of x as defined in RFC 9380 (Section 4.1) for Fp2: the
+// parity of the canonical representation of x.A0, or of x.A1 if x.A0 is zero.
+func (g2 *G2) sgn0(x *fields_bls12381.E2) frontend.Variable {
+	fp := g2.curveF
+	a0 := fp.Reduce(&x.A0)
+	fp.AssertIsInRange(a0)
+	a1 := fp.Reduce(&x.A1)
+	fp.AssertIsInRange(a1)
+	sign0 := fp.ToBits(a0)[0]
+	sign1 := fp.ToBits(a1)[0]
+	return g2.api.Or(sign0, g2.api.And(fp.IsZero(a0), sign1))
+}
+
+// isogeny maps the point P of the isogenous curve E2' to the twist E2. The
+// points in the kernel of the isogeny are mapped to the point at infinity
+// (0,0).
+func (g2 *G2) isogeny(P *G2Affine) *G2Affine {
+	xNum := g2.evalPolynomial(g2IsogenyXNum, false, &P.P.X)
+	xDen := g2.evalPolynomial(g2IsogenyXDen, true, &P.P.X)
+	yNum := g2.evalPolynomial(g2IsogenyYNum, false, &P.P.X)
+	yNum = g2.Ext2.Mul(yNum, &P.P.Y)
+	yDen := g2.evalPolynomial(g2IsogenyYDen, true, &P.P.X)
+
+	isInfinity := g2.api.Or(g2.Ext2.IsZero(xDen), g2.Ext2.IsZero(yDen))
+	one := g2.Ext2.One()
+	xDen = g2.Ext2.Select(isInfinity, one, xDen)
+	yDen = g2.Ext2.Select(isInfinity, one, yDen)
+	x := g2.Ext2.DivUnchecked(xNum, xDen)
+	y := g2.Ext2.DivUnchecked(yNum, yDen)
+	zero := g2.Ext2.Zero()
+	return &G2Affine{
+		P: g2AffP{
+			X: *g2.Ext2.Select(isInfinity, zero, x),
+			Y: *g2.Ext2.Select(isInfinity, zero, y),
+		},
+	}
+}
+
+// evalPolynomial evaluates the polynomial with the given coefficients in
+// increasing degree at x. If monic is set, then the leading coefficient is one
+// and is omitted from coeffs.
+func (g2 *G2) evalPolynomial(coeffs [][2]string, monic bool, x *fields_bls12381.E2) *fields_bls12381.E2 {
+	res := e2ValueOf(coeffs[len(coeffs)-1])
+	if monic {
+		res = g2.Ext2.Add(res, x)
+	}
+	for i := len(coeffs) - 2; i >= 0; i-- {
+		res = g2.Ext2.Mul(res, x)
+		res = g2.Ext2.Add(res, e2ValueOf(coeffs[i]))
+	}
+	return res
+}
+
+// clearCofactor computes [h_eff]P with the endomorphism ψ as in RFC 9380
+// (Appendix G.3), using the unified addition formulas:
+//
+//	[h_eff]P = [x₀² - x₀ - 1]P + [x₀ - 1]ψ(P) + ψ²([2]P)
+func (g2 *G2) clearCofactor(P *G2Affine) *G2Affine {
+	t1 := g2.scalarMulBySeedUnified(P)
+	t2 := g2.psi(P)
+	t3 := g2.AddUnified(P, P)
+	t3 = g2.psi(g2.psi(t3))
+	t3 = g2.AddUnified(t3, g2.neg(t2))
+	t2 = g2.AddUnified(t1, t2)
+	t2 = g2.scalarMulBySeedUnified(t2)
+	t3 = g2.AddUnified(t3, t2)
+	t3 = g2.AddUnified(t3, g2.neg(t1))
+	return g2.AddUnified(t3, g2.neg(P))
+}
@@ -0,0 +1,39 @@
+package sw_bls12381
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/test"
+)
+
+type mapToG2Circuit struct {
+	In  fields_bls12381.E2
+	Res G2Affine
+}
+
+func (c *mapToG2Circuit) Define(api frontend.API) error {
+	g2 := NewG2(api)
+	res := g2.MapToG2(&c.In)
+	g2.AssertIsEqual(res, &c.Res)
+	return nil
+}
+
+func TestMapToG2TestSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u, zero, one bls12381.E2
+	u.SetRandom()
+	one.SetOne()
+	for _, in := range []bls12381.E2{u, zero, one} {
+		res := bls12381.MapToG2(in)
+		witness := mapToG2Circuit{
+			In:  fields_bls12381.FromE2(&in),
+			Res: NewG2Affine(res),
+		}
+		err := test.IsSolved(&mapToG2Circuit{}, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, in.String())
+	}
+}
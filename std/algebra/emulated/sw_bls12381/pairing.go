@@ -307,6 +307,91 @@ func (pr Pairing) AssertIsOnG2(Q *G2Affine) {
 	pr.g2.AssertIsEqual(xQ, psiQ)
 }
 
+// IsOnCurve returns a boolean indicating if P is on the curve or is the point
+// at infinity (0,0).
+func (pr Pairing) IsOnCurve(P *G1Affine) frontend.Variable {
+	// Curve: Y² == X³ + aX + b, where a=0 and b=4
+	// (X,Y) ∈ {Y² == X³ + aX + b} U (0,0)
+
+	// if P=(0,0) we assign b=0 otherwise 4, and continue
+	selector := pr.api.And(pr.curveF.IsZero(&P.X), pr.curveF.IsZero(&P.Y))
+	four := emulated.ValueOf[BaseField](4)
+	b := pr.curveF.Select(selector, pr.curveF.Zero(), &four)
+
+	left := pr.curveF.Mul(&P.Y, &P.Y)
+	right := pr.curveF.Mul(&P.X, &P.X)
+	right = pr.curveF.Mul(right, &P.X)
+	right = pr.curveF.Add(right, b)
+	return pr.curveF.IsZero(pr.curveF.Sub(left, right))
+}
+
+// IsOnTwist returns a boolean indicating if Q is on the twist or is the point
+// at infinity (0,0).
+func (pr Pairing) IsOnTwist(Q *G2Affine) frontend.Variable {
+	// Twist: Y² == X³ + aX + b, where a=0 and b=4(1+u)
+	// (X,Y) ∈ {Y² == X³ + aX + b} U (0,0)
+
+	// if Q=(0,0) we assign b=0 otherwise 4(1+u), and continue
+	selector := pr.api.And(pr.Ext2.IsZero(&Q.P.X), pr.Ext2.IsZero(&Q.P.Y))
+	b := pr.Ext2.Select(selector, pr.Ext2.Zero(), pr.bTwist)
+
+	left := pr.Ext2.Square(&Q.P.Y)
+	right := pr.Ext2.Square(&Q.P.X)
+	right = pr.Ext2.Mul(right, &Q.P.X)
+	right = pr.Ext2.Add(right, b)
+	return pr.Ext2.IsZero(pr.Ext2.Sub(left, right))
+}
+
+// IsOnG1 returns a boolean indicating if P is on the curve and in the prime
+// order subgroup G1. The point at infinity (0,0) is in G1. Contrary to
+// [Pairing.AssertIsOnG1], the check is defined for any input, so that it can be
+// used to prove that a point is not in G1.
+func (pr Pairing) IsOnG1(P *G1Affine) frontend.Variable {
+	// 1- Check P is on the curve
+	isOnCurve := pr.IsOnCurve(P)
+
+	// if P is not on the curve, we continue with the generator to keep the
+	// computations well defined.
+	_P := pr.curve.Select(isOnCurve, P, pr.curve.Generator())
+
+	// 2- Check P has the right subgroup order
+	// [r]Q == 0 <==>  P = -[x²]ϕ(P)
+	phiP := pr.g1.phi(_P)
+	xP := pr.g1.scalarMulBySeedSquareUnified(phiP)
+	xP = pr.curve.Neg(xP)
+	xEq := pr.curveF.IsZero(pr.curveF.Sub(&xP.X, &_P.X))
+	yEq := pr.curveF.IsZero(pr.curveF.Sub(&xP.Y, &_P.Y))
+	return pr.api.And(isOnCurve, pr.api.And(xEq, yEq))
+}
+
+// IsOnG2 returns a boolean indicating if Q is on the twist and in the prime
+// order subgroup G2. The point at infinity (0,0) is in G2. Contrary to
+// [Pairing.AssertIsOnG2], the check is defined for any input, so that it can be
+// used to prove that a point is not in G2.
+func (pr Pairing) IsOnG2(Q *G2Affine) frontend.Variable {
+	// 1- Check Q is on the twist
+	isOnTwist := pr.IsOnTwist(Q)
+
+	// if Q is not on the twist, we continue with the generator to keep the
+	// computations well defined.
+	_, _, _, g2gen := bls12381.Generators()
+	gen := NewG2Affine(g2gen)
+	_Q := pr.g2.Select(isOnTwist, Q, &gen)
+
+	// 2- Check Q has the right subgroup order
+	// [r]Q == 0 <==>  ψ(Q) == [x₀]Q
+	xQ := pr.g2.scalarMulBySeedUnified(_Q)
+	psiQ := pr.g2.psi(_Q)
+	return pr.api.And(isOnTwist, pr.g2.IsEqual(xQ, psiQ))
+}
+
+// seedAbs is the absolute value of the curve seed x₀ = -15132376222941642752
+// and seedSquare its square.
+var (
+	seedAbs, _    = new(big.Int).SetString("15132376222941642752", 10)
+	seedSquare, _ = new(big.Int).SetString("228988810152649578064853576960394133504", 10)
+)
+
 // loopCounter = seed in binary
 //
 //	seed=-15132376222941642752
@@ -226,6 +226,90 @@ func TestGroupMembershipSolve(t *testing.T) {
 	assert.NoError(err)
 }
 
+type IsOnGroupCircuit struct {
+	InG1           G1Affine
+	InG2           G2Affine
+	IsOnG1, IsOnG2 frontend.Variable
+	IsOnCurve      frontend.Variable
+	IsOnTwist      frontend.Variable
+}
+
+func (c *IsOnGroupCircuit) Define(api frontend.API) error {
+	pairing, err := NewPairing(api)
+	if err != nil {
+		return fmt.Errorf("new pairing: %w", err)
+	}
+	api.AssertIsEqual(pairing.IsOnCurve(&c.InG1), c.IsOnCurve)
+	api.AssertIsEqual(pairing.IsOnTwist(&c.InG2), c.IsOnTwist)
+	api.AssertIsEqual(pairing.IsOnG1(&c.InG1), c.IsOnG1)
+	api.AssertIsEqual(pairing.IsOnG2(&c.InG2), c.IsOnG2)
+	return nil
+}
+
+// randomCurvePoints returns points on the curve and on the twist which are not
+// in the prime order subgroups.
+func randomCurvePoints() (p bls12381.G1Affine, q bls12381.G2Affine) {
+	var b bls12381.G2Affine
+	b.X.A0.SetUint64(4)
+	b.X.A1.SetUint64(4)
+	b.Y.A0.SetUint64(4)
+	for {
+		p.X.SetRandom()
+		p.Y.Square(&p.X).Mul(&p.Y, &p.X).Add(&p.Y, &b.Y.A0)
+		if p.Y.Legendre() == 1 {
+			p.Y.Sqrt(&p.Y)
+			break
+		}
+	}
+	for {
+		q.X.SetRandom()
+		q.Y.Square(&q.X).Mul(&q.Y, &q.X).Add(&q.Y, &b.X)
+		if q.Y.Legendre() == 1 {
+			q.Y.Sqrt(&q.Y)
+			break
+		}
+	}
+	return
+}
+
+func TestIsOnGroupSolve(t *testing.T) {
+	assert := test.NewAssert(t)
+	// points in the subgroups
+	p, q := randomG1G2Affines()
+	witness := IsOnGroupCircuit{
+		InG1: NewG1Affine(p), InG2: NewG2Affine(q),
+		IsOnG1: 1, IsOnG2: 1, IsOnCurve: 1, IsOnTwist: 1,
+	}
+	err := test.IsSolved(&IsOnGroupCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points at infinity
+	witness.InG1 = NewG1Affine(bls12381.G1Affine{})
+	witness.InG2 = NewG2Affine(bls12381.G2Affine{})
+	err = test.IsSolved(&IsOnGroupCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points on the curves but not in the subgroups
+	p, q = randomCurvePoints()
+	witness = IsOnGroupCircuit{
+		InG1: NewG1Affine(p), InG2: NewG2Affine(q),
+		IsOnG1: 0, IsOnG2: 0, IsOnCurve: 1, IsOnTwist: 1,
+	}
+	err = test.IsSolved(&IsOnGroupCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points not on the curves
+	p, q = randomG1G2Affines()
+	p.Y.Double(&p.Y)
+	q.Y.Double(&q.Y)
+	witness = IsOnGroupCircuit{
+		InG1: NewG1Affine(p), InG2: NewG2Affine(q),
+		IsOnG1: 0, IsOnG2: 0, IsOnCurve: 0, IsOnTwist: 0,
+	}
+	err = test.IsSolved(&IsOnGroupCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
 // bench
 func BenchmarkPairing(b *testing.B) {
 
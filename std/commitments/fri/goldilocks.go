@@ -0,0 +1,289 @@
+package fri
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_goldilocks"
+	"github.com/consensys/gnark/std/hash/poseidon_goldilocks"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+// goldilocksTwoAdicity is the largest k such that 2^k divides p-1 for the
+// Goldilocks modulus p.
+const goldilocksTwoAdicity = 32
+
+// GoldilocksMultiplicativeGenerator generates the multiplicative group of the
+// Goldilocks field. It is also used as the shift of the evaluation domain.
+const GoldilocksMultiplicativeGenerator = 7
+
+var (
+	ErrInvalidParameters = errors.New("fri: invalid parameters")
+	ErrInvalidProofShape = errors.New("fri: proof does not match the parameters")
+)
+
+// GoldilocksParams describe an instance of the FRI protocol over the Goldilocks
+// field in the style of Plonky2.
+type GoldilocksParams struct {
+	// LogDegree is the logarithm of the number of coefficients of the
+	// committed polynomial.
+	LogDegree int
+	// RateBits is the logarithm of the blowup factor. The evaluation domain
+	// has size 2^(LogDegree+RateBits).
+	RateBits int
+	// NbQueries is the number of queries.
+	NbQueries int
+	// LogFinalPolyLen is the logarithm of the number of coefficients of the
+	// final polynomial, which is sent in clear.
+	LogFinalPolyLen int
+}
+
+// nbFoldings returns the number of committed layers.
+func (p GoldilocksParams) nbFoldings() int {
+	return p.LogDegree - p.LogFinalPolyLen
+}
+
+// logDomainSize returns the logarithm of the size of the first evaluation
+// domain.
+func (p GoldilocksParams) logDomainSize() int {
+	return p.LogDegree + p.RateBits
+}
+
+func (p GoldilocksParams) check() error {
+	if p.LogDegree < 1 || p.RateBits < 1 || p.NbQueries < 1 ||
+		p.LogFinalPolyLen < 0 || p.LogFinalPolyLen >= p.LogDegree ||
+		p.logDomainSize() > goldilocksTwoAdicity {
+		return ErrInvalidParameters
+	}
+	return nil
+}
+
+// GoldilocksQueryStep is the opening of a committed layer at a query.
+type GoldilocksQueryStep struct {
+	// Evals stores the evaluations at the points x and -x of the layer,
+	// which form a single Merkle leaf.
+	Evals [2]fields_goldilocks.E2
+	// MerkleProof stores the siblings from the leaf to the root.
+	MerkleProof []poseidon_goldilocks.HashOut
+}
+
+// GoldilocksQueryRound stores the openings of every layer at a query.
+type GoldilocksQueryRound struct {
+	Steps []GoldilocksQueryStep
+}
+
+// GoldilocksProof is a FRI proof of proximity over the quadratic extension of
+// the Goldilocks field.
+//
+// The committed polynomial f₀ of degree less than 2^LogDegree is evaluated on
+// the coset g·H of the subgroup H of size N = 2^(LogDegree+RateBits), where g
+// is [GoldilocksMultiplicativeGenerator]. The evaluations are stored in
+// bit-reversed order so that the points x and -x are adjacent, and a Merkle
+// tree with Poseidon is built where every leaf contains such a pair. At every
+// layer the polynomial is folded with a challenge β as
+//
+//	fᵢ₊₁(x²) = (fᵢ(x) + fᵢ(-x))/2 + β·(fᵢ(x) - fᵢ(-x))/(2x)
+//
+// and the last folded polynomial is sent in clear.
+type GoldilocksProof struct {
+	// Commitments are the Merkle roots of the layers, starting with the
+	// committed polynomial.
+	Commitments []poseidon_goldilocks.HashOut
+	// QueryRounds stores the openings for every query.
+	QueryRounds []GoldilocksQueryRound
+	// FinalPoly stores the coefficients of the last folded polynomial.
+	FinalPoly []fields_goldilocks.E2
+}
+
+// PlaceholderGoldilocksProof returns a proof with the slices allocated
+// according to params, to be used when defining a circuit.
+func PlaceholderGoldilocksProof(params GoldilocksParams) GoldilocksProof {
+	nbFoldings := params.nbFoldings()
+	proof := GoldilocksProof{
+		Commitments: make([]poseidon_goldilocks.HashOut, nbFoldings),
+		QueryRounds: make([]GoldilocksQueryRound, params.NbQueries),
+		FinalPoly:   make([]fields_goldilocks.E2, 1<<params.LogFinalPolyLen),
+	}
+	for i := range proof.QueryRounds {
+		proof.QueryRounds[i].Steps = make([]GoldilocksQueryStep, nbFoldings)
+		for j := range proof.QueryRounds[i].Steps {
+			proof.QueryRounds[i].Steps[j].MerkleProof = make([]poseidon_goldilocks.HashOut, params.logDomainSize()-j-1)
+		}
+	}
+	return proof
+}
+
+// GoldilocksFri is the in-circuit verifier of [GoldilocksProof]. The
+// Goldilocks field is emulated, so it can be used to verify Plonky2-style
+// proofs in circuits over any native field.
+type GoldilocksFri struct {
+	fp     *emulated.Field[emulated.Goldilocks]
+	ext    *fields_goldilocks.Ext2
+	h      *poseidon_goldilocks.Poseidon
+	params GoldilocksParams
+
+	// shift is the shift of the first evaluation domain.
+	shift goldilocks.Element
+	// rootPowers stores ω^(2^(n-1-i)) where ω generates the first evaluation
+	// domain of size 2^n.
+	rootPowers []goldilocks.Element
+}
+
+// NewGoldilocksFri returns a new verifier for the given parameters using the
+// default Poseidon parameters for hashing.
+func NewGoldilocksFri(api frontend.API, params GoldilocksParams) (*GoldilocksFri, error) {
+	h, err := poseidon_goldilocks.NewPoseidon(api)
+	if err != nil {
+		return nil, err
+	}
+	return NewGoldilocksFriWithHasher(api, params, h)
+}
+
+// NewGoldilocksFriWithHasher returns a new verifier for the given parameters
+// using h for hashing.
+func NewGoldilocksFriWithHasher(api frontend.API, params GoldilocksParams, h *poseidon_goldilocks.Poseidon) (*GoldilocksFri, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	fp, err := emulated.NewField[emulated.Goldilocks](api)
+	if err != nil {
+		return nil, err
+	}
+	res := &GoldilocksFri{
+		fp:     fp,
+		ext:    fields_goldilocks.NewExt2(api),
+		h:      h,
+		params: params,
+	}
+	res.shift.SetUint64(GoldilocksMultiplicativeGenerator)
+	n := params.logDomainSize()
+	res.rootPowers = make([]goldilocks.Element, n)
+	res.rootPowers[n-1] = GoldilocksRootOfUnity(n)
+	for i := n - 2; i >= 0; i-- {
+		res.rootPowers[i].Square(&res.rootPowers[i+1])
+	}
+	return res, nil
+}
+
+// GoldilocksRootOfUnity returns the generator ω of the subgroup of size 2^logN
+// of the Goldilocks field used for the evaluation domains.
+func GoldilocksRootOfUnity(logN int) goldilocks.Element {
+	var g goldilocks.Element
+	g.SetUint64(GoldilocksMultiplicativeGenerator)
+	e := new(big.Int).Sub(goldilocks.Modulus(), big.NewInt(1))
+	e.Rsh(e, uint(logN))
+	return *g.Exp(g, e)
+}
+
+// VerifyProofOfProximity verifies the proof, deriving the challenges from a
+// fresh Poseidon sponge.
+func (f *GoldilocksFri) VerifyProofOfProximity(proof GoldilocksProof) error {
+	return f.VerifyProofOfProximityWithSponge(poseidon_goldilocks.NewSpongeFromPermutation(f.h), proof)
+}
+
+// VerifyProofOfProximityWithSponge verifies the proof, deriving the challenges
+// from the sponge s. It allows binding the challenges to the rest of a larger
+// protocol.
+func (f *GoldilocksFri) VerifyProofOfProximityWithSponge(s *poseidon_goldilocks.Sponge, proof GoldilocksProof) error {
+	if err := f.checkShape(proof); err != nil {
+		return err
+	}
+
+	// commit phase: derive the folding challenges
+	nbFoldings := f.params.nbFoldings()
+	betas := make([]*fields_goldilocks.E2, nbFoldings)
+	for i := 0; i < nbFoldings; i++ {
+		s.AbsorbHash(proof.Commitments[i])
+		a0 := s.Squeeze()
+		a1 := s.Squeeze()
+		betas[i] = &fields_goldilocks.E2{A0: *a0, A1: *a1}
+	}
+	for i := range proof.FinalPoly {
+		s.Absorb(&proof.FinalPoly[i].A0, &proof.FinalPoly[i].A1)
+	}
+
+	// query phase
+	for i := range proof.QueryRounds {
+		idx := s.Squeeze()
+		idx = f.fp.Reduce(idx)
+		f.fp.AssertIsInRange(idx)
+		idxBits := f.fp.ToBits(idx)[:f.params.logDomainSize()]
+		f.verifyQueryRound(idxBits, betas, proof.Commitments, proof.FinalPoly, proof.QueryRounds[i])
+	}
+	return nil
+}
+
+func (f *GoldilocksFri) checkShape(proof GoldilocksProof) error {
+	nbFoldings := f.params.nbFoldings()
+	if len(proof.Commitments) != nbFoldings ||
+		len(proof.QueryRounds) != f.params.NbQueries ||
+		len(proof.FinalPoly) != 1<<f.params.LogFinalPolyLen {
+		return ErrInvalidProofShape
+	}
+	for i := range proof.QueryRounds {
+		if len(proof.QueryRounds[i].Steps) != nbFoldings {
+			return ErrInvalidProofShape
+		}
+		for j := range proof.QueryRounds[i].Steps {
+			if len(proof.QueryRounds[i].Steps[j].MerkleProof) != f.params.logDomainSize()-j-1 {
+				return ErrInvalidProofShape
+			}
+		}
+	}
+	return nil
+}
+
+// verifyQueryRound checks the openings at the position given by idxBits
+// (little-endian) of the first evaluation domain.
+func (f *GoldilocksFri) verifyQueryRound(idxBits []frontend.Variable, betas []*fields_goldilocks.E2, commitments []poseidon_goldilocks.HashOut, finalPoly []fields_goldilocks.E2, round GoldilocksQueryRound) {
+	// the point at position idx in bit-reversed order is shift*ω^rev(idx),
+	// where the i-th bit of idx contributes ω^(2^(n-1-i)).
+	one := f.fp.One()
+	shift := emulated.ValueOf[emulated.Goldilocks](f.shift)
+	point := &shift
+	for i := range idxBits {
+		c := emulated.ValueOf[emulated.Goldilocks](f.rootPowers[i])
+		point = f.fp.Mul(point, f.fp.Select(idxBits[i], &c, one))
+	}
+
+	var half goldilocks.Element
+	half.SetUint64(2).Inverse(&half)
+	halfEl := emulated.ValueOf[emulated.Goldilocks](half)
+
+	var folded *fields_goldilocks.E2
+	for i, step := range round.Steps {
+		// the position in the i-th layer is idx >> i, the lowest bit selects
+		// the evaluation in the leaf and the others the leaf.
+		slot := idxBits[i]
+		leafBits := idxBits[i+1:]
+
+		if folded != nil {
+			f.ext.AssertIsEqual(f.ext.Select(slot, &step.Evals[1], &step.Evals[0]), folded)
+		}
+
+		f.verifyMerkleProof(commitments[i], step, leafBits)
+
+		// x is the point of the first evaluation of the leaf
+		x := f.fp.Select(slot, f.fp.Neg(point), point)
+		sum := f.ext.Add(&step.Evals[0], &step.Evals[1])
+		diff := f.ext.Sub(&step.Evals[0], &step.Evals[1])
+		diff = f.ext.MulByElement(f.ext.Mul(diff, betas[i]), f.fp.Inverse(x))
+		folded = f.ext.MulByElement(f.ext.Add(sum, diff), &halfEl)
+
+		point = f.fp.Mul(point, point)
+	}
+
+	f.ext.AssertIsEqual(f.ext.EvalPolynomial(finalPoly, point), folded)
+}
+
+func (f *GoldilocksFri) verifyMerkleProof(root poseidon_goldilocks.HashOut, step GoldilocksQueryStep, leafBits []frontend.Variable) {
+	node := f.h.HashOrNoop(&step.Evals[0].A0, &step.Evals[0].A1, &step.Evals[1].A0, &step.Evals[1].A1)
+	for i, sibling := range step.MerkleProof {
+		left := f.h.Select(leafBits[i], sibling, node)
+		right := f.h.Select(leafBits[i], node, sibling)
+		node = f.h.TwoToOne(left, right)
+	}
+	f.h.AssertIsEqual(node, root)
+}
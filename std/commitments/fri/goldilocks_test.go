@@ -0,0 +1,219 @@
+package fri
+
+import (
+	"math/big"
+	"math/bits"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_goldilocks"
+	"github.com/consensys/gnark/std/hash/poseidon_goldilocks"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+// gE2 is a native element of the quadratic extension of the Goldilocks field.
+type gE2 struct {
+	a0, a1 goldilocks.Element
+}
+
+func (z *gE2) add(x, y *gE2) *gE2 {
+	z.a0.Add(&x.a0, &y.a0)
+	z.a1.Add(&x.a1, &y.a1)
+	return z
+}
+
+func (z *gE2) mul(x, y *gE2) *gE2 {
+	var w, a, b, c goldilocks.Element
+	w.SetUint64(fields_goldilocks.W)
+	a.Mul(&x.a0, &y.a0)
+	b.Mul(&x.a1, &y.a1)
+	b.Mul(&b, &w)
+	c.Mul(&x.a0, &y.a1)
+	z.a1.Mul(&x.a1, &y.a0)
+	z.a1.Add(&z.a1, &c)
+	z.a0.Add(&a, &b)
+	return z
+}
+
+func (z *gE2) mulByElement(x *gE2, y *goldilocks.Element) *gE2 {
+	z.a0.Mul(&x.a0, y)
+	z.a1.Mul(&x.a1, y)
+	return z
+}
+
+func evalPolynomial(coeffs []gE2, x *goldilocks.Element) gE2 {
+	var res gE2
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		res.mulByElement(&res, x)
+		res.add(&res, &coeffs[i])
+	}
+	return res
+}
+
+func toE2(x gE2) fields_goldilocks.E2 {
+	return fields_goldilocks.E2{
+		A0: emulated.ValueOf[emulated.Goldilocks](x.a0),
+		A1: emulated.ValueOf[emulated.Goldilocks](x.a1),
+	}
+}
+
+func toHashOut(d [poseidon_goldilocks.DigestSize]goldilocks.Element) poseidon_goldilocks.HashOut {
+	var res poseidon_goldilocks.HashOut
+	for i := range d {
+		res[i] = emulated.ValueOf[emulated.Goldilocks](d[i])
+	}
+	return res
+}
+
+// goldilocksMerkleTree stores the nodes of a Merkle tree, layer by layer
+// starting from the leaves.
+type goldilocksMerkleTree [][][poseidon_goldilocks.DigestSize]goldilocks.Element
+
+func newGoldilocksMerkleTree(h *poseidon_goldilocks.Parameters, evals []gE2) goldilocksMerkleTree {
+	nodes := make([][poseidon_goldilocks.DigestSize]goldilocks.Element, len(evals)/2)
+	for j := range nodes {
+		nodes[j] = h.HashOrNoop([]goldilocks.Element{evals[2*j].a0, evals[2*j].a1, evals[2*j+1].a0, evals[2*j+1].a1})
+	}
+	tree := goldilocksMerkleTree{nodes}
+	for len(nodes) > 1 {
+		next := make([][poseidon_goldilocks.DigestSize]goldilocks.Element, len(nodes)/2)
+		for j := range next {
+			next[j] = h.TwoToOne(nodes[2*j], nodes[2*j+1])
+		}
+		tree = append(tree, next)
+		nodes = next
+	}
+	return tree
+}
+
+func (t goldilocksMerkleTree) root() [poseidon_goldilocks.DigestSize]goldilocks.Element {
+	return t[len(t)-1][0]
+}
+
+func (t goldilocksMerkleTree) proof(leaf uint64) []poseidon_goldilocks.HashOut {
+	res := make([]poseidon_goldilocks.HashOut, len(t)-1)
+	for i := range res {
+		res[i] = toHashOut(t[i][leaf^1])
+		leaf >>= 1
+	}
+	return res
+}
+
+// proveGoldilocks builds a proof of proximity for the polynomial with the given
+// coefficients. It is the native counterpart of [GoldilocksFri].
+func proveGoldilocks(params GoldilocksParams, coeffs []gE2) GoldilocksProof {
+	h := poseidon_goldilocks.GetDefaultParameters()
+	sponge := poseidon_goldilocks.NewNativeSponge(h)
+	proof := PlaceholderGoldilocksProof(params)
+	n := params.logDomainSize()
+
+	// commit phase
+	var shift goldilocks.Element
+	shift.SetUint64(GoldilocksMultiplicativeGenerator)
+	omega := GoldilocksRootOfUnity(n)
+	layers := make([][]gE2, params.nbFoldings())
+	trees := make([]goldilocksMerkleTree, params.nbFoldings())
+	for i := range layers {
+		size := uint64(1) << (n - i)
+		layers[i] = make([]gE2, size)
+		for k := range layers[i] {
+			var x goldilocks.Element
+			x.Exp(omega, new(big.Int).SetUint64(bits.Reverse64(uint64(k))>>(64-n+i)))
+			x.Mul(&x, &shift)
+			layers[i][k] = evalPolynomial(coeffs, &x)
+		}
+		trees[i] = newGoldilocksMerkleTree(h, layers[i])
+		root := trees[i].root()
+		proof.Commitments[i] = toHashOut(root)
+		sponge.Absorb(root[:]...)
+		var beta gE2
+		beta.a0 = sponge.Squeeze()
+		beta.a1 = sponge.Squeeze()
+
+		folded := make([]gE2, len(coeffs)/2)
+		for k := range folded {
+			folded[k].mul(&beta, &coeffs[2*k+1])
+			folded[k].add(&folded[k], &coeffs[2*k])
+		}
+		coeffs = folded
+		shift.Square(&shift)
+		omega.Square(&omega)
+	}
+	for i := range coeffs {
+		proof.FinalPoly[i] = toE2(coeffs[i])
+		sponge.Absorb(coeffs[i].a0, coeffs[i].a1)
+	}
+
+	// query phase
+	for q := range proof.QueryRounds {
+		e := sponge.Squeeze()
+		idx := e.Uint64() & (1<<n - 1)
+		for i := range layers {
+			pos := idx >> i
+			leaf := pos >> 1
+			step := &proof.QueryRounds[q].Steps[i]
+			step.Evals[0] = toE2(layers[i][2*leaf])
+			step.Evals[1] = toE2(layers[i][2*leaf+1])
+			step.MerkleProof = trees[i].proof(leaf)
+		}
+	}
+	return proof
+}
+
+type goldilocksFriCircuit struct {
+	params GoldilocksParams
+	Proof  GoldilocksProof
+}
+
+func (c *goldilocksFriCircuit) Define(api frontend.API) error {
+	f, err := NewGoldilocksFri(api, c.params)
+	if err != nil {
+		return err
+	}
+	return f.VerifyProofOfProximity(c.Proof)
+}
+
+func TestGoldilocksFriVerification(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := GoldilocksParams{LogDegree: 3, RateBits: 2, NbQueries: 2, LogFinalPolyLen: 1}
+
+	coeffs := make([]gE2, 1<<params.LogDegree)
+	for i := range coeffs {
+		coeffs[i].a0.SetRandom()
+		coeffs[i].a1.SetRandom()
+	}
+	proof := proveGoldilocks(params, coeffs)
+
+	circuit := goldilocksFriCircuit{params: params, Proof: PlaceholderGoldilocksProof(params)}
+	witness := goldilocksFriCircuit{Proof: proof}
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// tampering with the final polynomial changes the query positions and
+	// breaks the final evaluation check.
+	invalid := proveGoldilocks(params, coeffs)
+	invalid.FinalPoly[0] = toE2(gE2{})
+	err = test.IsSolved(&circuit, &goldilocksFriCircuit{Proof: invalid}, ecc.BN254.ScalarField())
+	assert.Error(err)
+
+	// tampering with an opened evaluation breaks the Merkle proof.
+	invalid = proveGoldilocks(params, coeffs)
+	invalid.QueryRounds[0].Steps[1].Evals[0] = toE2(gE2{})
+	err = test.IsSolved(&circuit, &goldilocksFriCircuit{Proof: invalid}, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+func TestGoldilocksFriParameters(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, params := range []GoldilocksParams{
+		{LogDegree: 3, RateBits: 0, NbQueries: 2, LogFinalPolyLen: 1},
+		{LogDegree: 3, RateBits: 2, NbQueries: 0, LogFinalPolyLen: 1},
+		{LogDegree: 3, RateBits: 2, NbQueries: 2, LogFinalPolyLen: 3},
+		{LogDegree: 30, RateBits: 3, NbQueries: 2, LogFinalPolyLen: 1},
+	} {
+		assert.ErrorIs(params.check(), ErrInvalidParameters)
+	}
+}
@@ -0,0 +1,321 @@
+package fri
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	fiatshamir "github.com/consensys/gnark/std/fiat-shamir"
+	"github.com/consensys/gnark/std/hash"
+)
+
+// NativeParams describe an instance of the FRI protocol over the native field
+// of the circuit.
+type NativeParams struct {
+	// LogDegree is the logarithm of the number of coefficients of the
+	// committed polynomial.
+	LogDegree int
+	// RateBits is the logarithm of the blowup factor. The evaluation domain
+	// has size 2^(LogDegree+RateBits).
+	RateBits int
+	// NbQueries is the number of queries.
+	NbQueries int
+	// LogFinalPolyLen is the logarithm of the number of coefficients of the
+	// final polynomial, which is sent in clear.
+	LogFinalPolyLen int
+}
+
+// nbFoldings returns the number of committed layers.
+func (p NativeParams) nbFoldings() int {
+	return p.LogDegree - p.LogFinalPolyLen
+}
+
+// logDomainSize returns the logarithm of the size of the first evaluation
+// domain.
+func (p NativeParams) logDomainSize() int {
+	return p.LogDegree + p.RateBits
+}
+
+func (p NativeParams) check() error {
+	if p.LogDegree < 1 || p.RateBits < 1 || p.NbQueries < 1 ||
+		p.LogFinalPolyLen < 0 || p.LogFinalPolyLen >= p.LogDegree {
+		return ErrInvalidParameters
+	}
+	return nil
+}
+
+// NativeQueryStep is the opening of a committed layer at a query.
+type NativeQueryStep struct {
+	// Evals stores the evaluations at the points x and -x of the layer,
+	// which form a single Merkle leaf.
+	Evals [2]frontend.Variable
+	// MerkleProof stores the siblings from the leaf to the root.
+	MerkleProof []frontend.Variable
+}
+
+// NativeQueryRound stores the openings of every layer at a query.
+type NativeQueryRound struct {
+	Steps []NativeQueryStep
+}
+
+// NativeProof is a FRI proof of proximity over the native field.
+//
+// It follows the same layout as [GoldilocksProof]: the committed polynomial
+// f₀ of degree less than 2^LogDegree is evaluated on a coset g·H of the
+// subgroup H of size N = 2^(LogDegree+RateBits), the evaluations are stored in
+// bit-reversed order so that the points x and -x are adjacent, and a Merkle
+// tree is built where every leaf is the hash of such a pair and every node is
+// the hash of its two children. At every layer the polynomial is folded with a
+// challenge β as
+//
+//	fᵢ₊₁(x²) = (fᵢ(x) + fᵢ(-x))/2 + β·(fᵢ(x) - fᵢ(-x))/(2x)
+//
+// and the last folded polynomial is sent in clear.
+type NativeProof struct {
+	// Commitments are the Merkle roots of the layers, starting with the
+	// committed polynomial.
+	Commitments []frontend.Variable
+	// QueryRounds stores the openings for every query.
+	QueryRounds []NativeQueryRound
+	// FinalPoly stores the coefficients of the last folded polynomial.
+	FinalPoly []frontend.Variable
+}
+
+// PlaceholderNativeProof returns a proof with the slices allocated according
+// to params, to be used when defining a circuit.
+func PlaceholderNativeProof(params NativeParams) NativeProof {
+	nbFoldings := params.nbFoldings()
+	proof := NativeProof{
+		Commitments: make([]frontend.Variable, nbFoldings),
+		QueryRounds: make([]NativeQueryRound, params.NbQueries),
+		FinalPoly:   make([]frontend.Variable, 1<<params.LogFinalPolyLen),
+	}
+	for i := range proof.QueryRounds {
+		proof.QueryRounds[i].Steps = make([]NativeQueryStep, nbFoldings)
+		for j := range proof.QueryRounds[i].Steps {
+			proof.QueryRounds[i].Steps[j].MerkleProof = make([]frontend.Variable, params.logDomainSize()-j-1)
+		}
+	}
+	return proof
+}
+
+// NativeQuery is a query of the verifier in the first evaluation domain. It
+// allows protocols built on top of FRI to check that the committed polynomial
+// is consistent with other oracles.
+type NativeQuery struct {
+	// Bits is the little-endian decomposition of the position of the query in
+	// the first evaluation domain, where the evaluations are stored in
+	// bit-reversed order. Bits[0] selects the evaluation in the leaf and
+	// Bits[1:] the leaf.
+	Bits []frontend.Variable
+	// Point is the point of the first evaluation domain at the position.
+	Point frontend.Variable
+	// Eval is the opened evaluation of the committed polynomial at Point.
+	Eval frontend.Variable
+}
+
+// NativeChallengeIDs returns the names of the challenges of the transcript
+// used by [NativeFri.VerifyProofOfProximityWithTranscript], in order. The
+// folding challenge "fri<i>" is bound to the i-th commitment, and the first
+// query challenge "fq0" to the final polynomial.
+func NativeChallengeIDs(params NativeParams) []string {
+	res := make([]string, 0, params.nbFoldings()+params.NbQueries)
+	for i := 0; i < params.nbFoldings(); i++ {
+		res = append(res, fmt.Sprintf("fri%d", i))
+	}
+	for i := 0; i < params.NbQueries; i++ {
+		res = append(res, fmt.Sprintf("fq%d", i))
+	}
+	return res
+}
+
+// NativeFri is the in-circuit verifier of [NativeProof]. The challenges are
+// derived with a hash over the native field, so that the proofs are
+// efficiently verifiable in a circuit over the field they are defined on.
+type NativeFri struct {
+	api    frontend.API
+	h      hash.FieldHasher
+	params NativeParams
+
+	// shift is the shift of the first evaluation domain.
+	shift big.Int
+	// rootPowers stores ω^(2^(n-1-i)) where ω generates the first evaluation
+	// domain of size 2^n.
+	rootPowers []big.Int
+	// half is 1/2 in the native field.
+	half big.Int
+}
+
+// NewNativeFri returns a new verifier for the given parameters using h for
+// hashing. The first evaluation domain is shift·⟨generator⟩, where generator
+// must be of order 2^(LogDegree+RateBits) in the native field.
+func NewNativeFri(api frontend.API, params NativeParams, h hash.FieldHasher, shift, generator *big.Int) (*NativeFri, error) {
+	if err := params.check(); err != nil {
+		return nil, err
+	}
+	modulus := api.Compiler().Field()
+	n := params.logDomainSize()
+	res := &NativeFri{
+		api:    api,
+		h:      h,
+		params: params,
+	}
+	res.shift.Mod(shift, modulus)
+	res.rootPowers = make([]big.Int, n)
+	res.rootPowers[n-1].Mod(generator, modulus)
+	for i := n - 2; i >= 0; i-- {
+		res.rootPowers[i].Mul(&res.rootPowers[i+1], &res.rootPowers[i+1]).Mod(&res.rootPowers[i], modulus)
+	}
+	// the generator must be of order exactly 2ⁿ
+	var last big.Int
+	last.Mul(&res.rootPowers[0], &res.rootPowers[0]).Mod(&last, modulus)
+	if last.Cmp(big.NewInt(1)) != 0 || res.rootPowers[0].Cmp(big.NewInt(1)) == 0 {
+		return nil, ErrInvalidParameters
+	}
+	res.half.ModInverse(big.NewInt(2), modulus)
+	return res, nil
+}
+
+// VerifyProofOfProximity verifies the proof, deriving the challenges from a
+// fresh transcript. It returns the queries of the verifier.
+func (f *NativeFri) VerifyProofOfProximity(proof NativeProof) ([]NativeQuery, error) {
+	f.h.Reset()
+	fs := fiatshamir.NewTranscript(f.api, f.h, NativeChallengeIDs(f.params))
+	return f.VerifyProofOfProximityWithTranscript(fs, proof)
+}
+
+// VerifyProofOfProximityWithTranscript verifies the proof, deriving the
+// challenges from fs. The challenges of [NativeChallengeIDs] must be the last
+// ones of the transcript, which allows binding them to the rest of a larger
+// protocol. It returns the queries of the verifier, the caller is responsible
+// for checking them against the other oracles of its protocol.
+func (f *NativeFri) VerifyProofOfProximityWithTranscript(fs *fiatshamir.Transcript, proof NativeProof) ([]NativeQuery, error) {
+	if err := f.checkShape(proof); err != nil {
+		return nil, err
+	}
+	ids := NativeChallengeIDs(f.params)
+	nbFoldings := f.params.nbFoldings()
+
+	// commit phase: derive the folding challenges
+	betas := make([]frontend.Variable, nbFoldings)
+	for i := 0; i < nbFoldings; i++ {
+		if err := fs.Bind(ids[i], []frontend.Variable{proof.Commitments[i]}); err != nil {
+			return nil, err
+		}
+		var err error
+		if betas[i], err = fs.ComputeChallenge(ids[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := fs.Bind(ids[nbFoldings], proof.FinalPoly); err != nil {
+		return nil, err
+	}
+
+	// query phase
+	res := make([]NativeQuery, f.params.NbQueries)
+	for i := range proof.QueryRounds {
+		c, err := fs.ComputeChallenge(ids[nbFoldings+i])
+		if err != nil {
+			return nil, err
+		}
+		res[i].Bits = f.api.ToBinary(c)[:f.params.logDomainSize()]
+		res[i].Point, res[i].Eval = f.verifyQueryRound(res[i].Bits, betas, proof.Commitments, proof.FinalPoly, proof.QueryRounds[i])
+	}
+	return res, nil
+}
+
+func (f *NativeFri) checkShape(proof NativeProof) error {
+	nbFoldings := f.params.nbFoldings()
+	if len(proof.Commitments) != nbFoldings ||
+		len(proof.QueryRounds) != f.params.NbQueries ||
+		len(proof.FinalPoly) != 1<<f.params.LogFinalPolyLen {
+		return ErrInvalidProofShape
+	}
+	for i := range proof.QueryRounds {
+		if len(proof.QueryRounds[i].Steps) != nbFoldings {
+			return ErrInvalidProofShape
+		}
+		for j := range proof.QueryRounds[i].Steps {
+			if len(proof.QueryRounds[i].Steps[j].MerkleProof) != f.params.logDomainSize()-j-1 {
+				return ErrInvalidProofShape
+			}
+		}
+	}
+	return nil
+}
+
+// QueryPoint returns the point of the first evaluation domain at the position
+// given by idxBits (little-endian), where the evaluations are stored in
+// bit-reversed order.
+func (f *NativeFri) QueryPoint(idxBits []frontend.Variable) frontend.Variable {
+	// the point at position idx in bit-reversed order is shift*ω^rev(idx),
+	// where the i-th bit of idx contributes ω^(2^(n-1-i)).
+	var point frontend.Variable = f.shift
+	for i := range idxBits {
+		point = f.api.Mul(point, f.api.Select(idxBits[i], f.rootPowers[i], 1))
+	}
+	return point
+}
+
+// verifyQueryRound checks the openings at the position given by idxBits
+// (little-endian) of the first evaluation domain. It returns the point of the
+// query and the opened evaluation of the committed polynomial.
+func (f *NativeFri) verifyQueryRound(idxBits []frontend.Variable, betas []frontend.Variable, commitments []frontend.Variable, finalPoly []frontend.Variable, round NativeQueryRound) (frontend.Variable, frontend.Variable) {
+	api := f.api
+	point := f.QueryPoint(idxBits)
+	first, eval := point, frontend.Variable(nil)
+
+	var folded frontend.Variable
+	for i, step := range round.Steps {
+		// the position in the i-th layer is idx >> i, the lowest bit selects
+		// the evaluation in the leaf and the others the leaf.
+		slot := idxBits[i]
+		leafBits := idxBits[i+1:]
+
+		if folded != nil {
+			api.AssertIsEqual(api.Select(slot, step.Evals[1], step.Evals[0]), folded)
+		} else {
+			eval = api.Select(slot, step.Evals[1], step.Evals[0])
+		}
+
+		f.VerifyMerkleProof(commitments[i], step.Evals[:], step.MerkleProof, leafBits)
+
+		// x is the point of the first evaluation of the leaf
+		x := api.Select(slot, api.Neg(point), point)
+		sum := api.Add(step.Evals[0], step.Evals[1])
+		diff := api.Sub(step.Evals[0], step.Evals[1])
+		diff = api.Div(api.Mul(diff, betas[i]), x)
+		folded = api.Mul(api.Add(sum, diff), f.half)
+
+		point = api.Mul(point, point)
+	}
+
+	// evaluate the final polynomial at the last point
+	var acc frontend.Variable = 0
+	for i := len(finalPoly) - 1; i >= 0; i-- {
+		acc = api.Add(api.Mul(acc, point), finalPoly[i])
+	}
+	api.AssertIsEqual(acc, folded)
+
+	return first, eval
+}
+
+// VerifyMerkleProof asserts that leaf is the content of the leaf at position
+// leafBits (little-endian) of the Merkle tree with the given root. The leaf is
+// hashed as a whole and the nodes are the hash of their two children, path
+// stores the siblings from the leaf to the root.
+func (f *NativeFri) VerifyMerkleProof(root frontend.Variable, leaf, path, leafBits []frontend.Variable) {
+	api := f.api
+	f.h.Reset()
+	f.h.Write(leaf...)
+	node := f.h.Sum()
+	for i, sibling := range path {
+		left := api.Select(leafBits[i], sibling, node)
+		right := api.Select(leafBits[i], node, sibling)
+		f.h.Reset()
+		f.h.Write(left, right)
+		node = f.h.Sum()
+	}
+	f.h.Reset()
+	api.AssertIsEqual(node, root)
+}
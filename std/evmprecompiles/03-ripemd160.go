@@ -0,0 +1,28 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/ripemd160"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// RIPEMD160 implements [RIPEMD160] precompile contract at address 0x03.
+//
+// The 20-byte digest is left-padded with 12 zero bytes to a 32-byte word, as
+// returned by the precompile.
+//
+// [RIPEMD160]: https://ethereum.github.io/execution-specs/autoapi/ethereum/paris/vm/precompiled_contracts/ripemd160/index.html
+func RIPEMD160(api frontend.API, data []uints.U8) [32]uints.U8 {
+	h, err := ripemd160.New(api)
+	if err != nil {
+		panic(err)
+	}
+	h.Write(data)
+	digest := h.Sum()
+	var res [32]uints.U8
+	for i := 0; i < 32-ripemd160.Size; i++ {
+		res[i] = uints.NewU8(0)
+	}
+	copy(res[32-ripemd160.Size:], digest)
+	return res
+}
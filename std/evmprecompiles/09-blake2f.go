@@ -0,0 +1,30 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash/blake2b"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// BLAKE2F implements [BLAKE2F] precompile contract at address 0x09.
+//
+// The state vector h, the message block m and the offset counters t are given
+// as 64-bit words, already decoded from their little-endian encoding. The final
+// block indicator f must be boolean. As the circuit has fixed size, the number
+// of rounds is bounded by maxRounds and the circuit fails to solve if rounds is
+// larger.
+//
+// [BLAKE2F]: https://ethereum.github.io/execution-specs/autoapi/ethereum/istanbul/vm/precompiled_contracts/blake2f/index.html
+func BLAKE2F(api frontend.API, rounds frontend.Variable, h [8]uints.U64, m [16]uints.U64, t [2]uints.U64, f frontend.Variable, maxRounds int) [8]uints.U64 {
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		panic(err)
+	}
+	api.AssertIsBoolean(f)
+	// the final block flag inverts all bits of the 14th word of the working vector
+	var ff uints.U64
+	for i := range ff {
+		ff[i] = uints.U8{Val: api.Mul(f, 0xff)}
+	}
+	return blake2b.CompressVariableRounds(api, uapi, h, m, t, ff, rounds, maxRounds)
+}
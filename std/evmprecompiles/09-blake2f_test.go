@@ -0,0 +1,105 @@
+package evmprecompiles
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type blake2fCircuit struct {
+	Rounds   frontend.Variable
+	H        [8]uints.U64
+	M        [16]uints.U64
+	T        [2]uints.U64
+	F        frontend.Variable
+	Expected [8]uints.U64
+}
+
+func (c *blake2fCircuit) Define(api frontend.API) error {
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return err
+	}
+	res := BLAKE2F(api, c.Rounds, c.H, c.M, c.T, c.F, 12)
+	for i := range c.Expected {
+		uapi.AssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+// blake2fWitness decodes the 213-byte input and 64-byte output of the
+// precompile.
+func blake2fWitness(t *testing.T, input, output string) *blake2fCircuit {
+	in, err := hex.DecodeString(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := hex.DecodeString(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(in) != 213 || len(out) != 64 {
+		t.Fatal("invalid test vector")
+	}
+	word := func(b []byte) uints.U64 { return uints.NewU64(binary.LittleEndian.Uint64(b)) }
+	var w blake2fCircuit
+	w.Rounds = binary.BigEndian.Uint32(in[:4])
+	for i := range w.H {
+		w.H[i] = word(in[4+8*i:])
+		w.Expected[i] = word(out[8*i:])
+	}
+	for i := range w.M {
+		w.M[i] = word(in[68+8*i:])
+	}
+	for i := range w.T {
+		w.T[i] = word(in[196+8*i:])
+	}
+	w.F = in[212]
+	return &w
+}
+
+// test vectors from EIP-152 as used in go-ethereum
+const (
+	blake2fH = "48c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b"
+	blake2fM = "6162630000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+	blake2fT = "03000000000000000000000000000000"
+)
+
+func TestBLAKE2F(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, tc := range []struct {
+		input, output string
+	}{
+		{
+			input:  "0000000c" + blake2fH + blake2fM + blake2fT + "01",
+			output: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+		},
+		{
+			input:  "00000000" + blake2fH + blake2fM + blake2fT + "01",
+			output: "08c9bcf367e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d282e6ad7f520e511f6c3e2b8c68059b9442be0454267ce079217e1319cde05b",
+		},
+		{
+			input:  "0000000c" + blake2fH + blake2fM + blake2fT + "00",
+			output: "75ab69d3190a562c51aef8d88f1c2775876944407270c42c9844252c26d2875298743e7f6d5ea2f2d3e8d226039cd31b4e426ac4f2d3d666a610c2116fde4735",
+		},
+		{
+			input:  "00000001" + blake2fH + blake2fM + blake2fT + "01",
+			output: "b63a380cb2897d521994a85234ee2c181b5f844d2c624c002677e9703449d2fba551b3a8333bcdf5f2f7e08993d53923de3d64fcc68c034e717b9293fed7a421",
+		},
+	} {
+		circuit := blake2fCircuit{}
+		witness := blake2fWitness(t, tc.input, tc.output)
+		err := test.IsSolved(&circuit, witness, ecc.BN254.ScalarField())
+		assert.NoError(err, tc.input[:8])
+	}
+
+	// the number of rounds is bounded by the circuit size
+	witness := blake2fWitness(t, "0000000d"+blake2fH+blake2fM+blake2fT+"01", "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923")
+	err := test.IsSolved(&blake2fCircuit{}, witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
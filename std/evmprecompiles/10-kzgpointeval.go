@@ -0,0 +1,177 @@
+package evmprecompiles
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/commitments/kzg"
+	"github.com/consensys/gnark/std/hash/sha2"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// blobCommitmentVersionKZG is the version byte of the versioned hash of KZG
+// commitments.
+const blobCommitmentVersionKZG = 0x01
+
+// kzgSetupG2 is the compressed [τ]G₂ point of the Ethereum KZG ceremony, used
+// as the trusted setup of the point evaluation precompile.
+const kzgSetupG2 = "b5bfd7dd8cdeb128843bc287230af38926187075cbfbefa81009a2ce615ac53d2914e5870cb452d2afaaab24f3499f72185cbfee53492714734429b7b38608e23926c911cceceac9a36851477ba4c60b087041de621000edc98edada20c1def2"
+
+// KZGPointEval implements [POINT_EVALUATION] precompile contract at address 0x0a.
+//
+// It asserts that the versioned hash corresponds to the commitment, i.e. that
+// it is the SHA-256 hash of the compressed commitment with the first byte
+// replaced by the version 0x01, and that proof is a valid KZG opening proof of
+// the polynomial committed to by commitment at point z with value y. The
+// opening proof is verified against the [τ]G₂ point of the Ethereum trusted
+// setup. The precompile returns constant values on success (the number of field
+// elements per blob and the scalar field modulus), so the function does not
+// return any value.
+//
+// The points z and y are given as scalar field elements and the commitment and
+// proof as affine points, it is up to the caller to decode and range check the
+// precompile input. The commitment and proof are asserted to be in G1, where
+// the point at infinity is (0,0) and is encoded with the infinity flag, as for
+// the commitment of the zero blob.
+//
+// [POINT_EVALUATION]: https://ethereum.github.io/execution-specs/autoapi/ethereum/cancun/vm/precompiled_contracts/point_evaluation/index.html
+func KZGPointEval(api frontend.API, versionedHash [32]uints.U8, commitment *sw_bls12381.G1Affine, z, y *sw_bls12381.Scalar, proof *sw_bls12381.G1Affine) {
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		panic(err)
+	}
+	fp, err := emulated.NewField[sw_bls12381.BaseField](api)
+	if err != nil {
+		panic(err)
+	}
+	fr, err := emulated.NewField[sw_bls12381.ScalarField](api)
+	if err != nil {
+		panic(err)
+	}
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(err)
+	}
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	vk := kzgVerifyingKey()
+
+	// 1- Check that the commitment and the proof are in G1
+	api.AssertIsEqual(pair.IsOnG1(commitment), 1)
+	api.AssertIsEqual(pair.IsOnG1(proof), 1)
+
+	// 2- Check that the versioned hash matches the commitment
+	h, err := sha2.New(api)
+	if err != nil {
+		panic(err)
+	}
+	compressed := compressG1(api, fp, commitment)
+	h.Write(compressed[:])
+	digest := h.Sum()
+	uapi.ByteAssertEq(versionedHash[0], uints.NewU8(blobCommitmentVersionKZG))
+	for i := 1; i < len(versionedHash); i++ {
+		uapi.ByteAssertEq(versionedHash[i], digest[i])
+	}
+
+	// 3- Check the opening proof
+	//
+	// [y]G₁ + [-z]π - C. We use complete arithmetic as the commitment, the
+	// proof and the scalars can be zero.
+	total, err := curve.MultiScalarMul(
+		[]*sw_bls12381.G1Affine{&vk.G1, proof},
+		[]*sw_bls12381.Scalar{y, fr.Neg(z)},
+		algopts.WithCompleteArithmetic(),
+	)
+	if err != nil {
+		panic(err)
+	}
+	total = curve.AddUnified(total, curve.Neg(commitment))
+
+	// e([y]G₁ - [z]π - C, G₂)⋅e(π, [τ]G₂) == 1. The pairs with a point at
+	// infinity are skipped, and we continue with the generator to keep the
+	// computations well defined.
+	ml := pair.One()
+	for i, P := range []*sw_bls12381.G1Affine{total, proof} {
+		isInfinity := api.And(fp.IsZero(&P.X), fp.IsZero(&P.Y))
+		p := curve.Select(isInfinity, &vk.G1, P)
+		mli, err := pair.MillerLoop([]*sw_bls12381.G1Affine{p}, []*sw_bls12381.G2Affine{&vk.G2[i]})
+		if err != nil {
+			panic(err)
+		}
+		ml = pair.Mul(ml, pair.Select(isInfinity, pair.One(), mli))
+	}
+	pair.AssertIsEqual(pair.FinalExponentiation(ml), pair.One())
+}
+
+// kzgVerifyingKey returns the verifying key of the Ethereum trusted setup as a
+// constant with precomputed lines.
+func kzgVerifyingKey() kzg.VerifyingKey[sw_bls12381.G1Affine, sw_bls12381.G2Affine] {
+	b, err := hex.DecodeString(kzgSetupG2)
+	if err != nil {
+		panic(err)
+	}
+	var tau bls12381.G2Affine
+	if _, err := tau.SetBytes(b); err != nil {
+		panic(fmt.Sprintf("decode trusted setup: %v", err))
+	}
+	_, _, g1, g2 := bls12381.Generators()
+	return kzg.VerifyingKey[sw_bls12381.G1Affine, sw_bls12381.G2Affine]{
+		G1: sw_bls12381.NewG1Affine(g1),
+		G2: [2]sw_bls12381.G2Affine{sw_bls12381.NewG2AffineFixed(g2), sw_bls12381.NewG2AffineFixed(tau)},
+	}
+}
+
+// compressG1 returns the 48-byte compressed encoding of the BLS12-381 G1 point
+// P as defined in the ZCash serialization format. The point at infinity is
+// (0,0).
+func compressG1(api frontend.API, fp *emulated.Field[sw_bls12381.BaseField], P *sw_bls12381.G1Affine) [48]uints.U8 {
+	const nbBits = 381
+	x := fp.Reduce(&P.X)
+	fp.AssertIsInRange(x)
+	y := fp.Reduce(&P.Y)
+	fp.AssertIsInRange(y)
+	xBits := fp.ToBits(x)[:nbBits]
+	yBits := fp.ToBits(y)[:nbBits]
+	isInfinity := api.And(fp.IsZero(x), fp.IsZero(y))
+
+	// y is lexicographically largest when y > (p-1)/2, which doesn't hold for
+	// the point at infinity.
+	half := new(big.Int).Sub(sw_bls12381.BaseField{}.Modulus(), big.NewInt(1))
+	half.Rsh(half, 1)
+	isGreater := isGreaterConst(api, yBits, half)
+
+	// the three most significant bits of the encoding are the compression
+	// flag, the infinity flag and the sign of y.
+	var res [48]uints.U8
+	for i := range res {
+		var acc frontend.Variable = 0
+		for j := 7; j >= 0; j-- {
+			k := 8*(len(res)-1-i) + j
+			var bit frontend.Variable
+			switch {
+			case k < nbBits:
+				bit = xBits[k]
+			case k == nbBits+2:
+				bit = 1
+			case k == nbBits+1:
+				bit = isInfinity
+			case k == nbBits:
+				bit = isGreater
+			default:
+				bit = 0
+			}
+			acc = api.Add(api.Mul(acc, 2), bit)
+		}
+		res[i] = uints.U8{Val: acc}
+	}
+	return res
+}
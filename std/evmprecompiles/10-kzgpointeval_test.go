@@ -0,0 +1,94 @@
+package evmprecompiles
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+type kzgPointEvalCircuit struct {
+	VersionedHash [32]uints.U8
+	Commitment    sw_bls12381.G1Affine
+	Z, Y          sw_bls12381.Scalar
+	Proof         sw_bls12381.G1Affine
+}
+
+func (c *kzgPointEvalCircuit) Define(api frontend.API) error {
+	KZGPointEval(api, c.VersionedHash, &c.Commitment, &c.Z, &c.Y, &c.Proof)
+	return nil
+}
+
+// kzgPointEvalWitness decodes the 192-byte input of the precompile.
+func kzgPointEvalWitness(t *testing.T, input string) *kzgPointEvalCircuit {
+	in, err := hex.DecodeString(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(in) != 192 {
+		t.Fatal("invalid test vector")
+	}
+	var z, y fr.Element
+	z.SetBigInt(new(big.Int).SetBytes(in[32:64]))
+	y.SetBigInt(new(big.Int).SetBytes(in[64:96]))
+	var commitment, proof bls12381.G1Affine
+	if _, err := commitment.SetBytes(in[96:144]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proof.SetBytes(in[144:192]); err != nil {
+		t.Fatal(err)
+	}
+	var w kzgPointEvalCircuit
+	copy(w.VersionedHash[:], uints.NewU8Array(in[:32]))
+	w.Commitment = sw_bls12381.NewG1Affine(commitment)
+	w.Z = sw_bls12381.NewScalar(z)
+	w.Y = sw_bls12381.NewScalar(y)
+	w.Proof = sw_bls12381.NewG1Affine(proof)
+	return &w
+}
+
+// test vector from go-ethereum
+const kzgPointEvalInput = "01e798154708fe7789429634053cbf9f99b619f9f084048927333fce637f549b564c0a11a0f704f4fc3e8acfe0f8245f0ad1347b378fbf96e206da11a5d3630624d25032e67a7e6a4910df5834b8fe70e6bcfeeac0352434196bdf4b2485d5a18f59a8d2a1a625a17f3fea0fe5eb8c896db3764f3185481bc22f91b4aaffcca25f26936857bc3a7c2539ea8ec3a952b7873033e038326e87ed3e1276fd140253fa08e9fc25fb2d9a98527fc22a2c9612fbeafdad446cbc7bcdbdcd780af2c16a"
+
+// test vector from go-ethereum for the zero blob, the commitment and the proof
+// are the point at infinity
+const kzgPointEvalZeroInput = "010657f37554c781402a22917dee2f75def7ab966d7b770905398eba3c44401400000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000c00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000c00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+
+func TestKZGPointEval(t *testing.T) {
+	assert := test.NewAssert(t)
+	witness := kzgPointEvalWitness(t, kzgPointEvalInput)
+	err := test.IsSolved(&kzgPointEvalCircuit{}, witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// invalid versioned hash
+	invalid := kzgPointEvalWitness(t, kzgPointEvalInput)
+	invalid.VersionedHash[31] = uints.NewU8(0)
+	err = test.IsSolved(&kzgPointEvalCircuit{}, invalid, ecc.BN254.ScalarField())
+	assert.Error(err)
+
+	// invalid claimed value
+	invalid = kzgPointEvalWitness(t, kzgPointEvalInput)
+	invalid.Y = sw_bls12381.NewScalar(fr.NewElement(1))
+	err = test.IsSolved(&kzgPointEvalCircuit{}, invalid, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+func TestKZGPointEvalInfinity(t *testing.T) {
+	assert := test.NewAssert(t)
+	witness := kzgPointEvalWitness(t, kzgPointEvalZeroInput)
+	err := test.IsSolved(&kzgPointEvalCircuit{}, witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// invalid claimed value, the zero polynomial evaluates to zero
+	invalid := kzgPointEvalWitness(t, kzgPointEvalZeroInput)
+	invalid.Y = sw_bls12381.NewScalar(fr.NewElement(1))
+	err = test.IsSolved(&kzgPointEvalCircuit{}, invalid, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
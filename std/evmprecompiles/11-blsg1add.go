@@ -0,0 +1,61 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// ECAddG1BLS implements [BLS12_G1ADD] precompile contract at address 0x0b.
+//
+// It returns the sum of P and Q and a boolean indicating if the call succeeds.
+// The call fails if P or Q is neither on the curve nor the point at infinity
+// (0,0), in which case the returned point is undefined. As per the EIP, the
+// points are not checked to be in the prime order subgroup. The caller is
+// responsible for decoding the coordinates and checking that they are
+// canonical, see [ECAddG1BLSEncoded] for the precompile on the encoded input.
+//
+// [BLS12_G1ADD]: https://eips.ethereum.org/EIPS/eip-2537
+func ECAddG1BLS(api frontend.API, P, Q *sw_bls12381.G1Affine) (*sw_bls12381.G1Affine, frontend.Variable) {
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(err)
+	}
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	// 1- Check that P and Q are on the curve
+	isSuccess := api.And(pair.IsOnCurve(P), pair.IsOnCurve(Q))
+
+	// 2- Compute P+Q. We use AddUnified because P can be equal to Q, -Q and
+	// either or both can be (0,0).
+	res := curve.AddUnified(P, Q)
+	return res, isSuccess
+}
+
+// ECAddG1BLSEncoded implements [BLS12_G1ADD] precompile contract at address
+// 0x0b on the encoded input.
+//
+// The input is the concatenation of the 128-byte encodings of the points P and
+// Q, the 64-byte encoding of a coordinate being its big-endian encoding padded
+// with 16 zero bytes. It returns the 128-byte encoding of P+Q and a boolean
+// indicating if the call succeeds. In addition to the failure cases of
+// [ECAddG1BLS], the call fails if a coordinate is not smaller than the base
+// field modulus or if its padding is not zero, in which case the returned
+// encoding is undefined.
+//
+// [BLS12_G1ADD]: https://eips.ethereum.org/EIPS/eip-2537
+func ECAddG1BLSEncoded(api frontend.API, input [2 * blsG1Size]uints.U8) ([blsG1Size]uints.U8, frontend.Variable) {
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	P, isValidP := decodeG1BLS(api, fp, input[:blsG1Size])
+	Q, isValidQ := decodeG1BLS(api, fp, input[blsG1Size:])
+	res, isSuccess := ECAddG1BLS(api, P, Q)
+	isSuccess = api.And(isSuccess, api.And(isValidP, isValidQ))
+	return encodeG1BLS(api, fp, res), isSuccess
+}
@@ -0,0 +1,95 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// ECMSMG1BLS implements [BLS12_G1MSM] precompile contract at address 0x0c.
+//
+// It returns ∑ᵢ [sᵢ]Pᵢ and a boolean indicating if the call succeeds. The call
+// fails if any of the points is not in G1, in which case the returned point is
+// undefined. The point at infinity (0,0) is in G1. The caller is responsible for
+// decoding the coordinates and reducing the scalars modulo the group order,
+// which doesn't change the result for points in G1, see [ECMSMG1BLSEncoded]
+// for the precompile on the encoded input.
+//
+// [BLS12_G1MSM]: https://eips.ethereum.org/EIPS/eip-2537
+func ECMSMG1BLS(api frontend.API, P []*sw_bls12381.G1Affine, s []*sw_bls12381.Scalar) (*sw_bls12381.G1Affine, frontend.Variable) {
+	if len(P) != len(s) {
+		panic("P and s length mismatch")
+	}
+	if len(P) == 0 {
+		panic("invalid multi-scalar multiplication size")
+	}
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(err)
+	}
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	// 1- Check that Pᵢ are in G1. If not, we continue with (0,0) to keep the
+	// computations well defined.
+	var isSuccess frontend.Variable = 1
+	points := make([]*sw_bls12381.G1Affine, len(P))
+	infinity := &sw_bls12381.G1Affine{X: emulated.ValueOf[emulated.BLS12381Fp](0), Y: emulated.ValueOf[emulated.BLS12381Fp](0)}
+	for i := range P {
+		isOnG1 := pair.IsOnG1(P[i])
+		isSuccess = api.And(isSuccess, isOnG1)
+		points[i] = curve.Select(isOnG1, P[i], infinity)
+	}
+
+	// 2- Compute ∑ᵢ [sᵢ]Pᵢ. We use complete arithmetic as the points and the
+	// scalars can be zero.
+	res, err := curve.MultiScalarMul(points, s, algopts.WithCompleteArithmetic())
+	if err != nil {
+		panic(err)
+	}
+	return res, isSuccess
+}
+
+// ECMSMG1BLSEncoded implements [BLS12_G1MSM] precompile contract at address
+// 0x0c on the encoded input.
+//
+// The input is the concatenation of k 160-byte slices, each being the 128-byte
+// encoding of a point Pᵢ followed by the 32-byte big-endian encoding of a
+// scalar sᵢ. It returns the 128-byte encoding of ∑ᵢ [sᵢ]Pᵢ and a boolean
+// indicating if the call succeeds. In addition to the failure cases of
+// [ECMSMG1BLS], the call fails if a coordinate is not smaller than the base
+// field modulus or if its padding is not zero, in which case the returned
+// encoding is undefined. The scalars are not required to be smaller than the
+// group order.
+//
+// [BLS12_G1MSM]: https://eips.ethereum.org/EIPS/eip-2537
+func ECMSMG1BLSEncoded(api frontend.API, input []uints.U8) ([blsG1Size]uints.U8, frontend.Variable) {
+	const pairSize = blsG1Size + blsScalarSize
+	if len(input) == 0 || len(input)%pairSize != 0 {
+		panic("invalid multi-scalar multiplication input size")
+	}
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	fr, err := emulated.NewField[emulated.BLS12381Fr](api)
+	if err != nil {
+		panic(err)
+	}
+	var isValid frontend.Variable = 1
+	P := make([]*sw_bls12381.G1Affine, len(input)/pairSize)
+	s := make([]*sw_bls12381.Scalar, len(input)/pairSize)
+	for i := range P {
+		in := input[i*pairSize : (i+1)*pairSize]
+		var isValidP frontend.Variable
+		P[i], isValidP = decodeG1BLS(api, fp, in[:blsG1Size])
+		s[i] = decodeScalarBLS(api, fr, in[blsG1Size:])
+		isValid = api.And(isValid, isValidP)
+	}
+	res, isSuccess := ECMSMG1BLS(api, P, s)
+	return encodeG1BLS(api, fp, res), api.And(isSuccess, isValid)
+}
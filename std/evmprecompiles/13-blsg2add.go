@@ -0,0 +1,57 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// ECAddG2BLS implements [BLS12_G2ADD] precompile contract at address 0x0d.
+//
+// It returns the sum of P and Q and a boolean indicating if the call succeeds.
+// The call fails if P or Q is neither on the twist nor the point at infinity
+// (0,0), in which case the returned point is undefined. As per the EIP, the
+// points are not checked to be in the prime order subgroup. The caller is
+// responsible for decoding the coordinates and checking that they are
+// canonical, see [ECAddG2BLSEncoded] for the precompile on the encoded input.
+//
+// [BLS12_G2ADD]: https://eips.ethereum.org/EIPS/eip-2537
+func ECAddG2BLS(api frontend.API, P, Q *sw_bls12381.G2Affine) (*sw_bls12381.G2Affine, frontend.Variable) {
+	g2 := sw_bls12381.NewG2(api)
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	// 1- Check that P and Q are on the twist
+	isSuccess := api.And(pair.IsOnTwist(P), pair.IsOnTwist(Q))
+
+	// 2- Compute P+Q. We use AddUnified because P can be equal to Q, -Q and
+	// either or both can be (0,0).
+	res := g2.AddUnified(P, Q)
+	return res, isSuccess
+}
+
+// ECAddG2BLSEncoded implements [BLS12_G2ADD] precompile contract at address
+// 0x0d on the encoded input.
+//
+// The input is the concatenation of the 256-byte encodings of the points P and
+// Q, the encoding of a point being the encodings of the coordinates x₀, x₁,
+// y₀ and y₁ in Fp2 = Fp[u] with x = x₀ + x₁u and y = y₀ + y₁u. It returns the
+// 256-byte encoding of P+Q and a boolean indicating if the call succeeds. In
+// addition to the failure cases of [ECAddG2BLS], the call fails if a
+// coordinate is not smaller than the base field modulus or if its padding is
+// not zero, in which case the returned encoding is undefined.
+//
+// [BLS12_G2ADD]: https://eips.ethereum.org/EIPS/eip-2537
+func ECAddG2BLSEncoded(api frontend.API, input [2 * blsG2Size]uints.U8) ([blsG2Size]uints.U8, frontend.Variable) {
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	P, isValidP := decodeG2BLS(api, fp, input[:blsG2Size])
+	Q, isValidQ := decodeG2BLS(api, fp, input[blsG2Size:])
+	res, isSuccess := ECAddG2BLS(api, P, Q)
+	isSuccess = api.And(isSuccess, api.And(isValidP, isValidQ))
+	return encodeG2BLS(api, fp, res), isSuccess
+}
@@ -0,0 +1,90 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// ECMSMG2BLS implements [BLS12_G2MSM] precompile contract at address 0x0e.
+//
+// It returns ∑ᵢ [sᵢ]Pᵢ and a boolean indicating if the call succeeds. The call
+// fails if any of the points is not in G2, in which case the returned point is
+// undefined. The point at infinity (0,0) is in G2. The caller is responsible for
+// decoding the coordinates and reducing the scalars modulo the group order,
+// which doesn't change the result for points in G2, see [ECMSMG2BLSEncoded]
+// for the precompile on the encoded input.
+//
+// [BLS12_G2MSM]: https://eips.ethereum.org/EIPS/eip-2537
+func ECMSMG2BLS(api frontend.API, P []*sw_bls12381.G2Affine, s []*sw_bls12381.Scalar) (*sw_bls12381.G2Affine, frontend.Variable) {
+	if len(P) != len(s) {
+		panic("P and s length mismatch")
+	}
+	if len(P) == 0 {
+		panic("invalid multi-scalar multiplication size")
+	}
+	g2 := sw_bls12381.NewG2(api)
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	zero := fields_bls12381.NewExt2(api).Zero()
+	infinity := &sw_bls12381.G2Affine{}
+	infinity.P.X, infinity.P.Y = *zero, *zero
+
+	// 1- Check that Pᵢ are in G2. If not, we continue with (0,0) to keep the
+	// computations well defined.
+	// 2- Compute ∑ᵢ [sᵢ]Pᵢ with the unified addition formulas as the points and
+	// the scalars can be zero.
+	var isSuccess frontend.Variable = 1
+	res := infinity
+	for i := range P {
+		isOnG2 := pair.IsOnG2(P[i])
+		isSuccess = api.And(isSuccess, isOnG2)
+		point := g2.Select(isOnG2, P[i], infinity)
+		res = g2.AddUnified(res, g2.ScalarMul(point, s[i]))
+	}
+	return res, isSuccess
+}
+
+// ECMSMG2BLSEncoded implements [BLS12_G2MSM] precompile contract at address
+// 0x0e on the encoded input.
+//
+// The input is the concatenation of k 288-byte slices, each being the 256-byte
+// encoding of a point Pᵢ followed by the 32-byte big-endian encoding of a
+// scalar sᵢ. It returns the 256-byte encoding of ∑ᵢ [sᵢ]Pᵢ and a boolean
+// indicating if the call succeeds. In addition to the failure cases of
+// [ECMSMG2BLS], the call fails if a coordinate is not smaller than the base
+// field modulus or if its padding is not zero, in which case the returned
+// encoding is undefined. The scalars are not required to be smaller than the
+// group order.
+//
+// [BLS12_G2MSM]: https://eips.ethereum.org/EIPS/eip-2537
+func ECMSMG2BLSEncoded(api frontend.API, input []uints.U8) ([blsG2Size]uints.U8, frontend.Variable) {
+	const pairSize = blsG2Size + blsScalarSize
+	if len(input) == 0 || len(input)%pairSize != 0 {
+		panic("invalid multi-scalar multiplication input size")
+	}
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	fr, err := emulated.NewField[emulated.BLS12381Fr](api)
+	if err != nil {
+		panic(err)
+	}
+	var isValid frontend.Variable = 1
+	P := make([]*sw_bls12381.G2Affine, len(input)/pairSize)
+	s := make([]*sw_bls12381.Scalar, len(input)/pairSize)
+	for i := range P {
+		in := input[i*pairSize : (i+1)*pairSize]
+		var isValidP frontend.Variable
+		P[i], isValidP = decodeG2BLS(api, fp, in[:blsG2Size])
+		s[i] = decodeScalarBLS(api, fr, in[blsG2Size:])
+		isValid = api.And(isValid, isValidP)
+	}
+	res, isSuccess := ECMSMG2BLS(api, P, s)
+	return encodeG2BLS(api, fp, res), api.And(isSuccess, isValid)
+}
@@ -0,0 +1,117 @@
+package evmprecompiles
+
+import (
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// ECPairBLS implements [BLS12_PAIRING_CHECK] precompile contract at address 0x0f.
+//
+// It returns a boolean indicating if ∏ᵢ e(Pᵢ, Qᵢ) == 1 and a boolean indicating
+// if the call succeeds. The call fails if any of the points Pᵢ is not in G1 or
+// any of the points Qᵢ is not in G2, in which case the returned result is
+// undefined. The points at infinity (0,0) are in G1 and G2 and the
+// corresponding pairs are skipped. The caller is responsible for decoding the
+// coordinates and checking that they are canonical, see [ECPairBLSEncoded] for
+// the precompile on the encoded input.
+//
+// [BLS12_PAIRING_CHECK]: https://eips.ethereum.org/EIPS/eip-2537
+func ECPairBLS(api frontend.API, P []*sw_bls12381.G1Affine, Q []*sw_bls12381.G2Affine) (isOne, isSuccess frontend.Variable) {
+	if len(P) != len(Q) {
+		panic("P and Q length mismatch")
+	}
+	if len(P) == 0 {
+		panic("invalid multipairing size")
+	}
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		panic(err)
+	}
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	g2 := sw_bls12381.NewG2(api)
+	pair, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		panic(err)
+	}
+	_, _, g1gen, g2gen := bls12381.Generators()
+	g1Gen := sw_bls12381.NewG1Affine(g1gen)
+	g2Gen := sw_bls12381.NewG2Affine(g2gen)
+
+	isSuccess = 1
+	ml := pair.One()
+	for i := range P {
+		// 1- Check that Pᵢ are in G1 and Qᵢ are in G2
+		isOnG1 := pair.IsOnG1(P[i])
+		isOnG2 := pair.IsOnG2(Q[i])
+		isValid := api.And(isOnG1, isOnG2)
+		isSuccess = api.And(isSuccess, isValid)
+
+		// 2- Compute the Miller loop of the pair. The pairs with a point at
+		// infinity or an invalid point are skipped, and we continue with the
+		// generators to keep the computations well defined.
+		isInfinity := api.Or(
+			api.And(fp.IsZero(&P[i].X), fp.IsZero(&P[i].Y)),
+			api.And(g2.Ext2.IsZero(&Q[i].P.X), g2.Ext2.IsZero(&Q[i].P.Y)),
+		)
+		skip := api.Or(isInfinity, api.Sub(1, isValid))
+		p := curve.Select(skip, &g1Gen, P[i])
+		q := g2.Select(skip, &g2Gen, Q[i])
+		mli, err := pair.MillerLoop([]*sw_bls12381.G1Affine{p}, []*sw_bls12381.G2Affine{q})
+		if err != nil {
+			panic(err)
+		}
+		ml = pair.Mul(ml, pair.Select(skip, pair.One(), mli))
+	}
+
+	// 3- Check that ∏ᵢ e(Pᵢ, Qᵢ) == 1
+	res := pair.FinalExponentiation(ml)
+	isOne = pair.IsZero(pair.Sub(res, pair.One()))
+	return isOne, isSuccess
+}
+
+// ECPairBLSEncoded implements [BLS12_PAIRING_CHECK] precompile contract at
+// address 0x0f on the encoded input.
+//
+// The input is the concatenation of k 384-byte slices, each being the 128-byte
+// encoding of a point Pᵢ followed by the 256-byte encoding of a point Qᵢ. It
+// returns the 32-byte big-endian encoding of 1 if ∏ᵢ e(Pᵢ, Qᵢ) == 1 and of 0
+// otherwise, and a boolean indicating if the call succeeds. In addition to the
+// failure cases of [ECPairBLS], the call fails if a coordinate is not smaller
+// than the base field modulus or if its padding is not zero, in which case the
+// returned encoding is undefined.
+//
+// [BLS12_PAIRING_CHECK]: https://eips.ethereum.org/EIPS/eip-2537
+func ECPairBLSEncoded(api frontend.API, input []uints.U8) ([32]uints.U8, frontend.Variable) {
+	const pairSize = blsG1Size + blsG2Size
+	if len(input) == 0 || len(input)%pairSize != 0 {
+		panic("invalid multipairing input size")
+	}
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	var isValid frontend.Variable = 1
+	P := make([]*sw_bls12381.G1Affine, len(input)/pairSize)
+	Q := make([]*sw_bls12381.G2Affine, len(input)/pairSize)
+	for i := range P {
+		in := input[i*pairSize : (i+1)*pairSize]
+		var isValidP, isValidQ frontend.Variable
+		P[i], isValidP = decodeG1BLS(api, fp, in[:blsG1Size])
+		Q[i], isValidQ = decodeG2BLS(api, fp, in[blsG1Size:])
+		isValid = api.And(isValid, api.And(isValidP, isValidQ))
+	}
+	isOne, isSuccess := ECPairBLS(api, P, Q)
+	var res [32]uints.U8
+	for i := range res {
+		res[i] = uints.NewU8(0)
+	}
+	res[len(res)-1] = uints.U8{Val: isOne}
+	return res, api.And(isSuccess, isValid)
+}
@@ -0,0 +1,44 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// ECMapToG1BLS implements [BLS12_MAP_FP_TO_G1] precompile contract at address 0x10.
+//
+// It maps the base field element u to a point in G1 using the simplified SWU
+// map of RFC 9380 followed by cofactor clearing. The call fails only if the
+// encoding of u is not canonical. The caller is responsible for decoding u and
+// checking that it is canonical, so the function doesn't return a success
+// indicator. See [ECMapToG1BLSEncoded] for the precompile on the encoded input.
+//
+// [BLS12_MAP_FP_TO_G1]: https://eips.ethereum.org/EIPS/eip-2537
+func ECMapToG1BLS(api frontend.API, u *emulated.Element[emulated.BLS12381Fp]) *sw_bls12381.G1Affine {
+	g1, err := sw_bls12381.NewG1(api)
+	if err != nil {
+		panic(err)
+	}
+	return g1.MapToG1(u)
+}
+
+// ECMapToG1BLSEncoded implements [BLS12_MAP_FP_TO_G1] precompile contract at
+// address 0x10 on the encoded input.
+//
+// The input is the 64-byte encoding of the base field element u, its
+// big-endian encoding padded with 16 zero bytes. It returns the 128-byte
+// encoding of the mapped point and a boolean indicating if the call succeeds.
+// The call fails if u is not smaller than the base field modulus or if its
+// padding is not zero, in which case the returned encoding is undefined.
+//
+// [BLS12_MAP_FP_TO_G1]: https://eips.ethereum.org/EIPS/eip-2537
+func ECMapToG1BLSEncoded(api frontend.API, input [blsFpSize]uints.U8) ([blsG1Size]uints.U8, frontend.Variable) {
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	u, isSuccess := decodeFpBLS(api, fp, input[:])
+	return encodeG1BLS(api, fp, ECMapToG1BLS(api, u)), isSuccess
+}
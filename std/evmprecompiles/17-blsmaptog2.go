@@ -0,0 +1,45 @@
+package evmprecompiles
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// ECMapToG2BLS implements [BLS12_MAP_FP2_TO_G2] precompile contract at address 0x11.
+//
+// It maps the element u of Fp2 to a point in G2 using the simplified SWU map
+// of RFC 9380 followed by cofactor clearing. The call fails only if the
+// encoding of u is not canonical. The caller is responsible for decoding u and
+// checking that it is canonical, so the function doesn't return a success
+// indicator. See [ECMapToG2BLSEncoded] for the precompile on the encoded input.
+//
+// [BLS12_MAP_FP2_TO_G2]: https://eips.ethereum.org/EIPS/eip-2537
+func ECMapToG2BLS(api frontend.API, u *fields_bls12381.E2) *sw_bls12381.G2Affine {
+	g2 := sw_bls12381.NewG2(api)
+	return g2.MapToG2(u)
+}
+
+// ECMapToG2BLSEncoded implements [BLS12_MAP_FP2_TO_G2] precompile contract at
+// address 0x11 on the encoded input.
+//
+// The input is the 128-byte encoding of the element u = c0 + c1·i of Fp2, the
+// 64-byte encodings of c0 and c1 being their big-endian encodings padded with
+// 16 zero bytes. It returns the 256-byte encoding of the mapped point and a
+// boolean indicating if the call succeeds. The call fails if c0 or c1 is not
+// smaller than the base field modulus or if its padding is not zero, in which
+// case the returned encoding is undefined.
+//
+// [BLS12_MAP_FP2_TO_G2]: https://eips.ethereum.org/EIPS/eip-2537
+func ECMapToG2BLSEncoded(api frontend.API, input [blsFp2Size]uints.U8) ([blsG2Size]uints.U8, frontend.Variable) {
+	fp, err := emulated.NewField[emulated.BLS12381Fp](api)
+	if err != nil {
+		panic(err)
+	}
+	c0, isSuccess0 := decodeFpBLS(api, fp, input[:blsFpSize])
+	c1, isSuccess1 := decodeFpBLS(api, fp, input[blsFpSize:])
+	u := &fields_bls12381.E2{A0: *c0, A1: *c1}
+	return encodeG2BLS(api, fp, ECMapToG2BLS(api, u)), api.And(isSuccess0, isSuccess1)
+}
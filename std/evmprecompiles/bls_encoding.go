@@ -0,0 +1,182 @@
+package evmprecompiles
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+// Sizes of the encodings of the BLS12-381 precompiles defined in EIP-2537.
+const (
+	blsFpSize     = 64
+	blsScalarSize = 32
+	blsFp2Size    = 2 * blsFpSize
+	blsG1Size     = 2 * blsFpSize
+	blsG2Size     = 4 * blsFpSize
+)
+
+// blsFpNbBits is the bit length of the BLS12-381 base field modulus.
+const blsFpNbBits = 381
+
+// bytesToBits returns the bits of the big-endian byte string b in little-endian
+// order. The bytes are constrained to be 8 bits wide.
+func bytesToBits(api frontend.API, b []uints.U8) []frontend.Variable {
+	res := make([]frontend.Variable, 0, 8*len(b))
+	for i := len(b) - 1; i >= 0; i-- {
+		res = append(res, bits.ToBinary(api, b[i].Val, bits.WithNbDigits(8))...)
+	}
+	return res
+}
+
+// bitsToBytes returns the big-endian byte string of n bytes of the integer
+// given by its little-endian bits, the missing most significant bits being
+// zero.
+func bitsToBytes(api frontend.API, b []frontend.Variable, n int) []uints.U8 {
+	res := make([]uints.U8, n)
+	for i := range res {
+		k := 8 * (n - 1 - i)
+		var acc frontend.Variable = 0
+		if k < len(b) {
+			end := k + 8
+			if end > len(b) {
+				end = len(b)
+			}
+			acc = bits.FromBinary(api, b[k:end], bits.WithUnconstrainedInputs())
+		}
+		res[i] = uints.U8{Val: acc}
+	}
+	return res
+}
+
+// isGreaterConst returns 1 if the integer given by its little-endian bits is
+// greater than c and 0 otherwise. The bit length of c must not exceed the
+// number of bits.
+func isGreaterConst(api frontend.API, b []frontend.Variable, c *big.Int) frontend.Variable {
+	if c.BitLen() > len(b) {
+		panic("constant larger than the number of bits")
+	}
+	// we compare the bits starting from the most significant one.
+	var isGreater, isEqual frontend.Variable = 0, 1
+	for i := len(b) - 1; i >= 0; i-- {
+		if c.Bit(i) == 1 {
+			isEqual = api.Mul(isEqual, b[i])
+		} else {
+			isGreater = api.Add(isGreater, api.Mul(isEqual, b[i]))
+			isEqual = api.Sub(isEqual, api.Mul(isEqual, b[i]))
+		}
+	}
+	return isGreater
+}
+
+// decodeFpBLS decodes the 64-byte encoding of a BLS12-381 base field element.
+// It returns the element and a boolean indicating if the encoding is valid,
+// i.e. if the 16 most significant bytes are zero and the encoded integer is
+// smaller than the modulus. If the encoding is invalid, the returned element
+// is zero.
+func decodeFpBLS(api frontend.API, fp *emulated.Field[emulated.BLS12381Fp], b []uints.U8) (*emulated.Element[emulated.BLS12381Fp], frontend.Variable) {
+	if len(b) != blsFpSize {
+		panic("invalid base field element encoding size")
+	}
+	bs := bytesToBits(api, b)
+
+	// 1- Check that the bits above the modulus bit length are zero. It covers
+	// the 16 bytes of padding.
+	var high frontend.Variable = 0
+	for i := blsFpNbBits; i < len(bs); i++ {
+		high = api.Add(high, bs[i])
+	}
+	isValid := api.IsZero(high)
+
+	// 2- Check that the encoded integer is smaller than the modulus.
+	pMinusOne := new(big.Int).Sub(emulated.BLS12381Fp{}.Modulus(), big.NewInt(1))
+	isValid = api.And(isValid, api.Sub(1, isGreaterConst(api, bs[:blsFpNbBits], pMinusOne)))
+
+	// 3- Zero the invalid encodings to keep the element well-formed.
+	masked := make([]frontend.Variable, blsFpNbBits)
+	for i := range masked {
+		masked[i] = api.Mul(bs[i], isValid)
+	}
+	return fp.FromBits(masked...), isValid
+}
+
+// decodeG1BLS decodes the 128-byte encoding of a BLS12-381 G1 point. It
+// returns the point and a boolean indicating if the coordinates are validly
+// encoded. The point is not checked to be on the curve.
+func decodeG1BLS(api frontend.API, fp *emulated.Field[emulated.BLS12381Fp], b []uints.U8) (*sw_bls12381.G1Affine, frontend.Variable) {
+	if len(b) != blsG1Size {
+		panic("invalid G1 point encoding size")
+	}
+	x, isValidX := decodeFpBLS(api, fp, b[:blsFpSize])
+	y, isValidY := decodeFpBLS(api, fp, b[blsFpSize:])
+	return &sw_bls12381.G1Affine{X: *x, Y: *y}, api.And(isValidX, isValidY)
+}
+
+// decodeG2BLS decodes the 256-byte encoding of a BLS12-381 G2 point. It
+// returns the point and a boolean indicating if the coordinates are validly
+// encoded. The point is not checked to be on the twist.
+func decodeG2BLS(api frontend.API, fp *emulated.Field[emulated.BLS12381Fp], b []uints.U8) (*sw_bls12381.G2Affine, frontend.Variable) {
+	if len(b) != blsG2Size {
+		panic("invalid G2 point encoding size")
+	}
+	var coords [4]*emulated.Element[emulated.BLS12381Fp]
+	var isValid frontend.Variable = 1
+	for i := range coords {
+		var isValidI frontend.Variable
+		coords[i], isValidI = decodeFpBLS(api, fp, b[i*blsFpSize:(i+1)*blsFpSize])
+		isValid = api.And(isValid, isValidI)
+	}
+	res := new(sw_bls12381.G2Affine)
+	res.P.X = fields_bls12381.E2{A0: *coords[0], A1: *coords[1]}
+	res.P.Y = fields_bls12381.E2{A0: *coords[2], A1: *coords[3]}
+	return res, isValid
+}
+
+// decodeScalarBLS decodes the 32-byte big-endian encoding of a scalar. Any
+// 256-bit integer is a valid encoding, the returned scalar is congruent to it
+// modulo the group order.
+func decodeScalarBLS(api frontend.API, fr *emulated.Field[emulated.BLS12381Fr], b []uints.U8) *sw_bls12381.Scalar {
+	if len(b) != blsScalarSize {
+		panic("invalid scalar encoding size")
+	}
+	bs := bytesToBits(api, b)
+	// the 255 least significant bits fit in the scalar field elements, we add
+	// the most significant one reduced modulo the group order.
+	nbBits := emulated.BLS12381Fr{}.Modulus().BitLen()
+	top := new(big.Int).Lsh(big.NewInt(1), uint(nbBits))
+	top.Mod(top, emulated.BLS12381Fr{}.Modulus())
+	res := fr.FromBits(bs[:nbBits]...)
+	for i := nbBits; i < len(bs); i++ {
+		res = fr.Add(res, fr.Select(bs[i], fr.NewElement(top), fr.Zero()))
+		top.Lsh(top, 1).Mod(top, emulated.BLS12381Fr{}.Modulus())
+	}
+	return fr.Reduce(res)
+}
+
+// encodeFpBLS returns the 64-byte encoding of a BLS12-381 base field element.
+func encodeFpBLS(api frontend.API, fp *emulated.Field[emulated.BLS12381Fp], x *emulated.Element[emulated.BLS12381Fp]) []uints.U8 {
+	x = fp.Reduce(x)
+	fp.AssertIsInRange(x)
+	return bitsToBytes(api, fp.ToBits(x)[:blsFpNbBits], blsFpSize)
+}
+
+// encodeG1BLS returns the 128-byte encoding of a BLS12-381 G1 point.
+func encodeG1BLS(api frontend.API, fp *emulated.Field[emulated.BLS12381Fp], P *sw_bls12381.G1Affine) [blsG1Size]uints.U8 {
+	var res [blsG1Size]uints.U8
+	copy(res[:blsFpSize], encodeFpBLS(api, fp, &P.X))
+	copy(res[blsFpSize:], encodeFpBLS(api, fp, &P.Y))
+	return res
+}
+
+// encodeG2BLS returns the 256-byte encoding of a BLS12-381 G2 point.
+func encodeG2BLS(api frontend.API, fp *emulated.Field[emulated.BLS12381Fp], P *sw_bls12381.G2Affine) [blsG2Size]uints.U8 {
+	var res [blsG2Size]uints.U8
+	for i, c := range []*emulated.Element[emulated.BLS12381Fp]{&P.P.X.A0, &P.P.X.A1, &P.P.Y.A0, &P.P.Y.A1} {
+		copy(res[i*blsFpSize:(i+1)*blsFpSize], encodeFpBLS(api, fp, c))
+	}
+	return res
+}
@@ -0,0 +1,727 @@
+package evmprecompiles
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+)
+
+func randomBLSG1G2() (bls12381.G1Affine, bls12381.G2Affine) {
+	_, _, g1, g2 := bls12381.Generators()
+	var s1, s2 fr.Element
+	s1.SetRandom()
+	s2.SetRandom()
+	var p bls12381.G1Affine
+	var q bls12381.G2Affine
+	p.ScalarMultiplication(&g1, s1.BigInt(new(big.Int)))
+	q.ScalarMultiplication(&g2, s2.BigInt(new(big.Int)))
+	return p, q
+}
+
+// randomBLSCurvePoints returns points on the curve and on the twist which are
+// not in the prime order subgroups.
+func randomBLSCurvePoints() (p bls12381.G1Affine, q bls12381.G2Affine) {
+	var b bls12381.G2Affine
+	b.X.A0.SetUint64(4)
+	b.X.A1.SetUint64(4)
+	for {
+		p.X.SetRandom()
+		p.Y.Square(&p.X).Mul(&p.Y, &p.X).Add(&p.Y, &b.X.A0)
+		if p.Y.Legendre() == 1 {
+			p.Y.Sqrt(&p.Y)
+			break
+		}
+	}
+	for {
+		q.X.SetRandom()
+		q.Y.Square(&q.X).Mul(&q.Y, &q.X).Add(&q.Y, &b.X)
+		if q.Y.Legendre() == 1 {
+			q.Y.Sqrt(&q.Y)
+			break
+		}
+	}
+	return
+}
+
+type blsG1AddCircuit struct {
+	P, Q      sw_bls12381.G1Affine
+	Expected  sw_bls12381.G1Affine
+	IsSuccess frontend.Variable
+}
+
+func (c *blsG1AddCircuit) Define(api frontend.API) error {
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return err
+	}
+	res, isSuccess := ECAddG1BLS(api, &c.P, &c.Q)
+	api.AssertIsEqual(isSuccess, c.IsSuccess)
+	res = curve.Select(isSuccess, res, &c.Expected)
+	curve.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestBLSG1Add(t *testing.T) {
+	assert := test.NewAssert(t)
+	p, _ := randomBLSG1G2()
+	q, _ := randomBLSG1G2()
+	var expected bls12381.G1Affine
+	expected.Add(&p, &q)
+	witness := blsG1AddCircuit{
+		P:         sw_bls12381.NewG1Affine(p),
+		Q:         sw_bls12381.NewG1Affine(q),
+		Expected:  sw_bls12381.NewG1Affine(expected),
+		IsSuccess: 1,
+	}
+	err := test.IsSolved(&blsG1AddCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points not in the subgroup are valid inputs
+	p, _ = randomBLSCurvePoints()
+	expected.Add(&p, &q)
+	witness.P = sw_bls12381.NewG1Affine(p)
+	witness.Expected = sw_bls12381.NewG1Affine(expected)
+	err = test.IsSolved(&blsG1AddCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points not on the curve make the call fail
+	p.Y.Double(&p.Y)
+	witness.P = sw_bls12381.NewG1Affine(p)
+	witness.IsSuccess = 0
+	err = test.IsSolved(&blsG1AddCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type blsG1MSMCircuit struct {
+	P         [2]sw_bls12381.G1Affine
+	S         [2]sw_bls12381.Scalar
+	Expected  sw_bls12381.G1Affine
+	IsSuccess frontend.Variable
+}
+
+func (c *blsG1MSMCircuit) Define(api frontend.API) error {
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return err
+	}
+	res, isSuccess := ECMSMG1BLS(api, []*sw_bls12381.G1Affine{&c.P[0], &c.P[1]}, []*sw_bls12381.Scalar{&c.S[0], &c.S[1]})
+	api.AssertIsEqual(isSuccess, c.IsSuccess)
+	res = curve.Select(isSuccess, res, &c.Expected)
+	curve.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestBLSG1MSM(t *testing.T) {
+	assert := test.NewAssert(t)
+	p0, _ := randomBLSG1G2()
+	p1, _ := randomBLSG1G2()
+	var s0, s1 fr.Element
+	s0.SetRandom()
+	s1.SetRandom()
+	var expected bls12381.G1Affine
+	_, err := expected.MultiExp([]bls12381.G1Affine{p0, p1}, []fr.Element{s0, s1}, ecc.MultiExpConfig{})
+	assert.NoError(err)
+	witness := blsG1MSMCircuit{
+		P:         [2]sw_bls12381.G1Affine{sw_bls12381.NewG1Affine(p0), sw_bls12381.NewG1Affine(p1)},
+		S:         [2]sw_bls12381.Scalar{sw_bls12381.NewScalar(s0), sw_bls12381.NewScalar(s1)},
+		Expected:  sw_bls12381.NewG1Affine(expected),
+		IsSuccess: 1,
+	}
+	err = test.IsSolved(&blsG1MSMCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points at infinity and zero scalars are valid inputs
+	expected.ScalarMultiplication(&p1, s1.BigInt(new(big.Int)))
+	witness.P[0] = sw_bls12381.NewG1Affine(bls12381.G1Affine{})
+	witness.Expected = sw_bls12381.NewG1Affine(expected)
+	err = test.IsSolved(&blsG1MSMCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	witness.P[0] = sw_bls12381.NewG1Affine(p0)
+	witness.S[0] = sw_bls12381.NewScalar(fr.NewElement(0))
+	err = test.IsSolved(&blsG1MSMCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points not in the subgroup make the call fail
+	p0, _ = randomBLSCurvePoints()
+	witness.P[0] = sw_bls12381.NewG1Affine(p0)
+	witness.IsSuccess = 0
+	err = test.IsSolved(&blsG1MSMCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type blsG2AddCircuit struct {
+	P, Q      sw_bls12381.G2Affine
+	Expected  sw_bls12381.G2Affine
+	IsSuccess frontend.Variable
+}
+
+func (c *blsG2AddCircuit) Define(api frontend.API) error {
+	g2 := sw_bls12381.NewG2(api)
+	res, isSuccess := ECAddG2BLS(api, &c.P, &c.Q)
+	api.AssertIsEqual(isSuccess, c.IsSuccess)
+	res = g2.Select(isSuccess, res, &c.Expected)
+	g2.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestBLSG2Add(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, p := randomBLSG1G2()
+	_, q := randomBLSG1G2()
+	var expected bls12381.G2Affine
+	expected.Add(&p, &q)
+	witness := blsG2AddCircuit{
+		P:         sw_bls12381.NewG2Affine(p),
+		Q:         sw_bls12381.NewG2Affine(q),
+		Expected:  sw_bls12381.NewG2Affine(expected),
+		IsSuccess: 1,
+	}
+	err := test.IsSolved(&blsG2AddCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points not on the twist make the call fail
+	p.Y.Double(&p.Y)
+	witness.P = sw_bls12381.NewG2Affine(p)
+	witness.IsSuccess = 0
+	err = test.IsSolved(&blsG2AddCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type blsG2MSMCircuit struct {
+	P         [2]sw_bls12381.G2Affine
+	S         [2]sw_bls12381.Scalar
+	Expected  sw_bls12381.G2Affine
+	IsSuccess frontend.Variable
+}
+
+func (c *blsG2MSMCircuit) Define(api frontend.API) error {
+	g2 := sw_bls12381.NewG2(api)
+	res, isSuccess := ECMSMG2BLS(api, []*sw_bls12381.G2Affine{&c.P[0], &c.P[1]}, []*sw_bls12381.Scalar{&c.S[0], &c.S[1]})
+	api.AssertIsEqual(isSuccess, c.IsSuccess)
+	res = g2.Select(isSuccess, res, &c.Expected)
+	g2.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestBLSG2MSM(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, p0 := randomBLSG1G2()
+	_, p1 := randomBLSG1G2()
+	var s0, s1 fr.Element
+	s0.SetRandom()
+	s1.SetRandom()
+	var expected bls12381.G2Affine
+	_, err := expected.MultiExp([]bls12381.G2Affine{p0, p1}, []fr.Element{s0, s1}, ecc.MultiExpConfig{})
+	assert.NoError(err)
+	witness := blsG2MSMCircuit{
+		P:         [2]sw_bls12381.G2Affine{sw_bls12381.NewG2Affine(p0), sw_bls12381.NewG2Affine(p1)},
+		S:         [2]sw_bls12381.Scalar{sw_bls12381.NewScalar(s0), sw_bls12381.NewScalar(s1)},
+		Expected:  sw_bls12381.NewG2Affine(expected),
+		IsSuccess: 1,
+	}
+	err = test.IsSolved(&blsG2MSMCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points not in the subgroup make the call fail
+	_, p0 = randomBLSCurvePoints()
+	witness.P[0] = sw_bls12381.NewG2Affine(p0)
+	witness.IsSuccess = 0
+	err = test.IsSolved(&blsG2MSMCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type blsPairCircuit struct {
+	P         [3]sw_bls12381.G1Affine
+	Q         [3]sw_bls12381.G2Affine
+	IsOne     frontend.Variable
+	IsSuccess frontend.Variable
+}
+
+func (c *blsPairCircuit) Define(api frontend.API) error {
+	isOne, isSuccess := ECPairBLS(api,
+		[]*sw_bls12381.G1Affine{&c.P[0], &c.P[1], &c.P[2]},
+		[]*sw_bls12381.G2Affine{&c.Q[0], &c.Q[1], &c.Q[2]},
+	)
+	api.AssertIsEqual(isSuccess, c.IsSuccess)
+	api.AssertIsEqual(api.Mul(isSuccess, isOne), c.IsOne)
+	return nil
+}
+
+func TestBLSPair(t *testing.T) {
+	assert := test.NewAssert(t)
+	p, q := randomBLSG1G2()
+	var pNeg bls12381.G1Affine
+	pNeg.Neg(&p)
+	// e(P, Q)·e(-P, Q)·e(0, Q) == 1
+	witness := blsPairCircuit{
+		P:         [3]sw_bls12381.G1Affine{sw_bls12381.NewG1Affine(p), sw_bls12381.NewG1Affine(pNeg), sw_bls12381.NewG1Affine(bls12381.G1Affine{})},
+		Q:         [3]sw_bls12381.G2Affine{sw_bls12381.NewG2Affine(q), sw_bls12381.NewG2Affine(q), sw_bls12381.NewG2Affine(q)},
+		IsOne:     1,
+		IsSuccess: 1,
+	}
+	err := test.IsSolved(&blsPairCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// e(P, Q)·e(P, Q)·e(0, Q) != 1
+	witness.P[1] = sw_bls12381.NewG1Affine(p)
+	witness.IsOne = 0
+	err = test.IsSolved(&blsPairCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	// points not in the subgroups make the call fail
+	_, qInvalid := randomBLSCurvePoints()
+	witness.Q[2] = sw_bls12381.NewG2Affine(qInvalid)
+	witness.IsSuccess = 0
+	err = test.IsSolved(&blsPairCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type blsMapToG1Circuit struct {
+	U        emulated.Element[emulated.BLS12381Fp]
+	Expected sw_bls12381.G1Affine
+}
+
+func (c *blsMapToG1Circuit) Define(api frontend.API) error {
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return err
+	}
+	res := ECMapToG1BLS(api, &c.U)
+	curve.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestBLSMapToG1(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u fp.Element
+	u.SetRandom()
+	expected := bls12381.MapToG1(u)
+	witness := blsMapToG1Circuit{
+		U:        emulated.ValueOf[emulated.BLS12381Fp](u),
+		Expected: sw_bls12381.NewG1Affine(expected),
+	}
+	err := test.IsSolved(&blsMapToG1Circuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+// blsEncodeFp returns the 64-byte encoding of x defined in EIP-2537.
+func blsEncodeFp(x fp.Element) []byte {
+	b := x.Bytes()
+	return append(make([]byte, 16), b[:]...)
+}
+
+func blsEncodeG1(p bls12381.G1Affine) []byte {
+	return append(blsEncodeFp(p.X), blsEncodeFp(p.Y)...)
+}
+
+func blsEncodeG2(q bls12381.G2Affine) []byte {
+	res := append(blsEncodeFp(q.X.A0), blsEncodeFp(q.X.A1)...)
+	res = append(res, blsEncodeFp(q.Y.A0)...)
+	return append(res, blsEncodeFp(q.Y.A1)...)
+}
+
+func blsEncodeScalar(s *big.Int) []byte {
+	return s.FillBytes(make([]byte, 32))
+}
+
+// blsNonCanonical returns a copy of the encoding where the coordinate at the
+// given offset is replaced by its value plus the modulus.
+func blsNonCanonical(enc []byte, offset int) []byte {
+	res := append([]byte{}, enc...)
+	x := new(big.Int).SetBytes(res[offset : offset+64])
+	x.Add(x, fp.Modulus())
+	x.FillBytes(res[offset : offset+64])
+	return res
+}
+
+// blsNonZeroPadding returns a copy of the encoding where the padding of the
+// coordinate at the given offset is not zero.
+func blsNonZeroPadding(enc []byte, offset int) []byte {
+	res := append([]byte{}, enc...)
+	res[offset] = 1
+	return res
+}
+
+// assertEncodedOutput asserts that the output of a successful call is the
+// expected one and that the success indicator is the expected one.
+func assertEncodedOutput(api frontend.API, res, expected []uints.U8, isSuccess, expectedSuccess frontend.Variable) {
+	api.AssertIsEqual(isSuccess, expectedSuccess)
+	for i := range res {
+		api.AssertIsEqual(api.Mul(isSuccess, api.Sub(res[i].Val, expected[i].Val)), 0)
+	}
+}
+
+type blsG1AddEncodedCircuit struct {
+	Input     [256]uints.U8
+	Expected  [128]uints.U8
+	IsSuccess frontend.Variable
+}
+
+func (c *blsG1AddEncodedCircuit) Define(api frontend.API) error {
+	res, isSuccess := ECAddG1BLSEncoded(api, c.Input)
+	assertEncodedOutput(api, res[:], c.Expected[:], isSuccess, c.IsSuccess)
+	return nil
+}
+
+func TestBLSG1AddEncoded(t *testing.T) {
+	assert := test.NewAssert(t)
+	p, _ := randomBLSG1G2()
+	q, _ := randomBLSG1G2()
+	var expected bls12381.G1Affine
+	expected.Add(&p, &q)
+	input := append(blsEncodeG1(p), blsEncodeG1(q)...)
+	for _, tc := range []struct {
+		name      string
+		input     []byte
+		isSuccess int
+	}{
+		{"valid", input, 1},
+		{"non-canonical", blsNonCanonical(input, 0), 0},
+		{"non-zero padding", blsNonZeroPadding(input, 192), 0},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			var witness blsG1AddEncodedCircuit
+			copy(witness.Input[:], uints.NewU8Array(tc.input))
+			copy(witness.Expected[:], uints.NewU8Array(blsEncodeG1(expected)))
+			witness.IsSuccess = tc.isSuccess
+			err := test.IsSolved(&blsG1AddEncodedCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type blsG1MSMEncodedCircuit struct {
+	Input     [160]uints.U8
+	Expected  [128]uints.U8
+	IsSuccess frontend.Variable
+}
+
+func (c *blsG1MSMEncodedCircuit) Define(api frontend.API) error {
+	res, isSuccess := ECMSMG1BLSEncoded(api, c.Input[:])
+	assertEncodedOutput(api, res[:], c.Expected[:], isSuccess, c.IsSuccess)
+	return nil
+}
+
+func TestBLSG1MSMEncoded(t *testing.T) {
+	assert := test.NewAssert(t)
+	p, _ := randomBLSG1G2()
+	pInvalid, _ := randomBLSCurvePoints()
+	// the scalars are not reduced modulo the group order
+	s := new(big.Int).Lsh(big.NewInt(1), 256)
+	s.Sub(s, big.NewInt(1))
+	var expected bls12381.G1Affine
+	expected.ScalarMultiplication(&p, s)
+	input := append(blsEncodeG1(p), blsEncodeScalar(s)...)
+	for _, tc := range []struct {
+		name      string
+		input     []byte
+		isSuccess int
+	}{
+		{"valid", input, 1},
+		{"non-canonical", blsNonCanonical(input, 64), 0},
+		{"non-zero padding", blsNonZeroPadding(input, 0), 0},
+		{"not in subgroup", append(blsEncodeG1(pInvalid), blsEncodeScalar(s)...), 0},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			var witness blsG1MSMEncodedCircuit
+			copy(witness.Input[:], uints.NewU8Array(tc.input))
+			copy(witness.Expected[:], uints.NewU8Array(blsEncodeG1(expected)))
+			witness.IsSuccess = tc.isSuccess
+			err := test.IsSolved(&blsG1MSMEncodedCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type blsG2AddEncodedCircuit struct {
+	Input     [512]uints.U8
+	Expected  [256]uints.U8
+	IsSuccess frontend.Variable
+}
+
+func (c *blsG2AddEncodedCircuit) Define(api frontend.API) error {
+	res, isSuccess := ECAddG2BLSEncoded(api, c.Input)
+	assertEncodedOutput(api, res[:], c.Expected[:], isSuccess, c.IsSuccess)
+	return nil
+}
+
+func TestBLSG2AddEncoded(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, p := randomBLSG1G2()
+	_, q := randomBLSG1G2()
+	var expected bls12381.G2Affine
+	expected.Add(&p, &q)
+	input := append(blsEncodeG2(p), blsEncodeG2(q)...)
+	for _, tc := range []struct {
+		name      string
+		input     []byte
+		isSuccess int
+	}{
+		{"valid", input, 1},
+		{"non-canonical", blsNonCanonical(input, 320), 0},
+		{"non-zero padding", blsNonZeroPadding(input, 64), 0},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			var witness blsG2AddEncodedCircuit
+			copy(witness.Input[:], uints.NewU8Array(tc.input))
+			copy(witness.Expected[:], uints.NewU8Array(blsEncodeG2(expected)))
+			witness.IsSuccess = tc.isSuccess
+			err := test.IsSolved(&blsG2AddEncodedCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type blsG2MSMEncodedCircuit struct {
+	Input     [288]uints.U8
+	Expected  [256]uints.U8
+	IsSuccess frontend.Variable
+}
+
+func (c *blsG2MSMEncodedCircuit) Define(api frontend.API) error {
+	res, isSuccess := ECMSMG2BLSEncoded(api, c.Input[:])
+	assertEncodedOutput(api, res[:], c.Expected[:], isSuccess, c.IsSuccess)
+	return nil
+}
+
+func TestBLSG2MSMEncoded(t *testing.T) {
+	assert := test.NewAssert(t)
+	_, p := randomBLSG1G2()
+	_, pInvalid := randomBLSCurvePoints()
+	var s fr.Element
+	s.SetRandom()
+	var expected bls12381.G2Affine
+	expected.ScalarMultiplication(&p, s.BigInt(new(big.Int)))
+	input := append(blsEncodeG2(p), blsEncodeScalar(s.BigInt(new(big.Int)))...)
+	for _, tc := range []struct {
+		name      string
+		input     []byte
+		isSuccess int
+	}{
+		{"valid", input, 1},
+		{"non-canonical", blsNonCanonical(input, 128), 0},
+		{"non-zero padding", blsNonZeroPadding(input, 192), 0},
+		{"not in subgroup", append(blsEncodeG2(pInvalid), blsEncodeScalar(s.BigInt(new(big.Int)))...), 0},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			var witness blsG2MSMEncodedCircuit
+			copy(witness.Input[:], uints.NewU8Array(tc.input))
+			copy(witness.Expected[:], uints.NewU8Array(blsEncodeG2(expected)))
+			witness.IsSuccess = tc.isSuccess
+			err := test.IsSolved(&blsG2MSMEncodedCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type blsPairEncodedCircuit struct {
+	Input     [768]uints.U8
+	Expected  [32]uints.U8
+	IsSuccess frontend.Variable
+}
+
+func (c *blsPairEncodedCircuit) Define(api frontend.API) error {
+	res, isSuccess := ECPairBLSEncoded(api, c.Input[:])
+	assertEncodedOutput(api, res[:], c.Expected[:], isSuccess, c.IsSuccess)
+	return nil
+}
+
+func TestBLSPairEncoded(t *testing.T) {
+	assert := test.NewAssert(t)
+	p, q := randomBLSG1G2()
+	_, qInvalid := randomBLSCurvePoints()
+	var pNeg bls12381.G1Affine
+	pNeg.Neg(&p)
+	// e(P, Q)·e(-P, Q) == 1
+	input := append(blsEncodeG1(p), blsEncodeG2(q)...)
+	input = append(input, blsEncodeG1(pNeg)...)
+	input = append(input, blsEncodeG2(q)...)
+	invalid := append(blsEncodeG1(p), blsEncodeG2(qInvalid)...)
+	invalid = append(invalid, input[384:]...)
+	expected := make([]byte, 32)
+	expected[31] = 1
+	for _, tc := range []struct {
+		name      string
+		input     []byte
+		isSuccess int
+	}{
+		{"valid", input, 1},
+		{"non-canonical", blsNonCanonical(input, 384+64), 0},
+		{"non-zero padding", blsNonZeroPadding(input, 128+192), 0},
+		{"not in subgroup", invalid, 0},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			var witness blsPairEncodedCircuit
+			copy(witness.Input[:], uints.NewU8Array(tc.input))
+			copy(witness.Expected[:], uints.NewU8Array(expected))
+			witness.IsSuccess = tc.isSuccess
+			err := test.IsSolved(&blsPairEncodedCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type blsMapToG1EncodedCircuit struct {
+	Input     [64]uints.U8
+	Expected  [128]uints.U8
+	IsSuccess frontend.Variable
+}
+
+func (c *blsMapToG1EncodedCircuit) Define(api frontend.API) error {
+	res, isSuccess := ECMapToG1BLSEncoded(api, c.Input)
+	assertEncodedOutput(api, res[:], c.Expected[:], isSuccess, c.IsSuccess)
+	return nil
+}
+
+func TestBLSMapToG1Encoded(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u fp.Element
+	u.SetRandom()
+	expected := bls12381.MapToG1(u)
+	input := blsEncodeFp(u)
+	for _, tc := range []struct {
+		name      string
+		input     []byte
+		isSuccess int
+	}{
+		{"valid", input, 1},
+		{"non-canonical", blsNonCanonical(input, 0), 0},
+		{"non-zero padding", blsNonZeroPadding(input, 15), 0},
+	} {
+		assert.Run(func(assert *test.Assert) {
+			var witness blsMapToG1EncodedCircuit
+			copy(witness.Input[:], uints.NewU8Array(tc.input))
+			copy(witness.Expected[:], uints.NewU8Array(blsEncodeG1(expected)))
+			witness.IsSuccess = tc.isSuccess
+			err := test.IsSolved(&blsMapToG1EncodedCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
+
+type blsMapToG2Circuit struct {
+	U        fields_bls12381.E2
+	Expected sw_bls12381.G2Affine
+}
+
+func (c *blsMapToG2Circuit) Define(api frontend.API) error {
+	g2 := sw_bls12381.NewG2(api)
+	res := ECMapToG2BLS(api, &c.U)
+	g2.AssertIsEqual(res, &c.Expected)
+	return nil
+}
+
+func TestBLSMapToG2(t *testing.T) {
+	assert := test.NewAssert(t)
+	var u bls12381.E2
+	u.SetRandom()
+	expected := bls12381.MapToG2(u)
+	witness := blsMapToG2Circuit{
+		U:        fields_bls12381.FromE2(&u),
+		Expected: sw_bls12381.NewG2Affine(expected),
+	}
+	err := test.IsSolved(&blsMapToG2Circuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+// blsMapToG2Vectors are the test vectors of BLS12_MAP_FP2_TO_G2 in EIP-2537,
+// which are the u and P values of the BLS12381G2_XMD:SHA-256_SSWU_NU_ suite of
+// RFC 9380 (Appendix J.10.2), as hex encoded c0, c1 of u and x.c0, x.c1, y.c0,
+// y.c1 of P.
+var blsMapToG2Vectors = []struct {
+	u [2]string
+	p [4]string
+}{
+	{
+		u: [2]string{"0x07355d25caf6e7f2f0cb2812ca0e513bd026ed09dda65b177500fa31714e09ea0ded3a078b526bed3307f804d4b93b04", "0x02829ce3c021339ccb5caf3e187f6370e1e2a311dec9b75363117063ab2015603ff52c3d3b98f19c2f65575e99e8b78c"},
+		p: [4]string{"0x00e7f4568a82b4b7dc1f14c6aaa055edf51502319c723c4dc2688c7fe5944c213f510328082396515734b6612c4e7bb7", "0x126b855e9e69b1f691f816e48ac6977664d24d99f8724868a184186469ddfd4617367e94527d4b74fc86413483afb35b", "0x0caead0fd7b6176c01436833c79d305c78be307da5f6af6c133c47311def6ff1e0babf57a0fb5539fce7ee12407b0a42", "0x1498aadcf7ae2b345243e281ae076df6de84455d766ab6fcdaad71fab60abb2e8b980a440043cd305db09d283c895e3d"},
+	},
+	{
+		u: [2]string{"0x138879a9559e24cecee8697b8b4ad32cced053138ab913b99872772dc753a2967ed50aabc907937aefb2439ba06cc50c", "0x0a1ae7999ea9bab1dcc9ef8887a6cb6e8f1e22566015428d220b7eec90ffa70ad1f624018a9ad11e78d588bd3617f9f2"},
+		p: [4]string{"0x108ed59fd9fae381abfd1d6bce2fd2fa220990f0f837fa30e0f27914ed6e1454db0d1ee957b219f61da6ff8be0d6441f", "0x0296238ea82c6d4adb3c838ee3cb2346049c90b96d602d7bb1b469b905c9228be25c627bffee872def773d5b2a2eb57d", "0x033f90f6057aadacae7963b0a0b379dd46750c1c94a6357c99b65f63b79e321ff50fe3053330911c56b6ceea08fee656", "0x153606c417e59fb331b7ae6bce4fbf7c5190c33ce9402b5ebe2b70e44fca614f3f1382a3625ed5493843d0b0a652fc3f"},
+	},
+	{
+		u: [2]string{"0x18c16fe362b7dbdfa102e42bdfd3e2f4e6191d479437a59db4eb716986bf08ee1f42634db66bde97d6c16bbfd342b3b8", "0x0e37812ce1b146d998d5f92bdd5ada2a31bfd63dfe18311aa91637b5f279dd045763166aa1615e46a50d8d8f475f184e"},
+		p: [4]string{"0x038af300ef34c7759a6caaa4e69363cafeed218a1f207e93b2c70d91a1263d375d6730bd6b6509dcac3ba5b567e85bf3", "0x0da75be60fb6aa0e9e3143e40c42796edf15685cafe0279afd2a67c3dff1c82341f17effd402e4f1af240ea90f4b659b", "0x19b148cbdf163cf0894f29660d2e7bfb2b68e37d54cc83fd4e6e62c020eaa48709302ef8e746736c0e19342cc1ce3df4", "0x0492f4fed741b073e5a82580f7c663f9b79e036b70ab3e51162359cec4e77c78086fe879b65ca7a47d34374c8315ac5e"},
+	},
+	{
+		u: [2]string{"0x08d4a0997b9d52fecf99427abb721f0fa779479963315fe21c6445250de7183e3f63bfdf86570da8929489e421d4ee95", "0x16cb4ccad91ec95aab070f22043916cd6a59c4ca94097f7f510043d48515526dc8eaaea27e586f09151ae613688d5a89"},
+		p: [4]string{"0x0c5ae723be00e6c3f0efe184fdc0702b64588fe77dda152ab13099a3bacd3876767fa7bbad6d6fd90b3642e902b208f9", "0x12c8c05c1d5fc7bfa847f4d7d81e294e66b9a78bc9953990c358945e1f042eedafce608b67fdd3ab0cb2e6e263b9b1ad", "0x04e77ddb3ede41b5ec4396b7421dd916efc68a358a0d7425bddd253547f2fb4830522358491827265dfc5bcc1928a569", "0x11c624c56dbe154d759d021eec60fab3d8b852395a89de497e48504366feedd4662d023af447d66926a28076813dd646"},
+	},
+	{
+		u: [2]string{"0x03f80ce4ff0ca2f576d797a3660e3f65b274285c054feccc3215c879e2c0589d376e83ede13f93c32f05da0f68fd6a10", "0x006488a837c5413746d868d1efb7232724da10eca410b07d8b505b9363bdccf0a1fc0029bad07d65b15ccfe6dd25e20d"},
+		p: [4]string{"0x0ea4e7c33d43e17cc516a72f76437c4bf81d8f4eac69ac355d3bf9b71b8138d55dc10fd458be115afa798b55dac34be1", "0x1565c2f625032d232f13121d3cfb476f45275c303a037faa255f9da62000c2c864ea881e2bcddd111edc4a3c0da3e88d", "0x043b6f5fe4e52c839148dc66f2b3751e69a0f6ebb3d056d6465d50d4108543ecd956e10fa1640dfd9bc0030cc2558d28", "0x0f8991d2a1ad662e7b6f58ab787947f1fa607fce12dde171bc17903b012091b657e15333e11701edcf5b63ba2a561247"},
+	},
+}
+
+// blsHexFp returns the base field element of the hex encoded integer.
+func blsHexFp(s string) fp.Element {
+	var x fp.Element
+	if _, err := x.SetString(s); err != nil {
+		panic(err)
+	}
+	return x
+}
+
+type blsMapToG2EncodedCircuit struct {
+	Input     [128]uints.U8
+	Expected  [256]uints.U8
+	IsSuccess frontend.Variable
+}
+
+func (c *blsMapToG2EncodedCircuit) Define(api frontend.API) error {
+	res, isSuccess := ECMapToG2BLSEncoded(api, c.Input)
+	assertEncodedOutput(api, res[:], c.Expected[:], isSuccess, c.IsSuccess)
+	return nil
+}
+
+func TestBLSMapToG2Encoded(t *testing.T) {
+	assert := test.NewAssert(t)
+	type testCase struct {
+		name      string
+		input     []byte
+		expected  bls12381.G2Affine
+		isSuccess int
+	}
+	var testCases []testCase
+	for i, v := range blsMapToG2Vectors {
+		var u bls12381.E2
+		u.A0, u.A1 = blsHexFp(v.u[0]), blsHexFp(v.u[1])
+		var expected bls12381.G2Affine
+		expected.X.A0, expected.X.A1 = blsHexFp(v.p[0]), blsHexFp(v.p[1])
+		expected.Y.A0, expected.Y.A1 = blsHexFp(v.p[2]), blsHexFp(v.p[3])
+		mapped := bls12381.MapToG2(u)
+		assert.True(expected.Equal(&mapped), "vector %d", i)
+		input := append(blsEncodeFp(u.A0), blsEncodeFp(u.A1)...)
+		testCases = append(testCases, testCase{fmt.Sprintf("vector %d", i), input, expected, 1})
+		if i == 0 {
+			testCases = append(testCases,
+				testCase{"non-canonical c0", blsNonCanonical(input, 0), expected, 0},
+				testCase{"non-canonical c1", blsNonCanonical(input, 64), expected, 0},
+				testCase{"non-zero padding c1", blsNonZeroPadding(input, 64+15), expected, 0},
+			)
+		}
+	}
+	for _, tc := range testCases {
+		assert.Run(func(assert *test.Assert) {
+			var witness blsMapToG2EncodedCircuit
+			copy(witness.Input[:], uints.NewU8Array(tc.input))
+			copy(witness.Expected[:], uints.NewU8Array(blsEncodeG2(tc.expected)))
+			witness.IsSuccess = tc.isSuccess
+			err := test.IsSolved(&blsMapToG2EncodedCircuit{}, &witness, ecc.BN254.ScalarField())
+			assert.NoError(err)
+		}, tc.name)
+	}
+}
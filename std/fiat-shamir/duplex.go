@@ -0,0 +1,149 @@
+package fiatshamir
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+var errInvalidSpongeWidth = errors.New("the duplex sponge needs a permutation of width at least 2")
+
+// Permutation is a cryptographic permutation over the native field, such as
+// [github.com/consensys/gnark/std/permutation/poseidon2.Permutation]. It is
+// used by [Sponge] to build a duplex sponge.
+type Permutation interface {
+	// Permutation applies the permutation on the state in place.
+	Permutation(state []frontend.Variable) error
+}
+
+// Sponge is an in-circuit duplex sponge built on top of a [Permutation]. The
+// first element of the state is the capacity and the remaining elements are
+// the rate. Inputs are added into the rate and outputs are read from the rate,
+// and the permutation is applied when the rate is exhausted or when switching
+// from absorbing to squeezing. Before switching to squeezing, the absorbed
+// inputs are padded with a one followed by zeros up to the end of the rate
+// (10* padding), so that absorbing a sequence and the same sequence followed by
+// zeros lead to different outputs.
+//
+// The native counterpart is [NativeSponge].
+type Sponge struct {
+	api       frontend.API
+	perm      Permutation
+	state     []frontend.Variable
+	pos       int
+	squeezing bool
+}
+
+// NewSponge returns a new duplex sponge with the given permutation working on
+// states of the given width. The state is initialised to zero.
+func NewSponge(api frontend.API, perm Permutation, width int) (*Sponge, error) {
+	if width < 2 {
+		return nil, errInvalidSpongeWidth
+	}
+	state := make([]frontend.Variable, width)
+	for i := range state {
+		state[i] = 0
+	}
+	return &Sponge{api: api, perm: perm, state: state}, nil
+}
+
+// Absorb adds the values into the state of the sponge.
+func (s *Sponge) Absorb(values ...frontend.Variable) error {
+	rate := len(s.state) - 1
+	for _, v := range values {
+		if s.squeezing {
+			s.squeezing = false
+			s.pos = 0
+		}
+		if s.pos == rate {
+			if err := s.perm.Permutation(s.state); err != nil {
+				return err
+			}
+			s.pos = 0
+		}
+		s.state[1+s.pos] = s.api.Add(s.state[1+s.pos], v)
+		s.pos++
+	}
+	return nil
+}
+
+// Squeeze returns the next output element of the sponge.
+func (s *Sponge) Squeeze() (frontend.Variable, error) {
+	rate := len(s.state) - 1
+	if !s.squeezing {
+		if s.pos == rate {
+			if err := s.perm.Permutation(s.state); err != nil {
+				return nil, err
+			}
+			s.pos = 0
+		}
+		s.state[1+s.pos] = s.api.Add(s.state[1+s.pos], 1)
+		s.pos = rate
+	}
+	if s.pos == rate {
+		if err := s.perm.Permutation(s.state); err != nil {
+			return nil, err
+		}
+		s.squeezing = true
+		s.pos = 0
+	}
+	res := s.state[1+s.pos]
+	s.pos++
+	return res, nil
+}
+
+// NewDuplexTranscript returns a new transcript in duplex-sponge mode. Instead of
+// hashing every challenge independently, a single [Sponge] is kept for the
+// whole transcript. Computing a challenge absorbs the challenge name (see
+// [domainSeparator]) and the binded values into the sponge and squeezes one
+// element. As the sponge state is carried over, every challenge depends on all
+// the previous ones and the previous challenge is not absorbed again.
+//
+// The permutation perm works on states of the given width. The options
+// modifying the hashing mode (e.g. [WithTryBitmode]) have no effect in this
+// mode. The native counterpart is [NewNativeDuplexTranscript]. The PLONK
+// recursive verifier uses this transcript with the option
+// [github.com/consensys/gnark/std/recursion/plonk.WithDuplexTranscript], the
+// native prover computing the challenges with
+// [github.com/consensys/gnark/std/recursion.NewNativeDuplexTranscript].
+func NewDuplexTranscript(api frontend.API, perm Permutation, width int, challengesID []string, opts ...TranscriptOption) (*Transcript, error) {
+	sponge, err := NewSponge(api, perm, width)
+	if err != nil {
+		return nil, err
+	}
+	t := NewTranscript(api, nil, challengesID, opts...)
+	t.sponge = sponge
+	return t, nil
+}
+
+// computeChallengeDuplex derives the challenge value from the transcript sponge.
+func (t *Transcript) computeChallengeDuplex(challengeID string, bindings []frontend.Variable) (frontend.Variable, error) {
+	for _, e := range domainSeparator(challengeID, t.api.Compiler().Field()) {
+		if err := t.sponge.Absorb(e); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.sponge.Absorb(bindings...); err != nil {
+		return nil, err
+	}
+	return t.sponge.Squeeze()
+}
+
+// domainSeparator returns the encoding of the challenge name as field elements.
+// The name is split into chunks which fit into a field element and every chunk
+// is interpreted as a big-endian integer. The first element is the length of
+// the name in bytes so that the encoding is injective.
+func domainSeparator(challengeID string, modulus *big.Int) []*big.Int {
+	chunkSize := (modulus.BitLen() - 1) / 8
+	name := []byte(challengeID)
+	res := []*big.Int{big.NewInt(int64(len(name)))}
+	for i := 0; i < len(name); i += chunkSize {
+		end := i + chunkSize
+		if end > len(name) {
+			end = len(name)
+		}
+		res = append(res, new(big.Int).SetBytes(name[i:end]))
+	}
+	return res
+}
@@ -0,0 +1,162 @@
+package fiatshamir
+
+import (
+	"math/big"
+)
+
+// NativePermutation is a cryptographic permutation over a prime field, such as
+// [github.com/consensys/gnark/std/permutation/poseidon2.Parameters]. It is the
+// native counterpart of [Permutation].
+type NativePermutation interface {
+	// Permute applies the permutation on the state in place. The inputs are
+	// reduced modulo the field order.
+	Permute(state []*big.Int) error
+}
+
+// NativeSponge is the native counterpart of [Sponge]. It can be used to compute
+// off-circuit the values squeezed in-circuit.
+type NativeSponge struct {
+	perm      NativePermutation
+	modulus   *big.Int
+	state     []*big.Int
+	pos       int
+	squeezing bool
+}
+
+// NewNativeSponge returns a new native duplex sponge with the given permutation
+// working on states of the given width over the field of the given modulus.
+func NewNativeSponge(perm NativePermutation, width int, modulus *big.Int) (*NativeSponge, error) {
+	if width < 2 {
+		return nil, errInvalidSpongeWidth
+	}
+	state := make([]*big.Int, width)
+	for i := range state {
+		state[i] = new(big.Int)
+	}
+	return &NativeSponge{perm: perm, modulus: new(big.Int).Set(modulus), state: state}, nil
+}
+
+// Absorb adds the values into the state of the sponge.
+func (s *NativeSponge) Absorb(values ...*big.Int) error {
+	rate := len(s.state) - 1
+	for _, v := range values {
+		if s.squeezing {
+			s.squeezing = false
+			s.pos = 0
+		}
+		if s.pos == rate {
+			if err := s.perm.Permute(s.state); err != nil {
+				return err
+			}
+			s.pos = 0
+		}
+		s.state[1+s.pos].Add(s.state[1+s.pos], v).Mod(s.state[1+s.pos], s.modulus)
+		s.pos++
+	}
+	return nil
+}
+
+// Squeeze returns the next output element of the sponge.
+func (s *NativeSponge) Squeeze() (*big.Int, error) {
+	rate := len(s.state) - 1
+	if !s.squeezing {
+		if s.pos == rate {
+			if err := s.perm.Permute(s.state); err != nil {
+				return nil, err
+			}
+			s.pos = 0
+		}
+		s.state[1+s.pos].Add(s.state[1+s.pos], big.NewInt(1)).Mod(s.state[1+s.pos], s.modulus)
+		s.pos = rate
+	}
+	if s.pos == rate {
+		if err := s.perm.Permute(s.state); err != nil {
+			return nil, err
+		}
+		s.squeezing = true
+		s.pos = 0
+	}
+	res := new(big.Int).Set(s.state[1+s.pos])
+	s.pos++
+	return res, nil
+}
+
+// NativeDuplexTranscript is the native counterpart of the [Transcript] in
+// duplex-sponge mode (see [NewDuplexTranscript]).
+type NativeDuplexTranscript struct {
+	sponge     *NativeSponge
+	challenges map[string]nativeChallenge
+	previous   int
+}
+
+type nativeChallenge struct {
+	position   int
+	bindings   []*big.Int
+	value      *big.Int
+	isComputed bool
+}
+
+// NewNativeDuplexTranscript returns a new native transcript in duplex-sponge
+// mode. The arguments are the same as for [NewDuplexTranscript], with the
+// addition of the modulus of the field.
+func NewNativeDuplexTranscript(perm NativePermutation, width int, modulus *big.Int, challengesID []string) (*NativeDuplexTranscript, error) {
+	sponge, err := NewNativeSponge(perm, width, modulus)
+	if err != nil {
+		return nil, err
+	}
+	t := NativeDuplexTranscript{
+		sponge:     sponge,
+		challenges: make(map[string]nativeChallenge, len(challengesID)),
+		previous:   -1,
+	}
+	for i := range challengesID {
+		t.challenges[challengesID[i]] = nativeChallenge{position: i}
+	}
+	return &t, nil
+}
+
+// Bind binds the challenge to values. See [Transcript.Bind].
+func (t *NativeDuplexTranscript) Bind(challengeID string, values ...*big.Int) error {
+	challenge, ok := t.challenges[challengeID]
+	if !ok {
+		return errChallengeNotFound
+	}
+	if challenge.isComputed {
+		return errChallengeAlreadyComputed
+	}
+	for _, v := range values {
+		challenge.bindings = append(challenge.bindings, new(big.Int).Mod(v, t.sponge.modulus))
+	}
+	t.challenges[challengeID] = challenge
+	return nil
+}
+
+// ComputeChallenge computes the challenge corresponding to the given name. See
+// [Transcript.ComputeChallenge].
+func (t *NativeDuplexTranscript) ComputeChallenge(challengeID string) (*big.Int, error) {
+	challenge, ok := t.challenges[challengeID]
+	if !ok {
+		return nil, errChallengeNotFound
+	}
+	if challenge.isComputed {
+		return new(big.Int).Set(challenge.value), nil
+	}
+	if challenge.position != t.previous+1 {
+		return nil, errPreviousChallengeNotComputed
+	}
+	if err := t.sponge.Absorb(domainSeparator(challengeID, t.sponge.modulus)...); err != nil {
+		return nil, err
+	}
+	if err := t.sponge.Absorb(challenge.bindings...); err != nil {
+		return nil, err
+	}
+	value, err := t.sponge.Squeeze()
+	if err != nil {
+		return nil, err
+	}
+	challenge.value = value
+	challenge.isComputed = true
+	t.previous = challenge.position
+	t.challenges[challengeID] = challenge
+	return new(big.Int).Set(value), nil
+}
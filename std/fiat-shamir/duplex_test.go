@@ -0,0 +1,138 @@
+package fiatshamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/permutation/poseidon2"
+	"github.com/consensys/gnark/test"
+)
+
+type duplexCircuit struct {
+	Bindings   [3][4]frontend.Variable `gnark:",public"`
+	Challenges [3]frontend.Variable    `gnark:",secret"`
+	width      int
+}
+
+func (circuit *duplexCircuit) Define(api frontend.API) error {
+	perm, err := poseidon2.NewPoseidon2FromParameters(api, circuit.width, 8, 56)
+	if err != nil {
+		return err
+	}
+	names := []string{"alpha", "beta", "gamma-challenge-with-a-rather-long-name"}
+	ts, err := NewDuplexTranscript(api, perm, circuit.width, names)
+	if err != nil {
+		return err
+	}
+	for i := range names {
+		if err := ts.Bind(names[i], circuit.Bindings[i][:]); err != nil {
+			return err
+		}
+	}
+	for i := range names {
+		c, err := ts.ComputeChallenge(names[i])
+		if err != nil {
+			return err
+		}
+		api.AssertIsEqual(c, circuit.Challenges[i])
+	}
+	return nil
+}
+
+func TestDuplexTranscript(t *testing.T) {
+	assert := test.NewAssert(t)
+	names := []string{"alpha", "beta", "gamma-challenge-with-a-rather-long-name"}
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_381} {
+		for _, width := range []int{2, 3} {
+			params, err := poseidon2.NewParameters(curve, width, 8, 56)
+			assert.NoError(err)
+			ts, err := NewNativeDuplexTranscript(params, width, curve.ScalarField(), names)
+			assert.NoError(err)
+			witness := duplexCircuit{width: width}
+			for i := range names {
+				for j := 0; j < 4; j++ {
+					b, err := rand.Int(rand.Reader, curve.ScalarField())
+					assert.NoError(err)
+					witness.Bindings[i][j] = b
+					assert.NoError(ts.Bind(names[i], b))
+				}
+			}
+			_, err = ts.ComputeChallenge(names[1])
+			assert.ErrorIs(err, errPreviousChallengeNotComputed)
+			for i := range names {
+				c, err := ts.ComputeChallenge(names[i])
+				assert.NoError(err)
+				witness.Challenges[i] = c
+			}
+			assert.ErrorIs(ts.Bind(names[0], big.NewInt(1)), errChallengeAlreadyComputed)
+			err = test.IsSolved(&duplexCircuit{width: width}, &witness, curve.ScalarField())
+			assert.NoError(err)
+
+			witness.Challenges[2] = new(big.Int).Add(witness.Challenges[2].(*big.Int), big.NewInt(1))
+			err = test.IsSolved(&duplexCircuit{width: width}, &witness, curve.ScalarField())
+			assert.Error(err)
+		}
+	}
+}
+
+type spongeCircuit struct {
+	Inputs   []frontend.Variable
+	Expected frontend.Variable
+	width    int
+}
+
+func (circuit *spongeCircuit) Define(api frontend.API) error {
+	perm, err := poseidon2.NewPoseidon2FromParameters(api, circuit.width, 8, 56)
+	if err != nil {
+		return err
+	}
+	sponge, err := NewSponge(api, perm, circuit.width)
+	if err != nil {
+		return err
+	}
+	if err := sponge.Absorb(circuit.Inputs...); err != nil {
+		return err
+	}
+	res, err := sponge.Squeeze()
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(res, circuit.Expected)
+	return nil
+}
+
+func TestSpongePadding(t *testing.T) {
+	assert := test.NewAssert(t)
+	curve := ecc.BN254
+	for _, width := range []int{2, 3} {
+		params, err := poseidon2.NewParameters(curve, width, 8, 56)
+		assert.NoError(err)
+		// inputs which only differ by trailing zeros, up to and across the
+		// end of the rate
+		outputs := make(map[string]int)
+		for nbInputs := 0; nbInputs < 2*width; nbInputs++ {
+			sponge, err := NewNativeSponge(params, width, curve.ScalarField())
+			assert.NoError(err)
+			inputs := make([]*big.Int, nbInputs)
+			for i := range inputs {
+				inputs[i] = new(big.Int)
+			}
+			assert.NoError(sponge.Absorb(inputs...))
+			res, err := sponge.Squeeze()
+			assert.NoError(err)
+			prev, ok := outputs[res.String()]
+			assert.False(ok, "collision between %d and %d zero inputs", prev, nbInputs)
+			outputs[res.String()] = nbInputs
+
+			witness := spongeCircuit{Inputs: make([]frontend.Variable, nbInputs), Expected: res}
+			for i := range witness.Inputs {
+				witness.Inputs[i] = 0
+			}
+			err = test.IsSolved(&spongeCircuit{Inputs: make([]frontend.Variable, nbInputs), width: width}, &witness, curve.ScalarField())
+			assert.NoError(err)
+		}
+	}
+}
@@ -37,6 +37,8 @@ var (
 type Transcript struct {
 	// hash function that is used.
 	h hash.FieldHasher
+	// sponge is used instead of h in duplex-sponge mode.
+	sponge *Sponge
 
 	challenges map[string]challenge
 	previous   *challenge
@@ -103,6 +105,10 @@ func (t *Transcript) Bind(challengeID string, values []frontend.Variable) error
 // The resulting variable is:
 //   - H(name ∥ previous_challenge ∥ binded_values...) if the challenge is not the first one
 //   - H(name ∥ binded_values... ) if it's is the first challenge
+//
+// In duplex-sponge mode (see [NewDuplexTranscript]) the name and the binded
+// values are absorbed into the transcript sponge and the challenge is squeezed
+// from it.
 func (t *Transcript) ComputeChallenge(challengeID string) (frontend.Variable, error) {
 	challenge, ok := t.challenges[challengeID]
 
@@ -115,6 +121,21 @@ func (t *Transcript) ComputeChallenge(challengeID string) (frontend.Variable, er
 		return challenge.value, nil
 	}
 
+	if t.sponge != nil {
+		if challenge.position != 0 && (t.previous == nil || (t.previous.position != challenge.position-1)) {
+			return nil, errPreviousChallengeNotComputed
+		}
+		value, err := t.computeChallengeDuplex(challengeID, challenge.bindings)
+		if err != nil {
+			return nil, err
+		}
+		challenge.value = value
+		challenge.isComputed = true
+		t.previous = &challenge
+		t.challenges[challengeID] = challenge
+		return challenge.value, nil
+	}
+
 	t.h.Reset()
 
 	// write the challenge name, the purpose is to have a domain separator
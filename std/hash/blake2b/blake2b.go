@@ -0,0 +1,226 @@
+// Package blake2b implements the BLAKE2b hash function.
+//
+// The implementation follows RFC 7693 and corresponds to
+// golang.org/x/crypto/blake2b, including keyed hashing and truncated digests.
+// The message is processed by blocks of 128 bytes using the compression
+// function [Compress] over 64-bit words, which is implemented on top of
+// [uints.BinaryField] of [uints.U64].
+package blake2b
+
+import (
+	"errors"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+const (
+	// BlockSize is the block size of BLAKE2b in bytes.
+	BlockSize = 128
+	// Size is the size of a BLAKE2b-512 digest in bytes.
+	Size = 64
+	// Size384 is the size of a BLAKE2b-384 digest in bytes.
+	Size384 = 48
+	// Size256 is the size of a BLAKE2b-256 digest in bytes.
+	Size256 = 32
+	// NbRounds is the number of rounds of the compression function.
+	NbRounds = 12
+)
+
+var (
+	ErrInvalidSize    = errors.New("blake2b: invalid hash size")
+	ErrInvalidKeySize = errors.New("blake2b: invalid key size")
+)
+
+type digest struct {
+	api  frontend.API
+	uapi *uints.BinaryField[uints.U64]
+	size int
+	key  []uints.U8
+	in   []uints.U8
+}
+
+// New returns a new BLAKE2b hash computing a digest of size bytes, keyed with
+// key. The size must be between 1 and 64 and the key at most 64 bytes long. A
+// nil key gives the unkeyed hash.
+func New(api frontend.API, size int, key []uints.U8) (hash.BinaryFixedLengthHasher, error) {
+	if size < 1 || size > Size {
+		return nil, ErrInvalidSize
+	}
+	if len(key) > Size {
+		return nil, ErrInvalidKeySize
+	}
+	uapi, err := uints.New[uints.U64](api)
+	if err != nil {
+		return nil, err
+	}
+	return &digest{api: api, uapi: uapi, size: size, key: key}, nil
+}
+
+// New512 returns a new unkeyed BLAKE2b-512 hash.
+func New512(api frontend.API) (hash.BinaryFixedLengthHasher, error) {
+	return New(api, Size, nil)
+}
+
+// New384 returns a new unkeyed BLAKE2b-384 hash.
+func New384(api frontend.API) (hash.BinaryFixedLengthHasher, error) {
+	return New(api, Size384, nil)
+}
+
+// New256 returns a new unkeyed BLAKE2b-256 hash.
+func New256(api frontend.API) (hash.BinaryFixedLengthHasher, error) {
+	return New(api, Size256, nil)
+}
+
+func (d *digest) Write(data []uints.U8) {
+	d.in = append(d.in, data...)
+}
+
+func (d *digest) Reset() {
+	d.in = nil
+}
+
+func (d *digest) Size() int { return d.size }
+
+// data returns the key padded to a full block, if any, followed by the input.
+func (d *digest) data() []uints.U8 {
+	var res []uints.U8
+	if len(d.key) > 0 {
+		res = append(res, d.key...)
+		res = append(res, uints.NewU8Array(make([]uint8, BlockSize-len(d.key)))...)
+	}
+	return append(res, d.in...)
+}
+
+func (d *digest) initState() [8]uints.U64 {
+	var h [8]uints.U64
+	for i := range h {
+		h[i] = uints.NewU64(_iv[i])
+	}
+	h[0] = uints.NewU64(_iv[0] ^ 0x01010000 ^ uint64(len(d.key))<<8 ^ uint64(d.size))
+	return h
+}
+
+func nbBlocks(length int) int {
+	if length == 0 {
+		return 1
+	}
+	return (length + BlockSize - 1) / BlockSize
+}
+
+func block(data []uints.U8, i int) [16]uints.U64 {
+	var buf [BlockSize]uints.U8
+	for j := range buf {
+		if i*BlockSize+j < len(data) {
+			buf[j] = data[i*BlockSize+j]
+		} else {
+			buf[j] = uints.NewU8(0)
+		}
+	}
+	var m [16]uints.U64
+	for j := range m {
+		copy(m[j][:], buf[8*j:8*j+8])
+	}
+	return m
+}
+
+func (d *digest) output(h [8]uints.U64) []uints.U8 {
+	var res []uints.U8
+	for i := range h {
+		res = append(res, d.uapi.UnpackLSB(h[i])...)
+	}
+	return res[:d.size]
+}
+
+// Sum returns the digest of all the data written.
+func (d *digest) Sum() []uints.U8 {
+	data := d.data()
+	h := d.initState()
+	zero := uints.NewU64(0)
+	n := nbBlocks(len(data))
+	for i := 0; i < n; i++ {
+		t := uint64((i + 1) * BlockSize)
+		f := zero
+		if i == n-1 {
+			t = uint64(len(data))
+			f = uints.NewU64(^uint64(0))
+		}
+		h = Compress(d.uapi, h, block(data, i), [2]uints.U64{uints.NewU64(t), zero}, f, NbRounds)
+	}
+	return d.output(h)
+}
+
+// FixedLengthSum returns the digest of the first length bytes written. The
+// length must be at most the number of bytes written, which defines the number
+// of blocks processed in-circuit.
+func (d *digest) FixedLengthSum(length frontend.Variable) []uints.U8 {
+	api := d.api
+	data := d.data()
+	offset := len(data) - len(d.in)
+	total := api.Add(length, offset)
+
+	// isEnd[p] is 1 iff p is the total length of the data to hash. Exactly
+	// one of them is set, which ensures that 0 <= length <= len(d.in).
+	isEnd := make([]frontend.Variable, len(data)+1)
+	for p := range isEnd {
+		if p < offset {
+			isEnd[p] = 0
+		} else {
+			isEnd[p] = api.IsZero(api.Sub(total, p))
+		}
+	}
+	api.AssertIsEqual(api.Add(0, 0, isEnd...), 1)
+
+	// mask the bytes after the end of the data
+	var ended frontend.Variable = 0
+	masked := make([]uints.U8, len(data))
+	for p := range data {
+		ended = api.Add(ended, isEnd[p])
+		if p < offset {
+			masked[p] = data[p]
+		} else {
+			masked[p] = uints.U8{Val: api.Mul(data[p].Val, api.Sub(1, ended))}
+		}
+	}
+
+	totalU64 := d.uapi.ValueOf(total)
+	api.AssertIsEqual(d.uapi.ToValue(totalU64), total)
+
+	zero := uints.NewU64(0)
+	h := d.initState()
+	var res [8]uints.U64
+	for i := 0; i < nbBlocks(len(data)); i++ {
+		// the block is the last one if the end of the data is in (iB, (i+1)B],
+		// or [0, B] for the first block.
+		var isLast frontend.Variable = 0
+		for p := i*BlockSize + 1; p <= (i+1)*BlockSize && p < len(isEnd); p++ {
+			isLast = api.Add(isLast, isEnd[p])
+		}
+		if i == 0 {
+			isLast = api.Add(isLast, isEnd[0])
+		}
+		t := selectU64(api, isLast, totalU64, uints.NewU64(uint64((i+1)*BlockSize)))
+		var f uints.U64
+		for j := range f {
+			f[j] = uints.U8{Val: api.Mul(isLast, 0xff)}
+		}
+		h = Compress(d.uapi, h, block(masked, i), [2]uints.U64{t, zero}, f, NbRounds)
+		for j := range res {
+			if i == 0 {
+				res[j] = h[j]
+			} else {
+				res[j] = selectU64(api, isLast, h[j], res[j])
+			}
+		}
+	}
+	return d.output(res)
+}
+
+func selectU64(api frontend.API, sel frontend.Variable, a, b uints.U64) uints.U64 {
+	var res uints.U64
+	for i := range res {
+		res[i] = uints.U8{Val: api.Select(sel, a[i].Val, b[i].Val)}
+	}
+	return res
+}
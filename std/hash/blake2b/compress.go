@@ -0,0 +1,107 @@
+package blake2b
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+var _iv = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+var _sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Compress applies the compression function F of BLAKE2b (RFC 7693, Section
+// 3.2) with the given number of rounds on the state h, the message block m and
+// the offset counter t. The final block flag f is XORed into the working
+// vector, it must be either zero or all-ones for the last block. It returns the
+// new state.
+//
+// The standard hash uses 12 rounds, but the number of rounds is a parameter of
+// the BLAKE2F precompile of EIP-152.
+func Compress(uapi *uints.BinaryField[uints.U64], h [8]uints.U64, m [16]uints.U64, t [2]uints.U64, f uints.U64, rounds int) [8]uints.U64 {
+	v := initVector(uapi, h, t, f)
+	for i := 0; i < rounds; i++ {
+		round(uapi, &v, &m, i)
+	}
+	return finalize(uapi, h, &v)
+}
+
+// CompressVariableRounds is as [Compress], but the number of rounds is a
+// variable in the range [0, maxRounds]. The circuit always computes maxRounds
+// rounds and selects the working vector after the requested number of rounds.
+// It fails to solve if rounds is larger than maxRounds.
+func CompressVariableRounds(api frontend.API, uapi *uints.BinaryField[uints.U64], h [8]uints.U64, m [16]uints.U64, t [2]uints.U64, f uints.U64, rounds frontend.Variable, maxRounds int) [8]uints.U64 {
+	v := initVector(uapi, h, t, f)
+	// done is set when the requested number of rounds has been applied
+	done := api.IsZero(rounds)
+	for i := 0; i < maxRounds; i++ {
+		next := v
+		round(uapi, &next, &m, i)
+		for j := range v {
+			v[j] = selectU64(api, done, v[j], next[j])
+		}
+		done = api.Or(done, api.IsZero(api.Sub(rounds, i+1)))
+	}
+	api.AssertIsEqual(done, 1)
+	return finalize(uapi, h, &v)
+}
+
+// initVector initializes the working vector of the compression function.
+func initVector(uapi *uints.BinaryField[uints.U64], h [8]uints.U64, t [2]uints.U64, f uints.U64) [16]uints.U64 {
+	var v [16]uints.U64
+	copy(v[:8], h[:])
+	for i := range _iv {
+		v[8+i] = uints.NewU64(_iv[i])
+	}
+	v[12] = uapi.Xor(v[12], t[0])
+	v[13] = uapi.Xor(v[13], t[1])
+	v[14] = uapi.Xor(v[14], f)
+	return v
+}
+
+// round applies the i-th round of the compression function on the working
+// vector v.
+func round(uapi *uints.BinaryField[uints.U64], v *[16]uints.U64, m *[16]uints.U64, i int) {
+	s := &_sigma[i%10]
+	g(uapi, v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+	g(uapi, v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+	g(uapi, v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+	g(uapi, v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+	g(uapi, v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+	g(uapi, v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+	g(uapi, v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+	g(uapi, v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+}
+
+// finalize XORs both halves of the working vector into the state h.
+func finalize(uapi *uints.BinaryField[uints.U64], h [8]uints.U64, v *[16]uints.U64) [8]uints.U64 {
+	for i := range h {
+		h[i] = uapi.Xor(h[i], v[i], v[i+8])
+	}
+	return h
+}
+
+// g is the mixing function G of BLAKE2b.
+func g(uapi *uints.BinaryField[uints.U64], v *[16]uints.U64, a, b, c, d int, x, y uints.U64) {
+	v[a] = uapi.Add(v[a], v[b], x)
+	v[d] = uapi.Lrot(uapi.Xor(v[d], v[a]), -32)
+	v[c] = uapi.Add(v[c], v[d])
+	v[b] = uapi.Lrot(uapi.Xor(v[b], v[c]), -24)
+	v[a] = uapi.Add(v[a], v[b], y)
+	v[d] = uapi.Lrot(uapi.Xor(v[d], v[a]), -16)
+	v[c] = uapi.Add(v[c], v[d])
+	v[b] = uapi.Lrot(uapi.Xor(v[b], v[c]), -63)
+}
@@ -0,0 +1,148 @@
+package blake2s
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/blake2s"
+)
+
+type blake2sCircuit struct {
+	In       []uints.U8
+	Key      []uints.U8
+	Expected []uints.U8
+}
+
+func (c *blake2sCircuit) Define(api frontend.API) error {
+	h, err := New(api, len(c.Expected), c.Key)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In)
+	res := h.Sum()
+	if len(res) != len(c.Expected) {
+		return fmt.Errorf("expected %d bytes, got %d", len(c.Expected), len(res))
+	}
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestBlake2s(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, tc := range []struct{ inLen, keyLen, size int }{
+		{0, 0, Size},
+		{3, 0, Size},
+		{64, 0, Size},
+		{200, 0, Size},
+		{0, 32, Size},
+		{150, 16, Size128},
+	} {
+		in := make([]byte, tc.inLen)
+		_, err := rand.Reader.Read(in)
+		assert.NoError(err)
+		key := make([]byte, tc.keyLen)
+		_, err = rand.Reader.Read(key)
+		assert.NoError(err)
+
+		newHash := blake2s.New256
+		if tc.size == Size128 {
+			newHash = blake2s.New128
+		}
+		h, err := newHash(key)
+		assert.NoError(err)
+		h.Write(in)
+		expected := h.Sum(nil)
+
+		circuit := &blake2sCircuit{
+			In:       make([]uints.U8, len(in)),
+			Key:      make([]uints.U8, len(key)),
+			Expected: make([]uints.U8, len(expected)),
+		}
+		witness := &blake2sCircuit{
+			In:       uints.NewU8Array(in),
+			Key:      uints.NewU8Array(key),
+			Expected: uints.NewU8Array(expected),
+		}
+		err = test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+		assert.NoError(err, "in=%d key=%d size=%d", tc.inLen, tc.keyLen, tc.size)
+	}
+}
+
+type blake2sFixedLengthCircuit struct {
+	In       []uints.U8
+	Key      []uints.U8
+	Length   frontend.Variable
+	Expected [Size]uints.U8
+}
+
+func (c *blake2sFixedLengthCircuit) Define(api frontend.API) error {
+	h, err := New(api, Size, c.Key)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In)
+	res := h.FixedLengthSum(c.Length)
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestBlake2sFixedLength(t *testing.T) {
+	assert := test.NewAssert(t)
+	in := make([]byte, 300)
+	_, err := rand.Reader.Read(in)
+	assert.NoError(err)
+	for _, keyLen := range []int{0, 16} {
+		key := make([]byte, keyLen)
+		_, err = rand.Reader.Read(key)
+		assert.NoError(err)
+		circuit := &blake2sFixedLengthCircuit{
+			In:  make([]uints.U8, len(in)),
+			Key: make([]uints.U8, len(key)),
+		}
+		for _, length := range []int{0, 1, 63, 64, 65, 128, 300} {
+			h, err := blake2s.New256(key)
+			assert.NoError(err)
+			h.Write(in[:length])
+			var expected [Size]byte
+			copy(expected[:], h.Sum(nil))
+
+			witness := &blake2sFixedLengthCircuit{
+				In:     uints.NewU8Array(in),
+				Key:    uints.NewU8Array(key),
+				Length: length,
+			}
+			copy(witness.Expected[:], uints.NewU8Array(expected[:]))
+			err = test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+			assert.NoError(err, "key=%d length=%d", keyLen, length)
+		}
+
+		// the length cannot exceed the written data
+		witness := &blake2sFixedLengthCircuit{
+			In:     uints.NewU8Array(in),
+			Key:    uints.NewU8Array(key),
+			Length: len(in) + 1,
+		}
+		for i := range witness.Expected {
+			witness.Expected[i] = uints.NewU8(0)
+		}
+		err = test.IsSolved(circuit, witness, ecc.BN254.ScalarField())
+		assert.Error(err)
+	}
+}
@@ -0,0 +1,68 @@
+package blake2s
+
+import (
+	"github.com/consensys/gnark/std/math/uints"
+)
+
+var _iv = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a,
+	0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+var _sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Compress applies the compression function F of BLAKE2s (RFC 7693, Section
+// 3.2) with the given number of rounds on the state h, the message block m and
+// the offset counter t. The final block flag f is XORed into the working
+// vector, it must be either zero or all-ones for the last block. It returns the
+// new state. The standard hash uses [NbRounds] rounds.
+func Compress(uapi *uints.BinaryField[uints.U32], h [8]uints.U32, m [16]uints.U32, t [2]uints.U32, f uints.U32, rounds int) [8]uints.U32 {
+	var v [16]uints.U32
+	copy(v[:8], h[:])
+	for i := range _iv {
+		v[8+i] = uints.NewU32(_iv[i])
+	}
+	v[12] = uapi.Xor(v[12], t[0])
+	v[13] = uapi.Xor(v[13], t[1])
+	v[14] = uapi.Xor(v[14], f)
+
+	for i := 0; i < rounds; i++ {
+		s := &_sigma[i%10]
+		g(uapi, &v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		g(uapi, &v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		g(uapi, &v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		g(uapi, &v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		g(uapi, &v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		g(uapi, &v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		g(uapi, &v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		g(uapi, &v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := range h {
+		h[i] = uapi.Xor(h[i], v[i], v[i+8])
+	}
+	return h
+}
+
+// g is the mixing function G of BLAKE2s.
+func g(uapi *uints.BinaryField[uints.U32], v *[16]uints.U32, a, b, c, d int, x, y uints.U32) {
+	v[a] = uapi.Add(v[a], v[b], x)
+	v[d] = uapi.Lrot(uapi.Xor(v[d], v[a]), -16)
+	v[c] = uapi.Add(v[c], v[d])
+	v[b] = uapi.Lrot(uapi.Xor(v[b], v[c]), -12)
+	v[a] = uapi.Add(v[a], v[b], y)
+	v[d] = uapi.Lrot(uapi.Xor(v[d], v[a]), -8)
+	v[c] = uapi.Add(v[c], v[d])
+	v[b] = uapi.Lrot(uapi.Xor(v[b], v[c]), -7)
+}
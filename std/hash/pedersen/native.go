@@ -0,0 +1,189 @@
+package pedersen
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+
+	edwards "github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+// HashBits computes natively the Pedersen hash of the bits and returns the
+// affine coordinates of the resulting point, in the coordinates of the curve in
+// gnark-crypto. It is the counterpart of [Hasher.HashBits].
+func (p *Parameters) HashBits(bits []bool) (x, y *big.Int, err error) {
+	curve, modulus, err := p.check()
+	if err != nil {
+		return nil, nil, err
+	}
+	segBits := p.segmentBits()
+	nbSegments := (len(bits) + segBits - 1) / segBits
+	if nbSegments > len(p.Generators) {
+		return nil, nil, ErrInputTooLong
+	}
+	if nbSegments == 0 {
+		// hash of the empty input is the identity
+		return new(big.Int), big.NewInt(1), nil
+	}
+	x, y = new(big.Int), big.NewInt(1)
+	for i := 0; i < nbSegments; i++ {
+		bx, by := new(big.Int).Set(p.Generators[i][0]), new(big.Int).Set(p.Generators[i][1])
+		for j := 0; j < p.NbWindows; j++ {
+			start := i*segBits + j*p.WindowBits
+			if start >= len(bits) {
+				break
+			}
+			// enc = (1 + Σ 2ᵏbₖ) · (1 - 2b_{c-1})
+			enc := 1
+			for k := 0; k < p.WindowBits-1; k++ {
+				if start+k < len(bits) && bits[start+k] {
+					enc += 1 << k
+				}
+			}
+			wx, wy := scalarMul(curve, modulus, bx, by, big.NewInt(int64(enc)))
+			if start+p.WindowBits-1 < len(bits) && bits[start+p.WindowBits-1] {
+				wx.Sub(modulus, wx).Mod(wx, modulus)
+			}
+			x, y = add(curve, modulus, x, y, wx, wy)
+			for k := 0; k < p.WindowBits+1; k++ {
+				bx, by = add(curve, modulus, bx, by, bx, by)
+			}
+		}
+	}
+	return x, y, nil
+}
+
+// digest returns the x-coordinate of the hash of the bits in the coordinates of
+// the curve model of the parameters.
+func (p *Parameters) digest(bits []bool) (*big.Int, error) {
+	x, _, err := p.HashBits(bits)
+	if err != nil {
+		return nil, err
+	}
+	if p.outScale != nil {
+		modulus, err := edwards.GetSnarkField(p.Curve)
+		if err != nil {
+			return nil, err
+		}
+		x.Mul(x, p.outScale).Mod(x, modulus)
+	}
+	return x, nil
+}
+
+type nativeDigest struct {
+	params  *Parameters
+	modulus *big.Int
+	data    []*big.Int
+}
+
+// NewNative returns the native counterpart of [Hasher] for the given parameters.
+// The input is interpreted as a sequence of big-endian encoded field elements
+// of [hash.Hash.BlockSize] bytes each, and the digest is the big-endian
+// encoding of the x-coordinate of the hash.
+func NewNative(params *Parameters) (hash.Hash, error) {
+	if _, _, err := params.check(); err != nil {
+		return nil, err
+	}
+	modulus, err := edwards.GetSnarkField(params.Curve)
+	if err != nil {
+		return nil, err
+	}
+	return &nativeDigest{params: params, modulus: modulus}, nil
+}
+
+// Write adds the blocks of p to the data to hash. It returns an error if the
+// length of p is not a multiple of the block size or if a block is not reduced
+// modulo the field order. In that case no data is added.
+func (d *nativeDigest) Write(p []byte) (int, error) {
+	bs := d.BlockSize()
+	if len(p)%bs != 0 {
+		return 0, errors.New("pedersen: input length must be a multiple of the block size")
+	}
+	elems := make([]*big.Int, 0, len(p)/bs)
+	for i := 0; i < len(p); i += bs {
+		e := new(big.Int).SetBytes(p[i : i+bs])
+		if e.Cmp(d.modulus) >= 0 {
+			return 0, errors.New("pedersen: input block is not a canonical field element")
+		}
+		elems = append(elems, e)
+	}
+	d.data = append(d.data, elems...)
+	return len(p), nil
+}
+
+// Sum appends the digest of the data written so far to b. It panics if the data
+// is too long for the parameters.
+func (d *nativeDigest) Sum(b []byte) []byte {
+	nbBits := d.modulus.BitLen()
+	bits := make([]bool, 0, len(d.data)*nbBits)
+	for _, e := range d.data {
+		for i := 0; i < nbBits; i++ {
+			bits = append(bits, e.Bit(i) == 1)
+		}
+	}
+	x, err := d.params.digest(bits)
+	if err != nil {
+		panic(err)
+	}
+	return append(b, x.FillBytes(make([]byte, d.Size()))...)
+}
+
+func (d *nativeDigest) Reset() {
+	d.data = nil
+}
+
+func (d *nativeDigest) Size() int {
+	return (d.modulus.BitLen() + 7) / 8
+}
+
+func (d *nativeDigest) BlockSize() int {
+	return d.Size()
+}
+
+// add returns the sum of the affine points (x1, y1) and (x2, y2) on the twisted
+// Edwards curve.
+func add(curve *edwards.CurveParams, modulus, x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	// x3 = (x1y2 + y1x2) / (1 + dx1x2y1y2)
+	// y3 = (y1y2 - ax1x2) / (1 - dx1x2y1y2)
+	x1x2 := new(big.Int).Mul(x1, x2)
+	y1y2 := new(big.Int).Mul(y1, y2)
+	dxy := new(big.Int).Mul(x1x2, y1y2)
+	dxy.Mul(dxy, curve.D).Mod(dxy, modulus)
+
+	xn := new(big.Int).Mul(x1, y2)
+	xn.Add(xn, new(big.Int).Mul(y1, x2))
+	xd := new(big.Int).Add(big.NewInt(1), dxy)
+	xd.ModInverse(xd, modulus)
+	xn.Mul(xn, xd).Mod(xn, modulus)
+
+	yn := new(big.Int).Mul(curve.A, x1x2)
+	yn.Sub(y1y2, yn)
+	yd := new(big.Int).Sub(big.NewInt(1), dxy)
+	yd.Mod(yd, modulus).ModInverse(yd, modulus)
+	yn.Mul(yn, yd).Mod(yn, modulus)
+
+	return xn, yn
+}
+
+// scalarMul returns [s](x, y) on the twisted Edwards curve for s > 0.
+func scalarMul(curve *edwards.CurveParams, modulus, x, y, s *big.Int) (*big.Int, *big.Int) {
+	rx, ry := new(big.Int).Set(x), new(big.Int).Set(y)
+	for i := s.BitLen() - 2; i >= 0; i-- {
+		rx, ry = add(curve, modulus, rx, ry, rx, ry)
+		if s.Bit(i) == 1 {
+			rx, ry = add(curve, modulus, rx, ry, x, y)
+		}
+	}
+	return rx, ry
+}
+
+// isOnCurve returns true if (x, y) satisfies ax² + y² = 1 + dx²y².
+func isOnCurve(curve *edwards.CurveParams, modulus, x, y *big.Int) bool {
+	xx := new(big.Int).Mul(x, x)
+	yy := new(big.Int).Mul(y, y)
+	lhs := new(big.Int).Mul(curve.A, xx)
+	lhs.Add(lhs, yy)
+	rhs := new(big.Int).Mul(curve.D, xx)
+	rhs.Mul(rhs, yy).Add(rhs, big.NewInt(1))
+	return lhs.Sub(lhs, rhs).Mod(lhs, modulus).Sign() == 0
+}
@@ -0,0 +1,168 @@
+package pedersen
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/ecc/twistededwards"
+	edwards "github.com/consensys/gnark/std/algebra/native/twistededwards"
+)
+
+var (
+	ErrInvalidEncoding  = errors.New("pedersen: window must have at least 2 bits and a segment at least 1 window")
+	ErrNoGenerators     = errors.New("pedersen: no generators")
+	ErrInputTooLong     = errors.New("pedersen: input is longer than supported by the generators")
+	ErrInvalidGenerator = errors.New("pedersen: generator is not on the curve")
+)
+
+// Parameters describe a Pedersen hash instance.
+//
+// The input bits are split into segments of NbWindows windows of WindowBits
+// bits each, the last segment and window being padded with zeros. Every window
+// (b₀, …, b_{c-1}) is encoded as the signed integer
+//
+//	enc = (1 + b₀ + 2b₁ + … + 2^{c-2}b_{c-2}) · (1 - 2b_{c-1})
+//
+// and the hash is the point
+//
+//	Σᵢ Σⱼ enc(segment i, window j) · 2^{(c+1)j} · Generators[i].
+//
+// The number of generators bounds the length of the input.
+type Parameters struct {
+	// Curve is the twisted Edwards curve the hash is defined on. It must be
+	// defined over the native field of the circuit.
+	Curve twistededwards.ID
+	// WindowBits is the number of bits c per window. The last bit of the window
+	// is the sign.
+	WindowBits int
+	// NbWindows is the number of windows per segment.
+	NbWindows int
+	// Generators are the affine coordinates of the generators of the segments,
+	// in the coordinates of the curve in gnark-crypto.
+	Generators [][2]*big.Int
+
+	// outScale is the factor applied to the x-coordinate of the result when
+	// the hash is defined on an isomorphic curve model. nil means 1.
+	outScale *big.Int
+}
+
+// SaplingParameters returns the parameters of the Zcash Sapling Pedersen hash
+// on Jubjub (windows of 3 bits, 63 windows per segment and the 6 generators of
+// Sapling), see Zcash protocol specification §5.4.1.7. This allows to hash up
+// to 1134 bits. The personalization bits of the Sapling hashes (e.g. the
+// Merkle tree layer) are part of the input.
+func SaplingParameters() *Parameters {
+	generators := make([][2]*big.Int, len(saplingGenerators))
+	for i := range saplingGenerators {
+		x, _ := new(big.Int).SetString(saplingGenerators[i][0], 10)
+		y, _ := new(big.Int).SetString(saplingGenerators[i][1], 10)
+		generators[i] = [2]*big.Int{x, y}
+	}
+	return &Parameters{
+		Curve:      twistededwards.BLS12_381,
+		WindowBits: 3,
+		NbWindows:  63,
+		Generators: generators,
+	}
+}
+
+// CircomlibParameters returns the parameters of the Pedersen hash of circomlib
+// (template Pedersen in pedersen.circom) on Baby-Jubjub: windows of 4 bits, 50
+// windows per segment and the 10 generators of circomlib, which allows to hash
+// up to 2000 bits.
+//
+// circomlib uses the curve 168700x² + y² = 1 + 168696x²y², which is isomorphic
+// to the curve of gnark-crypto through (x, y) ↦ (√-168700·x, y). The
+// generators are given in the coordinates of gnark-crypto and the digest is the
+// x-coordinate of the result in the coordinates of circomlib, so that it
+// matches out[0] of the circom template.
+func CircomlibParameters() *Parameters {
+	scale, err := circomlibScale()
+	if err != nil {
+		panic(err)
+	}
+	r := ecc.BN254.ScalarField()
+	generators := make([][2]*big.Int, len(circomlibBase))
+	for i := range circomlibBase {
+		x, _ := new(big.Int).SetString(circomlibBase[i][0], 10)
+		y, _ := new(big.Int).SetString(circomlibBase[i][1], 10)
+		x.Mul(x, scale).Mod(x, r)
+		generators[i] = [2]*big.Int{x, y}
+	}
+	return &Parameters{
+		Curve:      twistededwards.BN254,
+		WindowBits: 4,
+		NbWindows:  50,
+		Generators: generators,
+		outScale:   new(big.Int).ModInverse(scale, r),
+	}
+}
+
+// circomlibScale returns √-168700 in the scalar field of BN254.
+func circomlibScale() (*big.Int, error) {
+	r := ecc.BN254.ScalarField()
+	a := new(big.Int).Sub(r, big.NewInt(168700))
+	s := new(big.Int).ModSqrt(a, r)
+	if s == nil {
+		return nil, errors.New("pedersen: circomlib curve is not isomorphic")
+	}
+	return s, nil
+}
+
+// check verifies that the parameters are well formed and returns the curve
+// parameters.
+func (p *Parameters) check() (*edwards.CurveParams, *big.Int, error) {
+	if p.WindowBits < 2 || p.NbWindows < 1 {
+		return nil, nil, ErrInvalidEncoding
+	}
+	if len(p.Generators) == 0 {
+		return nil, nil, ErrNoGenerators
+	}
+	curve, err := edwards.GetCurveParams(p.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	modulus, err := edwards.GetSnarkField(p.Curve)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, g := range p.Generators {
+		if !isOnCurve(curve, modulus, g[0], g[1]) {
+			return nil, nil, ErrInvalidGenerator
+		}
+	}
+	return curve, modulus, nil
+}
+
+// segmentBits returns the number of input bits hashed with one generator.
+func (p *Parameters) segmentBits() int {
+	return p.WindowBits * p.NbWindows
+}
+
+// circomlibBase are the generators BASE of pedersen.circom in circomlib, in the
+// coordinates of circomlib.
+var circomlibBase = [10][2]string{
+	{"10457101036533406547632367118273992217979173478358440826365724437999023779287", "19824078218392094440610104313265183977899662750282163392862422243483260492317"},
+	{"2671756056509184035029146175565761955751135805354291559563293617232983272177", "2663205510731142763556352975002641716101654201788071096152948830924149045094"},
+	{"5802099305472655231388284418920769829666717045250560929368476121199858275951", "5980429700218124965372158798884772646841287887664001482443826541541529227896"},
+	{"7107336197374528537877327281242680114152313102022415488494307685842428166594", "2857869773864086953506483169737724679646433914307247183624878062391496185654"},
+	{"20265828622013100949498132415626198973119240347465898028410217039057588424236", "1160461593266035632937973507065134938065359936056410650153315956301179689506"},
+	{"1487999857809287756929114517587739322941449154962237464737694709326309567994", "14017256862867289575056460215526364897734808720610101650676790868051368668003"},
+	{"14618644331049802168996997831720384953259095788558646464435263343433563860015", "13115243279999696210147231297848654998887864576952244320558158620692603342236"},
+	{"6814338563135591367010655964669793483652536871717891893032616415581401894627", "13660303521961041205824633772157003587453809761793065294055279768121314853695"},
+	{"3571615583211663069428808372184817973703476260057504149923239576077102575715", "11981351099832644138306422070127357074117642951423551606012551622164230222506"},
+	{"18597552580465440374022635246985743886550544261632147935254624835147509493269", "6753322320275422086923032033899357299485124665258735666995435957890214041481"},
+}
+
+// saplingGenerators are the generators of the Sapling Pedersen hash, obtained
+// with the BLAKE2s group hash FindGroupHash^J("Zcash_PH", I2LEOSP₃₂(i)) for i
+// in 0..5 (PEDERSEN_HASH_GENERATORS in librustzcash).
+var saplingGenerators = [6][2]string{
+	{"52355368488200756720908213129543630848976972731871436319321443845291207170897", "18372611905088487385433946659983357101887954355879737496286092836680199584970"},
+	{"9787319019520772215561425571402619434275350335445140843695488791465664995454", "617599303620822769724880923839314378351145790385632133893219494436232173713"},
+	{"46254521528573726497224586973822974014192468152453531001037375756982829433973", "24506313747297525290953778557147418250711256987769181747135349052620150133847"},
+	{"22718818598176814730279188811725115822910786497974609492339302594899840639692", "21482900543196151117444117927157074338652061209517124624989426821710350741737"},
+	{"27058202516373004425968234366429922161775745886920564416371317087192362222289", "33152712010531917481292916450097258839113870850090594303231218126810079660783"},
+	{"44899967701403962114488563060475643935789150330315799264409868287497276170361", "45648747605882624690586248172048386288129541878950585457687885861218308416154"},
+}
@@ -0,0 +1,136 @@
+// Package pedersen implements the Pedersen hash over twisted Edwards curves
+// defined over the native field.
+//
+// The hash maps a sequence of bits to a point on the curve as a sum of signed
+// multiples of fixed generators, see [Parameters] for the encoding. The
+// multiples of the generators are computed at compile time, so that every
+// window of the input costs a lookup in a constant table and one point
+// addition.
+//
+// The parameters returned by [CircomlibParameters] match the Pedersen template
+// of circomlib and the parameters returned by [SaplingParameters] match the
+// Pedersen hash of Zcash Sapling. Custom generators and encodings can be given
+// by defining [Parameters] directly.
+//
+// [Hasher] implements [hash.FieldHasher]: every written element is decomposed
+// into as many bits as the bit length of the field, least significant bit
+// first, and the digest is the x-coordinate of the hash. To match circuits
+// hashing bit strings of other lengths use [Hasher.HashBits] directly. The
+// native counterpart is returned by [NewNative].
+package pedersen
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/native/twistededwards"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/selector"
+)
+
+// Hasher is the in-circuit Pedersen hasher. It implements [hash.FieldHasher].
+type Hasher struct {
+	api    frontend.API
+	curve  twistededwards.Curve
+	params *Parameters
+	data   []frontend.Variable
+}
+
+var _ hash.FieldHasher = (*Hasher)(nil)
+
+// New returns a new Pedersen hasher with the given parameters. The curve of the
+// parameters must be defined over the native field.
+func New(api frontend.API, params *Parameters) (*Hasher, error) {
+	if _, _, err := params.check(); err != nil {
+		return nil, err
+	}
+	curve, err := twistededwards.NewEdCurve(api, params.Curve)
+	if err != nil {
+		return nil, err
+	}
+	return &Hasher{api: api, curve: curve, params: params}, nil
+}
+
+// Write adds more data to the running hash.
+func (h *Hasher) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+// Reset empties the data written so far.
+func (h *Hasher) Reset() {
+	h.data = nil
+}
+
+// Sum returns the x-coordinate of the hash of the bits of the data written so
+// far. It panics if the data is too long for the generators of the parameters.
+func (h *Hasher) Sum() frontend.Variable {
+	nbBits := h.api.Compiler().FieldBitLen()
+	bits := make([]frontend.Variable, 0, len(h.data)*nbBits)
+	for _, d := range h.data {
+		bits = append(bits, h.api.ToBinary(d, nbBits)...)
+	}
+	res := h.HashBits(bits)
+	if h.params.outScale != nil {
+		return h.api.Mul(res.X, h.params.outScale)
+	}
+	return res.X
+}
+
+// HashBits returns the Pedersen hash of the bits, in the coordinates of the
+// curve in gnark-crypto. The bits are constrained to be boolean. It panics if
+// the input is too long for the generators of the parameters.
+func (h *Hasher) HashBits(bits []frontend.Variable) twistededwards.Point {
+	curveParams := h.curve.Params()
+	modulus := h.api.Compiler().Field()
+	p := h.params
+	segBits := p.segmentBits()
+	nbSegments := (len(bits) + segBits - 1) / segBits
+	if nbSegments > len(p.Generators) {
+		panic(ErrInputTooLong)
+	}
+	res := twistededwards.Point{X: 0, Y: 1}
+	first := true
+	tableX := make([]frontend.Variable, 1<<(p.WindowBits-1))
+	tableY := make([]frontend.Variable, 1<<(p.WindowBits-1))
+	for i := 0; i < nbSegments; i++ {
+		bx, by := new(big.Int).Set(p.Generators[i][0]), new(big.Int).Set(p.Generators[i][1])
+		for j := 0; j < p.NbWindows; j++ {
+			start := i*segBits + j*p.WindowBits
+			if start >= len(bits) {
+				break
+			}
+			window := make([]frontend.Variable, p.WindowBits)
+			for k := range window {
+				if start+k < len(bits) {
+					window[k] = bits[start+k]
+				} else {
+					window[k] = 0
+				}
+			}
+			// table of the multiples [1]B, [2]B, … , [2^{c-1}]B of the window base
+			tx, ty := new(big.Int).Set(bx), new(big.Int).Set(by)
+			for k := range tableX {
+				tableX[k], tableY[k] = new(big.Int).Set(tx), new(big.Int).Set(ty)
+				tx, ty = add(curveParams, modulus, tx, ty, bx, by)
+			}
+			magnitude := window[:p.WindowBits-1]
+			sign := window[p.WindowBits-1]
+			// negation only changes the sign of the x-coordinate
+			x := selector.BinaryMux(h.api, magnitude, tableX)
+			x = h.api.Sub(x, h.api.Mul(2, sign, x))
+			y := selector.BinaryMux(h.api, magnitude, tableY)
+			h.api.AssertIsBoolean(sign)
+			pt := twistededwards.Point{X: x, Y: y}
+			if first {
+				res = pt
+				first = false
+			} else {
+				res = h.curve.Add(res, pt)
+			}
+			for k := 0; k < p.WindowBits+1; k++ {
+				bx, by = add(curveParams, modulus, bx, by, bx, by)
+			}
+		}
+	}
+	return res
+}
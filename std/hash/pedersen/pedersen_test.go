@@ -0,0 +1,159 @@
+package pedersen
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"golang.org/x/exp/slices"
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// expected values computed with an independent implementation of the circomlib
+// Pedersen template on the curve model of circomlib.
+const (
+	circomlibInput0     = "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	circomlibExpected   = "20704801049750950368614458948735198209047949186437271639280451918471249769911"
+	circomlibExpected7b = "16300371373157376595504539748029877823226928119755739834563930253634379604458"
+)
+
+// saplingEmptyRoots are the roots of the empty Sapling note commitment trees
+// of depths 1, 2 and 32 (the last one is the final Sapling root of the blocks
+// without Sapling outputs), as little-endian hex strings. The empty leaf is 1.
+var saplingEmptyRoots = map[int]string{
+	1:  "817de36ab2d57feb077634bca77819c8e0bd298c04f6fed0e6a83cc1356ca155",
+	2:  "ffe9fc03f18b176c998806439ff0bb8ad193afdb27b2ccbc88856916dd804e34",
+	32: "fbc2f4300c01f0b7820d00e3347c8da4ee614674376cbc45359daa54f9b5493e",
+}
+
+type fieldHasherCircuit struct {
+	In       [2]frontend.Variable
+	Expected frontend.Variable
+}
+
+func (c *fieldHasherCircuit) Define(api frontend.API) error {
+	h, err := New(api, CircomlibParameters())
+	if err != nil {
+		return err
+	}
+	h.Write(c.In[:]...)
+	api.AssertIsEqual(h.Sum(), c.Expected)
+	return nil
+}
+
+func TestCircomlib(t *testing.T) {
+	assert := test.NewAssert(t)
+	in0, _ := new(big.Int).SetString(circomlibInput0, 0)
+	in1 := new(big.Int).Sub(ecc.BN254.ScalarField(), big.NewInt(1))
+	expected, _ := new(big.Int).SetString(circomlibExpected, 10)
+
+	// native
+	h, err := NewNative(CircomlibParameters())
+	assert.NoError(err)
+	buf := make([]byte, h.BlockSize())
+	_, err = h.Write(in0.FillBytes(buf))
+	assert.NoError(err)
+	_, err = h.Write(in1.FillBytes(buf))
+	assert.NoError(err)
+	assert.Equal(expected.Bytes(), h.Sum(nil))
+
+	x, err := CircomlibParameters().digest([]bool{true, false, true, true, false, false, true})
+	assert.NoError(err)
+	assert.Equal(circomlibExpected7b, x.String())
+
+	// in-circuit
+	assert.CheckCircuit(&fieldHasherCircuit{}, test.WithValidAssignment(&fieldHasherCircuit{
+		In:       [2]frontend.Variable{in0, in1},
+		Expected: expected,
+	}), test.WithInvalidAssignment(&fieldHasherCircuit{
+		In:       [2]frontend.Variable{in1, in0},
+		Expected: expected,
+	}), test.WithCurves(ecc.BN254))
+}
+
+type hashBitsCircuit struct {
+	Bits     []frontend.Variable
+	Expected [2]frontend.Variable
+	params   *Parameters
+}
+
+func (c *hashBitsCircuit) Define(api frontend.API) error {
+	h, err := New(api, c.params)
+	if err != nil {
+		return err
+	}
+	res := h.HashBits(c.Bits)
+	api.AssertIsEqual(res.X, c.Expected[0])
+	api.AssertIsEqual(res.Y, c.Expected[1])
+	return nil
+}
+
+func TestHashBits(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := SaplingParameters()
+	for _, nbBits := range []int{1, 5, 189, 190, 500} {
+		bits := make([]bool, nbBits)
+		assignment := make([]frontend.Variable, nbBits)
+		for i := range bits {
+			b, err := rand.Int(rand.Reader, big.NewInt(2))
+			assert.NoError(err)
+			bits[i] = b.Bit(0) == 1
+			assignment[i] = b
+		}
+		x, y, err := params.HashBits(bits)
+		assert.NoError(err)
+		err = test.IsSolved(&hashBitsCircuit{Bits: make([]frontend.Variable, nbBits), params: params},
+			&hashBitsCircuit{Bits: assignment, Expected: [2]frontend.Variable{x, y}}, ecc.BLS12_381.ScalarField())
+		assert.NoError(err, nbBits)
+	}
+	_, _, err := params.HashBits(make([]bool, 6*189+1))
+	assert.ErrorIs(err, ErrInputTooLong)
+}
+
+// saplingMerkleInput returns the input bits of MerkleCRH^Sapling: the layer on 6
+// bits followed by the 255 bits of left and right, least significant bit first.
+func saplingMerkleInput(layer int, left, right *big.Int) []bool {
+	res := make([]bool, 0, 6+2*255)
+	for i := 0; i < 6; i++ {
+		res = append(res, (layer>>i)&1 == 1)
+	}
+	for _, v := range []*big.Int{left, right} {
+		for i := 0; i < 255; i++ {
+			res = append(res, v.Bit(i) == 1)
+		}
+	}
+	return res
+}
+
+func TestSaplingMerkleTree(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := SaplingParameters()
+	node := big.NewInt(1)
+	for depth := 1; depth <= 32; depth++ {
+		bits := saplingMerkleInput(depth-1, node, node)
+		x, y, err := params.HashBits(bits)
+		assert.NoError(err)
+		if depth == 1 {
+			assignment := make([]frontend.Variable, len(bits))
+			for i := range bits {
+				assignment[i] = 0
+				if bits[i] {
+					assignment[i] = 1
+				}
+			}
+			err = test.IsSolved(&hashBitsCircuit{Bits: make([]frontend.Variable, len(bits)), params: params},
+				&hashBitsCircuit{Bits: assignment, Expected: [2]frontend.Variable{x, y}}, ecc.BLS12_381.ScalarField())
+			assert.NoError(err)
+		}
+		node = x
+		if expected, ok := saplingEmptyRoots[depth]; ok {
+			var buf [32]byte
+			node.FillBytes(buf[:])
+			slices.Reverse(buf[:])
+			assert.Equal(expected, hex.EncodeToString(buf[:]), depth)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package poseidon2
+
+import (
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/std/permutation/poseidon2"
+)
+
+// ErrNonCanonical is returned when writing a block which does not represent a
+// canonical field element.
+var ErrNonCanonical = errors.New("poseidon2: input block is not a canonical field element")
+
+type nativeDigest struct {
+	params *poseidon2.Parameters
+	state  *big.Int
+}
+
+// NewNative returns the native counterpart of [Poseidon2] using the default
+// parameters for the scalar field of the given curve. It implements
+// [hash.Hash] in the same way as the Poseidon2 hashers of gnark-crypto: the
+// input is interpreted as a sequence of big-endian encoded field elements of
+// [hash.Hash.BlockSize] bytes each and the digest is the big-endian encoding of
+// the output field element.
+func NewNative(curve ecc.ID) (hash.Hash, error) {
+	params, err := poseidon2.GetDefaultParameters(curve)
+	if err != nil {
+		return nil, err
+	}
+	return NewNativeFromParameters(params)
+}
+
+// NewNativeFromParameters returns the native counterpart of [Poseidon2] for the
+// given permutation parameters. The permutation must have width 2.
+func NewNativeFromParameters(params *poseidon2.Parameters) (hash.Hash, error) {
+	if params.Width != 2 {
+		return nil, poseidon2.ErrInvalidWidth
+	}
+	return &nativeDigest{params: params, state: new(big.Int)}, nil
+}
+
+// Write compresses the blocks of p into the state. It returns an error if the
+// length of p is not a multiple of the block size or if a block is not reduced
+// modulo the field order. In that case no data is absorbed.
+func (d *nativeDigest) Write(p []byte) (int, error) {
+	bs := d.BlockSize()
+	if len(p)%bs != 0 {
+		return 0, errors.New("poseidon2: input length must be a multiple of the block size")
+	}
+	elems := make([]*big.Int, 0, len(p)/bs)
+	for i := 0; i < len(p); i += bs {
+		e := new(big.Int).SetBytes(p[i : i+bs])
+		if e.Cmp(d.params.Modulus) >= 0 {
+			return 0, ErrNonCanonical
+		}
+		elems = append(elems, e)
+	}
+	for _, e := range elems {
+		res, err := d.params.Compress(d.state, e)
+		if err != nil {
+			return 0, err
+		}
+		d.state = res
+	}
+	return len(p), nil
+}
+
+// Sum appends the current state to b.
+func (d *nativeDigest) Sum(b []byte) []byte {
+	buf := make([]byte, d.Size())
+	return append(b, d.state.FillBytes(buf)...)
+}
+
+func (d *nativeDigest) Reset() {
+	d.state = new(big.Int)
+}
+
+// Size returns the number of bytes of the digest, which is the byte length of
+// a field element.
+func (d *nativeDigest) Size() int {
+	return (d.params.Modulus.BitLen() + 7) / 8
+}
+
+// BlockSize returns the number of bytes of a field element.
+func (d *nativeDigest) BlockSize() int {
+	return d.Size()
+}
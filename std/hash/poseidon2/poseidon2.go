@@ -0,0 +1,85 @@
+// Package poseidon2 implements the Poseidon2 hash function.
+//
+// This package extends the Poseidon2 permutation [poseidon2] into a hash
+// function over the native field using the Merkle-Damgard construction on top
+// of the 2-to-1 compression function [poseidon2.Permutation.Compress]. The
+// initial state is zero and every input element is compressed into the running
+// state. This is the same construction as the Poseidon2 hashers of
+// gnark-crypto, so that the digests agree bit-for-bit.
+//
+// As in gnark-crypto, no length padding is performed. The caller should bind
+// the length of variable-length inputs if collision resistance across lengths
+// is required.
+//
+// The hasher is registered in [hash] under the name [HashName] and can be
+// obtained with [hash.GetFieldHasher]. The native counterpart is returned by
+// [NewNative] and [NewNativeFromParameters].
+package poseidon2
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/std/permutation/poseidon2"
+)
+
+// HashName is the name under which the hasher is registered in [hash].
+const HashName = "poseidon2"
+
+func init() {
+	hash.Register(HashName, func(api frontend.API) (hash.FieldHasher, error) {
+		h, err := NewPoseidon2(api)
+		if err != nil {
+			return nil, err
+		}
+		return &h, nil
+	})
+}
+
+// Poseidon2 is the in-circuit Poseidon2 hasher. It implements
+// [hash.FieldHasher].
+type Poseidon2 struct {
+	perm  *poseidon2.Permutation
+	state frontend.Variable
+	data  []frontend.Variable
+}
+
+// NewPoseidon2 returns a Poseidon2 hasher instance using the default
+// permutation parameters for the native field.
+func NewPoseidon2(api frontend.API) (Poseidon2, error) {
+	perm, err := poseidon2.NewPoseidon2(api)
+	if err != nil {
+		return Poseidon2{}, err
+	}
+	return Poseidon2{perm: perm, state: 0}, nil
+}
+
+// NewPoseidon2FromParameters returns a Poseidon2 hasher instance using a
+// permutation of width 2 with the given number of rounds.
+func NewPoseidon2FromParameters(api frontend.API, nbFullRounds, nbPartialRounds int) (Poseidon2, error) {
+	perm, err := poseidon2.NewPoseidon2FromParameters(api, 2, nbFullRounds, nbPartialRounds)
+	if err != nil {
+		return Poseidon2{}, err
+	}
+	return Poseidon2{perm: perm, state: 0}, nil
+}
+
+// Write adds more data to the running hash.
+func (h *Poseidon2) Write(data ...frontend.Variable) {
+	h.data = append(h.data, data...)
+}
+
+// Reset resets the hash to its initial state.
+func (h *Poseidon2) Reset() {
+	h.data = nil
+	h.state = 0
+}
+
+// Sum returns the current state of the hash after compressing the data written
+// since the last call.
+func (h *Poseidon2) Sum() frontend.Variable {
+	for _, d := range h.data {
+		h.state = h.perm.Compress(h.state, d)
+	}
+	h.data = nil // flush the data already hashed
+	return h.state
+}
@@ -0,0 +1,157 @@
+package poseidon2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/hash"
+	"github.com/consensys/gnark/test"
+)
+
+type poseidon2Circuit struct {
+	ExpectedResult frontend.Variable `gnark:"data,public"`
+	Data           []frontend.Variable
+}
+
+func (circuit *poseidon2Circuit) Define(api frontend.API) error {
+	h, err := hash.GetFieldHasher(HashName, api)
+	if err != nil {
+		return err
+	}
+	h.Write(circuit.Data...)
+	api.AssertIsEqual(h.Sum(), circuit.ExpectedResult)
+	return nil
+}
+
+func TestPoseidon2All(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BW6_761} {
+		for _, nbInputs := range []int{0, 1, 2, 5} {
+			modulus := curve.ScalarField()
+			data := make([]big.Int, nbInputs)
+			for i := range data {
+				if i == 0 {
+					data[i].Sub(modulus, big.NewInt(1))
+				} else {
+					data[i].Add(&data[i-1], &data[i-1]).Mod(&data[i], modulus)
+				}
+			}
+
+			// running Poseidon2 (Go)
+			goHash, err := NewNative(curve)
+			assert.NoError(err)
+			buf := make([]byte, goHash.BlockSize())
+			for i := range data {
+				_, err = goHash.Write(data[i].FillBytes(buf))
+				assert.NoError(err)
+			}
+			expected := goHash.Sum(nil)
+
+			circuit := poseidon2Circuit{Data: make([]frontend.Variable, nbInputs)}
+			validWitness := poseidon2Circuit{Data: make([]frontend.Variable, nbInputs), ExpectedResult: expected}
+			invalidWitness := poseidon2Circuit{Data: make([]frontend.Variable, nbInputs), ExpectedResult: 1}
+			for i := range data {
+				validWitness.Data[i] = data[i].String()
+				invalidWitness.Data[i] = data[i].String()
+			}
+			assert.CheckCircuit(&circuit,
+				test.WithValidAssignment(&validWitness),
+				test.WithInvalidAssignment(&invalidWitness),
+				test.WithCurves(curve))
+		}
+	}
+}
+
+func TestNativeNonCanonical(t *testing.T) {
+	assert := test.NewAssert(t)
+	h, err := NewNative(ecc.BN254)
+	assert.NoError(err)
+	buf := ecc.BN254.ScalarField().FillBytes(make([]byte, h.BlockSize()))
+	_, err = h.Write(buf)
+	assert.ErrorIs(err, ErrNonCanonical)
+	_, err = h.Write(buf[1:])
+	assert.Error(err)
+}
+
+func TestNativeZeroBlock(t *testing.T) {
+	// the initial state is zero, compressing a zero block must still change
+	// the state.
+	assert := test.NewAssert(t)
+	h, err := NewNative(ecc.BN254)
+	assert.NoError(err)
+	d0 := h.Sum(nil)
+	_, err = h.Write(make([]byte, h.BlockSize()))
+	assert.NoError(err)
+	d1 := h.Sum(nil)
+	assert.NotEqual(d0, d1)
+}
+
+// TestKnownAnswers checks the hash of (0, 1, …, n-1) against the outputs of
+// the Merkle-Damgard Poseidon2 hashers of gnark-crypto v0.17.0.
+func TestKnownAnswers(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, tc := range []struct {
+		curve    ecc.ID
+		expected map[int]string
+	}{
+		{ecc.BN254, map[int]string{
+			1: "18622970401557034651033185129330286139447343337105683528700775943440799145467",
+			2: "20463671494677712854009578808814441587925054594429507283031252048850218235694",
+			5: "14751419511132168686242022886778309825335537760863710392127674999533572284630",
+		}},
+		{ecc.BLS12_377, map[int]string{
+			1: "4259941440993218657740870745858354010316549714149225161099297553566865545922",
+			2: "2269931024945876931764074129888078134320679724431446309815782926359135847333",
+			5: "1405819823847725172034398763731933437929519279240105095548135632261777261011",
+		}},
+		{ecc.BLS12_381, map[int]string{
+			1: "1923799207013976976064094502877627580456113909105016285914672446800380158836",
+			2: "7239619379806855042218429963142918014603046229013217848874890945004325990387",
+			5: "15394062525003784606167228728444281549360309677133688039402361088547832923662",
+		}},
+		{ecc.BW6_761, map[int]string{
+			1: "129253081284194505664988888720529981658777496463252433222786260398696091491943949685597119539487140296459593810028",
+			2: "258408503452730553832185616246297250124342639785255234947362637292221068160486465525204240479359890632170569735682",
+			5: "143993563742493286260483384439804231107729209368236167916218840762499485323379558085343542168168240212938234113260",
+		}},
+		{ecc.BW6_633, map[int]string{
+			1: "25139362537560027671563340738086504480462589023818048129578179391813100842019308954425925445177",
+			2: "12723684694832766031909261657493435232157021014767892697641224683618110301233715102912672266690",
+			5: "32561212257906691818585990818245627483210495490954235053087260057730721267618907338110552694013",
+		}},
+		{ecc.BLS24_315, map[int]string{
+			1: "3002876775198563400378594210475648042890098313173789787079033192250607618132",
+			2: "4872021504076332686262392988694679072377015010429035785763934659077429684974",
+			5: "11284607546565879771435777810634170834047000550604927688168479413917398608300",
+		}},
+		{ecc.BLS24_317, map[int]string{
+			1: "9047164564925589511346242724057479977626909403390663523872079077369253474795",
+			2: "7067359430897772993096071965405782806376812484831511512615806750418828984678",
+			5: "13615595688378924598888735009929728338051391756079181792141936894544720365979",
+		}},
+	} {
+		for nbInputs, digest := range tc.expected {
+			expected, ok := new(big.Int).SetString(digest, 10)
+			assert.True(ok)
+
+			h, err := NewNative(tc.curve)
+			assert.NoError(err)
+			buf := make([]byte, h.BlockSize())
+			for i := 0; i < nbInputs; i++ {
+				_, err = h.Write(big.NewInt(int64(i)).FillBytes(buf))
+				assert.NoError(err)
+			}
+			assert.Equal(expected, new(big.Int).SetBytes(h.Sum(nil)), "%s: %d inputs", tc.curve, nbInputs)
+
+			circuit := poseidon2Circuit{Data: make([]frontend.Variable, nbInputs)}
+			witness := poseidon2Circuit{Data: make([]frontend.Variable, nbInputs), ExpectedResult: expected}
+			for i := range witness.Data {
+				witness.Data[i] = i
+			}
+			assert.NoError(test.IsSolved(&circuit, &witness, tc.curve.ScalarField()))
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package poseidon_goldilocks
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+)
+
+// Permute applies the Poseidon permutation natively on the state in place. It
+// is the reference for the in-circuit [Poseidon.Permutation] and can be used to
+// compute witnesses off-circuit.
+func (p *Parameters) Permute(state []goldilocks.Element) error {
+	if len(state) != Width {
+		return ErrInvalidBufSize
+	}
+	rf := p.NbFullRounds / 2
+	for i := 0; i < p.NbFullRounds+p.NbPartialRounds; i++ {
+		for j := range state {
+			var rc goldilocks.Element
+			rc.SetUint64(p.RoundConstants[i][j])
+			state[j].Add(&state[j], &rc)
+		}
+		if i < rf || i >= rf+p.NbPartialRounds {
+			for j := range state {
+				sBox(&state[j])
+			}
+		} else {
+			sBox(&state[0])
+		}
+		p.mds(state)
+	}
+	return nil
+}
+
+// HashNoPad returns the digest of inputs. The inputs are absorbed by chunks of
+// [Rate] elements overwriting the beginning of the state, without padding.
+func (p *Parameters) HashNoPad(inputs []goldilocks.Element) [DigestSize]goldilocks.Element {
+	state := make([]goldilocks.Element, Width)
+	for i := 0; i < len(inputs); i += Rate {
+		end := i + Rate
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+		copy(state, inputs[i:end])
+		if err := p.Permute(state); err != nil {
+			panic(err) // can't happen, the state has the width of the permutation
+		}
+	}
+	var res [DigestSize]goldilocks.Element
+	copy(res[:], state)
+	return res
+}
+
+// HashOrNoop returns the inputs padded with zeros if they fit in a digest and
+// [Parameters.HashNoPad] of the inputs otherwise. It is used to hash the leaves
+// of Merkle trees.
+func (p *Parameters) HashOrNoop(inputs []goldilocks.Element) [DigestSize]goldilocks.Element {
+	if len(inputs) <= DigestSize {
+		var res [DigestSize]goldilocks.Element
+		copy(res[:], inputs)
+		return res
+	}
+	return p.HashNoPad(inputs)
+}
+
+// TwoToOne compresses two digests into one. It is used to hash the internal
+// nodes of Merkle trees.
+func (p *Parameters) TwoToOne(left, right [DigestSize]goldilocks.Element) [DigestSize]goldilocks.Element {
+	state := make([]goldilocks.Element, Width)
+	copy(state, left[:])
+	copy(state[DigestSize:], right[:])
+	if err := p.Permute(state); err != nil {
+		panic(err) // can't happen, the state has the width of the permutation
+	}
+	var res [DigestSize]goldilocks.Element
+	copy(res[:], state)
+	return res
+}
+
+func sBox(x *goldilocks.Element) {
+	x.Exp(*x, big.NewInt(DegreeSBox))
+}
+
+func (p *Parameters) mds(state []goldilocks.Element) {
+	var res [Width]goldilocks.Element
+	var c, t goldilocks.Element
+	for r := 0; r < Width; r++ {
+		for i := 0; i < Width; i++ {
+			c.SetUint64(p.MDSCirc[i])
+			t.Mul(&state[(i+r)%Width], &c)
+			res[r].Add(&res[r], &t)
+		}
+		c.SetUint64(p.MDSDiag[r])
+		t.Mul(&state[r], &c)
+		res[r].Add(&res[r], &t)
+	}
+	copy(state, res[:])
+}
+
+// NativeSponge is the native counterpart of [Sponge].
+type NativeSponge struct {
+	params *Parameters
+	state  []goldilocks.Element
+	input  []goldilocks.Element
+	output []goldilocks.Element
+}
+
+// NewNativeSponge returns a new native duplex sponge with a zero state.
+func NewNativeSponge(params *Parameters) *NativeSponge {
+	return &NativeSponge{params: params, state: make([]goldilocks.Element, Width)}
+}
+
+// Absorb adds elements to the sponge. It invalidates all pending outputs.
+func (s *NativeSponge) Absorb(elems ...goldilocks.Element) {
+	s.output = s.output[:0]
+	for i := range elems {
+		s.input = append(s.input, elems[i])
+		if len(s.input) == Rate {
+			s.duplex()
+		}
+	}
+}
+
+// Squeeze returns the next output element of the sponge.
+func (s *NativeSponge) Squeeze() goldilocks.Element {
+	if len(s.input) > 0 || len(s.output) == 0 {
+		s.duplex()
+	}
+	res := s.output[len(s.output)-1]
+	s.output = s.output[:len(s.output)-1]
+	return res
+}
+
+func (s *NativeSponge) duplex() {
+	copy(s.state, s.input)
+	s.input = s.input[:0]
+	if err := s.params.Permute(s.state); err != nil {
+		panic(err) // can't happen, the state has the width of the permutation
+	}
+	s.output = append(s.output[:0], s.state[:Rate]...)
+}
@@ -0,0 +1,73 @@
+package poseidon_goldilocks
+
+import "errors"
+
+const (
+	// Width is the size of the state of the permutation.
+	Width = 12
+	// Rate is the number of elements absorbed or squeezed per permutation
+	// call. The remaining Width-Rate elements form the capacity.
+	Rate = 8
+	// DigestSize is the number of field elements of a digest [HashOut].
+	DigestSize = 4
+	// DegreeSBox is the degree d of the power map x -> x^d. It is the smallest
+	// d such that gcd(d, p-1) = 1 for the Goldilocks modulus p.
+	DegreeSBox = 7
+)
+
+var (
+	ErrInvalidRounds  = errors.New("poseidon: number of full rounds must be even and the total number of rounds at most 30")
+	ErrInvalidBufSize = errors.New("poseidon: state length does not match the width")
+)
+
+// Parameters describe a Poseidon instance over the Goldilocks field with width
+// [Width] and S-box x^[DegreeSBox].
+type Parameters struct {
+	// NbFullRounds is the total number of full rounds R_F (half of them is
+	// applied at the beginning and half at the end of the permutation).
+	NbFullRounds int
+	// NbPartialRounds is the number of partial rounds R_P.
+	NbPartialRounds int
+	// RoundConstants stores Width constants for every round.
+	RoundConstants [][Width]uint64
+	// MDSCirc is the first row of the circulant part of the MDS matrix.
+	MDSCirc [Width]uint64
+	// MDSDiag is the diagonal part of the MDS matrix.
+	MDSDiag [Width]uint64
+}
+
+// mdsCirc and mdsDiag define the MDS matrix used by Plonky2, which has small
+// entries so that the linear layer is cheap to evaluate.
+var (
+	mdsCirc = [Width]uint64{17, 15, 41, 16, 2, 28, 13, 13, 39, 18, 34, 20}
+	mdsDiag = [Width]uint64{8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+)
+
+// GetDefaultParameters returns the parameters with 8 full rounds and 22 partial
+// rounds, targeting 128 bits of security as in Plonky2.
+func GetDefaultParameters() *Parameters {
+	p, err := NewParameters(8, 22)
+	if err != nil {
+		panic(err) // can't happen, the rounds match the constants of Plonky2
+	}
+	return p
+}
+
+// NewParameters returns parameters with the given number of rounds. The round
+// constants of round i are the ones of round i of Plonky2, so that the number
+// of rounds is at most 30. Callers needing a different set of constants may
+// overwrite [Parameters.RoundConstants].
+func NewParameters(nbFullRounds, nbPartialRounds int) (*Parameters, error) {
+	if nbFullRounds%2 != 0 || nbFullRounds < 0 || nbPartialRounds < 0 || nbFullRounds+nbPartialRounds > len(roundConstants) {
+		return nil, ErrInvalidRounds
+	}
+	p := &Parameters{
+		NbFullRounds:    nbFullRounds,
+		NbPartialRounds: nbPartialRounds,
+		MDSCirc:         mdsCirc,
+		MDSDiag:         mdsDiag,
+	}
+	p.RoundConstants = make([][Width]uint64, nbFullRounds+nbPartialRounds)
+	copy(p.RoundConstants, roundConstants[:])
+	return p, nil
+}
@@ -0,0 +1,180 @@
+// Package poseidon_goldilocks implements the Poseidon hash function over the
+// Goldilocks field p = 2⁶⁴ - 2³² + 1.
+//
+// The Goldilocks field is emulated using [emulated.Field] with
+// [emulated.Goldilocks] parameters, so that the gadgets can be used in circuits
+// over any native field, for example to verify Plonky2-style proofs inside a
+// BN254 Groth16 circuit. The instance follows the construction of Plonky2: the
+// state has width 12, the S-box is x^7, there are 8 full rounds and 22 partial
+// rounds and the MDS matrix is the sum of a circulant and a diagonal matrix
+// with small entries. The round constants are the ones of Plonky2, so that the
+// permutation matches the one of Plonky2 (see [NewParameters]).
+//
+// The package provides the permutation [Poseidon.Permutation], the hash
+// functions used for Merkle trees ([Poseidon.HashNoPad], [Poseidon.HashOrNoop]
+// and [Poseidon.TwoToOne]) and a duplex sponge [Sponge] for deriving Fiat-Shamir
+// challenges. Every gadget has a native counterpart defined on [Parameters] or
+// [NativeSponge].
+//
+// [Poseidon]: https://eprint.iacr.org/2019/458
+package poseidon_goldilocks
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+)
+
+type baseField = emulated.Field[emulated.Goldilocks]
+type baseEl = emulated.Element[emulated.Goldilocks]
+
+// HashOut is a digest of [DigestSize] Goldilocks elements.
+type HashOut [DigestSize]emulated.Element[emulated.Goldilocks]
+
+// Poseidon is the in-circuit Poseidon permutation over the emulated Goldilocks
+// field.
+type Poseidon struct {
+	fp     *baseField
+	params *Parameters
+}
+
+// NewPoseidon returns a new Poseidon instance using the default parameters
+// (see [GetDefaultParameters]).
+func NewPoseidon(api frontend.API) (*Poseidon, error) {
+	return NewPoseidonFromParameters(api, GetDefaultParameters())
+}
+
+// NewPoseidonFromParameters returns a new Poseidon instance using the given
+// parameters.
+func NewPoseidonFromParameters(api frontend.API, params *Parameters) (*Poseidon, error) {
+	fp, err := emulated.NewField[emulated.Goldilocks](api)
+	if err != nil {
+		return nil, err
+	}
+	return &Poseidon{fp: fp, params: params}, nil
+}
+
+// Parameters returns the parameters of the permutation.
+func (h *Poseidon) Parameters() *Parameters {
+	return h.params
+}
+
+// Permutation applies the permutation on the state in place.
+func (h *Poseidon) Permutation(state []*baseEl) error {
+	if len(state) != Width {
+		return ErrInvalidBufSize
+	}
+	rf := h.params.NbFullRounds / 2
+	for i := 0; i < h.params.NbFullRounds+h.params.NbPartialRounds; i++ {
+		for j := range state {
+			rc := emulated.ValueOf[emulated.Goldilocks](h.params.RoundConstants[i][j])
+			state[j] = h.fp.Add(state[j], &rc)
+		}
+		if i < rf || i >= rf+h.params.NbPartialRounds {
+			for j := range state {
+				state[j] = h.sBox(state[j])
+			}
+		} else {
+			state[0] = h.sBox(state[0])
+		}
+		h.mds(state)
+	}
+	return nil
+}
+
+// HashNoPad returns the digest of inputs. It matches [Parameters.HashNoPad].
+func (h *Poseidon) HashNoPad(inputs ...*baseEl) HashOut {
+	state := h.zeroState()
+	for i := 0; i < len(inputs); i += Rate {
+		for j := 0; j < Rate && i+j < len(inputs); j++ {
+			state[j] = inputs[i+j]
+		}
+		if err := h.Permutation(state); err != nil {
+			panic(err) // can't happen, the state has the width of the permutation
+		}
+	}
+	return h.digest(state)
+}
+
+// HashOrNoop returns the inputs padded with zeros if they fit in a digest and
+// [Poseidon.HashNoPad] of the inputs otherwise. It matches
+// [Parameters.HashOrNoop].
+func (h *Poseidon) HashOrNoop(inputs ...*baseEl) HashOut {
+	if len(inputs) > DigestSize {
+		return h.HashNoPad(inputs...)
+	}
+	var res HashOut
+	for i := range res {
+		if i < len(inputs) {
+			res[i] = *inputs[i]
+		} else {
+			res[i] = *h.fp.Zero()
+		}
+	}
+	return res
+}
+
+// TwoToOne compresses two digests into one. It matches [Parameters.TwoToOne].
+func (h *Poseidon) TwoToOne(left, right HashOut) HashOut {
+	state := h.zeroState()
+	for i := 0; i < DigestSize; i++ {
+		state[i] = &left[i]
+		state[DigestSize+i] = &right[i]
+	}
+	if err := h.Permutation(state); err != nil {
+		panic(err) // can't happen, the state has the width of the permutation
+	}
+	return h.digest(state)
+}
+
+// AssertIsEqual asserts that the digests a and b are equal.
+func (h *Poseidon) AssertIsEqual(a, b HashOut) {
+	for i := range a {
+		h.fp.AssertIsEqual(&a[i], &b[i])
+	}
+}
+
+// Select returns a if selector is 1 and b otherwise.
+func (h *Poseidon) Select(selector frontend.Variable, a, b HashOut) HashOut {
+	var res HashOut
+	for i := range res {
+		res[i] = *h.fp.Select(selector, &a[i], &b[i])
+	}
+	return res
+}
+
+func (h *Poseidon) zeroState() []*baseEl {
+	state := make([]*baseEl, Width)
+	for i := range state {
+		state[i] = h.fp.Zero()
+	}
+	return state
+}
+
+func (h *Poseidon) digest(state []*baseEl) HashOut {
+	var res HashOut
+	for i := range res {
+		res[i] = *state[i]
+	}
+	return res
+}
+
+// sBox returns x^7.
+func (h *Poseidon) sBox(x *baseEl) *baseEl {
+	x2 := h.fp.Mul(x, x)
+	x3 := h.fp.Mul(x2, x)
+	x4 := h.fp.Mul(x2, x2)
+	return h.fp.Mul(x4, x3)
+}
+
+func (h *Poseidon) mds(state []*baseEl) {
+	res := make([]*baseEl, Width)
+	for r := 0; r < Width; r++ {
+		res[r] = h.fp.MulConst(state[r], new(big.Int).SetUint64(h.params.MDSCirc[0]+h.params.MDSDiag[r]))
+		for i := 1; i < Width; i++ {
+			res[r] = h.fp.Add(res[r], h.fp.MulConst(state[(i+r)%Width], new(big.Int).SetUint64(h.params.MDSCirc[i])))
+		}
+	}
+	copy(state, res)
+}
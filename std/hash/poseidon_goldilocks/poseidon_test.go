@@ -0,0 +1,268 @@
+package poseidon_goldilocks
+
+import (
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark-crypto/field/goldilocks"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+type permutationCircuit struct {
+	Input    [Width]emulated.Element[emulated.Goldilocks]
+	Expected [Width]emulated.Element[emulated.Goldilocks] `gnark:",public"`
+}
+
+func (c *permutationCircuit) Define(api frontend.API) error {
+	h, err := NewPoseidon(api)
+	if err != nil {
+		return err
+	}
+	state := make([]*baseEl, Width)
+	for i := range state {
+		state[i] = &c.Input[i]
+	}
+	if err := h.Permutation(state); err != nil {
+		return err
+	}
+	for i := range state {
+		h.fp.AssertIsEqual(state[i], &c.Expected[i])
+	}
+	return nil
+}
+
+func TestPermutation(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := GetDefaultParameters()
+	state := make([]goldilocks.Element, Width)
+	var witness permutationCircuit
+	for i := range state {
+		state[i].SetRandom()
+		witness.Input[i] = emulated.ValueOf[emulated.Goldilocks](state[i])
+	}
+	assert.NoError(params.Permute(state))
+	for i := range state {
+		witness.Expected[i] = emulated.ValueOf[emulated.Goldilocks](state[i])
+	}
+	err := test.IsSolved(&permutationCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+
+	witness.Expected[0] = emulated.ValueOf[emulated.Goldilocks](0)
+	err = test.IsSolved(&permutationCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.Error(err)
+}
+
+// plonky2Vectors are the test vectors of the Poseidon permutation of Plonky2
+// (test_vectors12 in plonky2/src/hash/poseidon_goldilocks.rs).
+var plonky2Vectors = []struct {
+	input, output [Width]uint64
+}{
+	{
+		[Width]uint64{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		[Width]uint64{
+			0x3c18a9786cb0b359, 0xc4055e3364a246c3, 0x7953db0ab48808f4, 0xc71603f33a1144ca,
+			0xd7709673896996dc, 0x46a84e87642f44ed, 0xd032648251ee0b3c, 0x1c687363b207df62,
+			0xdf8565563e8045fe, 0x40f5b37ff4254dae, 0xd070f637b431067c, 0x1792b1c4342109d7,
+		},
+	},
+	{
+		[Width]uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		[Width]uint64{
+			0xd64e1e3efc5b8e9e, 0x53666633020aaa47, 0xd40285597c6a8825, 0x613a4f81e81231d2,
+			0x414754bfebd051f0, 0xcb1f8980294a023f, 0x6eb2a9e4d54a9d0f, 0x1902bc3af467e056,
+			0xf045d5eafdc6021f, 0xe4150f77caaa3be5, 0xc9bfd01d39b50cce, 0x5c0a27fcb0e1459b,
+		},
+	},
+	{
+		[Width]uint64{
+			0xffffffff00000000, 0xffffffff00000000, 0xffffffff00000000, 0xffffffff00000000,
+			0xffffffff00000000, 0xffffffff00000000, 0xffffffff00000000, 0xffffffff00000000,
+			0xffffffff00000000, 0xffffffff00000000, 0xffffffff00000000, 0xffffffff00000000,
+		},
+		[Width]uint64{
+			0xbe0085cfc57a8357, 0xd95af71847d05c09, 0xcf55a13d33c1c953, 0x95803a74f4530e82,
+			0xfcd99eb30a135df1, 0xe095905e913a3029, 0xde0392461b42919b, 0x7d3260e24e81d031,
+			0x10d3d0465d9deaa0, 0xa87571083dfc2a47, 0xe18263681e9958f8, 0xe28e96f1ae5e60d3,
+		},
+	},
+	{
+		[Width]uint64{
+			0x8ccbbbea4fe5d2b7, 0xc2af59ee9ec49970, 0x90f7e1a9e658446a, 0xdcc0630a3ab8b1b8,
+			0x7ff8256bca20588c, 0x5d99a7ca0c44ecfb, 0x48452b17a70fbee3, 0xeb09d654690b6c88,
+			0x4a55d3a39c676a88, 0xc0407a38d2285139, 0xa234bac9356386d1, 0xe1633f2bad98a52f,
+		},
+		[Width]uint64{
+			0xa89280105650c4ec, 0xab542d53860d12ed, 0x5704148e9ccab94f, 0xd3a826d4b62da9f5,
+			0x8a7a6ca87892574f, 0xc7017e1cad1a674e, 0x1f06668922318e34, 0xa3b203bc8102676f,
+			0xfcc781b0ce382bf2, 0x934c69ff3ed14ba5, 0x504688a5996e8f13, 0x401f3f2ed524a2ba,
+		},
+	},
+}
+
+func TestPermutationPlonky2Vectors(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := GetDefaultParameters()
+	for i, v := range plonky2Vectors {
+		state := make([]goldilocks.Element, Width)
+		var witness permutationCircuit
+		for j := range state {
+			state[j].SetUint64(v.input[j])
+			witness.Input[j] = emulated.ValueOf[emulated.Goldilocks](v.input[j])
+			witness.Expected[j] = emulated.ValueOf[emulated.Goldilocks](v.output[j])
+		}
+		assert.NoError(params.Permute(state))
+		for j := range state {
+			assert.Equal(v.output[j], state[j].Uint64(), "vector %d, element %d", i, j)
+		}
+		err := test.IsSolved(&permutationCircuit{}, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, i)
+	}
+}
+
+type hashCircuit struct {
+	Inputs      []emulated.Element[emulated.Goldilocks]
+	Left, Right HashOut
+	HashNoPad   HashOut
+	HashOrNoop  HashOut
+	TwoToOne    HashOut
+}
+
+func (c *hashCircuit) Define(api frontend.API) error {
+	h, err := NewPoseidon(api)
+	if err != nil {
+		return err
+	}
+	inputs := make([]*baseEl, len(c.Inputs))
+	for i := range inputs {
+		inputs[i] = &c.Inputs[i]
+	}
+	h.AssertIsEqual(h.HashNoPad(inputs...), c.HashNoPad)
+	h.AssertIsEqual(h.HashOrNoop(inputs...), c.HashOrNoop)
+	h.AssertIsEqual(h.TwoToOne(c.Left, c.Right), c.TwoToOne)
+	return nil
+}
+
+func TestHash(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := GetDefaultParameters()
+	for _, nbInputs := range []int{0, 3, 8, 13} {
+		inputs := make([]goldilocks.Element, nbInputs)
+		witness := hashCircuit{Inputs: make([]emulated.Element[emulated.Goldilocks], nbInputs)}
+		for i := range inputs {
+			inputs[i].SetRandom()
+			witness.Inputs[i] = emulated.ValueOf[emulated.Goldilocks](inputs[i])
+		}
+		var left, right [DigestSize]goldilocks.Element
+		for i := range left {
+			left[i].SetRandom()
+			right[i].SetRandom()
+		}
+		witness.Left = toHashOut(left)
+		witness.Right = toHashOut(right)
+		witness.HashNoPad = toHashOut(params.HashNoPad(inputs))
+		witness.HashOrNoop = toHashOut(params.HashOrNoop(inputs))
+		witness.TwoToOne = toHashOut(params.TwoToOne(left, right))
+
+		circuit := hashCircuit{Inputs: make([]emulated.Element[emulated.Goldilocks], nbInputs)}
+		err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, nbInputs)
+	}
+}
+
+// TestHashPlonky2Vectors checks hash_n_to_m_no_pad and two_to_one of Plonky2
+// on zero inputs: both overwrite the beginning of a zero state with zeros
+// before a single permutation, so that the digest is the beginning of the
+// first vector of [plonky2Vectors].
+func TestHashPlonky2Vectors(t *testing.T) {
+	assert := test.NewAssert(t)
+	params := GetDefaultParameters()
+	var expected, zero [DigestSize]goldilocks.Element
+	for i := range expected {
+		expected[i].SetUint64(plonky2Vectors[0].output[i])
+	}
+	inputs := make([]goldilocks.Element, Rate)
+	assert.Equal(expected, params.HashNoPad(inputs))
+	assert.Equal(expected, params.HashOrNoop(inputs))
+	assert.Equal(expected, params.TwoToOne(zero, zero))
+
+	witness := hashCircuit{Inputs: make([]emulated.Element[emulated.Goldilocks], Rate)}
+	for i := range witness.Inputs {
+		witness.Inputs[i] = emulated.ValueOf[emulated.Goldilocks](0)
+	}
+	witness.Left = toHashOut(zero)
+	witness.Right = toHashOut(zero)
+	witness.HashNoPad = toHashOut(expected)
+	witness.HashOrNoop = toHashOut(expected)
+	witness.TwoToOne = toHashOut(expected)
+	circuit := hashCircuit{Inputs: make([]emulated.Element[emulated.Goldilocks], Rate)}
+	err := test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+type spongeCircuit struct {
+	Inputs   [11]emulated.Element[emulated.Goldilocks]
+	Expected [10]emulated.Element[emulated.Goldilocks]
+}
+
+func (c *spongeCircuit) Define(api frontend.API) error {
+	s, err := NewSponge(api)
+	if err != nil {
+		return err
+	}
+	fp, err := emulated.NewField[emulated.Goldilocks](api)
+	if err != nil {
+		return err
+	}
+	// interleave absorptions and squeezes to exercise the buffering
+	s.Absorb(&c.Inputs[0], &c.Inputs[1])
+	fp.AssertIsEqual(s.Squeeze(), &c.Expected[0])
+	fp.AssertIsEqual(s.Squeeze(), &c.Expected[1])
+	s.Absorb(&c.Inputs[2], &c.Inputs[3], &c.Inputs[4], &c.Inputs[5], &c.Inputs[6], &c.Inputs[7], &c.Inputs[8], &c.Inputs[9], &c.Inputs[10])
+	for i := 2; i < len(c.Expected); i++ {
+		fp.AssertIsEqual(s.Squeeze(), &c.Expected[i])
+	}
+	return nil
+}
+
+func TestSponge(t *testing.T) {
+	assert := test.NewAssert(t)
+	s := NewNativeSponge(GetDefaultParameters())
+	var inputs [11]goldilocks.Element
+	var witness spongeCircuit
+	for i := range inputs {
+		inputs[i].SetRandom()
+		witness.Inputs[i] = emulated.ValueOf[emulated.Goldilocks](inputs[i])
+	}
+	s.Absorb(inputs[:2]...)
+	witness.Expected[0] = emulated.ValueOf[emulated.Goldilocks](s.Squeeze())
+	witness.Expected[1] = emulated.ValueOf[emulated.Goldilocks](s.Squeeze())
+	s.Absorb(inputs[2:]...)
+	for i := 2; i < len(witness.Expected); i++ {
+		witness.Expected[i] = emulated.ValueOf[emulated.Goldilocks](s.Squeeze())
+	}
+	err := test.IsSolved(&spongeCircuit{}, &witness, ecc.BN254.ScalarField())
+	assert.NoError(err)
+}
+
+func TestParameters(t *testing.T) {
+	assert := test.NewAssert(t)
+	p1 := GetDefaultParameters()
+	p2 := GetDefaultParameters()
+	assert.Equal(p1.RoundConstants, p2.RoundConstants)
+	assert.Equal(30, len(p1.RoundConstants))
+	_, err := NewParameters(7, 22)
+	assert.ErrorIs(err, ErrInvalidRounds)
+	_, err = NewParameters(8, 23)
+	assert.ErrorIs(err, ErrInvalidRounds)
+	assert.ErrorIs(p1.Permute(make([]goldilocks.Element, Width-1)), ErrInvalidBufSize)
+}
+
+func toHashOut(d [DigestSize]goldilocks.Element) HashOut {
+	var res HashOut
+	for i := range d {
+		res[i] = emulated.ValueOf[emulated.Goldilocks](d[i])
+	}
+	return res
+}
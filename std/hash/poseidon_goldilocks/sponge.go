@@ -0,0 +1,70 @@
+package poseidon_goldilocks
+
+import "github.com/consensys/gnark/frontend"
+
+// Sponge is an in-circuit duplex sponge built on [Poseidon], used for deriving
+// Fiat-Shamir challenges in the same way as the challenger of Plonky2. Absorbed
+// elements are buffered and overwrite the rate part of the state, and squeezed
+// elements are read from the rate part of the state after a permutation call,
+// starting from the last one.
+//
+// It matches [NativeSponge].
+type Sponge struct {
+	h      *Poseidon
+	state  []*baseEl
+	input  []*baseEl
+	output []*baseEl
+}
+
+// NewSponge returns a new duplex sponge with a zero state using the default
+// parameters.
+func NewSponge(api frontend.API) (*Sponge, error) {
+	h, err := NewPoseidon(api)
+	if err != nil {
+		return nil, err
+	}
+	return NewSpongeFromPermutation(h), nil
+}
+
+// NewSpongeFromPermutation returns a new duplex sponge with a zero state using
+// the given permutation.
+func NewSpongeFromPermutation(h *Poseidon) *Sponge {
+	return &Sponge{h: h, state: h.zeroState()}
+}
+
+// Absorb adds elements to the sponge. It invalidates all pending outputs.
+func (s *Sponge) Absorb(elems ...*baseEl) {
+	s.output = s.output[:0]
+	for i := range elems {
+		s.input = append(s.input, elems[i])
+		if len(s.input) == Rate {
+			s.duplex()
+		}
+	}
+}
+
+// AbsorbHash adds the elements of a digest to the sponge.
+func (s *Sponge) AbsorbHash(d HashOut) {
+	for i := range d {
+		s.Absorb(&d[i])
+	}
+}
+
+// Squeeze returns the next output element of the sponge.
+func (s *Sponge) Squeeze() *baseEl {
+	if len(s.input) > 0 || len(s.output) == 0 {
+		s.duplex()
+	}
+	res := s.output[len(s.output)-1]
+	s.output = s.output[:len(s.output)-1]
+	return res
+}
+
+func (s *Sponge) duplex() {
+	copy(s.state, s.input)
+	s.input = s.input[:0]
+	if err := s.h.Permutation(s.state); err != nil {
+		panic(err) // can't happen, the state has the width of the permutation
+	}
+	s.output = append(s.output[:0], s.state[:Rate]...)
+}
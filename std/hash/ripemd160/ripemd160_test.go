@@ -0,0 +1,53 @@
+package ripemd160
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/math/uints"
+	"github.com/consensys/gnark/test"
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // used as reference implementation
+)
+
+type ripemd160Circuit struct {
+	In       []uints.U8
+	Expected [Size]uints.U8
+}
+
+func (c *ripemd160Circuit) Define(api frontend.API) error {
+	h, err := New(api)
+	if err != nil {
+		return err
+	}
+	uapi, err := uints.New[uints.U32](api)
+	if err != nil {
+		return err
+	}
+	h.Write(c.In)
+	res := h.Sum()
+	for i := range c.Expected {
+		uapi.ByteAssertEq(c.Expected[i], res[i])
+	}
+	return nil
+}
+
+func TestRIPEMD160(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, inLen := range []int{0, 3, 55, 56, 64, 150} {
+		in := make([]byte, inLen)
+		_, err := rand.Reader.Read(in)
+		assert.NoError(err)
+		h := ripemd160.New()
+		h.Write(in)
+		var expected [Size]byte
+		copy(expected[:], h.Sum(nil))
+
+		circuit := ripemd160Circuit{In: make([]uints.U8, len(in))}
+		witness := ripemd160Circuit{In: uints.NewU8Array(in)}
+		copy(witness.Expected[:], uints.NewU8Array(expected[:]))
+		err = test.IsSolved(&circuit, &witness, ecc.BN254.ScalarField())
+		assert.NoError(err, "length %d", inLen)
+	}
+}
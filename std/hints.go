@@ -7,6 +7,8 @@ import (
 	"github.com/consensys/gnark/std/algebra/emulated/fields_bls12381"
 	"github.com/consensys/gnark/std/algebra/emulated/fields_bn254"
 	"github.com/consensys/gnark/std/algebra/emulated/fields_bw6761"
+	"github.com/consensys/gnark/std/algebra/emulated/fields_goldilocks"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
 	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
 	"github.com/consensys/gnark/std/algebra/native/fields_bls12377"
 	"github.com/consensys/gnark/std/algebra/native/fields_bls24315"
@@ -19,6 +21,7 @@ import (
 	"github.com/consensys/gnark/std/math/cmp"
 	"github.com/consensys/gnark/std/math/emulated"
 	"github.com/consensys/gnark/std/rangecheck"
+	"github.com/consensys/gnark/std/recursion/nova"
 	"github.com/consensys/gnark/std/selector"
 )
 
@@ -46,14 +49,18 @@ func registerHints() {
 	solver.RegisterHint(fields_bls12381.GetHints()...)
 	solver.RegisterHint(fields_bn254.GetHints()...)
 	solver.RegisterHint(fields_bw6761.GetHints()...)
+	solver.RegisterHint(fields_goldilocks.GetHints()...)
 	// native fields
 	solver.RegisterHint(fields_bls12377.GetHints()...)
 	solver.RegisterHint(fields_bls24315.GetHints()...)
 	// emulated curves
 	solver.RegisterHint(sw_emulated.GetHints()...)
+	solver.RegisterHint(sw_bls12381.GetHints()...)
 	// native curves
 	solver.RegisterHint(sw_bls12377.GetHints()...)
 	solver.RegisterHint(sw_bls24315.GetHints()...)
+	// folding schemes
+	solver.RegisterHint(nova.GetHints()...)
 }
 
 func init() {
@@ -9,10 +9,13 @@
 // With this package, we can instead compute all results for two inputs of
 // length 8 bit and then just perform a lookup on the inputs.
 //
-// We use the [logderivarg] package for the actual log-derivative argument.
+// We use the [logderivarg] package for the actual log-derivative argument, or
+// the native lookup argument of the backend if it is enabled (see
+// [frontend.WithNativeLookups]).
 package logderivprecomp
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -123,5 +126,25 @@ func (t *Precomputed) build(api frontend.API) error {
 		return nil
 	}
 	table := t.buildTable()
+	// if the backend has a native lookup argument, we look the queries up in
+	// a fixed table instead of building the log-derivative argument
+	if lookuper, ok := api.Compiler().(frontend.FixedTableLookuper); ok {
+		entries := make([]*big.Int, len(table))
+		for i := range table {
+			entries[i] = table[i].(*big.Int)
+		}
+		id, err := lookuper.NewFixedTable(entries)
+		if err == nil {
+			for i := range t.queries {
+				lookuper.AssertIsInFixedTable(id, t.queries[i])
+			}
+			return nil
+		}
+		// fall back to the log-derivative argument only if the native lookups
+		// are not enabled
+		if !errors.Is(err, frontend.ErrNativeLookupsDisabled) {
+			return fmt.Errorf("new fixed table: %w", err)
+		}
+	}
 	return logderivarg.Build(t.api, logderivarg.AsTable(table), logderivarg.AsTable(t.queries))
 }
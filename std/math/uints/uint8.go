@@ -24,6 +24,8 @@ package uints
 
 import (
 	"fmt"
+	"math/big"
+	"math/bits"
 
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/std/internal/logderivprecomp"
@@ -261,7 +263,14 @@ func (bf *BinaryField[T]) Add(a ...T) T {
 	}
 	vres := bf.api.Add(va[0], va[1], va[2:]...)
 	res := bf.ValueOf(vres)
-	// TODO: should also check the that carry we omitted is correct.
+	// the sum may overflow, ensure that the carry we omitted is small so that
+	// the result is uniquely defined.
+	nbCarryBits := bits.Len(uint(len(a) - 1))
+	if 8*len(res)+nbCarryBits >= bf.api.Compiler().FieldBitLen() {
+		panic("sum overflows the native field")
+	}
+	carry := bf.api.Div(bf.api.Sub(vres, bf.ToValue(res)), new(big.Int).Lsh(big.NewInt(1), uint(8*len(res))))
+	bf.rchecker.Check(carry, nbCarryBits)
 	return res
 }
 
@@ -1,11 +1,15 @@
 package uints
 
 import (
+	"math/big"
 	"math/bits"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/constraint/solver"
 	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/scs"
 	"github.com/consensys/gnark/test"
 )
 
@@ -79,3 +83,72 @@ func TestRshift(t *testing.T) {
 	err = test.IsSolved(&rshiftCircuit{Shift: 11}, &rshiftCircuit{Shift: 11, In: NewU32(0x12345678), Expected: NewU32(0x12345678 >> 11)}, ecc.BN254.ScalarField())
 	assert.NoError(err)
 }
+
+type binaryOpsCircuit struct {
+	A, B, Xor, And, Sum U32
+}
+
+func (c *binaryOpsCircuit) Define(api frontend.API) error {
+	uapi, err := New[U32](api)
+	if err != nil {
+		return err
+	}
+	uapi.AssertEq(uapi.Xor(c.A, c.B), c.Xor)
+	uapi.AssertEq(uapi.And(c.A, c.B), c.And)
+	uapi.AssertEq(uapi.Add(c.A, c.B), c.Sum)
+	return nil
+}
+
+func TestBinaryOpsNativeLookups(t *testing.T) {
+	assert := test.NewAssert(t)
+	var a, b uint32 = 0x12345678, 0xdeadbeef
+	assert.CheckCircuit(&binaryOpsCircuit{},
+		test.WithValidAssignment(&binaryOpsCircuit{A: NewU32(a), B: NewU32(b), Xor: NewU32(a ^ b), And: NewU32(a & b), Sum: NewU32(a + b)}),
+		test.WithInvalidAssignment(&binaryOpsCircuit{A: NewU32(a), B: NewU32(b), Xor: NewU32(a ^ b), And: NewU32(a | b), Sum: NewU32(a + b)}),
+		test.WithBackends(backend.PLONK),
+		test.WithCurves(ecc.BN254),
+		test.WithCompileOpts(frontend.WithNativeLookups()))
+
+	// the byte operations are looked up in fixed tables instead of being
+	// emulated with the log-derivative argument
+	withLookups, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &binaryOpsCircuit{}, frontend.WithNativeLookups())
+	assert.NoError(err)
+	withoutLookups, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &binaryOpsCircuit{})
+	assert.NoError(err)
+	assert.Less(withLookups.GetNbConstraints(), withoutLookups.GetNbConstraints())
+}
+
+type addCircuit struct {
+	In       [3]U64
+	Expected U64
+}
+
+func (c *addCircuit) Define(api frontend.API) error {
+	uapi, err := New[U64](api)
+	if err != nil {
+		return err
+	}
+	uapi.AssertEq(uapi.Add(c.In[0], c.In[1], c.In[2]), c.Expected)
+	return nil
+}
+
+// maliciousToBytes decomposes the sum minus one. Without constraining the
+// omitted carry, the result of [BinaryField.Add] would be any sequence of bytes.
+func maliciousToBytes(m *big.Int, inputs []*big.Int, outputs []*big.Int) error {
+	in := []*big.Int{inputs[0], new(big.Int).Sub(inputs[1], big.NewInt(1))}
+	return toBytes(m, in, outputs)
+}
+
+func TestAdd(t *testing.T) {
+	assert := test.NewAssert(t)
+	var a, b, c uint64 = 0xffffffffffffffff, 0xfedcba9876543210, 0x0123456789abcdef
+	assert.CheckCircuit(&addCircuit{},
+		test.WithValidAssignment(&addCircuit{In: [3]U64{NewU64(a), NewU64(b), NewU64(c)}, Expected: NewU64(a + b + c)}),
+		test.WithInvalidAssignment(&addCircuit{In: [3]U64{NewU64(a), NewU64(b), NewU64(c)}, Expected: NewU64(a + b)}),
+		test.WithCurves(ecc.BN254))
+	assert.CheckCircuit(&addCircuit{},
+		test.WithInvalidAssignment(&addCircuit{In: [3]U64{NewU64(a), NewU64(b), NewU64(c)}, Expected: NewU64(a + b + c - 1)}),
+		test.WithCurves(ecc.BN254),
+		test.NoTestEngine(),
+		test.WithSolverOpts(solver.OverrideHint(solver.GetHintID(toBytes), maliciousToBytes)))
+}
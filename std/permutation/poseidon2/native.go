@@ -0,0 +1,84 @@
+package poseidon2
+
+import "math/big"
+
+// Permute applies the Poseidon2 permutation natively on the state in place. It
+// is the reference for the in-circuit [Permutation.Permutation] and can be
+// used to compute witnesses off-circuit. All inputs must be reduced modulo the
+// field order.
+func (p *Parameters) Permute(state []*big.Int) error {
+	if len(state) != p.Width {
+		return ErrInvalidBufSize
+	}
+	p.matMulExternal(state)
+	rf := p.NbFullRounds / 2
+	for i := 0; i < rf; i++ {
+		p.addRC(state, i)
+		for j := range state {
+			p.sBox(state[j])
+		}
+		p.matMulExternal(state)
+	}
+	for i := rf; i < rf+p.NbPartialRounds; i++ {
+		p.addRC(state, i)
+		p.sBox(state[0])
+		p.matMulInternal(state)
+	}
+	for i := rf + p.NbPartialRounds; i < p.NbFullRounds+p.NbPartialRounds; i++ {
+		p.addRC(state, i)
+		for j := range state {
+			p.sBox(state[j])
+		}
+		p.matMulExternal(state)
+	}
+	return nil
+}
+
+// Compress returns the compression of left and right using the permutation in
+// Davies-Meyer mode, i.e. P(left, right)[1] + right. It requires width 2.
+func (p *Parameters) Compress(left, right *big.Int) (*big.Int, error) {
+	if p.Width != 2 {
+		return nil, ErrInvalidWidth
+	}
+	state := []*big.Int{new(big.Int).Set(left), new(big.Int).Set(right)}
+	if err := p.Permute(state); err != nil {
+		return nil, err
+	}
+	state[1].Add(state[1], right).Mod(state[1], p.Modulus)
+	return state[1], nil
+}
+
+func (p *Parameters) sBox(x *big.Int) {
+	x.Exp(x, big.NewInt(int64(p.DegreeSBox)), p.Modulus)
+}
+
+func (p *Parameters) addRC(state []*big.Int, round int) {
+	for i, rk := range p.RoundKeys[round] {
+		state[i].Add(state[i], rk).Mod(state[i], p.Modulus)
+	}
+}
+
+// matMulExternal multiplies the state by circ(2, 1) or circ(2, 1, 1).
+func (p *Parameters) matMulExternal(state []*big.Int) {
+	sum := new(big.Int)
+	for i := range state {
+		sum.Add(sum, state[i])
+	}
+	for i := range state {
+		state[i].Add(state[i], sum).Mod(state[i], p.Modulus)
+	}
+}
+
+// matMulInternal multiplies the state by [[2, 1], [1, 3]] or [[2, 1, 1], [1,
+// 2, 1], [1, 1, 3]].
+func (p *Parameters) matMulInternal(state []*big.Int) {
+	sum := new(big.Int)
+	for i := range state {
+		sum.Add(sum, state[i])
+	}
+	last := len(state) - 1
+	state[last].Lsh(state[last], 1)
+	for i := range state {
+		state[i].Add(state[i], sum).Mod(state[i], p.Modulus)
+	}
+}
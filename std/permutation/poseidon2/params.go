@@ -0,0 +1,143 @@
+package poseidon2
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	ErrInvalidWidth   = errors.New("poseidon2: only width 2 and 3 are supported")
+	ErrInvalidRounds  = errors.New("poseidon2: number of full rounds must be even")
+	ErrUnknownCurve   = errors.New("poseidon2: no default parameters for curve")
+	ErrInvalidBufSize = errors.New("poseidon2: state length does not match the width")
+)
+
+// Parameters describe a Poseidon2 instance. The round keys are derived
+// deterministically from a seed string depending on the curve and the other
+// parameters, so that the in-circuit and native implementations agree with the
+// reference implementation in gnark-crypto.
+type Parameters struct {
+	// Width is the size of the state t.
+	Width int
+	// DegreeSBox is the degree d of the power map x -> x^d.
+	DegreeSBox int
+	// NbFullRounds is the total number of full rounds R_F (half of them is
+	// applied at the beginning and half at the end of the permutation).
+	NbFullRounds int
+	// NbPartialRounds is the number of partial rounds R_P.
+	NbPartialRounds int
+	// RoundKeys stores the round constants. For full rounds there are Width
+	// constants per round, for partial rounds only one.
+	RoundKeys [][]*big.Int
+	// Modulus is the order of the field the permutation is defined over.
+	Modulus *big.Int
+
+	curve ecc.ID
+}
+
+// degreeSBox stores the degree of the S-box for every supported curve. They
+// are the same as in gnark-crypto, so that the hashes can be checked
+// off-circuit. For BLS24-315 the power map x -> x^5 is not a permutation of the
+// field, as 5 divides r-1, but it is kept for compatibility.
+var degreeSBox = map[ecc.ID]int{
+	ecc.BN254:     5,
+	ecc.BLS12_381: 5,
+	ecc.BLS12_377: 17,
+	ecc.BW6_761:   5,
+	ecc.BW6_633:   5,
+	ecc.BLS24_315: 5,
+	ecc.BLS24_317: 7,
+}
+
+// defaultPartialRounds stores the number of partial rounds of the default
+// instance for every supported curve. The default instance has width 2 and 6
+// full rounds, as in gnark-crypto.
+var defaultPartialRounds = map[ecc.ID]int{
+	ecc.BN254:     50,
+	ecc.BLS12_381: 50,
+	ecc.BLS12_377: 26,
+	ecc.BW6_761:   50,
+	ecc.BW6_633:   50,
+	ecc.BLS24_315: 50,
+	ecc.BLS24_317: 40,
+}
+
+// GetDefaultParameters returns the default Poseidon2 parameters for the scalar
+// field of the given curve. The permutation has width 2 so that it can be used
+// as a 2-to-1 compression function (see [Parameters.Compress]).
+func GetDefaultParameters(curve ecc.ID) (*Parameters, error) {
+	rp, ok := defaultPartialRounds[curve]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownCurve, curve)
+	}
+	return NewParameters(curve, 2, 6, rp)
+}
+
+// NewParameters returns Poseidon2 parameters for the scalar field of the given
+// curve with width t, nbFullRounds full rounds and nbPartialRounds partial
+// rounds. The round keys are derived from the seed returned by
+// [Parameters.String].
+func NewParameters(curve ecc.ID, width, nbFullRounds, nbPartialRounds int) (*Parameters, error) {
+	d, ok := degreeSBox[curve]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownCurve, curve)
+	}
+	if width != 2 && width != 3 {
+		return nil, ErrInvalidWidth
+	}
+	if nbFullRounds%2 != 0 {
+		return nil, ErrInvalidRounds
+	}
+	p := &Parameters{
+		Width:           width,
+		DegreeSBox:      d,
+		NbFullRounds:    nbFullRounds,
+		NbPartialRounds: nbPartialRounds,
+		Modulus:         curve.ScalarField(),
+		curve:           curve,
+	}
+	p.initRC(p.String())
+	return p, nil
+}
+
+// String returns the seed used to derive the round keys. It is the same as
+// in gnark-crypto.
+func (p *Parameters) String() string {
+	return fmt.Sprintf("Poseidon2-%s[t=%d,rF=%d,rP=%d,d=%d]", strings.ToUpper(p.curve.String()), p.Width, p.NbFullRounds, p.NbPartialRounds, p.DegreeSBox)
+}
+
+// initRC derives the round keys by iterating Keccak256 over the seed. Every
+// output is interpreted as a big-endian integer reduced modulo the field order.
+func (p *Parameters) initRC(seed string) {
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write([]byte(seed))
+	rnd := h.Sum(nil)
+	h.Reset()
+	_, _ = h.Write(rnd)
+
+	next := func() *big.Int {
+		rnd = h.Sum(nil)
+		h.Reset()
+		_, _ = h.Write(rnd)
+		r := new(big.Int).SetBytes(rnd)
+		return r.Mod(r, p.Modulus)
+	}
+
+	rf := p.NbFullRounds / 2
+	p.RoundKeys = make([][]*big.Int, p.NbFullRounds+p.NbPartialRounds)
+	for i := range p.RoundKeys {
+		n := p.Width
+		if i >= rf && i < rf+p.NbPartialRounds {
+			n = 1
+		}
+		p.RoundKeys[i] = make([]*big.Int, n)
+		for j := 0; j < n; j++ {
+			p.RoundKeys[i][j] = next()
+		}
+	}
+}
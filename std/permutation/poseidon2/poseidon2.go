@@ -0,0 +1,142 @@
+// Package poseidon2 implements the Poseidon2 permutation function.
+//
+// Poseidon2 [Poseidon2] is a SNARK-friendly permutation over a prime field.
+// This package exposes the permutation primitive both in-circuit and natively
+// (see [Parameters.Permute]), together with the 2-to-1 compression function
+// built on top of it (see [Permutation.Compress]). For hashing arbitrary length
+// inputs use [github.com/consensys/gnark/std/hash/poseidon2].
+//
+// Only widths 2 and 3 are supported, for which the external and internal
+// linear layers are the ones given in the paper, Section 5.
+//
+// [Poseidon2]: https://eprint.iacr.org/2023/323
+package poseidon2
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/utils"
+)
+
+// Permutation is the in-circuit Poseidon2 permutation.
+type Permutation struct {
+	api    frontend.API
+	params *Parameters
+}
+
+// NewPoseidon2 returns a new Poseidon2 permutation instance over the native
+// field using the default parameters (see [GetDefaultParameters]).
+func NewPoseidon2(api frontend.API) (*Permutation, error) {
+	params, err := GetDefaultParameters(utils.FieldToCurve(api.Compiler().Field()))
+	if err != nil {
+		return nil, err
+	}
+	return &Permutation{api: api, params: params}, nil
+}
+
+// NewPoseidon2FromParameters returns a new Poseidon2 permutation instance over
+// the native field with the given width and number of rounds.
+func NewPoseidon2FromParameters(api frontend.API, width, nbFullRounds, nbPartialRounds int) (*Permutation, error) {
+	params, err := NewParameters(utils.FieldToCurve(api.Compiler().Field()), width, nbFullRounds, nbPartialRounds)
+	if err != nil {
+		return nil, err
+	}
+	return &Permutation{api: api, params: params}, nil
+}
+
+// Parameters returns the parameters of the permutation.
+func (h *Permutation) Parameters() *Parameters {
+	return h.params
+}
+
+// Permutation applies the permutation on the state in place.
+func (h *Permutation) Permutation(state []frontend.Variable) error {
+	if len(state) != h.params.Width {
+		return ErrInvalidBufSize
+	}
+	h.matMulExternalInPlace(state)
+	rf := h.params.NbFullRounds / 2
+	for i := 0; i < rf; i++ {
+		h.addRC(state, i)
+		for j := range state {
+			state[j] = h.sBox(state[j])
+		}
+		h.matMulExternalInPlace(state)
+	}
+	for i := rf; i < rf+h.params.NbPartialRounds; i++ {
+		h.addRC(state, i)
+		state[0] = h.sBox(state[0])
+		h.matMulInternalInPlace(state)
+	}
+	for i := rf + h.params.NbPartialRounds; i < h.params.NbFullRounds+h.params.NbPartialRounds; i++ {
+		h.addRC(state, i)
+		for j := range state {
+			state[j] = h.sBox(state[j])
+		}
+		h.matMulExternalInPlace(state)
+	}
+	return nil
+}
+
+// Compress returns the compression of left and right in Davies-Meyer mode,
+// i.e. P(left, right)[1] + right. It requires a permutation of width 2 and
+// matches [Parameters.Compress].
+func (h *Permutation) Compress(left, right frontend.Variable) frontend.Variable {
+	if h.params.Width != 2 {
+		panic(ErrInvalidWidth)
+	}
+	state := []frontend.Variable{left, right}
+	if err := h.Permutation(state); err != nil {
+		panic(err) // can't happen, the width is checked above
+	}
+	return h.api.Add(state[1], right)
+}
+
+// sBox returns x^d using a square-and-multiply chain.
+func (h *Permutation) sBox(x frontend.Variable) frontend.Variable {
+	d := h.params.DegreeSBox
+	var res frontend.Variable
+	acc := x
+	for d > 0 {
+		if d&1 == 1 {
+			if res == nil {
+				res = acc
+			} else {
+				res = h.api.Mul(res, acc)
+			}
+		}
+		d >>= 1
+		if d > 0 {
+			acc = h.api.Mul(acc, acc)
+		}
+	}
+	return res
+}
+
+func (h *Permutation) addRC(state []frontend.Variable, round int) {
+	for i, rk := range h.params.RoundKeys[round] {
+		state[i] = h.api.Add(state[i], rk)
+	}
+}
+
+// matMulExternalInPlace multiplies the state by circ(2, 1) or circ(2, 1, 1).
+func (h *Permutation) matMulExternalInPlace(state []frontend.Variable) {
+	sum := h.sum(state)
+	for i := range state {
+		state[i] = h.api.Add(state[i], sum)
+	}
+}
+
+// matMulInternalInPlace multiplies the state by [[2, 1], [1, 3]] or [[2, 1,
+// 1], [1, 2, 1], [1, 1, 3]].
+func (h *Permutation) matMulInternalInPlace(state []frontend.Variable) {
+	sum := h.sum(state)
+	last := len(state) - 1
+	for i := 0; i < last; i++ {
+		state[i] = h.api.Add(state[i], sum)
+	}
+	state[last] = h.api.Add(h.api.Mul(state[last], 2), sum)
+}
+
+func (h *Permutation) sum(state []frontend.Variable) frontend.Variable {
+	return h.api.Add(state[0], state[1], state[2:]...)
+}
@@ -0,0 +1,149 @@
+package poseidon2_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/permutation/poseidon2"
+	"github.com/consensys/gnark/test"
+)
+
+type poseidon2Circuit struct {
+	Input    []frontend.Variable
+	Expected []frontend.Variable `gnark:",public"`
+
+	width, nbFullRounds, nbPartialRounds int
+}
+
+func (c *poseidon2Circuit) Define(api frontend.API) error {
+	h, err := poseidon2.NewPoseidon2FromParameters(api, c.width, c.nbFullRounds, c.nbPartialRounds)
+	if err != nil {
+		return err
+	}
+	state := make([]frontend.Variable, len(c.Input))
+	copy(state, c.Input)
+	if err := h.Permutation(state); err != nil {
+		return err
+	}
+	for i := range state {
+		api.AssertIsEqual(state[i], c.Expected[i])
+	}
+	return nil
+}
+
+func TestPoseidon2Permutation(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BW6_761} {
+		for _, width := range []int{2, 3} {
+			params, err := poseidon2.NewParameters(curve, width, 8, 56)
+			assert.NoError(err)
+			input := make([]*big.Int, width)
+			output := make([]*big.Int, width)
+			for i := range input {
+				input[i] = big.NewInt(int64(i))
+				output[i] = big.NewInt(int64(i))
+			}
+			assert.NoError(params.Permute(output))
+
+			circuit := poseidon2Circuit{Input: make([]frontend.Variable, width), Expected: make([]frontend.Variable, width), width: width, nbFullRounds: 8, nbPartialRounds: 56}
+			witness := poseidon2Circuit{Input: make([]frontend.Variable, width), Expected: make([]frontend.Variable, width)}
+			for i := range input {
+				witness.Input[i] = input[i]
+				witness.Expected[i] = output[i]
+			}
+			assert.CheckCircuit(&circuit, test.WithValidAssignment(&witness), test.WithCurves(curve))
+		}
+	}
+}
+
+type compressCircuit struct {
+	Left, Right frontend.Variable
+	Expected    frontend.Variable `gnark:",public"`
+}
+
+func (c *compressCircuit) Define(api frontend.API) error {
+	h, err := poseidon2.NewPoseidon2FromParameters(api, 2, 8, 56)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(h.Compress(c.Left, c.Right), c.Expected)
+	return nil
+}
+
+func TestPoseidon2Compress(t *testing.T) {
+	assert := test.NewAssert(t)
+	params, err := poseidon2.NewParameters(ecc.BN254, 2, 8, 56)
+	assert.NoError(err)
+	left, right := big.NewInt(42), big.NewInt(1337)
+	res, err := params.Compress(left, right)
+	assert.NoError(err)
+	assert.CheckCircuit(&compressCircuit{},
+		test.WithValidAssignment(&compressCircuit{Left: left, Right: right, Expected: res}),
+		test.WithInvalidAssignment(&compressCircuit{Left: left, Right: right, Expected: left}),
+		test.WithCurves(ecc.BN254))
+}
+
+func TestDefaultParameters(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, curve := range []ecc.ID{ecc.BN254, ecc.BLS12_377, ecc.BLS12_381, ecc.BW6_761, ecc.BW6_633, ecc.BLS24_315, ecc.BLS24_317} {
+		params, err := poseidon2.GetDefaultParameters(curve)
+		assert.NoError(err)
+		// x -> x^d must be a permutation of the field, except for BLS24-315
+		// where gnark-crypto uses d = 5 although 5 divides r-1
+		rMinusOne := new(big.Int).Sub(params.Modulus, big.NewInt(1))
+		gcd := new(big.Int).GCD(nil, nil, big.NewInt(int64(params.DegreeSBox)), rMinusOne)
+		if curve == ecc.BLS24_315 {
+			assert.Equal(5, params.DegreeSBox)
+			continue
+		}
+		assert.Equal(int64(1), gcd.Int64(), curve.String())
+	}
+	_, err := poseidon2.GetDefaultParameters(ecc.SECP256K1)
+	assert.Error(err)
+	_, err = poseidon2.NewParameters(ecc.BN254, 4, 8, 56)
+	assert.Error(err)
+}
+
+// TestPermutationKnownAnswers checks the default instances against the
+// outputs of the permutation of (0, 1) computed with the Poseidon2
+// implementation of gnark-crypto v0.17.0.
+func TestPermutationKnownAnswers(t *testing.T) {
+	assert := test.NewAssert(t)
+	for _, tc := range []struct {
+		curve    ecc.ID
+		expected [2]string
+	}{
+		{ecc.BN254, [2]string{
+			"13079098124093204705096814169007477429889534732683206940537456080211104206429",
+			"12157562999385135173166708316607836110878334226144932937475223226141207470305",
+		}},
+		{ecc.BLS12_377, [2]string{
+			"6899563382891411808333444159047527092127376459054259927464647345211008084035",
+			"4571666262401399024127322320412444405848195563131421701616771620227459322209",
+		}},
+		{ecc.BLS12_381, [2]string{
+			"13055978951255794638237842318440671010228434389241742461107793085140977876723",
+			"42394080253455157043399775171944545217767448015442670487935330938891055450595",
+		}},
+		{ecc.BW6_761, [2]string{
+			"148978627167113275726107187162422149634927458755736321874238565200787949722264091364060482374577902245158153500973",
+			"87403355206709512605591970640064156431163004102660571583715012652144007971402350051557943092901385262000854243331",
+		}},
+	} {
+		params, err := poseidon2.GetDefaultParameters(tc.curve)
+		assert.NoError(err)
+		state := []*big.Int{big.NewInt(0), big.NewInt(1)}
+		assert.NoError(params.Permute(state))
+		for i := range state {
+			expected, ok := new(big.Int).SetString(tc.expected[i], 10)
+			assert.True(ok)
+			assert.Equal(expected, state[i], "%s: state[%d]", tc.curve, i)
+		}
+
+		circuit := poseidon2Circuit{Input: make([]frontend.Variable, 2), Expected: make([]frontend.Variable, 2), width: 2, nbFullRounds: params.NbFullRounds, nbPartialRounds: params.NbPartialRounds}
+		witness := poseidon2Circuit{Input: []frontend.Variable{0, 1}, Expected: []frontend.Variable{tc.expected[0], tc.expected[1]}}
+		assert.NoError(test.IsSolved(&circuit, &witness, tc.curve.ScalarField()))
+	}
+}
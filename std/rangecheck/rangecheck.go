@@ -3,6 +3,7 @@
 // This package chooses the most optimal path for performing range checks:
 //   - if the backend supports native range checking and the frontend exports the variables in the proprietary format by implementing [frontend.Rangechecker], then use it directly;
 //   - if the backend supports creating a commitment of variables by implementing [frontend.Committer], then we use the log-derivative variant [[Haböck22]] of the product argument as in [[BCG+18]] . [r1cs.NewBuilder] returns a builder which implements this interface;
+//     if the backend also has a native lookup argument enabled with [frontend.WithNativeLookups], then the limbs are checked against a fixed table instead;
 //   - lacking these, we perform binary decomposition of variable into bits.
 //
 // [BCG+18]: https://eprint.iacr.org/2018/380
@@ -1,6 +1,7 @@
 package rangecheck
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"math/big"
@@ -70,7 +71,36 @@ func (c *commitChecker) commit(api frontend.API) error {
 	if len(c.collected) == 0 {
 		return nil
 	}
+	// if the backend has a native lookup argument, we check the limbs against
+	// a fixed table instead of building the log-derivative argument
+	if lookuper, ok := api.Compiler().(frontend.FixedTableLookuper); ok {
+		baseLength := optimalWidth(nbLookupConstraints, c.collected)
+		nbTable := 1 << baseLength
+		tbl := make([]*big.Int, nbTable)
+		for i := range tbl {
+			tbl[i] = big.NewInt(int64(i))
+		}
+		table, err := lookuper.NewFixedTable(tbl)
+		if err == nil {
+			for _, limb := range c.decompose(api, baseLength) {
+				lookuper.AssertIsInFixedTable(table, limb)
+			}
+			return nil
+		}
+		// fall back to the log-derivative argument only if the native lookups
+		// are not enabled
+		if !errors.Is(err, frontend.ErrNativeLookupsDisabled) {
+			return fmt.Errorf("new fixed table: %w", err)
+		}
+	}
 	baseLength := c.getOptimalBasewidth(api)
+	nbTable := 1 << baseLength
+	return logderivarg.Build(api, logderivarg.AsTable(c.buildTable(nbTable)), logderivarg.AsTable(c.decompose(api, baseLength)))
+}
+
+// decompose decomposes the collected variables into limbs of baseLength bits
+// and returns the limbs to range check.
+func (c *commitChecker) decompose(api frontend.API, baseLength int) []frontend.Variable {
 	// decompose into smaller limbs
 	decomposed := make([]frontend.Variable, 0, len(c.collected))
 	collected := make([]frontend.Variable, len(c.collected))
@@ -107,8 +137,7 @@ func (c *commitChecker) commit(api frontend.API) error {
 			decomposed = append(decomposed, msLimbShifted)
 		}
 	}
-	nbTable := 1 << baseLength
-	return logderivarg.Build(api, logderivarg.AsTable(c.buildTable(nbTable)), logderivarg.AsTable(decomposed))
+	return decomposed
 }
 
 func decompSize(varSize int, limbSize int) int {
@@ -181,6 +210,21 @@ func nbR1CSConstraints(baseLength int, collected []checkedVariable) int {
 	return nbleft + nbRight + eqs + 1
 }
 
+func nbLookupConstraints(baseLength int, collected []checkedVariable) int {
+	nbDecomposed := 0
+	for i := range collected {
+		nbVarLimbs := int(decompSize(collected[i].bits, baseLength))
+		if nbVarLimbs*baseLength > collected[i].bits {
+			nbVarLimbs += 1
+		}
+		nbDecomposed += int(nbVarLimbs)
+	}
+	eqs := nbDecomposed         // check correctness of every decomposition
+	nbRight := nbDecomposed     // lookup per decomposed
+	nbleft := (1 << baseLength) // the table entries are laid out along the rows
+	return nbleft + nbRight + eqs
+}
+
 func nbPLONKConstraints(baseLength int, collected []checkedVariable) int {
 	nbDecomposed := 0
 	for i := range collected {
@@ -1,14 +1,19 @@
 package rangecheck
 
 import (
+	"bytes"
 	"crypto/rand"
 	"math/big"
 	"testing"
 
 	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/logger"
 	"github.com/consensys/gnark/test"
+	"github.com/rs/zerolog"
 )
 
 type CheckCircuit struct {
@@ -44,3 +49,53 @@ func TestCheck(t *testing.T) {
 	_, err = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit, frontend.WithCompressThreshold(100))
 	assert.NoError(err)
 }
+
+func TestCheckNativeLookups(t *testing.T) {
+	assert := test.NewAssert(t)
+	var err error
+	bits := 64
+	nbVals := 100
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	vals := make([]frontend.Variable, nbVals)
+	for i := range vals {
+		vals[i], err = rand.Int(rand.Reader, bound)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	witness := CheckCircuit{Vals: vals, bits: bits}
+	invalid := CheckCircuit{Vals: make([]frontend.Variable, nbVals), bits: bits}
+	copy(invalid.Vals, vals)
+	invalid.Vals[0] = bound
+	circuit := CheckCircuit{Vals: make([]frontend.Variable, len(vals)), bits: bits}
+	assert.CheckCircuit(&circuit,
+		test.WithValidAssignment(&witness),
+		test.WithInvalidAssignment(&invalid),
+		test.WithBackends(backend.PLONK),
+		test.WithCurves(ecc.BN254),
+		test.WithCompileOpts(frontend.WithNativeLookups()))
+
+	// the native lookups are cheaper than the log-derivative argument
+	withLookups, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &circuit, frontend.WithNativeLookups())
+	assert.NoError(err)
+	withoutLookups, err := frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, &circuit)
+	assert.NoError(err)
+	assert.Less(withLookups.GetNbConstraints(), withoutLookups.GetNbConstraints())
+}
+
+func TestNativeLookupsUnsupported(t *testing.T) {
+	assert := test.NewAssert(t)
+	var buf bytes.Buffer
+	defer logger.Set(logger.Logger())
+	logger.Set(zerolog.New(&buf))
+
+	// the R1CS builder has no fixed tables, so the option is ignored with a
+	// warning and the range checks use the log-derivative argument
+	circuit := CheckCircuit{Vals: make([]frontend.Variable, 4), bits: 8}
+	withOption, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit, frontend.WithNativeLookups())
+	assert.NoError(err)
+	assert.Contains(buf.String(), "WithNativeLookups option is ignored")
+	withoutOption, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &circuit)
+	assert.NoError(err)
+	assert.Equal(withoutOption.GetNbConstraints(), withOption.GetNbConstraints())
+}
@@ -0,0 +1,146 @@
+package recursion
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/internal/utils"
+	fiatshamir "github.com/consensys/gnark/std/fiat-shamir"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/permutation/poseidon2"
+	"golang.org/x/exp/slices"
+)
+
+// the duplex-sponge transcripts use the Poseidon2 permutation of width 3, so
+// that two elements are absorbed per permutation.
+const (
+	duplexWidth           = 3
+	duplexNbFullRounds    = 8
+	duplexNbPartialRounds = 56
+)
+
+// duplexSizes returns the number of bytes packed in a field element of the
+// current field when binding values, and the number of bits of the challenges,
+// so that they fit both in the current and in the target field.
+func duplexSizes(current, target *big.Int) (chunkSize, outSize int) {
+	outSize = target.BitLen()
+	if outSize > current.BitLen() {
+		outSize = current.BitLen()
+	}
+	return (current.BitLen() - 1) / 8, outSize - 1
+}
+
+type nativeDuplexTranscript struct {
+	fs        *fiatshamir.NativeDuplexTranscript
+	chunkSize int
+	outSize   int
+}
+
+// NewNativeDuplexTranscript returns a native Fiat-Shamir transcript in
+// duplex-sponge mode over the current native field, computing challenges in the
+// target field (usually the scalar field of the circuit being recursed). The
+// sponge uses the Poseidon2 permutation of width 3. It is the native
+// counterpart of [NewDuplexTranscript] and can be used by the PLONK prover with
+// [backend.WithProverChallengeTranscript].
+//
+// The bound bytes are split in chunks which fit into a field element, every
+// chunk being interpreted as a big-endian integer, and the challenges are
+// truncated so that they fit in the target field.
+func NewNativeDuplexTranscript(current, target *big.Int, challenges ...string) (backend.Transcript, error) {
+	params, err := poseidon2.NewParameters(utils.FieldToCurve(current), duplexWidth, duplexNbFullRounds, duplexNbPartialRounds)
+	if err != nil {
+		return nil, fmt.Errorf("get poseidon2 parameters: %w", err)
+	}
+	fs, err := fiatshamir.NewNativeDuplexTranscript(params, duplexWidth, current, challenges)
+	if err != nil {
+		return nil, err
+	}
+	chunkSize, outSize := duplexSizes(current, target)
+	return &nativeDuplexTranscript{fs: fs, chunkSize: chunkSize, outSize: outSize}, nil
+}
+
+func (t *nativeDuplexTranscript) Bind(challengeID string, value []byte) error {
+	for i := 0; i < len(value); i += t.chunkSize {
+		end := i + t.chunkSize
+		if end > len(value) {
+			end = len(value)
+		}
+		if err := t.fs.Bind(challengeID, new(big.Int).SetBytes(value[i:end])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *nativeDuplexTranscript) ComputeChallenge(challengeID string) ([]byte, error) {
+	res, err := t.fs.ComputeChallenge(challengeID)
+	if err != nil {
+		return nil, err
+	}
+	mask := new(big.Int).Lsh(big.NewInt(1), uint(t.outSize))
+	mask.Sub(mask, big.NewInt(1))
+	return res.And(res, mask).Bytes(), nil
+}
+
+// DuplexTranscript is an in-circuit Fiat-Shamir transcript in duplex-sponge
+// mode, see [NewDuplexTranscript].
+type DuplexTranscript struct {
+	api       frontend.API
+	fs        *fiatshamir.Transcript
+	chunkSize int
+	outSize   int
+}
+
+// NewDuplexTranscript returns a new Fiat-Shamir transcript in duplex-sponge
+// mode computing challenges in the target field (usually the scalar field of
+// the circuit being recursed). It is compatible with the native transcript
+// returned by [NewNativeDuplexTranscript]. Compared to the transcript returned
+// by [NewTranscript], it doesn't hash the challenge names and the previous
+// challenges bit by bit and absorbs two elements per permutation.
+func NewDuplexTranscript(api frontend.API, target *big.Int, challenges []string) (*DuplexTranscript, error) {
+	perm, err := poseidon2.NewPoseidon2FromParameters(api, duplexWidth, duplexNbFullRounds, duplexNbPartialRounds)
+	if err != nil {
+		return nil, fmt.Errorf("get poseidon2: %w", err)
+	}
+	fs, err := fiatshamir.NewDuplexTranscript(api, perm, duplexWidth, challenges)
+	if err != nil {
+		return nil, err
+	}
+	chunkSize, outSize := duplexSizes(api.Compiler().Field(), target)
+	return &DuplexTranscript{api: api, fs: fs, chunkSize: chunkSize, outSize: outSize}, nil
+}
+
+// Bind binds the challenge to a byte string given as bits, the most
+// significant bit of every byte first, as returned by the marshalling methods
+// of [github.com/consensys/gnark/std/algebra.Curve].
+func (t *DuplexTranscript) Bind(challengeID string, values []frontend.Variable) error {
+	if len(values)%8 != 0 {
+		return fmt.Errorf("binding %d bits, not a multiple of a byte", len(values))
+	}
+	chunkBits := 8 * t.chunkSize
+	for i := 0; i < len(values); i += chunkBits {
+		end := i + chunkBits
+		if end > len(values) {
+			end = len(values)
+		}
+		chunk := make([]frontend.Variable, end-i)
+		copy(chunk, values[i:end])
+		slices.Reverse(chunk)
+		if err := t.fs.Bind(challengeID, []frontend.Variable{bits.FromBinary(t.api, chunk)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ComputeChallenge computes the challenge corresponding to the given name.
+func (t *DuplexTranscript) ComputeChallenge(challengeID string) (frontend.Variable, error) {
+	res, err := t.fs.ComputeChallenge(challengeID)
+	if err != nil {
+		return nil, err
+	}
+	resBits := bits.ToBinary(t.api, res)
+	return bits.FromBinary(t.api, resBits[:t.outSize]), nil
+}
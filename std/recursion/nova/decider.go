@@ -0,0 +1,339 @@
+package nova
+
+import (
+	"errors"
+	"fmt"
+
+	novabackend_bls12381 "github.com/consensys/gnark/backend/nova/bls12-381"
+	novabackend_bn254 "github.com/consensys/gnark/backend/nova/bn254"
+	novabackend_bw6761 "github.com/consensys/gnark/backend/nova/bw6-761"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/recursion"
+)
+
+// DeciderProof is the compression of the proof of an incrementally verifiable
+// computation, which the verifier checks natively with VerifyDecider. Use
+// [ValueOfDeciderProof] to initialize the public witness from the native
+// proof.
+//
+// [VerifyDecider]: https://pkg.go.dev/github.com/consensys/gnark/backend/nova/bn254#VerifyDecider
+type DeciderProof[Base emulated.FieldParams] struct {
+	// AccW, AccE and AccCommitted are the commitments of the running instance,
+	// InstanceW and InstanceCommitted the ones of the last instance and T the
+	// commitment to the cross term of their folding
+	AccW, AccE, AccCommitted     sw_emulated.AffinePoint[Base]
+	InstanceW, InstanceCommitted sw_emulated.AffinePoint[Base]
+	T                            sw_emulated.AffinePoint[Base]
+
+	// R is the folding challenge and Value the claimed value of the opening of
+	// the folded commitments
+	R, Value frontend.Variable
+}
+
+// DeciderCircuit compresses the proof of an incrementally verifiable
+// computation of the augmented step circuit Augmented (see [AugmentedCircuit]),
+// to be proven with Groth16. Its public inputs are the number of steps I, the
+// initial state Z0, the final state Z and the decider proof.
+//
+// It checks that the public input of the last instance uₙ is the digest H(n,
+// z₀, zₙ, Uₙ) of the state and of the running instance Uₙ, and that its
+// commitment wire is derived from its committed wires. It folds the scalars of
+// uₙ into Uₙ with the folding challenge r and checks that the witness W
+// satisfies the folded relaxed R1CS, where the error vector E is the one
+// defined by W. The commitments are folded by the verifier, which checks the
+// KZG opening of W + γE + γ²·Committed at ζ to the value computed in-circuit
+// from W, E and the blinding factors. The challenges γ and ζ are derived from
+// r, which binds the commitments, so the commitment key must be the one of
+// SetupKZG.
+//
+// Use [PlaceholderDeciderCircuit] for compiling the circuit and
+// [ValueOfDeciderCircuit] for the assignment.
+type DeciderCircuit[Base, Scalar emulated.FieldParams] struct {
+	I     frontend.Variable   `gnark:",public"`
+	Z0, Z []frontend.Variable `gnark:",public"`
+	Proof DeciderProof[Base]  `gnark:",public"`
+
+	// U, X and Commitment are the scalars of the running instance, and
+	// InstanceX and InstanceCommitment the ones of the last instance
+	U, X, Commitment              frontend.Variable
+	InstanceX, InstanceCommitment frontend.Variable
+
+	// W is the witness vector of the folded instance, and BlindingW,
+	// BlindingE and BlindingCommitted the blinding factors of its commitments
+	W                                       []frontend.Variable
+	BlindingW, BlindingE, BlindingCommitted frontend.Variable
+
+	Augmented constraint.ConstraintSystem `gnark:"-"`
+}
+
+// Define implements the decider circuit.
+func (c *DeciderCircuit[Base, Scalar]) Define(api frontend.API) error {
+	var fr Scalar
+	if fr.Modulus().Cmp(api.Compiler().Field()) != 0 {
+		return errAugmentedField
+	}
+	if len(c.Z) != len(c.Z0) {
+		return errInvalidStepArity
+	}
+	r1cs, ok := c.Augmented.(constraint.R1CS)
+	if !ok {
+		return errNotR1CS
+	}
+	commitments, ok := c.Augmented.GetCommitments().(constraint.Groth16Commitments)
+	if !ok || len(commitments) != 1 {
+		return errAugmentedCommitment
+	}
+	nbPublic := c.Augmented.GetNbPublicVariables()
+	nbWitness := c.Augmented.GetNbSecretVariables() + c.Augmented.GetNbInternalVariables()
+	nbConstraints := c.Augmented.GetNbConstraints()
+	if nbPublic != 2 || len(c.W) != nbWitness {
+		return errors.New("mismatching witness size")
+	}
+	curve, err := sw_emulated.New[Base, Scalar](api, sw_emulated.GetCurveParams[Base]())
+	if err != nil {
+		return fmt.Errorf("new curve: %w", err)
+	}
+	baseApi, err := emulated.NewField[Base](api)
+	if err != nil {
+		return fmt.Errorf("new base field: %w", err)
+	}
+	acc := AugmentedRelaxedInstance[Base]{
+		W: c.Proof.AccW, E: c.Proof.AccE, Committed: c.Proof.AccCommitted,
+		U: c.U, X: c.X, Commitment: c.Commitment,
+	}
+	instance := AugmentedInstance[Base]{
+		W: c.Proof.InstanceW, Committed: c.Proof.InstanceCommitted,
+		X: c.InstanceX, Commitment: c.InstanceCommitment,
+	}
+
+	// uₙ.x = H(n, z₀, zₙ, Uₙ) with n > 0
+	api.AssertIsDifferent(c.I, 0)
+	digest, err := digestState(api, curve, c.I, c.Z0, c.Z, &acc)
+	if err != nil {
+		return fmt.Errorf("digest of the state: %w", err)
+	}
+	api.AssertIsEqual(instance.X, digest)
+	commitment, err := commitmentWire(api, curve, &instance.Committed)
+	if err != nil {
+		return fmt.Errorf("commitment wire: %w", err)
+	}
+	api.AssertIsEqual(instance.Commitment, commitment)
+
+	// the scalars of Uₙ₊₁ = Uₙ + r·uₙ, the commitments are folded by the
+	// verifier
+	r, err := foldingChallenge(api, curve, baseApi, &acc, &instance, &FoldingProof[sw_emulated.AffinePoint[Base]]{T: c.Proof.T})
+	if err != nil {
+		return fmt.Errorf("folding challenge: %w", err)
+	}
+	// the challenge is recomposed from its bits, use the public input from now
+	// on to keep the linear expressions short
+	api.AssertIsEqual(c.Proof.R, r)
+	r = c.Proof.R
+	u := api.Add(c.U, r)
+	x := api.Add(c.X, api.Mul(r, c.InstanceX))
+	commitmentIndex := commitments[0].CommitmentIndex - nbPublic
+	api.AssertIsEqual(c.W[commitmentIndex], api.Add(c.Commitment, api.Mul(r, c.InstanceCommitment)))
+
+	// E = Az∘Bz - u·Cz with z = (u, x, W)
+	z := make([]frontend.Variable, 0, nbPublic+nbWitness)
+	z = append(z, u, x)
+	z = append(z, c.W...)
+	e := make([]frontend.Variable, 0, nbConstraints)
+	it := r1cs.GetR1CIterator()
+	for r1c := it.Next(); r1c != nil; r1c = it.Next() {
+		a := evaluateNativeLinearExpression(api, c.Augmented, r1c.L, z)
+		b := evaluateNativeLinearExpression(api, c.Augmented, r1c.R, z)
+		o := evaluateNativeLinearExpression(api, c.Augmented, r1c.O, z)
+		e = append(e, api.Sub(api.Mul(a, b), api.Mul(u, o)))
+	}
+
+	// ∑ᵢ (Uᵢ + γEᵢ + γ²Kᵢ)ζⁱ + (ρ_W + γρ_E + γ²ρ_K)ζⁿ, where K are the
+	// committed wires of W and U the other ones except the commitment wire
+	gamma, zeta, err := deciderChallenges(api, r)
+	if err != nil {
+		return fmt.Errorf("decider challenges: %w", err)
+	}
+	gamma2 := api.Mul(gamma, gamma)
+	isCommitted := make([]bool, nbWitness)
+	for _, j := range commitments[0].PrivateCommitted {
+		isCommitted[j-nbPublic] = true
+	}
+	n := nbWitness
+	if nbConstraints > n {
+		n = nbConstraints
+	}
+	value := api.Add(c.BlindingW, api.Mul(gamma, c.BlindingE), api.Mul(gamma2, c.BlindingCommitted))
+	for i := n - 1; i >= 0; i-- {
+		terms := []frontend.Variable{api.Mul(value, zeta), 0}
+		switch {
+		case i >= nbWitness || i == commitmentIndex:
+		case isCommitted[i]:
+			terms = append(terms, api.Mul(gamma2, c.W[i]))
+		default:
+			terms = append(terms, c.W[i])
+		}
+		if i < nbConstraints {
+			terms = append(terms, api.Mul(gamma, e[i]))
+		}
+		value = api.Add(terms[0], terms[1], terms[2:]...)
+	}
+	api.AssertIsEqual(c.Proof.Value, value)
+	return nil
+}
+
+// deciderChallenges returns the challenges γ and ζ of the opening of the
+// folded instance, as the native FoldDecider.
+func deciderChallenges(api frontend.API, r frontend.Variable) (gamma, zeta frontend.Variable, err error) {
+	fs, err := recursion.NewTranscript(api, api.Compiler().Field(), []string{"gamma", "zeta"})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = fs.Bind("gamma", marshalNative(api, r)); err != nil {
+		return nil, nil, err
+	}
+	if gamma, err = fs.ComputeChallenge("gamma"); err != nil {
+		return nil, nil, err
+	}
+	if zeta, err = fs.ComputeChallenge("zeta"); err != nil {
+		return nil, nil, err
+	}
+	if gamma, err = compress(api, gamma); err != nil {
+		return nil, nil, err
+	}
+	if zeta, err = compress(api, zeta); err != nil {
+		return nil, nil, err
+	}
+	return gamma, zeta, nil
+}
+
+// compress returns a single wire equal to v. The challenges are recomposed from
+// their bits and their linear expressions would otherwise be copied in every
+// constraint using them.
+func compress(api frontend.API, v frontend.Variable) (frontend.Variable, error) {
+	res, err := api.Compiler().NewHint(copyHint, 1, v)
+	if err != nil {
+		return nil, err
+	}
+	api.AssertIsEqual(res[0], v)
+	return res[0], nil
+}
+
+// evaluateNativeLinearExpression returns the value of l at z, without
+// constraints.
+func evaluateNativeLinearExpression(api frontend.API, ccs constraint.ConstraintSystem, l constraint.LinearExpression, z []frontend.Variable) frontend.Variable {
+	terms := make([]frontend.Variable, 2, len(l)+2)
+	terms[0], terms[1] = 0, 0
+	for _, t := range l {
+		coeff := ccs.ToBigInt(ccs.GetCoefficient(t.CoeffID()))
+		terms = append(terms, api.Mul(coeff, z[t.WireID()]))
+	}
+	return api.Add(terms[0], terms[1], terms[2:]...)
+}
+
+// PlaceholderDeciderCircuit returns a placeholder of the decider circuit of the
+// augmented step circuit augmented, with states of length arity, to be used
+// for compiling. For actual witness assignment use [ValueOfDeciderCircuit].
+func PlaceholderDeciderCircuit[Base, Scalar emulated.FieldParams](augmented constraint.ConstraintSystem, arity int) *DeciderCircuit[Base, Scalar] {
+	return &DeciderCircuit[Base, Scalar]{
+		Z0:        make([]frontend.Variable, arity),
+		Z:         make([]frontend.Variable, arity),
+		W:         make([]frontend.Variable, augmented.GetNbSecretVariables()+augmented.GetNbInternalVariables()),
+		Augmented: augmented,
+	}
+}
+
+// ValueOfDeciderCircuit returns the assignment of the decider circuit for
+// compressing the proof p. The native proof p is returned by ProveIVC, and s
+// by FoldDecider. It returns an error if there is a mismatch between the type
+// parameters and the provided native values.
+func ValueOfDeciderCircuit[Base, Scalar emulated.FieldParams](p, s any) (*DeciderCircuit[Base, Scalar], error) {
+	var ret DeciderCircuit[Base, Scalar]
+	var err error
+	switch tp := p.(type) {
+	case *novabackend_bls12381.IVCProof:
+		ts, ok := s.(*novabackend_bls12381.DeciderStep)
+		if !ok {
+			return nil, fmt.Errorf("expected %T, got %T", ts, s)
+		}
+		if len(tp.Acc.Public) != 1 || len(tp.Instance.Public) != 1 {
+			return nil, errors.New("the instances of the augmented circuit have one public input")
+		}
+		ret = DeciderCircuit[Base, Scalar]{
+			I: tp.Steps, Z0: toVariables(tp.Z0), Z: toVariables(tp.Z),
+			U: tp.Acc.U, X: tp.Acc.Public[0], Commitment: tp.Acc.Commitment,
+			InstanceX: tp.Instance.Public[0], InstanceCommitment: tp.Instance.Commitment,
+			W:         toVariables(ts.AccWitness.W),
+			BlindingW: ts.AccWitness.BlindingW, BlindingE: ts.AccWitness.BlindingE, BlindingCommitted: ts.AccWitness.BlindingCommitted,
+		}
+		ret.Proof, err = ValueOfDeciderProof[Base](&ts.Proof)
+	case *novabackend_bn254.IVCProof:
+		ts, ok := s.(*novabackend_bn254.DeciderStep)
+		if !ok {
+			return nil, fmt.Errorf("expected %T, got %T", ts, s)
+		}
+		if len(tp.Acc.Public) != 1 || len(tp.Instance.Public) != 1 {
+			return nil, errors.New("the instances of the augmented circuit have one public input")
+		}
+		ret = DeciderCircuit[Base, Scalar]{
+			I: tp.Steps, Z0: toVariables(tp.Z0), Z: toVariables(tp.Z),
+			U: tp.Acc.U, X: tp.Acc.Public[0], Commitment: tp.Acc.Commitment,
+			InstanceX: tp.Instance.Public[0], InstanceCommitment: tp.Instance.Commitment,
+			W:         toVariables(ts.AccWitness.W),
+			BlindingW: ts.AccWitness.BlindingW, BlindingE: ts.AccWitness.BlindingE, BlindingCommitted: ts.AccWitness.BlindingCommitted,
+		}
+		ret.Proof, err = ValueOfDeciderProof[Base](&ts.Proof)
+	case *novabackend_bw6761.IVCProof:
+		ts, ok := s.(*novabackend_bw6761.DeciderStep)
+		if !ok {
+			return nil, fmt.Errorf("expected %T, got %T", ts, s)
+		}
+		if len(tp.Acc.Public) != 1 || len(tp.Instance.Public) != 1 {
+			return nil, errors.New("the instances of the augmented circuit have one public input")
+		}
+		ret = DeciderCircuit[Base, Scalar]{
+			I: tp.Steps, Z0: toVariables(tp.Z0), Z: toVariables(tp.Z),
+			U: tp.Acc.U, X: tp.Acc.Public[0], Commitment: tp.Acc.Commitment,
+			InstanceX: tp.Instance.Public[0], InstanceCommitment: tp.Instance.Commitment,
+			W:         toVariables(ts.AccWitness.W),
+			BlindingW: ts.AccWitness.BlindingW, BlindingE: ts.AccWitness.BlindingE, BlindingCommitted: ts.AccWitness.BlindingCommitted,
+		}
+		ret.Proof, err = ValueOfDeciderProof[Base](&ts.Proof)
+	default:
+		return nil, fmt.Errorf("unknown parametric type combination: %T", p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// ValueOfDeciderProof returns the typed witness of the native decider proof,
+// for the public witness of the decider circuit. It returns an error if there
+// is a mismatch between the type parameters and the provided native proof.
+func ValueOfDeciderProof[Base emulated.FieldParams](proof any) (DeciderProof[Base], error) {
+	var ret DeciderProof[Base]
+	var points []any
+	switch tProof := proof.(type) {
+	case *novabackend_bls12381.DeciderProof:
+		points = []any{tProof.AccW, tProof.AccE, tProof.AccCommitted, tProof.InstanceW, tProof.InstanceCommitted, tProof.T}
+		ret.R, ret.Value = tProof.R, tProof.Opening.ClaimedValue
+	case *novabackend_bn254.DeciderProof:
+		points = []any{tProof.AccW, tProof.AccE, tProof.AccCommitted, tProof.InstanceW, tProof.InstanceCommitted, tProof.T}
+		ret.R, ret.Value = tProof.R, tProof.Opening.ClaimedValue
+	case *novabackend_bw6761.DeciderProof:
+		points = []any{tProof.AccW, tProof.AccE, tProof.AccCommitted, tProof.InstanceW, tProof.InstanceCommitted, tProof.T}
+		ret.R, ret.Value = tProof.R, tProof.Opening.ClaimedValue
+	default:
+		return ret, fmt.Errorf("unknown parametric type combination: %T", proof)
+	}
+	var err error
+	for i, dst := range []*sw_emulated.AffinePoint[Base]{&ret.AccW, &ret.AccE, &ret.AccCommitted, &ret.InstanceW, &ret.InstanceCommitted, &ret.T} {
+		if *dst, err = valueOfG1[sw_emulated.AffinePoint[Base]](points[i]); err != nil {
+			return ret, err
+		}
+	}
+	return ret, nil
+}
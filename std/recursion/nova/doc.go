@@ -0,0 +1,47 @@
+// Package nova implements the in-circuit verifier of the Nova folding scheme
+// over R1CS, see [github.com/consensys/gnark/backend/nova].
+//
+// # Incrementally verifiable computation
+//
+// [AugmentedCircuit] is the step circuit of an incrementally verifiable
+// computation zᵢ₊₁ = F(zᵢ), for a step function F implementing [StepCircuit].
+// Each step checks that the instance of the previous step outputs the digest of
+// the state and of the running instance, folds it into the running instance
+// and applies F. The commitments are points of the curve whose scalar field is
+// the native field, in emulated arithmetic, which is supported for BN254,
+// BLS12-381 and BW6-761. The steps are proven and verified with the native IVC
+// prover and verifier, with the hash functions returned by
+// [GetNativeProverOptions] and [GetNativeVerifierOptions]. The augmented
+// circuit doesn't depend on the number of steps.
+//
+// # Compression of an incrementally verifiable computation
+//
+// [DeciderCircuit] compresses an incrementally verifiable computation into a
+// proof of constant size, for example with Groth16. The public inputs are the
+// number of steps, the initial and final states and the commitments of the
+// final running and last instances, the challenge which folds them and the
+// value of the opening of the folded commitments. The circuit checks that the
+// last instance outputs the digest of the state, folds the scalars of the
+// instances and checks that the folded witness satisfies the relaxed R1CS of
+// the augmented circuit in native arithmetic. The commitment key must be
+// derived from a KZG SRS, and the native verifier of the decider checks the
+// folding and the opening of the commitments.
+//
+// # Compression of a fixed number of steps
+//
+// [Verifier.AssertSteps] folds a fixed number of committed instances of a step
+// circuit, checks that they are consecutive steps of the same computation and
+// decides the folded relaxed R1CS instance, so that proving the circuit (for
+// example with Groth16) compresses all the steps in a single succinct proof.
+// Folding costs two scalar multiplications per step instead of a full SNARK
+// verification, but the number of steps is fixed when compiling the circuit and
+// its size grows linearly with it. The decider checks the relaxed R1CS in
+// emulated arithmetic and opens Pedersen commitments with a multi-scalar
+// multiplication of the size of the step circuit. The step circuit must not use
+// commitments, so the augmented circuit can't be compressed this way. The inner
+// curve must be supported by [algebra.Curve], either as a 2-chain (BLS12-377 in
+// BW6-761, BLS24-315 in BW6-633) or emulated.
+//
+// The native folding prover must compute the folding challenges with the hash
+// function returned by [GetNativeProverOptions].
+package nova
@@ -0,0 +1,375 @@
+package nova
+
+import (
+	"errors"
+	"fmt"
+
+	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	fr_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr"
+	novabackend_bls12381 "github.com/consensys/gnark/backend/nova/bls12-381"
+	novabackend_bn254 "github.com/consensys/gnark/backend/nova/bn254"
+	novabackend_bw6761 "github.com/consensys/gnark/backend/nova/bw6-761"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/recursion"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	errAugmentedField      = errors.New("the augmented step circuit must be defined over the scalar field of the commitments")
+	errAugmentedCommitment = errors.New("the instances of the augmented step circuit must commit to the wires of the emulated arithmetic")
+)
+
+// StepCircuit is the step function F of an incrementally verifiable
+// computation zᵢ₊₁ = F(zᵢ, ωᵢ). The non-deterministic advice ωᵢ are the fields
+// of the implementation, which are assigned at each step.
+type StepCircuit interface {
+	// Step returns the state after the step from the state z.
+	Step(api frontend.API, z []frontend.Variable) ([]frontend.Variable, error)
+}
+
+// AugmentedInstance is a committed instance of the augmented step circuit. Its
+// only public input X is native. The emulated arithmetic of the circuit
+// commits to some wires, which are committed separately in Committed, and
+// Commitment is the value of the commitment wire.
+type AugmentedInstance[Base emulated.FieldParams] struct {
+	W, Committed  sw_emulated.AffinePoint[Base]
+	X, Commitment frontend.Variable
+}
+
+// AugmentedRelaxedInstance is a committed relaxed instance of the augmented step
+// circuit.
+type AugmentedRelaxedInstance[Base emulated.FieldParams] struct {
+	W, E, Committed  sw_emulated.AffinePoint[Base]
+	U, X, Commitment frontend.Variable
+}
+
+// AugmentedCircuit is the step circuit augmented with the folding verifier, to
+// prove the steps of an incrementally verifiable computation with the native
+// IVC prover of [github.com/consensys/gnark/backend/nova].
+//
+// The i-th step checks that the public input of the instance uᵢ of the
+// previous step is the digest H(i, z₀, zᵢ, Uᵢ) and that its commitment wire is
+// derived from its committed wires, folds uᵢ into the running
+// instance Uᵢ, applies the step function to zᵢ and outputs the digest H(i+1,
+// z₀, zᵢ₊₁, Uᵢ₊₁) as its public input X. The first step starts from z₀ and the
+// trivial running instance. The instances of the augmented circuit are over
+// the scalar field of the curve, which must be the native field, and their
+// commitments are points of the curve in emulated arithmetic.
+//
+// Use [PlaceholderAugmentedCircuit] for compiling the circuit and
+// [ValueOfAugmentedCircuit] for the assignment of each step. The proof of the
+// steps is compressed with [DeciderCircuit].
+type AugmentedCircuit[Base, Scalar emulated.FieldParams] struct {
+	X frontend.Variable `gnark:",public"`
+
+	// I is the index of the step, starting from zero
+	I frontend.Variable
+
+	// Z0 is the initial state, Z the state before the step and Next the state
+	// after the step
+	Z0, Z, Next []frontend.Variable
+
+	// Acc is the running instance Uᵢ and Instance the instance uᵢ of the
+	// previous step, which are folded with Proof. They are ignored in the first
+	// step.
+	Acc      AugmentedRelaxedInstance[Base]
+	Instance AugmentedInstance[Base]
+	Proof    FoldingProof[sw_emulated.AffinePoint[Base]]
+
+	Step StepCircuit
+}
+
+// Define implements the augmented step circuit.
+func (c *AugmentedCircuit[Base, Scalar]) Define(api frontend.API) error {
+	var fr Scalar
+	if fr.Modulus().Cmp(api.Compiler().Field()) != 0 {
+		return errAugmentedField
+	}
+	if len(c.Z) != len(c.Z0) || len(c.Next) != len(c.Z0) {
+		return errInvalidStepArity
+	}
+	curve, err := sw_emulated.New[Base, Scalar](api, sw_emulated.GetCurveParams[Base]())
+	if err != nil {
+		return fmt.Errorf("new curve: %w", err)
+	}
+	baseApi, err := emulated.NewField[Base](api)
+	if err != nil {
+		return fmt.Errorf("new base field: %w", err)
+	}
+	scalarApi, err := emulated.NewField[Scalar](api)
+	if err != nil {
+		return fmt.Errorf("new scalar field: %w", err)
+	}
+	isFirst := api.IsZero(c.I)
+
+	// uᵢ.x = H(i, z₀, zᵢ, Uᵢ), except in the first step
+	digest, err := digestState(api, curve, c.I, c.Z0, c.Z, &c.Acc)
+	if err != nil {
+		return fmt.Errorf("digest of the state: %w", err)
+	}
+	api.AssertIsEqual(api.Select(isFirst, 0, api.Sub(c.Instance.X, digest)), 0)
+	commitment, err := commitmentWire(api, curve, &c.Instance.Committed)
+	if err != nil {
+		return fmt.Errorf("commitment wire: %w", err)
+	}
+	api.AssertIsEqual(api.Select(isFirst, 0, api.Sub(c.Instance.Commitment, commitment)), 0)
+
+	// Uᵢ₊₁ = Uᵢ + r·uᵢ, or the trivial instance in the first step. The
+	// commitments may be the point at infinity (0,0).
+	r, err := foldingChallenge(api, curve, baseApi, &c.Acc, &c.Instance, &c.Proof)
+	if err != nil {
+		return fmt.Errorf("folding challenge: %w", err)
+	}
+	rBits := bits.ToBinary(api, r, bits.WithNbDigits(fr.Modulus().BitLen()))
+	rScalar := scalarApi.FromBits(rBits...)
+	W := curve.AddUnified(&c.Acc.W, curve.ScalarMul(&c.Instance.W, rScalar, algopts.WithCompleteArithmetic()))
+	E := curve.AddUnified(&c.Acc.E, curve.ScalarMul(&c.Proof.T, rScalar, algopts.WithCompleteArithmetic()))
+	committed := curve.AddUnified(&c.Acc.Committed, curve.ScalarMul(&c.Instance.Committed, rScalar, algopts.WithCompleteArithmetic()))
+	infinity := &sw_emulated.AffinePoint[Base]{X: *baseApi.Zero(), Y: *baseApi.Zero()}
+	acc := AugmentedRelaxedInstance[Base]{
+		W:          *curve.Select(isFirst, infinity, W),
+		E:          *curve.Select(isFirst, infinity, E),
+		Committed:  *curve.Select(isFirst, infinity, committed),
+		U:          api.Select(isFirst, 0, api.Add(c.Acc.U, r)),
+		X:          api.Select(isFirst, 0, api.Add(c.Acc.X, api.Mul(r, c.Instance.X))),
+		Commitment: api.Select(isFirst, 0, api.Add(c.Acc.Commitment, api.Mul(r, c.Instance.Commitment))),
+	}
+
+	// zᵢ₊₁ = F(zᵢ), starting from z₀
+	z := make([]frontend.Variable, len(c.Z))
+	for j := range z {
+		z[j] = api.Select(isFirst, c.Z0[j], c.Z[j])
+	}
+	next, err := c.Step.Step(api, z)
+	if err != nil {
+		return fmt.Errorf("step: %w", err)
+	}
+	if len(next) != len(c.Next) {
+		return errInvalidStepArity
+	}
+	for j := range next {
+		api.AssertIsEqual(next[j], c.Next[j])
+	}
+
+	digest, err = digestState(api, curve, api.Add(c.I, 1), c.Z0, c.Next, &acc)
+	if err != nil {
+		return fmt.Errorf("digest of the next state: %w", err)
+	}
+	api.AssertIsEqual(c.X, digest)
+	return nil
+}
+
+// digestState returns H(i, z₀, z, U) as the native [Digest], with the hash
+// [recursion.NewShort].
+//
+// [Digest]: https://pkg.go.dev/github.com/consensys/gnark/backend/nova/bn254#Digest
+func digestState[Base, Scalar emulated.FieldParams](api frontend.API, curve *sw_emulated.Curve[Base, Scalar], i frontend.Variable, z0, z []frontend.Variable, acc *AugmentedRelaxedInstance[Base]) (frontend.Variable, error) {
+	h, err := recursion.NewHash(api, api.Compiler().Field(), true)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(marshalNative(api, i)...)
+	for _, v := range [][]frontend.Variable{z0, z} {
+		for j := range v {
+			h.Write(marshalNative(api, v[j])...)
+		}
+	}
+	h.Write(curve.MarshalG1(acc.W)...)
+	h.Write(curve.MarshalG1(acc.E)...)
+	h.Write(curve.MarshalG1(acc.Committed)...)
+	h.Write(marshalNative(api, acc.U)...)
+	h.Write(marshalNative(api, acc.X)...)
+	h.Write(marshalNative(api, acc.Commitment)...)
+	return h.Sum(), nil
+}
+
+// commitmentWire returns the value of the commitment wire derived from the
+// commitment to the committed wires, as the native NewInstance.
+func commitmentWire[Base, Scalar emulated.FieldParams](api frontend.API, curve *sw_emulated.Curve[Base, Scalar], committed *sw_emulated.AffinePoint[Base]) (frontend.Variable, error) {
+	h, err := recursion.NewHash(api, api.Compiler().Field(), true)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(curve.MarshalG1(*committed)...)
+	return h.Sum(), nil
+}
+
+// foldingChallenge returns the challenge of the folding of instance into acc,
+// as the native [VerifyFold]. The native folding doesn't bind the commitment
+// to the error vector when it is the point at infinity, so both challenges are
+// computed.
+//
+// [VerifyFold]: https://pkg.go.dev/github.com/consensys/gnark/backend/nova/bn254#VerifyFold
+func foldingChallenge[Base, Scalar emulated.FieldParams](api frontend.API, curve *sw_emulated.Curve[Base, Scalar], baseApi *emulated.Field[Base], acc *AugmentedRelaxedInstance[Base], instance *AugmentedInstance[Base], proof *FoldingProof[sw_emulated.AffinePoint[Base]]) (frontend.Variable, error) {
+	accW := curve.MarshalG1(acc.W)
+	accE := curve.MarshalG1(acc.E)
+	rest := [][]frontend.Variable{
+		marshalNative(api, acc.U),
+		marshalNative(api, acc.X),
+		curve.MarshalG1(acc.Committed),
+		marshalNative(api, acc.Commitment),
+		curve.MarshalG1(instance.W),
+		marshalNative(api, instance.X),
+		curve.MarshalG1(instance.Committed),
+		marshalNative(api, instance.Commitment),
+		curve.MarshalG1(proof.T),
+	}
+
+	var challenges [2]frontend.Variable
+	for k, toBind := range [][][]frontend.Variable{
+		append([][]frontend.Variable{accW}, rest...),
+		append([][]frontend.Variable{accW, accE}, rest...),
+	} {
+		fs, err := recursion.NewTranscript(api, api.Compiler().Field(), []string{"r"})
+		if err != nil {
+			return nil, err
+		}
+		for i := range toBind {
+			if err := fs.Bind("r", toBind[i]); err != nil {
+				return nil, err
+			}
+		}
+		if challenges[k], err = fs.ComputeChallenge("r"); err != nil {
+			return nil, err
+		}
+	}
+	isInfinity := api.And(baseApi.IsZero(&acc.E.X), baseApi.IsZero(&acc.E.Y))
+	return api.Select(isInfinity, challenges[0], challenges[1]), nil
+}
+
+// marshalNative returns the bits of v in big endian, on a whole number of
+// bytes, as the marshalling of the scalars in gnark-crypto.
+func marshalNative(api frontend.API, v frontend.Variable) []frontend.Variable {
+	nbBits := 8 * ((api.Compiler().FieldBitLen() + 7) / 8)
+	res := bits.ToBinary(api, v, bits.WithNbDigits(nbBits))
+	slices.Reverse(res)
+	return res
+}
+
+// PlaceholderAugmentedCircuit returns a placeholder of the augmented circuit
+// of the step circuit step, with states of length arity, to be used for
+// compiling. For actual witness assignment use [ValueOfAugmentedCircuit].
+func PlaceholderAugmentedCircuit[Base, Scalar emulated.FieldParams](arity int, step StepCircuit) *AugmentedCircuit[Base, Scalar] {
+	return &AugmentedCircuit[Base, Scalar]{
+		Z0:   make([]frontend.Variable, arity),
+		Z:    make([]frontend.Variable, arity),
+		Next: make([]frontend.Variable, arity),
+		Step: step,
+	}
+}
+
+// ValueOfAugmentedCircuit returns the assignment of the augmented circuit for
+// proving the step s after the proof p, where step is the assignment of the
+// step circuit. The native proof p is returned by NewIVCProof or ProveIVC, and
+// the native step s by FoldIVC. It returns an error if there is a mismatch
+// between the type parameters and the provided native values.
+func ValueOfAugmentedCircuit[Base, Scalar emulated.FieldParams](p, s any, step StepCircuit) (*AugmentedCircuit[Base, Scalar], error) {
+	switch tp := p.(type) {
+	case *novabackend_bls12381.IVCProof:
+		ts, ok := s.(*novabackend_bls12381.IVCStep)
+		if !ok {
+			return nil, fmt.Errorf("expected %T, got %T", ts, s)
+		}
+		if tp.Acc.Committed == nil || tp.Instance.Committed == nil {
+			return nil, errAugmentedCommitment
+		}
+		return valueOfAugmentedCircuit[Base, Scalar](nativeAugmentedCircuit[fr_bls12381.Element]{
+			steps: tp.Steps, z0: tp.Z0, z: tp.Z, next: ts.Next, digest: ts.Digest,
+			accW: tp.Acc.W, accE: tp.Acc.E, accU: tp.Acc.U, accPublic: tp.Acc.Public,
+			accCommitted: *tp.Acc.Committed, accCommitment: tp.Acc.Commitment,
+			instanceW: tp.Instance.W, instancePublic: tp.Instance.Public, t: ts.FoldingProof.T,
+			instanceCommitted: *tp.Instance.Committed, instanceCommitment: tp.Instance.Commitment,
+		}, step)
+	case *novabackend_bn254.IVCProof:
+		ts, ok := s.(*novabackend_bn254.IVCStep)
+		if !ok {
+			return nil, fmt.Errorf("expected %T, got %T", ts, s)
+		}
+		if tp.Acc.Committed == nil || tp.Instance.Committed == nil {
+			return nil, errAugmentedCommitment
+		}
+		return valueOfAugmentedCircuit[Base, Scalar](nativeAugmentedCircuit[fr_bn254.Element]{
+			steps: tp.Steps, z0: tp.Z0, z: tp.Z, next: ts.Next, digest: ts.Digest,
+			accW: tp.Acc.W, accE: tp.Acc.E, accU: tp.Acc.U, accPublic: tp.Acc.Public,
+			accCommitted: *tp.Acc.Committed, accCommitment: tp.Acc.Commitment,
+			instanceW: tp.Instance.W, instancePublic: tp.Instance.Public, t: ts.FoldingProof.T,
+			instanceCommitted: *tp.Instance.Committed, instanceCommitment: tp.Instance.Commitment,
+		}, step)
+	case *novabackend_bw6761.IVCProof:
+		ts, ok := s.(*novabackend_bw6761.IVCStep)
+		if !ok {
+			return nil, fmt.Errorf("expected %T, got %T", ts, s)
+		}
+		if tp.Acc.Committed == nil || tp.Instance.Committed == nil {
+			return nil, errAugmentedCommitment
+		}
+		return valueOfAugmentedCircuit[Base, Scalar](nativeAugmentedCircuit[fr_bw6761.Element]{
+			steps: tp.Steps, z0: tp.Z0, z: tp.Z, next: ts.Next, digest: ts.Digest,
+			accW: tp.Acc.W, accE: tp.Acc.E, accU: tp.Acc.U, accPublic: tp.Acc.Public,
+			accCommitted: *tp.Acc.Committed, accCommitment: tp.Acc.Commitment,
+			instanceW: tp.Instance.W, instancePublic: tp.Instance.Public, t: ts.FoldingProof.T,
+			instanceCommitted: *tp.Instance.Committed, instanceCommitment: tp.Instance.Commitment,
+		}, step)
+	default:
+		return nil, fmt.Errorf("unknown parametric type combination: %T", p)
+	}
+}
+
+// nativeAugmentedCircuit holds the native values of the assignment of the
+// augmented circuit, the points are of the native curve.
+type nativeAugmentedCircuit[E any] struct {
+	steps                           uint64
+	z0, z, next                     []E
+	digest                          E
+	accW, accE, instanceW, t        any
+	accCommitted, instanceCommitted any
+	accU, accCommitment             E
+	instanceCommitment              E
+	accPublic, instancePublic       []E
+}
+
+func valueOfAugmentedCircuit[Base, Scalar emulated.FieldParams, E any](n nativeAugmentedCircuit[E], step StepCircuit) (*AugmentedCircuit[Base, Scalar], error) {
+	if len(n.accPublic) != 1 || len(n.instancePublic) != 1 {
+		return nil, errors.New("the instances of the augmented circuit have one public input")
+	}
+	ret := AugmentedCircuit[Base, Scalar]{
+		X:    n.digest,
+		I:    n.steps,
+		Z0:   toVariables(n.z0),
+		Z:    toVariables(n.z),
+		Next: toVariables(n.next),
+		Step: step,
+	}
+	ret.Acc.U, ret.Acc.X, ret.Acc.Commitment = n.accU, n.accPublic[0], n.accCommitment
+	ret.Instance.X, ret.Instance.Commitment = n.instancePublic[0], n.instanceCommitment
+	var err error
+	for _, v := range []struct {
+		dst *sw_emulated.AffinePoint[Base]
+		src any
+	}{
+		{&ret.Acc.W, n.accW},
+		{&ret.Acc.E, n.accE},
+		{&ret.Acc.Committed, n.accCommitted},
+		{&ret.Instance.W, n.instanceW},
+		{&ret.Instance.Committed, n.instanceCommitted},
+		{&ret.Proof.T, n.t},
+	} {
+		if *v.dst, err = valueOfG1[sw_emulated.AffinePoint[Base]](v.src); err != nil {
+			return nil, err
+		}
+	}
+	return &ret, nil
+}
+
+func toVariables[E any](v []E) []frontend.Variable {
+	ret := make([]frontend.Variable, len(v))
+	for i := range v {
+		ret[i] = v[i]
+	}
+	return ret
+}
@@ -0,0 +1,153 @@
+package nova
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	fr_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	kzg_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/kzg"
+	"github.com/consensys/gnark/backend/groth16"
+	novabackend "github.com/consensys/gnark/backend/nova/bn254"
+	cs_bn254 "github.com/consensys/gnark/constraint/bn254"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/test"
+)
+
+// ivcStepCircuit is the step zᵢ₊₁ = zᵢ³ + aᵢ, where the advice aᵢ is a secret
+// of the step.
+type ivcStepCircuit struct {
+	A frontend.Variable
+}
+
+func (c *ivcStepCircuit) Step(api frontend.API, z []frontend.Variable) ([]frontend.Variable, error) {
+	return []frontend.Variable{api.Add(api.Mul(z[0], z[0], z[0]), c.A)}, nil
+}
+
+// ivcStep returns z³ + a.
+func ivcStep(z fr_bn254.Element, a uint64) fr_bn254.Element {
+	var res, t fr_bn254.Element
+	t.SetUint64(a)
+	res.Square(&z).Mul(&res, &z).Add(&res, &t)
+	return res
+}
+
+// proveIVC proves len(a) steps of the computation from z0 with the advice a,
+// and checks the proof after each step.
+func proveIVC(assert *test.Assert, ccs *cs_bn254.R1CS, ck *novabackend.CommitmentKey, z0 uint64, a []uint64) *novabackend.IVCProof {
+	proverOpt := GetNativeProverOptions(ecc.BN254.ScalarField(), ecc.BN254.ScalarField())
+	verifierOpt := GetNativeVerifierOptions(ecc.BN254.ScalarField(), ecc.BN254.ScalarField())
+
+	var z fr_bn254.Element
+	z.SetUint64(z0)
+	p := novabackend.NewIVCProof(ccs, fr_bn254.Vector{z})
+	for i := range a {
+		s, err := novabackend.FoldIVC(ccs, ck, p, fr_bn254.Vector{ivcStep(p.Z[0], a[i])}, proverOpt)
+		assert.NoError(err)
+		assignment, err := ValueOfAugmentedCircuit[sw_bn254.BaseField, sw_bn254.ScalarField](p, s, &ivcStepCircuit{A: a[i]})
+		assert.NoError(err)
+		w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+		assert.NoError(err)
+		p, err = novabackend.ProveIVC(ccs, ck, p, s, w, proverOpt)
+		assert.NoError(err)
+		assert.NoError(novabackend.VerifyIVC(ccs, ck, p, verifierOpt))
+	}
+	return p
+}
+
+func TestIVC(t *testing.T) {
+	assert := test.NewAssert(t)
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, PlaceholderAugmentedCircuit[sw_bn254.BaseField, sw_bn254.ScalarField](1, &ivcStepCircuit{}))
+	assert.NoError(err)
+	augmented := ccs.(*cs_bn254.R1CS)
+	ck, err := novabackend.Setup(augmented)
+	assert.NoError(err)
+	a := []uint64{1, 2, 3}
+	p := proveIVC(assert, augmented, ck, 3, a)
+
+	var z fr_bn254.Element
+	z.SetUint64(3)
+	for i := range a {
+		z = ivcStep(z, a[i])
+	}
+	assert.Equal(uint64(len(a)), p.Steps)
+	assert.True(p.Z[0].Equal(&z))
+
+	// the proof doesn't verify for another state or number of steps
+	verifierOpt := GetNativeVerifierOptions(ecc.BN254.ScalarField(), ecc.BN254.ScalarField())
+	tampered := *p
+	tampered.Z = fr_bn254.Vector{ivcStep(z, 1)}
+	assert.Error(novabackend.VerifyIVC(augmented, ck, &tampered, verifierOpt))
+	tampered = *p
+	tampered.Steps++
+	assert.Error(novabackend.VerifyIVC(augmented, ck, &tampered, verifierOpt))
+
+	// a step which doesn't apply the step function can't be proven
+	proverOpt := GetNativeProverOptions(ecc.BN254.ScalarField(), ecc.BN254.ScalarField())
+	s, err := novabackend.FoldIVC(augmented, ck, p, fr_bn254.Vector{ivcStep(z, 1)}, proverOpt)
+	assert.NoError(err)
+	assignment, err := ValueOfAugmentedCircuit[sw_bn254.BaseField, sw_bn254.ScalarField](p, s, &ivcStepCircuit{A: 2})
+	assert.NoError(err)
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	_, err = novabackend.ProveIVC(augmented, ck, p, s, w, proverOpt)
+	assert.Error(err)
+}
+
+func TestIVCDecider(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compression in short mode")
+	}
+	assert := test.NewAssert(t)
+	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, PlaceholderAugmentedCircuit[sw_bn254.BaseField, sw_bn254.ScalarField](1, &ivcStepCircuit{}))
+	assert.NoError(err)
+	augmented := ccs.(*cs_bn254.R1CS)
+	size := augmented.GetNbSecretVariables() + augmented.GetNbInternalVariables()
+	if nbConstraints := augmented.GetNbConstraints(); nbConstraints > size {
+		size = nbConstraints
+	}
+	srs, err := kzg_bn254.NewSRS(uint64(size+1), big.NewInt(42))
+	assert.NoError(err)
+	ck, err := novabackend.SetupKZG(augmented, srs.Pk)
+	assert.NoError(err)
+	p := proveIVC(assert, augmented, ck, 3, []uint64{1, 2})
+
+	// compress the IVC proof
+	proverOpt := GetNativeProverOptions(ecc.BN254.ScalarField(), ecc.BN254.ScalarField())
+	verifierOpt := GetNativeVerifierOptions(ecc.BN254.ScalarField(), ecc.BN254.ScalarField())
+	s, err := novabackend.FoldDecider(augmented, ck, srs.Pk, p, proverOpt)
+	assert.NoError(err)
+	assert.NoError(novabackend.VerifyDecider(srs.Vk, &s.Proof, verifierOpt))
+
+	decider, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, PlaceholderDeciderCircuit[sw_bn254.BaseField, sw_bn254.ScalarField](augmented, 1))
+	assert.NoError(err)
+	assignment, err := ValueOfDeciderCircuit[sw_bn254.BaseField, sw_bn254.ScalarField](p, s)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(decider)
+	assert.NoError(err)
+	proof, err := groth16.Prove(decider, pk, w)
+	assert.NoError(err)
+
+	// the verifier only knows the number of steps, the states and the decider
+	// proof
+	publicAssignment := DeciderCircuit[sw_bn254.BaseField, sw_bn254.ScalarField]{
+		I:  p.Steps,
+		Z0: []frontend.Variable{p.Z0[0]},
+		Z:  []frontend.Variable{p.Z[0]},
+	}
+	publicAssignment.Proof, err = ValueOfDeciderProof[sw_bn254.BaseField](&s.Proof)
+	assert.NoError(err)
+	publicWitness, err := frontend.NewWitness(&publicAssignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+
+	// the proof doesn't verify for another final state
+	publicAssignment.Z = []frontend.Variable{ivcStep(p.Z[0], 1)}
+	publicWitness, err = frontend.NewWitness(&publicAssignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	assert.NoError(err)
+	assert.Error(groth16.Verify(proof, vk, publicWitness))
+}
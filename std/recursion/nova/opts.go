@@ -0,0 +1,59 @@
+package nova
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/std/recursion"
+)
+
+// GetNativeProverOptions returns options for the native folding prover to
+// compute the folding challenges and the commitment wire of the step circuit
+// as the in-circuit verifier.
+func GetNativeProverOptions(outer, field *big.Int) backend.ProverOption {
+	return func(pc *backend.ProverConfig) error {
+		fsProverHasher, err := recursion.NewShort(outer, field)
+		if err != nil {
+			return fmt.Errorf("get prover fs hash: %w", err)
+		}
+		fsOpt := backend.WithProverChallengeHashFunction(fsProverHasher)
+		if err = fsOpt(pc); err != nil {
+			return fmt.Errorf("apply prover fs hash option: %w", err)
+		}
+		htfProverHasher, err := recursion.NewShort(outer, field)
+		if err != nil {
+			return fmt.Errorf("get hash to field: %w", err)
+		}
+		htfOpt := backend.WithProverHashToFieldFunction(htfProverHasher)
+		if err = htfOpt(pc); err != nil {
+			return fmt.Errorf("apply prover htf option: %w", err)
+		}
+		return nil
+	}
+}
+
+// GetNativeVerifierOptions returns options for the native folding verifier to
+// compute the folding challenges and the commitment wire of the step circuit
+// as the in-circuit verifier.
+func GetNativeVerifierOptions(outer, field *big.Int) backend.VerifierOption {
+	return func(vc *backend.VerifierConfig) error {
+		fsVerifierHasher, err := recursion.NewShort(outer, field)
+		if err != nil {
+			return fmt.Errorf("get verifier fs hash: %w", err)
+		}
+		fsOpt := backend.WithVerifierChallengeHashFunction(fsVerifierHasher)
+		if err = fsOpt(vc); err != nil {
+			return fmt.Errorf("apply verifier fs hash option: %w", err)
+		}
+		htfVerifierHasher, err := recursion.NewShort(outer, field)
+		if err != nil {
+			return fmt.Errorf("get hash to field: %w", err)
+		}
+		htfOpt := backend.WithVerifierHashToFieldFunction(htfVerifierHasher)
+		if err = htfOpt(vc); err != nil {
+			return fmt.Errorf("apply verifier htf option: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,498 @@
+package nova
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315"
+	"github.com/consensys/gnark-crypto/ecc/bn254"
+	bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761"
+	novabackend_bls12377 "github.com/consensys/gnark/backend/nova/bls12-377"
+	novabackend_bls12381 "github.com/consensys/gnark/backend/nova/bls12-381"
+	novabackend_bls24315 "github.com/consensys/gnark/backend/nova/bls24-315"
+	novabackend_bn254 "github.com/consensys/gnark/backend/nova/bn254"
+	novabackend_bw6761 "github.com/consensys/gnark/backend/nova/bw6-761"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bn254"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bw6761"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls24315"
+	"github.com/consensys/gnark/std/math/bits"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/std/recursion"
+)
+
+var (
+	errNotR1CS          = errors.New("the step circuit must be compiled with r1cs.NewBuilder")
+	errTooFewInstances  = errors.New("at least two instances are needed for folding")
+	errInvalidStepArity = errors.New("the step circuit must have as public inputs the input and output states")
+	errStepCommitment   = errors.New("the step circuit must not use commitments")
+)
+
+// Instance is a committed instance of the step circuit, with implicit u = 1
+// and E = 0. Use [ValueOfInstance] to initialize the witness from the native
+// instance and [PlaceholderInstance] for compiling the circuit.
+type Instance[FR emulated.FieldParams, G1El algebra.G1ElementT] struct {
+	// W is the commitment to the secret and internal wires
+	W G1El
+
+	// Public are the public inputs of the step circuit
+	Public []emulated.Element[FR]
+}
+
+// FoldingProof is the commitment to the cross term when folding an instance.
+// Use [ValueOfFoldingProof] to initialize the witness from the native proof.
+type FoldingProof[G1El algebra.G1ElementT] struct {
+	T G1El
+}
+
+// RelaxedWitness is the opening of the folded relaxed instance. Use
+// [ValueOfRelaxedWitness] to initialize the witness from the native witness and
+// [PlaceholderRelaxedWitness] for compiling the circuit.
+type RelaxedWitness[FR emulated.FieldParams] struct {
+	W, E                 []emulated.Element[FR]
+	BlindingW, BlindingE emulated.Element[FR]
+}
+
+// CommitmentKey is the key of the Pedersen commitments. It must be a constant
+// of the circuit, that is a field with the `gnark:"-"` tag set before
+// compiling with [ValueOfCommitmentKey].
+type CommitmentKey[G1El algebra.G1ElementT] struct {
+	G []G1El
+	H G1El
+}
+
+// RelaxedInstance is a folded relaxed R1CS instance computed in-circuit.
+type RelaxedInstance[FR emulated.FieldParams, G1El algebra.G1ElementT] struct {
+	// W and E are the commitments to the witness and error vectors. E is nil
+	// before the first folding, when the error vector is zero.
+	W, E *G1El
+
+	U      *emulated.Element[FR]
+	Public []*emulated.Element[FR]
+}
+
+// Verifier is the in-circuit folding verifier and decider.
+type Verifier[FR emulated.FieldParams, G1El algebra.G1ElementT] struct {
+	api       frontend.API
+	scalarApi *emulated.Field[FR]
+	curve     algebra.Curve[FR, G1El]
+}
+
+// NewVerifier returns a new [Verifier] instance.
+func NewVerifier[FR emulated.FieldParams, G1El algebra.G1ElementT](api frontend.API) (*Verifier[FR, G1El], error) {
+	curve, err := algebra.GetCurve[FR, G1El](api)
+	if err != nil {
+		return nil, err
+	}
+	f, err := emulated.NewField[FR](api)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier[FR, G1El]{
+		api:       api,
+		scalarApi: f,
+		curve:     curve,
+	}, nil
+}
+
+// AssertSteps asserts that the instances are consecutive steps of a
+// computation from the state z0 to the state zN, where the public inputs of
+// the step circuit are the input state followed by the output state. The
+// instances are folded with the proofs, and w must open the folded instance to
+// a solution of the relaxed R1CS of the step circuit ccs. The number of steps
+// is the number of instances, which is fixed when compiling the circuit, and
+// the size of the circuit grows linearly with it. For an unbounded number of
+// steps, see [AugmentedCircuit].
+func (v *Verifier[FR, G1El]) AssertSteps(ccs constraint.ConstraintSystem, ck CommitmentKey[G1El], z0, zN []emulated.Element[FR], instances []Instance[FR, G1El], proofs []FoldingProof[G1El], w RelaxedWitness[FR]) error {
+	if len(z0) != len(zN) {
+		return errInvalidStepArity
+	}
+	arity := len(z0)
+	for i := range instances {
+		if len(instances[i].Public) != 2*arity {
+			return errInvalidStepArity
+		}
+		if i > 0 {
+			for j := 0; j < arity; j++ {
+				v.scalarApi.AssertIsEqual(&instances[i-1].Public[arity+j], &instances[i].Public[j])
+			}
+		}
+	}
+	if len(instances) > 0 {
+		for j := 0; j < arity; j++ {
+			v.scalarApi.AssertIsEqual(&instances[0].Public[j], &z0[j])
+			v.scalarApi.AssertIsEqual(&instances[len(instances)-1].Public[arity+j], &zN[j])
+		}
+	}
+
+	folded, err := v.FoldInstances(instances, proofs)
+	if err != nil {
+		return err
+	}
+	return v.AssertIsSatisfied(ccs, ck, folded, w)
+}
+
+// FoldInstances folds the instances in a relaxed instance, starting from the
+// first instance. The i-th proof is the one of the folding of the (i+1)-th
+// instance. The challenges are computed as the native [VerifyFold] with the
+// hash [recursion.NewShort].
+//
+// [VerifyFold]: https://pkg.go.dev/github.com/consensys/gnark/backend/nova/bn254#VerifyFold
+func (v *Verifier[FR, G1El]) FoldInstances(instances []Instance[FR, G1El], proofs []FoldingProof[G1El]) (*RelaxedInstance[FR, G1El], error) {
+	if len(instances) < 2 {
+		return nil, errTooFewInstances
+	}
+	if len(proofs) != len(instances)-1 {
+		return nil, fmt.Errorf("expected %d folding proofs, got %d", len(instances)-1, len(proofs))
+	}
+
+	acc := &RelaxedInstance[FR, G1El]{
+		W:      &instances[0].W,
+		U:      v.scalarApi.One(),
+		Public: make([]*emulated.Element[FR], len(instances[0].Public)),
+	}
+	for i := range acc.Public {
+		acc.Public[i] = &instances[0].Public[i]
+	}
+	for i := 1; i < len(instances); i++ {
+		if len(instances[i].Public) != len(acc.Public) {
+			return nil, fmt.Errorf("instance %d: mismatching number of public inputs", i)
+		}
+		r, err := v.deriveChallenge(acc, &instances[i], &proofs[i-1])
+		if err != nil {
+			return nil, fmt.Errorf("folding %d: %w", i, err)
+		}
+		folded := &RelaxedInstance[FR, G1El]{
+			W:      v.curve.Add(acc.W, v.curve.ScalarMul(&instances[i].W, r)),
+			E:      v.curve.ScalarMul(&proofs[i-1].T, r),
+			U:      v.scalarApi.Add(acc.U, r),
+			Public: make([]*emulated.Element[FR], len(acc.Public)),
+		}
+		if acc.E != nil {
+			folded.E = v.curve.Add(acc.E, folded.E)
+		}
+		for j := range folded.Public {
+			folded.Public[j] = v.scalarApi.Add(acc.Public[j], v.scalarApi.Mul(r, &instances[i].Public[j]))
+		}
+		acc = folded
+	}
+	return acc, nil
+}
+
+// AssertIsSatisfied asserts that w opens the commitments of the relaxed
+// instance to a solution of the relaxed R1CS of the step circuit ccs,
+//
+//	Az∘Bz = u·Cz + E
+//
+// with z = (u, x, W).
+func (v *Verifier[FR, G1El]) AssertIsSatisfied(ccs constraint.ConstraintSystem, ck CommitmentKey[G1El], instance *RelaxedInstance[FR, G1El], w RelaxedWitness[FR]) error {
+	r1cs, ok := ccs.(constraint.R1CS)
+	if !ok {
+		return errNotR1CS
+	}
+	if len(ccs.GetCommitments().CommitmentIndexes()) != 0 {
+		return errStepCommitment
+	}
+	nbWitness := ccs.GetNbSecretVariables() + ccs.GetNbInternalVariables()
+	if len(instance.Public) != ccs.GetNbPublicVariables()-1 || len(w.W) != nbWitness || len(w.E) != ccs.GetNbConstraints() {
+		return fmt.Errorf("mismatching witness size")
+	}
+	if len(ck.G) < nbWitness || len(ck.G) < len(w.E) {
+		return fmt.Errorf("commitment key too small")
+	}
+	if instance.E == nil {
+		return errTooFewInstances
+	}
+
+	// commitments
+	if err := v.assertCommitment(ck, instance.W, w.W, &w.BlindingW); err != nil {
+		return fmt.Errorf("witness commitment: %w", err)
+	}
+	if err := v.assertCommitment(ck, instance.E, w.E, &w.BlindingE); err != nil {
+		return fmt.Errorf("error commitment: %w", err)
+	}
+
+	// relaxed R1CS
+	z := make([]*emulated.Element[FR], 0, 1+len(instance.Public)+len(w.W))
+	z = append(z, instance.U)
+	z = append(z, instance.Public...)
+	for i := range w.W {
+		z = append(z, &w.W[i])
+	}
+	it := r1cs.GetR1CIterator()
+	for j, r1c := 0, it.Next(); r1c != nil; j, r1c = j+1, it.Next() {
+		a := v.evaluateLinearExpression(ccs, r1c.L, z)
+		b := v.evaluateLinearExpression(ccs, r1c.R, z)
+		c := v.evaluateLinearExpression(ccs, r1c.O, z)
+		v.scalarApi.AssertIsEqual(
+			v.scalarApi.Mul(a, b),
+			v.scalarApi.Add(v.scalarApi.Mul(instance.U, c), &w.E[j]),
+		)
+	}
+	return nil
+}
+
+// assertCommitment asserts that commitment = ∑ᵢ vᵢGᵢ + blinding·H.
+func (v *Verifier[FR, G1El]) assertCommitment(ck CommitmentKey[G1El], commitment *G1El, values []emulated.Element[FR], blinding *emulated.Element[FR]) error {
+	points := make([]*G1El, len(values)+1)
+	scalars := make([]*emulated.Element[FR], len(values)+1)
+	for i := range values {
+		points[i] = &ck.G[i]
+		scalars[i] = &values[i]
+	}
+	points[len(values)] = &ck.H
+	scalars[len(values)] = blinding
+	res, err := v.curve.MultiScalarMul(points, scalars, algopts.WithCompleteArithmetic())
+	if err != nil {
+		return err
+	}
+	v.curve.AssertIsEqual(res, commitment)
+	return nil
+}
+
+// evaluateLinearExpression returns the value of l at z.
+func (v *Verifier[FR, G1El]) evaluateLinearExpression(ccs constraint.ConstraintSystem, l constraint.LinearExpression, z []*emulated.Element[FR]) *emulated.Element[FR] {
+	var fr FR
+	minusOne := new(big.Int).Sub(fr.Modulus(), big.NewInt(1))
+	res := v.scalarApi.Zero()
+	for _, t := range l {
+		coeff := ccs.ToBigInt(ccs.GetCoefficient(t.CoeffID()))
+		switch {
+		case coeff.Sign() == 0:
+		case coeff.IsUint64() && coeff.Uint64() == 1:
+			res = v.scalarApi.Add(res, z[t.WireID()])
+		case coeff.Cmp(minusOne) == 0:
+			res = v.scalarApi.Sub(res, z[t.WireID()])
+		default:
+			res = v.scalarApi.Add(res, v.scalarApi.Mul(v.scalarApi.NewElement(coeff), z[t.WireID()]))
+		}
+	}
+	return res
+}
+
+// deriveChallenge returns the folding challenge, binding the data in the same
+// order as the native folding.
+func (v *Verifier[FR, G1El]) deriveChallenge(acc *RelaxedInstance[FR, G1El], instance *Instance[FR, G1El], proof *FoldingProof[G1El]) (*emulated.Element[FR], error) {
+	var fr FR
+	fs, err := recursion.NewTranscript(v.api, fr.Modulus(), []string{"r"})
+	if err != nil {
+		return nil, err
+	}
+
+	toBind := [][]frontend.Variable{v.curve.MarshalG1(*acc.W)}
+	if acc.E != nil {
+		toBind = append(toBind, v.curve.MarshalG1(*acc.E))
+	}
+	toBind = append(toBind, v.curve.MarshalScalar(*acc.U))
+	for i := range acc.Public {
+		toBind = append(toBind, v.curve.MarshalScalar(*acc.Public[i]))
+	}
+	toBind = append(toBind, v.curve.MarshalG1(instance.W))
+	for i := range instance.Public {
+		toBind = append(toBind, v.curve.MarshalScalar(instance.Public[i]))
+	}
+	toBind = append(toBind, v.curve.MarshalG1(proof.T))
+
+	for i := range toBind {
+		if err := fs.Bind("r", toBind[i]); err != nil {
+			return nil, err
+		}
+	}
+	b, err := fs.ComputeChallenge("r")
+	if err != nil {
+		return nil, err
+	}
+	bbits := bits.ToBinary(v.api, b, bits.WithNbDigits(fr.Modulus().BitLen()))
+	return v.scalarApi.FromBits(bbits...), nil
+}
+
+// PlaceholderInstance returns a placeholder instance of the step circuit ccs to
+// be used for compiling the outer circuit. For actual witness assignment use
+// [ValueOfInstance].
+func PlaceholderInstance[FR emulated.FieldParams, G1El algebra.G1ElementT](ccs constraint.ConstraintSystem) Instance[FR, G1El] {
+	return Instance[FR, G1El]{
+		Public: make([]emulated.Element[FR], ccs.GetNbPublicVariables()-1),
+	}
+}
+
+// PlaceholderRelaxedWitness returns a placeholder relaxed witness of the step
+// circuit ccs to be used for compiling the outer circuit. For actual witness
+// assignment use [ValueOfRelaxedWitness].
+func PlaceholderRelaxedWitness[FR emulated.FieldParams](ccs constraint.ConstraintSystem) RelaxedWitness[FR] {
+	return RelaxedWitness[FR]{
+		W: make([]emulated.Element[FR], ccs.GetNbSecretVariables()+ccs.GetNbInternalVariables()),
+		E: make([]emulated.Element[FR], ccs.GetNbConstraints()),
+	}
+}
+
+// ValueOfInstance returns the typed witness of the native instance. It returns
+// an error if there is a mismatch between the type parameters and the provided
+// native instance.
+func ValueOfInstance[FR emulated.FieldParams, G1El algebra.G1ElementT](instance any) (Instance[FR, G1El], error) {
+	var ret Instance[FR, G1El]
+	var err error
+	switch tInstance := instance.(type) {
+	case *novabackend_bls12377.Instance:
+		ret.W, err = valueOfG1[G1El](tInstance.W)
+		ret.Public = valueOfVector[FR](tInstance.Public)
+	case *novabackend_bls12381.Instance:
+		ret.W, err = valueOfG1[G1El](tInstance.W)
+		ret.Public = valueOfVector[FR](tInstance.Public)
+	case *novabackend_bls24315.Instance:
+		ret.W, err = valueOfG1[G1El](tInstance.W)
+		ret.Public = valueOfVector[FR](tInstance.Public)
+	case *novabackend_bn254.Instance:
+		ret.W, err = valueOfG1[G1El](tInstance.W)
+		ret.Public = valueOfVector[FR](tInstance.Public)
+	case *novabackend_bw6761.Instance:
+		ret.W, err = valueOfG1[G1El](tInstance.W)
+		ret.Public = valueOfVector[FR](tInstance.Public)
+	default:
+		return ret, fmt.Errorf("unknown parametric type combination: %T", instance)
+	}
+	return ret, err
+}
+
+// ValueOfFoldingProof returns the typed witness of the native folding proof. It
+// returns an error if there is a mismatch between the type parameters and the
+// provided native proof.
+func ValueOfFoldingProof[G1El algebra.G1ElementT](proof any) (FoldingProof[G1El], error) {
+	var ret FoldingProof[G1El]
+	var err error
+	switch tProof := proof.(type) {
+	case *novabackend_bls12377.FoldingProof:
+		ret.T, err = valueOfG1[G1El](tProof.T)
+	case *novabackend_bls12381.FoldingProof:
+		ret.T, err = valueOfG1[G1El](tProof.T)
+	case *novabackend_bls24315.FoldingProof:
+		ret.T, err = valueOfG1[G1El](tProof.T)
+	case *novabackend_bn254.FoldingProof:
+		ret.T, err = valueOfG1[G1El](tProof.T)
+	case *novabackend_bw6761.FoldingProof:
+		ret.T, err = valueOfG1[G1El](tProof.T)
+	default:
+		return ret, fmt.Errorf("unknown parametric type combination: %T", proof)
+	}
+	return ret, err
+}
+
+// ValueOfRelaxedWitness returns the typed witness of the native relaxed
+// witness. It returns an error if the provided witness is not a native relaxed
+// witness.
+func ValueOfRelaxedWitness[FR emulated.FieldParams](w any) (RelaxedWitness[FR], error) {
+	var ret RelaxedWitness[FR]
+	switch tw := w.(type) {
+	case *novabackend_bls12377.RelaxedWitness:
+		ret.W, ret.E = valueOfVector[FR](tw.W), valueOfVector[FR](tw.E)
+		ret.BlindingW, ret.BlindingE = emulated.ValueOf[FR](tw.BlindingW), emulated.ValueOf[FR](tw.BlindingE)
+	case *novabackend_bls12381.RelaxedWitness:
+		ret.W, ret.E = valueOfVector[FR](tw.W), valueOfVector[FR](tw.E)
+		ret.BlindingW, ret.BlindingE = emulated.ValueOf[FR](tw.BlindingW), emulated.ValueOf[FR](tw.BlindingE)
+	case *novabackend_bls24315.RelaxedWitness:
+		ret.W, ret.E = valueOfVector[FR](tw.W), valueOfVector[FR](tw.E)
+		ret.BlindingW, ret.BlindingE = emulated.ValueOf[FR](tw.BlindingW), emulated.ValueOf[FR](tw.BlindingE)
+	case *novabackend_bn254.RelaxedWitness:
+		ret.W, ret.E = valueOfVector[FR](tw.W), valueOfVector[FR](tw.E)
+		ret.BlindingW, ret.BlindingE = emulated.ValueOf[FR](tw.BlindingW), emulated.ValueOf[FR](tw.BlindingE)
+	case *novabackend_bw6761.RelaxedWitness:
+		ret.W, ret.E = valueOfVector[FR](tw.W), valueOfVector[FR](tw.E)
+		ret.BlindingW, ret.BlindingE = emulated.ValueOf[FR](tw.BlindingW), emulated.ValueOf[FR](tw.BlindingE)
+	default:
+		return ret, fmt.Errorf("unknown parametric type combination: %T", w)
+	}
+	return ret, nil
+}
+
+// ValueOfCommitmentKey returns the constant commitment key of the native key.
+// It returns an error if there is a mismatch between the type parameters and
+// the provided native key.
+func ValueOfCommitmentKey[G1El algebra.G1ElementT](ck any) (CommitmentKey[G1El], error) {
+	var ret CommitmentKey[G1El]
+	var points []any
+	switch tck := ck.(type) {
+	case *novabackend_bls12377.CommitmentKey:
+		points = append(toAny(tck.G), tck.H)
+	case *novabackend_bls12381.CommitmentKey:
+		points = append(toAny(tck.G), tck.H)
+	case *novabackend_bls24315.CommitmentKey:
+		points = append(toAny(tck.G), tck.H)
+	case *novabackend_bn254.CommitmentKey:
+		points = append(toAny(tck.G), tck.H)
+	case *novabackend_bw6761.CommitmentKey:
+		points = append(toAny(tck.G), tck.H)
+	default:
+		return ret, fmt.Errorf("unknown parametric type combination: %T", ck)
+	}
+	ret.G = make([]G1El, len(points)-1)
+	var err error
+	for i := range ret.G {
+		if ret.G[i], err = valueOfG1[G1El](points[i]); err != nil {
+			return ret, err
+		}
+	}
+	ret.H, err = valueOfG1[G1El](points[len(points)-1])
+	return ret, err
+}
+
+// valueOfG1 returns the value of the native point p as G1El.
+func valueOfG1[G1El algebra.G1ElementT](p any) (G1El, error) {
+	var ret G1El
+	var ok bool
+	switch r := any(&ret).(type) {
+	case *sw_bls12377.G1Affine:
+		var tp bls12377.G1Affine
+		if tp, ok = p.(bls12377.G1Affine); ok {
+			*r = sw_bls12377.NewG1Affine(tp)
+		}
+	case *sw_bls12381.G1Affine:
+		var tp bls12381.G1Affine
+		if tp, ok = p.(bls12381.G1Affine); ok {
+			*r = sw_bls12381.NewG1Affine(tp)
+		}
+	case *sw_bls24315.G1Affine:
+		var tp bls24315.G1Affine
+		if tp, ok = p.(bls24315.G1Affine); ok {
+			*r = sw_bls24315.NewG1Affine(tp)
+		}
+	case *sw_bn254.G1Affine:
+		var tp bn254.G1Affine
+		if tp, ok = p.(bn254.G1Affine); ok {
+			*r = sw_bn254.NewG1Affine(tp)
+		}
+	case *sw_bw6761.G1Affine:
+		var tp bw6761.G1Affine
+		if tp, ok = p.(bw6761.G1Affine); ok {
+			*r = sw_bw6761.NewG1Affine(tp)
+		}
+	default:
+		return ret, fmt.Errorf("unknown parametric type: %T", ret)
+	}
+	if !ok {
+		return ret, fmt.Errorf("expected a point of %T, got %T", ret, p)
+	}
+	return ret, nil
+}
+
+func valueOfVector[FR emulated.FieldParams, E any](v []E) []emulated.Element[FR] {
+	ret := make([]emulated.Element[FR], len(v))
+	for i := range v {
+		ret[i] = emulated.ValueOf[FR](v[i])
+	}
+	return ret
+}
+
+func toAny[E any](v []E) []any {
+	ret := make([]any, len(v))
+	for i := range v {
+		ret[i] = v[i]
+	}
+	return ret
+}
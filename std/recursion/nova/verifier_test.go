@@ -0,0 +1,166 @@
+package nova
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	fr_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	"github.com/consensys/gnark/backend/groth16"
+	novabackend "github.com/consensys/gnark/backend/nova/bls12-377"
+	"github.com/consensys/gnark/constraint"
+	cs_bls12377 "github.com/consensys/gnark/constraint/bls12-377"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra"
+	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
+	"github.com/consensys/gnark/std/math/emulated"
+	"github.com/consensys/gnark/test"
+)
+
+// stepCircuit is one step of the computation zᵢ₊₁ = zᵢ³ + zᵢ + 5
+type stepCircuit struct {
+	In  frontend.Variable `gnark:",public"`
+	Out frontend.Variable `gnark:",public"`
+}
+
+func (c *stepCircuit) Define(api frontend.API) error {
+	x3 := api.Mul(c.In, c.In, c.In)
+	api.AssertIsEqual(c.Out, api.Add(x3, c.In, 5))
+	return nil
+}
+
+type outerCircuit[FR emulated.FieldParams, G1El algebra.G1ElementT] struct {
+	Instances []Instance[FR, G1El]
+	Proofs    []FoldingProof[G1El]
+	Witness   RelaxedWitness[FR]
+	Z0, ZN    []emulated.Element[FR] `gnark:",public"`
+
+	Step          constraint.ConstraintSystem `gnark:"-"`
+	CommitmentKey CommitmentKey[G1El]         `gnark:"-"`
+}
+
+func (c *outerCircuit[FR, G1El]) Define(api frontend.API) error {
+	verifier, err := NewVerifier[FR, G1El](api)
+	if err != nil {
+		return fmt.Errorf("new verifier: %w", err)
+	}
+	return verifier.AssertSteps(c.Step, c.CommitmentKey, c.Z0, c.ZN, c.Instances, c.Proofs, c.Witness)
+}
+
+// foldSteps runs nbSteps steps of the computation from z0 and returns the
+// outer circuit and its assignment.
+func foldSteps(assert *test.Assert, z0 uint64, nbSteps int) (circuit, assignment *outerCircuit[sw_bls12377.ScalarField, sw_bls12377.G1Affine]) {
+	ccs, err := frontend.Compile(ecc.BLS12_377.ScalarField(), r1cs.NewBuilder, &stepCircuit{})
+	assert.NoError(err)
+	step := ccs.(*cs_bls12377.R1CS)
+	ck, err := novabackend.Setup(step)
+	assert.NoError(err)
+	constCk, err := ValueOfCommitmentKey[sw_bls12377.G1Affine](ck)
+	assert.NoError(err)
+
+	circuit = &outerCircuit[sw_bls12377.ScalarField, sw_bls12377.G1Affine]{
+		Instances:     make([]Instance[sw_bls12377.ScalarField, sw_bls12377.G1Affine], nbSteps),
+		Proofs:        make([]FoldingProof[sw_bls12377.G1Affine], nbSteps-1),
+		Witness:       PlaceholderRelaxedWitness[sw_bls12377.ScalarField](ccs),
+		Z0:            make([]emulated.Element[sw_bls12377.ScalarField], 1),
+		ZN:            make([]emulated.Element[sw_bls12377.ScalarField], 1),
+		Step:          ccs,
+		CommitmentKey: constCk,
+	}
+	for i := range circuit.Instances {
+		circuit.Instances[i] = PlaceholderInstance[sw_bls12377.ScalarField, sw_bls12377.G1Affine](ccs)
+	}
+	assignment = &outerCircuit[sw_bls12377.ScalarField, sw_bls12377.G1Affine]{
+		Instances: make([]Instance[sw_bls12377.ScalarField, sw_bls12377.G1Affine], nbSteps),
+		Proofs:    make([]FoldingProof[sw_bls12377.G1Affine], nbSteps-1),
+	}
+
+	var z, five fr_bls12377.Element
+	z.SetUint64(z0)
+	five.SetUint64(5)
+	assignment.Z0 = []emulated.Element[sw_bls12377.ScalarField]{emulated.ValueOf[sw_bls12377.ScalarField](z)}
+	proverOpt := GetNativeProverOptions(ecc.BW6_761.ScalarField(), ecc.BLS12_377.ScalarField())
+	verifierOpt := GetNativeVerifierOptions(ecc.BW6_761.ScalarField(), ecc.BLS12_377.ScalarField())
+
+	var acc *novabackend.RelaxedInstance
+	var accWitness *novabackend.RelaxedWitness
+	for i := 0; i < nbSteps; i++ {
+		var out fr_bls12377.Element
+		out.Square(&z).Mul(&out, &z).Add(&out, &z).Add(&out, &five)
+		w, err := frontend.NewWitness(&stepCircuit{In: z, Out: out}, ecc.BLS12_377.ScalarField())
+		assert.NoError(err)
+		instance, instanceWitness, err := novabackend.NewInstance(step, ck, w)
+		assert.NoError(err)
+		assignment.Instances[i], err = ValueOfInstance[sw_bls12377.ScalarField, sw_bls12377.G1Affine](instance)
+		assert.NoError(err)
+		z = out
+
+		if i == 0 {
+			acc, accWitness = novabackend.Relax(step, instance, instanceWitness)
+			continue
+		}
+		folded, foldedWitness, proof, err := novabackend.Fold(step, ck, acc, accWitness, instance, instanceWitness, proverOpt)
+		assert.NoError(err)
+		verified, err := novabackend.VerifyFold(acc, instance, proof, verifierOpt)
+		assert.NoError(err)
+		assert.Equal(folded, verified)
+		assignment.Proofs[i-1], err = ValueOfFoldingProof[sw_bls12377.G1Affine](proof)
+		assert.NoError(err)
+		acc, accWitness = folded, foldedWitness
+	}
+	assert.NoError(novabackend.IsSatisfied(step, ck, acc, accWitness))
+	assignment.ZN = []emulated.Element[sw_bls12377.ScalarField]{emulated.ValueOf[sw_bls12377.ScalarField](z)}
+	assignment.Witness, err = ValueOfRelaxedWitness[sw_bls12377.ScalarField](accWitness)
+	assert.NoError(err)
+	return circuit, assignment
+}
+
+func TestBLS12InBW6(t *testing.T) {
+	assert := test.NewAssert(t)
+	circuit, assignment := foldSteps(assert, 3, 3)
+	err := test.IsSolved(circuit, assignment, ecc.BW6_761.ScalarField())
+	assert.NoError(err)
+
+	// wrong final state
+	assignment.ZN = assignment.Z0
+	err = test.IsSolved(circuit, assignment, ecc.BW6_761.ScalarField())
+	assert.Error(err)
+}
+
+func TestBLS12InBW6InvalidFolding(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	// swapping the folding proofs changes the challenges
+	circuit, assignment := foldSteps(assert, 3, 3)
+	assignment.Proofs[0], assignment.Proofs[1] = assignment.Proofs[1], assignment.Proofs[0]
+	err := test.IsSolved(circuit, assignment, ecc.BW6_761.ScalarField())
+	assert.Error(err)
+
+	// instances which are not consecutive steps
+	circuit, assignment = foldSteps(assert, 3, 3)
+	_, other := foldSteps(assert, 4, 3)
+	assignment.Instances[1] = other.Instances[1]
+	err = test.IsSolved(circuit, assignment, ecc.BW6_761.ScalarField())
+	assert.Error(err)
+}
+
+func TestBLS12InBW6Groth16(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compression in short mode")
+	}
+	assert := test.NewAssert(t)
+	circuit, assignment := foldSteps(assert, 3, 3)
+
+	ccs, err := frontend.Compile(ecc.BW6_761.ScalarField(), r1cs.NewBuilder, circuit)
+	assert.NoError(err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(err)
+	w, err := frontend.NewWitness(assignment, ecc.BW6_761.ScalarField())
+	assert.NoError(err)
+	proof, err := groth16.Prove(ccs, pk, w)
+	assert.NoError(err)
+	publicWitness, err := w.Public()
+	assert.NoError(err)
+	assert.NoError(groth16.Verify(proof, vk, publicWitness))
+}
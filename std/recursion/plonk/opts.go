@@ -73,8 +73,46 @@ func GetNativeVerifierOptions(outer, field *big.Int) backend.VerifierOption {
 	}
 }
 
+// GetNativeDuplexProverOptions returns PLONK prover options for the native
+// prover to initialize the configuration suitable for in-circuit verification
+// with the [WithDuplexTranscript] option. The challenges are computed with the
+// duplex-sponge transcript returned by [recursion.NewNativeDuplexTranscript].
+func GetNativeDuplexProverOptions(outer, field *big.Int) backend.ProverOption {
+	return func(pc *backend.ProverConfig) error {
+		if err := GetNativeProverOptions(outer, field)(pc); err != nil {
+			return err
+		}
+		fsOpt := backend.WithProverChallengeTranscript(func(challengesID ...string) (backend.Transcript, error) {
+			return recursion.NewNativeDuplexTranscript(outer, field, challengesID...)
+		})
+		if err := fsOpt(pc); err != nil {
+			return fmt.Errorf("apply prover fs transcript option: %w", err)
+		}
+		return nil
+	}
+}
+
+// GetNativeDuplexVerifierOptions returns PLONK verifier options to initialize
+// the configuration to be compatible with in-circuit verification with the
+// [WithDuplexTranscript] option.
+func GetNativeDuplexVerifierOptions(outer, field *big.Int) backend.VerifierOption {
+	return func(vc *backend.VerifierConfig) error {
+		if err := GetNativeVerifierOptions(outer, field)(vc); err != nil {
+			return err
+		}
+		fsOpt := backend.WithVerifierChallengeTranscript(func(challengesID ...string) (backend.Transcript, error) {
+			return recursion.NewNativeDuplexTranscript(outer, field, challengesID...)
+		})
+		if err := fsOpt(vc); err != nil {
+			return fmt.Errorf("apply verifier fs transcript option: %w", err)
+		}
+		return nil
+	}
+}
+
 type verifierCfg struct {
 	withCompleteArithmetic bool
+	withDuplexTranscript   bool
 }
 
 // VerifierOption allows to modify the behaviour of PLONK verifier.
@@ -94,6 +132,19 @@ func WithCompleteArithmetic() VerifierOption {
 	}
 }
 
+// WithDuplexTranscript computes the challenges with the duplex-sponge
+// transcript returned by [recursion.NewDuplexTranscript] instead of the MiMC
+// based transcript. The proofs must be computed with the options returned by
+// [GetNativeDuplexProverOptions].
+//
+// The duplex-sponge transcript uses fewer constraints than the default one.
+func WithDuplexTranscript() VerifierOption {
+	return func(cfg *verifierCfg) error {
+		cfg.withDuplexTranscript = true
+		return nil
+	}
+}
+
 func newCfg(opts ...VerifierOption) (*verifierCfg, error) {
 	cfg := new(verifierCfg)
 	for i := range opts {
@@ -1,7 +1,9 @@
 package plonk
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 
 	fr_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
 	fr_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
@@ -25,13 +27,16 @@ import (
 	"github.com/consensys/gnark/std/algebra/native/sw_bls12377"
 	"github.com/consensys/gnark/std/algebra/native/sw_bls24315"
 	"github.com/consensys/gnark/std/commitments/kzg"
-	fiatshamir "github.com/consensys/gnark/std/fiat-shamir"
 	"github.com/consensys/gnark/std/math/bits"
 	"github.com/consensys/gnark/std/math/emulated"
 	"github.com/consensys/gnark/std/recursion"
 	"github.com/consensys/gnark/std/selector"
 )
 
+// errLookupsUnsupported is returned when assigning the verification key of a
+// circuit with fixed tables, as the lookup argument isn't verified in-circuit.
+var errLookupsUnsupported = errors.New("recursive verification of circuits with fixed tables is not supported")
+
 // Proof is a typed PLONK proof of SNARK. Use [ValueOfProof] to initialize the
 // witness from the native proof. Use [PlaceholderProof] to initialize the
 // placeholder witness for compiling the circuit.
@@ -53,6 +58,10 @@ type Proof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2Elem
 
 	// Opening proof of Z at zeta*mu
 	ZShiftedOpening kzg.OpeningProof[FR, G1El]
+
+	// Batch opening proof of l, r, o at zeta*mu, only set if a custom gate
+	// spans two rows
+	LROShiftedOpening kzg.BatchOpeningProof[FR, G1El]
 }
 
 // ValueOfProof returns the typed witness of the native proof. It returns an
@@ -99,6 +108,10 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if err != nil {
 			return ret, fmt.Errorf("z shifted opening proof value assignment: %w", err)
 		}
+		r.LROShiftedOpening, err = kzg.ValueOfBatchOpeningProof[sw_bls12377.ScalarField, sw_bls12377.G1Affine](tProof.LROShiftedOpening)
+		if err != nil {
+			return ret, fmt.Errorf("lro shifted opening proof value assignment: %w", err)
+		}
 	case *Proof[sw_bls12381.ScalarField, sw_bls12381.G1Affine, sw_bls12381.G2Affine]:
 		tProof, ok := proof.(*plonkbackend_bls12381.Proof)
 		if !ok {
@@ -136,6 +149,10 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if err != nil {
 			return ret, fmt.Errorf("z shifted opening proof value assignment: %w", err)
 		}
+		r.LROShiftedOpening, err = kzg.ValueOfBatchOpeningProof[sw_bls12381.ScalarField, sw_bls12381.G1Affine](tProof.LROShiftedOpening)
+		if err != nil {
+			return ret, fmt.Errorf("lro shifted opening proof value assignment: %w", err)
+		}
 	case *Proof[sw_bls24315.ScalarField, sw_bls24315.G1Affine, sw_bls24315.G2Affine]:
 		tProof, ok := proof.(*plonkbackend_bls24315.Proof)
 		if !ok {
@@ -173,6 +190,10 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if err != nil {
 			return ret, fmt.Errorf("z shifted opening proof value assignment: %w", err)
 		}
+		r.LROShiftedOpening, err = kzg.ValueOfBatchOpeningProof[sw_bls24315.ScalarField, sw_bls24315.G1Affine](tProof.LROShiftedOpening)
+		if err != nil {
+			return ret, fmt.Errorf("lro shifted opening proof value assignment: %w", err)
+		}
 
 	case *Proof[sw_bw6761.ScalarField, sw_bw6761.G1Affine, sw_bw6761.G2Affine]:
 		tProof, ok := proof.(*plonkbackend_bw6761.Proof)
@@ -211,6 +232,10 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if err != nil {
 			return ret, fmt.Errorf("z shifted opening proof value assignment: %w", err)
 		}
+		r.LROShiftedOpening, err = kzg.ValueOfBatchOpeningProof[sw_bw6761.ScalarField, sw_bw6761.G1Affine](tProof.LROShiftedOpening)
+		if err != nil {
+			return ret, fmt.Errorf("lro shifted opening proof value assignment: %w", err)
+		}
 	case *Proof[sw_bn254.ScalarField, sw_bn254.G1Affine, sw_bn254.G2Affine]:
 		tProof, ok := proof.(*plonkbackend_bn254.Proof)
 		if !ok {
@@ -248,6 +273,10 @@ func ValueOfProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra
 		if err != nil {
 			return ret, fmt.Errorf("z shifted opening proof value assignment: %w", err)
 		}
+		r.LROShiftedOpening, err = kzg.ValueOfBatchOpeningProof[sw_bn254.ScalarField, sw_bn254.G1Affine](tProof.LROShiftedOpening)
+		if err != nil {
+			return ret, fmt.Errorf("lro shifted opening proof value assignment: %w", err)
+		}
 		// TODO: missing bls12-381, bls24315, bn254, bls24317
 	default:
 		return ret, fmt.Errorf("unknown parametric type combination: %T", ret)
@@ -266,6 +295,12 @@ func PlaceholderProof[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El alg
 		},
 		Bsb22Commitments: make([]kzg.Commitment[G1El], nbCommitments),
 	}
+	for _, gate := range customGates(ccs) {
+		if gate.SpansTwoRows() {
+			ret.LROShiftedOpening.ClaimedValues = make([]emulated.Element[FR], 3)
+			break
+		}
+	}
 	return ret
 }
 
@@ -302,6 +337,14 @@ type CircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT] struc
 	Qcp []kzg.Commitment[G1El]
 
 	CommitmentConstraintIndexes []frontend.Variable
+
+	// Qg commitments to the selectors of the custom gates
+	Qg []kzg.Commitment[G1El]
+
+	// CustomGates are the custom gates of the circuit. They are not part of
+	// the witness, circuits sharing a [BaseVerifyingKey] must use the same
+	// custom gates.
+	CustomGates []constraint.CustomGate `gnark:"-"`
 }
 
 // VerifyingKey is a typed PLONK verification key. Use [ValueOfVerifyingKey] or
@@ -390,6 +433,9 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		if !ok {
 			return ret, fmt.Errorf("expected bls12377.VerifyingKey, got %T", vk)
 		}
+		if tVk.NbFixedTables != 0 {
+			return ret, errLookupsUnsupported
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bls12377.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bls12377.NewScalar(tVk.Generator)
@@ -430,11 +476,22 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		for i := range r.CommitmentConstraintIndexes {
 			r.CommitmentConstraintIndexes[i] = tVk.CommitmentConstraintIndexes[i]
 		}
+		r.Qg = make([]kzg.Commitment[sw_bls12377.G1Affine], len(tVk.Qg))
+		for i := range r.Qg {
+			r.Qg[i], err = kzg.ValueOfCommitment[sw_bls12377.G1Affine](tVk.Qg[i])
+			if err != nil {
+				return ret, fmt.Errorf("commitment Qg[%d] witness assignment: %w", i, err)
+			}
+		}
+		r.CustomGates = tVk.CustomGates
 	case *CircuitVerifyingKey[sw_bls12381.ScalarField, sw_bls12381.G1Affine]:
 		tVk, ok := vk.(*plonkbackend_bls12381.VerifyingKey)
 		if !ok {
 			return ret, fmt.Errorf("expected bls12381.VerifyingKey, got %T", vk)
 		}
+		if tVk.NbFixedTables != 0 {
+			return ret, errLookupsUnsupported
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bls12381.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bls12381.NewScalar(tVk.Generator)
@@ -475,11 +532,22 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		for i := range r.CommitmentConstraintIndexes {
 			r.CommitmentConstraintIndexes[i] = tVk.CommitmentConstraintIndexes[i]
 		}
+		r.Qg = make([]kzg.Commitment[sw_bls12381.G1Affine], len(tVk.Qg))
+		for i := range r.Qg {
+			r.Qg[i], err = kzg.ValueOfCommitment[sw_bls12381.G1Affine](tVk.Qg[i])
+			if err != nil {
+				return ret, fmt.Errorf("commitment Qg[%d] witness assignment: %w", i, err)
+			}
+		}
+		r.CustomGates = tVk.CustomGates
 	case *CircuitVerifyingKey[sw_bls24315.ScalarField, sw_bls24315.G1Affine]:
 		tVk, ok := vk.(*plonkbackend_bls24315.VerifyingKey)
 		if !ok {
 			return ret, fmt.Errorf("expected bls24315.VerifyingKey, got %T", vk)
 		}
+		if tVk.NbFixedTables != 0 {
+			return ret, errLookupsUnsupported
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bls24315.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bls24315.NewScalar(tVk.Generator)
@@ -520,11 +588,22 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		for i := range r.CommitmentConstraintIndexes {
 			r.CommitmentConstraintIndexes[i] = tVk.CommitmentConstraintIndexes[i]
 		}
+		r.Qg = make([]kzg.Commitment[sw_bls24315.G1Affine], len(tVk.Qg))
+		for i := range r.Qg {
+			r.Qg[i], err = kzg.ValueOfCommitment[sw_bls24315.G1Affine](tVk.Qg[i])
+			if err != nil {
+				return ret, fmt.Errorf("commitment Qg[%d] witness assignment: %w", i, err)
+			}
+		}
+		r.CustomGates = tVk.CustomGates
 	case *CircuitVerifyingKey[sw_bw6761.ScalarField, sw_bw6761.G1Affine]:
 		tVk, ok := vk.(*plonkbackend_bw6761.VerifyingKey)
 		if !ok {
 			return ret, fmt.Errorf("expected bls12377.VerifyingKey, got %T", vk)
 		}
+		if tVk.NbFixedTables != 0 {
+			return ret, errLookupsUnsupported
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bw6761.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bw6761.NewScalar(tVk.Generator)
@@ -565,11 +644,22 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		for i := range r.CommitmentConstraintIndexes {
 			r.CommitmentConstraintIndexes[i] = tVk.CommitmentConstraintIndexes[i]
 		}
+		r.Qg = make([]kzg.Commitment[sw_bw6761.G1Affine], len(tVk.Qg))
+		for i := range r.Qg {
+			r.Qg[i], err = kzg.ValueOfCommitment[sw_bw6761.G1Affine](tVk.Qg[i])
+			if err != nil {
+				return ret, fmt.Errorf("commitment Qg[%d] witness assignment: %w", i, err)
+			}
+		}
+		r.CustomGates = tVk.CustomGates
 	case *CircuitVerifyingKey[sw_bn254.ScalarField, sw_bn254.G1Affine]:
 		tVk, ok := vk.(*plonkbackend_bn254.VerifyingKey)
 		if !ok {
 			return ret, fmt.Errorf("expected bn254.VerifyingKey, got %T", vk)
 		}
+		if tVk.NbFixedTables != 0 {
+			return ret, errLookupsUnsupported
+		}
 		r.Size = tVk.Size
 		r.SizeInv = sw_bn254.NewScalar(tVk.SizeInv)
 		r.Generator = sw_bn254.NewScalar(tVk.Generator)
@@ -610,6 +700,14 @@ func ValueOfCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT
 		for i := range r.CommitmentConstraintIndexes {
 			r.CommitmentConstraintIndexes[i] = tVk.CommitmentConstraintIndexes[i]
 		}
+		r.Qg = make([]kzg.Commitment[sw_bn254.G1Affine], len(tVk.Qg))
+		for i := range r.Qg {
+			r.Qg[i], err = kzg.ValueOfCommitment[sw_bn254.G1Affine](tVk.Qg[i])
+			if err != nil {
+				return ret, fmt.Errorf("commitment Qg[%d] witness assignment: %w", i, err)
+			}
+		}
+		r.CustomGates = tVk.CustomGates
 	default:
 		return ret, fmt.Errorf("unknown parametric type combination")
 	}
@@ -650,12 +748,24 @@ func PlaceholderBaseVerifyingKey[FR emulated.FieldParams, G1El algebra.G1Element
 // the verification key with same [BaseVerifyingKey].
 func PlaceholderCircuitVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT](ccs constraint.ConstraintSystem) CircuitVerifyingKey[FR, G1El] {
 	commitmentIndexes := ccs.GetCommitments().CommitmentIndexes()
+	gates := customGates(ccs)
 	return CircuitVerifyingKey[FR, G1El]{
 		CommitmentConstraintIndexes: make([]frontend.Variable, len(commitmentIndexes)),
 		Qcp:                         make([]kzg.Commitment[G1El], len(commitmentIndexes)),
+		Qg:                          make([]kzg.Commitment[G1El], len(gates)),
+		CustomGates:                 gates,
 	}
 }
 
+// customGates returns the custom gates of ccs, if it is a PLONK constraint
+// system.
+func customGates(ccs constraint.ConstraintSystem) []constraint.CustomGate {
+	if spr, ok := ccs.(constraint.SparseR1CS); ok {
+		return spr.GetCustomGates()
+	}
+	return nil
+}
+
 // PlaceholderVerifyingKey returns placeholder of the verification key for
 // compiling the outer circuit.
 func PlaceholderVerifyingKey[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT](ccs constraint.ConstraintSystem) VerifyingKey[FR, G1El, G2El] {
@@ -739,6 +849,14 @@ func PlaceholderWitness[FR emulated.FieldParams](ccs constraint.ConstraintSystem
 	}
 }
 
+// transcript is the Fiat-Shamir transcript of the verifier, either the
+// transcript returned by [recursion.NewTranscript] or the one returned by
+// [recursion.NewDuplexTranscript].
+type transcript interface {
+	Bind(challengeID string, values []frontend.Variable) error
+	ComputeChallenge(challengeID string) (frontend.Variable, error)
+}
+
 // Verifier verifies PLONK proofs.
 type Verifier[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT] struct {
 	api       frontend.API
@@ -787,8 +905,26 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) PrepareVerification(vk VerifyingKey[FR,
 	if len(proof.Bsb22Commitments) != len(vk.Qcp) {
 		return nil, nil, nil, fmt.Errorf("BSB22 commitment number mismatch")
 	}
+	if len(vk.Qg) != len(vk.CustomGates) {
+		return nil, nil, nil, fmt.Errorf("custom gate number mismatch")
+	}
+	nbShiftedLRO := 0
+	for _, gate := range vk.CustomGates {
+		if gate.SpansTwoRows() {
+			nbShiftedLRO = 3
+			break
+		}
+	}
+	if len(proof.LROShiftedOpening.ClaimedValues) != nbShiftedLRO {
+		return nil, nil, nil, fmt.Errorf("shifted l, r, o opening mismatch")
+	}
 
-	fs, err := recursion.NewTranscript(v.api, fr.Modulus(), []string{"gamma", "beta", "alpha", "zeta"})
+	var fs transcript
+	if cfg.withDuplexTranscript {
+		fs, err = recursion.NewDuplexTranscript(v.api, fr.Modulus(), []string{"gamma", "beta", "alpha", "zeta"})
+	} else {
+		fs, err = recursion.NewTranscript(v.api, fr.Modulus(), []string{"gamma", "beta", "alpha", "zeta"})
+	}
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("init new transcript: %w", err)
 	}
@@ -996,6 +1132,9 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) PrepareVerification(vk VerifyingKey[FR,
 		&vk.Ql.G1El, &vk.Qr.G1El, &vk.Qm.G1El, &vk.Qo.G1El, // first part
 		&vk.S[2].G1El, &proof.Z.G1El, // second & third part
 	)
+	for i := range vk.Qg {
+		points = append(points, &vk.Qg[i].G1El) // custom gates
+	}
 
 	qC := make([]*emulated.Element[FR], len(proof.Bsb22Commitments))
 	for i := range proof.BatchedProof.ClaimedValues[7:] {
@@ -1006,6 +1145,11 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) PrepareVerification(vk VerifyingKey[FR,
 		&l, &r, rl, &o, // first part
 		_s1, _s2, // second & third part
 	)
+	wires := []*emulated.Element[FR]{&l, &r, &o}
+	for i := range proof.LROShiftedOpening.ClaimedValues {
+		wires = append(wires, &proof.LROShiftedOpening.ClaimedValues[i])
+	}
+	scalars = append(scalars, v.evaluateCustomGates(vk.CustomGates, wires, alpha)...) // custom gates
 
 	var msmOpts []algopts.AlgebraOption
 	if cfg.withCompleteArithmetic {
@@ -1036,7 +1180,7 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) PrepareVerification(vk VerifyingKey[FR,
 		digestsToFold,
 		proof.BatchedProof,
 		*zeta,
-		zu,
+		append([]emulated.Element[FR]{zu}, proof.LROShiftedOpening.ClaimedValues...)...,
 	)
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("fold kzg proof: %w", err)
@@ -1046,6 +1190,20 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) PrepareVerification(vk VerifyingKey[FR,
 	resCommitments := []kzg.Commitment[G1El]{foldedDigest, proof.Z}
 	resProofs := []kzg.OpeningProof[FR, G1El]{foldedProof, proof.ZShiftedOpening}
 	resPoints := []emulated.Element[FR]{*zeta, *shiftedZeta}
+	if nbShiftedLRO != 0 {
+		// fold the opening of l, r, o at ωζ
+		foldedShiftedProof, foldedShiftedDigest, err := v.kzg.FoldProof(
+			proof.LRO[:],
+			proof.LROShiftedOpening,
+			*shiftedZeta,
+		)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("fold shifted kzg proof: %w", err)
+		}
+		resCommitments = append(resCommitments, foldedShiftedDigest)
+		resProofs = append(resProofs, foldedShiftedProof)
+		resPoints = append(resPoints, *shiftedZeta)
+	}
 
 	return resCommitments, resProofs, resPoints, nil
 }
@@ -1130,7 +1288,7 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) AssertDifferentProofs(bvk BaseVerifying
 	return nil
 }
 
-func (v *Verifier[FR, G1El, G2El, GtEl]) bindPublicData(fs *fiatshamir.Transcript, challenge string, vk VerifyingKey[FR, G1El, G2El], witness Witness[FR]) error {
+func (v *Verifier[FR, G1El, G2El, GtEl]) bindPublicData(fs transcript, challenge string, vk VerifyingKey[FR, G1El, G2El], witness Witness[FR]) error {
 
 	// permutation
 	if err := fs.Bind(challenge, v.curve.MarshalG1(vk.S[0].G1El)); err != nil {
@@ -1164,6 +1322,11 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) bindPublicData(fs *fiatshamir.Transcrip
 			return err
 		}
 	}
+	for i := range vk.Qg {
+		if err := fs.Bind(challenge, v.curve.MarshalG1(vk.Qg[i].G1El)); err != nil {
+			return err
+		}
+	}
 
 	// public inputs
 	for i := 0; i < len(witness.Public); i++ {
@@ -1175,7 +1338,7 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) bindPublicData(fs *fiatshamir.Transcrip
 	return nil
 }
 
-func (v *Verifier[FR, G1El, G2El, GtEl]) deriveRandomness(fs *fiatshamir.Transcript, challenge string, points ...G1El) (*emulated.Element[FR], error) {
+func (v *Verifier[FR, G1El, G2El, GtEl]) deriveRandomness(fs transcript, challenge string, points ...G1El) (*emulated.Element[FR], error) {
 	var fr FR
 	for i := range points {
 		if err := fs.Bind(challenge, v.curve.MarshalG1(points[i])); err != nil {
@@ -1191,6 +1354,30 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) deriveRandomness(fs *fiatshamir.Transcr
 	return ret, nil
 }
 
+// evaluateCustomGates returns the evaluations of the custom gates on wires, the
+// j-th identity (counted over all the gates) being scaled by α³⁺ʲ. wires are
+// indexed by constraint.GateWire.
+func (v *Verifier[FR, G1El, G2El, GtEl]) evaluateCustomGates(gates []constraint.CustomGate, wires []*emulated.Element[FR], alpha *emulated.Element[FR]) []*emulated.Element[FR] {
+	alphaPower := v.scalarApi.Mul(alpha, alpha)
+	res := make([]*emulated.Element[FR], len(gates))
+	for i := range gates {
+		res[i] = v.scalarApi.Zero()
+		for _, identity := range gates[i].Identities {
+			alphaPower = v.scalarApi.Mul(alphaPower, alpha)
+			acc := v.scalarApi.Zero()
+			for _, t := range identity {
+				m := v.scalarApi.NewElement(t.Coeff)
+				for _, w := range t.Wires {
+					m = v.scalarApi.Mul(m, wires[w])
+				}
+				acc = v.scalarApi.Add(acc, m)
+			}
+			res[i] = v.scalarApi.Add(res[i], v.scalarApi.Mul(acc, alphaPower))
+		}
+	}
+	return res
+}
+
 func (v *Verifier[FR, G1El, G2El, GtEl]) fixedExpN(n frontend.Variable, s *emulated.Element[FR]) *emulated.Element[FR] {
 	// assume circuit of maximum size 2**30.
 	const maxExpBits = 30
@@ -1254,6 +1441,12 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) SwitchVerificationKey(bvk BaseVerifying
 		if len(cvks[i].Qcp) != nbCmts {
 			return ret, fmt.Errorf("inconsistent circuit verification key")
 		}
+		if !reflect.DeepEqual(cvks[i].CustomGates, cvks[0].CustomGates) {
+			return ret, fmt.Errorf("mismatching custom gates")
+		}
+		if len(cvks[i].Qg) != len(cvks[0].CustomGates) {
+			return ret, fmt.Errorf("inconsistent circuit verification key")
+		}
 	}
 	sizeEls := make([]frontend.Variable, nbIns)
 	sizeInvEls := make([]*emulated.Element[FR], nbIns)
@@ -1273,6 +1466,10 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) SwitchVerificationKey(bvk BaseVerifying
 		QcpEls[i] = make([]*G1El, nbIns)
 		cmtIndicesEls[i] = make([]frontend.Variable, nbIns)
 	}
+	QgEls := make([][]*G1El, len(cvks[0].Qg))
+	for i := range QgEls {
+		QgEls[i] = make([]*G1El, nbIns)
+	}
 	for i := range cvks {
 		sizeEls[i] = cvks[i].Size
 		sizeInvEls[i] = &cvks[i].SizeInv
@@ -1289,6 +1486,9 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) SwitchVerificationKey(bvk BaseVerifying
 			QcpEls[j][i] = &cvks[i].Qcp[j].G1El
 			cmtIndicesEls[j][i] = cvks[i].CommitmentConstraintIndexes[j]
 		}
+		for j := range QgEls {
+			QgEls[j][i] = &cvks[i].Qg[j].G1El
+		}
 	}
 	cvk := CircuitVerifyingKey[FR, G1El]{
 		Size:      selector.Mux(v.api, idx, sizeEls...),
@@ -1306,11 +1506,16 @@ func (v *Verifier[FR, G1El, G2El, GtEl]) SwitchVerificationKey(bvk BaseVerifying
 		Qk:                          kzg.Commitment[G1El]{G1El: *v.curve.Mux(idx, QkEls...)},
 		Qcp:                         make([]kzg.Commitment[G1El], nbCmts),
 		CommitmentConstraintIndexes: make([]frontend.Variable, nbCmts),
+		Qg:                          make([]kzg.Commitment[G1El], len(QgEls)),
+		CustomGates:                 cvks[0].CustomGates,
 	}
 	for i := range QcpEls {
 		cvk.Qcp[i] = kzg.Commitment[G1El]{G1El: *v.curve.Mux(idx, QcpEls[i]...)}
 		cvk.CommitmentConstraintIndexes[i] = selector.Mux(v.api, idx, cmtIndicesEls[i]...)
 	}
+	for i := range QgEls {
+		cvk.Qg[i] = kzg.Commitment[G1El]{G1El: *v.curve.Mux(idx, QgEls[i]...)}
+	}
 	return VerifyingKey[FR, G1El, G2El]{
 		BaseVerifyingKey:    bvk,
 		CircuitVerifyingKey: cvk,
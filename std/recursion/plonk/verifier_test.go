@@ -159,6 +159,162 @@ func TestBLS12381InBN254WoCommit(t *testing.T) {
 	assert.NoError(err)
 }
 
+//-----------------------------------------------------------------
+// With the duplex-sponge transcript
+
+type OuterCircuitDuplex[FR emulated.FieldParams, G1El algebra.G1ElementT, G2El algebra.G2ElementT, GtEl algebra.GtElementT] struct {
+	Proof        Proof[FR, G1El, G2El]
+	VerifyingKey VerifyingKey[FR, G1El, G2El] `gnark:"-"`
+	InnerWitness Witness[FR]                  `gnark:",public"`
+}
+
+func (c *OuterCircuitDuplex[FR, G1El, G2El, GtEl]) Define(api frontend.API) error {
+	verifier, err := NewVerifier[FR, G1El, G2El, GtEl](api)
+	if err != nil {
+		return fmt.Errorf("new verifier: %w", err)
+	}
+	err = verifier.AssertProof(c.VerifyingKey, c.Proof, c.InnerWitness, WithCompleteArithmetic(), WithDuplexTranscript())
+	return err
+}
+
+func TestBLS12InBW6DuplexTranscript(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	field, outer := ecc.BLS12_377.ScalarField(), ecc.BW6_761.ScalarField()
+	innerCcs, err := frontend.Compile(field, scs.NewBuilder, &InnerCircuitNativeWoCommit{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(innerCcs)
+	assert.NoError(err)
+	innerPK, innerVK, err := native_plonk.Setup(innerCcs, srs, srsLagrange)
+	assert.NoError(err)
+
+	// inner proof
+	innerWitness, err := frontend.NewWitness(&InnerCircuitNativeWoCommit{P: 3, Q: 5, N: 15}, field)
+	assert.NoError(err)
+	innerProof, err := native_plonk.Prove(innerCcs, innerPK, innerWitness, GetNativeDuplexProverOptions(outer, field))
+	assert.NoError(err)
+	innerPubWitness, err := innerWitness.Public()
+	assert.NoError(err)
+	err = native_plonk.Verify(innerProof, innerVK, innerPubWitness, GetNativeDuplexVerifierOptions(outer, field))
+	assert.NoError(err)
+	err = native_plonk.Verify(innerProof, innerVK, innerPubWitness, GetNativeVerifierOptions(outer, field))
+	assert.Error(err)
+
+	// outer proof
+	circuitVk, err := ValueOfVerifyingKey[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerVK)
+	assert.NoError(err)
+	circuitWitness, err := ValueOfWitness[sw_bls12377.ScalarField](innerPubWitness)
+	assert.NoError(err)
+	circuitProof, err := ValueOfProof[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerProof)
+	assert.NoError(err)
+
+	outerCircuit := &OuterCircuitDuplex[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT]{
+		InnerWitness: PlaceholderWitness[sw_bls12377.ScalarField](innerCcs),
+		Proof:        PlaceholderProof[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerCcs),
+		VerifyingKey: circuitVk,
+	}
+	outerAssignment := &OuterCircuitDuplex[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT]{
+		InnerWitness: circuitWitness,
+		Proof:        circuitProof,
+	}
+	err = test.IsSolved(outerCircuit, outerAssignment, outer)
+	assert.NoError(err)
+
+	// the verifier circuit is smaller than with the default transcript
+	duplexCcs, err := frontend.Compile(outer, scs.NewBuilder, outerCircuit)
+	assert.NoError(err)
+	defaultCcs, err := frontend.Compile(outer, scs.NewBuilder, &OuterCircuit[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT]{
+		InnerWitness: PlaceholderWitness[sw_bls12377.ScalarField](innerCcs),
+		Proof:        PlaceholderProof[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerCcs),
+		VerifyingKey: circuitVk,
+	})
+	assert.NoError(err)
+	assert.Less(duplexCcs.GetNbConstraints(), defaultCcs.GetNbConstraints())
+	t.Logf("duplex transcript: %d constraints, default transcript: %d constraints", duplexCcs.GetNbConstraints(), defaultCcs.GetNbConstraints())
+}
+
+//-----------------------------------------------------------------
+// With custom gates
+
+type InnerCircuitCustomGates struct {
+	P, Q frontend.Variable
+	N    frontend.Variable `gnark:",public"`
+}
+
+func (c *InnerCircuitCustomGates) Define(api frontend.API) error {
+	plonkAPI, ok := api.(frontend.PlonkAPI)
+	if !ok {
+		return fmt.Errorf("builder does not implement frontend.PlonkAPI")
+	}
+	// N = P⁵ on the row (P, P², N)
+	sbox, err := plonkAPI.AddCustomGate(constraint.CustomGate{
+		Name: "sbox",
+		Identities: [][]constraint.GateTerm{
+			{{Coeff: 1, Wires: []constraint.GateWire{constraint.GateR}}, {Coeff: -1, Wires: []constraint.GateWire{constraint.GateL, constraint.GateL}}},
+			{{Coeff: 1, Wires: []constraint.GateWire{constraint.GateO}}, {Coeff: -1, Wires: []constraint.GateWire{constraint.GateR, constraint.GateR, constraint.GateL}}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	// the left wire of the next row is the product of the left and right wires
+	mul, err := plonkAPI.AddCustomGate(constraint.CustomGate{
+		Name: "mul",
+		Identities: [][]constraint.GateTerm{
+			{{Coeff: 1, Wires: []constraint.GateWire{constraint.GateNextL}}, {Coeff: -1, Wires: []constraint.GateWire{constraint.GateL, constraint.GateR}}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	plonkAPI.AddCustomConstraint(sbox, c.P, api.Mul(c.P, c.P), c.N)
+	plonkAPI.AddCustomConstraint(mul, c.P, c.Q, c.N, api.Mul(c.P, c.Q), c.Q, c.P)
+	return nil
+}
+
+func TestBLS12InBW6CustomGates(t *testing.T) {
+
+	assert := test.NewAssert(t)
+	field, outer := ecc.BLS12_377.ScalarField(), ecc.BW6_761.ScalarField()
+
+	innerCcs, err := frontend.Compile(field, scs.NewBuilder, &InnerCircuitCustomGates{})
+	assert.NoError(err)
+	srs, srsLagrange, err := unsafekzg.NewSRS(innerCcs)
+	assert.NoError(err)
+	innerPK, innerVK, err := native_plonk.Setup(innerCcs, srs, srsLagrange)
+	assert.NoError(err)
+
+	// inner proof
+	innerWitness, err := frontend.NewWitness(&InnerCircuitCustomGates{P: 3, Q: 5, N: 243}, field)
+	assert.NoError(err)
+	innerProof, err := native_plonk.Prove(innerCcs, innerPK, innerWitness, GetNativeProverOptions(outer, field))
+	assert.NoError(err)
+	innerPubWitness, err := innerWitness.Public()
+	assert.NoError(err)
+	err = native_plonk.Verify(innerProof, innerVK, innerPubWitness, GetNativeVerifierOptions(outer, field))
+	assert.NoError(err)
+
+	// outer proof
+	circuitVk, err := ValueOfVerifyingKey[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerVK)
+	assert.NoError(err)
+	circuitWitness, err := ValueOfWitness[sw_bls12377.ScalarField](innerPubWitness)
+	assert.NoError(err)
+	circuitProof, err := ValueOfProof[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerProof)
+	assert.NoError(err)
+
+	outerCircuit := &OuterCircuit[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT]{
+		InnerWitness: PlaceholderWitness[sw_bls12377.ScalarField](innerCcs),
+		Proof:        PlaceholderProof[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine](innerCcs),
+		VerifyingKey: circuitVk,
+	}
+	outerAssignment := &OuterCircuit[sw_bls12377.ScalarField, sw_bls12377.G1Affine, sw_bls12377.G2Affine, sw_bls12377.GT]{
+		InnerWitness: circuitWitness,
+		Proof:        circuitProof,
+	}
+	err = test.IsSolved(outerCircuit, outerAssignment, ecc.BW6_761.ScalarField())
+	assert.NoError(err)
+}
+
 //-----------------------------------------------------------------
 // With api.Commit
 
@@ -0,0 +1,541 @@
+// Package plonkfri implements the in-circuit verifier of the PLONK with FRI
+// proofs of [github.com/consensys/gnark/backend/plonkfri].
+//
+// The proofs only use MiMC over the scalar field of the curve they are
+// generated on, so they are verified in a circuit over the same field, without
+// field emulation nor pairings. The verification circuit is typically itself
+// proved with Groth16 to compress the proof.
+package plonkfri
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	fft_bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377/fr/fft"
+	fft_bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381/fr/fft"
+	fft_bls24315 "github.com/consensys/gnark-crypto/ecc/bls24-315/fr/fft"
+	fft_bls24317 "github.com/consensys/gnark-crypto/ecc/bls24-317/fr/fft"
+	fft_bn254 "github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	fft_bw6633 "github.com/consensys/gnark-crypto/ecc/bw6-633/fr/fft"
+	fft_bw6761 "github.com/consensys/gnark-crypto/ecc/bw6-761/fr/fft"
+	backend_plonkfri "github.com/consensys/gnark/backend/plonkfri"
+	plonkfri_bls12377 "github.com/consensys/gnark/backend/plonkfri/bls12-377"
+	plonkfri_bls12381 "github.com/consensys/gnark/backend/plonkfri/bls12-381"
+	plonkfri_bls24315 "github.com/consensys/gnark/backend/plonkfri/bls24-315"
+	plonkfri_bls24317 "github.com/consensys/gnark/backend/plonkfri/bls24-317"
+	plonkfri_bn254 "github.com/consensys/gnark/backend/plonkfri/bn254"
+	plonkfri_bw6633 "github.com/consensys/gnark/backend/plonkfri/bw6-633"
+	plonkfri_bw6761 "github.com/consensys/gnark/backend/plonkfri/bw6-761"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/commitments/fri"
+	fiatshamir "github.com/consensys/gnark/std/fiat-shamir"
+	"github.com/consensys/gnark/std/hash/mimc"
+)
+
+// indices of the polynomials opened at ζ in Proof.ZetaEvaluations, see the
+// backend.
+const (
+	idQl = iota
+	idQr
+	idQm
+	idQo
+	idQk
+	idS1
+	idS2
+	idS3
+	idL
+	idR
+	idO
+	idZ
+	idH1
+	idH2
+	idH3
+	nbZetaEvaluations
+)
+
+// number of polynomials committed in every Merkle tree of the proof.
+const (
+	nbPreprocessed = 8
+	nbLRO          = 3
+	nbZ            = 1
+	nbH            = 4 // h1, h2, h3 and the mask of the DEEP quotient
+)
+
+var (
+	errInvalidProofShape   = errors.New("proof does not match the verifying key")
+	errInvalidWitness      = errors.New("witness length is invalid")
+	errUnsupportedType     = errors.New("unsupported type")
+	errFieldMismatch       = errors.New("the proof must be verified in a circuit over its scalar field")
+	errUnsupportedTreeSize = errors.New("the circuit must have at least two constraints and public inputs")
+)
+
+// TreeOpening is the opening of a leaf of one of the Merkle trees of the proof.
+type TreeOpening struct {
+	Values      []frontend.Variable
+	MerkleProof []frontend.Variable
+}
+
+// QueryOpening stores the openings of the committed polynomials at a query of
+// FRI.
+type QueryOpening struct {
+	Preprocessed, LRO, Z, H TreeOpening
+}
+
+// Proof is a PLONK with FRI proof in a circuit. Use [PlaceholderProof] to
+// allocate it for compiling the circuit and [ValueOfProof] for witness
+// assignment.
+type Proof struct {
+	// Merkle roots of the evaluations of l, r, o, of Z and of h1, h2, h3
+	LRO, Z, H frontend.Variable
+
+	// evaluations at ζ of ql, qr, qm, qo, qk, s1, s2, s3, l, r, o, Z, h1, h2, h3
+	ZetaEvaluations []frontend.Variable
+
+	// evaluation of Z at ωζ
+	ZetaShiftedEvaluation frontend.Variable
+
+	// openings of the committed polynomials at the queries of FRI
+	Openings []QueryOpening
+
+	// proof of proximity of the DEEP quotient
+	Fri fri.NativeProof
+}
+
+// VerifyingKey is a PLONK with FRI verifying key in a circuit. Except the
+// Merkle root of the preprocessed polynomials, it is fixed in the circuit, so
+// a verification circuit can be used for all the circuits of the same size and
+// number of public inputs. Use [ValueOfVerifyingKey] both for compiling the
+// circuit and for witness assignment.
+type VerifyingKey struct {
+	// Size of the circuit, a power of 2
+	Size              uint64
+	NbPublicVariables uint64
+
+	// DegreeBound bounds the degrees of the committed polynomials, a power of
+	// 2
+	DegreeBound uint64
+
+	// parameters of FRI
+	RateBits, NbQueries uint64
+
+	// Generator of the domain of size Size, CosetShift the shift of the
+	// cosets of the permutation and of the evaluation domain of FRI, and
+	// EvaluationGenerator the generator of the evaluation domain, of size
+	// DegreeBound·2^RateBits
+	Generator, CosetShift, EvaluationGenerator big.Int `gnark:"-"`
+
+	// Merkle root of the evaluations of ql, qr, qm, qo, qk, s1, s2, s3
+	Preprocessed frontend.Variable
+}
+
+// Witness is the public witness of the verified circuit.
+type Witness struct {
+	Public []frontend.Variable
+}
+
+// ValueOfVerifyingKey returns the verifying key of the verification circuit.
+func ValueOfVerifyingKey(vk backend_plonkfri.VerifyingKey) (VerifyingKey, error) {
+	var ret VerifyingKey
+	switch tVk := vk.(type) {
+	case *plonkfri_bn254.VerifyingKey:
+		domain := fft_bn254.NewDomain(tVk.DegreeBound()<<tVk.RateBits, fft_bn254.WithoutPrecompute())
+		domain.Generator.BigInt(&ret.EvaluationGenerator)
+		tVk.Generator.BigInt(&ret.Generator)
+		tVk.CosetShift.BigInt(&ret.CosetShift)
+		ret.Preprocessed = tVk.Preprocessed.BigInt(new(big.Int))
+		ret.Size, ret.NbPublicVariables, ret.RateBits, ret.NbQueries = tVk.Size, tVk.NbPublicVariables, tVk.RateBits, tVk.NbQueries
+		ret.DegreeBound = tVk.DegreeBound()
+	case *plonkfri_bls12381.VerifyingKey:
+		domain := fft_bls12381.NewDomain(tVk.DegreeBound()<<tVk.RateBits, fft_bls12381.WithoutPrecompute())
+		domain.Generator.BigInt(&ret.EvaluationGenerator)
+		tVk.Generator.BigInt(&ret.Generator)
+		tVk.CosetShift.BigInt(&ret.CosetShift)
+		ret.Preprocessed = tVk.Preprocessed.BigInt(new(big.Int))
+		ret.Size, ret.NbPublicVariables, ret.RateBits, ret.NbQueries = tVk.Size, tVk.NbPublicVariables, tVk.RateBits, tVk.NbQueries
+		ret.DegreeBound = tVk.DegreeBound()
+	case *plonkfri_bls12377.VerifyingKey:
+		domain := fft_bls12377.NewDomain(tVk.DegreeBound()<<tVk.RateBits, fft_bls12377.WithoutPrecompute())
+		domain.Generator.BigInt(&ret.EvaluationGenerator)
+		tVk.Generator.BigInt(&ret.Generator)
+		tVk.CosetShift.BigInt(&ret.CosetShift)
+		ret.Preprocessed = tVk.Preprocessed.BigInt(new(big.Int))
+		ret.Size, ret.NbPublicVariables, ret.RateBits, ret.NbQueries = tVk.Size, tVk.NbPublicVariables, tVk.RateBits, tVk.NbQueries
+		ret.DegreeBound = tVk.DegreeBound()
+	case *plonkfri_bw6761.VerifyingKey:
+		domain := fft_bw6761.NewDomain(tVk.DegreeBound()<<tVk.RateBits, fft_bw6761.WithoutPrecompute())
+		domain.Generator.BigInt(&ret.EvaluationGenerator)
+		tVk.Generator.BigInt(&ret.Generator)
+		tVk.CosetShift.BigInt(&ret.CosetShift)
+		ret.Preprocessed = tVk.Preprocessed.BigInt(new(big.Int))
+		ret.Size, ret.NbPublicVariables, ret.RateBits, ret.NbQueries = tVk.Size, tVk.NbPublicVariables, tVk.RateBits, tVk.NbQueries
+		ret.DegreeBound = tVk.DegreeBound()
+	case *plonkfri_bls24317.VerifyingKey:
+		domain := fft_bls24317.NewDomain(tVk.DegreeBound()<<tVk.RateBits, fft_bls24317.WithoutPrecompute())
+		domain.Generator.BigInt(&ret.EvaluationGenerator)
+		tVk.Generator.BigInt(&ret.Generator)
+		tVk.CosetShift.BigInt(&ret.CosetShift)
+		ret.Preprocessed = tVk.Preprocessed.BigInt(new(big.Int))
+		ret.Size, ret.NbPublicVariables, ret.RateBits, ret.NbQueries = tVk.Size, tVk.NbPublicVariables, tVk.RateBits, tVk.NbQueries
+		ret.DegreeBound = tVk.DegreeBound()
+	case *plonkfri_bls24315.VerifyingKey:
+		domain := fft_bls24315.NewDomain(tVk.DegreeBound()<<tVk.RateBits, fft_bls24315.WithoutPrecompute())
+		domain.Generator.BigInt(&ret.EvaluationGenerator)
+		tVk.Generator.BigInt(&ret.Generator)
+		tVk.CosetShift.BigInt(&ret.CosetShift)
+		ret.Preprocessed = tVk.Preprocessed.BigInt(new(big.Int))
+		ret.Size, ret.NbPublicVariables, ret.RateBits, ret.NbQueries = tVk.Size, tVk.NbPublicVariables, tVk.RateBits, tVk.NbQueries
+		ret.DegreeBound = tVk.DegreeBound()
+	case *plonkfri_bw6633.VerifyingKey:
+		domain := fft_bw6633.NewDomain(tVk.DegreeBound()<<tVk.RateBits, fft_bw6633.WithoutPrecompute())
+		domain.Generator.BigInt(&ret.EvaluationGenerator)
+		tVk.Generator.BigInt(&ret.Generator)
+		tVk.CosetShift.BigInt(&ret.CosetShift)
+		ret.Preprocessed = tVk.Preprocessed.BigInt(new(big.Int))
+		ret.Size, ret.NbPublicVariables, ret.RateBits, ret.NbQueries = tVk.Size, tVk.NbPublicVariables, tVk.RateBits, tVk.NbQueries
+		ret.DegreeBound = tVk.DegreeBound()
+	default:
+		return ret, fmt.Errorf("%w: %T", errUnsupportedType, vk)
+	}
+	return ret, nil
+}
+
+// friParams returns the parameters of the proof of proximity of the DEEP
+// quotient.
+func (vk *VerifyingKey) friParams() fri.NativeParams {
+	logDegree := 0
+	for s := vk.DegreeBound; s > 1; s >>= 1 {
+		logDegree++
+	}
+	return fri.NativeParams{
+		LogDegree: logDegree,
+		RateBits:  int(vk.RateBits),
+		NbQueries: int(vk.NbQueries),
+	}
+}
+
+// PlaceholderProof returns a proof with the slices allocated according to vk,
+// to be used when compiling the verification circuit.
+func PlaceholderProof(vk VerifyingKey) Proof {
+	params := vk.friParams()
+	depth := params.LogDegree + params.RateBits - 1
+	tree := func(nbPolys int) TreeOpening {
+		return TreeOpening{
+			Values:      make([]frontend.Variable, 2*nbPolys),
+			MerkleProof: make([]frontend.Variable, depth),
+		}
+	}
+	proof := Proof{
+		ZetaEvaluations: make([]frontend.Variable, nbZetaEvaluations),
+		Openings:        make([]QueryOpening, vk.NbQueries),
+		Fri:             fri.PlaceholderNativeProof(params),
+	}
+	for i := range proof.Openings {
+		proof.Openings[i] = QueryOpening{
+			Preprocessed: tree(nbPreprocessed),
+			LRO:          tree(nbLRO),
+			Z:            tree(nbZ),
+			H:            tree(nbH),
+		}
+	}
+	return proof
+}
+
+// ValueOfProof returns the assignment of the proof.
+func ValueOfProof(proof backend_plonkfri.Proof) (Proof, error) {
+	switch proof.(type) {
+	case *plonkfri_bn254.Proof,
+		*plonkfri_bls12381.Proof,
+		*plonkfri_bls12377.Proof,
+		*plonkfri_bw6761.Proof,
+		*plonkfri_bls24317.Proof,
+		*plonkfri_bls24315.Proof,
+		*plonkfri_bw6633.Proof:
+	default:
+		return Proof{}, fmt.Errorf("%w: %T", errUnsupportedType, proof)
+	}
+
+	// the proofs of all the curves have the same layout, with fr.Element
+	// instead of frontend.Variable
+	p := reflect.ValueOf(proof).Elem()
+	field := func(v reflect.Value, name string) reflect.Value { return v.FieldByName(name) }
+	tree := func(v reflect.Value) TreeOpening {
+		return TreeOpening{
+			Values:      valuesOf(field(v, "Values")),
+			MerkleProof: valuesOf(field(v, "MerkleProof")),
+		}
+	}
+
+	ret := Proof{
+		LRO:                   valueOf(field(p, "LRO")),
+		Z:                     valueOf(field(p, "Z")),
+		H:                     valueOf(field(p, "H")),
+		ZetaEvaluations:       valuesOf(field(p, "ZetaEvaluations")),
+		ZetaShiftedEvaluation: valueOf(field(p, "ZetaShiftedEvaluation")),
+	}
+	openings := field(p, "Openings")
+	ret.Openings = make([]QueryOpening, openings.Len())
+	for i := range ret.Openings {
+		o := openings.Index(i)
+		ret.Openings[i] = QueryOpening{
+			Preprocessed: tree(field(o, "Preprocessed")),
+			LRO:          tree(field(o, "LRO")),
+			Z:            tree(field(o, "Z")),
+			H:            tree(field(o, "H")),
+		}
+	}
+
+	f := field(p, "Fri")
+	ret.Fri.Commitments = valuesOf(field(f, "Commitments"))
+	ret.Fri.FinalPoly = valuesOf(field(f, "FinalPoly"))
+	rounds := field(f, "QueryRounds")
+	ret.Fri.QueryRounds = make([]fri.NativeQueryRound, rounds.Len())
+	for i := range ret.Fri.QueryRounds {
+		steps := rounds.Index(i)
+		ret.Fri.QueryRounds[i].Steps = make([]fri.NativeQueryStep, steps.Len())
+		for j := range ret.Fri.QueryRounds[i].Steps {
+			evals := valuesOf(field(steps.Index(j), "Evals"))
+			ret.Fri.QueryRounds[i].Steps[j] = fri.NativeQueryStep{
+				Evals:       [2]frontend.Variable{evals[0], evals[1]},
+				MerkleProof: valuesOf(field(steps.Index(j), "MerkleProof")),
+			}
+		}
+	}
+	return ret, nil
+}
+
+// valueOf returns the value of the field element v.
+func valueOf(v reflect.Value) frontend.Variable {
+	e := v.Addr().Interface().(interface{ BigInt(*big.Int) *big.Int })
+	return e.BigInt(new(big.Int))
+}
+
+// valuesOf returns the values of the slice or array of field elements v.
+func valuesOf(v reflect.Value) []frontend.Variable {
+	res := make([]frontend.Variable, v.Len())
+	for i := range res {
+		res[i] = valueOf(v.Index(i))
+	}
+	return res
+}
+
+// ValueOfWitness returns the public witness of the verified circuit.
+func ValueOfWitness(w witness.Witness) (Witness, error) {
+	var ret Witness
+	pubw, err := w.Public()
+	if err != nil {
+		return ret, fmt.Errorf("get public witness: %w", err)
+	}
+	vec := reflect.ValueOf(pubw.Vector())
+	if vec.Kind() != reflect.Slice {
+		return ret, fmt.Errorf("%w: %T", errUnsupportedType, pubw.Vector())
+	}
+	ret.Public = valuesOf(vec)
+	return ret, nil
+}
+
+// Verifier verifies PLONK with FRI proofs over the native field of the
+// circuit.
+type Verifier struct {
+	api frontend.API
+}
+
+// NewVerifier returns a new verifier.
+func NewVerifier(api frontend.API) (*Verifier, error) {
+	return &Verifier{api: api}, nil
+}
+
+// AssertProof asserts that the proof is valid for the verifying key and the
+// public witness. The proof must have been generated on a curve whose scalar
+// field is the native field of the circuit.
+func (v *Verifier) AssertProof(vk VerifyingKey, proof Proof, witness Witness) error {
+	api := v.api
+	modulus := api.Compiler().Field()
+	if vk.Size < 2 || vk.DegreeBound < vk.Size {
+		return errUnsupportedTreeSize
+	}
+	if len(witness.Public) != int(vk.NbPublicVariables) {
+		return errInvalidWitness
+	}
+	if err := checkProofShape(vk, proof); err != nil {
+		return err
+	}
+	// the generator of the evaluation domain is a root of unity of the field
+	// of the proof
+	var check big.Int
+	check.Exp(&vk.EvaluationGenerator, new(big.Int).SetUint64(vk.DegreeBound<<vk.RateBits), modulus)
+	if check.Cmp(big.NewInt(1)) != 0 {
+		return errFieldMismatch
+	}
+
+	h, err := mimc.NewMiMC(api)
+	if err != nil {
+		return fmt.Errorf("new hash: %w", err)
+	}
+	params := vk.friParams()
+	ids := append([]string{"gamma", "beta", "alpha", "zeta", "lambda"}, fri.NativeChallengeIDs(params)...)
+	fs := fiatshamir.NewTranscript(api, &h, ids)
+
+	// derive the challenges
+	bindings := make([]frontend.Variable, 0, len(witness.Public)+2)
+	bindings = append(bindings, vk.Preprocessed)
+	bindings = append(bindings, witness.Public...)
+	bindings = append(bindings, proof.LRO)
+	gamma, err := deriveRandomness(fs, "gamma", bindings...)
+	if err != nil {
+		return err
+	}
+	beta, err := deriveRandomness(fs, "beta")
+	if err != nil {
+		return err
+	}
+	alpha, err := deriveRandomness(fs, "alpha", proof.Z)
+	if err != nil {
+		return err
+	}
+	zeta, err := deriveRandomness(fs, "zeta", proof.H)
+	if err != nil {
+		return err
+	}
+	bindings = append(append([]frontend.Variable{}, proof.ZetaEvaluations...), proof.ZetaShiftedEvaluation)
+	lambda, err := deriveRandomness(fs, "lambda", bindings...)
+	if err != nil {
+		return err
+	}
+
+	// check the identity at ζ
+	v.assertQuotient(vk, proof, witness, alpha, beta, gamma, zeta)
+
+	// check that the opened values are consistent with the proof of proximity
+	// of the DEEP quotient
+	f, err := fri.NewNativeFri(api, params, &h, &vk.CosetShift, &vk.EvaluationGenerator)
+	if err != nil {
+		return fmt.Errorf("new fri: %w", err)
+	}
+	queries, err := f.VerifyProofOfProximityWithTranscript(fs, proof.Fri)
+	if err != nil {
+		return fmt.Errorf("verify proof of proximity: %w", err)
+	}
+	zetaShifted := api.Mul(zeta, &vk.Generator)
+	var lambdaZ frontend.Variable = 1
+	for i := 0; i < nbZetaEvaluations; i++ {
+		lambdaZ = api.Mul(lambdaZ, lambda)
+	}
+	for i, q := range queries {
+		slot, leafBits := q.Bits[0], q.Bits[1:]
+		opening := &proof.Openings[i]
+		roots := []frontend.Variable{vk.Preprocessed, proof.LRO, proof.Z, proof.H}
+		values := make([]frontend.Variable, 0, nbZetaEvaluations+1)
+		for j, t := range []*TreeOpening{&opening.Preprocessed, &opening.LRO, &opening.Z, &opening.H} {
+			f.VerifyMerkleProof(roots[j], t.Values, t.MerkleProof, leafBits)
+			k := len(t.Values) / 2
+			for l := 0; l < k; l++ {
+				values = append(values, api.Select(slot, t.Values[k+l], t.Values[l]))
+			}
+		}
+
+		// Q(x) = ∑ⱼ λʲ(Pⱼ(x)-Pⱼ(ζ))/(x-ζ) + λ¹⁵(Z(x)-Z(ωζ))/(x-ωζ) + λ¹⁶M(x)
+		// where the mask M is the last opened value
+		var acc frontend.Variable = 0
+		for j := nbZetaEvaluations - 1; j >= 0; j-- {
+			acc = api.Add(api.Mul(acc, lambda), api.Sub(values[j], proof.ZetaEvaluations[j]))
+		}
+		acc = api.Div(acc, api.Sub(q.Point, zeta))
+		shifted := api.Div(api.Sub(values[idZ], proof.ZetaShiftedEvaluation), api.Sub(q.Point, zetaShifted))
+		shifted = api.Add(shifted, api.Mul(lambda, values[nbZetaEvaluations]))
+		api.AssertIsEqual(api.Add(acc, api.Mul(lambdaZ, shifted)), q.Eval)
+	}
+	return nil
+}
+
+// assertQuotient asserts that
+//
+//	gate(ζ) + α·perm(ζ) + α²·L₁(ζ)(Z(ζ)-1) = (h1(ζ) + ζⁿh2(ζ) + ζ²ⁿh3(ζ))(ζⁿ-1)
+//
+// from the claimed evaluations at ζ.
+func (v *Verifier) assertQuotient(vk VerifyingKey, proof Proof, witness Witness, alpha, beta, gamma, zeta frontend.Variable) {
+	api := v.api
+	modulus := api.Compiler().Field()
+	e := proof.ZetaEvaluations
+
+	zetaPowerN := zeta
+	for s := vk.Size; s > 1; s >>= 1 {
+		zetaPowerN = api.Mul(zetaPowerN, zetaPowerN)
+	}
+	zh := api.Sub(zetaPowerN, 1)
+	var sizeInv big.Int
+	sizeInv.SetUint64(vk.Size).ModInverse(&sizeInv, modulus)
+	zhOverN := api.Mul(zh, &sizeInv)
+
+	// L₁(ζ) = (ζⁿ-1)/(n(ζ-1)) and PI(ζ) = ∑ᵢ wᵢLᵢ(ζ) with Lᵢ(ζ) = ωⁱ(ζⁿ-1)/(n(ζ-ωⁱ))
+	l1 := api.Div(zhOverN, api.Sub(zeta, 1))
+	var pi frontend.Variable = 0
+	omegaI := big.NewInt(1)
+	for i := range witness.Public {
+		w := new(big.Int).Set(omegaI)
+		pi = api.Add(pi, api.Div(api.Mul(witness.Public[i], w), api.Sub(zeta, w)))
+		omegaI.Mul(omegaI, &vk.Generator).Mod(omegaI, modulus)
+	}
+	pi = api.Mul(pi, zhOverN)
+
+	gate := api.Add(
+		api.Mul(e[idQl], e[idL]),
+		api.Mul(e[idQr], e[idR]),
+		api.Mul(e[idQm], e[idL], e[idR]),
+		api.Mul(e[idQo], e[idO]),
+		e[idQk],
+		pi,
+	)
+
+	var u [3]big.Int
+	u[0].SetUint64(1)
+	u[1].Set(&vk.CosetShift)
+	u[2].Mul(&vk.CosetShift, &vk.CosetShift).Mod(&u[2], modulus)
+	num, den := e[idZ], proof.ZetaShiftedEvaluation
+	for j := 0; j < 3; j++ {
+		num = api.Mul(num, api.Add(api.Mul(zeta, &u[j], beta), e[idL+j], gamma))
+		den = api.Mul(den, api.Add(api.Mul(e[idS1+j], beta), e[idL+j], gamma))
+	}
+	perm := api.Sub(den, num)
+
+	lhs := api.Add(gate, api.Mul(alpha, api.Add(perm, api.Mul(alpha, l1, api.Sub(e[idZ], 1)))))
+	h := api.Add(e[idH1], api.Mul(zetaPowerN, api.Add(e[idH2], api.Mul(zetaPowerN, e[idH3]))))
+	api.AssertIsEqual(lhs, api.Mul(h, zh))
+}
+
+// checkProofShape checks that the sizes of the slices of the proof match vk.
+func checkProofShape(vk VerifyingKey, proof Proof) error {
+	if len(proof.ZetaEvaluations) != nbZetaEvaluations || len(proof.Openings) != int(vk.NbQueries) {
+		return errInvalidProofShape
+	}
+	params := vk.friParams()
+	depth := params.LogDegree + params.RateBits - 1
+	for i := range proof.Openings {
+		o := &proof.Openings[i]
+		for _, t := range []struct {
+			opening *TreeOpening
+			nbPolys int
+		}{{&o.Preprocessed, nbPreprocessed}, {&o.LRO, nbLRO}, {&o.Z, nbZ}, {&o.H, nbH}} {
+			if len(t.opening.Values) != 2*t.nbPolys || len(t.opening.MerkleProof) != depth {
+				return errInvalidProofShape
+			}
+		}
+	}
+	return nil
+}
+
+// deriveRandomness binds the values to the challenge and computes it.
+func deriveRandomness(fs *fiatshamir.Transcript, challenge string, values ...frontend.Variable) (frontend.Variable, error) {
+	if len(values) > 0 {
+		if err := fs.Bind(challenge, values); err != nil {
+			return nil, err
+		}
+	}
+	res, err := fs.ComputeChallenge(challenge)
+	if err != nil {
+		return nil, fmt.Errorf("compute challenge %s: %w", challenge, err)
+	}
+	return res, nil
+}
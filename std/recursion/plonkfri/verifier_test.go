@@ -0,0 +1,138 @@
+package plonkfri
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	backend_plonkfri "github.com/consensys/gnark/backend/plonkfri"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+	"github.com/consensys/gnark/test"
+)
+
+type innerCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+	Z frontend.Variable `gnark:",public"`
+}
+
+func (c *innerCircuit) Define(api frontend.API) error {
+	res := c.X
+	for i := 0; i < 5; i++ {
+		res = api.Mul(res, res)
+	}
+	api.AssertIsEqual(res, c.Y)
+	api.AssertIsEqual(api.Add(c.X, c.Y), c.Z)
+	return nil
+}
+
+func getInner(assert *test.Assert, field *big.Int) (backend_plonkfri.VerifyingKey, backend_plonkfri.Proof, *innerCircuit) {
+	ccs, err := frontend.Compile(field, scs.NewBuilder, &innerCircuit{})
+	assert.NoError(err)
+	x := big.NewInt(3)
+	y := new(big.Int).Exp(x, big.NewInt(32), field)
+	z := new(big.Int).Add(x, y)
+	assignment := &innerCircuit{X: x, Y: y, Z: z}
+	w, err := frontend.NewWitness(assignment, field)
+	assert.NoError(err)
+	pk, vk, err := backend_plonkfri.Setup(ccs, backend_plonkfri.WithNbQueries(4))
+	assert.NoError(err)
+	proof, err := backend_plonkfri.Prove(ccs, pk, w)
+	assert.NoError(err)
+	pubW, err := w.Public()
+	assert.NoError(err)
+	assert.NoError(backend_plonkfri.Verify(proof, vk, pubW))
+	return vk, proof, assignment
+}
+
+type outerCircuit struct {
+	VerifyingKey VerifyingKey
+	Proof        Proof
+	Witness      Witness
+}
+
+func (c *outerCircuit) Define(api frontend.API) error {
+	verifier, err := NewVerifier(api)
+	if err != nil {
+		return err
+	}
+	return verifier.AssertProof(c.VerifyingKey, c.Proof, c.Witness)
+}
+
+func TestVerifier(t *testing.T) {
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+	innerVk, innerProof, innerAssignment := getInner(assert, field)
+
+	vk, err := ValueOfVerifyingKey(innerVk)
+	assert.NoError(err)
+	proof, err := ValueOfProof(innerProof)
+	assert.NoError(err)
+	innerW, err := frontend.NewWitness(innerAssignment, field, frontend.PublicOnly())
+	assert.NoError(err)
+	w, err := ValueOfWitness(innerW)
+	assert.NoError(err)
+
+	circuit := &outerCircuit{
+		VerifyingKey: vk,
+		Proof:        PlaceholderProof(vk),
+		Witness:      Witness{Public: make([]frontend.Variable, vk.NbPublicVariables)},
+	}
+	assignment := &outerCircuit{
+		VerifyingKey: vk,
+		Proof:        proof,
+		Witness:      w,
+	}
+	err = test.IsSolved(circuit, assignment, field)
+	assert.NoError(err)
+
+	// the verification circuit can be proved with Groth16
+	_, err = frontend.Compile(field, r1cs.NewBuilder, circuit)
+	assert.NoError(err)
+
+	// wrong public input
+	wrong := &outerCircuit{
+		VerifyingKey: vk,
+		Proof:        proof,
+		Witness:      Witness{Public: []frontend.Variable{w.Public[0], 1}},
+	}
+	err = test.IsSolved(circuit, wrong, field)
+	assert.Error(err)
+
+	// tampered FRI proof
+	tampered, err := ValueOfProof(innerProof)
+	assert.NoError(err)
+	tampered.Fri.FinalPoly[0] = 1
+	err = test.IsSolved(circuit, &outerCircuit{VerifyingKey: vk, Proof: tampered, Witness: w}, field)
+	assert.Error(err)
+}
+
+func TestVerifierWithGroth16(t *testing.T) {
+	assert := test.NewAssert(t)
+	field := ecc.BN254.ScalarField()
+	innerVk, innerProof, innerAssignment := getInner(assert, field)
+
+	vk, err := ValueOfVerifyingKey(innerVk)
+	assert.NoError(err)
+	proof, err := ValueOfProof(innerProof)
+	assert.NoError(err)
+	innerW, err := frontend.NewWitness(innerAssignment, field, frontend.PublicOnly())
+	assert.NoError(err)
+	w, err := ValueOfWitness(innerW)
+	assert.NoError(err)
+
+	circuit := &outerCircuit{
+		VerifyingKey: vk,
+		Proof:        PlaceholderProof(vk),
+		Witness:      Witness{Public: make([]frontend.Variable, vk.NbPublicVariables)},
+	}
+	assignment := &outerCircuit{
+		VerifyingKey: vk,
+		Proof:        proof,
+		Witness:      w,
+	}
+	assert.CheckCircuit(circuit, test.WithValidAssignment(assignment), test.WithCurves(ecc.BN254), test.WithBackends(backend.GROTH16))
+}
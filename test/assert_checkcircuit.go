@@ -5,6 +5,8 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/plonkfri"
+	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/constraint"
 	"github.com/consensys/gnark/frontend"
@@ -25,7 +27,7 @@ import (
 //   - the circuit can be solved with the constraint system solver
 //   - the circuit can be solved with the prover
 //   - the circuit can be verified with the verifier
-//   - the circuit can be verified with gnark-solidity-checker
+//   - the circuit can be verified with gnark-solidity-checker (evmchecker on BLS12-381)
 //   - the circuit, witness, proving and verifying keys can be serialized and deserialized
 func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...TestingOption) {
 	// get the testing configuration
@@ -46,7 +48,7 @@ func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...TestingOpti
 
 				// check that the assignment is valid with the test engine
 				if !opt.skipTestEngine {
-					err := IsSolved(circuit, w.assignment, curve.ScalarField())
+					err := IsSolved(circuit, w.assignment, curve.ScalarField(), WithCompileOptions(opt.compileOpts...))
 					assert.noError(err, &w)
 				}
 			}
@@ -57,7 +59,7 @@ func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...TestingOpti
 
 				// check that the assignment is invalid with the test engine
 				if !opt.skipTestEngine {
-					err := IsSolved(circuit, w.assignment, curve.ScalarField())
+					err := IsSolved(circuit, w.assignment, curve.ScalarField(), WithCompileOptions(opt.compileOpts...))
 					assert.error(err, &w)
 				}
 			}
@@ -107,6 +109,8 @@ func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...TestingOpti
 						concreteBackend = _groth16
 					case backend.PLONK:
 						concreteBackend = _plonk
+					case backend.PLONK_FRI:
+						concreteBackend = _plonkfri
 					default:
 						panic("backend not implemented")
 					}
@@ -119,15 +123,21 @@ func (assert *Assert) CheckCircuit(circuit frontend.Circuit, opts ...TestingOpti
 					for _, w := range validWitnesses {
 						w := w
 						assert.Run(func(assert *Assert) {
-							checkSolidity := opt.checkSolidity && curve == ecc.BN254
-							proof, err := concreteBackend.prove(ccs, pk, w.full, opt.proverOpts...)
+							checkSolidity := opt.checkSolidity && (curve == ecc.BN254 || curve == ecc.BLS12_381) && (b == backend.GROTH16 || b == backend.PLONK)
+							proverOpts, verifierOpts := opt.proverOpts, opt.verifierOpts
+							if checkSolidity {
+								// the proof must be verifiable by the exported verifier
+								proverOpts = append(proverOpts[:len(proverOpts):len(proverOpts)], solidity.WithProverTargetSolidityVerifier(b))
+								verifierOpts = append(verifierOpts[:len(verifierOpts):len(verifierOpts)], solidity.WithVerifierTargetSolidityVerifier(b))
+							}
+							proof, err := concreteBackend.prove(ccs, pk, w.full, proverOpts...)
 							assert.noError(err, &w)
 
-							err = concreteBackend.verify(proof, vk, w.public, opt.verifierOpts...)
+							err = concreteBackend.verify(proof, vk, w.public, verifierOpts...)
 							assert.noError(err, &w)
 
 							if checkSolidity {
-								// check that the proof can be verified by gnark-solidity-checker
+								// check that the proof can be verified by the exported contract
 								if _vk, ok := vk.(verifyingKey); ok {
 									assert.Run(func(assert *Assert) {
 										assert.solidityVerification(b, _vk, proof, w.public)
@@ -268,4 +278,20 @@ var (
 			return plonk.Verify(proof.(plonk.Proof), vk.(plonk.VerifyingKey), publicWitness, opts...)
 		},
 	}
+
+	_plonkfri = tBackend{
+		setup: func(ccs constraint.ConstraintSystem, curve ecc.ID) (
+			pk, vk any,
+			pkBuilder, vkBuilder, proofBuilder func() any,
+			err error) {
+			pk, vk, err = plonkfri.Setup(ccs)
+			return pk, vk, func() any { return plonkfri.NewProvingKey(curve) }, func() any { return plonkfri.NewVerifyingKey(curve) }, func() any { return plonkfri.NewProof(curve) }, err
+		},
+		prove: func(ccs constraint.ConstraintSystem, pk any, fullWitness witness.Witness, opts ...backend.ProverOption) (proof any, err error) {
+			return plonkfri.Prove(ccs, pk.(plonkfri.ProvingKey), fullWitness, opts...)
+		},
+		verify: func(proof, vk any, publicWitness witness.Witness, opts ...backend.VerifierOption) error {
+			return plonkfri.Verify(proof.(plonkfri.Proof), vk.(plonkfri.VerifyingKey), publicWitness, opts...)
+		},
+	}
 )
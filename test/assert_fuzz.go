@@ -172,8 +172,8 @@ func (assert *Assert) fuzzer(fuzzer filler, circuit, w frontend.Circuit, b backe
 	// fuzz a witness
 	fuzzer(w, curve)
 
-	errVars := IsSolved(circuit, w, curve.ScalarField())
-	errConsts := IsSolved(circuit, w, curve.ScalarField(), SetAllVariablesAsConstants())
+	errVars := IsSolved(circuit, w, curve.ScalarField(), WithCompileOptions(opt.compileOpts...))
+	errConsts := IsSolved(circuit, w, curve.ScalarField(), SetAllVariablesAsConstants(), WithCompileOptions(opt.compileOpts...))
 
 	if (errVars == nil) != (errConsts == nil) {
 		w, err := frontend.NewWitness(w, curve.ScalarField())
@@ -217,7 +217,7 @@ func (assert *Assert) solvingSucceeded(circuit frontend.Circuit, validAssignment
 	checkError(err)
 
 	// must not error with big int test engine
-	err = IsSolved(circuit, validAssignment, curve.ScalarField())
+	err = IsSolved(circuit, validAssignment, curve.ScalarField(), WithCompileOptions(opt.compileOpts...))
 	checkError(err)
 
 	err = ccs.IsSolved(w.full, opt.solverOpts...)
@@ -237,7 +237,7 @@ func (assert *Assert) solvingFailed(circuit frontend.Circuit, invalidAssignment
 	checkError(err)
 
 	// must error with big int test engine
-	err = IsSolved(circuit, invalidAssignment, curve.ScalarField())
+	err = IsSolved(circuit, invalidAssignment, curve.ScalarField(), WithCompileOptions(opt.compileOpts...))
 	mustError(err)
 
 	err = ccs.IsSolved(w.full, opt.solverOpts...)
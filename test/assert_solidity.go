@@ -1,7 +1,6 @@
 package test
 
 import (
-	"bytes"
 	"encoding/hex"
 	"io"
 	"os"
@@ -10,19 +9,24 @@ import (
 	"strconv"
 
 	"github.com/consensys/gnark/backend"
+	groth16_bls12381 "github.com/consensys/gnark/backend/groth16/bls12-381"
 	groth16_bn254 "github.com/consensys/gnark/backend/groth16/bn254"
+	plonk_bls12381 "github.com/consensys/gnark/backend/plonk/bls12-381"
 	plonk_bn254 "github.com/consensys/gnark/backend/plonk/bn254"
+	"github.com/consensys/gnark/backend/solidity"
 	"github.com/consensys/gnark/backend/witness"
 )
 
 type verifyingKey interface {
 	NbPublicWitness() int
-	ExportSolidity(io.Writer) error
+	ExportSolidity(io.Writer, ...solidity.ExportOption) error
 }
 
 // solidityVerification checks that the exported solidity contract can verify the proof
 // and that the proof is valid.
-// It uses gnark-solidity-checker see test.WithSolidity option.
+// It uses gnark-solidity-checker for BN254 and evmchecker for BLS12-381, see
+// test.WithSolidity option. On BN254, evmchecker also checks that the Groth16
+// verifier accepts the proof compressed by its compressProof function.
 func (assert *Assert) solidityVerification(b backend.ID, vk verifyingKey,
 	proof any,
 	validPublicWitness witness.Witness) {
@@ -46,55 +50,79 @@ func (assert *Assert) solidityVerification(b backend.ID, vk verifyingKey,
 	err = fSolidity.Close()
 	assert.NoError(err)
 
-	// generate assets
-	// gnark-solidity-checker generate --dir tmpdir --solidity contract_g16.sol
-	cmd := exec.Command("gnark-solidity-checker", "generate", "--dir", tmpDir, "--solidity", "gnark_verifier.sol")
-	assert.t.Log("running ", cmd.String())
-	out, err := cmd.CombinedOutput()
-	assert.NoError(err, string(out))
+	// public witness to hex
+	bPublicWitness, err := validPublicWitness.MarshalBinary()
+	assert.NoError(err)
+	// that's quite dirty...
+	// first 4 bytes -> nbPublic
+	// next 4 bytes -> nbSecret
+	// next 4 bytes -> nb elements in the vector (== nbPublic + nbSecret)
+	bPublicWitness = bPublicWitness[12:]
+	publicWitnessStr := hex.EncodeToString(bPublicWitness)
 
 	// proof to hex
 	var proofStr string
 	var optBackend string
+	var optCommitments []string
 
-	if b == backend.GROTH16 {
+	switch _proof := proof.(type) {
+	case *groth16_bn254.Proof:
 		optBackend = "--groth16"
-		var buf bytes.Buffer
-		_proof := proof.(*groth16_bn254.Proof)
-		_, err = _proof.WriteRawTo(&buf)
-		assert.NoError(err)
-		proofBytes := buf.Bytes()
-		// keep only fpSize * 8 bytes; for now solidity contract doesn't handle the commitment part.
-		proofBytes = proofBytes[:32*8]
-		proofStr = hex.EncodeToString(proofBytes)
-	} else if b == backend.PLONK {
+		optCommitments = []string{"--commitment", strconv.Itoa(len(_proof.Commitments))}
+		proofStr = hex.EncodeToString(_proof.MarshalSolidity())
+	case *plonk_bn254.Proof:
 		optBackend = "--plonk"
-		_proof := proof.(*plonk_bn254.Proof)
 		// TODO @gbotrel make a single Marshal function for PlonK proof.
 		proofStr = hex.EncodeToString(_proof.MarshalSolidity())
-	} else {
+	case *groth16_bls12381.Proof:
+		assert.evmVerification(tmpDir, "verifyProof", hex.EncodeToString(_proof.MarshalSolidity()), publicWitnessStr)
+		return
+	case *plonk_bls12381.Proof:
+		assert.evmVerification(tmpDir, "Verify", hex.EncodeToString(_proof.MarshalSolidity()), publicWitnessStr)
+		return
+	default:
 		panic("not implemented")
 	}
 
-	// public witness to hex
-	bPublicWitness, err := validPublicWitness.MarshalBinary()
-	assert.NoError(err)
-	// that's quite dirty...
-	// first 4 bytes -> nbPublic
-	// next 4 bytes -> nbSecret
-	// next 4 bytes -> nb elements in the vector (== nbPublic + nbSecret)
-	bPublicWitness = bPublicWitness[12:]
-	publicWitnessStr := hex.EncodeToString(bPublicWitness)
+	// generate assets
+	// gnark-solidity-checker generate --dir tmpdir --solidity contract_g16.sol
+	cmd := exec.Command("gnark-solidity-checker", "generate", "--dir", tmpDir, "--solidity", "gnark_verifier.sol")
+	assert.t.Log("running ", cmd.String())
+	out, err := cmd.CombinedOutput()
+	assert.NoError(err, string(out))
 
 	// verify proof
 	// gnark-solidity-checker verify --dir tmdir --groth16 --nb-public-inputs 1 --proof 1234 --public-inputs dead
-	cmd = exec.Command("gnark-solidity-checker", "verify",
+	args := []string{"verify",
 		"--dir", tmpDir,
 		optBackend,
 		"--nb-public-inputs", strconv.Itoa(vk.NbPublicWitness()),
 		"--proof", proofStr,
-		"--public-inputs", publicWitnessStr)
+		"--public-inputs", publicWitnessStr}
+	args = append(args, optCommitments...)
+	cmd = exec.Command("gnark-solidity-checker", args...)
 	assert.t.Log("running ", cmd.String())
 	out, err = cmd.CombinedOutput()
 	assert.NoError(err, string(out))
+
+	if b == backend.GROTH16 {
+		assert.evmVerification(tmpDir, "verifyCompressedProof", proofStr, publicWitnessStr, "--compress", "compressProof")
+	}
+}
+
+// evmVerification checks that the function of the contract exported in dir
+// verifies the proof with evmchecker, which executes it in an EVM implementing
+// the BLS12-381 precompiles of EIP-2537. The extra arguments are passed to
+// evmchecker. See the test/evmchecker command.
+func (assert *Assert) evmVerification(dir, function, proofStr, publicWitnessStr string, extraArgs ...string) {
+	// evmchecker --solidity tmpdir/gnark_verifier.sol --function Verify --proof 1234 --public-inputs dead
+	args := []string{
+		"--solidity", filepath.Join(dir, "gnark_verifier.sol"),
+		"--function", function,
+		"--proof", proofStr,
+		"--public-inputs", publicWitnessStr}
+	cmd := exec.Command("evmchecker", append(args, extraArgs...)...)
+	assert.t.Log("running ", cmd.String())
+	out, err := cmd.CombinedOutput()
+	assert.NoError(err, string(out))
 }
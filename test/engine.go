@@ -17,6 +17,7 @@ limitations under the License.
 package test
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"path/filepath"
@@ -59,6 +60,8 @@ type engine struct {
 	kvstore.Store
 	blueprints        []constraint.Blueprint
 	internalVariables []*big.Int
+	fixedTables       []map[string]struct{}
+	nativeLookups     bool
 }
 
 // TestEngineOption defines an option for the test engine.
@@ -88,6 +91,23 @@ func WithBackendProverOptions(opts ...backend.ProverOption) TestEngineOption {
 	}
 }
 
+// WithCompileOptions is a test engine option which allows to define compile
+// options. The test engine only uses them to decide whether fixed tables are
+// accepted (see [frontend.WithNativeLookups]), so that the gadgets take the
+// same path as when compiled with the same options.
+func WithCompileOptions(opts ...frontend.CompileOption) TestEngineOption {
+	return func(e *engine) error {
+		var cfg frontend.CompileConfig
+		for _, opt := range opts {
+			if err := opt(&cfg); err != nil {
+				return fmt.Errorf("apply compile option: %w", err)
+			}
+		}
+		e.nativeLookups = cfg.NativeLookups
+		return nil
+	}
+}
+
 // IsSolved returns an error if the test execution engine failed to execute the given circuit
 // with provided witness as input.
 //
@@ -692,6 +712,39 @@ func (e *engine) Commit(v ...frontend.Variable) (frontend.Variable, error) {
 	return res, nil
 }
 
+// NewFixedTable declares a fixed table and returns its index. As the PLONK
+// frontend, the test engine only accepts fixed tables when the
+// [frontend.WithNativeLookups] compile option is given with
+// [WithCompileOptions]; otherwise the gadgets fall back to the in-circuit
+// lookups.
+func (e *engine) NewFixedTable(entries []*big.Int) (int, error) {
+	if !e.nativeLookups {
+		return 0, frontend.ErrNativeLookupsDisabled
+	}
+	if len(entries) == 0 {
+		return 0, errors.New("fixed table is empty")
+	}
+	table := make(map[string]struct{}, len(entries))
+	var entry big.Int
+	for i := range entries {
+		table[entry.Mod(entries[i], e.modulus()).String()] = struct{}{}
+	}
+	e.fixedTables = append(e.fixedTables, table)
+	return len(e.fixedTables) - 1, nil
+}
+
+// AssertIsInFixedTable asserts that v is an entry of the fixed table of index table.
+func (e *engine) AssertIsInFixedTable(table int, v frontend.Variable) {
+	if table < 0 || table >= len(e.fixedTables) {
+		panic("unknown fixed table")
+	}
+	b := new(big.Int).Mod(e.toBigInt(v), e.modulus())
+	if _, ok := e.fixedTables[table][b.String()]; ok {
+		return
+	}
+	panic(fmt.Sprintf("[assertIsInFixedTable] %s is not an entry of table %d", b.String(), table))
+}
+
 func (e *engine) Defer(cb func(frontend.API) error) {
 	circuitdefer.Put(e, cb)
 }
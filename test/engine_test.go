@@ -1,6 +1,7 @@
 package test
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
@@ -101,3 +102,33 @@ func TestPreCompileHook(t *testing.T) {
 		t.Error("callback not called")
 	}
 }
+
+type fixedTableCircuit struct {
+	X frontend.Variable
+}
+
+func (c *fixedTableCircuit) Define(api frontend.API) error {
+	lookuper, ok := api.Compiler().(frontend.FixedTableLookuper)
+	if !ok {
+		return fmt.Errorf("compiler does not implement fixed tables")
+	}
+	id, err := lookuper.NewFixedTable([]*big.Int{big.NewInt(1), big.NewInt(2)})
+	if err != nil {
+		return err
+	}
+	lookuper.AssertIsInFixedTable(id, c.X)
+	return nil
+}
+
+func TestFixedTableCompileOption(t *testing.T) {
+	field := ecc.BN254.ScalarField()
+	if err := IsSolved(&fixedTableCircuit{}, &fixedTableCircuit{X: 1}, field); !errors.Is(err, frontend.ErrNativeLookupsDisabled) {
+		t.Fatalf("fixed table accepted without the native lookups option: %v", err)
+	}
+	if err := IsSolved(&fixedTableCircuit{}, &fixedTableCircuit{X: 1}, field, WithCompileOptions(frontend.WithNativeLookups())); err != nil {
+		t.Fatal(err)
+	}
+	if err := IsSolved(&fixedTableCircuit{}, &fixedTableCircuit{X: 3}, field, WithCompileOptions(frontend.WithNativeLookups())); err == nil {
+		t.Fatal("witness shouldn't solve circuit")
+	}
+}
@@ -0,0 +1,373 @@
+// Command evmchecker checks that a Solidity verifier exported by gnark accepts a
+// proof when executed by an EVM implementing the Prague fork, which includes
+// the BLS12-381 precompiles of EIP-2537.
+//
+// The contract is compiled with solc, which must be in the PATH, deployed and
+// called with the proof and the public inputs:
+//
+//	evmchecker --solidity verifier.sol --proof <hex> --public-inputs <hex>
+//
+// A Vyper contract is compiled with vyper instead:
+//
+//	evmchecker --vyper verifier.vy --proof <hex> --public-inputs <hex>
+//
+// The verifier function (verifyProof by default) takes the public inputs as its
+// last argument, either a fixed or a dynamic uint256 array. The proof bytes are
+// spread over the previous arguments, in order: a fixed uint256 array or a
+// uint256 takes the corresponding number of words, and a bytes argument takes
+// the remaining bytes.
+//
+// With --compress, the proof is first given to another function of the
+// contract, spread over all its arguments, and the words it returns, in order,
+// are the proof given to the verifier function. This checks the entry points of
+// the Groth16 verifier taking compressed proofs:
+//
+//	evmchecker --solidity verifier.sol --function verifyCompressedProof --compress compressProof --proof <hex> --public-inputs <hex>
+//
+// The command fails if a call reverts or if the verifier function returns false.
+//
+// It is used by the gnark test engine with the solccheck build tag, see
+// github.com/consensys/gnark/test, and by the tests of the Vyper exporters with
+// the vypercheck build tag. It is a separate module so that gnark does
+// not depend on go-ethereum. Install it with:
+//
+//	cd test/evmchecker && go install .
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func main() {
+	var (
+		fSolidity     = flag.String("solidity", "", "path of the Solidity verifier")
+		fVyper        = flag.String("vyper", "", "path of the Vyper verifier")
+		fContract     = flag.String("contract", "", "name of the verifier contract, defaults to the contract defining the function")
+		fFunction     = flag.String("function", "verifyProof", "name of the verifier function")
+		fCompress     = flag.String("compress", "", "name of the function compressing the proof, if any")
+		fProof        = flag.String("proof", "", "hex encoded proof")
+		fPublicInputs = flag.String("public-inputs", "", "hex encoded public inputs, 32 bytes each")
+	)
+	flag.Parse()
+	if (*fSolidity == "") == (*fVyper == "") {
+		fmt.Fprintln(os.Stderr, "exactly one of --solidity and --vyper is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	compiler := func() (abi.ABI, []byte, error) {
+		return compile(*fSolidity, *fContract, *fFunction)
+	}
+	if *fVyper != "" {
+		compiler = func() (abi.ABI, []byte, error) {
+			return compileVyper(*fVyper, *fFunction)
+		}
+	}
+	gasUsed, err := check(compiler, *fFunction, *fCompress, *fProof, *fPublicInputs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("proof verified, gas used: %d\n", gasUsed)
+}
+
+func check(compiler func() (abi.ABI, []byte, error), function, compress, proofHex, publicInputsHex string) (uint64, error) {
+	proof, err := hex.DecodeString(proofHex)
+	if err != nil {
+		return 0, fmt.Errorf("decode proof: %w", err)
+	}
+	publicInputs, err := hex.DecodeString(publicInputsHex)
+	if err != nil {
+		return 0, fmt.Errorf("decode public inputs: %w", err)
+	}
+	if len(publicInputs)%32 != 0 {
+		return 0, errors.New("public inputs length is not a multiple of 32 bytes")
+	}
+
+	contractABI, bytecode, err := compiler()
+	if err != nil {
+		return 0, err
+	}
+	cfg := &runtime.Config{ChainConfig: params.MergedTestChainConfig}
+	_, address, _, err := runtime.Create(bytecode, cfg)
+	if err != nil {
+		return 0, fmt.Errorf("deploy contract: %w", err)
+	}
+	if compress != "" {
+		if proof, err = compressProof(contractABI, address, cfg, compress, proof); err != nil {
+			return 0, err
+		}
+	}
+
+	method := contractABI.Methods[function]
+	args, err := arguments(method.Inputs, proof, publicInputs)
+	if err != nil {
+		return 0, err
+	}
+	calldata, err := contractABI.Pack(function, args...)
+	if err != nil {
+		return 0, fmt.Errorf("pack arguments: %w", err)
+	}
+	gasLimit := cfg.GasLimit
+	ret, gasLeft, err := runtime.Call(address, calldata, cfg)
+	if err != nil {
+		return 0, fmt.Errorf("call %s: %w (return data %x)", function, err, ret)
+	}
+	if len(method.Outputs) == 1 && method.Outputs[0].Type.T == abi.BoolTy {
+		out, err := method.Outputs.Unpack(ret)
+		if err != nil {
+			return 0, fmt.Errorf("unpack output: %w", err)
+		}
+		if !out[0].(bool) {
+			return 0, fmt.Errorf("%s returned false", function)
+		}
+	}
+	return gasLimit - gasLeft, nil
+}
+
+// compressProof calls the function of the deployed contract with the proof
+// spread over its arguments, and returns its outputs, 32 bytes per word.
+func compressProof(contractABI abi.ABI, address common.Address, cfg *runtime.Config, function string, proof []byte) ([]byte, error) {
+	method, ok := contractABI.Methods[function]
+	if !ok {
+		return nil, fmt.Errorf("no function %s", function)
+	}
+	args, err := spread(method.Inputs, proof)
+	if err != nil {
+		return nil, err
+	}
+	calldata, err := contractABI.Pack(function, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pack arguments of %s: %w", function, err)
+	}
+	ret, _, err := runtime.Call(address, calldata, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("call %s: %w (return data %x)", function, err, ret)
+	}
+	out, err := method.Outputs.Unpack(ret)
+	if err != nil {
+		return nil, fmt.Errorf("unpack output of %s: %w", function, err)
+	}
+
+	var res []byte
+	word := func(x *big.Int) {
+		res = append(res, x.FillBytes(make([]byte, 32))...)
+	}
+	for i, o := range out {
+		switch output := method.Outputs[i]; {
+		case output.Type.T == abi.UintTy:
+			word(o.(*big.Int))
+		case output.Type.T == abi.ArrayTy && output.Type.Elem.T == abi.UintTy:
+			v := reflect.ValueOf(o)
+			for j := 0; j < v.Len(); j++ {
+				word(v.Index(j).Interface().(*big.Int))
+			}
+		default:
+			return nil, fmt.Errorf("unsupported output type %s", output.Type)
+		}
+	}
+	return res, nil
+}
+
+// arguments returns the arguments of the verifier function, the proof being
+// spread over the leading arguments and the public inputs being the last one.
+func arguments(inputs abi.Arguments, proof, publicInputs []byte) ([]any, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("the verifier function has no argument")
+	}
+	res, err := spread(inputs[:len(inputs)-1], proof)
+	if err != nil {
+		return nil, err
+	}
+
+	last := inputs[len(inputs)-1]
+	nbPublicInputs := len(publicInputs) / 32
+	var v reflect.Value
+	switch {
+	case last.Type.T == abi.SliceTy && last.Type.Elem.T == abi.UintTy:
+		v = reflect.MakeSlice(last.Type.GetType(), nbPublicInputs, nbPublicInputs)
+	case last.Type.T == abi.ArrayTy && last.Type.Elem.T == abi.UintTy:
+		if last.Type.Size != nbPublicInputs {
+			return nil, fmt.Errorf("the verifier expects %d public inputs, got %d", last.Type.Size, nbPublicInputs)
+		}
+		v = reflect.New(last.Type.GetType()).Elem()
+	default:
+		return nil, fmt.Errorf("unsupported public inputs type %s", last.Type)
+	}
+	for j := 0; j < nbPublicInputs; j++ {
+		v.Index(j).Set(reflect.ValueOf(new(big.Int).SetBytes(publicInputs[32*j : 32*(j+1)])))
+	}
+	return append(res, v.Interface()), nil
+}
+
+// spread returns the values of the arguments filled with the proof bytes, in
+// order. A fixed uint256 array or a uint256 takes the corresponding number of
+// words and a bytes argument takes the remaining bytes.
+func spread(inputs abi.Arguments, proof []byte) ([]any, error) {
+	res := make([]any, len(inputs))
+	for i, input := range inputs {
+		v := reflect.New(input.Type.GetType()).Elem()
+		switch {
+		case input.Type.T == abi.BytesTy:
+			v.SetBytes(proof)
+			proof = nil
+		case input.Type.T == abi.UintTy:
+			if len(proof) < 32 {
+				return nil, fmt.Errorf("proof too short for argument %s", input.Name)
+			}
+			v.Set(reflect.ValueOf(new(big.Int).SetBytes(proof[:32])))
+			proof = proof[32:]
+		case input.Type.T == abi.ArrayTy && input.Type.Elem.T == abi.UintTy:
+			if len(proof) < 32*input.Type.Size {
+				return nil, fmt.Errorf("proof too short for argument %s", input.Name)
+			}
+			for j := 0; j < input.Type.Size; j++ {
+				v.Index(j).Set(reflect.ValueOf(new(big.Int).SetBytes(proof[32*j : 32*(j+1)])))
+			}
+			proof = proof[32*input.Type.Size:]
+		default:
+			return nil, fmt.Errorf("unsupported argument type %s", input.Type)
+		}
+		res[i] = v.Interface()
+	}
+	if len(proof) != 0 {
+		return nil, fmt.Errorf("%d proof bytes left after filling the arguments", len(proof))
+	}
+	return res, nil
+}
+
+// compile compiles the Solidity file with solc and returns the ABI and the
+// creation bytecode of the contract. If the contract name is empty, it is the
+// contract defining the function.
+func compile(solidityPath, contract, function string) (abi.ABI, []byte, error) {
+	source, err := os.ReadFile(solidityPath)
+	if err != nil {
+		return abi.ABI{}, nil, err
+	}
+	name := filepath.Base(solidityPath)
+
+	type sourceIn struct {
+		Content string `json:"content"`
+	}
+	var input struct {
+		Language string              `json:"language"`
+		Sources  map[string]sourceIn `json:"sources"`
+		Settings struct {
+			Optimizer struct {
+				Enabled bool `json:"enabled"`
+				Runs    int  `json:"runs"`
+			} `json:"optimizer"`
+			OutputSelection map[string]map[string][]string `json:"outputSelection"`
+		} `json:"settings"`
+	}
+	input.Language = "Solidity"
+	input.Sources = map[string]sourceIn{name: {Content: string(source)}}
+	input.Settings.Optimizer.Enabled = true
+	input.Settings.Optimizer.Runs = 200
+	input.Settings.OutputSelection = map[string]map[string][]string{"*": {"*": {"abi", "evm.bytecode.object"}}}
+	bInput, err := json.Marshal(input)
+	if err != nil {
+		return abi.ABI{}, nil, err
+	}
+
+	cmd := exec.Command("solc", "--standard-json")
+	cmd.Stdin = bytes.NewReader(bInput)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	bOutput, err := cmd.Output()
+	if err != nil {
+		return abi.ABI{}, nil, fmt.Errorf("run solc: %w: %s", err, stderr.String())
+	}
+
+	var output struct {
+		Errors []struct {
+			Severity         string `json:"severity"`
+			FormattedMessage string `json:"formattedMessage"`
+		} `json:"errors"`
+		Contracts map[string]map[string]struct {
+			ABI json.RawMessage `json:"abi"`
+			EVM struct {
+				Bytecode struct {
+					Object string `json:"object"`
+				} `json:"bytecode"`
+			} `json:"evm"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(bOutput, &output); err != nil {
+		return abi.ABI{}, nil, fmt.Errorf("decode solc output: %w", err)
+	}
+	var errs []string
+	for _, e := range output.Errors {
+		if e.Severity == "error" {
+			errs = append(errs, e.FormattedMessage)
+		}
+	}
+	if len(errs) != 0 {
+		return abi.ABI{}, nil, fmt.Errorf("compile %s:\n%s", name, strings.Join(errs, "\n"))
+	}
+
+	for contractName, c := range output.Contracts[name] {
+		if contract != "" && contractName != contract {
+			continue
+		}
+		contractABI, err := abi.JSON(bytes.NewReader(c.ABI))
+		if err != nil {
+			return abi.ABI{}, nil, fmt.Errorf("decode ABI of %s: %w", contractName, err)
+		}
+		if _, ok := contractABI.Methods[function]; !ok {
+			continue
+		}
+		bytecode, err := hex.DecodeString(c.EVM.Bytecode.Object)
+		if err != nil {
+			return abi.ABI{}, nil, fmt.Errorf("decode bytecode of %s: %w", contractName, err)
+		}
+		return contractABI, bytecode, nil
+	}
+	return abi.ABI{}, nil, fmt.Errorf("no contract defining %s in %s", function, name)
+}
+
+// compileVyper compiles the Vyper file with vyper and returns the ABI and the
+// creation bytecode of the contract, which must define the function.
+func compileVyper(vyperPath, function string) (abi.ABI, []byte, error) {
+	cmd := exec.Command("vyper", "-f", "abi,bytecode", vyperPath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	bOutput, err := cmd.Output()
+	if err != nil {
+		return abi.ABI{}, nil, fmt.Errorf("run vyper: %w: %s", err, stderr.String())
+	}
+
+	// one line per output format, in the order of the -f flag
+	lines := strings.Split(strings.TrimSpace(string(bOutput)), "\n")
+	if len(lines) != 2 {
+		return abi.ABI{}, nil, fmt.Errorf("unexpected vyper output: %s", bOutput)
+	}
+	contractABI, err := abi.JSON(strings.NewReader(lines[0]))
+	if err != nil {
+		return abi.ABI{}, nil, fmt.Errorf("decode ABI: %w", err)
+	}
+	if _, ok := contractABI.Methods[function]; !ok {
+		return abi.ABI{}, nil, fmt.Errorf("no function %s in %s", function, filepath.Base(vyperPath))
+	}
+	bytecode, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(lines[1]), "0x"))
+	if err != nil {
+		return abi.ABI{}, nil, fmt.Errorf("decode bytecode: %w", err)
+	}
+	return contractABI, bytecode, nil
+}
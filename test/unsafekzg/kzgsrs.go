@@ -7,6 +7,7 @@ package unsafekzg
 import (
 	"bufio"
 	"crypto/rand"
+	"crypto/sha256"
 	"fmt"
 	"math/big"
 	"os"
@@ -78,6 +79,11 @@ func NewSRS(ccs constraint.ConstraintSystem, opts ...Option) (canonical kzg.SRS,
 		return nil, nil, err
 	}
 
+	if cfg.toxicSeed != nil {
+		log.Debug().Msg("generating SRS from toxic seed")
+		return newSRS(curveID, sizeCanonical, cfg.toxicSeed)
+	}
+
 	key := cacheKey(curveID, sizeCanonical)
 	log.Debug().Str("key", key).Msg("fetching SRS from mem cache")
 	memLock.RLock()
@@ -109,7 +115,7 @@ func NewSRS(ccs constraint.ConstraintSystem, opts ...Option) (canonical kzg.SRS,
 	log.Debug().Msg("SRS not found in cache, generating")
 
 	// not in cache, generate
-	canonical, lagrange, err = newSRS(curveID, sizeCanonical)
+	canonical, lagrange, err = newSRS(curveID, sizeCanonical, nil)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -147,10 +153,17 @@ func extractCurveID(key string) (ecc.ID, error) {
 	return ecc.IDFromString(matches[1])
 }
 
-func newSRS(curveID ecc.ID, size uint64) (kzg.SRS, kzg.SRS, error) {
-
-	tau, err := rand.Int(rand.Reader, curveID.ScalarField())
-	if err != nil {
+// newSRS generates an SRS of the given size, the toxic waste τ being sampled
+// or derived from toxicSeed if it is not nil, see WithToxicSeed.
+func newSRS(curveID ecc.ID, size uint64, toxicSeed []byte) (kzg.SRS, kzg.SRS, error) {
+
+	var tau *big.Int
+	var err error
+	if toxicSeed != nil {
+		digest := sha256.Sum256(toxicSeed)
+		tau = new(big.Int).SetBytes(digest[:])
+		tau.Mod(tau, curveID.ScalarField())
+	} else if tau, err = rand.Int(rand.Reader, curveID.ScalarField()); err != nil {
 		return nil, nil, err
 	}
 
@@ -18,9 +18,21 @@ func WithFSCache() Option {
 	}
 }
 
+// WithToxicSeed derives the toxic waste τ from seed instead of sampling it: τ
+// is the SHA-256 digest of seed, in big endian, reduced modulo the scalar field.
+// The SRS is then reproducible, and whoever knows seed can forge proofs. The
+// caches are bypassed.
+func WithToxicSeed(seed []byte) Option {
+	return func(opt *config) error {
+		opt.toxicSeed = seed
+		return nil
+	}
+}
+
 type config struct {
-	fsCache  bool
-	cacheDir string
+	fsCache   bool
+	cacheDir  string
+	toxicSeed []byte
 }
 
 // default options